@@ -19,7 +19,7 @@ externalName: {
 			schema: {
 				#Item: {
 					// type of the item.
-					type: "dashboard_by_tag" | "dashboard_by_uid" | "dashboard_by_id" @cuetsy(kind="enum")
+					type: "dashboard_by_tag" | "dashboard_by_uid" | "dashboard_by_id" | "dashboard_by_folder" | "dashboard_by_starred" @cuetsy(kind="enum")
 					// Value depends on type and describes the playlist item.
 					//  - dashboard_by_id: The value is an internal numerical identifier set by Grafana. This
 					//  is not portable as the numerical identifier is non-deterministic between different instances.
@@ -27,13 +27,34 @@ externalName: {
 					//  - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All
 					//  dashboards behind the tag will be added to the playlist.
 					//  - dashboard_by_uid: The value is the dashboard UID
+					//  - dashboard_by_folder: The value is a folder UID. All dashboards in the folder
+					//  will be added to the playlist.
+					//  - dashboard_by_starred: The value is unused. The dashboards starred by the user
+					//  playing the playlist will be added to the playlist.
 					value: string
+					// Interval overrides the playlist interval for the dashboards of this item.
+					interval?: string
+				}
+
+				#Schedule: {
+					// Weekdays the schedule starts on, for example "monday". The schedule applies to every day when empty.
+					weekdays?: [...string]
+					// Start time of the schedule in the 15:04 format.
+					startTime: string
+					// End time of the schedule in the 15:04 format. Schedules ending before they start span midnight.
+					endTime: string
+					// Timezone of the start and end times. Defaults to UTC.
+					timezone?: string
+					// The playlist played during the schedule.
+					playlistUid: string
 				}
 								
 				spec: {
 					title: string
 					interval: string
 					items: [...#Item]
+					// Schedules switch to other playlists during parts of the day.
+					schedules?: [...#Schedule]
 				}
 			}
 		}
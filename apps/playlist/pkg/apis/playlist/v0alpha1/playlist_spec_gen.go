@@ -2,14 +2,19 @@ package v0alpha1
 
 // Defines values for PlaylistItemType.
 const (
-	PlaylistItemTypeDashboardById  PlaylistItemType = "dashboard_by_id"
-	PlaylistItemTypeDashboardByTag PlaylistItemType = "dashboard_by_tag"
-	PlaylistItemTypeDashboardByUid PlaylistItemType = "dashboard_by_uid"
+	PlaylistItemTypeDashboardByFolder  PlaylistItemType = "dashboard_by_folder"
+	PlaylistItemTypeDashboardById      PlaylistItemType = "dashboard_by_id"
+	PlaylistItemTypeDashboardByStarred PlaylistItemType = "dashboard_by_starred"
+	PlaylistItemTypeDashboardByTag     PlaylistItemType = "dashboard_by_tag"
+	PlaylistItemTypeDashboardByUid     PlaylistItemType = "dashboard_by_uid"
 )
 
 // PlaylistItem defines model for PlaylistItem.
 // +k8s:openapi-gen=true
 type PlaylistItem struct {
+	// Interval overrides the playlist interval for the dashboards of this item.
+	Interval *string `json:"interval,omitempty"`
+
 	// type of the item.
 	Type PlaylistItemType `json:"type"`
 
@@ -20,6 +25,10 @@ type PlaylistItem struct {
 	//  - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All
 	//  dashboards behind the tag will be added to the playlist.
 	//  - dashboard_by_uid: The value is the dashboard UID
+	//  - dashboard_by_folder: The value is a folder UID. All dashboards in the folder
+	//  will be added to the playlist.
+	//  - dashboard_by_starred: The value is unused. The dashboards starred by the user
+	//  playing the playlist will be added to the playlist.
 	Value string `json:"value"`
 }
 
@@ -27,10 +36,32 @@ type PlaylistItem struct {
 // +k8s:openapi-gen=true
 type PlaylistItemType string
 
+// PlaylistSchedule defines model for PlaylistSchedule.
+// +k8s:openapi-gen=true
+type PlaylistSchedule struct {
+	// End time of the schedule in the 15:04 format. Schedules ending before they start span midnight.
+	EndTime string `json:"endTime"`
+
+	// The playlist played during the schedule.
+	PlaylistUid string `json:"playlistUid"`
+
+	// Start time of the schedule in the 15:04 format.
+	StartTime string `json:"startTime"`
+
+	// Timezone of the start and end times. Defaults to UTC.
+	Timezone *string `json:"timezone,omitempty"`
+
+	// Weekdays the schedule starts on, for example "monday". The schedule applies to every day when empty.
+	Weekdays []string `json:"weekdays,omitempty"`
+}
+
 // PlaylistSpec defines model for PlaylistSpec.
 // +k8s:openapi-gen=true
 type PlaylistSpec struct {
 	Interval string         `json:"interval"`
 	Items    []PlaylistItem `json:"items"`
-	Title    string         `json:"title"`
+
+	// Schedules switch to other playlists during parts of the day.
+	Schedules []PlaylistSchedule `json:"schedules,omitempty"`
+	Title     string             `json:"title"`
 }
@@ -16,6 +16,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistItem":                schema_pkg_apis_playlist_v0alpha1_PlaylistItem(ref),
 		"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistList":                schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref),
 		"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistOperatorState":       schema_pkg_apis_playlist_v0alpha1_PlaylistOperatorState(ref),
+		"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistSchedule":            schema_pkg_apis_playlist_v0alpha1_PlaylistSchedule(ref),
 		"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistSpec":                schema_pkg_apis_playlist_v0alpha1_PlaylistSpec(ref),
 		"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistStatus":              schema_pkg_apis_playlist_v0alpha1_PlaylistStatus(ref),
 		"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlayliststatusOperatorState": schema_pkg_apis_playlist_v0alpha1_PlayliststatusOperatorState(ref),
@@ -76,6 +77,13 @@ func schema_pkg_apis_playlist_v0alpha1_PlaylistItem(ref common.ReferenceCallback
 				Description: "PlaylistItem defines model for PlaylistItem.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
+					"interval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Interval overrides the playlist interval for the dashboards of this item.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"type": {
 						SchemaProps: spec.SchemaProps{
 							Description: "type of the item.",
@@ -86,7 +94,7 @@ func schema_pkg_apis_playlist_v0alpha1_PlaylistItem(ref common.ReferenceCallback
 					},
 					"value": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Value depends on type and describes the playlist item.\n - dashboard_by_id: The value is an internal numerical identifier set by Grafana. This\n is not portable as the numerical identifier is non-deterministic between different instances.\n Will be replaced by dashboard_by_uid in the future. (deprecated)\n - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All\n dashboards behind the tag will be added to the playlist.\n - dashboard_by_uid: The value is the dashboard UID",
+							Description: "Value depends on type and describes the playlist item.\n - dashboard_by_id: The value is an internal numerical identifier set by Grafana. This\n is not portable as the numerical identifier is non-deterministic between different instances.\n Will be replaced by dashboard_by_uid in the future. (deprecated)\n - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All\n dashboards behind the tag will be added to the playlist.\n - dashboard_by_uid: The value is the dashboard UID\n - dashboard_by_folder: The value is a folder UID. All dashboards in the folder\n will be added to the playlist.\n - dashboard_by_starred: The value is unused. The dashboards starred by the user\n playing the playlist will be added to the playlist.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
@@ -199,6 +207,66 @@ func schema_pkg_apis_playlist_v0alpha1_PlaylistOperatorState(ref common.Referenc
 	}
 }
 
+func schema_pkg_apis_playlist_v0alpha1_PlaylistSchedule(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PlaylistSchedule defines model for PlaylistSchedule.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "End time of the schedule in the 15:04 format. Schedules ending before they start span midnight.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"playlistUid": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The playlist played during the schedule.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start time of the schedule in the 15:04 format.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone of the start and end times. Defaults to UTC.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"weekdays": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Weekdays the schedule starts on, for example \"monday\". The schedule applies to every day when empty.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"endTime", "playlistUid", "startTime"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_playlist_v0alpha1_PlaylistSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -226,6 +294,20 @@ func schema_pkg_apis_playlist_v0alpha1_PlaylistSpec(ref common.ReferenceCallback
 							},
 						},
 					},
+					"schedules": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedules switch to other playlists during parts of the day.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistSchedule"),
+									},
+								},
+							},
+						},
+					},
 					"title": {
 						SchemaProps: spec.SchemaProps{
 							Default: "",
@@ -238,7 +320,7 @@ func schema_pkg_apis_playlist_v0alpha1_PlaylistSpec(ref common.ReferenceCallback
 			},
 		},
 		Dependencies: []string{
-			"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistItem"},
+			"github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistItem", "github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1.PlaylistSchedule"},
 	}
 }
 
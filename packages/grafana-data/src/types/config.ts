@@ -279,5 +279,6 @@ export interface AuthSettings {
 
   disableLogin?: boolean;
   passwordlessEnabled?: boolean;
+  webauthnEnabled?: boolean;
   basicAuthStrongPasswordPolicy?: boolean;
 }
@@ -792,6 +792,17 @@ export const versionedPages = {
         '9.5.0': 'public-dashboard-paused-description',
       },
     },
+    Passphrase: {
+      container: {
+        '11.4.0': 'public-dashboard-passphrase',
+      },
+      input: {
+        '11.4.0': 'data-testid public dashboard passphrase input',
+      },
+      submit: {
+        '11.4.0': 'data-testid public dashboard passphrase submit button',
+      },
+    },
     footer: {
       '11.0.0': 'public-dashboard-footer',
     },
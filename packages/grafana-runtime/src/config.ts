@@ -56,6 +56,8 @@ export type PreinstalledPlugin = {
 
 export class GrafanaBootConfig implements GrafanaConfig {
   publicDashboardAccessToken?: string;
+  /** Passphrase entered by the viewer of a passphrase protected public dashboard */
+  publicDashboardPassphrase?: string;
   publicDashboardsEnabled = true;
   snapshotEnabled = true;
   datasources: { [str: string]: DataSourceInstanceSettings } = {};
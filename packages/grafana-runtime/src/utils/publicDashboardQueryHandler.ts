@@ -38,6 +38,9 @@ export function publicDashboardQueryHandler(request: DataQueryRequest<DataQuery>
       method: 'POST',
       data: body,
       requestId,
+      headers: config.publicDashboardPassphrase
+        ? { 'X-Grafana-Public-Dashboard-Passphrase': config.publicDashboardPassphrase }
+        : undefined,
     })
     .pipe(
       switchMap((raw) => {
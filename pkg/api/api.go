@@ -77,6 +77,12 @@ func (hs *HTTPServer) registerRoutes() {
 	// not logged in views
 	r.Get("/logout", hs.Logout)
 	r.Post("/login", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginPost))
+	if hs.Cfg.MFA.Enabled {
+		r.Post("/login/mfa", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginMFA))
+	}
+	if hs.Cfg.WebAuthn.Enabled {
+		r.Post("/login/webauthn", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginWebAuthn))
+	}
 	r.Get("/login/:name", quota(string(auth.QuotaTargetSrv)), hs.OAuthLogin)
 
 	r.Get("/login", hs.LoginView)
@@ -562,6 +568,8 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// short urls
 		apiRoute.Post("/short-urls", routing.Wrap(hs.createShortURL))
+		apiRoute.Get("/short-urls", reqOrgAdmin, routing.Wrap(hs.searchShortURLs))
+		apiRoute.Delete("/short-urls/:uid", reqOrgAdmin, routing.Wrap(hs.deleteShortURL))
 	}, reqSignedIn)
 
 	// admin api
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	dashboardsV0 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
 	"github.com/grafana/grafana/pkg/components/dashdiffs"
@@ -551,6 +552,12 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	cmd.OrgID = c.SignedInUser.GetOrgID()
 	cmd.UserID = userID
 
+	if cmd.Merge && !cmd.Overwrite {
+		if rsp := hs.mergeDashboard(c, &cmd); rsp != nil {
+			return rsp
+		}
+	}
+
 	dash := cmd.GetDashboardModel()
 	newDashboard := dash.ID == 0
 	if newDashboard {
@@ -579,8 +586,10 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	allowUiUpdate := true
+	syncToFile := false
 	if provisioningData != nil {
 		allowUiUpdate = hs.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
+		syncToFile = hs.ProvisioningService.GetSyncUIUpdatesToFileFromConfig(provisioningData.Name)
 	}
 
 	dashItem := &dashboards.SaveDashboardDTO{
@@ -591,7 +600,17 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		Overwrite: cmd.Overwrite,
 	}
 
-	dashboard, saveErr := hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
+	var dashboard *dashboards.Dashboard
+	var saveErr error
+	if syncToFile {
+		// the provisioning file is the source of truth, the dashboard isn't saved over changes made on disk and
+		// is written to the file as it is saved
+		dashboard, saveErr = hs.ProvisioningService.SaveDashboardToFile(ctx, provisioningData, dash, func() (*dashboards.Dashboard, error) {
+			return hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
+		})
+	} else {
+		dashboard, saveErr = hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
+	}
 
 	if hs.Live != nil {
 		// Tell everyone listening that the dashboard changed
@@ -618,6 +637,11 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	if saveErr != nil {
+		// errors of the provisioning file, such as a conflict with changes made on disk
+		var fileErr errutil.Error
+		if syncToFile && errors.As(saveErr, &fileErr) {
+			return response.Err(saveErr)
+		}
 		return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, saveErr)
 	}
 
@@ -634,7 +658,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"status":    "success",
 		"slug":      dashboard.Slug,
 		"version":   dashboard.Version,
@@ -642,7 +666,19 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
-	})
+	}
+
+	// lint issues are warnings, they never prevent saving the dashboard
+	if hs.dashboardLintService != nil && hs.dashboardLintService.LintOnSave() {
+		lintResult, err := hs.dashboardLintService.Lint(ctx, dashboard.OrgID, dashboard.Data)
+		if err != nil {
+			hs.log.Warn("Failed to lint dashboard", "uid", dashboard.UID, "error", err)
+		} else if len(lintResult.Issues) > 0 {
+			result["lintWarnings"] = lintResult.Issues
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -955,6 +991,69 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 	return response.JSON(http.StatusOK, dashVersionMeta)
 }
 
+// mergeDashboard does a three-way merge of the saved dashboard with the latest version when
+// the dashboard was saved by someone else after the version the changes are based on.
+// Returns a response when the changes can't be merged.
+func (hs *HTTPServer) mergeDashboard(c *contextmodel.ReqContext, cmd *dashboards.SaveDashboardCommand) response.Response {
+	ctx := c.Req.Context()
+	dash := cmd.GetDashboardModel()
+	if dash.UID == "" || dash.Version == 0 {
+		return nil
+	}
+
+	current, err := hs.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dash.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+	if current.Version <= dash.Version {
+		return nil
+	}
+
+	guardian, err := guardian.NewByDashboard(ctx, current, cmd.OrgID, c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	base, err := hs.dashboardVersionService.Get(ctx, &dashver.GetDashboardVersionQuery{
+		OrgID:        cmd.OrgID,
+		DashboardID:  current.ID,
+		DashboardUID: current.UID,
+		Version:      dash.Version,
+	})
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return response.JSON(http.StatusPreconditionFailed, util.DynMap{
+				"status":  "version-mismatch",
+				"message": "Base version of the changes not found, the changes can't be merged",
+			})
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard version", err)
+	}
+
+	merged, conflicts, err := dashdiffs.Merge(base.Data, cmd.Dashboard, current.Data)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to merge dashboard", err)
+	}
+	if len(conflicts) > 0 {
+		return response.JSON(http.StatusConflict, util.DynMap{
+			"status":    "merge-conflict",
+			"message":   "The dashboard has been changed by someone else and the changes conflict",
+			"version":   current.Version,
+			"conflicts": conflicts,
+		})
+	}
+
+	hs.log.Debug("Merged dashboard changes", "uid", current.UID, "baseVersion", dash.Version, "version", current.Version)
+	cmd.Dashboard = merged
+	return nil
+}
+
 // swagger:route POST /dashboards/calculate-diff dashboards calculateDashboardDiff
 //
 // Perform diff on two dashboards.
@@ -1051,7 +1150,7 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
 	}
 
-	if options.DiffType == dashdiffs.DiffDelta {
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffStructural {
 		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
 	}
 
@@ -291,16 +291,17 @@ func (hs *HTTPServer) SearchDashboardSnapshots(c *contextmodel.ReqContext) respo
 	dto := make([]*dashboardsnapshots.DashboardSnapshotDTO, len(searchQueryResult))
 	for i, snapshot := range searchQueryResult {
 		dto[i] = &dashboardsnapshots.DashboardSnapshotDTO{
-			ID:          snapshot.ID,
-			Name:        snapshot.Name,
-			Key:         snapshot.Key,
-			OrgID:       snapshot.OrgID,
-			UserID:      snapshot.UserID,
-			External:    snapshot.External,
-			ExternalURL: snapshot.ExternalURL,
-			Expires:     snapshot.Expires,
-			Created:     snapshot.Created,
-			Updated:     snapshot.Updated,
+			ID:              snapshot.ID,
+			Name:            snapshot.Name,
+			Key:             snapshot.Key,
+			OrgID:           snapshot.OrgID,
+			UserID:          snapshot.UserID,
+			External:        snapshot.External,
+			ExternalURL:     snapshot.ExternalURL,
+			Expires:         snapshot.Expires,
+			Created:         snapshot.Created,
+			Updated:         snapshot.Updated,
+			RefreshInterval: snapshot.RefreshInterval,
 		}
 	}
 
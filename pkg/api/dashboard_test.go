@@ -32,6 +32,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/dashboards/service"
@@ -51,6 +52,7 @@ import (
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/preference/preftest"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	provisioningdashboards "github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -696,6 +698,243 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 	})
 }
 
+func TestDashboardAPIEndpoint_PostDashboardMerge(t *testing.T) {
+	panel := func(id int, title string) map[string]any {
+		return map[string]any{"id": id, "title": title}
+	}
+	base := simplejson.NewFromAny(map[string]any{
+		"uid": "uid", "title": "Dash", "version": 1,
+		"panels": []any{panel(1, "CPU"), panel(2, "Memory")},
+	})
+	current := &dashboards.Dashboard{ID: 2, UID: "uid", Title: "Dash", Version: 2, Data: simplejson.NewFromAny(map[string]any{
+		"uid": "uid", "title": "Dash", "version": 2,
+		"panels": []any{panel(1, "CPU usage"), panel(2, "Memory")},
+	})}
+
+	setup := func(t *testing.T) (*HTTPServer, *dashboards.FakeDashboardService) {
+		origNewGuardian := guardian.New
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+		t.Cleanup(func() {
+			guardian.New = origNewGuardian
+		})
+
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(current, nil)
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersion = &dashver.DashboardVersionDTO{DashboardID: 2, Version: 1, Data: base}
+		return &HTTPServer{
+			Cfg:                          setting.NewCfg(),
+			ProvisioningService:          provisioning.NewProvisioningServiceMock(context.Background()),
+			QuotaService:                 quotatest.New(false, nil),
+			pluginStore:                  &pluginstore.FakePluginStore{},
+			LibraryPanelService:          &mockLibraryPanelService{},
+			DashboardService:             dashboardService,
+			dashboardVersionService:      fakeDashboardVersionService,
+			dashboardProvisioningService: mockDashboardProvisioningService{},
+			Features:                     featuremgmt.WithFeatures(),
+			accesscontrolService:         actest.FakeService{},
+			log:                          log.New("test-logger"),
+			tracer:                       tracing.InitializeTracerForTest(),
+		}, dashboardService
+	}
+
+	post := func(t *testing.T, hs *HTTPServer, cmd dashboards.SaveDashboardCommand) *scenarioContext {
+		sc := setupScenarioContext(t, "/api/dashboards/db")
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &user.SignedInUser{OrgID: 1, UserID: 5}
+			return hs.PostDashboard(c)
+		})
+		sc.m.Post("/api/dashboards/db", sc.defaultHandler)
+		callPostDashboard(sc)
+		return sc
+	}
+
+	t.Run("changes are saved on top of the latest version", func(t *testing.T) {
+		hs, dashboardService := setup(t)
+		dashboardService.On("SaveDashboard", mock.Anything, mock.MatchedBy(func(dto *dashboards.SaveDashboardDTO) bool {
+			panels := dto.Dashboard.Data.Get("panels")
+			return dto.Dashboard.Version == 2 &&
+				panels.GetIndex(0).Get("title").MustString() == "CPU usage" &&
+				panels.GetIndex(1).Get("title").MustString() == "Memory used"
+		}), mock.AnythingOfType("bool")).Return(&dashboards.Dashboard{ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 3}, nil)
+
+		sc := post(t, hs, dashboards.SaveDashboardCommand{
+			OrgID: 1,
+			Merge: true,
+			Dashboard: simplejson.NewFromAny(map[string]any{
+				"uid": "uid", "title": "Dash", "version": 1,
+				"panels": []any{panel(1, "CPU"), panel(2, "Memory used")},
+			}),
+		})
+		require.Equal(t, http.StatusOK, sc.resp.Code, sc.resp.Body.String())
+		assert.Equal(t, int64(3), sc.ToJSON().Get("version").MustInt64())
+	})
+
+	t.Run("conflicting changes are not saved", func(t *testing.T) {
+		hs, _ := setup(t)
+		sc := post(t, hs, dashboards.SaveDashboardCommand{
+			OrgID: 1,
+			Merge: true,
+			Dashboard: simplejson.NewFromAny(map[string]any{
+				"uid": "uid", "title": "Dash", "version": 1,
+				"panels": []any{panel(1, "CPU load"), panel(2, "Memory")},
+			}),
+		})
+		require.Equal(t, http.StatusConflict, sc.resp.Code, sc.resp.Body.String())
+		result := sc.ToJSON()
+		assert.Equal(t, "merge-conflict", result.Get("status").MustString())
+		assert.Equal(t, "panels[id=1].title", result.Get("conflicts").GetIndex(0).Get("path").MustString())
+	})
+}
+
+func TestDashboardAPIEndpoint_PostDashboardLintOnSave(t *testing.T) {
+	origNewGuardian := guardian.New
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+
+	post := func(t *testing.T, lintService dashboardlint.Service) *scenarioContext {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
+			Return(&dashboards.Dashboard{ID: 2, UID: "uid", OrgID: 1, Title: "Dash", Slug: "dash", Version: 1, Data: simplejson.New()}, nil)
+		hs := &HTTPServer{
+			Cfg:                          setting.NewCfg(),
+			ProvisioningService:          provisioning.NewProvisioningServiceMock(context.Background()),
+			QuotaService:                 quotatest.New(false, nil),
+			pluginStore:                  &pluginstore.FakePluginStore{},
+			LibraryPanelService:          &mockLibraryPanelService{},
+			DashboardService:             dashboardService,
+			dashboardProvisioningService: mockDashboardProvisioningService{},
+			dashboardLintService:         lintService,
+			Features:                     featuremgmt.WithFeatures(),
+			accesscontrolService:         actest.FakeService{},
+			log:                          log.New("test-logger"),
+			tracer:                       tracing.InitializeTracerForTest(),
+		}
+
+		cmd := dashboards.SaveDashboardCommand{OrgID: 1, Dashboard: simplejson.NewFromAny(map[string]any{"title": "Dash"})}
+		sc := setupScenarioContext(t, "/api/dashboards/db")
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &user.SignedInUser{OrgID: 1, UserID: 5}
+			return hs.PostDashboard(c)
+		})
+		sc.m.Post("/api/dashboards/db", sc.defaultHandler)
+		callPostDashboard(sc)
+		return sc
+	}
+	issues := []dashboardlint.Issue{{Rule: "too-many-panels", Severity: dashboardlint.SeverityError, Message: "Too many panels"}}
+
+	t.Run("lint issues are returned as warnings", func(t *testing.T) {
+		sc := post(t, &fakeDashboardLintService{lintOnSave: true, issues: issues})
+		require.Equal(t, http.StatusOK, sc.resp.Code, sc.resp.Body.String())
+		warnings := sc.ToJSON().Get("lintWarnings")
+		assert.Equal(t, "too-many-panels", warnings.GetIndex(0).Get("rule").MustString())
+	})
+
+	t.Run("dashboards are not linted when lint on save is disabled", func(t *testing.T) {
+		sc := post(t, &fakeDashboardLintService{lintOnSave: false, issues: issues})
+		require.Equal(t, http.StatusOK, sc.resp.Code, sc.resp.Body.String())
+		_, ok := sc.ToJSON().CheckGet("lintWarnings")
+		assert.False(t, ok)
+	})
+}
+
+func TestDashboardAPIEndpoint_PostDashboardSyncToFile(t *testing.T) {
+	origNewGuardian := guardian.New
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+
+	provisioningData := &dashboards.DashboardProvisioning{DashboardID: 2, Name: "default", ExternalID: "/dashboards/dash.json"}
+	saved := &dashboards.Dashboard{ID: 2, UID: "uid", OrgID: 1, Title: "Dash", Slug: "dash", Version: 2, Data: simplejson.New()}
+
+	post := func(t *testing.T, provisioningService *provisioning.ProvisioningServiceMock, dashboardService dashboards.DashboardService) *scenarioContext {
+		dashboardProvisioningService := dashboards.NewFakeDashboardProvisioning(t)
+		dashboardProvisioningService.On("GetProvisionedDashboardDataByDashboardUID", mock.Anything, int64(1), "uid").Return(provisioningData, nil)
+		provisioningService.GetSyncUIUpdatesToFileFromConfigFunc = func(name string) bool {
+			return name == "default"
+		}
+		hs := &HTTPServer{
+			Cfg:                          setting.NewCfg(),
+			ProvisioningService:          provisioningService,
+			QuotaService:                 quotatest.New(false, nil),
+			pluginStore:                  &pluginstore.FakePluginStore{},
+			LibraryPanelService:          &mockLibraryPanelService{},
+			DashboardService:             dashboardService,
+			dashboardProvisioningService: dashboardProvisioningService,
+			Features:                     featuremgmt.WithFeatures(),
+			accesscontrolService:         actest.FakeService{},
+			log:                          log.New("test-logger"),
+			tracer:                       tracing.InitializeTracerForTest(),
+		}
+
+		cmd := dashboards.SaveDashboardCommand{OrgID: 1, Dashboard: simplejson.NewFromAny(map[string]any{"uid": "uid", "title": "Dash"})}
+		sc := setupScenarioContext(t, "/api/dashboards/db")
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &user.SignedInUser{OrgID: 1, UserID: 5}
+			return hs.PostDashboard(c)
+		})
+		sc.m.Post("/api/dashboards/db", sc.defaultHandler)
+		callPostDashboard(sc)
+		return sc
+	}
+
+	t.Run("saved dashboards are written to their provisioning file", func(t *testing.T) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), true).Return(saved, nil)
+		provisioningService := provisioning.NewProvisioningServiceMock(context.Background())
+		provisioningService.GetAllowUIUpdatesFromConfigFunc = func(name string) bool {
+			return true
+		}
+		var written *dashboards.Dashboard
+		provisioningService.SaveDashboardToFileFunc = func(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error) {
+			written = dash
+			return save()
+		}
+
+		sc := post(t, provisioningService, dashboardService)
+		require.Equal(t, http.StatusOK, sc.resp.Code, sc.resp.Body.String())
+		require.Len(t, provisioningService.Calls.SaveDashboardToFile, 1)
+		assert.Equal(t, "uid", written.UID)
+	})
+
+	t.Run("dashboards are not saved when their provisioning file was changed on disk", func(t *testing.T) {
+		provisioningService := provisioning.NewProvisioningServiceMock(context.Background())
+		provisioningService.SaveDashboardToFileFunc = func(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error) {
+			return nil, provisioningdashboards.ErrDashboardFileConflict.Errorf("changed on disk")
+		}
+
+		// the fake dashboard service fails the test if the dashboard is saved
+		sc := post(t, provisioningService, dashboards.NewFakeDashboardService(t))
+		require.Equal(t, http.StatusConflict, sc.resp.Code, sc.resp.Body.String())
+	})
+}
+
+type fakeDashboardLintService struct {
+	dashboardlint.Service
+	lintOnSave bool
+	issues     []dashboardlint.Issue
+}
+
+func (f *fakeDashboardLintService) LintOnSave() bool {
+	return f.lintOnSave
+}
+
+func (f *fakeDashboardLintService) Lint(_ context.Context, _ int64, _ *simplejson.Json) (*dashboardlint.LintResult, error) {
+	return &dashboardlint.LintResult{Issues: f.issues}, nil
+}
+
 func TestDashboardVersionsAPIEndpoint(t *testing.T) {
 	fakeDash := dashboards.NewDashboard("Child dash")
 
@@ -1038,6 +1277,12 @@ func (s mockDashboardProvisioningService) GetProvisionedDashboardDataByDashboard
 	return nil, nil
 }
 
+func (s mockDashboardProvisioningService) GetProvisionedDashboardDataByDashboardUID(ctx context.Context, orgID int64, dashboardUID string) (
+	*dashboards.DashboardProvisioning, error,
+) {
+	return nil, nil
+}
+
 type mockLibraryPanelService struct{}
 
 var _ librarypanels.Service = (*mockLibraryPanelService)(nil)
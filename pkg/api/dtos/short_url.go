@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 type ShortURL struct {
 	UID string `json:"uid"`
 	URL string `json:"url"`
@@ -7,4 +9,26 @@ type ShortURL struct {
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
+	// Slug is a custom UID for the short URL, unique in the organization.
+	Slug string `json:"slug"`
+	// ExpiresIn is the lifetime of the short URL, e.g. 7d, it does not expire when empty.
+	ExpiresIn string `json:"expiresIn"`
+}
+
+type ShortURLListItem struct {
+	UID        string     `json:"uid"`
+	URL        string     `json:"url"`
+	Path       string     `json:"path"`
+	CreatedBy  int64      `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Hits       int64      `json:"hits"`
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64               `json:"totalCount"`
+	ShortURLs  []*ShortURLListItem `json:"shortUrls"`
+	Page       int                 `json:"page"`
+	PerPage    int                 `json:"perPage"`
 }
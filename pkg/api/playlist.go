@@ -1,10 +1,14 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
-	"k8s.io/apimachinery/pkg/api/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -20,6 +24,7 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/util/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -34,6 +39,7 @@ func (hs *HTTPServer) registerPlaylistAPI(apiRoute routing.RouteRegister) {
 			playlistRoute.Get("/", handler.searchPlaylists)
 			playlistRoute.Get("/:uid", handler.getPlaylist)
 			playlistRoute.Get("/:uid/items", handler.getPlaylistItems)
+			playlistRoute.Get("/:uid/dashboards", handler.getPlaylistDashboards)
 			playlistRoute.Delete("/:uid", handler.deletePlaylist)
 			playlistRoute.Put("/:uid", handler.updatePlaylist)
 			playlistRoute.Post("/", handler.createPlaylist)
@@ -42,6 +48,7 @@ func (hs *HTTPServer) registerPlaylistAPI(apiRoute routing.RouteRegister) {
 			playlistRoute.Get("/", routing.Wrap(hs.SearchPlaylists))
 			playlistRoute.Get("/:uid", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylist))
 			playlistRoute.Get("/:uid/items", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistItems))
+			playlistRoute.Get("/:uid/dashboards", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistDashboards))
 			playlistRoute.Delete("/:uid", middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.DeletePlaylist))
 			playlistRoute.Put("/:uid", middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.UpdatePlaylist))
 			playlistRoute.Post("/", middleware.ReqEditorRole, routing.Wrap(hs.CreatePlaylist))
@@ -143,6 +150,35 @@ func (hs *HTTPServer) GetPlaylistItems(c *contextmodel.ReqContext) response.Resp
 	return response.JSON(http.StatusOK, dto.Items)
 }
 
+// swagger:route GET /playlists/{uid}/dashboards playlists getPlaylistDashboards
+//
+// Get the dashboards of a playlist.
+//
+// Resolves the playlist, or the playlist scheduled at the current time, to the dashboards
+// the user can read, in the order they should be played and with the interval of each dashboard.
+//
+// Responses:
+// 200: getPlaylistDashboardsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetPlaylistDashboards(c *contextmodel.ReqContext) response.Response {
+	getPlaylist := func(ctx context.Context, uid string) (*playlist.PlaylistDTO, error) {
+		return hs.playlistService.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: uid, OrgId: c.SignedInUser.GetOrgID()})
+	}
+
+	dashboards, err := loadPlaylistDashboards(c.Req.Context(), hs.SearchService, c.SignedInUser, getPlaylist, web.Params(c.Req)[":uid"], time.Now())
+	if err != nil {
+		if errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return response.Error(http.StatusNotFound, "Playlist not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to load playlist dashboards", err)
+	}
+
+	return response.JSON(http.StatusOK, dashboards)
+}
+
 // swagger:route DELETE /playlists/{uid} playlists deletePlaylist
 //
 // Delete playlist.
@@ -183,6 +219,9 @@ func (hs *HTTPServer) CreatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	p, err := hs.playlistService.Create(c.Req.Context(), &cmd)
 	if err != nil {
+		if playlist.IsValidationError(err) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to create playlist", err)
 	}
 
@@ -209,6 +248,9 @@ func (hs *HTTPServer) UpdatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	_, err := hs.playlistService.Update(c.Req.Context(), &cmd)
 	if err != nil {
+		if playlist.IsValidationError(err) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to save playlist", err)
 	}
 
@@ -323,6 +365,7 @@ type playlistK8sHandler struct {
 	namespacer           request.NamespaceMapper
 	gvr                  schema.GroupVersionResource
 	clientConfigProvider grafanaapiserver.DirectRestConfigProvider
+	searchService        search.Service
 }
 
 //-----------------------------------------------------------------------------------------
@@ -339,6 +382,7 @@ func newPlaylistK8sHandler(hs *HTTPServer) *playlistK8sHandler {
 		gvr:                  gvr,
 		namespacer:           request.GetNamespaceMapper(hs.Cfg),
 		clientConfigProvider: hs.clientConfigProvider,
+		searchService:        hs.SearchService,
 	}
 }
 
@@ -396,6 +440,27 @@ func (pk8s *playlistK8sHandler) getPlaylistItems(c *contextmodel.ReqContext) {
 	c.JSON(http.StatusOK, internalplaylist.UnstructuredToLegacyPlaylistDTO(*out).Items)
 }
 
+func (pk8s *playlistK8sHandler) getPlaylistDashboards(c *contextmodel.ReqContext) {
+	client, ok := pk8s.getClient(c)
+	if !ok {
+		return // error is already sent
+	}
+	getPlaylist := func(ctx context.Context, uid string) (*playlist.PlaylistDTO, error) {
+		out, err := client.Get(ctx, uid, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return internalplaylist.UnstructuredToLegacyPlaylistDTO(*out), nil
+	}
+
+	dashboards, err := loadPlaylistDashboards(c.Req.Context(), pk8s.searchService, c.SignedInUser, getPlaylist, web.Params(c.Req)[":uid"], time.Now())
+	if err != nil {
+		pk8s.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dashboards)
+}
+
 func (pk8s *playlistK8sHandler) deletePlaylist(c *contextmodel.ReqContext) {
 	client, ok := pk8s.getClient(c)
 	if !ok {
@@ -421,6 +486,13 @@ func (pk8s *playlistK8sHandler) updatePlaylist(c *contextmodel.ReqContext) {
 		c.JsonApiErr(http.StatusBadRequest, "bad request data", err)
 		return
 	}
+	if err := playlist.Validate(uid, cmd.Items, cmd.Schedules); err != nil {
+		c.JsonApiErr(http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !pk8s.validateScheduledPlaylists(c, client, cmd.Schedules) {
+		return // error is already sent
+	}
 	obj := internalplaylist.LegacyUpdateCommandToUnstructured(cmd)
 	obj.SetName(uid)
 	existing, err := client.Get(c.Req.Context(), uid, v1.GetOptions{})
@@ -447,6 +519,13 @@ func (pk8s *playlistK8sHandler) createPlaylist(c *contextmodel.ReqContext) {
 		c.JsonApiErr(http.StatusBadRequest, "bad request data", err)
 		return
 	}
+	if err := playlist.Validate(cmd.UID, cmd.Items, cmd.Schedules); err != nil {
+		c.JsonApiErr(http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !pk8s.validateScheduledPlaylists(c, client, cmd.Schedules) {
+		return // error is already sent
+	}
 	obj := internalplaylist.LegacyUpdateCommandToUnstructured(cmd)
 	out, err := client.Create(c.Req.Context(), &obj, v1.CreateOptions{})
 	if err != nil {
@@ -460,6 +539,23 @@ func (pk8s *playlistK8sHandler) createPlaylist(c *contextmodel.ReqContext) {
 // Utility functions
 //-----------------------------------------------------------------------------------------
 
+// validateScheduledPlaylists checks that the playlists played by the schedules exist.
+func (pk8s *playlistK8sHandler) validateScheduledPlaylists(c *contextmodel.ReqContext, client dynamic.ResourceInterface, schedules []playlist.PlaylistSchedule) bool {
+	for _, schedule := range schedules {
+		_, err := client.Get(c.Req.Context(), schedule.PlaylistUID, v1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			err = fmt.Errorf("%w: scheduled playlist %q not found", playlist.ErrInvalidSchedule, schedule.PlaylistUID)
+			c.JsonApiErr(http.StatusBadRequest, err.Error(), err)
+			return false
+		}
+		if err != nil {
+			pk8s.writeError(c, err)
+			return false
+		}
+	}
+	return true
+}
+
 func (pk8s *playlistK8sHandler) getClient(c *contextmodel.ReqContext) (dynamic.ResourceInterface, bool) {
 	dyn, err := dynamic.NewForConfig(pk8s.clientConfigProvider.GetDirectRestConfig(c))
 	if err != nil {
@@ -471,7 +567,7 @@ func (pk8s *playlistK8sHandler) getClient(c *contextmodel.ReqContext) (dynamic.R
 
 func (pk8s *playlistK8sHandler) writeError(c *contextmodel.ReqContext, err error) {
 	//nolint:errorlint
-	statusError, ok := err.(*errors.StatusError)
+	statusError, ok := err.(*k8serrors.StatusError)
 	if ok {
 		c.JsonApiErr(int(statusError.Status().Code), statusError.Status().Message, err)
 		return
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// maxPlaylistItemDashboards limits the number of dashboards a single playlist item resolves to.
+const maxPlaylistItemDashboards = 1000
+
+type playlistGetter func(ctx context.Context, uid string) (*playlist.PlaylistDTO, error)
+
+// loadPlaylistDashboards resolves the items of the playlist, or of the playlist scheduled at the given
+// time, to the dashboards the user can read. Dashboards matched by several items are played once.
+func loadPlaylistDashboards(ctx context.Context, searchService search.Service, usr *user.SignedInUser, getPlaylist playlistGetter, uid string, now time.Time) (dtos.PlaylistDashboardsSlice, error) {
+	p, err := getPlaylist(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	// scheduled playlists are played as they are, their own schedules are ignored. The playlist plays its
+	// own items when the scheduled playlist was deleted.
+	if schedule := playlist.ActiveSchedule(p.Schedules, now); schedule != nil {
+		scheduled, err := getPlaylist(ctx, schedule.PlaylistUID)
+		switch {
+		case err == nil:
+			p = scheduled
+		case !errors.Is(err, playlist.ErrPlaylistNotFound) && !k8serrors.IsNotFound(err):
+			return nil, err
+		}
+	}
+
+	result := dtos.PlaylistDashboardsSlice{}
+	seen := map[string]bool{}
+	for _, item := range p.Items {
+		query := search.Query{
+			SignedInUser: usr,
+			OrgId:        usr.GetOrgID(),
+			Limit:        maxPlaylistItemDashboards,
+			Type:         string(model.DashHitDB),
+		}
+
+		switch item.Type {
+		case playlist.ItemTypeDashboardByUID:
+			query.DashboardUIDs = []string{item.Value}
+		case playlist.ItemTypeDashboardByID:
+			id, err := strconv.ParseInt(item.Value, 10, 64)
+			if err != nil {
+				continue
+			}
+			query.DashboardIds = []int64{id}
+		case playlist.ItemTypeDashboardByTag:
+			query.Tags = []string{item.Value}
+		case playlist.ItemTypeDashboardByFolder:
+			query.FolderUIDs = []string{item.Value}
+		case playlist.ItemTypeDashboardByStarred:
+			query.IsStarred = true
+		default:
+			continue
+		}
+
+		hits, err := searchService.SearchHandler(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+
+		interval := p.Interval
+		if item.Interval != "" {
+			interval = item.Interval
+		}
+		for _, hit := range hits {
+			if seen[hit.UID] {
+				continue
+			}
+			seen[hit.UID] = true
+			result = append(result, dtos.PlaylistDashboard{
+				Id:       hit.ID,
+				Uid:      hit.UID,
+				Slug:     hit.Slug,
+				Title:    hit.Title,
+				Uri:      hit.URI,
+				Url:      hit.URL,
+				Order:    len(result) + 1,
+				Interval: interval,
+			})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakePlaylistSearchService struct {
+	search.Service
+	queries []search.Query
+}
+
+func (f *fakePlaylistSearchService) SearchHandler(_ context.Context, q *search.Query) (model.HitList, error) {
+	f.queries = append(f.queries, *q)
+
+	switch {
+	case len(q.DashboardUIDs) > 0:
+		return model.HitList{{UID: q.DashboardUIDs[0], Title: q.DashboardUIDs[0]}}, nil
+	case len(q.FolderUIDs) > 0:
+		return model.HitList{{UID: "cpu", Title: "CPU"}, {UID: "memory", Title: "Memory"}}, nil
+	case q.IsStarred:
+		return model.HitList{{UID: "memory", Title: "Memory"}, {UID: "home", Title: "Home"}}, nil
+	}
+	return model.HitList{}, nil
+}
+
+func TestLoadPlaylistDashboards(t *testing.T) {
+	playlists := map[string]*playlist.PlaylistDTO{
+		"lobby": {
+			Uid:      "lobby",
+			Interval: "5m",
+			Items: []playlist.PlaylistItemDTO{
+				{Type: playlist.ItemTypeDashboardByUID, Value: "welcome", Interval: "30s"},
+				{Type: playlist.ItemTypeDashboardByFolder, Value: "ops"},
+				{Type: playlist.ItemTypeDashboardByStarred},
+			},
+			Schedules: []playlist.PlaylistSchedule{
+				{StartTime: "20:00", EndTime: "06:00", PlaylistUID: "night"},
+			},
+		},
+		"night": {
+			Uid:      "night",
+			Interval: "10m",
+			Items: []playlist.PlaylistItemDTO{
+				{Type: playlist.ItemTypeDashboardByUID, Value: "alerts"},
+			},
+		},
+	}
+	getPlaylist := func(_ context.Context, uid string) (*playlist.PlaylistDTO, error) {
+		p, ok := playlists[uid]
+		if !ok {
+			return nil, playlist.ErrPlaylistNotFound
+		}
+		return p, nil
+	}
+	usr := &user.SignedInUser{UserID: 1, OrgID: 2}
+
+	t.Run("resolves the dynamic items to the dashboards of the user", func(t *testing.T) {
+		searchService := &fakePlaylistSearchService{}
+		noon := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+		dashboards, err := loadPlaylistDashboards(context.Background(), searchService, usr, getPlaylist, "lobby", noon)
+		require.NoError(t, err)
+
+		uids := make([]string, 0, len(dashboards))
+		for i, d := range dashboards {
+			uids = append(uids, d.Uid)
+			require.Equal(t, i+1, d.Order)
+		}
+		require.Equal(t, []string{"welcome", "cpu", "memory", "home"}, uids)
+		require.Equal(t, "30s", dashboards[0].Interval)
+		require.Equal(t, "5m", dashboards[1].Interval)
+
+		require.Len(t, searchService.queries, 3)
+		for _, q := range searchService.queries {
+			require.Equal(t, usr, q.SignedInUser)
+			require.Equal(t, int64(2), q.OrgId)
+			require.Equal(t, string(model.DashHitDB), q.Type)
+		}
+		require.Equal(t, []string{"ops"}, searchService.queries[1].FolderUIDs)
+		require.True(t, searchService.queries[2].IsStarred)
+	})
+
+	t.Run("plays the scheduled playlist", func(t *testing.T) {
+		night := time.Date(2024, 3, 15, 23, 0, 0, 0, time.UTC)
+
+		dashboards, err := loadPlaylistDashboards(context.Background(), &fakePlaylistSearchService{}, usr, getPlaylist, "lobby", night)
+		require.NoError(t, err)
+		require.Len(t, dashboards, 1)
+		require.Equal(t, "alerts", dashboards[0].Uid)
+		require.Equal(t, "10m", dashboards[0].Interval)
+	})
+
+	t.Run("plays its own items when the scheduled playlist was deleted", func(t *testing.T) {
+		playlists["evening"] = &playlist.PlaylistDTO{
+			Uid:      "evening",
+			Interval: "5m",
+			Items:    []playlist.PlaylistItemDTO{{Type: playlist.ItemTypeDashboardByUID, Value: "welcome"}},
+			Schedules: []playlist.PlaylistSchedule{
+				{StartTime: "18:00", EndTime: "20:00", PlaylistUID: "deleted"},
+			},
+		}
+		evening := time.Date(2024, 3, 15, 19, 0, 0, 0, time.UTC)
+
+		dashboards, err := loadPlaylistDashboards(context.Background(), &fakePlaylistSearchService{}, usr, getPlaylist, "evening", evening)
+		require.NoError(t, err)
+		require.Len(t, dashboards, 1)
+		require.Equal(t, "welcome", dashboards[0].Uid)
+		require.Equal(t, "5m", dashboards[0].Interval)
+	})
+
+	t.Run("returns an error for unknown playlists", func(t *testing.T) {
+		_, err := loadPlaylistDashboards(context.Background(), &fakePlaylistSearchService{}, usr, getPlaylist, "unknown", time.Now())
+		require.ErrorIs(t, err, playlist.ErrPlaylistNotFound)
+	})
+}
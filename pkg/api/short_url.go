@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -20,13 +23,26 @@ func (hs *HTTPServer) createShortURL(c *contextmodel.ReqContext) response.Respon
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Err(shorturls.ErrShortURLBadRequest.Errorf("bad request data: %w", err))
 	}
-	hs.log.Debug("Received request to create short URL", "path", cmd.Path)
-	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, cmd.Path)
+	hs.log.Debug("Received request to create short URL", "path", cmd.Path, "slug", cmd.Slug)
+
+	createCmd := shorturls.CreateShortURLCommand{
+		Path: cmd.Path,
+		Slug: cmd.Slug,
+	}
+	if cmd.ExpiresIn != "" {
+		expiresIn, err := gtime.ParseDuration(cmd.ExpiresIn)
+		if err != nil || expiresIn <= 0 {
+			return response.Err(shorturls.ErrShortURLInvalidTTL.Errorf("invalid expiry %q", cmd.ExpiresIn))
+		}
+		createCmd.ExpiresIn = expiresIn
+	}
+
+	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, &createCmd)
 	if err != nil {
 		return response.Err(err)
 	}
 
-	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(hs.Cfg.AppURL, "/"), shortURL.Uid, c.SignedInUser.GetOrgID())
+	url := hs.shortURLAbsURL(shortURL.Uid, c.SignedInUser.GetOrgID())
 	c.Logger.Debug("Created short URL", "url", url)
 
 	dto := dtos.ShortURL{
@@ -37,6 +53,63 @@ func (hs *HTTPServer) createShortURL(c *contextmodel.ReqContext) response.Respon
 	return response.JSON(http.StatusOK, dto)
 }
 
+// searchShortURLs handles requests to list the short URLs of the organization.
+func (hs *HTTPServer) searchShortURLs(c *contextmodel.ReqContext) response.Response {
+	query := shorturls.SearchShortURLsQuery{
+		OrgID: c.SignedInUser.GetOrgID(),
+		Query: c.Query("query"),
+		Limit: c.QueryInt("perpage"),
+		Page:  c.QueryInt("page"),
+	}
+
+	result, err := hs.ShortURLService.SearchShortURLs(c.Req.Context(), &query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search short URLs", err)
+	}
+
+	dto := dtos.SearchShortURLsResult{
+		TotalCount: result.TotalCount,
+		ShortURLs:  make([]*dtos.ShortURLListItem, 0, len(result.ShortURLs)),
+		Page:       result.Page,
+		PerPage:    result.PerPage,
+	}
+	for _, shortURL := range result.ShortURLs {
+		item := &dtos.ShortURLListItem{
+			UID:       shortURL.Uid,
+			URL:       hs.shortURLAbsURL(shortURL.Uid, shortURL.OrgId),
+			Path:      shortURL.Path,
+			CreatedBy: shortURL.CreatedBy,
+			CreatedAt: time.Unix(shortURL.CreatedAt, 0),
+			Hits:      shortURL.Hits,
+		}
+		if shortURL.LastSeenAt > 0 {
+			lastSeenAt := time.Unix(shortURL.LastSeenAt, 0)
+			item.LastSeenAt = &lastSeenAt
+		}
+		if shortURL.ExpiresAt > 0 {
+			expiresAt := time.Unix(shortURL.ExpiresAt, 0)
+			item.ExpiresAt = &expiresAt
+		}
+		dto.ShortURLs = append(dto.ShortURLs, item)
+	}
+
+	return response.JSON(http.StatusOK, dto)
+}
+
+// deleteShortURL handles requests to revoke a short URL.
+func (hs *HTTPServer) deleteShortURL(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if err := hs.ShortURLService.DeleteShortURL(c.Req.Context(), c.SignedInUser, uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete short URL", err)
+	}
+
+	return response.Success("Short URL deleted")
+}
+
+func (hs *HTTPServer) shortURLAbsURL(uid string, orgID int64) string {
+	return fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(hs.Cfg.AppURL, "/"), uid, orgID)
+}
+
 func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 	shortURLUID := web.Params(c.Req)[":uid"]
 
@@ -55,7 +128,7 @@ func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 		return
 	}
 
-	// Failure to update LastSeenAt should still allow to redirect
+	// Failure to update LastSeenAt and the hits should still allow to redirect
 	if err := hs.ShortURLService.UpdateLastSeenAt(c.Req.Context(), shortURL); err != nil {
 		hs.log.Error("Failed to update short URL last seen at", "error", err)
 	}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -31,7 +32,7 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 			Path:  cmd.Path,
 		}
 		service := &fakeShortURLService{
-			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 				return createResp, nil
 			},
 		}
@@ -47,6 +48,66 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 				require.Equal(t, fmt.Sprintf("/goto/%s?orgId=%d", createResp.Uid, createResp.OrgId), shortUrl.URL)
 			})
 	})
+
+	t.Run("Given a request for creating a shortUrl with a slug and an expiry", func(t *testing.T) {
+		cmd := dtos.CreateShortURLCmd{
+			Path:      "d/TxKARsmGz/new-dashboard",
+			Slug:      "incident-runbook",
+			ExpiresIn: "7d",
+		}
+
+		var received *shorturls.CreateShortURLCommand
+		service := &fakeShortURLService{
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+				received = cmd
+				return &shorturls.ShortUrl{OrgId: testOrgID, Uid: cmd.Slug, Path: cmd.Path}, nil
+			},
+		}
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, service,
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+
+				require.Equal(t, 200, sc.resp.Code)
+				require.NotNil(t, received)
+				require.Equal(t, "incident-runbook", received.Slug)
+				require.Equal(t, 7*24*time.Hour, received.ExpiresIn)
+			})
+	})
+
+	t.Run("Given a request for creating a shortUrl with an invalid expiry", func(t *testing.T) {
+		cmd := dtos.CreateShortURLCmd{
+			Path:      "d/TxKARsmGz/new-dashboard",
+			ExpiresIn: "soon",
+		}
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, &fakeShortURLService{},
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+
+				require.Equal(t, 400, sc.resp.Code)
+			})
+	})
+
+	t.Run("Given a request for creating a shortUrl with a taken slug", func(t *testing.T) {
+		cmd := dtos.CreateShortURLCmd{
+			Path: "d/TxKARsmGz/new-dashboard",
+			Slug: "incident-runbook",
+		}
+
+		service := &fakeShortURLService{
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+				return nil, shorturls.ErrShortURLSlugTaken.Errorf("slug already exists: %s", cmd.Slug)
+			},
+		}
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, service,
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+
+				require.Equal(t, 409, sc.resp.Code)
+			})
+	})
 }
 
 func callCreateShortURL(sc *scenarioContext) {
@@ -78,16 +139,16 @@ func createShortURLScenario(t *testing.T, desc string, url string, routePattern
 }
 
 type fakeShortURLService struct {
-	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error)
+	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error)
 }
 
 func (s *fakeShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
 	return nil, nil
 }
 
-func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 	if s.createShortURLFunc != nil {
-		return s.createShortURLFunc(ctx, user, path)
+		return s.createShortURLFunc(ctx, user, cmd)
 	}
 
 	return nil, nil
@@ -97,6 +158,14 @@ func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *sh
 	return nil
 }
 
+func (s *fakeShortURLService) SearchShortURLs(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	return &shorturls.SearchShortURLsResult{}, nil
+}
+
+func (s *fakeShortURLService) DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error {
+	return nil
+}
+
 func (s *fakeShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return nil
 }
@@ -169,6 +169,29 @@ var adminCommands = []*cli.Command{
 	},
 }
 
+var dashboardCommands = []*cli.Command{
+	{
+		Name:   "lint",
+		Usage:  "lint <dashboard json files>",
+		Action: runPluginCommand(lintDashboardsCommand),
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "disable",
+				Usage: "Disable a lint rule, can be repeated",
+			},
+			&cli.StringFlag{
+				Name:  "rules-config",
+				Usage: "Path to a JSON file configuring the lint rules",
+			},
+			&cli.BoolFlag{
+				Name:  "fail-on-warnings",
+				Usage: "Exit with an error when warnings are found",
+				Value: false,
+			},
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -180,4 +203,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "dashboards",
+		Usage:       "Dashboard commands",
+		Subcommands: dashboardCommands,
+	},
 }
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+)
+
+var (
+	errMissingDashboardFiles = errors.New("missing dashboard files to lint")
+	errLintIssuesFound       = errors.New("dashboard lint found issues")
+)
+
+// lintDashboardsCommand lints dashboard JSON files with the built-in rules, without a running Grafana server.
+func lintDashboardsCommand(c utils.CommandLine) error {
+	paths := c.Args().Slice()
+	if len(paths) == 0 {
+		return errMissingDashboardFiles
+	}
+
+	configs, err := readLintRulesConfig(c.String("rules-config"))
+	if err != nil {
+		return err
+	}
+	for _, name := range c.StringSlice("disable") {
+		configs[name] = dashboardlint.RuleConfig{Enabled: false}
+	}
+
+	results, err := lintDashboardFiles(dashboardlint.NewLinter(dashboardlint.DefaultRules()...), paths, configs)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, path := range paths {
+		issues := results[path]
+		if len(issues) == 0 {
+			logger.Infof("%s %s\n", color.GreenString("✔"), path)
+			continue
+		}
+
+		logger.Infof("%s %s\n", color.RedString("✗"), path)
+		for _, issue := range issues {
+			location := ""
+			if issue.PanelTitle != "" || issue.PanelID != 0 {
+				location = fmt.Sprintf(" (panel %d %q)", issue.PanelID, issue.PanelTitle)
+			}
+			severity := color.YellowString(string(issue.Severity))
+			if issue.Severity == dashboardlint.SeverityError {
+				severity = color.RedString(string(issue.Severity))
+			}
+			logger.Infof("  %s %s: %s%s\n", severity, issue.Rule, issue.Message, location)
+		}
+
+		if dashboardlint.HasErrors(issues) || c.Bool("fail-on-warnings") {
+			failed = true
+		}
+	}
+
+	if failed {
+		return errLintIssuesFound
+	}
+	return nil
+}
+
+// lintDashboardFiles lints the dashboard JSON files and returns the issues by path. Files exported
+// from the dashboard API, with the dashboard under the "dashboard" key, are supported.
+func lintDashboardFiles(linter *dashboardlint.Linter, paths []string, configs map[string]dashboardlint.RuleConfig) (map[string][]dashboardlint.Issue, error) {
+	results := make(map[string][]dashboardlint.Issue, len(paths))
+	for _, path := range paths {
+		// nolint:gosec
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dashboard %s: %w", path, err)
+		}
+		dashboard, err := simplejson.NewJson(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse dashboard %s: %w", path, err)
+		}
+		if inner, ok := dashboard.CheckGet("dashboard"); ok {
+			dashboard = inner
+		}
+
+		results[path] = linter.Lint(dashboard, configs)
+	}
+	return results, nil
+}
+
+// readLintRulesConfig reads the rule configurations from a JSON file mapping rule names to
+// their configuration, e.g. {"too-many-panels": {"enabled": true, "severity": "error", "settings": {"maxPanels": 30}}}.
+func readLintRulesConfig(path string) (map[string]dashboardlint.RuleConfig, error) {
+	configs := map[string]dashboardlint.RuleConfig{}
+	if path == "" {
+		return configs, nil
+	}
+
+	// nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lint rules config: %w", err)
+	}
+	rules, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse lint rules config: %w", err)
+	}
+
+	for name := range rules.MustMap() {
+		rule := rules.Get(name)
+		cfg := dashboardlint.RuleConfig{
+			Enabled:  rule.Get("enabled").MustBool(true),
+			Severity: dashboardlint.Severity(rule.Get("severity").MustString()),
+		}
+		if settings, ok := rule.CheckGet("settings"); ok {
+			cfg.Settings = settings
+		}
+		configs[name] = cfg
+	}
+	return configs, nil
+}
@@ -0,0 +1,197 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Conflict is a value changed differently in both merged versions.
+type Conflict struct {
+	Path   string `json:"path"`
+	Base   any    `json:"base,omitempty"`
+	Ours   any    `json:"ours,omitempty"`
+	Theirs any    `json:"theirs,omitempty"`
+}
+
+// absent marks a value missing in one of the merged versions.
+type absentValue struct{}
+
+var absent any = absentValue{}
+
+// Merge does a three-way merge of dashboard models. Base is the version both ours
+// and theirs started from, theirs is the latest saved version. Changes of both
+// sides are combined, panels, queries, template variables and annotations are
+// matched by their identity so that concurrent edits of different list items
+// merge cleanly. The result has the version of theirs so it can be saved on top
+// of it. When the same value is changed differently by both sides, ours is kept
+// in the result and a conflict is returned.
+func Merge(base, ours, theirs *simplejson.Json) (*simplejson.Json, []Conflict, error) {
+	m := &merger{}
+	b, o, t := copyObject(base), copyObject(ours), copyObject(theirs)
+	version := t["version"]
+	delete(b, "version")
+	delete(o, "version")
+	delete(t, "version")
+
+	merged := m.mergeObjects("", "", b, o, t)
+	if version != nil {
+		merged["version"] = version
+	}
+
+	// Round trip to get a model with the same value types as a decoded request.
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, m.conflicts, nil
+}
+
+type merger struct {
+	conflicts []Conflict
+}
+
+// merge returns the merged value, absent when the value is deleted.
+func (m *merger) merge(path, field, parentField string, b, o, t any) any {
+	switch {
+	case reflect.DeepEqual(o, t):
+		return o
+	case reflect.DeepEqual(b, o):
+		return t
+	case reflect.DeepEqual(b, t):
+		return o
+	}
+
+	oObj, oOK := o.(map[string]any)
+	tObj, tOK := t.(map[string]any)
+	if oOK && tOK {
+		bObj, ok := b.(map[string]any)
+		if !ok {
+			bObj = map[string]any{}
+		}
+		return m.mergeObjects(path, field, bObj, oObj, tObj)
+	}
+
+	if key := listKey(field, parentField); key != "" {
+		if merged, ok := m.mergeLists(path, key, b, o, t); ok {
+			return merged
+		}
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{Path: path, Base: presentOrNil(b), Ours: presentOrNil(o), Theirs: presentOrNil(t)})
+	return o
+}
+
+func (m *merger) mergeObjects(path, field string, b, o, t map[string]any) map[string]any {
+	result := map[string]any{}
+	for _, key := range unionKeys(unionObject(b, o), t) {
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		v := m.merge(childPath, key, field, valueOrAbsent(b, key), valueOrAbsent(o, key), valueOrAbsent(t, key))
+		if v != absent {
+			result[key] = v
+		}
+	}
+	return result
+}
+
+// mergeLists merges lists of objects by their identity. Items keep the order of
+// theirs, items added by ours are appended. Returns false when any of the lists
+// can't be indexed by the key.
+func (m *merger) mergeLists(path, key string, b, o, t any) ([]any, bool) {
+	index := func(v any) (*keyedList, bool) {
+		if v == absent {
+			return indexList(nil, key)
+		}
+		return indexList(v, key)
+	}
+	bl, ok := index(b)
+	if !ok {
+		return nil, false
+	}
+	ol, ok := index(o)
+	if !ok {
+		return nil, false
+	}
+	tl, ok := index(t)
+	if !ok {
+		return nil, false
+	}
+
+	keys := append([]string{}, tl.keys...)
+	for _, k := range ol.keys {
+		if _, ok := tl.items[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	result := []any{}
+	for _, k := range keys {
+		v := m.merge(itemPath(path, key, k), "", "", itemOrAbsent(bl, k), itemOrAbsent(ol, k), itemOrAbsent(tl, k))
+		if v != absent {
+			result = append(result, v)
+		}
+	}
+	return result, true
+}
+
+// listKey returns the identity field of list items, empty when the list is merged as a whole.
+func listKey(field, parentField string) string {
+	switch {
+	case field == "panels":
+		return "id"
+	case field == "targets":
+		return "refId"
+	case field == "list" && (parentField == "templating" || parentField == "annotations"):
+		return "name"
+	}
+	return ""
+}
+
+func copyObject(j *simplejson.Json) map[string]any {
+	obj := asObject(j.Interface())
+	c := make(map[string]any, len(obj))
+	for k, v := range obj {
+		c[k] = v
+	}
+	return c
+}
+
+func unionObject(a, b map[string]any) map[string]any {
+	u := make(map[string]any, len(a)+len(b))
+	for k, v := range a {
+		u[k] = v
+	}
+	for k, v := range b {
+		u[k] = v
+	}
+	return u
+}
+
+func valueOrAbsent(obj map[string]any, key string) any {
+	if v, ok := obj[key]; ok {
+		return v
+	}
+	return absent
+}
+
+func itemOrAbsent(l *keyedList, key string) any {
+	if v, ok := l.items[key]; ok {
+		return v
+	}
+	return absent
+}
+
+func presentOrNil(v any) any {
+	if v == absent {
+		return nil
+	}
+	return v
+}
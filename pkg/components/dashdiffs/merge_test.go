@@ -0,0 +1,68 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	t.Run("concurrent changes of different items are merged", func(t *testing.T) {
+		base := mustJSON(t, baseDashboardJSON)
+
+		ours := mustJSON(t, baseDashboardJSON)
+		ours.Get("panels").GetIndex(0).Set("title", "CPU usage")
+		ours.Set("panels", append(ours.Get("panels").MustArray(), map[string]any{"id": 5, "title": "Network"}))
+		ours.SetPath([]string{"templating", "list"}, []any{map[string]any{"name": "env", "query": "prod"}})
+
+		theirs := mustJSON(t, baseDashboardJSON)
+		theirs.Set("version", 5)
+		theirs.Get("panels").GetIndex(1).Get("targets").GetIndex(0).Set("expr", "memory_used")
+		theirs.Set("tags", []any{"team-a"})
+
+		merged, conflicts, err := Merge(base, ours, theirs)
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+
+		require.Equal(t, int64(5), merged.Get("version").MustInt64())
+		require.Equal(t, []string{"team-a"}, merged.Get("tags").MustStringArray())
+		panels := merged.Get("panels")
+		require.Len(t, panels.MustArray(), 4)
+		require.Equal(t, "CPU usage", panels.GetIndex(0).Get("title").MustString())
+		require.Equal(t, "memory_used", panels.GetIndex(1).Get("targets").GetIndex(0).Get("expr").MustString())
+		require.Equal(t, "Network", panels.GetIndex(3).Get("title").MustString())
+		require.Len(t, merged.GetPath("templating", "list").MustArray(), 1)
+	})
+
+	t.Run("same value changed by both sides is a conflict", func(t *testing.T) {
+		base := mustJSON(t, baseDashboardJSON)
+		ours := mustJSON(t, baseDashboardJSON)
+		ours.Get("panels").GetIndex(0).Get("targets").GetIndex(0).Set("expr", "ours")
+		theirs := mustJSON(t, baseDashboardJSON)
+		theirs.Get("panels").GetIndex(0).Get("targets").GetIndex(0).Set("expr", "theirs")
+
+		merged, conflicts, err := Merge(base, ours, theirs)
+		require.NoError(t, err)
+		require.Equal(t, []Conflict{{
+			Path:   "panels[id=1].targets[refId=A].expr",
+			Base:   "cpu",
+			Ours:   "ours",
+			Theirs: "theirs",
+		}}, conflicts)
+		require.Equal(t, "ours", merged.Get("panels").GetIndex(0).Get("targets").GetIndex(0).Get("expr").MustString())
+	})
+
+	t.Run("item removed by one side and changed by the other is a conflict", func(t *testing.T) {
+		base := mustJSON(t, baseDashboardJSON)
+		ours := mustJSON(t, baseDashboardJSON)
+		ours.Set("panels", ours.Get("panels").MustArray()[1:])
+		theirs := mustJSON(t, baseDashboardJSON)
+		theirs.Get("panels").GetIndex(0).Set("title", "CPU usage")
+
+		_, conflicts, err := Merge(base, ours, theirs)
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		require.Equal(t, "panels[id=1]", conflicts[0].Path)
+		require.Nil(t, conflicts[0].Ours)
+	})
+}
@@ -0,0 +1,232 @@
+package dashdiffs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type StructuralChangeType string
+
+const (
+	StructuralDashboardChanged StructuralChangeType = "dashboard-changed"
+	StructuralPanelAdded       StructuralChangeType = "panel-added"
+	StructuralPanelRemoved     StructuralChangeType = "panel-removed"
+	StructuralPanelMoved       StructuralChangeType = "panel-moved"
+	StructuralPanelChanged     StructuralChangeType = "panel-changed"
+	StructuralQueryAdded       StructuralChangeType = "query-added"
+	StructuralQueryRemoved     StructuralChangeType = "query-removed"
+	StructuralQueryChanged     StructuralChangeType = "query-changed"
+	StructuralVariableAdded    StructuralChangeType = "variable-added"
+	StructuralVariableRemoved  StructuralChangeType = "variable-removed"
+	StructuralVariableChanged  StructuralChangeType = "variable-changed"
+)
+
+// StructuralChange is a single semantic change between two dashboard versions.
+type StructuralChange struct {
+	Type StructuralChangeType `json:"type"`
+	// Path of the changed value, list items are addressed by their identity, e.g. panels[id=2].targets[refId=A].
+	Path    string `json:"path"`
+	PanelID any    `json:"panelId,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Before  any    `json:"before,omitempty"`
+	After   any    `json:"after,omitempty"`
+}
+
+// Fields compared separately or not interesting for the structural diff.
+var ignoredDashboardFields = map[string]bool{
+	"id":         true,
+	"version":    true,
+	"panels":     true,
+	"templating": true,
+}
+
+// StructuralDiff returns semantic changes between two dashboard models. Panels are
+// matched by id (panels nested in collapsed rows included), queries by refId and
+// template variables by name, so reordering is not reported as a change.
+func StructuralDiff(baseData, newData *simplejson.Json) []StructuralChange {
+	changes := []StructuralChange{}
+
+	base := asObject(baseData.Interface())
+	next := asObject(newData.Interface())
+	for _, key := range unionKeys(base, next) {
+		if ignoredDashboardFields[key] || reflect.DeepEqual(base[key], next[key]) {
+			continue
+		}
+		changes = append(changes, StructuralChange{Type: StructuralDashboardChanged, Path: key, Before: base[key], After: next[key]})
+	}
+
+	changes = append(changes, diffPanels(flattenPanels(base["panels"]), flattenPanels(next["panels"]))...)
+	changes = append(changes, diffVariables(baseData.GetPath("templating", "list").Interface(), newData.GetPath("templating", "list").Interface())...)
+	return changes
+}
+
+func diffPanels(base, next *keyedList) []StructuralChange {
+	var changes []StructuralChange
+	for _, id := range base.keys {
+		if _, ok := next.items[id]; !ok {
+			p := base.items[id]
+			changes = append(changes, StructuralChange{Type: StructuralPanelRemoved, Path: itemPath("panels", "id", id), PanelID: p["id"], Title: stringValue(p["title"]), Before: p})
+		}
+	}
+	for _, id := range next.keys {
+		p := next.items[id]
+		path := itemPath("panels", "id", id)
+		old, ok := base.items[id]
+		if !ok {
+			changes = append(changes, StructuralChange{Type: StructuralPanelAdded, Path: path, PanelID: p["id"], Title: stringValue(p["title"]), After: p})
+			continue
+		}
+		for _, key := range unionKeys(old, p) {
+			if reflect.DeepEqual(old[key], p[key]) || key == "panels" {
+				continue
+			}
+			switch key {
+			case "gridPos":
+				changes = append(changes, StructuralChange{Type: StructuralPanelMoved, Path: path + ".gridPos", PanelID: p["id"], Title: stringValue(p["title"]), Before: old[key], After: p[key]})
+			case "targets":
+				changes = append(changes, diffQueries(path, p, old[key], p[key])...)
+			default:
+				changes = append(changes, StructuralChange{Type: StructuralPanelChanged, Path: path + "." + key, PanelID: p["id"], Title: stringValue(p["title"]), Before: old[key], After: p[key]})
+			}
+		}
+	}
+	return changes
+}
+
+func diffQueries(panelPath string, panel map[string]any, baseTargets, newTargets any) []StructuralChange {
+	base, okBase := indexList(baseTargets, "refId")
+	next, okNext := indexList(newTargets, "refId")
+	path := panelPath + ".targets"
+	if !okBase || !okNext {
+		return []StructuralChange{{Type: StructuralQueryChanged, Path: path, PanelID: panel["id"], Title: stringValue(panel["title"]), Before: baseTargets, After: newTargets}}
+	}
+	var changes []StructuralChange
+	for _, refID := range base.keys {
+		if _, ok := next.items[refID]; !ok {
+			changes = append(changes, StructuralChange{Type: StructuralQueryRemoved, Path: itemPath(path, "refId", refID), PanelID: panel["id"], Title: stringValue(panel["title"]), Before: base.items[refID]})
+		}
+	}
+	for _, refID := range next.keys {
+		old, ok := base.items[refID]
+		switch {
+		case !ok:
+			changes = append(changes, StructuralChange{Type: StructuralQueryAdded, Path: itemPath(path, "refId", refID), PanelID: panel["id"], Title: stringValue(panel["title"]), After: next.items[refID]})
+		case !reflect.DeepEqual(old, next.items[refID]):
+			changes = append(changes, StructuralChange{Type: StructuralQueryChanged, Path: itemPath(path, "refId", refID), PanelID: panel["id"], Title: stringValue(panel["title"]), Before: old, After: next.items[refID]})
+		}
+	}
+	return changes
+}
+
+func diffVariables(baseList, newList any) []StructuralChange {
+	base, okBase := indexList(baseList, "name")
+	next, okNext := indexList(newList, "name")
+	if !okBase || !okNext {
+		return []StructuralChange{{Type: StructuralVariableChanged, Path: "templating.list", Before: baseList, After: newList}}
+	}
+	var changes []StructuralChange
+	for _, name := range base.keys {
+		if _, ok := next.items[name]; !ok {
+			changes = append(changes, StructuralChange{Type: StructuralVariableRemoved, Path: itemPath("templating.list", "name", name), Title: name, Before: base.items[name]})
+		}
+	}
+	for _, name := range next.keys {
+		old, ok := base.items[name]
+		switch {
+		case !ok:
+			changes = append(changes, StructuralChange{Type: StructuralVariableAdded, Path: itemPath("templating.list", "name", name), Title: name, After: next.items[name]})
+		case !reflect.DeepEqual(old, next.items[name]):
+			changes = append(changes, StructuralChange{Type: StructuralVariableChanged, Path: itemPath("templating.list", "name", name), Title: name, Before: old, After: next.items[name]})
+		}
+	}
+	return changes
+}
+
+// keyedList is a JSON array of objects indexed by an identity field.
+type keyedList struct {
+	keys  []string
+	items map[string]map[string]any
+}
+
+// indexList indexes list items by the key field. Returns false when the value is not
+// a list of objects with unique keys, such lists can only be compared as a whole.
+func indexList(v any, key string) (*keyedList, bool) {
+	l := &keyedList{items: map[string]map[string]any{}}
+	if v == nil {
+		return l, true
+	}
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	for _, item := range arr {
+		obj, ok := item.(map[string]any)
+		if !ok || obj[key] == nil {
+			return nil, false
+		}
+		k := fmt.Sprint(obj[key])
+		if _, exists := l.items[k]; exists {
+			return nil, false
+		}
+		l.keys = append(l.keys, k)
+		l.items[k] = obj
+	}
+	return l, true
+}
+
+// flattenPanels indexes panels by id including panels of collapsed rows.
+func flattenPanels(v any) *keyedList {
+	l := &keyedList{items: map[string]map[string]any{}}
+	var walk func(v any)
+	walk = func(v any) {
+		arr, _ := v.([]any)
+		for _, item := range arr {
+			obj, ok := item.(map[string]any)
+			if !ok || obj["id"] == nil {
+				continue
+			}
+			k := fmt.Sprint(obj["id"])
+			if _, exists := l.items[k]; !exists {
+				l.keys = append(l.keys, k)
+			}
+			l.items[k] = obj
+			walk(obj["panels"])
+		}
+	}
+	walk(v)
+	return l
+}
+
+func itemPath(path, key, value string) string {
+	return fmt.Sprintf("%s[%s=%s]", path, key, value)
+}
+
+func asObject(v any) map[string]any {
+	obj, _ := v.(map[string]any)
+	if obj == nil {
+		return map[string]any{}
+	}
+	return obj
+}
+
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringValue(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,74 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const baseDashboardJSON = `{
+	"id": 1,
+	"version": 3,
+	"title": "Services",
+	"panels": [
+		{"id": 1, "title": "CPU", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+		 "targets": [{"refId": "A", "expr": "cpu"}, {"refId": "B", "expr": "load"}]},
+		{"id": 2, "title": "Memory", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8},
+		 "targets": [{"refId": "A", "expr": "memory"}]},
+		{"id": 3, "title": "Row", "type": "row", "collapsed": true, "panels": [
+			{"id": 4, "title": "Disk", "type": "stat", "gridPos": {"x": 0, "y": 9, "w": 6, "h": 4}}
+		]}
+	],
+	"templating": {"list": [{"name": "env", "query": "prod"}, {"name": "host", "query": "*"}]}
+}`
+
+func mustJSON(t *testing.T, s string) *simplejson.Json {
+	t.Helper()
+	j, err := simplejson.NewJson([]byte(s))
+	require.NoError(t, err)
+	return j
+}
+
+func TestStructuralDiff(t *testing.T) {
+	base := mustJSON(t, baseDashboardJSON)
+
+	next := mustJSON(t, baseDashboardJSON)
+	next.Set("version", 4)
+	next.Set("title", "All services")
+	panels := next.Get("panels")
+	// Reordering panels and queries is not a change.
+	cpu, memory, row := panels.GetIndex(0), panels.GetIndex(1), panels.GetIndex(2)
+	cpu.Set("targets", []any{
+		map[string]any{"refId": "B", "expr": "load"},
+		map[string]any{"refId": "C", "expr": "cpu_total"},
+	})
+	memory.SetPath([]string{"gridPos", "y"}, 8)
+	row.Get("panels").GetIndex(0).Set("title", "Disk usage")
+	next.Set("panels", []any{memory.Interface(), cpu.Interface(), row.Interface(),
+		map[string]any{"id": 5, "title": "Network", "type": "timeseries"}})
+	next.SetPath([]string{"templating", "list"}, []any{
+		map[string]any{"name": "env", "query": "dev"},
+		map[string]any{"name": "region", "query": "*"},
+	})
+
+	changes := StructuralDiff(base, next)
+	summary := make([]string, 0, len(changes))
+	for _, c := range changes {
+		summary = append(summary, string(c.Type)+" "+c.Path)
+	}
+	require.Equal(t, []string{
+		"dashboard-changed title",
+		"panel-moved panels[id=2].gridPos",
+		"query-removed panels[id=1].targets[refId=A]",
+		"query-added panels[id=1].targets[refId=C]",
+		"panel-changed panels[id=4].title",
+		"panel-added panels[id=5]",
+		"variable-removed templating.list[name=host]",
+		"variable-changed templating.list[name=env]",
+		"variable-added templating.list[name=region]",
+	}, summary)
+
+	require.Empty(t, StructuralDiff(base, base))
+}
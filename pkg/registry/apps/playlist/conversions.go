@@ -21,18 +21,41 @@ import (
 func LegacyUpdateCommandToUnstructured(cmd playlistsvc.UpdatePlaylistCommand) unstructured.Unstructured {
 	items := []map[string]string{}
 	for _, item := range cmd.Items {
-		items = append(items, map[string]string{
+		v := map[string]string{
 			"type":  item.Type,
 			"value": item.Value,
-		})
+		}
+		if item.Interval != "" {
+			v["interval"] = item.Interval
+		}
+		items = append(items, v)
+	}
+	spec := map[string]interface{}{
+		"title":    cmd.Name,
+		"interval": cmd.Interval,
+		"items":    items,
+	}
+	if len(cmd.Schedules) > 0 {
+		schedules := []map[string]interface{}{}
+		for _, schedule := range cmd.Schedules {
+			v := map[string]interface{}{
+				"startTime":   schedule.StartTime,
+				"endTime":     schedule.EndTime,
+				"playlistUid": schedule.PlaylistUID,
+			}
+			if len(schedule.Weekdays) > 0 {
+				v["weekdays"] = schedule.Weekdays
+			}
+			if schedule.Timezone != "" {
+				v["timezone"] = schedule.Timezone
+			}
+			schedules = append(schedules, v)
+		}
+		spec["schedules"] = schedules
 	}
 	obj := unstructured.Unstructured{
 		Object: map[string]interface{}{
-			"spec": map[string]interface{}{
-				"title":    cmd.Name,
-				"interval": cmd.Interval,
-				"items":    items,
-			},
+			"spec": spec,
 		},
 	}
 	if cmd.UID == "" {
@@ -67,6 +90,13 @@ func UnstructuredToLegacyPlaylistDTO(item unstructured.Unstructured) *playlistsv
 			_ = json.Unmarshal(b, &dto.Items)
 		}
 	}
+	schedules := spec["schedules"]
+	if schedules != nil {
+		b, err := json.Marshal(schedules)
+		if err == nil {
+			_ = json.Unmarshal(b, &dto.Schedules)
+		}
+	}
 	return dto
 }
 
@@ -76,10 +106,26 @@ func convertToK8sResource(v *playlistsvc.PlaylistDTO, namespacer request.Namespa
 		Interval: v.Interval,
 	}
 	for _, item := range v.Items {
-		spec.Items = append(spec.Items, playlist.PlaylistItem{
+		i := playlist.PlaylistItem{
 			Type:  playlist.PlaylistItemType(item.Type),
 			Value: item.Value,
-		})
+		}
+		if item.Interval != "" {
+			i.Interval = &item.Interval
+		}
+		spec.Items = append(spec.Items, i)
+	}
+	for _, schedule := range v.Schedules {
+		s := playlist.PlaylistSchedule{
+			Weekdays:    schedule.Weekdays,
+			StartTime:   schedule.StartTime,
+			EndTime:     schedule.EndTime,
+			PlaylistUid: schedule.PlaylistUID,
+		}
+		if schedule.Timezone != "" {
+			s.Timezone = &schedule.Timezone
+		}
+		spec.Schedules = append(spec.Schedules, s)
 	}
 
 	p := &playlist.Playlist{
@@ -121,10 +167,26 @@ func convertToLegacyUpdateCommand(p *playlist.Playlist, orgId int64) (*playlists
 		if item.Type == playlist.PlaylistItemTypeDashboardById {
 			return nil, fmt.Errorf("unsupported item type: %s", item.Type)
 		}
-		cmd.Items = append(cmd.Items, playlistsvc.PlaylistItem{
+		i := playlistsvc.PlaylistItem{
 			Type:  string(item.Type),
 			Value: item.Value,
-		})
+		}
+		if item.Interval != nil {
+			i.Interval = *item.Interval
+		}
+		cmd.Items = append(cmd.Items, i)
+	}
+	for _, schedule := range spec.Schedules {
+		s := playlistsvc.PlaylistSchedule{
+			Weekdays:    schedule.Weekdays,
+			StartTime:   schedule.StartTime,
+			EndTime:     schedule.EndTime,
+			PlaylistUID: schedule.PlaylistUid,
+		}
+		if schedule.Timezone != nil {
+			s.Timezone = *schedule.Timezone
+		}
+		cmd.Schedules = append(cmd.Schedules, s)
 	}
 	return cmd, nil
 }
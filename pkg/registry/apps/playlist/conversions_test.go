@@ -69,3 +69,46 @@ func TestPlaylistConversion(t *testing.T) {
 		"status": {}
 	  }`, string(out))
 }
+
+func TestPlaylistConversionWithSchedules(t *testing.T) {
+	src := &playlist.PlaylistDTO{
+		OrgID:    3,
+		Uid:      "lobby",
+		Name:     "Lobby",
+		Interval: "5m",
+		Items: []playlist.PlaylistItemDTO{
+			{Type: "dashboard_by_folder", Value: "ops", Interval: "1m"},
+			{Type: "dashboard_by_starred"},
+		},
+		Schedules: []playlist.PlaylistSchedule{
+			{StartTime: "09:00", EndTime: "18:00", Weekdays: []string{"monday"}, Timezone: "Europe/Paris", PlaylistUID: "day"},
+			{StartTime: "18:00", EndTime: "09:00", PlaylistUID: "night"},
+		},
+	}
+	dst := convertToK8sResource(src, request.GetNamespaceMapper(nil))
+
+	out, err := json.Marshal(dst.Spec)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"title": "Lobby",
+		"interval": "5m",
+		"items": [
+			{"type": "dashboard_by_folder", "value": "ops", "interval": "1m"},
+			{"type": "dashboard_by_starred", "value": ""}
+		],
+		"schedules": [
+			{"startTime": "09:00", "endTime": "18:00", "weekdays": ["monday"], "timezone": "Europe/Paris", "playlistUid": "day"},
+			{"startTime": "18:00", "endTime": "09:00", "playlistUid": "night"}
+		]
+	}`, string(out))
+
+	cmd, err := convertToLegacyUpdateCommand(dst, 3)
+	require.NoError(t, err)
+	require.Equal(t, "1m", cmd.Items[0].Interval)
+	require.Equal(t, src.Schedules, cmd.Schedules)
+
+	obj := LegacyUpdateCommandToUnstructured(*cmd)
+	dto := UnstructuredToLegacyPlaylistDTO(obj)
+	require.Equal(t, src.Items, dto.Items)
+	require.Equal(t, src.Schedules, dto.Schedules)
+}
@@ -30,12 +30,15 @@ const (
 	ClientProxy        = "auth.client.proxy"
 	ClientSAML         = "auth.client.saml"
 	ClientPasswordless = "auth.client.passwordless"
+	ClientMFA          = "auth.client.mfa"
+	ClientWebAuthn     = "auth.client.webauthn"
 )
 
 const (
 	MetaKeyUsername            = "username"
 	MetaKeyAuthModule          = "authModule"
 	MetaKeyIsLogin             = "isLogin"
+	MetaKeyIsBasicAuth         = "isBasicAuth"
 	defaultRedirectToCookieKey = "redirect_to"
 )
 
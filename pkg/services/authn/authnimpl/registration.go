@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -24,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/rendering"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -41,6 +43,7 @@ func ProvideRegistration(
 	socialService social.Service, cache *remotecache.RemoteCache,
 	ldapService service.LDAP, settingsProviderService setting.Provider,
 	tracer tracing.Tracer, tempUserService tempuser.Service, notificationService notifications.Service,
+	mfaService mfa.Service, webauthnService webauthn.Service,
 ) Registration {
 	logger := log.New("authn.registration")
 
@@ -70,7 +73,7 @@ func ProvideRegistration(
 
 	// if we have password clients configure check if basic auth or form auth is enabled
 	if len(passwordClients) > 0 {
-		passwordClient := clients.ProvidePassword(loginAttempts, passwordClients...)
+		passwordClient := clients.ProvidePassword(cfg, loginAttempts, passwordClients...)
 		if cfg.BasicAuthEnabled {
 			authnSvc.RegisterClient(clients.ProvideBasic(passwordClient))
 		}
@@ -80,6 +83,23 @@ func ProvideRegistration(
 		}
 	}
 
+	// security keys and passkeys log users in without a password or complete a login challenged by the MFA client
+	var webauthnSecondFactor webauthn.Service
+	if cfg.WebAuthn.Enabled {
+		authnSvc.RegisterClient(clients.ProvideWebAuthn(webauthnService, mfaService))
+		webauthnSecondFactor = webauthnService
+	}
+
+	// the form login of users with a Grafana password is completed by the MFA client when a second factor is required,
+	// the step up hook also rejects basic authentication of these users
+	if cfg.MFA.Enabled && !cfg.DisableLogin {
+		mfaClient := clients.ProvideMFA(cfg, mfaService, webauthnSecondFactor, userService, loginAttempts)
+		if !cfg.DisableLoginForm {
+			authnSvc.RegisterClient(mfaClient)
+		}
+		authnSvc.RegisterPostAuthHook(mfaClient.StepUpHook, 105)
+	}
+
 	if cfg.PasswordlessMagicLinkAuth.Enabled && features.IsEnabledGlobally(featuremgmt.FlagPasswordlessMagicLinkAuthentication) {
 		passwordless := clients.ProvidePasswordless(cfg, loginAttempts, userService, tempUserService, notificationService, cache)
 		authnSvc.RegisterClient(passwordless)
@@ -33,6 +33,7 @@ func (c *Basic) Authenticate(ctx context.Context, r *authn.Request) (*authn.Iden
 		return nil, errDecodingBasicAuthHeader.Errorf("failed to decode basic auth header")
 	}
 
+	r.SetMeta(authn.MetaKeyIsBasicAuth, "true")
 	return c.client.AuthenticatePassword(ctx, r, username, password)
 }
 
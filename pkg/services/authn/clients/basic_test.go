@@ -46,6 +46,7 @@ func TestBasic_Authenticate(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.EqualValues(t, *tt.expectedIdentity, *identity)
+				assert.Equal(t, "true", tt.req.GetMeta(authn.MetaKeyIsBasicAuth))
 			}
 		})
 	}
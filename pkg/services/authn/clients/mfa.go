@@ -0,0 +1,196 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var (
+	errMFARequired             = errutil.Unauthorized("mfa.required", errutil.WithPublicMessage("Verify your second factor to log in"))
+	errMFAEnrollmentRequired   = errutil.Unauthorized("mfa.enrollment-required", errutil.WithPublicMessage("Multi-factor authentication is required, set up an authenticator app"))
+	errMFATooManyLoginAttempts = errutil.Unauthorized("mfa.invalid.login-attempt", errutil.WithPublicMessage("Login temporarily blocked"))
+	errMFABadForm              = errutil.BadRequest("mfa.invalid.form", errutil.WithPublicMessage("bad login data"))
+	errMFABasicAuth            = errutil.Unauthorized("mfa.basic-auth", errutil.WithPublicMessage("Basic authentication is not available for users with multi-factor authentication, use a service account token"))
+)
+
+// second factors a challenged user can verify, returned with the challenge
+const (
+	mfaMethodTOTP     = "totp"
+	mfaMethodWebAuthn = "webauthn"
+)
+
+var _ authn.Client = new(MFA)
+
+// ProvideMFA returns the MFA client. The webauthn service is nil when security keys and passkeys are disabled.
+func ProvideMFA(cfg *setting.Cfg, mfaService mfa.Service, webauthnService webauthn.Service, userService user.Service, loginAttempts loginattempt.Service) *MFA {
+	return &MFA{cfg, mfaService, webauthnService, userService, loginAttempts, log.New("authn.mfa")}
+}
+
+// MFA completes the login of users that were challenged for a second factor after entering their Grafana password.
+type MFA struct {
+	cfg             *setting.Cfg
+	mfaService      mfa.Service
+	webauthnService webauthn.Service
+	userService     user.Service
+	loginAttempts   loginattempt.Service
+	log             log.Logger
+}
+
+type mfaForm struct {
+	Challenge string `json:"challenge" binding:"Required"`
+	Code      string `json:"code" binding:"Required"`
+}
+
+func (c *MFA) Name() string {
+	return authn.ClientMFA
+}
+
+func (c *MFA) IsEnabled() bool {
+	return true
+}
+
+// Authenticate implements authn.Client. It verifies the code against the login challenge, the code of users
+// enrolling during the login confirms their enrollment. Only users challenged without a second factor can enroll.
+func (c *MFA) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	form := mfaForm{}
+	if err := web.Bind(r.HTTPRequest, &form); err != nil {
+		return nil, errMFABadForm.Errorf("failed to parse request: %w", err)
+	}
+
+	challenge, err := c.mfaService.GetChallenge(ctx, form.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	userID := challenge.UserID
+	usr, err := c.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := c.loginAttempts.Validate(ctx, usr.Login, getClientIP(c.cfg, r))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errMFATooManyLoginAttempts.Errorf("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
+	}
+
+	status, err := c.mfaService.GetStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Enabled {
+		err = c.mfaService.Verify(ctx, userID, form.Code)
+	} else {
+		if err := c.checkEnrollment(ctx, challenge); err != nil {
+			return nil, err
+		}
+		err = c.mfaService.ConfirmEnrollment(ctx, userID, form.Code)
+	}
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			_ = c.loginAttempts.Add(ctx, usr.Login, getClientIP(c.cfg, r))
+		}
+		return nil, err
+	}
+
+	if err := c.mfaService.DeleteChallenge(ctx, form.Challenge); err != nil {
+		c.log.FromContext(ctx).Warn("Failed to delete login challenge", "userID", userID, "error", err)
+	}
+
+	return &authn.Identity{
+		ID:              strconv.FormatInt(userID, 10),
+		Type:            claims.TypeUser,
+		OrgID:           r.OrgID,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		AuthenticatedBy: login.PasswordAuthModule,
+	}, nil
+}
+
+// StepUpHook is a post auth hook that interrupts the login of users with a Grafana password when they enabled
+// multi-factor authentication, registered a security key or passkey, or it is enforced for them. The returned error
+// carries the challenge to complete the login with, using the MFA or the WebAuthn client, and the methods the user
+// can verify. Basic authentication cannot be challenged, it is rejected for these users.
+func (c *MFA) StepUpHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	if r.GetMeta(authn.MetaKeyAuthModule) != "grafana" {
+		return nil
+	}
+	isBasicAuth := r.GetMeta(authn.MetaKeyIsBasicAuth) != ""
+	if r.GetMeta(authn.MetaKeyIsLogin) == "" && !isBasicAuth {
+		return nil
+	}
+
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return err
+	}
+	status, err := c.mfaService.GetStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	methods := []string{}
+	if status.Enabled {
+		methods = append(methods, mfaMethodTOTP)
+	}
+	if c.webauthnService != nil {
+		hasCredentials, err := c.webauthnService.HasCredentials(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if hasCredentials {
+			methods = append(methods, mfaMethodWebAuthn)
+		}
+	}
+	if len(methods) == 0 && !status.Enforced {
+		return nil
+	}
+	if isBasicAuth {
+		return errMFABasicAuth.Errorf("user %d cannot use basic authentication with multi-factor authentication", userID)
+	}
+
+	challenge, err := c.mfaService.CreateChallenge(ctx, &mfa.Challenge{UserID: userID, EnrollmentRequired: len(methods) == 0})
+	if err != nil {
+		return err
+	}
+
+	base := errMFARequired
+	if len(methods) == 0 {
+		base = errMFAEnrollmentRequired
+	}
+	challengeErr := base.Errorf("user %d has to verify a second factor", userID)
+	challengeErr.PublicPayload = map[string]any{"challenge": challenge, "methods": methods}
+	return challengeErr
+}
+
+// checkEnrollment returns an error unless the user of the challenge has no second factor and can enroll to complete
+// the login, users with a security key or passkey have to verify it.
+func (c *MFA) checkEnrollment(ctx context.Context, challenge *mfa.Challenge) error {
+	if !challenge.EnrollmentRequired {
+		return mfa.ErrEnrollmentNotAllowed.Errorf("login challenge of user %d does not allow enrollment", challenge.UserID)
+	}
+	if c.webauthnService == nil {
+		return nil
+	}
+	hasCredentials, err := c.webauthnService.HasCredentials(ctx, challenge.UserID)
+	if err != nil {
+		return err
+	}
+	if hasCredentials {
+		return mfa.ErrEnrollmentNotAllowed.Errorf("user %d has security keys and cannot enroll with a login challenge", challenge.UserID)
+	}
+	return nil
+}
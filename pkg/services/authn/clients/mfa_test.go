@@ -0,0 +1,236 @@
+package clients
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/services/webauthn/webauthntest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMFA_Authenticate(t *testing.T) {
+	type testCase struct {
+		desc               string
+		body               string
+		status             *mfa.Status
+		enrollmentRequired bool
+		hasCredentials     bool
+		verifyErr          error
+		blockLogin         bool
+		expectedErr        error
+		expectedIdentity   *authn.Identity
+		expectedVerify     int
+		expectedConfirmed  int
+	}
+
+	identity := &authn.Identity{
+		ID:              "1",
+		Type:            claims.TypeUser,
+		OrgID:           1,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		AuthenticatedBy: login.PasswordAuthModule,
+	}
+
+	tests := []testCase{
+		{
+			desc:             "should verify the code of users with multi-factor authentication",
+			body:             `{"challenge": "challenge", "code": "123456"}`,
+			status:           &mfa.Status{Enabled: true},
+			expectedIdentity: identity,
+			expectedVerify:   1,
+		},
+		{
+			desc:               "should confirm the enrollment of users enrolling during the login",
+			body:               `{"challenge": "challenge", "code": "123456"}`,
+			status:             &mfa.Status{Enforced: true},
+			enrollmentRequired: true,
+			expectedIdentity:   identity,
+			expectedConfirmed:  1,
+		},
+		{
+			desc:        "should not confirm an enrollment with the challenge of users with a second factor",
+			body:        `{"challenge": "challenge", "code": "123456"}`,
+			status:      &mfa.Status{Enforced: true},
+			expectedErr: mfa.ErrEnrollmentNotAllowed,
+		},
+		{
+			desc:               "should not confirm the enrollment of users with security keys",
+			body:               `{"challenge": "challenge", "code": "123456"}`,
+			status:             &mfa.Status{Enforced: true},
+			enrollmentRequired: true,
+			hasCredentials:     true,
+			expectedErr:        mfa.ErrEnrollmentNotAllowed,
+		},
+		{
+			desc:           "should fail for an invalid code",
+			body:           `{"challenge": "challenge", "code": "123456"}`,
+			status:         &mfa.Status{Enabled: true},
+			verifyErr:      mfa.ErrInvalidCode.Errorf("invalid code"),
+			expectedErr:    mfa.ErrInvalidCode,
+			expectedVerify: 1,
+		},
+		{
+			desc:        "should fail when the login is blocked",
+			body:        `{"challenge": "challenge", "code": "123456"}`,
+			status:      &mfa.Status{Enabled: true},
+			blockLogin:  true,
+			expectedErr: errMFATooManyLoginAttempts,
+		},
+		{
+			desc:        "should fail for a bad request",
+			body:        `{}`,
+			expectedErr: errMFABadForm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			mfaService := &mfatest.FakeService{ExpectedStatus: tt.status, ExpectedUserID: 1, ExpectedEnrollmentRequired: tt.enrollmentRequired, ExpectedVerifyErr: tt.verifyErr}
+			userService := &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Login: "user"}}
+			webauthnService := &webauthntest.FakeService{ExpectedHasCredentials: tt.hasCredentials}
+			c := ProvideMFA(setting.NewCfg(), mfaService, webauthnService, userService, &loginattempttest.FakeLoginAttemptService{ExpectedValid: !tt.blockLogin})
+
+			identity, err := c.Authenticate(context.Background(), &authn.Request{OrgID: 1, HTTPRequest: &http.Request{
+				Header: map[string][]string{"Content-Type": {"application/json"}},
+				Body:   io.NopCloser(strings.NewReader(tt.body)),
+			}})
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.EqualValues(t, tt.expectedIdentity, identity)
+			assert.Equal(t, tt.expectedVerify, mfaService.VerifyCalls)
+			assert.Equal(t, tt.expectedConfirmed, mfaService.ConfirmEnrollmentCalls)
+			if tt.expectedErr == nil {
+				assert.Equal(t, 1, mfaService.DeleteChallengeCalls)
+			}
+		})
+	}
+}
+
+func TestMFA_StepUpHook(t *testing.T) {
+	type testCase struct {
+		desc            string
+		status          *mfa.Status
+		hasCredentials  bool
+		meta            map[string]string
+		expectedErr     error
+		expectedMethods []string
+	}
+
+	loginMeta := map[string]string{authn.MetaKeyIsLogin: "true", authn.MetaKeyAuthModule: "grafana"}
+	basicAuthMeta := map[string]string{authn.MetaKeyIsBasicAuth: "true", authn.MetaKeyAuthModule: "grafana"}
+	tests := []testCase{
+		{
+			desc:            "should challenge users with multi-factor authentication",
+			status:          &mfa.Status{Enabled: true},
+			meta:            loginMeta,
+			expectedErr:     errMFARequired,
+			expectedMethods: []string{mfaMethodTOTP},
+		},
+		{
+			desc:            "should challenge users with security keys",
+			status:          &mfa.Status{},
+			hasCredentials:  true,
+			meta:            loginMeta,
+			expectedErr:     errMFARequired,
+			expectedMethods: []string{mfaMethodWebAuthn},
+		},
+		{
+			desc:            "should challenge users with both factors",
+			status:          &mfa.Status{Enabled: true},
+			hasCredentials:  true,
+			meta:            loginMeta,
+			expectedErr:     errMFARequired,
+			expectedMethods: []string{mfaMethodTOTP, mfaMethodWebAuthn},
+		},
+		{
+			desc:            "should challenge users that have to enroll",
+			status:          &mfa.Status{Enforced: true},
+			meta:            loginMeta,
+			expectedErr:     errMFAEnrollmentRequired,
+			expectedMethods: []string{},
+		},
+		{
+			desc:            "should not require enrollment of users with security keys",
+			status:          &mfa.Status{Enforced: true},
+			hasCredentials:  true,
+			meta:            loginMeta,
+			expectedErr:     errMFARequired,
+			expectedMethods: []string{mfaMethodWebAuthn},
+		},
+		{
+			desc:   "should not challenge users without multi-factor authentication",
+			status: &mfa.Status{},
+			meta:   loginMeta,
+		},
+		{
+			desc:   "should not challenge requests that are not logins",
+			status: &mfa.Status{Enabled: true},
+			meta:   map[string]string{authn.MetaKeyAuthModule: "grafana"},
+		},
+		{
+			desc:        "should reject basic auth of users with multi-factor authentication",
+			status:      &mfa.Status{Enabled: true},
+			meta:        basicAuthMeta,
+			expectedErr: errMFABasicAuth,
+		},
+		{
+			desc:           "should reject basic auth of users with security keys",
+			status:         &mfa.Status{},
+			hasCredentials: true,
+			meta:           basicAuthMeta,
+			expectedErr:    errMFABasicAuth,
+		},
+		{
+			desc:        "should reject basic auth of users that have to enroll",
+			status:      &mfa.Status{Enforced: true},
+			meta:        basicAuthMeta,
+			expectedErr: errMFABasicAuth,
+		},
+		{
+			desc:   "should allow basic auth of users without multi-factor authentication",
+			status: &mfa.Status{},
+			meta:   basicAuthMeta,
+		},
+		{
+			desc:   "should not challenge users of other auth modules",
+			status: &mfa.Status{Enabled: true},
+			meta:   map[string]string{authn.MetaKeyIsLogin: "true", authn.MetaKeyAuthModule: "ldap"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			mfaService := &mfatest.FakeService{ExpectedStatus: tt.status, ExpectedChallenge: "challenge"}
+			webauthnService := &webauthntest.FakeService{ExpectedHasCredentials: tt.hasCredentials}
+			c := ProvideMFA(setting.NewCfg(), mfaService, webauthnService, &usertest.FakeUserService{}, &loginattempttest.FakeLoginAttemptService{})
+
+			r := &authn.Request{}
+			for key, value := range tt.meta {
+				r.SetMeta(key, value)
+			}
+
+			err := c.StepUpHook(context.Background(), &authn.Identity{ID: "1", Type: claims.TypeUser}, r)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedMethods != nil {
+				var challengeErr errutil.Error
+				require.ErrorAs(t, err, &challengeErr)
+				assert.Equal(t, map[string]any{"challenge": "challenge", "methods": tt.expectedMethods}, challengeErr.PublicPayload)
+				assert.Equal(t, &mfa.Challenge{UserID: 1, EnrollmentRequired: len(tt.expectedMethods) == 0}, mfaService.CreatedChallenge)
+			}
+		})
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
-	"github.com/grafana/grafana/pkg/web"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -18,11 +18,12 @@ var (
 
 var _ authn.PasswordClient = new(Password)
 
-func ProvidePassword(loginAttempts loginattempt.Service, clients ...authn.PasswordClient) *Password {
-	return &Password{loginAttempts, clients, log.New("authn.password")}
+func ProvidePassword(cfg *setting.Cfg, loginAttempts loginattempt.Service, clients ...authn.PasswordClient) *Password {
+	return &Password{cfg, loginAttempts, clients, log.New("authn.password")}
 }
 
 type Password struct {
+	cfg           *setting.Cfg
 	loginAttempts loginattempt.Service
 	clients       []authn.PasswordClient
 	log           log.Logger
@@ -31,7 +32,10 @@ type Password struct {
 func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, username, password string) (*authn.Identity, error) {
 	r.SetMeta(authn.MetaKeyUsername, username)
 
-	ok, err := c.loginAttempts.Validate(ctx, username)
+	// requests without an HTTP request are only checked against the username policy
+	ipAddress := getClientIP(c.cfg, r)
+
+	ok, err := c.loginAttempts.Validate(ctx, username, ipAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +61,7 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 	}
 
 	if errors.Is(clientErrs, errInvalidPassword) {
-		_ = c.loginAttempts.Add(ctx, username, web.RemoteAddr(r.HTTPRequest))
+		_ = c.loginAttempts.Add(ctx, username, ipAddress)
 	}
 
 	return nil, errPasswordAuthFailed.Errorf("failed to authenticate identity: %w", clientErrs)
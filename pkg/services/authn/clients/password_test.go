@@ -2,14 +2,18 @@ package clients
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"testing"
 
 	"github.com/grafana/authlib/claims"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestPassword_AuthenticatePassword(t *testing.T) {
@@ -68,7 +72,7 @@ func TestPassword_AuthenticatePassword(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: !tt.blockLogin}, tt.clients...)
+			c := ProvidePassword(setting.NewCfg(), loginattempttest.FakeLoginAttemptService{ExpectedValid: !tt.blockLogin}, tt.clients...)
 
 			identity, err := c.AuthenticatePassword(context.Background(), tt.req, tt.username, tt.password)
 			if tt.expectedErr != nil {
@@ -81,3 +85,88 @@ func TestPassword_AuthenticatePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestPassword_ClientIP(t *testing.T) {
+	type TestCase struct {
+		desc           string
+		trustedProxies []string
+		req            *authn.Request
+		expectedIP     string
+	}
+
+	tests := []TestCase{
+		{
+			desc:       "should use the address of the connection",
+			req:        &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "10.0.0.1:5000", Header: http.Header{}}},
+			expectedIP: "10.0.0.1",
+		},
+		{
+			desc: "should ignore spoofed forwarding headers",
+			req: &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "10.0.0.1:5000", Header: http.Header{
+				"X-Real-Ip":       {"192.0.2.1"},
+				"X-Forwarded-For": {"192.0.2.2, 10.0.0.1"},
+			}}},
+			expectedIP: "10.0.0.1",
+		},
+		{
+			desc:           "should use the forwarded address of a request from a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			req: &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "10.0.0.1:5000", Header: http.Header{
+				"X-Forwarded-For": {"192.0.2.1, 192.0.2.2, 10.0.0.2"},
+			}}},
+			expectedIP: "192.0.2.2",
+		},
+		{
+			desc:           "should use the real IP header of a request from a trusted proxy",
+			trustedProxies: []string{"10.0.0.1/32"},
+			req: &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "10.0.0.1:5000", Header: http.Header{
+				"X-Real-Ip": {"192.0.2.1"},
+			}}},
+			expectedIP: "192.0.2.1",
+		},
+		{
+			desc:           "should ignore forwarding headers of a request from an untrusted address",
+			trustedProxies: []string{"10.0.0.0/8"},
+			req: &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "192.0.2.9:5000", Header: http.Header{
+				"X-Real-Ip":       {"192.0.2.1"},
+				"X-Forwarded-For": {"192.0.2.2"},
+			}}},
+			expectedIP: "192.0.2.9",
+		},
+		{
+			desc:           "should use the proxy address when all forwarded addresses are trusted proxies",
+			trustedProxies: []string{"10.0.0.0/8"},
+			req: &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "10.0.0.1:5000", Header: http.Header{
+				"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"},
+			}}},
+			expectedIP: "10.0.0.3",
+		},
+		{
+			desc:       "should use the address of an IPv6 connection",
+			req:        &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "[2001:db8::1]:5000", Header: http.Header{}}},
+			expectedIP: "2001:db8::1",
+		},
+		{
+			desc: "should not use an IP address for requests without an HTTP request",
+			req:  &authn.Request{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			for _, proxy := range tt.trustedProxies {
+				_, network, err := net.ParseCIDR(proxy)
+				require.NoError(t, err)
+				cfg.BruteForceLoginProtectionTrustedProxies = append(cfg.BruteForceLoginProtectionTrustedProxies, network)
+			}
+			loginAttempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+			c := ProvidePassword(cfg, loginAttempts, authntest.FakePasswordClient{ExpectedErr: errInvalidPassword})
+
+			_, err := c.AuthenticatePassword(context.Background(), tt.req, "test", "test")
+			assert.ErrorIs(t, err, errPasswordAuthFailed)
+			assert.True(t, loginAttempts.AddCalled)
+			assert.Equal(t, tt.expectedIP, loginAttempts.IPAddress)
+		})
+	}
+}
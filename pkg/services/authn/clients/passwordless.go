@@ -105,8 +105,7 @@ func (c *Passwordless) RedirectURL(ctx context.Context, r *authn.Request) (*auth
 		return nil, err
 	}
 
-	// TODO: add IP address validation
-	ok, err := c.loginAttempts.Validate(ctx, form.Email)
+	ok, err := c.loginAttempts.Validate(ctx, form.Email, getClientIP(c.cfg, r))
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +114,7 @@ func (c *Passwordless) RedirectURL(ctx context.Context, r *authn.Request) (*auth
 		return nil, errPasswordlessClientTooManyLoginAttempts.Errorf("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
 	}
 
-	err = c.loginAttempts.Add(ctx, form.Email, web.RemoteAddr(r.HTTPRequest))
+	err = c.loginAttempts.Add(ctx, form.Email, getClientIP(c.cfg, r))
 	if err != nil {
 		return nil, err
 	}
@@ -264,7 +263,7 @@ func (c *Passwordless) authenticatePasswordless(ctx context.Context, r *authn.Re
 		return nil, errPasswordlessClientInvalidConfirmationCode
 	}
 
-	ok, err := c.loginAttempts.Validate(ctx, codeEntry.Email)
+	ok, err := c.loginAttempts.Validate(ctx, codeEntry.Email, getClientIP(c.cfg, r))
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,8 @@
 package clients
 
 import (
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -24,3 +26,12 @@ func getRoles(cfg *setting.Cfg, extract roleExtractor) (map[int64]org.RoleType,
 
 	return orgRoles, isGrafanaAdmin, nil
 }
+
+// getClientIP returns the IP address of the client of the request, it is empty for requests without an HTTP
+// request. See loginattempt.ClientIP for the forwarding headers that are used.
+func getClientIP(cfg *setting.Cfg, r *authn.Request) string {
+	if r.HTTPRequest == nil {
+		return ""
+	}
+	return loginattempt.ClientIP(cfg, r.HTTPRequest)
+}
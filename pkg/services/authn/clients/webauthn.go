@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var (
+	errWebAuthnBadForm           = errutil.BadRequest("webauthn.invalid.form", errutil.WithPublicMessage("bad login data"))
+	errWebAuthnChallengeMismatch = errutil.Unauthorized("webauthn.challenge-mismatch", errutil.WithPublicMessage("Invalid security key or passkey"))
+)
+
+var _ authn.Client = new(WebAuthn)
+
+func ProvideWebAuthn(webauthnService webauthn.Service, mfaService mfa.Service) *WebAuthn {
+	return &WebAuthn{webauthnService, mfaService, log.New("authn.webauthn")}
+}
+
+// WebAuthn logs users in with a security key or passkey, either without a password or as the second factor of a
+// login challenged by the MFA step-up hook.
+type WebAuthn struct {
+	webauthnService webauthn.Service
+	mfaService      mfa.Service
+	log             log.Logger
+}
+
+func (c *WebAuthn) Name() string {
+	return authn.ClientWebAuthn
+}
+
+func (c *WebAuthn) IsEnabled() bool {
+	return true
+}
+
+// Authenticate implements authn.Client. It verifies the assertion of the authenticator against the ceremony started
+// with the begin login endpoint of the webauthn service.
+func (c *WebAuthn) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	response := webauthn.AssertionResponse{}
+	if err := web.Bind(r.HTTPRequest, &response); err != nil {
+		return nil, errWebAuthnBadForm.Errorf("failed to parse request: %w", err)
+	}
+
+	result, err := c.webauthnService.FinishLogin(ctx, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	authModule := login.WebAuthnAuthModule
+	if result.MFAChallenge != "" {
+		// the ceremony completes the login challenge of the user who entered their password
+		challenge, err := c.mfaService.GetChallenge(ctx, result.MFAChallenge)
+		if err != nil {
+			return nil, err
+		}
+		if challenge.UserID != result.UserID {
+			return nil, errWebAuthnChallengeMismatch.Errorf("login challenge of user %d was completed by user %d", challenge.UserID, result.UserID)
+		}
+		if err := c.mfaService.DeleteChallenge(ctx, result.MFAChallenge); err != nil {
+			c.log.FromContext(ctx).Warn("Failed to delete login challenge", "userID", challenge.UserID, "error", err)
+		}
+		authModule = login.PasswordAuthModule
+	}
+
+	r.SetMeta(authn.MetaKeyAuthModule, authModule)
+
+	return &authn.Identity{
+		ID:              strconv.FormatInt(result.UserID, 10),
+		Type:            claims.TypeUser,
+		OrgID:           r.OrgID,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		AuthenticatedBy: authModule,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package clients
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/services/webauthn/webauthntest"
+)
+
+func TestWebAuthn_Authenticate(t *testing.T) {
+	type testCase struct {
+		desc                      string
+		body                      string
+		result                    *webauthn.LoginResult
+		finishErr                 error
+		challengeUserID           int64
+		expectedErr               error
+		expectedIdentity          *authn.Identity
+		expectedDeletedChallenges int
+	}
+
+	identity := func(authModule string) *authn.Identity {
+		return &authn.Identity{
+			ID:              "1",
+			Type:            claims.TypeUser,
+			OrgID:           1,
+			ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+			AuthenticatedBy: authModule,
+		}
+	}
+	body := `{"id": "AQID", "rawId": "AQID", "type": "public-key", "response": {}}`
+
+	tests := []testCase{
+		{
+			desc:             "should log in users with a passkey",
+			body:             body,
+			result:           &webauthn.LoginResult{UserID: 1},
+			expectedIdentity: identity(login.WebAuthnAuthModule),
+		},
+		{
+			desc:                      "should complete the login challenge of users with a security key",
+			body:                      body,
+			result:                    &webauthn.LoginResult{UserID: 1, MFAChallenge: "challenge"},
+			challengeUserID:           1,
+			expectedIdentity:          identity(login.PasswordAuthModule),
+			expectedDeletedChallenges: 1,
+		},
+		{
+			desc:            "should fail when the login challenge is for another user",
+			body:            body,
+			result:          &webauthn.LoginResult{UserID: 1, MFAChallenge: "challenge"},
+			challengeUserID: 2,
+			expectedErr:     errWebAuthnChallengeMismatch,
+		},
+		{
+			desc:        "should fail for an invalid assertion",
+			body:        body,
+			finishErr:   webauthn.ErrInvalidCredential.Errorf("invalid signature"),
+			expectedErr: webauthn.ErrInvalidCredential,
+		},
+		{
+			desc:        "should fail for a bad request",
+			body:        `{"rawId": "%%%"}`,
+			expectedErr: errWebAuthnBadForm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			webauthnService := &webauthntest.FakeService{ExpectedLoginResult: tt.result, ExpectedErr: tt.finishErr}
+			mfaService := &mfatest.FakeService{ExpectedUserID: tt.challengeUserID}
+			c := ProvideWebAuthn(webauthnService, mfaService)
+
+			r := &authn.Request{OrgID: 1, HTTPRequest: &http.Request{
+				Header: map[string][]string{"Content-Type": {"application/json"}},
+				Body:   io.NopCloser(strings.NewReader(tt.body)),
+			}}
+			identity, err := c.Authenticate(context.Background(), r)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.EqualValues(t, tt.expectedIdentity, identity)
+			assert.Equal(t, tt.expectedDeletedChallenges, mfaService.DeleteChallengeCalls)
+			if tt.expectedIdentity != nil {
+				assert.Equal(t, tt.expectedIdentity.AuthenticatedBy, r.GetMeta(authn.MetaKeyAuthModule))
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"archive/zip"
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -43,6 +45,16 @@ func (api *ImportDashboardAPI) RegisterAPIEndpoints(routeRegister routing.RouteR
 			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
 			routing.Wrap(api.ImportDashboard),
 		)
+		route.Post(
+			"/import/preview",
+			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
+			routing.Wrap(api.PreviewImportDashboard),
+		)
+		route.Post(
+			"/import/bulk",
+			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
+			routing.Wrap(api.BulkImportDashboards),
+		)
 	}, middleware.ReqSignedIn)
 }
 
@@ -88,6 +100,106 @@ func (api *ImportDashboardAPI) ImportDashboard(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, resp)
 }
 
+// swagger:route POST /dashboards/import/preview dashboards previewImportDashboard
+//
+// Preview the import of a dashboard.
+//
+// Maps the datasource inputs and references of the dashboard to the datasources of the organization
+// without importing the dashboard, and reports the inputs and references that could not be mapped.
+//
+// Responses:
+// 200: previewImportDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 422: unprocessableEntityError
+// 500: internalServerError
+func (api *ImportDashboardAPI) PreviewImportDashboard(c *contextmodel.ReqContext) response.Response {
+	req := dashboardimport.ImportDashboardRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if req.PluginId == "" && req.Dashboard == nil {
+		return response.Error(http.StatusUnprocessableEntity, "Dashboard must be set", nil)
+	}
+
+	req.User = c.SignedInUser
+	resp, err := api.dashboardImportService.PreviewImport(c.Req.Context(), &req)
+	if err != nil {
+		return apierrors.ToDashboardErrorResponse(c.Req.Context(), api.pluginStore, err)
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
+
+// swagger:route POST /dashboards/import/bulk dashboards bulkImportDashboards
+//
+// Import dashboards in bulk.
+//
+// Imports all the dashboards of a zip archive uploaded as the `file` field of a multipart form, or of a
+// directory of the Grafana server given as `path` in a JSON body. Importing from a directory requires the
+// Grafana Admin role. Datasources are mapped automatically.
+//
+// Responses:
+// 200: bulkImportDashboardsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *ImportDashboardAPI) BulkImportDashboards(c *contextmodel.ReqContext) response.Response {
+	req := dashboardimport.BulkImportDashboardsRequest{}
+	if strings.HasPrefix(c.Req.Header.Get("Content-Type"), "multipart/form-data") {
+		c.Req.Body = http.MaxBytesReader(c.Resp, c.Req.Body, maxBulkImportSize)
+		if err := c.Req.ParseMultipartForm(maxBulkImportSize); err != nil {
+			return response.Error(http.StatusBadRequest, "failed to read the uploaded archive", err)
+		}
+		file, header, err := c.Req.FormFile("file")
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "a zip archive must be uploaded as the file field", err)
+		}
+		defer func() { _ = file.Close() }()
+
+		archive, err := zip.NewReader(file, header.Size)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "the uploaded file is not a zip archive", err)
+		}
+		req.Files = archive
+		req.FolderUid = c.Req.FormValue("folderUid")
+		req.Overwrite = c.Req.FormValue("overwrite") == "true"
+	} else {
+		if err := web.Bind(c.Req, &req); err != nil {
+			return response.Error(http.StatusBadRequest, "bad request data", err)
+		}
+		if !c.SignedInUser.GetIsGrafanaAdmin() {
+			return response.Error(http.StatusForbidden, "Importing dashboards from a directory requires the Grafana Admin role", nil)
+		}
+	}
+
+	limitReached, err := api.quotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	if limitReached {
+		return response.Error(http.StatusForbidden, "Quota reached", nil)
+	}
+
+	req.User = c.SignedInUser
+	// every imported dashboard counts against the quota
+	req.QuotaReached = func() (bool, error) {
+		return api.quotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
+	}
+	resp, err := api.dashboardImportService.BulkImport(c.Req.Context(), &req)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to import dashboards", err)
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
+
+// maxBulkImportSize is the maximum size of the zip archives of bulk imports.
+const maxBulkImportSize = 100 << 20
+
 type QuotaService interface {
 	QuotaReached(c *contextmodel.ReqContext, target quota.TargetSrv) (bool, error)
 }
@@ -110,3 +222,22 @@ type ImportDashboardResponse struct {
 	// in: body
 	Body dashboardimport.ImportDashboardResponse `json:"body"`
 }
+
+// swagger:parameters previewImportDashboard
+type PreviewImportDashboardParams struct {
+	// in:body
+	// required:true
+	Body dashboardimport.ImportDashboardRequest
+}
+
+// swagger:response previewImportDashboardResponse
+type PreviewImportDashboardResponse struct {
+	// in: body
+	Body dashboardimport.ImportDashboardPreviewResponse `json:"body"`
+}
+
+// swagger:response bulkImportDashboardsResponse
+type BulkImportDashboardsResponse struct {
+	// in: body
+	Body dashboardimport.BulkImportDashboardsResponse `json:"body"`
+}
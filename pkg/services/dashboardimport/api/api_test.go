@@ -1,9 +1,12 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
+	"io/fs"
+	"mime/multipart"
 	"net/http"
 	"testing"
 
@@ -159,8 +162,96 @@ func TestImportDashboardAPI(t *testing.T) {
 	})
 }
 
+func TestBulkImportDashboardsAPI(t *testing.T) {
+	var bulkReq *dashboardimport.BulkImportDashboardsRequest
+	service := &serviceMock{
+		bulkImportFunc: func(ctx context.Context, req *dashboardimport.BulkImportDashboardsRequest) (*dashboardimport.BulkImportDashboardsResponse, error) {
+			bulkReq = req
+			return &dashboardimport.BulkImportDashboardsResponse{Imported: 1}, nil
+		},
+	}
+	importDashboardAPI := New(service, quotaServiceFunc(quotaNotReached), nil, actest.FakeAccessControl{ExpectedEvaluate: true})
+	routeRegister := routing.NewRouteRegister()
+	importDashboardAPI.RegisterAPIEndpoints(routeRegister)
+	s := webtest.NewServer(t, routeRegister)
+
+	t.Run("Uploaded zip archive should be imported", func(t *testing.T) {
+		archive := &bytes.Buffer{}
+		zipWriter := zip.NewWriter(archive)
+		f, err := zipWriter.Create("dashboards/cpu.json")
+		require.NoError(t, err)
+		_, err = f.Write([]byte(`{"title": "CPU"}`))
+		require.NoError(t, err)
+		require.NoError(t, zipWriter.Close())
+
+		body := &bytes.Buffer{}
+		formWriter := multipart.NewWriter(body)
+		require.NoError(t, formWriter.WriteField("folderUid", "ops"))
+		file, err := formWriter.CreateFormFile("file", "dashboards.zip")
+		require.NoError(t, err)
+		_, err = file.Write(archive.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, formWriter.Close())
+
+		req := s.NewPostRequest("/api/dashboards/import/bulk", body)
+		req.Header.Set("Content-Type", formWriter.FormDataContentType())
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1})
+		resp, err := s.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.NotNil(t, bulkReq)
+		require.Equal(t, "ops", bulkReq.FolderUid)
+		data, err := fs.ReadFile(bulkReq.Files, "dashboards/cpu.json")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title": "CPU"}`, string(data))
+
+		// the quota is checked for every dashboard of the archive
+		require.NotNil(t, bulkReq.QuotaReached)
+		reached, err := bulkReq.QuotaReached()
+		require.NoError(t, err)
+		require.False(t, reached)
+	})
+
+	t.Run("Importing a server directory should require the Grafana Admin role", func(t *testing.T) {
+		bulkReq = nil
+		jsonBytes, err := json.Marshal(&dashboardimport.BulkImportDashboardsRequest{Path: "/var/lib/dashboards"})
+		require.NoError(t, err)
+
+		req := s.NewPostRequest("/api/dashboards/import/bulk", bytes.NewReader(jsonBytes))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1})
+		resp, err := s.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Nil(t, bulkReq)
+
+		req = s.NewPostRequest("/api/dashboards/import/bulk", bytes.NewReader(jsonBytes))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, IsGrafanaAdmin: true})
+		resp, err = s.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "/var/lib/dashboards", bulkReq.Path)
+	})
+}
+
 type serviceMock struct {
 	importDashboardFunc func(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error)
+	bulkImportFunc      func(ctx context.Context, req *dashboardimport.BulkImportDashboardsRequest) (*dashboardimport.BulkImportDashboardsResponse, error)
+}
+
+func (s *serviceMock) PreviewImport(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardPreviewResponse, error) {
+	return &dashboardimport.ImportDashboardPreviewResponse{}, nil
+}
+
+func (s *serviceMock) BulkImport(ctx context.Context, req *dashboardimport.BulkImportDashboardsRequest) (*dashboardimport.BulkImportDashboardsResponse, error) {
+	if s.bulkImportFunc != nil {
+		return s.bulkImportFunc(ctx, req)
+	}
+
+	return &dashboardimport.BulkImportDashboardsResponse{}, nil
 }
 
 func (s *serviceMock) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
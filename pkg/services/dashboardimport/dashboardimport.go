@@ -2,11 +2,24 @@ package dashboardimport
 
 import (
 	"context"
+	"io/fs"
 
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
+var (
+	ErrBulkImportSourceMissing = errutil.BadRequest("dashboardimport.bulk-source-missing",
+		errutil.WithPublicMessage("A directory or zip archive of dashboards is required"))
+	ErrBulkImportFileTooLarge = errutil.BadRequest("dashboardimport.bulk-file-too-large",
+		errutil.WithPublicMessage("The dashboard file is too large"))
+	ErrBulkImportTooLarge = errutil.BadRequest("dashboardimport.bulk-too-large",
+		errutil.WithPublicMessage("The dashboards to import are too large"))
+	ErrBulkImportQuotaReached = errutil.Forbidden("dashboardimport.bulk-quota-reached",
+		errutil.WithPublicMessage("Quota reached"))
+)
+
 // ImportDashboardInput definition of input parameters when importing a dashboard.
 type ImportDashboardInput struct {
 	Type     string `json:"type"`
@@ -25,6 +38,9 @@ type ImportDashboardRequest struct {
 	// Deprecated: use FolderUID instead
 	FolderId  int64  `json:"folderId"`
 	FolderUid string `json:"folderUid"`
+	// AutoMapDatasources maps the datasource inputs missing from Inputs, and the datasource references
+	// of the dashboard that do not exist in the organization, to existing datasources.
+	AutoMapDatasources bool `json:"autoMapDatasources"`
 
 	User identity.Requester `json:"-"`
 }
@@ -47,9 +63,71 @@ type ImportDashboardResponse struct {
 	Description      string `json:"description"`
 	Path             string `json:"path"`
 	Removed          bool   `json:"removed"`
+	// UnresolvedDatasources are the datasource references that could not be mapped when auto mapping datasources.
+	UnresolvedDatasources []DatasourceMapping `json:"unresolvedDatasources,omitempty"`
+}
+
+// DatasourceMapping describes how a datasource input or reference of an imported dashboard is mapped
+// to a datasource of the organization.
+type DatasourceMapping struct {
+	// Input is the name of the mapped input, empty for datasource references of the dashboard.
+	Input string `json:"input,omitempty"`
+	// Path is the location of the mapped datasource reference in the dashboard, e.g. panels[1].targets[0].
+	Path      string `json:"path,omitempty"`
+	PluginId  string `json:"pluginId"`
+	Reference string `json:"reference"`
+	// DatasourceUid and DatasourceName are the datasource the input or reference is mapped to.
+	DatasourceUid  string `json:"datasourceUid,omitempty"`
+	DatasourceName string `json:"datasourceName,omitempty"`
+	Resolved       bool   `json:"resolved"`
+	// Reason explains why the input or reference could not be mapped.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportDashboardPreviewResponse response object returned when previewing the import of a dashboard.
+type ImportDashboardPreviewResponse struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// Inputs are the inputs the dashboard would be imported with.
+	Inputs      []ImportDashboardInput `json:"inputs"`
+	Datasources []DatasourceMapping    `json:"datasources"`
+	Unresolved  []DatasourceMapping    `json:"unresolved"`
+	Dashboard   *simplejson.Json       `json:"dashboard"`
+}
+
+// BulkImportDashboardsRequest request object for importing all the dashboards of a directory or zip archive.
+// Datasources are always auto mapped.
+type BulkImportDashboardsRequest struct {
+	// Path is a directory of the Grafana server to import the dashboards from, when Files is not set.
+	Path      string                 `json:"path"`
+	Overwrite bool                   `json:"overwrite"`
+	Inputs    []ImportDashboardInput `json:"inputs"`
+	FolderUid string                 `json:"folderUid"`
+
+	// Files contains the dashboards to import, e.g. the content of an uploaded zip archive.
+	Files fs.FS              `json:"-"`
+	User  identity.Requester `json:"-"`
+	// QuotaReached is checked before every dashboard of the import, when set.
+	QuotaReached func() (bool, error) `json:"-"`
+}
+
+// BulkImportDashboardResult is the result of importing one of the dashboards of a bulk import.
+type BulkImportDashboardResult struct {
+	File      string                   `json:"file"`
+	Dashboard *ImportDashboardResponse `json:"dashboard,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// BulkImportDashboardsResponse response object returned when importing dashboards in bulk.
+type BulkImportDashboardsResponse struct {
+	Imported int                         `json:"imported"`
+	Failed   int                         `json:"failed"`
+	Results  []BulkImportDashboardResult `json:"results"`
 }
 
 // Service service interface for importing dashboards.
 type Service interface {
 	ImportDashboard(ctx context.Context, req *ImportDashboardRequest) (*ImportDashboardResponse, error)
+	PreviewImport(ctx context.Context, req *ImportDashboardRequest) (*ImportDashboardPreviewResponse, error)
+	BulkImport(ctx context.Context, req *BulkImportDashboardsRequest) (*BulkImportDashboardsResponse, error)
 }
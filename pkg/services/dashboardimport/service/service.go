@@ -2,6 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
@@ -12,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardimport/api"
 	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
@@ -23,13 +30,15 @@ func ProvideService(routeRegister routing.RouteRegister,
 	quotaService quota.Service,
 	pluginDashboardService plugindashboards.Service, pluginStore pluginstore.Store,
 	libraryPanelService librarypanels.Service, dashboardService dashboards.DashboardService,
-	ac accesscontrol.AccessControl, folderService folder.Service,
+	ac accesscontrol.AccessControl, folderService folder.Service, dataSourceService datasources.DataSourceService,
 ) *ImportDashboardService {
 	s := &ImportDashboardService{
 		pluginDashboardService: pluginDashboardService,
 		dashboardService:       dashboardService,
 		libraryPanelService:    libraryPanelService,
 		folderService:          folderService,
+		dataSourceService:      dataSourceService,
+		ac:                     ac,
 	}
 
 	dashboardImportAPI := api.New(s, quotaService, pluginStore, ac)
@@ -43,30 +52,50 @@ type ImportDashboardService struct {
 	dashboardService       dashboards.DashboardService
 	libraryPanelService    librarypanels.Service
 	folderService          folder.Service
+	dataSourceService      datasources.DataSourceService
+	ac                     accesscontrol.AccessControl
 }
 
+// The uncompressed size of the files read by bulk imports is limited, as the size of the entries of
+// zip archives is not known until they are read.
+var (
+	maxBulkImportFileSize  int64 = 10 << 20
+	maxBulkImportTotalSize int64 = 500 << 20
+)
+
 func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
-	var draftDashboard *dashboards.Dashboard
-	if req.PluginId != "" {
-		loadReq := &plugindashboards.LoadPluginDashboardRequest{
-			PluginID:  req.PluginId,
-			Reference: req.Path,
-		}
-		if resp, err := s.pluginDashboardService.LoadPluginDashboard(ctx, loadReq); err != nil {
+	var mapper *utils.DatasourceMapper
+	if req.AutoMapDatasources {
+		var err error
+		if mapper, err = s.datasourceMapper(ctx, req.User); err != nil {
 			return nil, err
-		} else {
-			draftDashboard = resp.Dashboard
 		}
-	} else {
-		draftDashboard = dashboards.NewDashboardFromJson(req.Dashboard)
 	}
+	return s.importDashboard(ctx, req, mapper)
+}
 
-	evaluator := utils.NewDashTemplateEvaluator(draftDashboard.Data, req.Inputs)
+func (s *ImportDashboardService) importDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest, mapper *utils.DatasourceMapper) (*dashboardimport.ImportDashboardResponse, error) {
+	draftDashboard, err := s.loadDashboard(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := req.Inputs
+	if mapper != nil {
+		inputs, _ = mapper.MapInputs(draftDashboard.Data, req.Inputs)
+	}
+
+	evaluator := utils.NewDashTemplateEvaluator(draftDashboard.Data, inputs)
 	generatedDash, err := evaluator.Eval()
 	if err != nil {
 		return nil, err
 	}
 
+	var unresolved []dashboardimport.DatasourceMapping
+	if mapper != nil {
+		unresolved = unresolvedMappings(mapper.RemapReferences(generatedDash))
+	}
+
 	// Maintain backwards compatibility by transforming array of library elements to map
 	libraryElements := generatedDash.Get("__elements")
 	libElementsArr, err := libraryElements.Array()
@@ -164,5 +193,208 @@ func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashb
 		Imported:         true,
 		DashboardId:      savedDashboard.ID,
 		Slug:             savedDashboard.Slug,
+
+		UnresolvedDatasources: unresolved,
 	}, nil
 }
+
+// PreviewImport maps the datasources of the dashboard like an import with AutoMapDatasources, without
+// importing the dashboard. Inputs that can't be mapped are left as is in the returned dashboard.
+func (s *ImportDashboardService) PreviewImport(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardPreviewResponse, error) {
+	mapper, err := s.datasourceMapper(ctx, req.User)
+	if err != nil {
+		return nil, err
+	}
+
+	draftDashboard, err := s.loadDashboard(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, mappings := mapper.MapInputs(draftDashboard.Data, req.Inputs)
+	evalInputs := inputs
+	for _, mapping := range mappings {
+		if !mapping.Resolved {
+			evalInputs = append(evalInputs, dashboardimport.ImportDashboardInput{
+				Type:  "datasource",
+				Name:  mapping.Input,
+				Value: "${" + mapping.Input + "}",
+			})
+		}
+	}
+
+	generatedDash, err := utils.NewDashTemplateEvaluator(draftDashboard.Data, evalInputs).Eval()
+	if err != nil {
+		return nil, err
+	}
+	mappings = append(mappings, mapper.RemapReferences(generatedDash)...)
+	generatedDash.Del("__elements")
+	generatedDash.Del("__requires")
+
+	return &dashboardimport.ImportDashboardPreviewResponse{
+		UID:         generatedDash.Get("uid").MustString(),
+		Title:       generatedDash.Get("title").MustString(),
+		Inputs:      inputs,
+		Datasources: mappings,
+		Unresolved:  unresolvedMappings(mappings),
+		Dashboard:   generatedDash,
+	}, nil
+}
+
+// BulkImport imports all the JSON files of a directory or zip archive, auto mapping their datasources.
+// A dashboard failing to import does not stop the import of the other dashboards.
+func (s *ImportDashboardService) BulkImport(ctx context.Context, req *dashboardimport.BulkImportDashboardsRequest) (*dashboardimport.BulkImportDashboardsResponse, error) {
+	files := req.Files
+	if files == nil {
+		if req.Path == "" {
+			return nil, dashboardimport.ErrBulkImportSourceMissing
+		}
+		info, err := os.Stat(req.Path)
+		if err != nil || !info.IsDir() {
+			return nil, dashboardimport.ErrBulkImportSourceMissing.Errorf("%s is not a directory", req.Path)
+		}
+		files = os.DirFS(req.Path)
+	}
+
+	mapper, err := s.datasourceMapper(ctx, req.User)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dashboardimport.BulkImportDashboardsResponse{Results: make([]dashboardimport.BulkImportDashboardResult, 0)}
+	var totalSize int64
+	err = fs.WalkDir(files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != "." {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		result := dashboardimport.BulkImportDashboardResult{File: path}
+		data, err := readFile(files, path)
+		totalSize += int64(len(data))
+		if totalSize > maxBulkImportTotalSize {
+			return dashboardimport.ErrBulkImportTooLarge.Errorf("the files to import exceed %d bytes", maxBulkImportTotalSize)
+		}
+		if err == nil && req.QuotaReached != nil {
+			err = checkBulkImportQuota(req.QuotaReached)
+		}
+		if err == nil {
+			result.Dashboard, err = s.importFile(ctx, data, req, mapper)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			resp.Failed++
+		} else {
+			resp.Imported++
+		}
+		resp.Results = append(resp.Results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func checkBulkImportQuota(quotaReached func() (bool, error)) error {
+	reached, err := quotaReached()
+	if err != nil {
+		return err
+	}
+	if reached {
+		return dashboardimport.ErrBulkImportQuotaReached.Errorf("dashboard quota reached")
+	}
+	return nil
+}
+
+// readFile reads a file of a bulk import, up to maxBulkImportFileSize bytes.
+func readFile(files fs.FS, path string) ([]byte, error) {
+	f, err := files.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxBulkImportFileSize+1))
+	if err != nil {
+		return data, err
+	}
+	if int64(len(data)) > maxBulkImportFileSize {
+		return data, dashboardimport.ErrBulkImportFileTooLarge.Errorf("%s exceeds %d bytes", path, maxBulkImportFileSize)
+	}
+	return data, nil
+}
+
+func (s *ImportDashboardService) importFile(ctx context.Context, data []byte, req *dashboardimport.BulkImportDashboardsRequest, mapper *utils.DatasourceMapper) (*dashboardimport.ImportDashboardResponse, error) {
+	dashboard, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dashboard JSON: %w", err)
+	}
+
+	return s.importDashboard(ctx, &dashboardimport.ImportDashboardRequest{
+		Dashboard:          dashboard,
+		Inputs:             req.Inputs,
+		Overwrite:          req.Overwrite,
+		FolderUid:          req.FolderUid,
+		AutoMapDatasources: true,
+		User:               req.User,
+	}, mapper)
+}
+
+func (s *ImportDashboardService) loadDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboards.Dashboard, error) {
+	if req.PluginId == "" {
+		return dashboards.NewDashboardFromJson(req.Dashboard), nil
+	}
+
+	resp, err := s.pluginDashboardService.LoadPluginDashboard(ctx, &plugindashboards.LoadPluginDashboardRequest{
+		PluginID:  req.PluginId,
+		Reference: req.Path,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Dashboard, nil
+}
+
+func (s *ImportDashboardService) datasourceMapper(ctx context.Context, user identity.Requester) (*utils.DatasourceMapper, error) {
+	dataSources, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+
+	// dashboards are only mapped to the datasources the user can query
+	allowed := make([]*datasources.DataSource, 0, len(dataSources))
+	for _, ds := range dataSources {
+		scope := datasources.ScopeProvider.GetResourceScopeUID(ds.UID)
+		ok, err := s.ac.Evaluate(ctx, user, accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(datasources.ActionRead, scope),
+			accesscontrol.EvalPermission(datasources.ActionQuery, scope),
+		))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = append(allowed, ds)
+		}
+	}
+	return utils.NewDatasourceMapper(allowed), nil
+}
+
+func unresolvedMappings(mappings []dashboardimport.DatasourceMapping) []dashboardimport.DatasourceMapping {
+	unresolved := make([]dashboardimport.DatasourceMapping, 0)
+	for _, mapping := range mappings {
+		if !mapping.Resolved {
+			unresolved = append(unresolved, mapping)
+		}
+	}
+	return unresolved
+}
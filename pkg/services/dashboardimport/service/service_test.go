@@ -5,13 +5,20 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/foldertest"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
@@ -163,6 +170,208 @@ func TestImportDashboardService(t *testing.T) {
 	})
 }
 
+func TestImportDashboardServiceAutoMapDatasources(t *testing.T) {
+	var imported []*dashboards.SaveDashboardDTO
+	dashboardService := &dashboardServiceMock{
+		importDashboardFunc: func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
+			if dto.Dashboard.Title == "Broken" {
+				return nil, dashboards.ErrDashboardTitleEmpty
+			}
+			imported = append(imported, dto)
+			return &dashboards.Dashboard{
+				ID:    int64(len(imported)),
+				UID:   dto.Dashboard.UID,
+				Slug:  dto.Dashboard.Slug,
+				OrgID: 3,
+				Title: dto.Dashboard.Title,
+				Data:  dto.Dashboard.Data,
+			}, nil
+		},
+	}
+	s := &ImportDashboardService{
+		dashboardService:    dashboardService,
+		libraryPanelService: &libraryPanelServiceMock{},
+		folderService:       &foldertest.FakeService{ExpectedFolder: &folder.Folder{UID: "123"}},
+		dataSourceService: &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{OrgID: 3, UID: "prom", Name: "gdev-prometheus", Type: "prometheus"},
+			{OrgID: 3, UID: "other-prom", Name: "Other", Type: "prometheus"},
+		}},
+		ac: acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()),
+	}
+	usr := &user.SignedInUser{UserID: 2, OrgRole: org.RoleAdmin, OrgID: 3, Permissions: map[int64]map[string][]string{
+		3: {datasources.ActionRead: {datasources.ScopeAll}, datasources.ActionQuery: {datasources.ScopeAll}},
+	}}
+
+	loadResp, err := loadTestDashboard(context.Background(), &plugindashboards.LoadPluginDashboardRequest{Reference: "dashboard.json"})
+	require.NoError(t, err)
+
+	t.Run("import without inputs maps the datasource inputs", func(t *testing.T) {
+		imported = nil
+		_, err := s.ImportDashboard(context.Background(), &dashboardimport.ImportDashboardRequest{
+			Dashboard: loadResp.Dashboard.Data,
+			User:      usr,
+			FolderUid: "folderUID",
+		})
+		require.ErrorIs(t, err, utils.ErrDashboardInputMissing)
+
+		resp, err := s.ImportDashboard(context.Background(), &dashboardimport.ImportDashboardRequest{
+			Dashboard:          loadResp.Dashboard.Data,
+			AutoMapDatasources: true,
+			User:               usr,
+			FolderUid:          "folderUID",
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.UnresolvedDatasources)
+		require.Len(t, imported, 1)
+
+		panel := imported[0].Dashboard.Data.Get("panels").GetIndex(0)
+		require.Equal(t, "prom", panel.Get("datasource").MustString())
+	})
+
+	t.Run("import only maps the datasources the user can query", func(t *testing.T) {
+		imported = nil
+		restricted := &user.SignedInUser{UserID: 3, OrgRole: org.RoleEditor, OrgID: 3, Permissions: map[int64]map[string][]string{
+			3: {
+				datasources.ActionRead:  {datasources.ScopeAll},
+				datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID("other-prom")},
+			},
+		}}
+		resp, err := s.ImportDashboard(context.Background(), &dashboardimport.ImportDashboardRequest{
+			Dashboard:          loadResp.Dashboard.Data,
+			AutoMapDatasources: true,
+			User:               restricted,
+			FolderUid:          "folderUID",
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.UnresolvedDatasources)
+		require.Len(t, imported, 1)
+
+		panel := imported[0].Dashboard.Data.Get("panels").GetIndex(0)
+		require.Equal(t, "other-prom", panel.Get("datasource").MustString())
+
+		noQuery := &user.SignedInUser{UserID: 4, OrgRole: org.RoleEditor, OrgID: 3, Permissions: map[int64]map[string][]string{
+			3: {datasources.ActionRead: {datasources.ScopeAll}},
+		}}
+		_, err = s.ImportDashboard(context.Background(), &dashboardimport.ImportDashboardRequest{
+			Dashboard:          loadResp.Dashboard.Data,
+			AutoMapDatasources: true,
+			User:               noQuery,
+			FolderUid:          "folderUID",
+		})
+		require.ErrorIs(t, err, utils.ErrDashboardInputMissing)
+	})
+
+	t.Run("preview reports unresolved datasources", func(t *testing.T) {
+		dashboard, err := simplejson.NewJson([]byte(`{
+			"title": "Preview",
+			"__inputs": [{"name": "DS_LOKI", "label": "loki", "type": "datasource", "pluginId": "loki"}],
+			"panels": [
+				{"datasource": {"type": "loki", "uid": "${DS_LOKI}"}},
+				{"datasource": {"type": "prometheus", "uid": "gdev-prometheus"}}
+			]
+		}`))
+		require.NoError(t, err)
+
+		resp, err := s.PreviewImport(context.Background(), &dashboardimport.ImportDashboardRequest{Dashboard: dashboard, User: usr})
+		require.NoError(t, err)
+		require.Equal(t, "Preview", resp.Title)
+		require.Len(t, resp.Datasources, 2)
+		require.Len(t, resp.Unresolved, 1)
+		require.Equal(t, "DS_LOKI", resp.Unresolved[0].Input)
+		require.Equal(t, "${DS_LOKI}", resp.Dashboard.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "prom", resp.Dashboard.Get("panels").GetIndex(1).GetPath("datasource", "uid").MustString())
+	})
+
+	t.Run("bulk import imports all the dashboards of the archive", func(t *testing.T) {
+		imported = nil
+		data, err := loadResp.Dashboard.Data.Encode()
+		require.NoError(t, err)
+		files := fstest.MapFS{
+			"team/cpu.json":    {Data: data},
+			"team/broken.json": {Data: []byte(`{"title": "Broken"}`)},
+			"invalid.json":     {Data: []byte(`{`)},
+			"README.md":        {Data: []byte(`dashboards`)},
+			".hidden/x.json":   {Data: data},
+		}
+
+		resp, err := s.BulkImport(context.Background(), &dashboardimport.BulkImportDashboardsRequest{Files: files, User: usr})
+		require.NoError(t, err)
+		require.Equal(t, 1, resp.Imported)
+		require.Equal(t, 2, resp.Failed)
+		require.Len(t, resp.Results, 3)
+		require.Equal(t, "invalid.json", resp.Results[0].File)
+		require.NotEmpty(t, resp.Results[0].Error)
+		require.Equal(t, "team/broken.json", resp.Results[1].File)
+		require.NotEmpty(t, resp.Results[1].Error)
+		require.Equal(t, "team/cpu.json", resp.Results[2].File)
+		require.Equal(t, "UDdpyzz7z", resp.Results[2].Dashboard.UID)
+		require.Len(t, imported, 1)
+	})
+
+	t.Run("bulk import limits the size of the files", func(t *testing.T) {
+		imported = nil
+		origFileSize, origTotalSize := maxBulkImportFileSize, maxBulkImportTotalSize
+		t.Cleanup(func() {
+			maxBulkImportFileSize, maxBulkImportTotalSize = origFileSize, origTotalSize
+		})
+		data, err := loadResp.Dashboard.Data.Encode()
+		require.NoError(t, err)
+		maxBulkImportFileSize = int64(len(data))
+		maxBulkImportTotalSize = int64(3 * len(data))
+
+		files := fstest.MapFS{
+			"a.json": {Data: data},
+			"b.json": {Data: append(data, ' ')},
+		}
+		resp, err := s.BulkImport(context.Background(), &dashboardimport.BulkImportDashboardsRequest{Files: files, User: usr})
+		require.NoError(t, err)
+		require.Equal(t, 1, resp.Imported)
+		require.Equal(t, 1, resp.Failed)
+		require.Equal(t, "b.json", resp.Results[1].File)
+		require.Contains(t, resp.Results[1].Error, "exceeds")
+
+		files["c.json"] = &fstest.MapFile{Data: data}
+		_, err = s.BulkImport(context.Background(), &dashboardimport.BulkImportDashboardsRequest{Files: files, User: usr})
+		require.ErrorIs(t, err, dashboardimport.ErrBulkImportTooLarge)
+	})
+
+	t.Run("bulk import checks the quota for every dashboard", func(t *testing.T) {
+		imported = nil
+		data, err := loadResp.Dashboard.Data.Encode()
+		require.NoError(t, err)
+		files := fstest.MapFS{
+			"a.json": {Data: data},
+			"b.json": {Data: data},
+			"c.json": {Data: data},
+		}
+		checks := 0
+		quotaReached := func() (bool, error) {
+			checks++
+			// the quota allows one more dashboard
+			return len(imported) >= 1, nil
+		}
+
+		resp, err := s.BulkImport(context.Background(), &dashboardimport.BulkImportDashboardsRequest{Files: files, User: usr, QuotaReached: quotaReached})
+		require.NoError(t, err)
+		require.Equal(t, 3, checks)
+		require.Equal(t, 1, resp.Imported)
+		require.Equal(t, 2, resp.Failed)
+		require.Equal(t, "a.json", resp.Results[0].File)
+		require.Empty(t, resp.Results[0].Error)
+		require.Contains(t, resp.Results[1].Error, "quota reached")
+		require.Contains(t, resp.Results[2].Error, "quota reached")
+		require.Len(t, imported, 1)
+	})
+
+	t.Run("bulk import requires a directory", func(t *testing.T) {
+		_, err := s.BulkImport(context.Background(), &dashboardimport.BulkImportDashboardsRequest{User: usr})
+		require.ErrorIs(t, err, dashboardimport.ErrBulkImportSourceMissing)
+
+		_, err = s.BulkImport(context.Background(), &dashboardimport.BulkImportDashboardsRequest{Path: filepath.Join("testdata", "dashboard.json"), User: usr})
+		require.ErrorIs(t, err, dashboardimport.ErrBulkImportSourceMissing)
+	})
+}
+
 func loadTestDashboard(ctx context.Context, req *plugindashboards.LoadPluginDashboardRequest) (*plugindashboards.LoadPluginDashboardResponse, error) {
 	// It's safe to ignore gosec warning G304 since this is a test and arguments comes from test configuration.
 	// nolint:gosec
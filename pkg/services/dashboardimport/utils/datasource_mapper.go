@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// DatasourceMapper maps the datasource inputs and references of imported dashboards to the
+// datasources of an organization.
+//
+// A datasource is chosen among the datasources of the referenced plugin type, preferring a
+// datasource whose name matches the reference, then the only datasource of the type, then the
+// default datasource.
+type DatasourceMapper struct {
+	datasources []*datasources.DataSource
+}
+
+func NewDatasourceMapper(dataSources []*datasources.DataSource) *DatasourceMapper {
+	return &DatasourceMapper{datasources: dataSources}
+}
+
+// MapInputs returns inputs completed with values for the inputs of the template missing from it.
+// Datasource inputs are mapped to datasources, constant inputs get the value of the template.
+// The returned mappings describe every datasource input the mapper tried to map.
+func (m *DatasourceMapper) MapInputs(template *simplejson.Json, inputs []dashboardimport.ImportDashboardInput) ([]dashboardimport.ImportDashboardInput, []dashboardimport.DatasourceMapping) {
+	evaluator := NewDashTemplateEvaluator(template, inputs)
+	result := append([]dashboardimport.ImportDashboardInput{}, inputs...)
+	mappings := make([]dashboardimport.DatasourceMapping, 0)
+
+	for _, inputDef := range template.Get("__inputs").MustArray() {
+		inputDefJson := simplejson.NewFromAny(inputDef)
+		inputName := inputDefJson.Get("name").MustString()
+		inputType := inputDefJson.Get("type").MustString()
+		pluginID := inputDefJson.Get("pluginId").MustString()
+		if pluginID == expr.DatasourceType || evaluator.findInput(inputName, inputType) != nil {
+			continue
+		}
+
+		switch inputType {
+		case "datasource":
+			label := inputDefJson.Get("label").MustString()
+			mapping := dashboardimport.DatasourceMapping{
+				Input:     inputName,
+				PluginId:  pluginID,
+				Reference: label,
+			}
+			ds, reason := m.find(pluginID, label, strings.TrimPrefix(inputName, "DS_"))
+			if ds != nil {
+				mapping.DatasourceUid = ds.UID
+				mapping.DatasourceName = ds.Name
+				mapping.Resolved = true
+				result = append(result, dashboardimport.ImportDashboardInput{
+					Type:     inputType,
+					PluginId: pluginID,
+					Name:     inputName,
+					Value:    ds.UID,
+				})
+			} else {
+				mapping.Reason = reason
+			}
+			mappings = append(mappings, mapping)
+		case "constant":
+			if value, err := inputDefJson.Get("value").String(); err == nil {
+				result = append(result, dashboardimport.ImportDashboardInput{
+					Type:  inputType,
+					Name:  inputName,
+					Value: value,
+				})
+			}
+		}
+	}
+
+	return result, mappings
+}
+
+// RemapReferences replaces the datasource references of the panels, queries, variables and annotations
+// of the dashboard that do not exist in the organization. The returned mappings describe every reference
+// the mapper tried to replace.
+func (m *DatasourceMapper) RemapReferences(dashboard *simplejson.Json) []dashboardimport.DatasourceMapping {
+	mappings := make([]dashboardimport.DatasourceMapping, 0)
+	remap := func(path string, parent *simplejson.Json) {
+		if mapping, ok := m.remapReference(parent); ok {
+			mapping.Path = path
+			mappings = append(mappings, mapping)
+		}
+	}
+
+	var remapPanels func(path string, panels []any)
+	remapPanels = func(path string, panels []any) {
+		for i, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			panelPath := fmt.Sprintf("%s[%d]", path, i)
+			remap(panelPath, panel)
+			for j, target := range panel.Get("targets").MustArray() {
+				remap(fmt.Sprintf("%s.targets[%d]", panelPath, j), simplejson.NewFromAny(target))
+			}
+			remapPanels(panelPath+".panels", panel.Get("panels").MustArray())
+		}
+	}
+
+	remapPanels("panels", dashboard.Get("panels").MustArray())
+	for i, row := range dashboard.Get("rows").MustArray() {
+		remapPanels(fmt.Sprintf("rows[%d].panels", i), simplejson.NewFromAny(row).Get("panels").MustArray())
+	}
+	for i, variable := range dashboard.GetPath("templating", "list").MustArray() {
+		remap(fmt.Sprintf("templating.list[%d]", i), simplejson.NewFromAny(variable))
+	}
+	for i, annotation := range dashboard.GetPath("annotations", "list").MustArray() {
+		remap(fmt.Sprintf("annotations.list[%d]", i), simplejson.NewFromAny(annotation))
+	}
+
+	return mappings
+}
+
+// remapReference replaces the datasource reference of parent when it does not exist in the organization.
+// It returns false when the reference does not need to be mapped.
+func (m *DatasourceMapper) remapReference(parent *simplejson.Json) (dashboardimport.DatasourceMapping, bool) {
+	ref := parent.Get("datasource")
+
+	// Legacy references by datasource name
+	if name, err := ref.String(); err == nil {
+		if name == "" || isVariableReference(name) || datasources.IsBuiltInDatasource(name) || m.exists(name, true) {
+			return dashboardimport.DatasourceMapping{}, false
+		}
+		mapping := dashboardimport.DatasourceMapping{Reference: name}
+		ds, reason := m.find("", name)
+		if ds == nil {
+			mapping.Reason = reason
+			return mapping, true
+		}
+		parent.Set("datasource", ds.Name)
+		mapping.PluginId = ds.Type
+		mapping.DatasourceUid = ds.UID
+		mapping.DatasourceName = ds.Name
+		mapping.Resolved = true
+		return mapping, true
+	}
+
+	uid := ref.Get("uid").MustString()
+	pluginID := ref.Get("type").MustString()
+	if uid == "" || isVariableReference(uid) || datasources.IsBuiltInDatasource(uid) ||
+		pluginID == "datasource" || pluginID == expr.DatasourceType || m.exists(uid, false) {
+		return dashboardimport.DatasourceMapping{}, false
+	}
+
+	mapping := dashboardimport.DatasourceMapping{PluginId: pluginID, Reference: uid}
+	if pluginID == "" {
+		mapping.Reason = "the reference has no datasource type"
+		return mapping, true
+	}
+	ds, reason := m.find(pluginID, uid)
+	if ds == nil {
+		mapping.Reason = reason
+		return mapping, true
+	}
+	ref.Set("uid", ds.UID)
+	mapping.DatasourceUid = ds.UID
+	mapping.DatasourceName = ds.Name
+	mapping.Resolved = true
+	return mapping, true
+}
+
+// find returns the datasource of type pluginID, or of any type when pluginID is empty, matching one of
+// names. Without a name match it falls back to the only datasource of the type, then to the default one.
+func (m *DatasourceMapper) find(pluginID string, names ...string) (*datasources.DataSource, string) {
+	candidates := make([]*datasources.DataSource, 0)
+	for _, ds := range m.datasources {
+		if pluginID == "" || ds.Type == pluginID {
+			candidates = append(candidates, ds)
+		}
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		for _, ds := range candidates {
+			if strings.EqualFold(ds.Name, name) || ds.UID == name {
+				return ds, ""
+			}
+		}
+	}
+
+	if pluginID == "" {
+		return nil, "no datasource with this name"
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Sprintf("no datasource of type %s", pluginID)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], ""
+	}
+	for _, ds := range candidates {
+		if ds.IsDefault {
+			return ds, ""
+		}
+	}
+	return nil, fmt.Sprintf("%d datasources of type %s match", len(candidates), pluginID)
+}
+
+// exists returns whether a datasource has ref as UID, or as name when byName is set.
+func (m *DatasourceMapper) exists(ref string, byName bool) bool {
+	for _, ds := range m.datasources {
+		if ds.UID == ref || (byName && ds.Name == ref) {
+			return true
+		}
+	}
+	return false
+}
+
+func isVariableReference(ref string) bool {
+	return strings.HasPrefix(ref, "$")
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+func TestDatasourceMapper(t *testing.T) {
+	mapper := NewDatasourceMapper([]*datasources.DataSource{
+		{UID: "prom-a", Name: "Prometheus A", Type: "prometheus"},
+		{UID: "prom-b", Name: "Prometheus B", Type: "prometheus", IsDefault: true},
+		{UID: "loki", Name: "Loki", Type: "loki"},
+		{UID: "pg-1", Name: "Postgres 1", Type: "postgres"},
+		{UID: "pg-2", Name: "Postgres 2", Type: "postgres"},
+	})
+
+	t.Run("maps inputs by name, by type and to the default datasource", func(t *testing.T) {
+		template, err := simplejson.NewJson([]byte(`{
+			"__inputs": [
+				{"name": "DS_PROMETHEUS_A", "label": "Prometheus A", "type": "datasource", "pluginId": "prometheus"},
+				{"name": "DS_PROM", "label": "prom", "type": "datasource", "pluginId": "prometheus"},
+				{"name": "DS_LOGS", "label": "logs", "type": "datasource", "pluginId": "loki"},
+				{"name": "DS_SQL", "label": "sql", "type": "datasource", "pluginId": "postgres"},
+				{"name": "DS_ES", "label": "es", "type": "datasource", "pluginId": "elasticsearch"},
+				{"name": "DS_GIVEN", "label": "given", "type": "datasource", "pluginId": "prometheus"},
+				{"name": "VAR_ENV", "type": "constant", "value": "prod"}
+			]
+		}`))
+		require.NoError(t, err)
+
+		given := dashboardimport.ImportDashboardInput{Name: "DS_GIVEN", Type: "datasource", Value: "prom-a"}
+		inputs, mappings := mapper.MapInputs(template, []dashboardimport.ImportDashboardInput{given})
+
+		values := map[string]string{}
+		for _, input := range inputs {
+			values[input.Name] = input.Value
+		}
+		require.Equal(t, map[string]string{
+			"DS_GIVEN":        "prom-a",
+			"DS_PROMETHEUS_A": "prom-a",
+			"DS_PROM":         "prom-b",
+			"DS_LOGS":         "loki",
+			"VAR_ENV":         "prod",
+		}, values)
+
+		require.Len(t, mappings, 5)
+		unresolved := map[string]string{}
+		for _, mapping := range mappings {
+			if !mapping.Resolved {
+				unresolved[mapping.Input] = mapping.Reason
+			}
+		}
+		require.Equal(t, map[string]string{
+			"DS_SQL": "2 datasources of type postgres match",
+			"DS_ES":  "no datasource of type elasticsearch",
+		}, unresolved)
+	})
+
+	t.Run("remaps references missing from the organization", func(t *testing.T) {
+		dashboard, err := simplejson.NewJson([]byte(`{
+			"panels": [
+				{
+					"datasource": {"type": "prometheus", "uid": "unknown"},
+					"targets": [
+						{"datasource": {"type": "prometheus", "uid": "prom-a"}},
+						{"datasource": {"type": "postgres", "uid": "other-pg"}}
+					]
+				},
+				{
+					"type": "row",
+					"panels": [{"datasource": "Loki"}, {"datasource": "Old graphite"}]
+				},
+				{"datasource": {"type": "prometheus", "uid": "${datasource}"}},
+				{"datasource": {"type": "datasource", "uid": "grafana"}}
+			],
+			"templating": {
+				"list": [{"datasource": {"type": "loki", "uid": "gone"}}]
+			}
+		}`))
+		require.NoError(t, err)
+
+		mappings := mapper.RemapReferences(dashboard)
+
+		require.Equal(t, "prom-b", dashboard.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "other-pg", dashboard.Get("panels").GetIndex(0).Get("targets").GetIndex(1).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "loki", dashboard.GetPath("templating", "list").GetIndex(0).GetPath("datasource", "uid").MustString())
+
+		require.Equal(t, []dashboardimport.DatasourceMapping{
+			{Path: "panels[0]", PluginId: "prometheus", Reference: "unknown", DatasourceUid: "prom-b", DatasourceName: "Prometheus B", Resolved: true},
+			{Path: "panels[0].targets[1]", PluginId: "postgres", Reference: "other-pg", Reason: "2 datasources of type postgres match"},
+			{Path: "panels[1].panels[1]", Reference: "Old graphite", Reason: "no datasource with this name"},
+			{Path: "templating.list[0]", PluginId: "loki", Reference: "gone", DatasourceUid: "loki", DatasourceName: "Loki", Resolved: true},
+		}, mappings)
+	})
+}
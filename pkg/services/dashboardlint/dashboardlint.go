@@ -0,0 +1,18 @@
+package dashboardlint
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type Service interface {
+	// Lint runs the rules enabled in the organization over the dashboard JSON.
+	Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*LintResult, error)
+	// LintOnSave returns whether dashboards are linted when saved.
+	LintOnSave() bool
+	GetRules(ctx context.Context, orgID int64) ([]*RuleDTO, error)
+	UpdateRule(ctx context.Context, cmd *UpdateRuleCommand) (*RuleDTO, error)
+	// RegisterRule makes the rule available to all organizations.
+	RegisterRule(rule Rule)
+}
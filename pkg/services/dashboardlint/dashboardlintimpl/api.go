@@ -0,0 +1,83 @@
+package dashboardlintimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/dashboard-lint", func(entities routing.RouteRegister) {
+		entities.Post("/", routing.Wrap(s.lintHandler))
+		entities.Get("/uid/:uid", routing.Wrap(s.lintDashboardHandler))
+		entities.Get("/rules", routing.Wrap(s.getRulesHandler))
+		entities.Put("/rules/:name", middleware.ReqOrgAdmin, routing.Wrap(s.updateRuleHandler))
+	}, middleware.ReqSignedIn)
+}
+
+func (s *Service) lintHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := dashboardlint.LintDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(dashboardlint.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	result, err := s.Lint(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Dashboard)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to lint dashboard", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) lintDashboardHandler(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+
+	evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
+	canRead, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+	if !canRead {
+		return response.Error(http.StatusForbidden, "Access denied to this dashboard", nil)
+	}
+
+	dash, err := s.dashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: uid, OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+
+	result, err := s.Lint(c.Req.Context(), c.SignedInUser.GetOrgID(), dash.Data)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to lint dashboard", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) getRulesHandler(c *contextmodel.ReqContext) response.Response {
+	rules, err := s.GetRules(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get lint rules", err)
+	}
+	return response.JSON(http.StatusOK, rules)
+}
+
+func (s *Service) updateRuleHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := dashboardlint.UpdateRuleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(dashboardlint.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.Rule = web.Params(c.Req)[":name"]
+
+	rule, err := s.UpdateRule(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update lint rule", err)
+	}
+	return response.JSON(http.StatusOK, rule)
+}
@@ -0,0 +1,144 @@
+package dashboardlintimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Service struct {
+	store            store
+	cfg              *setting.Cfg
+	linter           *dashboardlint.Linter
+	routeRegister    routing.RouteRegister
+	accessControl    accesscontrol.AccessControl
+	dashboardService dashboards.DashboardService
+	log              log.Logger
+}
+
+var _ dashboardlint.Service = &Service{}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	dashboardService dashboards.DashboardService) *Service {
+	s := &Service{
+		store:            &sqlStore{db: db},
+		cfg:              cfg,
+		linter:           dashboardlint.NewLinter(dashboardlint.DefaultRules()...),
+		routeRegister:    routeRegister,
+		accessControl:    accessControl,
+		dashboardService: dashboardService,
+		log:              log.New("dashboardlint"),
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *Service) LintOnSave() bool {
+	return s.cfg.DashboardLintOnSave
+}
+
+func (s *Service) RegisterRule(rule dashboardlint.Rule) {
+	s.linter.Register(rule)
+}
+
+func (s *Service) Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*dashboardlint.LintResult, error) {
+	if dashboard == nil {
+		return nil, dashboardlint.ErrBadRequest.Errorf("missing dashboard")
+	}
+
+	configs, err := s.orgRuleConfigs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dashboardlint.LintResult{Issues: s.linter.Lint(dashboard, configs)}, nil
+}
+
+func (s *Service) GetRules(ctx context.Context, orgID int64) ([]*dashboardlint.RuleDTO, error) {
+	configs, err := s.orgRuleConfigs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := s.linter.Rules()
+	result := make([]*dashboardlint.RuleDTO, 0, len(rules))
+	for _, rule := range rules {
+		cfg, ok := configs[rule.Name()]
+		if !ok {
+			cfg = dashboardlint.DefaultRuleConfig(rule)
+		}
+		result = append(result, ruleDTO(rule, cfg))
+	}
+	return result, nil
+}
+
+func (s *Service) UpdateRule(ctx context.Context, cmd *dashboardlint.UpdateRuleCommand) (*dashboardlint.RuleDTO, error) {
+	rule, ok := s.linter.Rule(cmd.Rule)
+	if !ok {
+		return nil, dashboardlint.ErrRuleNotFound.Errorf("lint rule %s not found", cmd.Rule)
+	}
+
+	severity := cmd.Severity
+	if severity == "" {
+		severity = rule.DefaultSeverity()
+	}
+	if !severity.IsValid() {
+		return nil, dashboardlint.ErrInvalidSeverity.Errorf("invalid severity %q", cmd.Severity)
+	}
+	settings := cmd.Settings
+	if settings == nil {
+		settings = simplejson.NewFromAny(rule.DefaultSettings())
+	}
+
+	orgRule := &dashboardlint.OrgRule{
+		OrgID:    cmd.OrgID,
+		Rule:     rule.Name(),
+		Enabled:  cmd.Enabled,
+		Severity: severity,
+		Settings: settings,
+		Updated:  time.Now(),
+	}
+	if err := s.store.Upsert(ctx, orgRule); err != nil {
+		return nil, err
+	}
+
+	return ruleDTO(rule, dashboardlint.RuleConfig{Enabled: orgRule.Enabled, Severity: orgRule.Severity, Settings: orgRule.Settings}), nil
+}
+
+// orgRuleConfigs returns the stored rule configurations of the organization by rule name.
+func (s *Service) orgRuleConfigs(ctx context.Context, orgID int64) (map[string]dashboardlint.RuleConfig, error) {
+	orgRules, err := s.store.List(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]dashboardlint.RuleConfig, len(orgRules))
+	for _, r := range orgRules {
+		configs[r.Rule] = dashboardlint.RuleConfig{
+			Enabled:  r.Enabled,
+			Severity: r.Severity,
+			Settings: r.Settings,
+		}
+	}
+	return configs, nil
+}
+
+func ruleDTO(rule dashboardlint.Rule, cfg dashboardlint.RuleConfig) *dashboardlint.RuleDTO {
+	return &dashboardlint.RuleDTO{
+		Name:        rule.Name(),
+		Description: rule.Description(),
+		Enabled:     cfg.Enabled,
+		Severity:    cfg.Severity,
+		Settings:    cfg.Settings,
+	}
+}
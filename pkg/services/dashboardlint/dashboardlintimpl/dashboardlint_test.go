@@ -0,0 +1,96 @@
+package dashboardlintimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationDashboardLint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := &Service{
+		store:  &sqlStore{db: db.InitTestDB(t)},
+		cfg:    setting.NewCfg(),
+		linter: dashboardlint.NewLinter(dashboardlint.DefaultRules()...),
+		log:    log.NewNopLogger(),
+	}
+	ctx := context.Background()
+	dashboard := simplejson.NewFromAny(map[string]any{
+		"refresh": "5s",
+		"panels": []any{
+			map[string]any{"id": 1, "type": "graph"},
+			map[string]any{"id": 2, "type": "timeseries"},
+		},
+	})
+
+	t.Run("lints with the default rules", func(t *testing.T) {
+		result, err := s.Lint(ctx, 1, dashboard)
+		require.NoError(t, err)
+		require.Len(t, result.Issues, 2)
+		require.Equal(t, "deprecated-panel-type", result.Issues[0].Rule)
+		require.Equal(t, "refresh-too-frequent", result.Issues[1].Rule)
+	})
+
+	t.Run("lints with the rules configured in the organization", func(t *testing.T) {
+		_, err := s.UpdateRule(ctx, &dashboardlint.UpdateRuleCommand{OrgID: 1, Rule: "deprecated-panel-type", Enabled: false})
+		require.NoError(t, err)
+		_, err = s.UpdateRule(ctx, &dashboardlint.UpdateRuleCommand{
+			OrgID: 1, Rule: "too-many-panels", Enabled: true, Severity: dashboardlint.SeverityError,
+			Settings: simplejson.NewFromAny(map[string]any{"maxPanels": 1}),
+		})
+		require.NoError(t, err)
+		// updating a rule replaces its configuration
+		_, err = s.UpdateRule(ctx, &dashboardlint.UpdateRuleCommand{OrgID: 1, Rule: "refresh-too-frequent", Enabled: true})
+		require.NoError(t, err)
+		_, err = s.UpdateRule(ctx, &dashboardlint.UpdateRuleCommand{OrgID: 1, Rule: "refresh-too-frequent", Enabled: false})
+		require.NoError(t, err)
+
+		result, err := s.Lint(ctx, 1, dashboard)
+		require.NoError(t, err)
+		require.Len(t, result.Issues, 1)
+		require.Equal(t, "too-many-panels", result.Issues[0].Rule)
+		require.Equal(t, dashboardlint.SeverityError, result.Issues[0].Severity)
+
+		rules, err := s.GetRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, len(dashboardlint.DefaultRules()))
+		for _, r := range rules {
+			switch r.Name {
+			case "deprecated-panel-type", "refresh-too-frequent":
+				require.False(t, r.Enabled)
+			case "too-many-panels":
+				require.Equal(t, 1, r.Settings.Get("maxPanels").MustInt())
+			default:
+				require.True(t, r.Enabled)
+			}
+		}
+
+		// other organizations keep the defaults
+		result, err = s.Lint(ctx, 2, dashboard)
+		require.NoError(t, err)
+		require.Len(t, result.Issues, 2)
+	})
+
+	t.Run("rejects unknown rules and severities", func(t *testing.T) {
+		_, err := s.UpdateRule(ctx, &dashboardlint.UpdateRuleCommand{OrgID: 1, Rule: "unknown", Enabled: true})
+		require.ErrorIs(t, err, dashboardlint.ErrRuleNotFound)
+
+		_, err = s.UpdateRule(ctx, &dashboardlint.UpdateRuleCommand{OrgID: 1, Rule: "too-many-panels", Enabled: true, Severity: "fatal"})
+		require.ErrorIs(t, err, dashboardlint.ErrInvalidSeverity)
+	})
+}
@@ -0,0 +1,46 @@
+package dashboardlintimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+)
+
+type store interface {
+	List(ctx context.Context, orgID int64) ([]*dashboardlint.OrgRule, error)
+	// Upsert stores the configuration of the rule in the organization, replacing the existing one.
+	Upsert(ctx context.Context, rule *dashboardlint.OrgRule) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) List(ctx context.Context, orgID int64) ([]*dashboardlint.OrgRule, error) {
+	rules := make([]*dashboardlint.OrgRule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("rule").Find(&rules)
+	})
+	return rules, err
+}
+
+func (s *sqlStore) Upsert(ctx context.Context, rule *dashboardlint.OrgRule) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &dashboardlint.OrgRule{}
+		has, err := sess.Where("org_id = ? AND rule = ?", rule.OrgID, rule.Rule).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(rule)
+			return err
+		}
+
+		rule.ID = existing.ID
+		_, err = sess.ID(rule.ID).AllCols().Update(rule)
+		return err
+	})
+}
@@ -0,0 +1,116 @@
+package dashboardlint
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Rule checks dashboards for one kind of problem. Rules are registered with the Linter and can be
+// enabled, disabled and configured per organization.
+type Rule interface {
+	Name() string
+	Description() string
+	// DefaultSeverity is the severity of the issues of the rule when the organization does not configure it.
+	DefaultSeverity() Severity
+	// DefaultSettings are the settings of the rule when the organization does not configure them.
+	DefaultSettings() map[string]any
+	// Check returns the problems found in the dashboard JSON. The rule and severity of the
+	// returned issues are set by the Linter.
+	Check(dashboard *simplejson.Json, settings *simplejson.Json) []Issue
+}
+
+// Linter runs the registered rules over dashboards.
+type Linter struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// NewLinter returns a linter running the given rules.
+func NewLinter(rules ...Rule) *Linter {
+	l := &Linter{rules: make(map[string]Rule, len(rules))}
+	for _, r := range rules {
+		l.Register(r)
+	}
+	return l
+}
+
+// Register adds the rule to the linter, replacing the rule with the same name.
+func (l *Linter) Register(rule Rule) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rules[rule.Name()] = rule
+}
+
+// Rules returns the registered rules sorted by name.
+func (l *Linter) Rules() []Rule {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	rules := make([]Rule, 0, len(l.rules))
+	for _, r := range l.rules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name() < rules[j].Name()
+	})
+	return rules
+}
+
+// Rule returns the registered rule with the given name.
+func (l *Linter) Rule(name string) (Rule, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	r, ok := l.rules[name]
+	return r, ok
+}
+
+// Lint runs the rules over the dashboard JSON. Rules missing from configs run with their defaults.
+func (l *Linter) Lint(dashboard *simplejson.Json, configs map[string]RuleConfig) []Issue {
+	issues := make([]Issue, 0)
+	for _, rule := range l.Rules() {
+		cfg, ok := configs[rule.Name()]
+		if !ok {
+			cfg = DefaultRuleConfig(rule)
+		}
+		if !cfg.Enabled {
+			continue
+		}
+
+		severity := cfg.Severity
+		if !severity.IsValid() {
+			severity = rule.DefaultSeverity()
+		}
+		settings := cfg.Settings
+		if settings == nil {
+			settings = simplejson.NewFromAny(rule.DefaultSettings())
+		}
+
+		for _, issue := range rule.Check(dashboard, settings) {
+			issue.Rule = rule.Name()
+			issue.Severity = severity
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// DefaultRuleConfig returns the configuration of the rule in organizations that do not configure it.
+func DefaultRuleConfig(rule Rule) RuleConfig {
+	return RuleConfig{
+		Enabled:  true,
+		Severity: rule.DefaultSeverity(),
+		Settings: simplejson.NewFromAny(rule.DefaultSettings()),
+	}
+}
+
+// HasErrors returns whether any of the issues has the error severity.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
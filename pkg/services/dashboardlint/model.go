@@ -0,0 +1,83 @@
+package dashboardlint
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrBadRequest      = errutil.BadRequest("dashboardlint.bad-request")
+	ErrRuleNotFound    = errutil.NotFound("dashboardlint.rule-not-found", errutil.WithPublicMessage("Lint rule not found"))
+	ErrInvalidSeverity = errutil.ValidationFailed("dashboardlint.invalid-severity", errutil.WithPublicMessage("Severity must be one of warning or error"))
+)
+
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+func (s Severity) IsValid() bool {
+	return s == SeverityWarning || s == SeverityError
+}
+
+// Issue is a problem found in a dashboard by a lint rule.
+type Issue struct {
+	Rule       string   `json:"rule"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	PanelID    int64    `json:"panelId,omitempty"`
+	PanelTitle string   `json:"panelTitle,omitempty"`
+}
+
+// RuleConfig configures a lint rule for an organization.
+type RuleConfig struct {
+	Enabled  bool
+	Severity Severity
+	// Settings are passed to the rule, e.g. the maximum number of panels.
+	Settings *simplejson.Json
+}
+
+// OrgRule is the stored configuration of a lint rule for an organization. Rules without stored
+// configuration are enabled with their default severity and settings.
+type OrgRule struct {
+	ID       int64            `xorm:"pk autoincr 'id'"`
+	OrgID    int64            `xorm:"org_id"`
+	Rule     string           `xorm:"rule"`
+	Enabled  bool             `xorm:"enabled"`
+	Severity Severity         `xorm:"severity"`
+	Settings *simplejson.Json `xorm:"settings"`
+	Updated  time.Time        `xorm:"updated"`
+}
+
+func (OrgRule) TableName() string {
+	return "dashboard_lint_rule"
+}
+
+// RuleDTO describes a lint rule and its configuration in an organization.
+type RuleDTO struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Enabled     bool             `json:"enabled"`
+	Severity    Severity         `json:"severity"`
+	Settings    *simplejson.Json `json:"settings"`
+}
+
+type UpdateRuleCommand struct {
+	OrgID    int64            `json:"-"`
+	Rule     string           `json:"-"`
+	Enabled  bool             `json:"enabled"`
+	Severity Severity         `json:"severity"`
+	Settings *simplejson.Json `json:"settings"`
+}
+
+type LintDashboardCommand struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+type LintResult struct {
+	Issues []Issue `json:"issues"`
+}
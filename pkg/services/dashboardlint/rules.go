@@ -0,0 +1,282 @@
+package dashboardlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// DefaultRules returns the built-in lint rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		deprecatedPanelTypeRule{},
+		missingDatasourceVariableRule{},
+		hardcodedDatasourceRule{},
+		frequentRefreshRule{},
+		tooManyPanelsRule{},
+		counterWithoutRateRule{},
+	}
+}
+
+// deprecatedPanelTypes maps deprecated panel types to their replacement.
+var deprecatedPanelTypes = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+type deprecatedPanelTypeRule struct{}
+
+func (deprecatedPanelTypeRule) Name() string { return "deprecated-panel-type" }
+func (deprecatedPanelTypeRule) Description() string {
+	return "Panels should not use deprecated panel types."
+}
+func (deprecatedPanelTypeRule) DefaultSeverity() Severity       { return SeverityWarning }
+func (deprecatedPanelTypeRule) DefaultSettings() map[string]any { return map[string]any{} }
+
+func (deprecatedPanelTypeRule) Check(dashboard *simplejson.Json, _ *simplejson.Json) []Issue {
+	var issues []Issue
+	for _, p := range dashboardPanels(dashboard) {
+		panelType := p.Get("type").MustString()
+		if replacement, ok := deprecatedPanelTypes[panelType]; ok {
+			issues = append(issues, panelIssue(p, fmt.Sprintf("Panel type %q is deprecated, use %q instead", panelType, replacement)))
+		}
+	}
+	return issues
+}
+
+type missingDatasourceVariableRule struct{}
+
+func (missingDatasourceVariableRule) Name() string { return "missing-datasource-variable" }
+func (missingDatasourceVariableRule) Description() string {
+	return "Dashboards querying data sources should let viewers choose the data source with a data source variable, and only reference variables that exist."
+}
+func (missingDatasourceVariableRule) DefaultSeverity() Severity       { return SeverityWarning }
+func (missingDatasourceVariableRule) DefaultSettings() map[string]any { return map[string]any{} }
+
+func (missingDatasourceVariableRule) Check(dashboard *simplejson.Json, _ *simplejson.Json) []Issue {
+	variables := map[string]bool{}
+	hasDatasourceVariable := false
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variables[variable.Get("name").MustString()] = true
+		if variable.Get("type").MustString() == "datasource" {
+			hasDatasourceVariable = true
+		}
+	}
+
+	var issues []Issue
+	usesDatasources := false
+	for _, p := range dashboardPanels(dashboard) {
+		for _, uid := range panelDatasources(p) {
+			if datasources.IsBuiltInDatasource(uid) {
+				continue
+			}
+			usesDatasources = true
+			if name, ok := variableName(uid); ok && !variables[name] {
+				issues = append(issues, panelIssue(p, fmt.Sprintf("Data source variable %q is not defined", name)))
+			}
+		}
+	}
+
+	if usesDatasources && !hasDatasourceVariable {
+		issues = append(issues, Issue{Message: "Dashboard has no data source variable"})
+	}
+	return issues
+}
+
+type hardcodedDatasourceRule struct{}
+
+func (hardcodedDatasourceRule) Name() string { return "hardcoded-datasource-uid" }
+func (hardcodedDatasourceRule) Description() string {
+	return "Panels and queries should reference data sources through variables rather than fixed UIDs or names."
+}
+func (hardcodedDatasourceRule) DefaultSeverity() Severity       { return SeverityWarning }
+func (hardcodedDatasourceRule) DefaultSettings() map[string]any { return map[string]any{} }
+
+func (hardcodedDatasourceRule) Check(dashboard *simplejson.Json, _ *simplejson.Json) []Issue {
+	var issues []Issue
+	for _, p := range dashboardPanels(dashboard) {
+		seen := map[string]bool{}
+		for _, uid := range panelDatasources(p) {
+			if seen[uid] || datasources.IsBuiltInDatasource(uid) {
+				continue
+			}
+			seen[uid] = true
+			if _, ok := variableName(uid); !ok {
+				issues = append(issues, panelIssue(p, fmt.Sprintf("Data source %q is hardcoded", uid)))
+			}
+		}
+	}
+	return issues
+}
+
+type frequentRefreshRule struct{}
+
+func (frequentRefreshRule) Name() string { return "refresh-too-frequent" }
+func (frequentRefreshRule) Description() string {
+	return "Dashboards should not refresh more often than the minimum interval."
+}
+func (frequentRefreshRule) DefaultSeverity() Severity { return SeverityWarning }
+func (frequentRefreshRule) DefaultSettings() map[string]any {
+	return map[string]any{"minInterval": "1m"}
+}
+
+func (frequentRefreshRule) Check(dashboard *simplejson.Json, settings *simplejson.Json) []Issue {
+	refresh := dashboard.Get("refresh").MustString()
+	if refresh == "" {
+		return nil
+	}
+	interval, err := gtime.ParseDuration(refresh)
+	if err != nil {
+		return []Issue{{Message: fmt.Sprintf("Invalid refresh interval %q", refresh)}}
+	}
+	minInterval, err := gtime.ParseDuration(settings.Get("minInterval").MustString("1m"))
+	if err != nil {
+		minInterval = time.Minute
+	}
+	if interval < minInterval {
+		return []Issue{{Message: fmt.Sprintf("Dashboard refreshes every %s, the minimum is %s", refresh, minInterval)}}
+	}
+	return nil
+}
+
+type tooManyPanelsRule struct{}
+
+func (tooManyPanelsRule) Name() string { return "too-many-panels" }
+func (tooManyPanelsRule) Description() string {
+	return "Dashboards with many panels are slow to load, split them or move panels into collapsed rows."
+}
+func (tooManyPanelsRule) DefaultSeverity() Severity { return SeverityWarning }
+func (tooManyPanelsRule) DefaultSettings() map[string]any {
+	return map[string]any{"maxPanels": 50}
+}
+
+func (tooManyPanelsRule) Check(dashboard *simplejson.Json, settings *simplejson.Json) []Issue {
+	maxPanels := settings.Get("maxPanels").MustInt(50)
+	if count := len(dashboardPanels(dashboard)); count > maxPanels {
+		return []Issue{{Message: fmt.Sprintf("Dashboard has %d panels, the maximum is %d", count, maxPanels)}}
+	}
+	return nil
+}
+
+var (
+	rateFunctionPattern = regexp.MustCompile(`\b(rate|irate|increase)\s*\(`)
+	metricNamePattern   = regexp.MustCompile(`[a-zA-Z_:][a-zA-Z0-9_:]*`)
+)
+
+type counterWithoutRateRule struct{}
+
+func (counterWithoutRateRule) Name() string { return "query-without-rate" }
+func (counterWithoutRateRule) Description() string {
+	return "Prometheus queries over counters should use rate(), irate() or increase()."
+}
+func (counterWithoutRateRule) DefaultSeverity() Severity { return SeverityWarning }
+func (counterWithoutRateRule) DefaultSettings() map[string]any {
+	return map[string]any{"counterSuffixes": []string{"_total", "_count", "_sum", "_bucket"}}
+}
+
+func (counterWithoutRateRule) Check(dashboard *simplejson.Json, settings *simplejson.Json) []Issue {
+	suffixes := settings.Get("counterSuffixes").MustStringArray([]string{"_total", "_count", "_sum", "_bucket"})
+
+	var issues []Issue
+	for _, p := range dashboardPanels(dashboard) {
+		for _, t := range p.Get("targets").MustArray() {
+			target := simplejson.NewFromAny(t)
+			expr := target.Get("expr").MustString()
+			if expr == "" || rateFunctionPattern.MatchString(expr) {
+				continue
+			}
+			if counter := findCounter(expr, suffixes); counter != "" {
+				issues = append(issues, panelIssue(p, fmt.Sprintf("Query %s uses counter %s without rate()", target.Get("refId").MustString(), counter)))
+			}
+		}
+	}
+	return issues
+}
+
+func findCounter(expr string, suffixes []string) string {
+	for _, name := range metricNamePattern.FindAllString(expr, -1) {
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(name, suffix) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// dashboardPanels returns the panels of the dashboard, including the panels of collapsed rows and
+// of the legacy rows layout. Rows themselves are not returned.
+func dashboardPanels(dashboard *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	var add func(list []any)
+	add = func(list []any) {
+		for _, item := range list {
+			p := simplejson.NewFromAny(item)
+			if p.Get("type").MustString() == "row" {
+				add(p.Get("panels").MustArray())
+				continue
+			}
+			panels = append(panels, p)
+		}
+	}
+
+	add(dashboard.Get("panels").MustArray())
+	for _, row := range dashboard.Get("rows").MustArray() {
+		add(simplejson.NewFromAny(row).Get("panels").MustArray())
+	}
+	return panels
+}
+
+// panelDatasources returns the UIDs, or legacy names, of the data sources of the panel and its queries.
+func panelDatasources(p *simplejson.Json) []string {
+	var uids []string
+	if uid, ok := datasourceUID(p.Get("datasource")); ok {
+		uids = append(uids, uid)
+	}
+	for _, t := range p.Get("targets").MustArray() {
+		if uid, ok := datasourceUID(simplejson.NewFromAny(t).Get("datasource")); ok {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+func datasourceUID(ds *simplejson.Json) (string, bool) {
+	if name, err := ds.String(); err == nil {
+		return name, name != ""
+	}
+	uid := ds.Get("uid").MustString()
+	return uid, uid != ""
+}
+
+// variableName returns the name of the variable referenced as $name or ${name}.
+func variableName(value string) (string, bool) {
+	if !strings.HasPrefix(value, "$") {
+		return "", false
+	}
+	name := strings.TrimPrefix(value, "$")
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "{"), "}")
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	return name, true
+}
+
+func panelIssue(p *simplejson.Json, message string) Issue {
+	return Issue{
+		Message:    message,
+		PanelID:    p.Get("id").MustInt64(),
+		PanelTitle: p.Get("title").MustString(),
+	}
+}
@@ -0,0 +1,132 @@
+package dashboardlint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const testDashboard = `{
+  "title": "Production",
+  "refresh": "10s",
+  "templating": {
+    "list": [
+      {"name": "cluster", "type": "query"}
+    ]
+  },
+  "panels": [
+    {
+      "id": 1,
+      "title": "Requests",
+      "type": "graph",
+      "datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"},
+      "targets": [
+        {"refId": "A", "expr": "sum(http_requests_total{cluster=\"$cluster\"})"},
+        {"refId": "B", "expr": "sum(rate(http_requests_total[5m]))"}
+      ]
+    },
+    {
+      "id": 2,
+      "title": "Errors",
+      "type": "timeseries",
+      "datasource": {"type": "prometheus", "uid": "${ds}"},
+      "targets": [
+        {"refId": "A", "expr": "sum(increase(http_errors_total[1h]))"}
+      ]
+    },
+    {
+      "id": 3,
+      "title": "Details",
+      "type": "row",
+      "collapsed": true,
+      "panels": [
+        {"id": 4, "title": "Uptime", "type": "singlestat", "datasource": "-- Grafana --"}
+      ]
+    }
+  ]
+}`
+
+func TestDefaultRules(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+
+	issues := NewLinter(DefaultRules()...).Lint(dashboard, nil)
+
+	byRule := map[string][]Issue{}
+	for _, issue := range issues {
+		require.Equal(t, SeverityWarning, issue.Severity)
+		byRule[issue.Rule] = append(byRule[issue.Rule], issue)
+	}
+
+	require.Len(t, byRule["deprecated-panel-type"], 2)
+	require.Equal(t, int64(1), byRule["deprecated-panel-type"][0].PanelID)
+	require.Equal(t, "Uptime", byRule["deprecated-panel-type"][1].PanelTitle)
+
+	require.Len(t, byRule["missing-datasource-variable"], 2)
+	require.Equal(t, `Data source variable "ds" is not defined`, byRule["missing-datasource-variable"][0].Message)
+	require.Equal(t, "Dashboard has no data source variable", byRule["missing-datasource-variable"][1].Message)
+
+	require.Len(t, byRule["hardcoded-datasource-uid"], 1)
+	require.Equal(t, "Requests", byRule["hardcoded-datasource-uid"][0].PanelTitle)
+
+	require.Len(t, byRule["refresh-too-frequent"], 1)
+
+	require.Len(t, byRule["query-without-rate"], 1)
+	require.Equal(t, "Query A uses counter http_requests_total without rate()", byRule["query-without-rate"][0].Message)
+
+	require.Empty(t, byRule["too-many-panels"])
+}
+
+func TestLinterConfigs(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+	linter := NewLinter(DefaultRules()...)
+
+	t.Run("disabled rules do not run", func(t *testing.T) {
+		configs := map[string]RuleConfig{}
+		for _, rule := range linter.Rules() {
+			configs[rule.Name()] = RuleConfig{Enabled: false}
+		}
+		require.Empty(t, linter.Lint(dashboard, configs))
+	})
+
+	t.Run("rules use the configured severity and settings", func(t *testing.T) {
+		issues := linter.Lint(dashboard, map[string]RuleConfig{
+			"too-many-panels":      {Enabled: true, Severity: SeverityError, Settings: simplejson.NewFromAny(map[string]any{"maxPanels": 2})},
+			"refresh-too-frequent": {Enabled: true, Settings: simplejson.NewFromAny(map[string]any{"minInterval": "5s"})},
+		})
+
+		var tooMany []Issue
+		for _, issue := range issues {
+			require.NotEqual(t, "refresh-too-frequent", issue.Rule)
+			if issue.Rule == "too-many-panels" {
+				tooMany = append(tooMany, issue)
+			}
+		}
+		require.Len(t, tooMany, 1)
+		require.Equal(t, SeverityError, tooMany[0].Severity)
+		require.Equal(t, "Dashboard has 3 panels, the maximum is 2", tooMany[0].Message)
+		require.True(t, HasErrors(issues))
+	})
+
+	t.Run("registered rules replace rules with the same name", func(t *testing.T) {
+		l := NewLinter(DefaultRules()...)
+		l.Register(fakeRule{name: "too-many-panels"})
+		rule, ok := l.Rule("too-many-panels")
+		require.True(t, ok)
+		require.Equal(t, fakeRule{name: "too-many-panels"}, rule)
+		require.Len(t, l.Rules(), len(DefaultRules()))
+	})
+}
+
+type fakeRule struct {
+	name string
+}
+
+func (r fakeRule) Name() string                      { return r.name }
+func (fakeRule) Description() string                 { return "fake" }
+func (fakeRule) DefaultSeverity() Severity           { return SeverityError }
+func (fakeRule) DefaultSettings() map[string]any     { return map[string]any{} }
+func (fakeRule) Check(_, _ *simplejson.Json) []Issue { return []Issue{{Message: "fake"}} }
@@ -172,6 +172,20 @@ func (_m *FakeDashboardProvisioning) SaveProvisionedDashboard(ctx context.Contex
 	return r0, r1
 }
 
+// SaveProvisionedDashboardData provides a mock function with given fields: ctx, provisioning
+func (_m *FakeDashboardProvisioning) SaveProvisionedDashboardData(ctx context.Context, provisioning *DashboardProvisioning) error {
+	ret := _m.Called(ctx, provisioning)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DashboardProvisioning) error); ok {
+		r0 = rf(ctx, provisioning)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UnprovisionDashboard provides a mock function with given fields: ctx, dashboardID
 func (_m *FakeDashboardProvisioning) UnprovisionDashboard(ctx context.Context, dashboardID int64) error {
 	ret := _m.Called(ctx, dashboardID)
@@ -186,6 +186,17 @@ func (d *dashboardStore) SaveDashboard(ctx context.Context, cmd dashboards.SaveD
 	return result, err
 }
 
+// SaveProvisionedDashboardData updates the provisioning data of an already provisioned dashboard without
+// saving the dashboard itself.
+func (d *dashboardStore) SaveProvisionedDashboardData(ctx context.Context, provisioning *dashboards.DashboardProvisioning) error {
+	ctx, span := tracer.Start(ctx, "dashboards.database.SaveProvisionedDashboardData")
+	defer span.End()
+
+	return d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		return saveProvisionedData(sess, provisioning, &dashboards.Dashboard{ID: provisioning.DashboardID})
+	})
+}
+
 // UnprovisionDashboard removes row in dashboard_provisioning for the dashboard making it seem as if manually created.
 // The dashboard will still have `created_by = -1` to see it was not created by any particular user.
 func (d *dashboardStore) UnprovisionDashboard(ctx context.Context, id int64) error {
@@ -198,6 +198,7 @@ type SaveDashboardCommand struct {
 	Dashboard    *simplejson.Json `json:"dashboard" binding:"Required"`
 	UserID       int64            `json:"userId" xorm:"user_id"`
 	Overwrite    bool             `json:"overwrite"`
+	Merge        bool             `json:"merge"`
 	Message      string           `json:"message"`
 	OrgID        int64            `json:"-" xorm:"org_id"`
 	RestoredFrom int              `json:"-"`
@@ -221,6 +222,8 @@ type DashboardProvisioning struct {
 	ExternalID  string `xorm:"external_id"`
 	CheckSum    string
 	Updated     int64
+	// CommitSHA is the commit the dashboard was provisioned from by git provider.
+	CommitSHA string `xorm:"commit_sha"`
 }
 
 type DeleteDashboardCommand struct {
@@ -495,6 +495,12 @@ func (dr *DashboardServiceImpl) ImportDashboard(ctx context.Context, dto *dashbo
 	return dash, nil
 }
 
+// SaveProvisionedDashboardData updates the provisioning data of a provisioned dashboard, for example when the
+// dashboard has been written back to its provisioning file.
+func (dr *DashboardServiceImpl) SaveProvisionedDashboardData(ctx context.Context, provisioning *dashboards.DashboardProvisioning) error {
+	return dr.dashboardStore.SaveProvisionedDashboardData(ctx, provisioning)
+}
+
 // UnprovisionDashboard removes info about dashboard being provisioned. Used after provisioning configs are changed
 // and provisioned dashboards are left behind but not deleted.
 func (dr *DashboardServiceImpl) UnprovisionDashboard(ctx context.Context, dashboardId int64) error {
@@ -604,6 +604,24 @@ func (_m *FakeDashboardStore) SoftDeleteDashboardsInFolders(ctx context.Context,
 	return r0
 }
 
+// SaveProvisionedDashboardData provides a mock function with given fields: ctx, provisioning
+func (_m *FakeDashboardStore) SaveProvisionedDashboardData(ctx context.Context, provisioning *DashboardProvisioning) error {
+	ret := _m.Called(ctx, provisioning)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveProvisionedDashboardData")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DashboardProvisioning) error); ok {
+		r0 = rf(ctx, provisioning)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UnprovisionDashboard provides a mock function with given fields: ctx, id
 func (_m *FakeDashboardStore) UnprovisionDashboard(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
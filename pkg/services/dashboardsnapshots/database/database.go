@@ -66,6 +66,11 @@ func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cm
 			Expires:            expires,
 			Created:            time.Now(),
 			Updated:            time.Now(),
+			RefreshInterval:    cmd.RefreshInterval,
+		}
+		if cmd.RefreshInterval > 0 {
+			nextRefresh := snapshot.Created.Add(time.Second * time.Duration(cmd.RefreshInterval))
+			snapshot.NextRefresh = &nextRefresh
 		}
 		_, err := sess.Insert(snapshot)
 		result = snapshot
@@ -151,3 +156,33 @@ func (d *DashboardSnapshotStore) SearchDashboardSnapshots(ctx context.Context, q
 	}
 	return queryResult, nil
 }
+
+// GetSnapshotsToRefresh returns the snapshots with a refresh interval that are due for a refresh and not expired.
+func (d *DashboardSnapshotStore) GetSnapshotsToRefresh(ctx context.Context, query *dashboardsnapshots.GetSnapshotsToRefreshQuery) ([]*dashboardsnapshots.DashboardSnapshot, error) {
+	snapshots := make([]*dashboardsnapshots.DashboardSnapshot, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("refresh_interval > 0 AND next_refresh <= ? AND expires > ? AND external = ?", query.Now, query.Now, false).
+			Asc("next_refresh").
+			Find(&snapshots)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// UpdateDashboardSnapshotRefresh stores the refreshed dashboard of a snapshot and schedules the next refresh.
+func (d *DashboardSnapshotStore) UpdateDashboardSnapshotRefresh(ctx context.Context, cmd *dashboardsnapshots.UpdateDashboardSnapshotRefreshCommand) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		snapshot := &dashboardsnapshots.DashboardSnapshot{NextRefresh: &cmd.NextRefresh}
+		cols := []string{"next_refresh"}
+		if cmd.DashboardEncrypted != nil {
+			snapshot.DashboardEncrypted = cmd.DashboardEncrypted
+			snapshot.Updated = time.Now()
+			cols = append(cols, "dashboard_encrypted", "updated")
+		}
+
+		_, err := sess.ID(cmd.ID).Cols(cols...).Update(snapshot)
+		return err
+	})
+}
@@ -199,6 +199,74 @@ func TestIntegrationDeleteExpiredSnapshots(t *testing.T) {
 	})
 }
 
+func TestIntegrationRefreshSnapshots(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlstore := db.InitTestDB(t)
+	dashStore := NewStore(sqlstore)
+	ctx := context.Background()
+
+	createTestSnapshot(t, dashStore, "static", 48000)
+	refreshed, err := dashStore.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+		Key:                "refreshed",
+		DeleteKey:          "deleterefreshed",
+		RefreshInterval:    3600,
+		DashboardEncrypted: []byte("v1"),
+		UserID:             1000,
+		OrgID:              1,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, refreshed.NextRefresh)
+	require.WithinDuration(t, time.Now().Add(time.Hour), *refreshed.NextRefresh, time.Minute)
+
+	t.Run("Should not return snapshots before their next refresh", func(t *testing.T) {
+		snapshots, err := dashStore.GetSnapshotsToRefresh(ctx, &dashboardsnapshots.GetSnapshotsToRefreshQuery{Now: time.Now()})
+		require.NoError(t, err)
+		require.Empty(t, snapshots)
+	})
+
+	t.Run("Should return snapshots due for a refresh", func(t *testing.T) {
+		snapshots, err := dashStore.GetSnapshotsToRefresh(ctx, &dashboardsnapshots.GetSnapshotsToRefreshQuery{Now: time.Now().Add(2 * time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		require.Equal(t, "refreshed", snapshots[0].Key)
+	})
+
+	t.Run("Should not return expired snapshots", func(t *testing.T) {
+		snapshots, err := dashStore.GetSnapshotsToRefresh(ctx, &dashboardsnapshots.GetSnapshotsToRefreshQuery{Now: time.Now().Add(time.Hour * 24 * 365 * 51)})
+		require.NoError(t, err)
+		require.Empty(t, snapshots)
+	})
+
+	t.Run("Should only reschedule when no dashboard is given", func(t *testing.T) {
+		next := time.Now().Add(3 * time.Hour).Truncate(time.Second)
+		err := dashStore.UpdateDashboardSnapshotRefresh(ctx, &dashboardsnapshots.UpdateDashboardSnapshotRefreshCommand{ID: refreshed.ID, NextRefresh: next})
+		require.NoError(t, err)
+
+		snapshot, err := dashStore.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "refreshed"})
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), snapshot.DashboardEncrypted)
+		require.True(t, next.Equal(*snapshot.NextRefresh))
+	})
+
+	t.Run("Should store the refreshed dashboard and keep the key", func(t *testing.T) {
+		next := time.Now().Add(4 * time.Hour).Truncate(time.Second)
+		err := dashStore.UpdateDashboardSnapshotRefresh(ctx, &dashboardsnapshots.UpdateDashboardSnapshotRefreshCommand{
+			ID:                 refreshed.ID,
+			DashboardEncrypted: []byte("v2"),
+			NextRefresh:        next,
+		})
+		require.NoError(t, err)
+
+		snapshot, err := dashStore.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "refreshed"})
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), snapshot.DashboardEncrypted)
+		require.Equal(t, "deleterefreshed", snapshot.DeleteKey)
+		require.True(t, next.Equal(*snapshot.NextRefresh))
+	})
+}
+
 func createTestSnapshot(t *testing.T, dashStore *DashboardSnapshotStore, key string, expires int64) *dashboardsnapshots.DashboardSnapshot {
 	cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
 		Key:       key,
@@ -8,6 +8,9 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
+// MinRefreshInterval is the shortest interval snapshots can be refreshed at, refreshing runs all the panel queries of the dashboard.
+const MinRefreshInterval = 5 * time.Minute
+
 // DashboardSnapshot model
 type DashboardSnapshot struct {
 	ID                int64 `xorm:"pk autoincr 'id'"`
@@ -24,6 +27,10 @@ type DashboardSnapshot struct {
 	Created time.Time
 	Updated time.Time
 
+	// Refresh interval in seconds of snapshots refreshed by re-running the panel queries, 0 for static snapshots
+	RefreshInterval int64
+	NextRefresh     *time.Time
+
 	Dashboard          *simplejson.Json
 	DashboardEncrypted []byte
 }
@@ -41,6 +48,8 @@ type DashboardSnapshotDTO struct {
 	Expires time.Time `json:"expires"`
 	Created time.Time `json:"created"`
 	Updated time.Time `json:"updated"`
+
+	RefreshInterval int64 `json:"refreshInterval"`
 }
 
 // -----------------
@@ -63,6 +72,12 @@ type CreateDashboardSnapshotCommand struct {
 	// required:false
 	DeleteKey string `json:"deleteKey"`
 
+	// Refresh the snapshot data every refreshInterval seconds by re-running the panel queries of the dashboard as the snapshot creator.
+	// The snapshot key stays the same. Not supported for external snapshots.
+	// required:false
+	// default:0
+	RefreshInterval int64 `json:"refreshInterval"`
+
 	OrgID  int64 `json:"-"`
 	UserID int64 `json:"-"`
 
@@ -77,6 +92,18 @@ type DeleteExpiredSnapshotsCommand struct {
 	DeletedRows int64
 }
 
+// UpdateDashboardSnapshotRefreshCommand stores the result of a snapshot refresh. The dashboard is kept when
+// DashboardEncrypted is nil, e.g. when the refresh failed and is only rescheduled.
+type UpdateDashboardSnapshotRefreshCommand struct {
+	ID                 int64
+	DashboardEncrypted []byte
+	NextRefresh        time.Time
+}
+
+type GetSnapshotsToRefreshQuery struct {
+	Now time.Time
+}
+
 type GetDashboardSnapshotQuery struct {
 	Key       string
 	DeleteKey string
@@ -59,6 +59,16 @@ func CreateDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.S
 		return
 	}
 
+	if cmd.RefreshInterval < 0 || (cmd.RefreshInterval > 0 && time.Duration(cmd.RefreshInterval)*time.Second < MinRefreshInterval) {
+		c.JsonApiErr(http.StatusBadRequest, fmt.Sprintf("Refresh interval must be at least %d seconds", int64(MinRefreshInterval.Seconds())), nil)
+		return
+	}
+
+	if cmd.RefreshInterval > 0 && cmd.DashboardCreateCommand.External {
+		c.JsonApiErr(http.StatusBadRequest, "Refreshing external snapshots is not supported", nil)
+		return
+	}
+
 	if cmd.DashboardCreateCommand.Name == "" {
 		cmd.DashboardCreateCommand.Name = "Unnamed snapshot"
 	}
@@ -67,6 +77,10 @@ func CreateDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.S
 	cmd.ExternalURL = ""
 	cmd.OrgID = user.GetOrgID()
 	cmd.UserID, _ = identity.UserIdentifier(user.GetID())
+	if cmd.RefreshInterval > 0 && cmd.UserID == 0 {
+		c.JsonApiErr(http.StatusBadRequest, "Refreshing snapshots requires a user or service account", nil)
+		return
+	}
 	originalDashboardURL, err := createOriginalDashboardURL(&cmd)
 	if err != nil {
 		c.JsonApiErr(http.StatusInternalServerError, "Invalid app URL", err)
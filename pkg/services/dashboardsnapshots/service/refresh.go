@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	refreshTickInterval = time.Minute
+	// failed refreshes are retried after the refresh interval of the snapshot, but at most after maxRetryInterval
+	maxRetryInterval = 10 * time.Minute
+)
+
+// RefreshService re-runs the panel queries of snapshots with a refresh interval as the snapshot creator
+// and replaces the snapshot data. The snapshot keys are not changed.
+type RefreshService struct {
+	cfg              *setting.Cfg
+	store            dashboardsnapshots.Store
+	secretsService   secrets.Service
+	dashboardService dashboards.DashboardService
+	queryService     query.Service
+	userService      user.Service
+	ac               accesscontrol.AccessControl
+	acService        accesscontrol.Service
+	serverLock       *serverlock.ServerLockService
+	log              log.Logger
+}
+
+func ProvideRefreshService(cfg *setting.Cfg, store dashboardsnapshots.Store, secretsService secrets.Service,
+	dashboardService dashboards.DashboardService, queryService query.Service, userService user.Service,
+	ac accesscontrol.AccessControl, acService accesscontrol.Service, serverLock *serverlock.ServerLockService) *RefreshService {
+	return &RefreshService{
+		cfg:              cfg,
+		store:            store,
+		secretsService:   secretsService,
+		dashboardService: dashboardService,
+		queryService:     queryService,
+		userService:      userService,
+		ac:               ac,
+		acService:        acService,
+		serverLock:       serverLock,
+		log:              log.New("dashboardsnapshots.refresh"),
+	}
+}
+
+func (s *RefreshService) IsDisabled() bool {
+	return !s.cfg.SnapshotEnabled
+}
+
+func (s *RefreshService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(refreshTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, "refresh dashboard snapshots", refreshTickInterval, func(ctx context.Context) {
+				s.refreshSnapshots(ctx, time.Now())
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute refresh of dashboard snapshots", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *RefreshService) refreshSnapshots(ctx context.Context, now time.Time) {
+	snapshots, err := s.store.GetSnapshotsToRefresh(ctx, &dashboardsnapshots.GetSnapshotsToRefreshQuery{Now: now})
+	if err != nil {
+		s.log.Error("Failed to get dashboard snapshots to refresh", "error", err)
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		if ctx.Err() != nil {
+			return
+		}
+
+		interval := time.Duration(snapshot.RefreshInterval) * time.Second
+		cmd := &dashboardsnapshots.UpdateDashboardSnapshotRefreshCommand{ID: snapshot.ID, NextRefresh: now.Add(interval)}
+
+		encrypted, err := s.refreshSnapshot(ctx, snapshot, now)
+		if err != nil {
+			s.log.Warn("Failed to refresh dashboard snapshot", "snapshotId", snapshot.ID, "orgId", snapshot.OrgID, "error", err)
+			cmd.NextRefresh = now.Add(min(interval, maxRetryInterval))
+		} else {
+			cmd.DashboardEncrypted = encrypted
+		}
+
+		if err := s.store.UpdateDashboardSnapshotRefresh(ctx, cmd); err != nil {
+			s.log.Error("Failed to update refreshed dashboard snapshot", "snapshotId", snapshot.ID, "error", err)
+		}
+	}
+}
+
+// refreshSnapshot returns the encrypted snapshot dashboard with the panel data replaced by the results of
+// the queries of the live dashboard.
+func (s *RefreshService) refreshSnapshot(ctx context.Context, snapshot *dashboardsnapshots.DashboardSnapshot, now time.Time) ([]byte, error) {
+	if snapshot.DashboardEncrypted == nil {
+		return nil, fmt.Errorf("snapshot has no dashboard")
+	}
+
+	decrypted, err := s.secretsService.Decrypt(ctx, snapshot.DashboardEncrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := simplejson.NewJson(decrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	usr, err := s.getSnapshotCreator(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	uid := model.Get("uid").MustString()
+	if uid == "" {
+		return nil, fmt.Errorf("snapshot does not reference a dashboard")
+	}
+
+	dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: snapshot.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
+	canRead, err := s.ac.Evaluate(ctx, usr, evaluator)
+	if err != nil {
+		return nil, err
+	}
+	if !canRead {
+		return nil, fmt.Errorf("snapshot creator cannot read dashboard %s", uid)
+	}
+
+	from, to := buildTimeRange(model, now)
+	queries := groupQueriesByPanelID(dashboard.Data)
+	variables := getSnapshotVariables(model)
+
+	// a failed panel keeps its previous data and the error, the other panels are still refreshed
+	for _, panelObj := range getFlattenedPanels(model) {
+		panel := simplejson.NewFromAny(panelObj)
+		panelQueries, ok := queries[panel.Get("id").MustInt64()]
+		if !ok || len(panelQueries) == 0 {
+			continue
+		}
+
+		snapshotData, err := s.queryPanel(ctx, usr, panelQueries, variables, from, to)
+		if err != nil {
+			s.log.Warn("Failed to refresh dashboard snapshot panel", "snapshotId", snapshot.ID, "panelId", panel.Get("id").MustInt64(), "error", err)
+			panel.Set("snapshotError", err.Error())
+			continue
+		}
+		panel.Set("snapshotData", snapshotData)
+		panel.Del("snapshotError")
+	}
+
+	model.SetPath([]string{"snapshot", "timestamp"}, now.UTC().Format(time.RFC3339))
+
+	marshalled, err := model.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.secretsService.Encrypt(ctx, marshalled, secrets.WithoutScope())
+}
+
+// queryPanel runs the panel queries with the template variables of the snapshot and returns the new panel snapshot data.
+func (s *RefreshService) queryPanel(ctx context.Context, usr *user.SignedInUser, panelQueries []*simplejson.Json,
+	variables map[string]snapshotVariable, from, to string) ([]any, error) {
+	interpolated := make([]*simplejson.Json, 0, len(panelQueries))
+	for _, query := range panelQueries {
+		value, err := interpolateVariables(query.Interface(), variables)
+		if err != nil {
+			return nil, err
+		}
+		interpolated = append(interpolated, simplejson.NewFromAny(value))
+	}
+
+	res, err := s.queryService.QueryData(ctx, usr, false, dtos.MetricRequest{
+		From:    from,
+		To:      to,
+		Queries: interpolated,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toSnapshotData(res)
+}
+
+// getSnapshotCreator returns the snapshot creator with the permissions in the snapshot organization.
+func (s *RefreshService) getSnapshotCreator(ctx context.Context, snapshot *dashboardsnapshots.DashboardSnapshot) (*user.SignedInUser, error) {
+	if snapshot.UserID == 0 {
+		return nil, fmt.Errorf("snapshot has no creator")
+	}
+
+	usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: snapshot.UserID, OrgID: snapshot.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	if usr.IsDisabled {
+		return nil, fmt.Errorf("snapshot creator is disabled")
+	}
+
+	if usr.Permissions == nil {
+		usr.Permissions = make(map[int64]map[string][]string)
+	}
+
+	if _, ok := usr.Permissions[snapshot.OrgID]; !ok {
+		permissions, err := s.acService.GetUserPermissions(ctx, usr, accesscontrol.Options{ReloadCache: false})
+		if err != nil {
+			return nil, err
+		}
+		usr.Permissions[snapshot.OrgID] = accesscontrol.GroupScopesByActionContext(ctx, permissions)
+	}
+
+	return usr, nil
+}
+
+// buildTimeRange resolves the time range of the snapshot dashboard to epoch milliseconds.
+func buildTimeRange(model *simplejson.Json, now time.Time) (string, string) {
+	from := model.GetPath("time", "from").MustString("now-6h")
+	to := model.GetPath("time", "to").MustString("now")
+
+	location := time.Local
+	if tz := model.Get("timezone").MustString(); tz == "utc" {
+		location = time.UTC
+	} else if loc, err := time.LoadLocation(tz); tz != "" && tz != "browser" && err == nil {
+		location = loc
+	}
+
+	timeRange := gtime.TimeRange{From: from, To: to, Now: now}
+	timeFrom, _ := timeRange.ParseFrom(gtime.WithLocation(location))
+	timeTo, _ := timeRange.ParseTo(gtime.WithLocation(location))
+
+	return strconv.FormatInt(timeFrom.UnixMilli(), 10), strconv.FormatInt(timeTo.UnixMilli(), 10)
+}
+
+// toSnapshotData converts the query responses to the data frame JSON stored in the panel snapshotData.
+func toSnapshotData(res *backend.QueryDataResponse) ([]any, error) {
+	snapshotData := make([]any, 0)
+	for _, dr := range res.Responses {
+		if dr.Error != nil {
+			return nil, dr.Error
+		}
+
+		for _, frame := range dr.Frames {
+			frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+			if err != nil {
+				return nil, err
+			}
+
+			var frameData map[string]any
+			if err := json.Unmarshal(frameJSON, &frameData); err != nil {
+				return nil, err
+			}
+			snapshotData = append(snapshotData, frameData)
+		}
+	}
+	return snapshotData, nil
+}
+
+func getFlattenedPanels(dashboard *simplejson.Json) []any {
+	var flatPanels []any
+	for _, panelObj := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		// collapsed rows contain their panels, expanded rows have no panels
+		if panel.Get("type").MustString() == "row" {
+			if panel.Get("collapsed").MustBool() {
+				flatPanels = append(flatPanels, panel.Get("panels").MustArray()...)
+			}
+		} else {
+			flatPanels = append(flatPanels, panelObj)
+		}
+	}
+	return flatPanels
+}
+
+// groupQueriesByPanelID returns the queries of the dashboard panels, the panel datasource is used for queries without datasource.
+func groupQueriesByPanelID(dashboard *simplejson.Json) map[int64][]*simplejson.Json {
+	result := make(map[int64][]*simplejson.Json)
+
+	for _, panelObj := range getFlattenedPanels(dashboard) {
+		panel := simplejson.NewFromAny(panelObj)
+		hasExpression := panelHasAnExpression(panel)
+
+		var panelQueries []*simplejson.Json
+		for _, queryObj := range panel.Get("targets").MustArray() {
+			query := simplejson.NewFromAny(queryObj)
+			// hidden queries can be needed by expressions, the expression handler removes them from the response
+			if !hasExpression && query.Get("hide").MustBool() {
+				continue
+			}
+
+			if _, ok := query.CheckGet("datasource"); !ok {
+				if datasource, ok := panel.CheckGet("datasource"); ok {
+					query.Set("datasource", datasource.Interface())
+				}
+			}
+			if maxDataPoints, ok := panel.CheckGet("maxDataPoints"); ok {
+				if _, ok := query.CheckGet("maxDataPoints"); !ok {
+					query.Set("maxDataPoints", maxDataPoints.Interface())
+				}
+			}
+			panelQueries = append(panelQueries, query)
+		}
+
+		result[panel.Get("id").MustInt64()] = panelQueries
+	}
+
+	return result
+}
+
+func panelHasAnExpression(panel *simplejson.Json) bool {
+	for _, queryObj := range panel.Get("targets").MustArray() {
+		query := simplejson.NewFromAny(queryObj)
+		if expr.NodeTypeFromDatasourceUID(getDataSourceUIDFromJSON(query)) == expr.TypeCMDNode {
+			return true
+		}
+	}
+	return false
+}
+
+func getDataSourceUIDFromJSON(query *simplejson.Json) string {
+	uid := query.Get("datasource").Get("uid").MustString()
+
+	// before 8.3 special types could be sent as datasource (expr)
+	if uid == "" {
+		uid = query.Get("datasource").MustString()
+	}
+
+	return uid
+}
+
+// snapshotVariable is the value of a template variable selected when the snapshot was taken.
+type snapshotVariable struct {
+	text   []string
+	values []string
+}
+
+// getSnapshotVariables returns the current values of the template variables stored in the snapshot dashboard.
+func getSnapshotVariables(model *simplejson.Json) map[string]snapshotVariable {
+	variables := make(map[string]snapshotVariable)
+	for _, variableObj := range model.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+
+		current := variable.Get("current")
+		v := snapshotVariable{
+			text:   stringOrStrings(current.Get("text")),
+			values: stringOrStrings(current.Get("value")),
+		}
+		// "All" is sent as the custom all value when there is one, as all the options otherwise
+		if len(v.values) == 1 && v.values[0] == "$__all" {
+			if allValue := variable.Get("allValue").MustString(); allValue != "" {
+				v.values = []string{allValue}
+			} else {
+				v.values = nil
+				for _, optionObj := range variable.Get("options").MustArray() {
+					if value := simplejson.NewFromAny(optionObj).Get("value").MustString(); value != "" && value != "$__all" {
+						v.values = append(v.values, value)
+					}
+				}
+			}
+		}
+		variables[name] = v
+	}
+	return variables
+}
+
+func stringOrStrings(value *simplejson.Json) []string {
+	if s, err := value.String(); err == nil {
+		return []string{s}
+	}
+	return value.MustStringArray()
+}
+
+// variableRegex matches $var, ${var}, ${var:format} and [[var]] like the frontend template service.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::([^}]+))?\}`)
+
+// interpolateVariables replaces the template variables in all strings of a query with their values in the snapshot.
+// Built-in variables like $__interval are left to the data source. Values which formatting depends on the data source,
+// multiple values without a format, are not supported.
+func interpolateVariables(value any, variables map[string]snapshotVariable) (any, error) {
+	switch v := value.(type) {
+	case string:
+		var interpolateErr error
+		result := variableRegex.ReplaceAllStringFunc(v, func(match string) string {
+			groups := variableRegex.FindStringSubmatch(match)
+			name, format := groups[1]+groups[2]+groups[4], groups[3]+groups[5]
+			if strings.HasPrefix(name, "__") {
+				return match
+			}
+			variable, ok := variables[name]
+			if !ok {
+				return match
+			}
+			formatted, err := variable.format(format)
+			if err != nil && interpolateErr == nil {
+				interpolateErr = fmt.Errorf("variable %s: %w", name, err)
+			}
+			return formatted
+		})
+		return result, interpolateErr
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			interpolated, err := interpolateVariables(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = interpolated
+		}
+		return result, nil
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			interpolated, err := interpolateVariables(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, interpolated)
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+func (v snapshotVariable) format(format string) (string, error) {
+	switch format {
+	case "text":
+		return strings.Join(v.text, " + "), nil
+	case "csv", "raw":
+		return strings.Join(v.values, ","), nil
+	case "pipe":
+		return strings.Join(v.values, "|"), nil
+	case "glob":
+		if len(v.values) > 1 {
+			return "{" + strings.Join(v.values, ",") + "}", nil
+		}
+		return strings.Join(v.values, ""), nil
+	case "":
+		if len(v.values) > 1 {
+			return "", fmt.Errorf("multiple values are only supported with the csv, raw, pipe or glob format")
+		}
+		return strings.Join(v.values, ""), nil
+	default:
+		return "", fmt.Errorf("format %s is not supported", format)
+	}
+}
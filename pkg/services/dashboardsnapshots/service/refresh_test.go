@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	dashsnapdb "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRefreshSnapshots(t *testing.T) {
+	snapshotModel := `{
+		"uid": "dash-uid",
+		"time": {"from": "now-1d/d", "to": "now-1d/d"},
+		"templating": {"list": [
+			{"name": "server", "current": {"text": "web 1", "value": "web-1"}, "options": [{"text": "web 1", "value": "web-1"}]}
+		]},
+		"panels": [
+			{"id": 1, "type": "timeseries", "snapshotData": [{"fields": []}]},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [{"id": 3, "type": "stat"}]},
+			{"id": 4, "type": "text"}
+		]
+	}`
+	liveDashboard := simplejson.MustJson([]byte(`{
+		"uid": "dash-uid",
+		"panels": [
+			{"id": 1, "datasource": {"uid": "ds1"}, "targets": [{"refId": "A", "expr": "up{instance=\"$server\"}[$__interval]"}, {"refId": "B", "hide": true}]},
+			{"id": 3, "targets": [{"refId": "C", "datasource": {"uid": "ds2"}}]},
+			{"id": 4, "type": "text"}
+		]
+	}`))
+
+	setup := func(t *testing.T, canRead bool) (*RefreshService, *dashsnapdb.DashboardSnapshotStore, *query.FakeQueryService) {
+		t.Helper()
+		sqlStore := db.InitTestDB(t)
+		store := dashsnapdb.NewStore(sqlStore)
+
+		dashboardService := &dashboards.FakeDashboardService{}
+		dashboardService.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{UID: "dash-uid", OrgID: 1}).
+			Return(&dashboards.Dashboard{UID: "dash-uid", OrgID: 1, Data: liveDashboard}, nil).Maybe()
+
+		queryService := &query.FakeQueryService{}
+		s := &RefreshService{
+			cfg:              setting.NewCfg(),
+			store:            store,
+			secretsService:   fakes.NewFakeSecretsService(),
+			dashboardService: dashboardService,
+			queryService:     queryService,
+			userService:      &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 10, OrgID: 1}},
+			ac:               actest.FakeAccessControl{ExpectedEvaluate: canRead},
+			acService:        actest.FakeService{},
+			log:              log.NewNopLogger(),
+		}
+		return s, store, queryService
+	}
+
+	createSnapshot := func(t *testing.T, s *RefreshService, store *dashsnapdb.DashboardSnapshotStore) *dashboardsnapshots.DashboardSnapshot {
+		t.Helper()
+		encrypted, err := s.secretsService.Encrypt(context.Background(), []byte(snapshotModel), secrets.WithoutScope())
+		require.NoError(t, err)
+
+		snapshot, err := store.CreateDashboardSnapshot(context.Background(), &dashboardsnapshots.CreateDashboardSnapshotCommand{
+			Key:                "key",
+			DeleteKey:          "deletekey",
+			RefreshInterval:    3600,
+			DashboardEncrypted: encrypted,
+			UserID:             10,
+			OrgID:              1,
+		})
+		require.NoError(t, err)
+		return snapshot
+	}
+
+	getModel := func(t *testing.T, s *RefreshService, store *dashsnapdb.DashboardSnapshotStore) (*dashboardsnapshots.DashboardSnapshot, *simplejson.Json) {
+		t.Helper()
+		snapshot, err := store.GetDashboardSnapshot(context.Background(), &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "key"})
+		require.NoError(t, err)
+		decrypted, err := s.secretsService.Decrypt(context.Background(), snapshot.DashboardEncrypted)
+		require.NoError(t, err)
+		return snapshot, simplejson.MustJson(decrypted)
+	}
+
+	t.Run("should replace the panel data with the results of the live dashboard queries", func(t *testing.T) {
+		s, store, queryService := setup(t, true)
+		createSnapshot(t, s, store)
+
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+			return len(req.Queries) == 1 && req.Queries[0].Get("refId").MustString() == "A" &&
+				req.Queries[0].Get("expr").MustString() == `up{instance="web-1"}[$__interval]`
+		})).Return(&backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{&data.Frame{Name: "A", RefID: "A", Fields: []*data.Field{data.NewField("value", nil, []float64{1, 2})}}}},
+		}}, nil).Once()
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+			return len(req.Queries) == 1 && req.Queries[0].Get("refId").MustString() == "C"
+		})).Return(&backend.QueryDataResponse{Responses: backend.Responses{
+			"C": {Frames: data.Frames{&data.Frame{Name: "C", RefID: "C", Fields: []*data.Field{data.NewField("value", nil, []float64{3})}}}},
+		}}, nil).Once()
+
+		now := time.Now().Add(2 * time.Hour)
+		s.refreshSnapshots(context.Background(), now)
+		queryService.AssertExpectations(t)
+
+		snapshot, model := getModel(t, s, store)
+		require.Equal(t, "deletekey", snapshot.DeleteKey)
+		require.WithinDuration(t, now.Add(time.Hour), *snapshot.NextRefresh, time.Second)
+		require.Equal(t, now.UTC().Format(time.RFC3339), model.GetPath("snapshot", "timestamp").MustString())
+
+		panels := model.Get("panels")
+		require.Equal(t, "A", panels.GetIndex(0).Get("snapshotData").GetIndex(0).GetPath("schema", "refId").MustString())
+		require.Equal(t, "C", panels.GetIndex(1).Get("panels").GetIndex(0).Get("snapshotData").GetIndex(0).GetPath("schema", "refId").MustString())
+		_, ok := panels.GetIndex(2).CheckGet("snapshotData")
+		require.False(t, ok)
+	})
+
+	t.Run("should keep the data and record the error of failed panels and refresh the other panels", func(t *testing.T) {
+		s, store, queryService := setup(t, true)
+		createSnapshot(t, s, store)
+
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+			return req.Queries[0].Get("refId").MustString() == "A"
+		})).Return(&backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Error: errors.New("datasource unavailable")},
+		}}, nil).Once()
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+			return req.Queries[0].Get("refId").MustString() == "C"
+		})).Return(&backend.QueryDataResponse{Responses: backend.Responses{
+			"C": {Frames: data.Frames{&data.Frame{Name: "C", RefID: "C", Fields: []*data.Field{data.NewField("value", nil, []float64{3})}}}},
+		}}, nil).Once()
+
+		now := time.Now().Add(2 * time.Hour)
+		s.refreshSnapshots(context.Background(), now)
+		queryService.AssertExpectations(t)
+
+		snapshot, model := getModel(t, s, store)
+		require.WithinDuration(t, now.Add(time.Hour), *snapshot.NextRefresh, time.Second)
+
+		panels := model.Get("panels")
+		require.Equal(t, []any{map[string]any{"fields": []any{}}}, panels.GetIndex(0).Get("snapshotData").MustArray())
+		require.Equal(t, "datasource unavailable", panels.GetIndex(0).Get("snapshotError").MustString())
+		require.Equal(t, "C", panels.GetIndex(1).Get("panels").GetIndex(0).Get("snapshotData").GetIndex(0).GetPath("schema", "refId").MustString())
+		_, ok := panels.GetIndex(1).Get("panels").GetIndex(0).CheckGet("snapshotError")
+		require.False(t, ok)
+	})
+
+	t.Run("should default the query datasource to the panel datasource and skip hidden queries", func(t *testing.T) {
+		queries := groupQueriesByPanelID(liveDashboard)
+		require.Len(t, queries[1], 1)
+		require.Equal(t, "ds1", queries[1][0].Get("datasource").Get("uid").MustString())
+		require.Equal(t, "ds2", queries[3][0].Get("datasource").Get("uid").MustString())
+		require.Empty(t, queries[4])
+	})
+
+	t.Run("should keep the snapshot data and retry later when the creator cannot read the dashboard", func(t *testing.T) {
+		s, store, queryService := setup(t, false)
+		createSnapshot(t, s, store)
+
+		now := time.Now().Add(2 * time.Hour)
+		s.refreshSnapshots(context.Background(), now)
+		queryService.AssertNotCalled(t, "QueryData")
+
+		snapshot, model := getModel(t, s, store)
+		require.WithinDuration(t, now.Add(maxRetryInterval), *snapshot.NextRefresh, time.Second)
+		require.Equal(t, []any{map[string]any{"fields": []any{}}}, model.Get("panels").GetIndex(0).Get("snapshotData").MustArray())
+	})
+
+	t.Run("should not refresh snapshots before their next refresh", func(t *testing.T) {
+		s, store, queryService := setup(t, true)
+		created := createSnapshot(t, s, store)
+
+		s.refreshSnapshots(context.Background(), time.Now())
+		queryService.AssertNotCalled(t, "QueryData")
+
+		snapshot, _ := getModel(t, s, store)
+		require.WithinDuration(t, *created.NextRefresh, *snapshot.NextRefresh, time.Second)
+	})
+}
+
+func TestBuildTimeRange(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	model := simplejson.MustJson([]byte(`{"time": {"from": "now-1d/d", "to": "now-1d/d"}, "timezone": "utc"}`))
+
+	from, to := buildTimeRange(model, now)
+	require.Equal(t, "1710374400000", from)
+	require.Equal(t, "1710460799999", to)
+}
+
+func TestInterpolateVariables(t *testing.T) {
+	model := simplejson.MustJson([]byte(`{"templating": {"list": [
+		{"name": "server", "current": {"text": ["web 1", "web 2"], "value": ["web-1", "web-2"]}},
+		{"name": "env", "current": {"text": "All", "value": ["$__all"]}, "options": [
+			{"text": "All", "value": "$__all"}, {"text": "dev", "value": "dev"}, {"text": "prod", "value": "prod"}
+		]},
+		{"name": "region", "current": {"text": "All", "value": "$__all"}, "allValue": ".*"},
+		{"name": "ds", "current": {"text": "Prometheus", "value": "prom-uid"}}
+	]}}`))
+	variables := getSnapshotVariables(model)
+
+	query := map[string]any{
+		"datasource": map[string]any{"uid": "${ds}"},
+		"expr":       `up{instance=~"${server:pipe}", env=~"${env:regex}"}`,
+		"targets":    []any{"[[server:csv]]", "${env:glob}", "$region", "${server:text}", "$__from", "$unknown"},
+		"maxLines":   100,
+	}
+
+	_, err := interpolateVariables(query, variables)
+	require.EqualError(t, err, "variable env: format regex is not supported")
+
+	query["expr"] = `up{instance=~"${server:pipe}", env=~"${env:pipe}"}`
+	interpolated, err := interpolateVariables(query, variables)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"datasource": map[string]any{"uid": "prom-uid"},
+		"expr":       `up{instance=~"web-1|web-2", env=~"dev|prod"}`,
+		"targets":    []any{"web-1,web-2", "{dev,prod}", ".*", "web 1 + web 2", "$__from", "$unknown"},
+		"maxLines":   100,
+	}, interpolated)
+
+	// the formatting of multiple values depends on the data source
+	_, err = interpolateVariables(`instance="$server"`, variables)
+	require.EqualError(t, err, "variable server: multiple values are only supported with the csv, raw, pipe or glob format")
+}
@@ -10,4 +10,6 @@ type Store interface {
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
+	GetSnapshotsToRefresh(context.Context, *GetSnapshotsToRefreshQuery) ([]*DashboardSnapshot, error)
+	UpdateDashboardSnapshotRefresh(context.Context, *UpdateDashboardSnapshotRefreshCommand) error
 }
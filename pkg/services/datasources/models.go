@@ -40,6 +40,17 @@ const (
 
 type DsAccess string
 
+// IsBuiltInDatasource returns whether a datasource reference of a dashboard names one of the
+// built-in datasources, or server-side expressions, rather than a configured datasource.
+func IsBuiltInDatasource(ref string) bool {
+	switch ref {
+	// "__expr__" is expr.DatasourceUID, the expr package depends on this one
+	case "grafana", "-- Grafana --", "-- Mixed --", "-- Dashboard --", "default", "__expr__":
+		return true
+	}
+	return false
+}
+
 type DataSource struct {
 	ID      int64 `json:"id,omitempty" xorm:"pk autoincr 'id'"`
 	OrgID   int64 `json:"orgId,omitempty" xorm:"org_id"`
@@ -3,6 +3,7 @@ package libraryelements
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -28,6 +29,9 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/", authorize(ac.EvalPermission(ActionLibraryPanelsRead)), routing.Wrap(l.getAllHandler))
 			entities.Get("/:uid", authorize(ac.EvalPermission(ActionLibraryPanelsRead)), routing.Wrap(l.getHandler))
 			entities.Get("/:uid/connections/", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getConnectionsHandler))
+			entities.Get("/:uid/connections/outdated", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getOutdatedConnectionsHandler))
+			entities.Get("/:uid/versions", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getVersionHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.patchHandler))
 		} else {
@@ -36,6 +40,9 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/", routing.Wrap(l.getAllHandler))
 			entities.Get("/:uid", routing.Wrap(l.getHandler))
 			entities.Get("/:uid/connections/", routing.Wrap(l.getConnectionsHandler))
+			entities.Get("/:uid/connections/outdated", routing.Wrap(l.getOutdatedConnectionsHandler))
+			entities.Get("/:uid/versions", routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", routing.Wrap(l.getVersionHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", routing.Wrap(l.patchHandler))
 		}
@@ -132,19 +139,55 @@ func (l *LibraryElementService) deleteHandler(c *contextmodel.ReqContext) respon
 // Get library element by UID.
 //
 // Returns a library element with the given UID.
+// Use the `version` query parameter to get the model of a previous version of the library element.
 //
 // Responses:
 // 200: getLibraryElementResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
 // 500: internalServerError
 func (l *LibraryElementService) getHandler(c *contextmodel.ReqContext) response.Response {
+	var version int64
+	if c.Query("version") != "" {
+		var err error
+		version, err = strconv.ParseInt(c.Query("version"), 10, 64)
+		if err != nil || version < 1 {
+			return response.Error(http.StatusBadRequest, "version must be a positive number", err)
+		}
+	}
+	return l.getElementResponse(c, version)
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/versions/{version} library_elements getLibraryElementVersion
+//
+// Get library element version.
+//
+// Returns a library element with the model of the given version.
+//
+// Responses:
+// 200: getLibraryElementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionHandler(c *contextmodel.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil || version < 1 {
+		return response.Error(http.StatusBadRequest, "version must be a positive number", err)
+	}
+	return l.getElementResponse(c, version)
+}
+
+func (l *LibraryElementService) getElementResponse(c *contextmodel.ReqContext, version int64) response.Response {
 	ctx := c.Req.Context()
 	element, err := l.getLibraryElementByUid(ctx, c.SignedInUser,
 		model.GetLibraryElementCommand{
 			UID:        web.Params(c.Req)[":uid"],
 			FolderName: dashboards.RootFolderName,
+			Version:    version,
 		},
 	)
 	if err != nil {
@@ -282,6 +325,48 @@ func (l *LibraryElementService) getConnectionsHandler(c *contextmodel.ReqContext
 	return response.JSON(http.StatusOK, model.LibraryElementConnectionsResponse{Result: connections})
 }
 
+// swagger:route GET /library-elements/{library_element_uid}/connections/outdated library_elements getLibraryElementOutdatedConnections
+//
+// Get outdated library element connections.
+//
+// Returns the connections of a library element that are pinned to a previous version of the library element.
+//
+// Responses:
+// 200: getLibraryElementConnectionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getOutdatedConnectionsHandler(c *contextmodel.ReqContext) response.Response {
+	connections, err := l.getOutdatedConnections(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get outdated connections")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementConnectionsResponse{Result: connections})
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/versions library_elements getLibraryElementVersions
+//
+// Get library element versions.
+//
+// Returns the version history of a library element, latest version first.
+//
+// Responses:
+// 200: getLibraryElementVersionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionsHandler(c *contextmodel.ReqContext) response.Response {
+	versions, err := l.getLibraryElementVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element versions")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionsResponse{Result: versions})
+}
+
 // swagger:route GET /library-elements/name/{library_element_name} library_elements getLibraryElementByName
 //
 // Get library element by name.
@@ -336,6 +421,9 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, model.ErrLibraryElementDashboardNotFound) {
 		return response.Error(http.StatusNotFound, model.ErrLibraryElementDashboardNotFound.Error(), err)
 	}
+	if errors.Is(err, model.ErrLibraryElementVersionNotFound) {
+		return response.Error(http.StatusNotFound, model.ErrLibraryElementVersionNotFound.Error(), err)
+	}
 	if errors.Is(err, model.ErrLibraryElementVersionMismatch) {
 		return response.Error(http.StatusPreconditionFailed, model.ErrLibraryElementVersionMismatch.Error(), err)
 	}
@@ -357,7 +445,7 @@ func toLibraryElementError(err error, message string) response.Response {
 	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
 }
 
-// swagger:parameters getLibraryElementByUID getLibraryElementConnections
+// swagger:parameters getLibraryElementByUID getLibraryElementConnections getLibraryElementOutdatedConnections getLibraryElementVersions
 type LibraryElementByUID struct {
 	// in:path
 	// required:true
@@ -369,6 +457,20 @@ type GetLibraryElementByUIDParams struct {
 	// in:path
 	// required:true
 	UID string `json:"library_element_uid"`
+	// The version of the library element to get the model of.
+	// in:query
+	// required:false
+	Version int64 `json:"version"`
+}
+
+// swagger:parameters getLibraryElementVersion
+type GetLibraryElementVersionParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// in:path
+	// required:true
+	Version int64 `json:"version"`
 }
 
 // swagger:parameters GetLibraryElementConnectionsParams
@@ -478,3 +580,9 @@ type GetLibraryElementConnectionsResponse struct {
 	// in: body
 	Body model.LibraryElementConnectionsResponse `json:"body"`
 }
+
+// swagger:response getLibraryElementVersionsResponse
+type GetLibraryElementVersionsResponse struct {
+	// in: body
+	Body model.LibraryElementVersionsResponse `json:"body"`
+}
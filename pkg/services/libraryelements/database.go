@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -192,7 +193,7 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
-		return nil
+		return insertLibraryElementVersion(session, element)
 	})
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
@@ -259,6 +260,10 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 			return model.ErrLibraryElementHasConnections
 		}
 
+		if _, err := session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", element.ID); err != nil {
+			return err
+		}
+
 		result, err := session.Exec("DELETE FROM library_element WHERE id=?", element.ID)
 		if err != nil {
 			return err
@@ -380,7 +385,19 @@ func (l *LibraryElementService) getLibraryElementByUid(c context.Context, signed
 		return model.LibraryElementDTO{}, fmt.Errorf("found %d elements, while expecting at most one", len(libraryElements))
 	}
 
-	return libraryElements[0], nil
+	element := libraryElements[0]
+	if cmd.Version == 0 || cmd.Version == element.Version {
+		return element, nil
+	}
+
+	version, err := l.getLibraryElementVersion(c, element, cmd.Version)
+	if err != nil {
+		return model.LibraryElementDTO{}, err
+	}
+	element.Name = version.Name
+	element.Model = version.Model
+	element.Version = version.Version
+	return element, nil
 }
 
 // getLibraryElementByName gets a Library Element by name.
@@ -633,6 +650,9 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		} else if rowsAffected != 1 {
 			return model.ErrLibraryElementNotFound
 		}
+		if err := insertLibraryElementVersion(session, libraryElement); err != nil {
+			return err
+		}
 
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
 		dto = model.LibraryElementDTO{
@@ -702,6 +722,7 @@ func (l *LibraryElementService) getConnections(c context.Context, signedInUser i
 				ElementID:     connection.ElementID,
 				ConnectionID:  connection.ConnectionID,
 				ConnectionUID: connection.ConnectionUID,
+				Version:       connection.Version,
 				Created:       connection.Created,
 				CreatedBy: librarypanel.LibraryElementDTOMetaUser{
 					Id:        connection.CreatedBy,
@@ -720,11 +741,13 @@ func (l *LibraryElementService) getConnections(c context.Context, signedInUser i
 // getElementsForDashboardID gets all elements for a specific dashboard
 func (l *LibraryElementService) getElementsForDashboardID(c context.Context, dashboardID int64) (map[string]model.LibraryElementDTO, error) {
 	libraryElementMap := make(map[string]model.LibraryElementDTO)
+	pinnedVersions := make(map[string]int64)
 	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
 		var libraryElements []model.LibraryElementWithMeta
 		sql := selectLibraryElementDTOWithMeta +
 			", coalesce(dashboard.title, 'General') AS folder_name" +
 			", coalesce(dashboard.uid, '') AS folder_uid" +
+			", lce.version AS pinned_version" +
 			getFromLibraryElementDTOWithMeta(l.SQLStore.GetDialect()) +
 			" LEFT JOIN dashboard AS dashboard ON dashboard.id = le.folder_id" +
 			" INNER JOIN " + model.LibraryElementConnectionTableName + " AS lce ON lce.element_id = le.id AND lce.kind=1 AND lce.connection_id=?"
@@ -736,6 +759,9 @@ func (l *LibraryElementService) getElementsForDashboardID(c context.Context, das
 
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
 		for _, element := range libraryElements {
+			if element.PinnedVersion > 0 && element.PinnedVersion != element.Version {
+				pinnedVersions[element.UID] = element.PinnedVersion
+			}
 			libraryElementMap[element.UID] = model.LibraryElementDTO{
 				ID:          element.ID,
 				OrgID:       element.OrgID,
@@ -769,12 +795,44 @@ func (l *LibraryElementService) getElementsForDashboardID(c context.Context, das
 
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return libraryElementMap, err
+	// the dashboard gets the pinned version of the elements, not the latest one
+	for uid, pinnedVersion := range pinnedVersions {
+		element := libraryElementMap[uid]
+		version, err := l.getLibraryElementVersion(c, element, pinnedVersion)
+		if err != nil {
+			return nil, err
+		}
+		element.Name = version.Name
+		element.Model = version.Model
+		element.Version = version.Version
+		libraryElementMap[uid] = element
+	}
+
+	return libraryElementMap, nil
 }
 
 // connectElementsToDashboardID adds connections for all elements Library Elements in a Dashboard.
 func (l *LibraryElementService) connectElementsToDashboardID(c context.Context, signedInUser identity.Requester, elementUIDs []string, dashboardID int64) error {
+	elements := make(map[string]int64, len(elementUIDs))
+	for _, elementUID := range elementUIDs {
+		elements[elementUID] = 0
+	}
+	return l.connectElementVersionsToDashboardID(c, signedInUser, elements, dashboardID)
+}
+
+// connectElementVersionsToDashboardID adds connections for all Library Elements in a Dashboard, where elements maps
+// the uid of each element to the version pinned by the dashboard, or 0 to follow the latest version.
+func (l *LibraryElementService) connectElementVersionsToDashboardID(c context.Context, signedInUser identity.Requester, elements map[string]int64, dashboardID int64) error {
+	elementUIDs := make([]string, 0, len(elements))
+	for elementUID := range elements {
+		elementUIDs = append(elementUIDs, elementUID)
+	}
+	sort.Strings(elementUIDs)
+
 	err := l.SQLStore.WithTransactionalDbSession(c, func(session *db.Session) error {
 		_, err := session.Exec("DELETE FROM "+model.LibraryElementConnectionTableName+" WHERE kind=1 AND connection_id=?", dashboardID)
 		if err != nil {
@@ -796,10 +854,22 @@ func (l *LibraryElementService) connectElementsToDashboardID(c context.Context,
 				userID = id
 			}
 
+			version := elements[elementUID]
+			if version > 0 {
+				exists, err := session.Table(model.LibraryElementVersionTableName).Where("element_id=? AND version=?", element.ID, version).Exist()
+				if err != nil {
+					return err
+				}
+				if !exists {
+					return model.ErrLibraryElementVersionNotFound
+				}
+			}
+
 			connection := model.LibraryElementConnection{
 				ElementID:    element.ID,
 				Kind:         1,
 				ConnectionID: dashboardID,
+				Version:      version,
 				Created:      time.Now(),
 				CreatedBy:    userID,
 			}
@@ -877,6 +947,10 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 			if err != nil {
 				return err
 			}
+			_, err = session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", elementID.ID)
+			if err != nil {
+				return err
+			}
 		}
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()); err != nil {
 			return err
@@ -88,6 +88,10 @@ func (l *LibraryElementService) ConnectElementsToDashboard(c context.Context, si
 	return nil
 }
 
+func (l *LibraryElementService) ConnectElementVersionsToDashboard(c context.Context, signedInUser identity.Requester, elements map[string]int64, dashboardID int64) error {
+	return nil
+}
+
 func (l *LibraryElementService) DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error {
 	return nil
 }
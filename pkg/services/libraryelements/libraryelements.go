@@ -38,6 +38,7 @@ type Service interface {
 	GetElement(c context.Context, signedInUser identity.Requester, cmd model.GetLibraryElementCommand) (model.LibraryElementDTO, error)
 	GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]model.LibraryElementDTO, error)
 	ConnectElementsToDashboard(c context.Context, signedInUser identity.Requester, elementUIDs []string, dashboardID int64) error
+	ConnectElementVersionsToDashboard(c context.Context, signedInUser identity.Requester, elements map[string]int64, dashboardID int64) error
 	DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error
 	DeleteLibraryElementsInFolder(c context.Context, signedInUser identity.Requester, folderUID string) error
 	GetAllElements(c context.Context, signedInUser identity.Requester, query model.SearchLibraryElementsQuery) (model.LibraryElementSearchResult, error)
@@ -76,6 +77,11 @@ func (l *LibraryElementService) ConnectElementsToDashboard(c context.Context, si
 	return l.connectElementsToDashboardID(c, signedInUser, elementUIDs, dashboardID)
 }
 
+// ConnectElementVersionsToDashboard connects elements to a specific dashboard, pinning the elements mapped to a version.
+func (l *LibraryElementService) ConnectElementVersionsToDashboard(c context.Context, signedInUser identity.Requester, elements map[string]int64, dashboardID int64) error {
+	return l.connectElementVersionsToDashboardID(c, signedInUser, elements, dashboardID)
+}
+
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
 func (l *LibraryElementService) DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error {
 	return l.disconnectElementsFromDashboardID(c, dashboardID)
@@ -0,0 +1,145 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	scenarioWithPanel(t, "When an admin patches a library panel, it should keep the previous versions",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+			patchLibraryPanelTitle(t, sc, 1, "Version two")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var versions model.LibraryElementVersionsResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &versions))
+			require.Len(t, versions.Result, 2)
+			require.Equal(t, int64(2), versions.Result[0].Version)
+			require.Equal(t, int64(1), versions.Result[1].Version)
+			require.Equal(t, userInDbName, versions.Result[1].CreatedBy.Name)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, int64(1), result.Result.Version)
+			require.Equal(t, "Text - Library Panel", result.Result.Model["title"])
+
+			err := sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Set("version", "2")
+			resp = sc.service.getHandler(sc.reqContext)
+			result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, int64(2), result.Result.Version)
+			require.Equal(t, "Version two", result.Result.Model["title"])
+		})
+
+	scenarioWithPanel(t, "When an admin tries to get a library panel version that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "3"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "latest"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When a dashboard pins a previous version of a library panel, it should be listed as outdated",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+			dash := dashboards.Dashboard{
+				Title: "Testing outdated connections",
+				Data:  simplejson.NewFromAny(map[string]any{}),
+			}
+			// nolint:staticcheck
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.ID, sc.folder.UID)
+			ctx := sc.reqContext.Req.Context()
+			uid := sc.initialResult.Result.UID
+
+			err := sc.service.ConnectElementVersionsToDashboard(ctx, sc.reqContext.SignedInUser, map[string]int64{uid: 1}, dashInDB.ID)
+			require.NoError(t, err)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": uid})
+			resp := sc.service.getOutdatedConnectionsHandler(sc.reqContext)
+			require.Empty(t, validateAndUnMarshalConnectionResponse(t, resp).Result)
+
+			patchLibraryPanelTitle(t, sc, 1, "Version two")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": uid})
+			resp = sc.service.getOutdatedConnectionsHandler(sc.reqContext)
+			outdated := validateAndUnMarshalConnectionResponse(t, resp).Result
+			require.Len(t, outdated, 1)
+			require.Equal(t, dashInDB.UID, outdated[0].ConnectionUID)
+			require.Equal(t, int64(1), outdated[0].Version)
+
+			err = sc.service.ConnectElementVersionsToDashboard(ctx, sc.reqContext.SignedInUser, map[string]int64{uid: 0}, dashInDB.ID)
+			require.NoError(t, err)
+			resp = sc.service.getOutdatedConnectionsHandler(sc.reqContext)
+			require.Empty(t, validateAndUnMarshalConnectionResponse(t, resp).Result)
+
+			err = sc.service.ConnectElementVersionsToDashboard(ctx, sc.reqContext.SignedInUser, map[string]int64{uid: 5}, dashInDB.ID)
+			require.ErrorIs(t, err, model.ErrLibraryElementVersionNotFound)
+		})
+
+	scenarioWithPanel(t, "When a dashboard pins a version of a library panel, editing the library panel should not change the dashboard",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+			dash := dashboards.Dashboard{
+				Title: "Testing pinned versions",
+				Data:  simplejson.NewFromAny(map[string]any{}),
+			}
+			// nolint:staticcheck
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.ID, sc.folder.UID)
+			ctx := sc.reqContext.Req.Context()
+			uid := sc.initialResult.Result.UID
+
+			err := sc.service.ConnectElementVersionsToDashboard(ctx, sc.reqContext.SignedInUser, map[string]int64{uid: 1}, dashInDB.ID)
+			require.NoError(t, err)
+
+			patchLibraryPanelTitle(t, sc, 1, "Version two")
+
+			elements, err := sc.service.GetElementsForDashboard(ctx, dashInDB.ID)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), elements[uid].Version)
+			var pinnedModel map[string]any
+			require.NoError(t, json.Unmarshal(elements[uid].Model, &pinnedModel))
+			require.Equal(t, "Text - Library Panel", pinnedModel["title"])
+
+			err = sc.service.ConnectElementVersionsToDashboard(ctx, sc.reqContext.SignedInUser, map[string]int64{uid: 0}, dashInDB.ID)
+			require.NoError(t, err)
+
+			elements, err = sc.service.GetElementsForDashboard(ctx, dashInDB.ID)
+			require.NoError(t, err)
+			require.Equal(t, int64(2), elements[uid].Version)
+			var latestModel map[string]any
+			require.NoError(t, json.Unmarshal(elements[uid].Model, &latestModel))
+			require.Equal(t, "Version two", latestModel["title"])
+		})
+}
+
+func patchLibraryPanelTitle(t *testing.T, sc scenarioContext, version int64, title string) {
+	t.Helper()
+
+	cmd := model.PatchLibraryElementCommand{
+		FolderID:  sc.folder.ID, // nolint:staticcheck
+		FolderUID: &sc.folder.UID,
+		Model:     []byte(`{"datasource": "${DS_GDEV-TESTDATA}", "id": 1, "title": "` + title + `", "type": "text"}`),
+		Kind:      int64(model.PanelElement),
+		Version:   version,
+	}
+	sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+	sc.reqContext.Req.Body = mockRequestBody(cmd)
+	resp := sc.service.patchHandler(sc.reqContext)
+	require.Equal(t, 200, resp.Status())
+}
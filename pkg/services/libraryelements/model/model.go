@@ -62,6 +62,8 @@ type LibraryElementWithMeta struct {
 	CreatedByEmail      string
 	UpdatedByName       string
 	UpdatedByEmail      string
+	// PinnedVersion is the version pinned by a dashboard, only set when getting the elements of a dashboard.
+	PinnedVersion int64
 }
 
 // LibraryElementDTO is the frontend DTO for entities.
@@ -103,12 +105,42 @@ type LibraryElementDTOMeta struct {
 	UpdatedBy librarypanel.LibraryElementDTOMetaUser `json:"updatedBy"`
 }
 
+// LibraryElementVersion is the model for the version history of library elements.
+type LibraryElementVersion struct {
+	ID        int64 `xorm:"pk autoincr 'id'"`
+	OrgID     int64 `xorm:"org_id"`
+	ElementID int64 `xorm:"element_id"`
+	Version   int64
+	Name      string
+	Model     json.RawMessage
+	Created   time.Time
+	CreatedBy int64
+}
+
+// LibraryElementVersionWithMeta is the model used to retrieve library element versions with additional meta information.
+type LibraryElementVersionWithMeta struct {
+	LibraryElementVersion `xorm:"extends"`
+	CreatedByName         string
+	CreatedByEmail        string
+}
+
+// LibraryElementVersionDTO is the frontend DTO for library element versions.
+type LibraryElementVersionDTO struct {
+	ID        int64                                  `json:"id"`
+	Version   int64                                  `json:"version"`
+	Name      string                                 `json:"name"`
+	Model     json.RawMessage                        `json:"model,omitempty"`
+	Created   time.Time                              `json:"created"`
+	CreatedBy librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
 // libraryElementConnection is the model for library element connections.
 type LibraryElementConnection struct {
 	ID           int64 `xorm:"pk autoincr 'id'"`
 	ElementID    int64 `xorm:"element_id"`
 	Kind         int64 `xorm:"kind"`
 	ConnectionID int64 `xorm:"connection_id"`
+	Version      int64 // pinned version of the element, 0 to follow the latest version
 	Created      time.Time
 	CreatedBy    int64
 }
@@ -120,6 +152,7 @@ type LibraryElementConnectionWithMeta struct {
 	Kind           int64  `xorm:"kind"`
 	ConnectionID   int64  `xorm:"connection_id"`
 	ConnectionUID  string `xorm:"connection_uid"`
+	Version        int64
 	Created        time.Time
 	CreatedBy      int64
 	CreatedByName  string
@@ -133,6 +166,7 @@ type LibraryElementConnectionDTO struct {
 	ElementID     int64                                  `json:"elementId"`
 	ConnectionID  int64                                  `json:"connectionId"`
 	ConnectionUID string                                 `json:"connectionUid"`
+	Version       int64                                  `json:"version"`
 	Created       time.Time                              `json:"created"`
 	CreatedBy     librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
 }
@@ -156,6 +190,8 @@ var (
 	ErrLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	ErrLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// ErrLibraryElementVersionNotFound is an error for when a version of a library element can't be found.
+	ErrLibraryElementVersionNotFound = errors.New("library element version could not be found")
 )
 
 // Commands
@@ -214,6 +250,8 @@ type GetLibraryElementCommand struct {
 	// Deprecated: use FolderUID instead
 	FolderID int64
 	UID      string
+	// Version gets the model of a previous version of the element when set.
+	Version int64
 }
 
 // SearchLibraryElementsQuery is the query used for searching for Elements
@@ -250,6 +288,11 @@ type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
 }
 
+// LibraryElementVersionsResponse is a response struct for an array of LibraryElementVersionDTO.
+type LibraryElementVersionsResponse struct {
+	Result []LibraryElementVersionDTO `json:"result"`
+}
+
 // DeleteLibraryElementResponse is the response struct for deleting a library element.
 type DeleteLibraryElementResponse struct {
 	ID      int64  `json:"id"`
@@ -267,3 +310,5 @@ const (
 )
 
 const LibraryElementConnectionTableName = "library_element_connection"
+
+const LibraryElementVersionTableName = "library_element_version"
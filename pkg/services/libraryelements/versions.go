@@ -0,0 +1,112 @@
+package libraryelements
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/kinds/librarypanel"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+// insertLibraryElementVersion stores the current state of a Library Element in its version history.
+func insertLibraryElementVersion(session *db.Session, element model.LibraryElement) error {
+	version := model.LibraryElementVersion{
+		OrgID:     element.OrgID,
+		ElementID: element.ID,
+		Version:   element.Version,
+		Name:      element.Name,
+		Model:     element.Model,
+		Created:   element.Updated,
+		CreatedBy: element.UpdatedBy,
+	}
+	_, err := session.Insert(&version)
+	return err
+}
+
+// getLibraryElementVersion gets a version of a Library Element.
+func (l *LibraryElementService) getLibraryElementVersion(c context.Context, element model.LibraryElementDTO, version int64) (model.LibraryElementVersion, error) {
+	var result model.LibraryElementVersion
+	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		exists, err := session.Where("element_id=? AND org_id=? AND version=?", element.ID, element.OrgID, version).Get(&result)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return model.ErrLibraryElementVersionNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return model.LibraryElementVersion{}, err
+	}
+
+	if element.Kind == int64(model.PanelElement) {
+		result.Model, err = l.addUidToLibraryPanel(result.Model, element.UID)
+		if err != nil {
+			return model.LibraryElementVersion{}, err
+		}
+	}
+	return result, nil
+}
+
+// getLibraryElementVersions gets the version history of a Library Element, latest version first.
+func (l *LibraryElementService) getLibraryElementVersions(c context.Context, signedInUser identity.Requester, uid string) ([]model.LibraryElementVersionDTO, error) {
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: uid})
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]model.LibraryElementVersionDTO, 0)
+	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		var elementVersions []model.LibraryElementVersionWithMeta
+		sql := "SELECT lev.id, lev.org_id, lev.element_id, lev.version, lev.name, lev.created, lev.created_by" +
+			", u1.login AS created_by_name, u1.email AS created_by_email" +
+			" FROM " + model.LibraryElementVersionTableName + " AS lev" +
+			" LEFT JOIN " + l.SQLStore.GetDialect().Quote("user") + " AS u1 ON lev.created_by = u1.id" +
+			" WHERE lev.element_id=? AND lev.org_id=?" +
+			" ORDER BY lev.version DESC"
+		if err := session.SQL(sql, element.ID, element.OrgID).Find(&elementVersions); err != nil {
+			return err
+		}
+
+		for _, version := range elementVersions {
+			versions = append(versions, model.LibraryElementVersionDTO{
+				ID:      version.ID,
+				Version: version.Version,
+				Name:    version.Name,
+				Created: version.Created,
+				CreatedBy: librarypanel.LibraryElementDTOMetaUser{
+					Id:        version.CreatedBy,
+					Name:      version.CreatedByName,
+					AvatarUrl: dtos.GetGravatarUrl(l.Cfg, version.CreatedByEmail),
+				},
+			})
+		}
+		return nil
+	})
+
+	return versions, err
+}
+
+// getOutdatedConnections gets the connections for a Library Element that are pinned to a previous version.
+func (l *LibraryElementService) getOutdatedConnections(c context.Context, signedInUser identity.Requester, uid string) ([]model.LibraryElementConnectionDTO, error) {
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: uid})
+	if err != nil {
+		return nil, err
+	}
+
+	connections, err := l.getConnections(c, signedInUser, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	outdated := make([]model.LibraryElementConnectionDTO, 0)
+	for _, connection := range connections {
+		if connection.Version > 0 && connection.Version < element.Version {
+			outdated = append(outdated, connection)
+		}
+	}
+	return outdated, nil
+}
@@ -65,25 +65,23 @@ var _ Service = (*LibraryPanelService)(nil)
 // ConnectLibraryPanelsForDashboard loops through all panels in dashboard JSON and connects any library panels to the dashboard.
 func (lps *LibraryPanelService) ConnectLibraryPanelsForDashboard(c context.Context, signedInUser identity.Requester, dash *dashboards.Dashboard) error {
 	panels := dash.Data.Get("panels").MustArray()
-	libraryPanels := make(map[string]string)
+	libraryPanels := make(map[string]int64)
 	err := connectLibraryPanelsRecursively(c, panels, libraryPanels)
 	if err != nil {
 		return err
 	}
 
-	elementUIDs := make([]string, 0, len(libraryPanels))
-	for libraryPanel := range libraryPanels {
-		elementUIDs = append(elementUIDs, libraryPanel)
-	}
-
-	return lps.LibraryElementService.ConnectElementsToDashboard(c, signedInUser, elementUIDs, dash.ID)
+	return lps.LibraryElementService.ConnectElementVersionsToDashboard(c, signedInUser, libraryPanels, dash.ID)
 }
 
 func isLibraryPanelOrRow(panel *simplejson.Json, panelType string) bool {
 	return panel.Interface() != nil || panelType == "row"
 }
 
-func connectLibraryPanelsRecursively(c context.Context, panels []any, libraryPanels map[string]string) error {
+// connectLibraryPanelsRecursively collects the library panels in panels, mapping their uid to the version pinned
+// with libraryPanel.pinnedVersion, or 0 when the panel follows the latest version. When a library panel is used
+// several times, the oldest pinned version is kept.
+func connectLibraryPanelsRecursively(c context.Context, panels []any, libraryPanels map[string]int64) error {
 	for _, panel := range panels {
 		panelAsJSON := simplejson.NewFromAny(panel)
 		libraryPanel := panelAsJSON.Get("libraryPanel")
@@ -107,9 +105,13 @@ func connectLibraryPanelsRecursively(c context.Context, panels []any, libraryPan
 		if len(UID) == 0 {
 			return errLibraryPanelHeaderUIDMissing
 		}
-		_, exists := libraryPanels[UID]
-		if !exists {
-			libraryPanels[UID] = UID
+		pinnedVersion := libraryPanel.Get("pinnedVersion").MustInt64()
+		if pinnedVersion < 0 {
+			return errLibraryPanelPinnedVersionInvalid
+		}
+		version, exists := libraryPanels[UID]
+		if !exists || (pinnedVersion > 0 && (version == 0 || pinnedVersion < version)) {
+			libraryPanels[UID] = pinnedVersion
 		}
 	}
 
@@ -343,6 +343,30 @@ func TestConnectLibraryPanelsForDashboard(t *testing.T) {
 		})
 }
 
+func TestConnectLibraryPanelsRecursively(t *testing.T) {
+	panels := []any{
+		map[string]any{"id": 1, "libraryPanel": map[string]any{"uid": "latest"}},
+		map[string]any{"id": 2, "libraryPanel": map[string]any{"uid": "pinned", "pinnedVersion": 3}},
+		map[string]any{
+			"type": "row",
+			"panels": []any{
+				map[string]any{"id": 3, "libraryPanel": map[string]any{"uid": "pinned", "pinnedVersion": 2}},
+				map[string]any{"id": 4, "libraryPanel": map[string]any{"uid": "pinned"}},
+				map[string]any{"id": 5, "libraryPanel": map[string]any{"uid": "latest", "pinnedVersion": 4}},
+			},
+		},
+	}
+
+	libraryPanels := make(map[string]int64)
+	err := connectLibraryPanelsRecursively(context.Background(), panels, libraryPanels)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"latest": 4, "pinned": 2}, libraryPanels)
+
+	panels = []any{map[string]any{"id": 1, "libraryPanel": map[string]any{"uid": "pinned", "pinnedVersion": -1}}}
+	err = connectLibraryPanelsRecursively(context.Background(), panels, make(map[string]int64))
+	require.ErrorIs(t, err, errLibraryPanelPinnedVersionInvalid)
+}
+
 func TestImportLibraryPanelsForDashboard(t *testing.T) {
 	testScenario(t, "When an admin tries to import a dashboard with a library panel that does not exist, it should import the library panel",
 		func(t *testing.T, sc scenarioContext) {
@@ -9,4 +9,6 @@ var (
 	errLibraryPanelHeaderUIDMissing = errors.New("library panel header is missing required property uid")
 	// errLibraryPanelHeaderNameMissing is an error for when a library panel header is missing the name property.
 	errLibraryPanelHeaderNameMissing = errors.New("library panel header is missing required property name")
+	// errLibraryPanelPinnedVersionInvalid is an error for when a library panel header pins a negative version.
+	errLibraryPanelPinnedVersionInvalid = errors.New("library panel header property pinnedVersion must be a positive number")
 )
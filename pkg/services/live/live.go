@@ -154,17 +154,21 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	historyConfig := managedstream.HistoryConfig{
+		MaxFrames: g.Cfg.LiveHistoryMaxFrames,
+		MaxAge:    g.Cfg.LiveHistoryMaxAge,
+	}
 	if redisClient != nil {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient, g.keyPrefix),
+			managedstream.NewRedisFrameCache(redisClient, g.keyPrefix, historyConfig),
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCache(historyConfig),
 		)
 	}
 
@@ -470,6 +474,21 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.Pipeline != nil {
+		// Flush pipeline windows closed by time.
+		eGroup.Go(func() error {
+			return g.Pipeline.Run(eCtx)
+		})
+	}
+
+	if lister, ok := g.pipelineStorage.(pipeline.InputConfigLister); ok && g.Pipeline != nil {
+		// Subscribe to the brokers configured in pipeline inputs.
+		inputRunner := pipeline.NewInputRunner(lister, g.Pipeline, g.SecretsService)
+		eGroup.Go(func() error {
+			return inputRunner.Run(eCtx)
+		})
+	}
+
 	return eGroup.Wait()
 }
 
@@ -1119,6 +1138,26 @@ func (s *DryRunRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]pipe
 	return s.ChannelRules, nil
 }
 
+func (s *DryRunRuleStorage) ListInputConfigs(_ context.Context, _ int64) ([]pipeline.InputConfig, error) {
+	return nil, nil
+}
+
+func (s *DryRunRuleStorage) GetInputConfig(_ context.Context, _ int64, _ pipeline.InputConfigGetCmd) (pipeline.InputConfig, bool, error) {
+	return pipeline.InputConfig{}, false, errors.New("not implemented by dry run rule storage")
+}
+
+func (s *DryRunRuleStorage) CreateInputConfig(_ context.Context, _ int64, _ pipeline.InputConfigCreateCmd) (pipeline.InputConfig, error) {
+	return pipeline.InputConfig{}, errors.New("not implemented by dry run rule storage")
+}
+
+func (s *DryRunRuleStorage) UpdateInputConfig(_ context.Context, _ int64, _ pipeline.InputConfigUpdateCmd) (pipeline.InputConfig, error) {
+	return pipeline.InputConfig{}, errors.New("not implemented by dry run rule storage")
+}
+
+func (s *DryRunRuleStorage) DeleteInputConfig(_ context.Context, _ int64, _ pipeline.InputConfigDeleteCmd) error {
+	return errors.New("not implemented by dry run rule storage")
+}
+
 // HandlePipelineConvertTestHTTP ...
 func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext) response.Response {
 	body, err := io.ReadAll(c.Req.Body)
@@ -1236,6 +1275,7 @@ func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *contextmodel.ReqContext)
 		"converters":      pipeline.ConvertersRegistry,
 		"frameProcessors": pipeline.FrameProcessorsRegistry,
 		"frameOutputs":    pipeline.FrameOutputsRegistry,
+		"inputs":          pipeline.InputsRegistry,
 	})
 }
 
@@ -1339,6 +1379,106 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *contextmodel.ReqContext) r
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// HandleInputConfigsListHTTP ...
+func (g *GrafanaLive) HandleInputConfigsListHTTP(c *contextmodel.ReqContext) response.Response {
+	inputs, err := g.pipelineStorage.ListInputConfigs(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get input configs", err)
+	}
+	result := make([]pipeline.InputConfigDto, 0, len(inputs))
+	for _, i := range inputs {
+		result = append(result, pipeline.InputConfigToDto(i))
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"inputConfigs": result,
+	})
+}
+
+// HandleInputConfigsPostHTTP ...
+func (g *GrafanaLive) HandleInputConfigsPostHTTP(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var cmd pipeline.InputConfigCreateCmd
+	err = json.Unmarshal(body, &cmd)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding input config create command", err)
+	}
+	result, err := g.pipelineStorage.CreateInputConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to create input config", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"inputConfig": pipeline.InputConfigToDto(result),
+	})
+}
+
+// HandleInputConfigsPutHTTP ...
+func (g *GrafanaLive) HandleInputConfigsPutHTTP(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var cmd pipeline.InputConfigUpdateCmd
+	err = json.Unmarshal(body, &cmd)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding input config update command", err)
+	}
+	if cmd.UID == "" {
+		return response.Error(http.StatusBadRequest, "UID required", nil)
+	}
+	existingInput, ok, err := g.pipelineStorage.GetInputConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), pipeline.InputConfigGetCmd{
+		UID: cmd.UID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get input config", err)
+	}
+	if ok {
+		if cmd.SecureSettings == nil {
+			cmd.SecureSettings = map[string]string{}
+		}
+		secureJSONData, err := g.SecretsService.DecryptJsonData(c.Req.Context(), existingInput.SecureSettings)
+		if err != nil {
+			logger.Error("Error decrypting secure settings", "error", err)
+			return response.Error(http.StatusInternalServerError, "Error decrypting secure settings", err)
+		}
+		for k, v := range secureJSONData {
+			if _, ok := cmd.SecureSettings[k]; !ok {
+				cmd.SecureSettings[k] = v
+			}
+		}
+	}
+	result, err := g.pipelineStorage.UpdateInputConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update input config", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"inputConfig": pipeline.InputConfigToDto(result),
+	})
+}
+
+// HandleInputConfigsDeleteHTTP ...
+func (g *GrafanaLive) HandleInputConfigsDeleteHTTP(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var cmd pipeline.InputConfigDeleteCmd
+	err = json.Unmarshal(body, &cmd)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding input config delete command", err)
+	}
+	if cmd.UID == "" {
+		return response.Error(http.StatusBadRequest, "UID required", nil)
+	}
+	err = g.pipelineStorage.DeleteInputConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete input config", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{})
+}
+
 // Write to the standard log15 logger
 func handleLog(msg centrifuge.LogEntry) {
 	arr := make([]interface{}, 0)
@@ -3,6 +3,7 @@ package managedstream
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -16,3 +17,23 @@ type FrameCache interface {
 	// Update updates frame cache and returns true if schema changed.
 	Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
 }
+
+// FrameHistory is implemented by frame caches which keep recent frames of a channel,
+// so they can be replayed to subscribers joining mid-stream.
+type FrameHistory interface {
+	// GetHistory returns full JSON frames pushed to a channel since the given time, oldest first.
+	GetHistory(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error)
+}
+
+// HistoryConfig limits the number of frames kept in history per channel. History is
+// disabled when MaxFrames is zero.
+type HistoryConfig struct {
+	// MaxFrames is a maximum number of frames kept per channel.
+	MaxFrames int
+	// MaxAge is a maximum age of kept frames, frames are only limited by MaxFrames when zero.
+	MaxAge time.Duration
+}
+
+func (c HistoryConfig) enabled() bool {
+	return c.MaxFrames > 0
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -12,16 +13,25 @@ import (
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
-	log    log.Logger
+	mu      sync.RWMutex
+	frames  map[int64]map[string]data.FrameJSONCache
+	history map[int64]map[string][]historyEntry
+	config  HistoryConfig
+	log     log.Logger
+}
+
+type historyEntry struct {
+	time  time.Time
+	frame json.RawMessage
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(config HistoryConfig) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
-		log:    log.New("live.memoryframecache"),
+		frames:  map[int64]map[string]data.FrameJSONCache{},
+		history: map[int64]map[string][]historyEntry{},
+		config:  config,
+		log:     log.New("live.memoryframecache"),
 	}
 }
 
@@ -61,6 +71,9 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.config.enabled() {
+		c.appendHistory(orgID, channel, jsonFrame.Bytes(data.IncludeAll), time.Now())
+	}
 	c.log.Debug("Cache update",
 		"orgId", orgID,
 		"channel", channel,
@@ -68,3 +81,38 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	)
 	return schemaUpdated, nil
 }
+
+// appendHistory adds a frame to the channel ring buffer, dropping frames over the limits.
+func (c *MemoryFrameCache) appendHistory(orgID int64, channel string, frame json.RawMessage, now time.Time) {
+	if _, ok := c.history[orgID]; !ok {
+		c.history[orgID] = map[string][]historyEntry{}
+	}
+	entries := append(c.history[orgID][channel], historyEntry{time: now, frame: frame})
+	start := 0
+	if len(entries) > c.config.MaxFrames {
+		start = len(entries) - c.config.MaxFrames
+	}
+	if c.config.MaxAge > 0 {
+		for start < len(entries) && now.Sub(entries[start].time) > c.config.MaxAge {
+			start++
+		}
+	}
+	// Dropped frames are released once append reallocates the slice.
+	c.history[orgID][channel] = entries[start:]
+}
+
+func (c *MemoryFrameCache) GetHistory(_ context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var frames []json.RawMessage
+	for _, e := range c.history[orgID][channel] {
+		if c.config.MaxAge > 0 && time.Since(e.time) > c.config.MaxAge {
+			continue
+		}
+		if e.time.Before(since) {
+			continue
+		}
+		frames = append(frames, e.frame)
+	}
+	return frames, nil
+}
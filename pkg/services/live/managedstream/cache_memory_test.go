@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -63,7 +64,50 @@ func testFrameCache(t *testing.T, c FrameCache) {
 }
 
 func TestMemoryFrameCache(t *testing.T) {
-	c := NewMemoryFrameCache()
+	c := NewMemoryFrameCache(HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestMemoryFrameCache_History(t *testing.T) {
+	c := NewMemoryFrameCache(HistoryConfig{MaxFrames: 2, MaxAge: time.Minute})
+
+	for _, name := range []string{"a", "b", "c"} {
+		frameJsonCache, err := data.FrameToJSONCache(data.NewFrame(name))
+		require.NoError(t, err)
+		_, err = c.Update(context.Background(), 1, "test", frameJsonCache)
+		require.NoError(t, err)
+	}
+
+	// Only the last MaxFrames frames are kept.
+	frames, err := c.GetHistory(context.Background(), 1, "test", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+	var f data.Frame
+	require.NoError(t, json.Unmarshal(frames[0], &f))
+	require.Equal(t, "b", f.Name)
+
+	frames, err = c.GetHistory(context.Background(), 1, "test", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, frames)
+
+	frames, err = c.GetHistory(context.Background(), 2, "test", time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, frames)
+
+	// Frames older than MaxAge are dropped.
+	c.appendHistory(1, "test", json.RawMessage(`{}`), time.Now().Add(2*time.Minute))
+	require.Len(t, c.history[1]["test"], 1)
+}
+
+func TestMemoryFrameCache_HistoryDisabled(t *testing.T) {
+	c := NewMemoryFrameCache(HistoryConfig{})
+	frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("a"))
+	require.NoError(t, err)
+	_, err = c.Update(context.Background(), 1, "test", frameJsonCache)
+	require.NoError(t, err)
+
+	frames, err := c.GetHistory(context.Background(), 1, "test", time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, frames)
+}
@@ -19,14 +19,16 @@ type RedisFrameCache struct {
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
 	keyPrefix   string
+	config      HistoryConfig
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client, keyPrefix string) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, keyPrefix string, config HistoryConfig) *RedisFrameCache {
 	return &RedisFrameCache{
 		keyPrefix:   keyPrefix,
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		config:      config,
 	}
 }
 
@@ -83,6 +85,24 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 	})
 	pipe.Expire(ctx, key, frameCacheTTL)
 
+	if c.config.enabled() {
+		entry, err := json.Marshal(redisHistoryEntry{
+			Time:  time.Now().UnixMilli(),
+			Frame: jsonFrame.Bytes(data.IncludeAll),
+		})
+		if err != nil {
+			return false, err
+		}
+		historyKey := c.getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+		historyTTL := frameCacheTTL
+		if c.config.MaxAge > 0 {
+			historyTTL = c.config.MaxAge
+		}
+		pipe.RPush(ctx, historyKey, entry)
+		pipe.LTrim(ctx, historyKey, int64(-c.config.MaxFrames), -1)
+		pipe.Expire(ctx, historyKey, historyTTL)
+	}
+
 	replies, err := pipe.Exec(ctx)
 	if err != nil {
 		return false, err
@@ -112,3 +132,39 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 func (c *RedisFrameCache) getCacheKey(channelID string) string {
 	return c.keyPrefix + ".managed_stream." + channelID
 }
+
+func (c *RedisFrameCache) getHistoryKey(channelID string) string {
+	return c.keyPrefix + ".managed_stream_history." + channelID
+}
+
+type redisHistoryEntry struct {
+	Time  int64           `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+func (c *RedisFrameCache) GetHistory(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	if !c.config.enabled() {
+		return nil, nil
+	}
+	key := c.getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := c.redisClient.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	var frames []json.RawMessage
+	for _, item := range result {
+		var entry redisHistoryEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			return nil, err
+		}
+		t := time.UnixMilli(entry.Time)
+		if c.config.MaxAge > 0 && time.Since(t) > c.config.MaxAge {
+			continue
+		}
+		if t.Before(since) {
+			continue
+		}
+		frames = append(frames, entry.Frame)
+	}
+	return frames, nil
+}
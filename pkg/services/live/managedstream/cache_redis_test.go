@@ -36,7 +36,7 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 
 	t.Cleanup(redisCleanup(t, redisClient, prefix))
 
-	c := NewRedisFrameCache(redisClient, prefix)
+	c := NewRedisFrameCache(redisClient, prefix, HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 
@@ -239,8 +239,40 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (model.ChannelHandler, err
 	return s, nil
 }
 
+// SubscribeOptions can be sent by clients in subscribe request data.
+type SubscribeOptions struct {
+	// Replay asks to send frames kept in history on join instead of the last frame.
+	Replay bool `json:"replay,omitempty"`
+	// ReplayMilliseconds limits the replayed history, all kept history is sent when zero.
+	ReplayMilliseconds int64 `json:"replayMs,omitempty"`
+}
+
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := model.SubscribeReply{}
+	var opts SubscribeOptions
+	if len(e.Data) > 0 {
+		if err := json.Unmarshal(e.Data, &opts); err != nil {
+			logger.Debug("Ignoring invalid subscribe options", "channel", e.Channel, "error", err)
+		}
+	}
+	if history, ok := s.frameCache.(FrameHistory); ok && opts.Replay {
+		since := time.Time{}
+		if opts.ReplayMilliseconds > 0 {
+			since = time.Now().Add(-time.Duration(opts.ReplayMilliseconds) * time.Millisecond)
+		}
+		frames, err := history.GetHistory(ctx, u.GetOrgID(), e.Channel, since)
+		if err != nil {
+			return reply, 0, err
+		}
+		if len(frames) > 0 {
+			frameJSON, err := mergeFrames(frames)
+			if err != nil {
+				return reply, 0, err
+			}
+			reply.Data = frameJSON
+			return reply, backend.SubscribeStreamStatusOK, nil
+		}
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.GetOrgID(), e.Channel)
 	if err != nil {
 		return reply, 0, err
@@ -254,3 +286,45 @@ func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester,
 func (s *NamespaceStream) OnPublish(_ context.Context, _ identity.Requester, _ model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
 	return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
 }
+
+// mergeFrames appends rows of history frames to a single frame. The schema of the
+// latest frame is used, older frames with a different schema are skipped.
+func mergeFrames(frames []json.RawMessage) (json.RawMessage, error) {
+	var latest data.Frame
+	if err := json.Unmarshal(frames[len(frames)-1], &latest); err != nil {
+		return nil, err
+	}
+	merged := latest.EmptyCopy()
+	for i, raw := range frames {
+		frame := &latest
+		if i < len(frames)-1 {
+			frame = &data.Frame{}
+			if err := json.Unmarshal(raw, frame); err != nil {
+				return nil, err
+			}
+			if !sameSchema(frame, merged) {
+				continue
+			}
+		}
+		rowLen, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		for row := 0; row < rowLen; row++ {
+			merged.AppendRow(frame.RowCopy(row)...)
+		}
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
+
+func sameSchema(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -2,11 +2,16 @@ package managedstream
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/user"
 )
 
 type testPublisher struct {
@@ -19,13 +24,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -47,7 +52,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	frameCache := NewMemoryFrameCache()
+	frameCache := NewMemoryFrameCache(HistoryConfig{})
 	runner := NewRunner(publisher.publish, nil, frameCache)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
@@ -83,3 +88,44 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestNamespaceStream_OnSubscribeReplay(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	runner := NewRunner(publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{MaxFrames: 10}))
+	s, err := runner.GetOrCreateStream(1, "stream", "test")
+	require.NoError(t, err)
+
+	for i := int64(0); i < 3; i++ {
+		err = s.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []int64{i})))
+		require.NoError(t, err)
+	}
+	// Frame with another schema breaks the history.
+	err = s.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []float64{3})))
+	require.NoError(t, err)
+	err = s.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []float64{4})))
+	require.NoError(t, err)
+
+	u := &user.SignedInUser{UserID: 2, OrgID: 1}
+	rowValues := func(reply model.SubscribeReply) []any {
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(reply.Data, &f))
+		values := make([]any, f.Fields[0].Len())
+		for i := range values {
+			values[i] = f.Fields[0].At(i)
+		}
+		return values
+	}
+
+	reply, status, err := s.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "stream/test/cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	require.Equal(t, []any{4.0}, rowValues(reply))
+
+	reply, status, err = s.OnSubscribe(context.Background(), u, model.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Data:    json.RawMessage(`{"replay":true}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	require.Equal(t, []any{3.0, 4.0}, rowValues(reply))
+}
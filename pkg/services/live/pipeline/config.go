@@ -40,6 +40,9 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	PrometheusConverterConfig *PrometheusConverterConfig `json:"prometheus,omitempty"`
+	CSVConverterConfig        *CSVConverterConfig        `json:"csv,omitempty"`
+	ProtobufConverterConfig   *ProtobufConverterConfig   `json:"protobuf,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -55,6 +58,26 @@ type FrameProcessorConfig struct {
 	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
 	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
 	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	WindowProcessorConfig     *WindowFrameProcessorConfig     `json:"window,omitempty"`
+}
+
+type WindowFrameProcessorConfig struct {
+	// SizeMilliseconds is a window duration.
+	SizeMilliseconds int64 `json:"sizeMilliseconds"`
+	// SlideMilliseconds is a period of sliding windows. Windows are tumbling
+	// when not set or equal to SizeMilliseconds.
+	SlideMilliseconds int64 `json:"slideMilliseconds,omitempty"`
+	// Aggregations is a list of avg, min, max, count or last. With several
+	// aggregations the name of aggregation is appended to field names.
+	Aggregations []string `json:"aggregations"`
+	// FieldNames to aggregate, all numeric fields when empty.
+	FieldNames []string `json:"fieldNames,omitempty"`
+	// AllowedLatenessMilliseconds keeps windows open after the highest frame
+	// time passed their end, so frames arriving out of order are still counted.
+	AllowedLatenessMilliseconds int64 `json:"allowedLatenessMilliseconds,omitempty"`
+	// IdleTimeoutMilliseconds closes all windows of a channel when no frame
+	// arrived for this long. Defaults to SizeMilliseconds.
+	IdleTimeoutMilliseconds int64 `json:"idleTimeoutMilliseconds,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -152,4 +175,24 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type PrometheusConverterConfig struct{}
+
+type CSVConverterConfig struct {
+	// Delimiter is a single character separating the values, comma by default.
+	Delimiter string `json:"delimiter,omitempty"`
+	// TimeField is a name of the column with row time, "time" by default.
+	TimeField string `json:"timeField,omitempty"`
+	// TimeFormat is a Go time layout or one of unix, unix_ms. RFC 3339 by default.
+	TimeFormat string `json:"timeFormat,omitempty"`
+}
+
+type ProtobufConverterConfig struct {
+	// DescriptorSet is a base64 encoded FileDescriptorSet, as produced by
+	// protoc --descriptor_set_out --include_imports.
+	DescriptorSet string `json:"descriptorSet"`
+	// MessageType is a fully qualified name of the pushed message.
+	MessageType string           `json:"messageType"`
+	FieldTips   map[string]Field `json:"fieldTips,omitempty"`
+}
+
 type ManagedStreamOutputConfig struct{}
@@ -0,0 +1,181 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// CSVConverter decodes CSV input with a header row into a single frame. Each row
+// of the input becomes a row of the frame.
+type CSVConverter struct {
+	config      CSVConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+// NewCSVConverter creates new CSVConverter.
+func NewCSVConverter(c CSVConverterConfig) *CSVConverter {
+	return &CSVConverter{config: c}
+}
+
+const ConverterTypeCSV = "csv"
+
+const (
+	csvTimeFormatUnix   = "unix"
+	csvTimeFormatUnixMs = "unix_ms"
+)
+
+func (c *CSVConverter) Type() string {
+	return ConverterTypeCSV
+}
+
+// Conversion works this way:
+// * Column types are detected from the values: numbers, booleans, strings otherwise
+// * Empty values are converted to nulls
+// * Time column is parsed using TimeFormat, time added automatically when there is no such column
+func (c *CSVConverter) Convert(_ context.Context, _ Vars, body []byte) ([]*ChannelFrame, error) {
+	r := csv.NewReader(bytes.NewReader(body))
+	if c.config.Delimiter != "" {
+		delimiter := []rune(c.config.Delimiter)
+		if len(delimiter) != 1 {
+			return nil, fmt.Errorf("invalid delimiter %q", c.config.Delimiter)
+		}
+		r.Comma = delimiter[0]
+	}
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header row")
+	}
+	header, rows := records[0], records[1:]
+
+	timeField := c.config.TimeField
+	if timeField == "" {
+		timeField = "time"
+	}
+
+	fields := make([]*data.Field, 0, len(header)+1)
+	hasTime := false
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		values := make([]string, len(rows))
+		for j, row := range rows {
+			values[j] = strings.TrimSpace(row[i])
+		}
+		var f *data.Field
+		if name == timeField {
+			f, err = c.timeColumn(values)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing %s column: %w", name, err)
+			}
+			hasTime = true
+		} else {
+			f = csvColumn(values)
+		}
+		f.Name = name
+		fields = append(fields, f)
+	}
+
+	if !hasTime {
+		nowTimeFunc := c.nowTimeFunc
+		if nowTimeFunc == nil {
+			nowTimeFunc = time.Now
+		}
+		times := make([]time.Time, len(rows))
+		now := nowTimeFunc()
+		for i := range times {
+			times[i] = now
+		}
+		fields = append([]*data.Field{data.NewField("time", nil, times)}, fields...)
+	}
+
+	return []*ChannelFrame{
+		{Channel: "", Frame: data.NewFrame("", fields...)},
+	}, nil
+}
+
+func (c *CSVConverter) timeColumn(values []string) (*data.Field, error) {
+	f := data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(values))
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		t, err := c.parseTime(v)
+		if err != nil {
+			return nil, err
+		}
+		f.SetConcrete(i, t)
+	}
+	return f, nil
+}
+
+func (c *CSVConverter) parseTime(v string) (time.Time, error) {
+	switch c.config.TimeFormat {
+	case csvTimeFormatUnix, csvTimeFormatUnixMs:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if c.config.TimeFormat == csvTimeFormatUnix {
+			n *= 1000
+		}
+		return time.UnixMilli(int64(n)).UTC(), nil
+	case "":
+		return time.Parse(time.RFC3339Nano, v)
+	default:
+		return time.Parse(c.config.TimeFormat, v)
+	}
+}
+
+// csvColumn converts column values to a field of the narrowest type all the values fit.
+func csvColumn(values []string) *data.Field {
+	isNumber, isBool := true, true
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			isNumber = false
+		}
+		if _, err := strconv.ParseBool(v); err != nil {
+			isBool = false
+		}
+	}
+
+	var f *data.Field
+	switch {
+	case isNumber:
+		f = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(values))
+	case isBool:
+		f = data.NewFieldFromFieldType(data.FieldTypeNullableBool, len(values))
+	default:
+		f = data.NewFieldFromFieldType(data.FieldTypeNullableString, len(values))
+	}
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		switch {
+		case isNumber:
+			n, _ := strconv.ParseFloat(v, 64)
+			f.SetConcrete(i, n)
+		case isBool:
+			b, _ := strconv.ParseBool(v)
+			f.SetConcrete(i, b)
+		default:
+			f.SetConcrete(i, v)
+		}
+	}
+	return f
+}
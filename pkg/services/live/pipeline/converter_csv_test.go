@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVConverter_Convert(t *testing.T) {
+	// nolint:gosec
+	content, err := os.ReadFile(filepath.Join("testdata", "csv.txt"))
+	require.NoError(t, err)
+
+	converter := NewCSVConverter(CSVConverterConfig{TimeFormat: "unix_ms"})
+	channelFrames, err := converter.Convert(context.Background(), Vars{}, content)
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+	require.Empty(t, channelFrames[0].Channel)
+
+	dr := &backend.DataResponse{Frames: data.Frames{channelFrames[0].Frame}}
+	experimental.CheckGoldenJSONResponse(t, "testdata", "csv.golden", dr, *update)
+}
+
+func TestCSVConverter_Convert_options(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	converter := NewCSVConverter(CSVConverterConfig{Delimiter: ";"})
+	converter.nowTimeFunc = func() time.Time { return now }
+
+	channelFrames, err := converter.Convert(context.Background(), Vars{}, []byte("device;value\nsensor-1;1\nsensor-2;x\n"))
+	require.NoError(t, err)
+	frame := channelFrames[0].Frame
+	require.Equal(t, 3, len(frame.Fields))
+	require.Equal(t, now, frame.Fields[0].At(1))
+	// a column with a non numeric value is a string column
+	require.Equal(t, data.FieldTypeNullableString, frame.Fields[2].Type())
+
+	_, err = converter.Convert(context.Background(), Vars{}, []byte(""))
+	require.Error(t, err)
+
+	_, err = NewCSVConverter(CSVConverterConfig{}).Convert(context.Background(), Vars{}, []byte("time,value\nyesterday,1\n"))
+	require.ErrorContains(t, err, "error parsing time column")
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// PrometheusConverter decodes Prometheus text exposition format and transforms
+// it to several ChannelFrame objects where Channel is constructed from original
+// channel + / + <metric_name>. Each series of a metric family becomes a field
+// of a single row frame with series labels attached to the field.
+type PrometheusConverter struct {
+	config      PrometheusConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+// NewPrometheusConverter creates new PrometheusConverter.
+func NewPrometheusConverter(c PrometheusConverterConfig) *PrometheusConverter {
+	return &PrometheusConverter{config: c}
+}
+
+const ConverterTypePrometheus = "prometheus"
+
+func (c *PrometheusConverter) Type() string {
+	return ConverterTypePrometheus
+}
+
+func (c *PrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+	now := nowTimeFunc()
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	channelFrames := make([]*ChannelFrame, 0, len(families))
+	for _, name := range names {
+		frame := metricFamilyToFrame(families[name], now)
+		if frame == nil {
+			continue
+		}
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: vars.Channel + "/" + name,
+			Frame:   frame,
+		})
+	}
+	return channelFrames, nil
+}
+
+// metricFamilyToFrame returns a single row frame. Histogram and summary series are
+// expanded to the _bucket, _sum and _count fields like Prometheus does when scraping.
+func metricFamilyToFrame(mf *dto.MetricFamily, now time.Time) *data.Frame {
+	name := mf.GetName()
+	ts := now
+	fields := []*data.Field{data.NewField("time", nil, []time.Time{ts})}
+
+	addValue := func(fieldName string, labels data.Labels, v float64) {
+		fields = append(fields, data.NewField(fieldName, labels, []float64{v}))
+	}
+
+	for _, m := range mf.GetMetric() {
+		if m.TimestampMs != nil {
+			ts = time.UnixMilli(m.GetTimestampMs()).UTC()
+		}
+		labels := data.Labels{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			addValue(name, labels, m.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			addValue(name, labels, m.GetGauge().GetValue())
+		case dto.MetricType_UNTYPED:
+			addValue(name, labels, m.GetUntyped().GetValue())
+		case dto.MetricType_SUMMARY:
+			s := m.GetSummary()
+			for _, q := range s.GetQuantile() {
+				addValue(name, labelsWith(labels, "quantile", formatFloat(q.GetQuantile())), q.GetValue())
+			}
+			addValue(name+"_sum", labels, s.GetSampleSum())
+			addValue(name+"_count", labels, float64(s.GetSampleCount()))
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			h := m.GetHistogram()
+			for _, b := range h.GetBucket() {
+				addValue(name+"_bucket", labelsWith(labels, "le", formatFloat(b.GetUpperBound())), float64(b.GetCumulativeCount()))
+			}
+			addValue(name+"_sum", labels, h.GetSampleSum())
+			addValue(name+"_count", labels, float64(h.GetSampleCount()))
+		}
+	}
+	if len(fields) == 1 {
+		return nil
+	}
+	// Explicit sample timestamps take precedence over the time of arrival.
+	fields[0].Set(0, ts)
+	return data.NewFrame(name, fields...)
+}
+
+func labelsWith(labels data.Labels, key, value string) data.Labels {
+	l := labels.Copy()
+	l[key] = value
+	return l
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,40 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusConverter_Convert(t *testing.T) {
+	// nolint:gosec
+	content, err := os.ReadFile(filepath.Join("testdata", "prometheus.txt"))
+	require.NoError(t, err)
+
+	converter := NewPrometheusConverter(PrometheusConverterConfig{})
+	converter.nowTimeFunc = func() time.Time {
+		return time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	}
+	channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/test/metrics"}, content)
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 3)
+
+	dr := &backend.DataResponse{}
+	for _, cf := range channelFrames {
+		require.Equal(t, "stream/test/metrics/"+cf.Frame.Name, cf.Channel)
+		dr.Frames = append(dr.Frames, cf.Frame)
+	}
+	experimental.CheckGoldenJSONResponse(t, "testdata", "prometheus.golden", dr, *update)
+}
+
+func TestPrometheusConverter_Convert_invalid(t *testing.T) {
+	converter := NewPrometheusConverter(PrometheusConverterConfig{})
+	_, err := converter.Convert(context.Background(), Vars{}, []byte("metric{ 1"))
+	require.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtobufConverter decodes protobuf messages described by a descriptor set
+// and converts them to a frame the same way AutoJsonConverter does with JSON
+// documents.
+type ProtobufConverter struct {
+	config      ProtobufConverterConfig
+	descriptor  protoreflect.MessageDescriptor
+	nowTimeFunc func() time.Time
+}
+
+// NewProtobufConverter creates new ProtobufConverter. Returns an error when the
+// message type can't be resolved from the configured descriptor set.
+func NewProtobufConverter(c ProtobufConverterConfig) (*ProtobufConverter, error) {
+	md, err := resolveMessageDescriptor(c.DescriptorSet, c.MessageType)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtobufConverter{config: c, descriptor: md}, nil
+}
+
+const ConverterTypeProtobuf = "protobuf"
+
+func (c *ProtobufConverter) Type() string {
+	return ConverterTypeProtobuf
+}
+
+// Conversion works this way:
+// * Message fields are flattened using proto field names, nested messages use a dot separator
+// * Scalar fields are always present, unset scalars have the default value
+// * Enums are converted to their names, timestamps to RFC 3339 strings
+func (c *ProtobufConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	msg := dynamicpb.NewMessage(c.descriptor)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("error decoding %s message: %w", c.descriptor.FullName(), err)
+	}
+	doc, err := json.Marshal(protoMessageToDoc(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+	frame, err := jsonDocToFrame(vars.Path, doc, c.config.FieldTips, nowTimeFunc)
+	if err != nil {
+		return nil, err
+	}
+	return []*ChannelFrame{
+		{Channel: "", Frame: frame},
+	}, nil
+}
+
+// resolveMessageDescriptor finds the message in a base64 encoded FileDescriptorSet,
+// as produced by protoc --descriptor_set_out --include_imports.
+func resolveMessageDescriptor(descriptorSet string, messageType string) (protoreflect.MessageDescriptor, error) {
+	if descriptorSet == "" {
+		return nil, fmt.Errorf("descriptor set required")
+	}
+	if messageType == "" {
+		return nil, fmt.Errorf("message type required")
+	}
+	raw, err := base64.StdEncoding.DecodeString(descriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding descriptor set: %w", err)
+	}
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &fds); err != nil {
+		return nil, fmt.Errorf("error decoding descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(messageType))
+	if err != nil {
+		return nil, fmt.Errorf("message type %s not found in descriptor set: %w", messageType, err)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message type", messageType)
+	}
+	return md, nil
+}
+
+// protoMessageToDoc converts the message to values encoding/json can marshal.
+// Unlike protojson, 64-bit integers are kept as numbers and default values are kept
+// so frame schema does not change between messages.
+func protoMessageToDoc(m protoreflect.Message) any {
+	md := m.Descriptor()
+	if md.FullName() == "google.protobuf.Timestamp" {
+		fields := md.Fields()
+		seconds := m.Get(fields.ByName("seconds")).Int()
+		nanos := m.Get(fields.ByName("nanos")).Int()
+		return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+	}
+
+	doc := map[string]any{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if (fd.ContainingOneof() != nil || fd.Message() != nil) && !m.Has(fd) {
+			continue
+		}
+		v := m.Get(fd)
+		switch {
+		case fd.IsList():
+			list := v.List()
+			items := make([]any, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				items[j] = protoValueToDoc(fd, list.Get(j))
+			}
+			doc[string(fd.Name())] = items
+		case fd.IsMap():
+			entries := map[string]any{}
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				entries[k.String()] = protoValueToDoc(fd.MapValue(), mv)
+				return true
+			})
+			doc[string(fd.Name())] = entries
+		default:
+			doc[string(fd.Name())] = protoValueToDoc(fd, v)
+		}
+	}
+	return doc
+}
+
+func protoValueToDoc(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protoMessageToDoc(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func testDescriptorSet(t *testing.T) string {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	status := field("status", 5, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional)
+	status.TypeName = proto.String(".sensors.Status")
+	location := field("location", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional)
+	location.TypeName = proto.String(".sensors.Location")
+
+	fds := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("sensors.proto"),
+		Package: proto.String("sensors"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("OK"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Location"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("lat", 1, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional),
+					field("lon", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional),
+				},
+			},
+			{
+				Name: proto.String("Reading"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("device", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+					field("temperature", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional),
+					field("counter", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional),
+					field("online", 4, descriptorpb.FieldDescriptorProto_TYPE_BOOL, optional),
+					status,
+					location,
+					field("samples", 7, descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+				},
+			},
+		},
+	}}}
+	raw, err := proto.Marshal(fds)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestProtobufConverter_Convert(t *testing.T) {
+	converter, err := NewProtobufConverter(ProtobufConverterConfig{
+		DescriptorSet: testDescriptorSet(t),
+		MessageType:   "sensors.Reading",
+	})
+	require.NoError(t, err)
+	converter.nowTimeFunc = func() time.Time {
+		return time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	}
+
+	msg := dynamicpb.NewMessage(converter.descriptor)
+	fields := converter.descriptor.Fields()
+	msg.Set(fields.ByName("device"), protoreflect.ValueOf("sensor-1"))
+	msg.Set(fields.ByName("temperature"), protoreflect.ValueOf(21.5))
+	msg.Set(fields.ByName("counter"), protoreflect.ValueOf(int64(1)<<40))
+	msg.Set(fields.ByName("status"), protoreflect.ValueOfEnum(1))
+	location := msg.Mutable(fields.ByName("location")).Message()
+	location.Set(location.Descriptor().Fields().ByName("lat"), protoreflect.ValueOf(59.33))
+	location.Set(location.Descriptor().Fields().ByName("lon"), protoreflect.ValueOf(18.06))
+	samples := msg.Mutable(fields.ByName("samples")).List()
+	samples.Append(protoreflect.ValueOf(int32(1)))
+	samples.Append(protoreflect.ValueOf(int32(2)))
+
+	body, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	channelFrames, err := converter.Convert(context.Background(), Vars{}, body)
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+	require.Empty(t, channelFrames[0].Channel)
+
+	dr := &backend.DataResponse{Frames: data.Frames{channelFrames[0].Frame}}
+	experimental.CheckGoldenJSONResponse(t, "testdata", "protobuf.golden", dr, *update)
+
+	_, err = converter.Convert(context.Background(), Vars{}, []byte{0xff, 0xff})
+	require.Error(t, err)
+}
+
+func TestNewProtobufConverter_invalid(t *testing.T) {
+	_, err := NewProtobufConverter(ProtobufConverterConfig{MessageType: "sensors.Reading"})
+	require.ErrorContains(t, err, "descriptor set required")
+
+	_, err = NewProtobufConverter(ProtobufConverterConfig{DescriptorSet: "not base64!", MessageType: "sensors.Reading"})
+	require.ErrorContains(t, err, "error decoding descriptor set")
+
+	_, err = NewProtobufConverter(ProtobufConverterConfig{DescriptorSet: testDescriptorSet(t), MessageType: "sensors.Missing"})
+	require.ErrorContains(t, err, "not found in descriptor set")
+
+	_, err = NewProtobufConverter(ProtobufConverterConfig{DescriptorSet: testDescriptorSet(t), MessageType: "sensors.Status"})
+	require.ErrorContains(t, err, "is not a message type")
+}
@@ -26,6 +26,9 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
@@ -0,0 +1,408 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+const (
+	WindowAggregationAvg   = "avg"
+	WindowAggregationMin   = "min"
+	WindowAggregationMax   = "max"
+	WindowAggregationCount = "count"
+	WindowAggregationLast  = "last"
+)
+
+// WindowFrameProcessor aggregates numeric fields of incoming frames over tumbling
+// or sliding time windows. Frames are kept until a window is complete, so frames
+// are dropped from the pipeline until a frame with a time after the window end
+// plus the allowed lateness arrives or until no frame arrived for the idle
+// timeout, see Pipeline.Run. Then one row per closed window and label set is
+// passed further. Windows are closed by frame times only, so the clocks of
+// publishers don't have to match the server clock.
+//
+// Label sets are field labels and values of string fields (like labels column
+// of influxAuto converter). Other fields are dropped. Window state is kept in
+// memory per channel, so it's not shared in HA setup. Rule builds keep the
+// processor while its configuration is unchanged, so reloading rules does not
+// reset windows.
+type WindowFrameProcessor struct {
+	config          WindowFrameProcessorConfig
+	size            time.Duration
+	slide           time.Duration
+	allowedLateness time.Duration
+	idleTimeout     time.Duration
+	nowTimeFunc     func() time.Time
+
+	mu       sync.Mutex
+	channels map[string]*windowChannelState
+}
+
+func NewWindowFrameProcessor(config WindowFrameProcessorConfig) (*WindowFrameProcessor, error) {
+	if config.SizeMilliseconds <= 0 {
+		return nil, fmt.Errorf("window size required")
+	}
+	if config.SlideMilliseconds < 0 || config.SlideMilliseconds > config.SizeMilliseconds {
+		return nil, fmt.Errorf("window slide must be between 0 and window size")
+	}
+	if config.AllowedLatenessMilliseconds < 0 {
+		return nil, fmt.Errorf("window allowed lateness must not be negative")
+	}
+	if config.IdleTimeoutMilliseconds < 0 {
+		return nil, fmt.Errorf("window idle timeout must not be negative")
+	}
+	if len(config.Aggregations) == 0 {
+		return nil, fmt.Errorf("at least one aggregation required")
+	}
+	for _, a := range config.Aggregations {
+		switch a {
+		case WindowAggregationAvg, WindowAggregationMin, WindowAggregationMax, WindowAggregationCount, WindowAggregationLast:
+		default:
+			return nil, fmt.Errorf("unknown aggregation: %s", a)
+		}
+	}
+	size := time.Duration(config.SizeMilliseconds) * time.Millisecond
+	slide := time.Duration(config.SlideMilliseconds) * time.Millisecond
+	if slide == 0 {
+		slide = size
+	}
+	idleTimeout := time.Duration(config.IdleTimeoutMilliseconds) * time.Millisecond
+	if idleTimeout == 0 {
+		idleTimeout = size
+	}
+	return &WindowFrameProcessor{
+		config:          config,
+		size:            size,
+		slide:           slide,
+		allowedLateness: time.Duration(config.AllowedLatenessMilliseconds) * time.Millisecond,
+		idleTimeout:     idleTimeout,
+		channels:        map[string]*windowChannelState{},
+	}, nil
+}
+
+const FrameProcessorTypeWindow = "window"
+
+func (p *WindowFrameProcessor) Type() string {
+	return FrameProcessorTypeWindow
+}
+
+type windowChannelState struct {
+	// Vars and frame name of the last frame, used when windows are flushed by time.
+	vars Vars
+	name string
+	// Windows by start time.
+	windows map[time.Time]*window
+	// Closed windows must not be reopened by late frames.
+	watermark time.Time
+	// Highest frame time seen, windows are closed by it.
+	latest time.Time
+	// Server time of the last frame, for the idle timeout.
+	lastFrameAt time.Time
+}
+
+type window struct {
+	start  time.Time
+	groups map[string]*windowGroup
+	order  []string
+}
+
+type windowGroup struct {
+	dimensions []windowDimension
+	aggregates map[string]*windowAggregate
+	order      []string
+}
+
+type windowDimension struct {
+	name  string
+	value string
+}
+
+type windowAggregate struct {
+	name   string
+	labels data.Labels
+	count  int64
+	sum    float64
+	min    float64
+	max    float64
+	last   float64
+}
+
+func (a *windowAggregate) add(v float64) {
+	if a.count == 0 || v < a.min {
+		a.min = v
+	}
+	if a.count == 0 || v > a.max {
+		a.max = v
+	}
+	a.count++
+	a.sum += v
+	a.last = v
+}
+
+func (a *windowAggregate) value(aggregation string) float64 {
+	switch aggregation {
+	case WindowAggregationAvg:
+		return a.sum / float64(a.count)
+	case WindowAggregationMin:
+		return a.min
+	case WindowAggregationMax:
+		return a.max
+	case WindowAggregationCount:
+		return float64(a.count)
+	default:
+		return a.last
+	}
+}
+
+func (p *WindowFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	now := p.now()
+
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+	timeIndex := -1
+	for i, f := range frame.Fields {
+		if f.Type().Time() {
+			timeIndex = i
+			break
+		}
+	}
+
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state, ok := p.channels[key]
+	if !ok {
+		state = &windowChannelState{windows: map[time.Time]*window{}}
+		p.channels[key] = state
+	}
+	state.vars = vars
+	state.name = frame.Name
+	state.lastFrameAt = now
+
+	for row := 0; row < rowLen; row++ {
+		t := now
+		if timeIndex >= 0 {
+			if v, ok := frame.Fields[timeIndex].ConcreteAt(row); ok {
+				t = v.(time.Time)
+			}
+		}
+		if t.After(state.latest) {
+			state.latest = t
+		}
+		p.addRow(state, frame, row, t)
+	}
+	if state.latest.IsZero() {
+		return nil, nil
+	}
+	return p.flush(frame.Name, state, state.latest.Add(-p.allowedLateness)), nil
+}
+
+func (p *WindowFrameProcessor) now() time.Time {
+	if p.nowTimeFunc != nil {
+		return p.nowTimeFunc()
+	}
+	return time.Now()
+}
+
+func (p *WindowFrameProcessor) addRow(state *windowChannelState, frame *data.Frame, row int, t time.Time) {
+	var dimensions []windowDimension
+	for _, f := range frame.Fields {
+		if f.Type() != data.FieldTypeString && f.Type() != data.FieldTypeNullableString {
+			continue
+		}
+		v, ok := f.ConcreteAt(row)
+		if !ok {
+			continue
+		}
+		dimensions = append(dimensions, windowDimension{name: f.Name, value: v.(string)})
+	}
+	groupKey := windowGroupKey(dimensions)
+
+	// All the windows containing t, sliding windows overlap.
+	lastStart := t.Truncate(p.slide)
+	for start := lastStart; start.Add(p.size).After(t); start = start.Add(-p.slide) {
+		if !start.Add(p.size).After(state.watermark) {
+			// Late data for already closed window.
+			break
+		}
+		w, ok := state.windows[start]
+		if !ok {
+			w = &window{start: start, groups: map[string]*windowGroup{}}
+			state.windows[start] = w
+		}
+		g, ok := w.groups[groupKey]
+		if !ok {
+			g = &windowGroup{dimensions: dimensions, aggregates: map[string]*windowAggregate{}}
+			w.groups[groupKey] = g
+			w.order = append(w.order, groupKey)
+		}
+		for _, f := range frame.Fields {
+			if !f.Type().Numeric() || !p.aggregated(f.Name) {
+				continue
+			}
+			v, err := f.NullableFloatAt(row)
+			if err != nil || v == nil || math.IsNaN(*v) {
+				continue
+			}
+			aggKey := f.Name + f.Labels.String()
+			a, ok := g.aggregates[aggKey]
+			if !ok {
+				a = &windowAggregate{name: f.Name, labels: f.Labels}
+				g.aggregates[aggKey] = a
+				g.order = append(g.order, aggKey)
+			}
+			a.add(*v)
+		}
+	}
+}
+
+func (p *WindowFrameProcessor) aggregated(fieldName string) bool {
+	if len(p.config.FieldNames) == 0 {
+		return true
+	}
+	for _, name := range p.config.FieldNames {
+		if name == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+func windowGroupKey(dimensions []windowDimension) string {
+	var sb strings.Builder
+	for _, d := range dimensions {
+		sb.WriteString(d.name)
+		sb.WriteByte('=')
+		sb.WriteString(d.value)
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// windowFlush is a frame of windows closed by time.
+type windowFlush struct {
+	vars  Vars
+	frame *data.Frame
+}
+
+// flushExpired returns all windows of channels with no frame for the idle
+// timeout before now, which is the server time. Channels idle for twice the
+// timeout are forgotten.
+func (p *WindowFrameProcessor) flushExpired(now time.Time) []windowFlush {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var flushed []windowFlush
+	for key, state := range p.channels {
+		idle := now.Sub(state.lastFrameAt)
+		if idle < p.idleTimeout {
+			continue
+		}
+		if len(state.windows) == 0 {
+			if idle >= 2*p.idleTimeout {
+				delete(p.channels, key)
+			}
+			continue
+		}
+		// The last window containing the highest frame time ends after all others.
+		end := state.latest.Truncate(p.slide).Add(p.size)
+		if frame := p.flush(state.name, state, end); frame != nil {
+			flushed = append(flushed, windowFlush{vars: state.vars, frame: frame})
+		}
+	}
+	return flushed
+}
+
+// flush removes windows ended before until and returns them as a frame, nil when
+// no window is complete yet.
+func (p *WindowFrameProcessor) flush(name string, state *windowChannelState, until time.Time) *data.Frame {
+	var closed []*window
+	for start, w := range state.windows {
+		if !start.Add(p.size).After(until) {
+			closed = append(closed, w)
+			delete(state.windows, start)
+		}
+	}
+	if len(closed) == 0 {
+		return nil
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].start.Before(closed[j].start) })
+	state.watermark = closed[len(closed)-1].start.Add(p.size)
+
+	// Collect the schema of the resulting frame, fields appear in order of arrival.
+	var dimensionNames []string
+	dimensionIndex := map[string]int{}
+	var aggregateKeys []string
+	aggregates := map[string]*windowAggregate{}
+	numRows := 0
+	for _, w := range closed {
+		for _, gk := range w.order {
+			g := w.groups[gk]
+			numRows++
+			for _, d := range g.dimensions {
+				if _, ok := dimensionIndex[d.name]; !ok {
+					dimensionIndex[d.name] = len(dimensionNames)
+					dimensionNames = append(dimensionNames, d.name)
+				}
+			}
+			for _, ak := range g.order {
+				if _, ok := aggregates[ak]; !ok {
+					aggregates[ak] = g.aggregates[ak]
+					aggregateKeys = append(aggregateKeys, ak)
+				}
+			}
+		}
+	}
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, numRows)
+	timeField.Name = "time"
+	fields := []*data.Field{timeField}
+	for _, name := range dimensionNames {
+		f := data.NewFieldFromFieldType(data.FieldTypeNullableString, numRows)
+		f.Name = name
+		fields = append(fields, f)
+	}
+	aggregateFields := make(map[string][]*data.Field, len(aggregateKeys))
+	for _, ak := range aggregateKeys {
+		a := aggregates[ak]
+		for _, aggregation := range p.config.Aggregations {
+			f := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, numRows)
+			f.Name = a.name
+			if len(p.config.Aggregations) > 1 {
+				f.Name = a.name + "_" + aggregation
+			}
+			f.Labels = a.labels
+			fields = append(fields, f)
+			aggregateFields[ak] = append(aggregateFields[ak], f)
+		}
+	}
+
+	row := 0
+	for _, w := range closed {
+		for _, gk := range w.order {
+			g := w.groups[gk]
+			// Rows are stamped with the window end.
+			timeField.Set(row, w.start.Add(p.size))
+			for _, d := range g.dimensions {
+				fields[1+dimensionIndex[d.name]].SetConcrete(row, d.value)
+			}
+			for ak, a := range g.aggregates {
+				for i, aggregation := range p.config.Aggregations {
+					aggregateFields[ak][i].SetConcrete(row, a.value(aggregation))
+				}
+			}
+			row++
+		}
+	}
+	return data.NewFrame(name, fields...)
+}
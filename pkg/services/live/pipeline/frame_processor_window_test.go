@@ -0,0 +1,289 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func windowTestFrame(t time.Time, host string, value float64) *data.Frame {
+	return data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{t}),
+		data.NewField("host", nil, []string{host}),
+		data.NewField("value", nil, []float64{value}),
+	)
+}
+
+func TestWindowFrameProcessor_Tumbling(t *testing.T) {
+	p, err := NewWindowFrameProcessor(WindowFrameProcessorConfig{
+		SizeMilliseconds: 10000,
+		Aggregations:     []string{WindowAggregationAvg, WindowAggregationMax, WindowAggregationCount},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	vars := Vars{OrgID: 1, Channel: "stream/test/cpu"}
+	start := time.Date(2021, 01, 01, 12, 12, 10, 0, time.UTC)
+
+	for i, sample := range []struct {
+		host  string
+		value float64
+	}{{"a", 1}, {"b", 10}, {"a", 3}, {"b", 20}} {
+		frame, err := p.ProcessFrame(ctx, vars, windowTestFrame(start.Add(time.Duration(i)*time.Second), sample.host, sample.value))
+		require.NoError(t, err)
+		require.Nil(t, frame, "window is not complete yet")
+	}
+
+	// A frame after the window end closes it.
+	frame, err := p.ProcessFrame(ctx, vars, windowTestFrame(start.Add(11*time.Second), "a", 100))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, "cpu", frame.Name)
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, []string{"time", "host", "value_avg", "value_max", "value_count"}, fieldNames(frame))
+
+	require.Equal(t, start.Add(10*time.Second), frame.Fields[0].At(0))
+	require.Equal(t, "a", *frame.Fields[1].At(0).(*string))
+	require.Equal(t, 2.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, 3.0, *frame.Fields[3].At(0).(*float64))
+	require.Equal(t, 2.0, *frame.Fields[4].At(0).(*float64))
+	require.Equal(t, "b", *frame.Fields[1].At(1).(*string))
+	require.Equal(t, 15.0, *frame.Fields[2].At(1).(*float64))
+
+	// Other channels have their own windows.
+	frame, err = p.ProcessFrame(ctx, Vars{OrgID: 1, Channel: "stream/test/other"}, windowTestFrame(start.Add(30*time.Second), "a", 1))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	// Late frames for closed windows are ignored.
+	frame, err = p.ProcessFrame(ctx, vars, windowTestFrame(start.Add(time.Second), "a", 1000))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+	frame, err = p.ProcessFrame(ctx, vars, windowTestFrame(start.Add(25*time.Second), "a", 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, 100.0, *frame.Fields[3].At(0).(*float64))
+}
+
+func TestWindowFrameProcessor_Sliding(t *testing.T) {
+	p, err := NewWindowFrameProcessor(WindowFrameProcessorConfig{
+		SizeMilliseconds:  10000,
+		SlideMilliseconds: 5000,
+		Aggregations:      []string{WindowAggregationLast},
+		FieldNames:        []string{"value"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	vars := Vars{OrgID: 1, Channel: "stream/test/cpu"}
+	start := time.Date(2021, 01, 01, 12, 12, 10, 0, time.UTC)
+
+	frame := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{start.Add(time.Second), start.Add(6 * time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{1, 2}),
+		data.NewField("other", nil, []float64{1, 2}),
+	)
+	// Windows overlap, each row belongs to two of them: [5s, 15s) is closed by the second row.
+	out, err := p.ProcessFrame(ctx, vars, frame)
+	require.NoError(t, err)
+	require.Equal(t, 1, out.Rows())
+	require.Equal(t, []string{"time", "value"}, fieldNames(out))
+	require.Equal(t, data.Labels{"host": "a"}, out.Fields[1].Labels)
+	require.Equal(t, start.Add(5*time.Second), out.Fields[0].At(0))
+	require.Equal(t, 1.0, *out.Fields[1].At(0).(*float64))
+
+	out, err = p.ProcessFrame(ctx, vars, data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{start.Add(26 * time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{3}),
+	))
+	require.NoError(t, err)
+	// Windows [10s, 20s) and [15s, 25s) are closed.
+	require.Equal(t, 2, out.Rows())
+	require.Equal(t, start.Add(10*time.Second), out.Fields[0].At(0))
+	require.Equal(t, 2.0, *out.Fields[1].At(0).(*float64))
+	require.Equal(t, start.Add(15*time.Second), out.Fields[0].At(1))
+	require.Equal(t, 2.0, *out.Fields[1].At(1).(*float64))
+}
+
+func TestNewWindowFrameProcessor_invalid(t *testing.T) {
+	_, err := NewWindowFrameProcessor(WindowFrameProcessorConfig{Aggregations: []string{WindowAggregationAvg}})
+	require.Error(t, err)
+	_, err = NewWindowFrameProcessor(WindowFrameProcessorConfig{SizeMilliseconds: 1000, SlideMilliseconds: 2000, Aggregations: []string{WindowAggregationAvg}})
+	require.Error(t, err)
+	_, err = NewWindowFrameProcessor(WindowFrameProcessorConfig{SizeMilliseconds: 1000})
+	require.Error(t, err)
+	_, err = NewWindowFrameProcessor(WindowFrameProcessorConfig{SizeMilliseconds: 1000, Aggregations: []string{"median"}})
+	require.Error(t, err)
+	_, err = NewWindowFrameProcessor(WindowFrameProcessorConfig{SizeMilliseconds: 1000, AllowedLatenessMilliseconds: -1, Aggregations: []string{WindowAggregationAvg}})
+	require.Error(t, err)
+	_, err = NewWindowFrameProcessor(WindowFrameProcessorConfig{SizeMilliseconds: 1000, IdleTimeoutMilliseconds: -1, Aggregations: []string{WindowAggregationAvg}})
+	require.Error(t, err)
+}
+
+type windowRuleStorage struct {
+	Storage
+	rules []ChannelRule
+}
+
+func (s *windowRuleStorage) ListChannelRules(context.Context, int64) ([]ChannelRule, error) {
+	return s.rules, nil
+}
+
+func (s *windowRuleStorage) ListWriteConfigs(context.Context, int64) ([]WriteConfig, error) {
+	return nil, nil
+}
+
+func windowRule(sizeMilliseconds int64) ChannelRule {
+	return ChannelRule{
+		OrgId:   1,
+		Pattern: "stream/test/cpu",
+		Settings: ChannelRuleSettings{
+			FrameProcessors: []*FrameProcessorConfig{{
+				Type: FrameProcessorTypeWindow,
+				WindowProcessorConfig: &WindowFrameProcessorConfig{
+					SizeMilliseconds: sizeMilliseconds,
+					Aggregations:     []string{WindowAggregationCount},
+				},
+			}},
+		},
+	}
+}
+
+func TestWindowFrameProcessor_RulesRebuilt(t *testing.T) {
+	storage := &windowRuleStorage{rules: []ChannelRule{windowRule(10000)}}
+	builder := &StorageRuleBuilder{Storage: storage}
+	ctx := context.Background()
+	vars := Vars{OrgID: 1, Channel: "stream/test/cpu"}
+	start := time.Date(2021, 01, 01, 12, 12, 10, 0, time.UTC)
+
+	rules, err := builder.BuildRules(ctx, 1)
+	require.NoError(t, err)
+	frame, err := rules[0].FrameProcessors[0].ProcessFrame(ctx, vars, windowTestFrame(start, "a", 1))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	// Rules are reloaded in the middle of the window.
+	rules, err = builder.BuildRules(ctx, 1)
+	require.NoError(t, err)
+	frame, err = rules[0].FrameProcessors[0].ProcessFrame(ctx, vars, windowTestFrame(start.Add(time.Second), "a", 2))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+	frame, err = rules[0].FrameProcessors[0].ProcessFrame(ctx, vars, windowTestFrame(start.Add(11*time.Second), "a", 3))
+	require.NoError(t, err)
+	require.Equal(t, 2.0, *frame.Fields[2].At(0).(*float64), "window keeps frames received before the reload")
+
+	// A changed window starts over.
+	storage.rules = []ChannelRule{windowRule(20000)}
+	rules, err = builder.BuildRules(ctx, 1)
+	require.NoError(t, err)
+	frame, err = rules[0].FrameProcessors[0].ProcessFrame(ctx, vars, windowTestFrame(start.Add(41*time.Second), "a", 4))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+}
+
+type windowRuleGetter struct {
+	testRuleGetter
+}
+
+func (g *windowRuleGetter) ListRules() []*LiveChannelRule {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	rules := make([]*LiveChannelRule, 0, len(g.rules))
+	for _, rule := range g.rules {
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func TestPipeline_flushWindows(t *testing.T) {
+	window, err := NewWindowFrameProcessor(WindowFrameProcessorConfig{
+		SizeMilliseconds: 10000,
+		Aggregations:     []string{WindowAggregationCount},
+	})
+	require.NoError(t, err)
+	// Frame times lag the server clock by an hour.
+	start := time.Date(2021, 01, 01, 12, 12, 10, 0, time.UTC)
+	now := start.Add(time.Hour)
+	window.nowTimeFunc = func() time.Time { return now }
+	out := &testOutputter{}
+	getter := &windowRuleGetter{testRuleGetter{rules: map[string]*LiveChannelRule{
+		"stream/test/cpu": {
+			OrgId:           1,
+			Pattern:         "stream/test/cpu",
+			FrameProcessors: []FrameProcessor{window, &testProcessor{}},
+			FrameOutputters: []FrameOutputter{out},
+		},
+	}}}
+	p, err := New(getter)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	frames, err := p.processFrame(ctx, 1, "stream/test/cpu", windowTestFrame(start, "a", 1))
+	require.NoError(t, err)
+	require.Empty(t, frames)
+
+	// The window ended long ago by the server clock, but the channel is not idle yet.
+	p.flushWindows(ctx, getter.ListRules(), now.Add(5*time.Second))
+	require.Nil(t, out.frame)
+	now = now.Add(5 * time.Second)
+	frames, err = p.processFrame(ctx, 1, "stream/test/cpu", windowTestFrame(start.Add(time.Second), "a", 2))
+	require.NoError(t, err)
+	require.Empty(t, frames)
+	p.flushWindows(ctx, getter.ListRules(), now.Add(5*time.Second))
+	require.Nil(t, out.frame)
+
+	// No frame arrives for the idle timeout, the window is closed.
+	p.flushWindows(ctx, getter.ListRules(), now.Add(10*time.Second))
+	require.NotNil(t, out.frame)
+	require.Equal(t, "cpu", out.frame.Name)
+	require.Equal(t, 2.0, *out.frame.Fields[2].At(0).(*float64))
+	require.Equal(t, start.Add(10*time.Second), out.frame.Fields[0].At(0))
+
+	// Closed windows are not flushed twice.
+	out.frame = nil
+	p.flushWindows(ctx, getter.ListRules(), now.Add(20*time.Second))
+	require.Nil(t, out.frame)
+}
+
+func TestWindowFrameProcessor_AllowedLateness(t *testing.T) {
+	p, err := NewWindowFrameProcessor(WindowFrameProcessorConfig{
+		SizeMilliseconds:            10000,
+		AllowedLatenessMilliseconds: 5000,
+		Aggregations:                []string{WindowAggregationCount},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	vars := Vars{OrgID: 1, Channel: "stream/test/cpu"}
+	start := time.Date(2021, 01, 01, 12, 12, 10, 0, time.UTC)
+
+	frame, err := p.ProcessFrame(ctx, vars, windowTestFrame(start, "a", 1))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+	frame, err = p.ProcessFrame(ctx, vars, windowTestFrame(start.Add(12*time.Second), "a", 1))
+	require.NoError(t, err)
+	require.Nil(t, frame, "window is kept open for the allowed lateness")
+
+	// Out of order frame is still counted.
+	frame, err = p.ProcessFrame(ctx, vars, windowTestFrame(start.Add(9*time.Second), "a", 1))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	frame, err = p.ProcessFrame(ctx, vars, windowTestFrame(start.Add(15*time.Second), "a", 1))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start.Add(10*time.Second), frame.Fields[0].At(0))
+	require.Equal(t, 2.0, *frame.Fields[2].At(0).(*float64))
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
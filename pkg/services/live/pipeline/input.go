@@ -0,0 +1,205 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// InputSubscriber subscribes to an external message broker. Subscribe blocks
+// until ctx is done or the connection to the broker is lost, calling handler
+// for every received message.
+type InputSubscriber interface {
+	Type() string
+	Subscribe(ctx context.Context, handler InputHandler) error
+}
+
+// InputHandler receives messages from InputSubscriber.
+type InputHandler func(ctx context.Context, topic string, payload []byte)
+
+// InputProcessor processes messages received by inputs, implemented by Pipeline.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+const (
+	defaultInputSyncInterval = 10 * time.Second
+	inputRetryInterval       = 5 * time.Second
+)
+
+// InputRunner keeps subscriptions of configured inputs running and routes
+// received messages through the pipeline. Input configs are reloaded
+// periodically, changed inputs are restarted.
+type InputRunner struct {
+	lister         InputConfigLister
+	processor      InputProcessor
+	secretsService secrets.Service
+	newSubscriber  func(settings InputSettings, secureSettings map[string]string) (InputSubscriber, error)
+	syncInterval   time.Duration
+	retryInterval  time.Duration
+
+	mu      sync.Mutex
+	running map[string]*runningInput
+}
+
+type runningInput struct {
+	config InputConfig
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewInputRunner creates new InputRunner.
+func NewInputRunner(lister InputConfigLister, processor InputProcessor, secretsService secrets.Service) *InputRunner {
+	return &InputRunner{
+		lister:         lister,
+		processor:      processor,
+		secretsService: secretsService,
+		newSubscriber:  newInputSubscriber,
+		syncInterval:   defaultInputSyncInterval,
+		retryInterval:  inputRetryInterval,
+		running:        map[string]*runningInput{},
+	}
+}
+
+func newInputSubscriber(settings InputSettings, secureSettings map[string]string) (InputSubscriber, error) {
+	switch settings.Type {
+	case InputTypeMQTT:
+		return NewMQTTInput(settings, secureSettings), nil
+	case InputTypeNATS:
+		return NewNATSInput(settings, secureSettings), nil
+	default:
+		return nil, fmt.Errorf("unknown input type: %s", settings.Type)
+	}
+}
+
+// Run blocks until ctx is done.
+func (r *InputRunner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.syncInterval)
+	defer ticker.Stop()
+	defer r.stopAll()
+
+	for {
+		if err := r.sync(ctx); err != nil {
+			logger.Error("Error loading input configs", "error", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *InputRunner) sync(ctx context.Context) error {
+	configs, err := r.lister.ListAllInputConfigs(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		key := fmt.Sprintf("%d/%s", config.OrgId, config.UID)
+		seen[key] = struct{}{}
+		if existing, ok := r.running[key]; ok {
+			if reflect.DeepEqual(existing.config, config) {
+				continue
+			}
+			existing.stop()
+		}
+		if ok, reason := config.Valid(); !ok {
+			logger.Error("Invalid input config", "orgId", config.OrgId, "uid", config.UID, "reason", reason)
+			delete(r.running, key)
+			continue
+		}
+		r.running[key] = r.start(ctx, config)
+	}
+	for key, input := range r.running {
+		if _, ok := seen[key]; !ok {
+			input.stop()
+			delete(r.running, key)
+		}
+	}
+	return nil
+}
+
+func (r *InputRunner) stopAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, input := range r.running {
+		input.stop()
+		delete(r.running, key)
+	}
+}
+
+func (i *runningInput) stop() {
+	i.cancel()
+	<-i.done
+}
+
+func (r *InputRunner) start(ctx context.Context, config InputConfig) *runningInput {
+	ctx, cancel := context.WithCancel(ctx)
+	input := &runningInput{config: config, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(input.done)
+		for {
+			err := r.subscribe(ctx, config)
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error("Input subscription failed", "orgId", config.OrgId, "uid", config.UID, "type", config.Settings.Type, "error", err)
+			select {
+			case <-time.After(r.retryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return input
+}
+
+func (r *InputRunner) subscribe(ctx context.Context, config InputConfig) error {
+	secureSettings, err := r.secretsService.DecryptJsonData(ctx, config.SecureSettings)
+	if err != nil {
+		return fmt.Errorf("error decrypting secure settings: %w", err)
+	}
+	subscriber, err := r.newSubscriber(config.Settings, secureSettings)
+	if err != nil {
+		return err
+	}
+	logger.Debug("Starting input", "orgId", config.OrgId, "uid", config.UID, "type", subscriber.Type())
+	return subscriber.Subscribe(ctx, func(ctx context.Context, topic string, payload []byte) {
+		channel := inputChannel(config.Settings, topic)
+		ok, err := r.processor.ProcessInput(ctx, config.OrgId, channel, payload)
+		if err != nil {
+			logger.Error("Error processing input message", "uid", config.UID, "channel", channel, "error", err)
+			return
+		}
+		if !ok {
+			logger.Debug("No channel rule for input message", "uid", config.UID, "channel", channel)
+		}
+	})
+}
+
+var invalidChannelPathChars = regexp.MustCompile(`[^A-Za-z0-9_\-/=.]`)
+
+// inputChannel returns the channel a message is published to. Topic characters
+// not allowed in channel path are replaced with underscores.
+func inputChannel(settings InputSettings, topic string) string {
+	if !settings.TopicPath {
+		return settings.Channel
+	}
+	path := strings.Trim(invalidChannelPathChars.ReplaceAllString(topic, "_"), "/")
+	if path == "" {
+		return settings.Channel
+	}
+	return settings.Channel + "/" + path
+}
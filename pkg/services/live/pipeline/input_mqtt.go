@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/at-wat/mqtt-go"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const InputTypeMQTT = "mqtt"
+
+const (
+	mqttKeepAlive   = 30 * time.Second
+	mqttPingTimeout = 10 * time.Second
+)
+
+// MQTTInput subscribes to MQTT topics.
+type MQTTInput struct {
+	settings InputSettings
+	password string
+	dial     func(ctx context.Context, url string) (mqtt.ClientCloser, error)
+}
+
+// NewMQTTInput creates new MQTTInput. The password is taken from "password"
+// secure setting.
+func NewMQTTInput(settings InputSettings, secureSettings map[string]string) *MQTTInput {
+	return &MQTTInput{settings: settings, password: secureSettings["password"], dial: dialMQTT}
+}
+
+func dialMQTT(ctx context.Context, url string) (mqtt.ClientCloser, error) {
+	return mqtt.DialContext(ctx, url)
+}
+
+func (i *MQTTInput) Type() string {
+	return InputTypeMQTT
+}
+
+func (i *MQTTInput) Subscribe(ctx context.Context, handler InputHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cli, err := i.dial(ctx, i.settings.URL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = cli.Close() }()
+
+	clientID := i.settings.ClientID
+	if clientID == "" {
+		clientID = "grafana-live-" + util.GenerateShortUID()
+	}
+	opts := []mqtt.ConnectOption{mqtt.WithKeepAlive(uint16(mqttKeepAlive.Seconds()))}
+	if i.settings.User != "" {
+		opts = append(opts, mqtt.WithUserNamePassword(i.settings.User, i.password))
+	}
+	if _, err := cli.Connect(ctx, clientID, opts...); err != nil {
+		return err
+	}
+
+	cli.Handle(mqtt.HandlerFunc(func(msg *mqtt.Message) {
+		handler(ctx, msg.Topic, msg.Payload)
+	}))
+	subs := make([]mqtt.Subscription, 0, len(i.settings.Topics))
+	for _, topic := range i.settings.Topics {
+		subs = append(subs, mqtt.Subscription{Topic: topic, QoS: mqtt.QoS1})
+	}
+	if _, err := cli.Subscribe(ctx, subs...); err != nil {
+		return err
+	}
+
+	go func() {
+		// Close the connection when the broker stops responding, Subscribe returns then.
+		if err := mqtt.KeepAlive(ctx, cli, mqttKeepAlive/2, mqttPingTimeout); err != nil {
+			_ = cli.Close()
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = cli.Disconnect(context.Background())
+		return ctx.Err()
+	case <-cli.Done():
+		return cli.Err()
+	}
+}
@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/at-wat/mqtt-go"
+	mockmqtt "github.com/at-wat/mqtt-go/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+// fakeMQTTClient is a connection to a fake broker, it is closed with drop.
+type fakeMQTTClient struct {
+	mockmqtt.Client
+
+	clientID   string
+	options    mqtt.ConnectOptions
+	subscribed chan []mqtt.Subscription
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func newFakeMQTTClient() *fakeMQTTClient {
+	c := &fakeMQTTClient{subscribed: make(chan []mqtt.Subscription, 1), done: make(chan struct{})}
+	c.ConnectFn = func(_ context.Context, clientID string, opts ...mqtt.ConnectOption) (bool, error) {
+		c.clientID = clientID
+		for _, opt := range opts {
+			if err := opt(&c.options); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+	c.SubscribeFn = func(_ context.Context, subs ...mqtt.Subscription) ([]mqtt.Subscription, error) {
+		c.subscribed <- subs
+		return subs, nil
+	}
+	return c
+}
+
+func (c *fakeMQTTClient) drop(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
+
+func (c *fakeMQTTClient) Close() error {
+	c.drop(nil)
+	return nil
+}
+
+func (c *fakeMQTTClient) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *fakeMQTTClient) Err() error {
+	return c.err
+}
+
+func waitMQTTSubscribe(t *testing.T, c *fakeMQTTClient) []mqtt.Subscription {
+	t.Helper()
+	select {
+	case subs := <-c.subscribed:
+		return subs
+	case <-time.After(time.Second):
+		require.FailNow(t, "client did not subscribe")
+		return nil
+	}
+}
+
+func nextMQTTClient(t *testing.T, clients <-chan *fakeMQTTClient) *fakeMQTTClient {
+	t.Helper()
+	select {
+	case c := <-clients:
+		return c
+	case <-time.After(time.Second):
+		require.FailNow(t, "client did not connect")
+		return nil
+	}
+}
+
+func TestMQTTInput_Subscribe(t *testing.T) {
+	settings := InputSettings{
+		Type:     InputTypeMQTT,
+		URL:      "tcp://localhost:1883",
+		Topics:   []string{"sensors/#", "alerts"},
+		Channel:  "stream/sensors",
+		ClientID: "grafana",
+		User:     "user",
+	}
+
+	t.Run("forwards messages of subscribed topics", func(t *testing.T) {
+		client := newFakeMQTTClient()
+		input := NewMQTTInput(settings, map[string]string{"password": "secret"})
+		input.dial = func(_ context.Context, url string) (mqtt.ClientCloser, error) {
+			require.Equal(t, "tcp://localhost:1883", url)
+			return client, nil
+		}
+
+		received := make(chan testBrokerMessage, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- input.Subscribe(ctx, func(_ context.Context, topic string, payload []byte) {
+				received <- testBrokerMessage{topic: topic, payload: payload}
+			})
+		}()
+
+		subs := waitMQTTSubscribe(t, client)
+		require.Equal(t, []mqtt.Subscription{{Topic: "sensors/#", QoS: mqtt.QoS1}, {Topic: "alerts", QoS: mqtt.QoS1}}, subs)
+		require.Equal(t, "grafana", client.clientID)
+		require.Equal(t, "user", client.options.UserName)
+		require.Equal(t, "secret", client.options.Password)
+
+		client.Serve(&mqtt.Message{Topic: "sensors/kitchen", Payload: []byte("t=21.5")})
+		require.Equal(t, testBrokerMessage{topic: "sensors/kitchen", payload: []byte("t=21.5")}, <-received)
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("returns when the connection is lost", func(t *testing.T) {
+		client := newFakeMQTTClient()
+		input := NewMQTTInput(settings, nil)
+		input.dial = func(context.Context, string) (mqtt.ClientCloser, error) {
+			return client, nil
+		}
+
+		done := make(chan error)
+		go func() {
+			done <- input.Subscribe(context.Background(), func(context.Context, string, []byte) {})
+		}()
+		waitMQTTSubscribe(t, client)
+
+		client.drop(errors.New("connection reset"))
+		require.EqualError(t, <-done, "connection reset")
+	})
+}
+
+func TestInputRunner_MQTTReconnect(t *testing.T) {
+	clients := make(chan *fakeMQTTClient, 2)
+	processor := &testInputProcessor{input: map[string][]string{}}
+	runner := NewInputRunner(&staticInputConfigLister{configs: []InputConfig{{
+		OrgId: 1,
+		UID:   "sensors",
+		Settings: InputSettings{
+			Type:    InputTypeMQTT,
+			URL:     "tcp://localhost:1883",
+			Topics:  []string{"sensors/#"},
+			Channel: "stream/sensors/all",
+		},
+	}}}, processor, fakes.NewFakeSecretsService())
+	runner.retryInterval = time.Millisecond
+	runner.newSubscriber = func(settings InputSettings, secureSettings map[string]string) (InputSubscriber, error) {
+		input := NewMQTTInput(settings, secureSettings)
+		input.dial = func(context.Context, string) (mqtt.ClientCloser, error) {
+			client := newFakeMQTTClient()
+			clients <- client
+			return client, nil
+		}
+		return input, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- runner.Run(ctx) }()
+
+	first := nextMQTTClient(t, clients)
+	waitMQTTSubscribe(t, first)
+	first.Serve(&mqtt.Message{Topic: "sensors/kitchen", Payload: []byte("1")})
+	first.drop(errors.New("connection reset"))
+
+	second := nextMQTTClient(t, clients)
+	waitMQTTSubscribe(t, second)
+	second.Serve(&mqtt.Message{Topic: "sensors/kitchen", Payload: []byte("2")})
+	require.Equal(t, []string{"1", "2"}, processor.received("stream/sensors/all"))
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+type staticInputConfigLister struct {
+	configs []InputConfig
+}
+
+func (l *staticInputConfigLister) ListAllInputConfigs(context.Context) ([]InputConfig, error) {
+	return l.configs, nil
+}
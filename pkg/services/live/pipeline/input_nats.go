@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nats-io/nats.go"
+)
+
+const InputTypeNATS = "nats"
+
+// NATSInput subscribes to NATS subjects.
+type NATSInput struct {
+	settings InputSettings
+	password string
+	token    string
+	connect  func(url string, opts ...nats.Option) (natsConn, error)
+}
+
+// natsConn is the part of *nats.Conn used by NATSInput.
+type natsConn interface {
+	Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error)
+	LastError() error
+	Close()
+}
+
+// NewNATSInput creates new NATSInput. Credentials are taken from "password"
+// or "token" secure settings.
+func NewNATSInput(settings InputSettings, secureSettings map[string]string) *NATSInput {
+	return &NATSInput{settings: settings, password: secureSettings["password"], token: secureSettings["token"], connect: connectNATS}
+}
+
+func connectNATS(url string, opts ...nats.Option) (natsConn, error) {
+	conn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (i *NATSInput) Type() string {
+	return InputTypeNATS
+}
+
+func (i *NATSInput) Subscribe(ctx context.Context, handler InputHandler) error {
+	closed := make(chan struct{})
+	opts := []nats.Option{
+		nats.Name(i.settings.ClientID),
+		nats.ClosedHandler(func(*nats.Conn) { close(closed) }),
+	}
+	if i.settings.User != "" {
+		opts = append(opts, nats.UserInfo(i.settings.User, i.password))
+	}
+	if i.token != "" {
+		opts = append(opts, nats.Token(i.token))
+	}
+	conn, err := i.connect(i.settings.URL, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, subject := range i.settings.Topics {
+		_, err := conn.Subscribe(subject, func(msg *nats.Msg) {
+			handler(ctx, msg.Subject, msg.Data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-closed:
+		// Closed after reconnect attempts are exhausted.
+		if err := conn.LastError(); err != nil {
+			return err
+		}
+		return errors.New("connection closed")
+	}
+}
@@ -0,0 +1,171 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNATSConn is a connection to a fake server, it is closed with drop.
+type fakeNATSConn struct {
+	options    nats.Options
+	subscribed chan string
+
+	mu       sync.Mutex
+	handlers map[string]nats.MsgHandler
+	closed   bool
+	err      error
+}
+
+func newFakeNATSConn() *fakeNATSConn {
+	return &fakeNATSConn{subscribed: make(chan string, 10), handlers: map[string]nats.MsgHandler{}}
+}
+
+func (c *fakeNATSConn) connect(_ string, opts ...nats.Option) (natsConn, error) {
+	for _, opt := range opts {
+		if err := opt(&c.options); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *fakeNATSConn) Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	c.mu.Lock()
+	c.handlers[subject] = cb
+	c.mu.Unlock()
+	c.subscribed <- subject
+	return &nats.Subscription{Subject: subject}, nil
+}
+
+// publish delivers a message to the handler of the subscribed subject.
+func (c *fakeNATSConn) publish(subscription, subject string, data []byte) {
+	c.mu.Lock()
+	cb := c.handlers[subscription]
+	c.mu.Unlock()
+	cb(&nats.Msg{Subject: subject, Data: data})
+}
+
+// drop closes the connection like the client does when reconnect attempts are exhausted.
+func (c *fakeNATSConn) drop(err error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	c.err = err
+	c.mu.Unlock()
+	if c.options.ClosedCB != nil {
+		c.options.ClosedCB(nil)
+	}
+}
+
+func (c *fakeNATSConn) LastError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *fakeNATSConn) Close() {
+	c.drop(nil)
+}
+
+func waitNATSSubscribe(t *testing.T, c *fakeNATSConn, n int) []string {
+	t.Helper()
+	subjects := make([]string, 0, n)
+	for len(subjects) < n {
+		select {
+		case subject := <-c.subscribed:
+			subjects = append(subjects, subject)
+		case <-time.After(time.Second):
+			require.FailNow(t, "client did not subscribe")
+		}
+	}
+	return subjects
+}
+
+func TestNATSInput_Subscribe(t *testing.T) {
+	t.Run("forwards messages of subscribed subjects", func(t *testing.T) {
+		conn := newFakeNATSConn()
+		input := NewNATSInput(InputSettings{
+			Type:     InputTypeNATS,
+			URL:      "nats://localhost:4222",
+			Topics:   []string{"sensors.>", "alerts"},
+			Channel:  "stream/sensors",
+			ClientID: "grafana",
+			User:     "user",
+		}, map[string]string{"password": "secret"})
+		input.connect = func(url string, opts ...nats.Option) (natsConn, error) {
+			require.Equal(t, "nats://localhost:4222", url)
+			return conn.connect(url, opts...)
+		}
+
+		received := make(chan testBrokerMessage, 2)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- input.Subscribe(ctx, func(_ context.Context, topic string, payload []byte) {
+				received <- testBrokerMessage{topic: topic, payload: payload}
+			})
+		}()
+
+		require.Equal(t, []string{"sensors.>", "alerts"}, waitNATSSubscribe(t, conn, 2))
+		require.Equal(t, "grafana", conn.options.Name)
+		require.Equal(t, "user", conn.options.User)
+		require.Equal(t, "secret", conn.options.Password)
+
+		conn.publish("sensors.>", "sensors.kitchen", []byte("t=21.5"))
+		conn.publish("alerts", "alerts", []byte("fire"))
+		require.Equal(t, testBrokerMessage{topic: "sensors.kitchen", payload: []byte("t=21.5")}, <-received)
+		require.Equal(t, testBrokerMessage{topic: "alerts", payload: []byte("fire")}, <-received)
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("passes the token", func(t *testing.T) {
+		conn := newFakeNATSConn()
+		input := NewNATSInput(InputSettings{Topics: []string{"sensors.>"}}, map[string]string{"token": "abc"})
+		input.connect = conn.connect
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- input.Subscribe(ctx, func(context.Context, string, []byte) {})
+		}()
+		waitNATSSubscribe(t, conn, 1)
+		require.Equal(t, "abc", conn.options.Token)
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("returns the connection error", func(t *testing.T) {
+		input := NewNATSInput(InputSettings{Topics: []string{"sensors.>"}}, map[string]string{"token": "wrong"})
+		input.connect = func(string, ...nats.Option) (natsConn, error) {
+			return nil, nats.ErrAuthorization
+		}
+		require.ErrorIs(t, input.Subscribe(context.Background(), func(context.Context, string, []byte) {}), nats.ErrAuthorization)
+	})
+
+	t.Run("returns when the connection is closed", func(t *testing.T) {
+		conn := newFakeNATSConn()
+		input := NewNATSInput(InputSettings{Topics: []string{"sensors.>"}}, nil)
+		input.connect = conn.connect
+
+		done := make(chan error)
+		go func() {
+			done <- input.Subscribe(context.Background(), func(context.Context, string, []byte) {})
+		}()
+		waitNATSSubscribe(t, conn, 1)
+
+		conn.drop(errors.New("connection reset"))
+		require.EqualError(t, <-done, "connection reset")
+	})
+}
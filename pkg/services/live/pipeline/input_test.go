@@ -0,0 +1,203 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+type testBrokerMessage struct {
+	topic   string
+	payload []byte
+}
+
+// testBroker is a local broker stand-in, messages are delivered to all subscribers.
+type testBroker struct {
+	mu          sync.Mutex
+	subscribers map[*testBrokerSubscriber]struct{}
+	credentials []string
+}
+
+func newTestBroker() *testBroker {
+	return &testBroker{subscribers: map[*testBrokerSubscriber]struct{}{}}
+}
+
+func (b *testBroker) publish(topic string, payload []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers {
+		s.messages <- testBrokerMessage{topic: topic, payload: payload}
+	}
+}
+
+func (b *testBroker) connections() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.credentials...)
+}
+
+func (b *testBroker) numSubscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+type testBrokerSubscriber struct {
+	broker   *testBroker
+	messages chan testBrokerMessage
+}
+
+func (s *testBrokerSubscriber) Type() string {
+	return "test"
+}
+
+func (s *testBrokerSubscriber) Subscribe(ctx context.Context, handler InputHandler) error {
+	s.broker.mu.Lock()
+	s.broker.subscribers[s] = struct{}{}
+	s.broker.mu.Unlock()
+	defer func() {
+		s.broker.mu.Lock()
+		delete(s.broker.subscribers, s)
+		s.broker.mu.Unlock()
+	}()
+	for {
+		select {
+		case msg := <-s.messages:
+			handler(ctx, msg.topic, msg.payload)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+type testInputProcessor struct {
+	mu    sync.Mutex
+	input map[string][]string
+}
+
+func (p *testInputProcessor) ProcessInput(_ context.Context, orgID int64, channelID string, body []byte) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.input[channelID] = append(p.input[channelID], string(body))
+	return true, nil
+}
+
+func (p *testInputProcessor) received(channelID string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.input[channelID]
+}
+
+func TestInputRunner(t *testing.T) {
+	dataPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataPath, "pipeline"), 0750))
+	storage := &FileStorage{DataPath: dataPath, SecretsService: fakes.NewFakeSecretsService()}
+
+	ctx := context.Background()
+	_, err := storage.CreateInputConfig(ctx, 1, InputConfigCreateCmd{
+		UID: "sensors",
+		Settings: InputSettings{
+			Type:      InputTypeMQTT,
+			URL:       "tcp://localhost:1883",
+			Topics:    []string{"sensors/#"},
+			Channel:   "stream/sensors",
+			TopicPath: true,
+			User:      "grafana",
+		},
+		SecureSettings: map[string]string{"password": "secret"},
+	})
+	require.NoError(t, err)
+
+	broker := newTestBroker()
+	processor := &testInputProcessor{input: map[string][]string{}}
+	runner := NewInputRunner(storage, processor, fakes.NewFakeSecretsService())
+	runner.syncInterval = 10 * time.Millisecond
+	runner.newSubscriber = func(settings InputSettings, secureSettings map[string]string) (InputSubscriber, error) {
+		broker.mu.Lock()
+		broker.credentials = append(broker.credentials, settings.User+":"+secureSettings["password"])
+		broker.mu.Unlock()
+		return &testBrokerSubscriber{broker: broker, messages: make(chan testBrokerMessage, 10)}, nil
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- runner.Run(runCtx) }()
+
+	require.Eventually(t, func() bool { return broker.numSubscribers() == 1 }, time.Second, 5*time.Millisecond)
+	broker.publish("sensors/kitchen temp", []byte("t=21.5"))
+	require.Eventually(t, func() bool {
+		return len(processor.received("stream/sensors/sensors/kitchen_temp")) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, []string{"grafana:secret"}, broker.connections())
+
+	t.Run("changed input is restarted", func(t *testing.T) {
+		_, err := storage.UpdateInputConfig(ctx, 1, InputConfigUpdateCmd{
+			UID: "sensors",
+			Settings: InputSettings{
+				Type:    InputTypeMQTT,
+				URL:     "tcp://localhost:1883",
+				Topics:  []string{"sensors/#"},
+				Channel: "stream/sensors/all",
+			},
+		})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(broker.connections()) == 2 }, time.Second, 5*time.Millisecond)
+		require.Eventually(t, func() bool { return broker.numSubscribers() == 1 }, time.Second, 5*time.Millisecond)
+
+		broker.publish("sensors/garage", []byte("t=12"))
+		require.Eventually(t, func() bool {
+			return len(processor.received("stream/sensors/all")) == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("deleted input is stopped", func(t *testing.T) {
+		require.NoError(t, storage.DeleteInputConfig(ctx, 1, InputConfigDeleteCmd{UID: "sensors"}))
+		require.Eventually(t, func() bool { return broker.numSubscribers() == 0 }, time.Second, 5*time.Millisecond)
+	})
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestInputConfig_Valid(t *testing.T) {
+	valid := InputConfig{
+		UID: "test",
+		Settings: InputSettings{
+			Type:    InputTypeNATS,
+			URL:     "nats://localhost:4222",
+			Topics:  []string{"sensors.>"},
+			Channel: "stream/sensors/all",
+		},
+	}
+	ok, _ := valid.Valid()
+	require.True(t, ok)
+
+	topicPath := valid
+	topicPath.Settings.Channel = "stream/sensors"
+	topicPath.Settings.TopicPath = true
+	ok, _ = topicPath.Valid()
+	require.True(t, ok)
+
+	for name, modify := range map[string]func(c *InputConfig){
+		"uid required":         func(c *InputConfig) { c.UID = "" },
+		"unknown input type":   func(c *InputConfig) { c.Settings.Type = "kafka" },
+		"url required":         func(c *InputConfig) { c.Settings.URL = "" },
+		"topic required":       func(c *InputConfig) { c.Settings.Topics = nil },
+		"invalid channel":      func(c *InputConfig) { c.Settings.Channel = "stream" },
+		"channel not a stream": func(c *InputConfig) { c.Settings.Channel = "grafana/dashboard/uid" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := valid
+			modify(&c)
+			ok, _ := c.Valid()
+			require.False(t, ok)
+		})
+	}
+}
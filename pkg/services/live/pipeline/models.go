@@ -3,6 +3,8 @@ package pipeline
 import (
 	"fmt"
 
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
 	"github.com/grafana/grafana/pkg/services/live/pipeline/pattern"
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
@@ -125,6 +127,105 @@ type WriteConfigs struct {
 	Configs []WriteConfig `json:"writeConfigs"`
 }
 
+func InputConfigToDto(b InputConfig) InputConfigDto {
+	secureFields := make(map[string]bool, len(b.SecureSettings))
+	for k := range b.SecureSettings {
+		secureFields[k] = true
+	}
+	return InputConfigDto{
+		UID:          b.UID,
+		Settings:     b.Settings,
+		SecureFields: secureFields,
+	}
+}
+
+type InputConfigDto struct {
+	UID          string          `json:"uid"`
+	Settings     InputSettings   `json:"settings"`
+	SecureFields map[string]bool `json:"secureFields"`
+}
+
+type InputConfigGetCmd struct {
+	UID string `json:"uid"`
+}
+
+type InputConfigCreateCmd struct {
+	UID            string            `json:"uid"`
+	Settings       InputSettings     `json:"settings"`
+	SecureSettings map[string]string `json:"secureSettings"`
+}
+
+type InputConfigUpdateCmd struct {
+	UID            string            `json:"uid"`
+	Settings       InputSettings     `json:"settings"`
+	SecureSettings map[string]string `json:"secureSettings"`
+}
+
+type InputConfigDeleteCmd struct {
+	UID string `json:"uid"`
+}
+
+// InputConfig describes a subscription to an external message broker.
+// Unlike write configs, the organization is kept in the file since inputs
+// are started for all organizations on startup.
+type InputConfig struct {
+	OrgId          int64             `json:"orgId,omitempty"`
+	UID            string            `json:"uid"`
+	Settings       InputSettings     `json:"settings"`
+	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
+}
+
+func (r InputConfig) Valid() (bool, string) {
+	if r.UID == "" {
+		return false, "uid required"
+	}
+	if !typeRegistered(r.Settings.Type, InputsRegistry) {
+		return false, fmt.Sprintf("unknown input type: %s", r.Settings.Type)
+	}
+	if r.Settings.URL == "" {
+		return false, "url required"
+	}
+	if len(r.Settings.Topics) == 0 {
+		return false, "at least one topic required"
+	}
+	channel := r.Settings.Channel
+	if r.Settings.TopicPath {
+		// The path is taken from the topic, so stream namespace is enough.
+		channel += "/topic"
+	}
+	ch, err := live.ParseChannel(channel)
+	if err != nil {
+		return false, fmt.Sprintf("invalid channel: %s", r.Settings.Channel)
+	}
+	if ch.Scope != live.ScopeStream {
+		return false, "channel must be in stream scope"
+	}
+	return true, ""
+}
+
+type InputSettings struct {
+	// Type of the broker, mqtt or nats.
+	Type string `json:"type"`
+	// URL of the broker, like tcp://localhost:1883 or nats://localhost:4222.
+	URL string `json:"url"`
+	// Topics are MQTT topic filters or NATS subjects to subscribe to, wildcards are allowed.
+	Topics []string `json:"topics"`
+	// Channel is a stream/... channel messages are published to.
+	Channel string `json:"channel"`
+	// TopicPath appends the message topic to Channel, so messages from different
+	// topics can be processed by different channel rules. Channel can be just
+	// stream/<namespace> then.
+	TopicPath bool `json:"topicPath,omitempty"`
+	// User is an optional broker user, the password or token are secure settings.
+	User string `json:"user,omitempty"`
+	// ClientID is an MQTT client ID or NATS connection name, generated when empty.
+	ClientID string `json:"clientId,omitempty"`
+}
+
+type InputConfigs struct {
+	Configs []InputConfig `json:"inputConfigs"`
+}
+
 type ChannelRules struct {
 	Rules []ChannelRule `json:"rules"`
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -174,6 +175,12 @@ type ChannelRuleGetter interface {
 	Get(orgID int64, channel string) (*LiveChannelRule, bool, error)
 }
 
+// ChannelRuleLister lists loaded rules, window processors of listed rules are
+// flushed by Pipeline.Run.
+type ChannelRuleLister interface {
+	ListRules() []*LiveChannelRule
+}
+
 // Pipeline allows processing custom input data according to user-defined rules.
 // This includes:
 // * transforming custom input to data.Frame objects
@@ -214,6 +221,50 @@ func (p *Pipeline) Get(orgID int64, channel string) (*LiveChannelRule, bool, err
 	return p.ruleGetter.Get(orgID, channel)
 }
 
+const windowFlushInterval = time.Second
+
+// Run passes windows closed by the idle timeout through the rest of their rules,
+// so windows are flushed when frames of a channel stop. Windows nested in a
+// multiple processor are flushed only by new frames. Run blocks until ctx is done.
+func (p *Pipeline) Run(ctx context.Context) error {
+	lister, ok := p.ruleGetter.(ChannelRuleLister)
+	if !ok {
+		return nil
+	}
+	ticker := time.NewTicker(windowFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			p.flushWindows(ctx, lister.ListRules(), now)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *Pipeline) flushWindows(ctx context.Context, rules []*LiveChannelRule, now time.Time) {
+	for _, rule := range rules {
+		for i, proc := range rule.FrameProcessors {
+			window, ok := proc.(*WindowFrameProcessor)
+			if !ok {
+				continue
+			}
+			for _, flushed := range window.flushExpired(now) {
+				frames, err := p.processRuleFrame(ctx, rule, flushed.vars, flushed.frame, rule.FrameProcessors[i+1:])
+				if err != nil {
+					logger.Error("Error processing window frame", "error", err, "channel", flushed.vars.Channel)
+					continue
+				}
+				visitedChannels := map[string]struct{}{flushed.vars.Channel: {}}
+				if err := p.processChannelFrames(ctx, flushed.vars.OrgID, flushed.vars.Channel, frames, visitedChannels); err != nil {
+					logger.Error("Error processing window frame", "error", err, "channel", flushed.vars.Channel)
+				}
+			}
+		}
+	}
+}
+
 func (p *Pipeline) ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error) {
 	var span trace.Span
 	if p.tracer != nil {
@@ -404,9 +455,14 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 		Namespace: ch.Namespace,
 		Path:      ch.Path,
 	}
+	return p.processRuleFrame(ctx, rule, vars, frame, rule.FrameProcessors)
+}
 
-	if len(rule.FrameProcessors) > 0 {
-		for _, proc := range rule.FrameProcessors {
+// processRuleFrame applies processors and then frame outputters of the rule.
+func (p *Pipeline) processRuleFrame(ctx context.Context, rule *LiveChannelRule, vars Vars, frame *data.Frame, processors []FrameProcessor) ([]*ChannelFrame, error) {
+	var err error
+	if len(processors) > 0 {
+		for _, proc := range processors {
 			frame, err = p.execProcessor(ctx, proc, vars, frame)
 			if err != nil {
 				logger.Error("Error processing frame", "error", err)
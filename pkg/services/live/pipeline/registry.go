@@ -50,6 +50,29 @@ var FrameOutputsRegistry = []EntityInfo{
 	},
 }
 
+var InputsRegistry = []EntityInfo{
+	{
+		Type:        InputTypeMQTT,
+		Description: "subscribe to MQTT topics",
+		Example: InputSettings{
+			Type:    InputTypeMQTT,
+			URL:     "tcp://localhost:1883",
+			Topics:  []string{"sensors/#"},
+			Channel: "stream/sensors",
+		},
+	},
+	{
+		Type:        InputTypeNATS,
+		Description: "subscribe to NATS subjects",
+		Example: InputSettings{
+			Type:    InputTypeNATS,
+			URL:     "nats://localhost:4222",
+			Topics:  []string{"sensors.>"},
+			Channel: "stream/sensors",
+		},
+	},
+}
+
 var ConvertersRegistry = []EntityInfo{
 	{
 		Type:        ConverterTypeJsonAuto,
@@ -66,6 +89,26 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheus,
+		Description: "accept Prometheus text exposition format",
+	},
+	{
+		Type:        ConverterTypeCSV,
+		Description: "CSV with a header row, each line becomes a frame row",
+		Example: CSVConverterConfig{
+			Delimiter:  ",",
+			TimeField:  "time",
+			TimeFormat: "unix_ms",
+		},
+	},
+	{
+		Type:        ConverterTypeProtobuf,
+		Description: "protobuf message described by a descriptor set",
+		Example: ProtobufConverterConfig{
+			MessageType: "sensors.Reading",
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +122,14 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeWindow,
+		Description: "aggregate numeric fields over tumbling or sliding time windows",
+		Example: WindowFrameProcessorConfig{
+			SizeMilliseconds: 10000,
+			Aggregations:     []string{WindowAggregationAvg, WindowAggregationMax},
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
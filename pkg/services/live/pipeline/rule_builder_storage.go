@@ -3,6 +3,9 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
 
 	"github.com/centrifugal/centrifuge"
 
@@ -17,6 +20,29 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+
+	windowsMu sync.Mutex
+	// windows are window processors of the last rule build by org and processor key.
+	windows map[int64]map[string]*WindowFrameProcessor
+}
+
+// windowProcessors reuses window processors of the previous rule build, window
+// state would be lost otherwise every time rules are reloaded.
+type windowProcessors struct {
+	previous map[string]*WindowFrameProcessor
+	current  map[string]*WindowFrameProcessor
+}
+
+func (w *windowProcessors) get(key string, config WindowFrameProcessorConfig) (*WindowFrameProcessor, error) {
+	p, ok := w.previous[key]
+	if !ok || !reflect.DeepEqual(p.config, config) {
+		var err error
+		if p, err = NewWindowFrameProcessor(config); err != nil {
+			return nil, err
+		}
+	}
+	w.current[key] = p
+	return p, nil
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -69,12 +95,28 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheus:
+		if config.PrometheusConverterConfig == nil {
+			config.PrometheusConverterConfig = &PrometheusConverterConfig{}
+		}
+		return NewPrometheusConverter(*config.PrometheusConverterConfig), nil
+	case ConverterTypeCSV:
+		if config.CSVConverterConfig == nil {
+			config.CSVConverterConfig = &CSVConverterConfig{}
+		}
+		return NewCSVConverter(*config.CSVConverterConfig), nil
+	case ConverterTypeProtobuf:
+		if config.ProtobufConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewProtobufConverter(*config.ProtobufConverterConfig)
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
 }
 
-func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig) (FrameProcessor, error) {
+// extractFrameProcessor builds a processor, key identifies the processor among rules of an org.
+func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig, key string, windows *windowProcessors) (FrameProcessor, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -90,14 +132,19 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			return nil, missingConfiguration
 		}
 		return NewKeepFieldsFrameProcessor(*config.KeepFieldsProcessorConfig), nil
+	case FrameProcessorTypeWindow:
+		if config.WindowProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return windows.get(key, *config.WindowProcessorConfig)
 	case FrameProcessorTypeMultiple:
 		if config.MultipleProcessorConfig == nil {
 			return nil, missingConfiguration
 		}
 		var processors []FrameProcessor
-		for _, outConf := range config.MultipleProcessorConfig.Processors {
+		for i, outConf := range config.MultipleProcessorConfig.Processors {
 			out := outConf
-			proc, err := f.extractFrameProcessor(&out)
+			proc, err := f.extractFrameProcessor(&out, key+"/"+strconv.Itoa(i), windows)
 			if err != nil {
 				return nil, err
 			}
@@ -310,6 +357,11 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		return nil, err
 	}
 
+	// Concurrent builds of an org must not create different window processors.
+	f.windowsMu.Lock()
+	defer f.windowsMu.Unlock()
+	windows := &windowProcessors{previous: f.windows[orgID], current: map[string]*WindowFrameProcessor{}}
+
 	rules := make([]*LiveChannelRule, 0, len(channelRules))
 
 	for _, ruleConfig := range channelRules {
@@ -334,8 +386,8 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		}
 
 		var processors []FrameProcessor
-		for _, procConfig := range ruleConfig.Settings.FrameProcessors {
-			proc, err := f.extractFrameProcessor(procConfig)
+		for i, procConfig := range ruleConfig.Settings.FrameProcessors {
+			proc, err := f.extractFrameProcessor(procConfig, rule.Pattern+"/"+strconv.Itoa(i), windows)
 			if err != nil {
 				return nil, fmt.Errorf("error building processor for %s: %w", rule.Pattern, err)
 			}
@@ -376,5 +428,9 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		rules = append(rules, rule)
 	}
 
+	if f.windows == nil {
+		f.windows = map[int64]map[string]*WindowFrameProcessor{}
+	}
+	f.windows[orgID] = windows.current
 	return rules, nil
 }
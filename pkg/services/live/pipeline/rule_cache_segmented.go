@@ -13,12 +13,14 @@ import (
 type CacheSegmentedTree struct {
 	radixMu     sync.RWMutex
 	radix       map[int64]*tree.Node
+	rules       map[int64][]*LiveChannelRule
 	ruleBuilder RuleBuilder
 }
 
 func NewCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
 	s := &CacheSegmentedTree{
 		radix:       map[int64]*tree.Node{},
+		rules:       map[int64][]*LiveChannelRule{},
 		ruleBuilder: storage,
 	}
 	go s.updatePeriodically()
@@ -56,9 +58,21 @@ func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
 	for _, ch := range channels {
 		s.radix[orgID].AddRoute("/"+ch.Pattern, ch)
 	}
+	s.rules[orgID] = channels
 	return nil
 }
 
+// ListRules returns rules of all orgs loaded so far.
+func (s *CacheSegmentedTree) ListRules() []*LiveChannelRule {
+	s.radixMu.RLock()
+	defer s.radixMu.RUnlock()
+	var rules []*LiveChannelRule
+	for _, orgRules := range s.rules {
+		rules = append(rules, orgRules...)
+	}
+	return rules
+}
+
 func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	s.radixMu.RLock()
 	_, ok := s.radix[orgID]
@@ -13,4 +13,14 @@ type Storage interface {
 	CreateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error)
 	UpdateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error)
 	DeleteChannelRule(_ context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error
+	ListInputConfigs(_ context.Context, orgID int64) ([]InputConfig, error)
+	GetInputConfig(_ context.Context, orgID int64, cmd InputConfigGetCmd) (InputConfig, bool, error)
+	CreateInputConfig(_ context.Context, orgID int64, cmd InputConfigCreateCmd) (InputConfig, error)
+	UpdateInputConfig(_ context.Context, orgID int64, cmd InputConfigUpdateCmd) (InputConfig, error)
+	DeleteInputConfig(_ context.Context, orgID int64, cmd InputConfigDeleteCmd) error
+}
+
+// InputConfigLister lists input configs of all organizations.
+type InputConfigLister interface {
+	ListAllInputConfigs(_ context.Context) ([]InputConfig, error)
 }
@@ -337,3 +337,189 @@ func (f *FileStorage) saveWriteConfigs(_ int64, writeConfigs WriteConfigs) error
 	}
 	return nil
 }
+
+func (f *FileStorage) ListInputConfigs(_ context.Context, orgID int64) ([]InputConfig, error) {
+	inputConfigs, err := f.readInputConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("can't read input configs: %w", err)
+	}
+	var orgConfigs []InputConfig
+	for _, c := range inputConfigs.Configs {
+		if c.OrgId == orgID || (orgID == 1 && c.OrgId == 0) {
+			orgConfigs = append(orgConfigs, c)
+		}
+	}
+	return orgConfigs, nil
+}
+
+func (f *FileStorage) ListAllInputConfigs(_ context.Context) ([]InputConfig, error) {
+	inputConfigs, err := f.readInputConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("can't read input configs: %w", err)
+	}
+	configs := make([]InputConfig, 0, len(inputConfigs.Configs))
+	for _, c := range inputConfigs.Configs {
+		if c.OrgId == 0 {
+			c.OrgId = 1
+		}
+		configs = append(configs, c)
+	}
+	return configs, nil
+}
+
+func (f *FileStorage) GetInputConfig(_ context.Context, orgID int64, cmd InputConfigGetCmd) (InputConfig, bool, error) {
+	inputConfigs, err := f.readInputConfigs()
+	if err != nil {
+		return InputConfig{}, false, fmt.Errorf("can't read input configs: %w", err)
+	}
+	for _, existingInput := range inputConfigs.Configs {
+		if inputUIDMatch(orgID, cmd.UID, existingInput) {
+			return existingInput, true, nil
+		}
+	}
+	return InputConfig{}, false, nil
+}
+
+func (f *FileStorage) CreateInputConfig(ctx context.Context, orgID int64, cmd InputConfigCreateCmd) (InputConfig, error) {
+	inputConfigs, err := f.readInputConfigs()
+	if err != nil {
+		return InputConfig{}, fmt.Errorf("can't read input configs: %w", err)
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+
+	secureSettings, err := f.SecretsService.EncryptJsonData(ctx, cmd.SecureSettings, secrets.WithoutScope())
+	if err != nil {
+		return InputConfig{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+
+	input := InputConfig{
+		OrgId:          orgID,
+		UID:            cmd.UID,
+		Settings:       cmd.Settings,
+		SecureSettings: secureSettings,
+	}
+
+	ok, reason := input.Valid()
+	if !ok {
+		return InputConfig{}, fmt.Errorf("invalid input config: %s", reason)
+	}
+	for _, existingInput := range inputConfigs.Configs {
+		if inputUIDMatch(orgID, input.UID, existingInput) {
+			return InputConfig{}, fmt.Errorf("input already exists in org: %s", input.UID)
+		}
+	}
+	inputConfigs.Configs = append(inputConfigs.Configs, input)
+	err = f.saveInputConfigs(inputConfigs)
+	return input, err
+}
+
+func (f *FileStorage) UpdateInputConfig(ctx context.Context, orgID int64, cmd InputConfigUpdateCmd) (InputConfig, error) {
+	inputConfigs, err := f.readInputConfigs()
+	if err != nil {
+		return InputConfig{}, fmt.Errorf("can't read input configs: %w", err)
+	}
+
+	secureSettings, err := f.SecretsService.EncryptJsonData(ctx, cmd.SecureSettings, secrets.WithoutScope())
+	if err != nil {
+		return InputConfig{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+
+	input := InputConfig{
+		OrgId:          orgID,
+		UID:            cmd.UID,
+		Settings:       cmd.Settings,
+		SecureSettings: secureSettings,
+	}
+
+	ok, reason := input.Valid()
+	if !ok {
+		return InputConfig{}, fmt.Errorf("invalid input config: %s", reason)
+	}
+
+	index := -1
+	for i, existingInput := range inputConfigs.Configs {
+		if inputUIDMatch(orgID, input.UID, existingInput) {
+			index = i
+			break
+		}
+	}
+	if index > -1 {
+		inputConfigs.Configs[index] = input
+	} else {
+		return f.CreateInputConfig(ctx, orgID, InputConfigCreateCmd(cmd))
+	}
+
+	err = f.saveInputConfigs(inputConfigs)
+	return input, err
+}
+
+func (f *FileStorage) DeleteInputConfig(_ context.Context, orgID int64, cmd InputConfigDeleteCmd) error {
+	inputConfigs, err := f.readInputConfigs()
+	if err != nil {
+		return fmt.Errorf("can't read input configs: %w", err)
+	}
+
+	index := -1
+	for i, existingInput := range inputConfigs.Configs {
+		if inputUIDMatch(orgID, cmd.UID, existingInput) {
+			index = i
+			break
+		}
+	}
+
+	if index > -1 {
+		inputConfigs.Configs = append(inputConfigs.Configs[:index], inputConfigs.Configs[index+1:]...)
+	} else {
+		return fmt.Errorf("input config not found")
+	}
+
+	return f.saveInputConfigs(inputConfigs)
+}
+
+func inputUIDMatch(orgID int64, uid string, existingInput InputConfig) bool {
+	return uid == existingInput.UID && (existingInput.OrgId == orgID || (existingInput.OrgId == 0 && orgID == 1))
+}
+
+func (f *FileStorage) inputConfigsFilePath() string {
+	return filepath.Join(f.DataPath, "pipeline", "input-configs.json")
+}
+
+func (f *FileStorage) readInputConfigs() (InputConfigs, error) {
+	filePath := f.inputConfigsFilePath()
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Inputs are optional, unlike rules and write configs.
+			return InputConfigs{}, nil
+		}
+		return InputConfigs{}, fmt.Errorf("can't read %s file: %w", filePath, err)
+	}
+	var inputConfigs InputConfigs
+	err = json.Unmarshal(bytes, &inputConfigs)
+	if err != nil {
+		return InputConfigs{}, fmt.Errorf("can't unmarshal %s data: %w", filePath, err)
+	}
+	return inputConfigs, nil
+}
+
+func (f *FileStorage) saveInputConfigs(inputConfigs InputConfigs) error {
+	filePath := f.inputConfigsFilePath()
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("can't open input configs file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(inputConfigs)
+	if err != nil {
+		return fmt.Errorf("can't save input configs to file: %w", err)
+	}
+	return nil
+}
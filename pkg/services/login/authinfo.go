@@ -27,6 +27,7 @@ const (
 	// modules
 	PasswordAuthModule     = "password"
 	PasswordlessAuthModule = "passwordless"
+	WebAuthnAuthModule     = "webauthn"
 	APIKeyAuthModule       = "apikey"
 	SAMLAuthModule         = "auth.saml"
 	LDAPAuthModule         = "ldap"
@@ -2,14 +2,24 @@ package loginattempt
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
+var ErrBadRequest = errutil.BadRequest("loginattempt.bad-request")
+
 type Service interface {
 	// Add adds a new login attempt record for provided username
 	Add(ctx context.Context, username, IPAddress string) error
-	// Validate checks if username has to many login attempts inside a window.
-	// Will return true if provided username do not have too many attempts.
-	Validate(ctx context.Context, username string) (bool, error)
+	// Validate checks if username, username from IP address or IP address have too many login attempts.
+	// Will return true if logins of the username from the IP address are not blocked. The IP address policies
+	// are skipped if the IP address is empty.
+	Validate(ctx context.Context, username, IPAddress string) (bool, error)
 	// Reset resets all login attempts attached to username
 	Reset(ctx context.Context, username string) error
 }
@@ -20,3 +30,74 @@ type LoginAttempt struct {
 	IpAddress string
 	Created   int64
 }
+
+// Policies of the brute force login protection, they block the logins of a username, of a username from an IP
+// address or of any username from an IP address.
+const (
+	PolicyUsername          = "username"
+	PolicyIPAddressUsername = "ip_address_username"
+	PolicyIPAddress         = "ip_address"
+)
+
+// BlockedLogin is a username, a username from an IP address or an IP address whose logins are blocked.
+type BlockedLogin struct {
+	Policy       string    `json:"policy"`
+	Username     string    `json:"username,omitempty"`
+	IPAddress    string    `json:"ipAddress,omitempty"`
+	Attempts     int64     `json:"attempts"`
+	LastAttempt  time.Time `json:"lastAttempt"`
+	BlockedUntil time.Time `json:"blockedUntil"`
+}
+
+// UnblockCommand unblocks the logins of a username, of a username from an IP address or from an IP address.
+type UnblockCommand struct {
+	Username  string `json:"username"`
+	IPAddress string `json:"ipAddress"`
+}
+
+// ClientIP returns the IP address of the client of the request. The X-Forwarded-For and X-Real-IP headers are only
+// used when the connection comes from one of the trusted proxies, otherwise any client could set them to evade the
+// limits of failed login attempts from an IP address. X-Forwarded-For is read from the right and the first address
+// that is not a trusted proxy is used.
+func ClientIP(cfg *setting.Cfg, r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(cfg, host) {
+		return host
+	}
+
+	if forwardedFor := r.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		addrs := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			addr := strings.TrimSpace(addrs[i])
+			if net.ParseIP(addr) == nil {
+				break
+			}
+			host = addr
+			if !isTrustedProxy(cfg, addr) {
+				break
+			}
+		}
+		return host
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return host
+}
+
+func isTrustedProxy(cfg *setting.Cfg, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range cfg.BruteForceLoginProtectionTrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package loginattemptimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/admin/login-attempts", func(entities routing.RouteRegister) {
+		entities.Get("/blocked", routing.Wrap(s.listBlockedHandler))
+		entities.Post("/unblock", routing.Wrap(s.unblockHandler))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+func (s *Service) listBlockedHandler(c *contextmodel.ReqContext) response.Response {
+	blocked, err := s.ListBlocked(c.Req.Context())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list blocked logins", err)
+	}
+	return response.JSON(http.StatusOK, blocked)
+}
+
+func (s *Service) unblockHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := loginattempt.UnblockCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(loginattempt.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+	if cmd.Username == "" && cmd.IPAddress == "" {
+		return response.Err(loginattempt.ErrBadRequest.Errorf("username or ipAddress is required"))
+	}
+
+	if err := s.Unblock(c.Req.Context(), cmd.Username, cmd.IPAddress); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to unblock logins", err)
+	}
+	return response.Success("Logins unblocked")
+}
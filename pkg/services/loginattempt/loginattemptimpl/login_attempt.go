@@ -5,24 +5,33 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
-	maxInvalidLoginAttempts int64 = 5
-	loginAttemptsWindow           = time.Minute * 5
+	// cleanupInterval is also the minimum age of the deleted login attempts
+	cleanupInterval = time.Minute * 10
 )
 
-func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService) *Service {
-	return &Service{
+func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService, routeRegister routing.RouteRegister) *Service {
+	s := &Service{
 		&xormStore{db: db, now: time.Now},
 		cfg,
 		lock,
 		log.New("login_attempt"),
+		time.Now,
 	}
+
+	if routeRegister != nil {
+		s.registerAPIEndpoints(routeRegister)
+	}
+
+	return s
 }
 
 type Service struct {
@@ -30,6 +39,23 @@ type Service struct {
 	cfg    *setting.Cfg
 	lock   *serverlock.ServerLockService
 	logger log.Logger
+	now    func() time.Time
+}
+
+// policy limits the failed login attempts of a username, of a username from an IP address or from an IP address.
+type policy struct {
+	name        string
+	byUsername  bool
+	byIPAddress bool
+	maxAttempts int64
+}
+
+func (s *Service) policies() []policy {
+	return []policy{
+		{name: loginattempt.PolicyUsername, byUsername: true, maxAttempts: s.cfg.BruteForceLoginProtectionMaxAttempts},
+		{name: loginattempt.PolicyIPAddressUsername, byUsername: true, byIPAddress: true, maxAttempts: s.cfg.BruteForceLoginProtectionIPUsernameMaxAttempts},
+		{name: loginattempt.PolicyIPAddress, byIPAddress: true, maxAttempts: s.cfg.BruteForceLoginProtectionIPMaxAttempts},
+	}
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -38,7 +64,7 @@ func (s *Service) Run(ctx context.Context) error {
 		return nil
 	}
 
-	ticker := time.NewTicker(time.Minute * 10)
+	ticker := time.NewTicker(cleanupInterval)
 	for {
 		select {
 		case <-ticker.C:
@@ -62,35 +88,111 @@ func (s *Service) Add(ctx context.Context, username, IPAddress string) error {
 }
 
 func (s *Service) Reset(ctx context.Context, username string) error {
-	return s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{strings.ToLower(username)})
+	return s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{Username: strings.ToLower(username)})
 }
 
-func (s *Service) Validate(ctx context.Context, username string) (bool, error) {
+func (s *Service) Validate(ctx context.Context, username, IPAddress string) (bool, error) {
 	if s.cfg.DisableBruteForceLoginProtection {
 		return true, nil
 	}
 
-	loginAttemptCountQuery := GetUserLoginAttemptCountQuery{
-		Username: strings.ToLower(username),
-		Since:    time.Now().Add(-loginAttemptsWindow),
+	now := s.now()
+	for _, p := range s.policies() {
+		if p.maxAttempts <= 0 || (p.byIPAddress && IPAddress == "") {
+			continue
+		}
+
+		query := GetLoginAttemptStatsQuery{Since: now.Add(-s.cfg.BruteForceLoginProtectionMaxLockout)}
+		if p.byUsername {
+			query.Username = strings.ToLower(username)
+		}
+		if p.byIPAddress {
+			query.IpAddress = IPAddress
+		}
+		stats, err := s.store.GetLoginAttemptStats(ctx, query)
+		if err != nil {
+			return false, err
+		}
+
+		if s.blockedUntil(stats, p.maxAttempts).After(now) {
+			s.logger.FromContext(ctx).Debug("Login blocked", "policy", p.name, "attempts", stats.Count)
+			return false, nil
+		}
 	}
 
-	count, err := s.store.GetUserLoginAttemptCount(ctx, loginAttemptCountQuery)
-	if err != nil {
-		return false, err
+	return true, nil
+}
+
+// ListBlocked returns the usernames, usernames from IP addresses and IP addresses whose logins are blocked.
+func (s *Service) ListBlocked(ctx context.Context) ([]loginattempt.BlockedLogin, error) {
+	blocked := make([]loginattempt.BlockedLogin, 0)
+	if s.cfg.DisableBruteForceLoginProtection {
+		return blocked, nil
 	}
 
-	if count >= maxInvalidLoginAttempts {
-		return false, nil
+	now := s.now()
+	for _, p := range s.policies() {
+		if p.maxAttempts <= 0 {
+			continue
+		}
+
+		stats, err := s.store.ListLoginAttemptStats(ctx, ListLoginAttemptStatsQuery{
+			ByUsername:  p.byUsername,
+			ByIpAddress: p.byIPAddress,
+			Since:       now.Add(-s.cfg.BruteForceLoginProtectionMaxLockout),
+			MinCount:    p.maxAttempts,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, stat := range stats {
+			blockedUntil := s.blockedUntil(stat, p.maxAttempts)
+			if !blockedUntil.After(now) {
+				continue
+			}
+			blocked = append(blocked, loginattempt.BlockedLogin{
+				Policy:       p.name,
+				Username:     stat.Username,
+				IPAddress:    stat.IpAddress,
+				Attempts:     stat.Count,
+				LastAttempt:  time.Unix(stat.LastAttempt, 0),
+				BlockedUntil: blockedUntil,
+			})
+		}
 	}
+	return blocked, nil
+}
 
-	return true, nil
+// Unblock deletes the login attempts of a username, of a username from an IP address or from an IP address.
+func (s *Service) Unblock(ctx context.Context, username, IPAddress string) error {
+	return s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{
+		Username:  strings.ToLower(username),
+		IpAddress: IPAddress,
+	})
+}
+
+// blockedUntil returns the end of the lockout after the last failed attempt. The lockout doubles with every attempt
+// above the maximum, attempts are not recorded while the logins are blocked.
+func (s *Service) blockedUntil(stats LoginAttemptStats, maxAttempts int64) time.Time {
+	if stats.Count < maxAttempts {
+		return time.Time{}
+	}
+
+	lockout := s.cfg.BruteForceLoginProtectionLockout
+	for i := maxAttempts; i < stats.Count && lockout < s.cfg.BruteForceLoginProtectionMaxLockout; i++ {
+		lockout *= 2
+	}
+	lockout = min(lockout, s.cfg.BruteForceLoginProtectionMaxLockout)
+
+	return time.Unix(stats.LastAttempt, 0).Add(lockout)
 }
 
 func (s *Service) cleanup(ctx context.Context) {
-	err := s.lock.LockAndExecute(ctx, "delete old login attempts", time.Minute*10, func(context.Context) {
+	err := s.lock.LockAndExecute(ctx, "delete old login attempts", cleanupInterval, func(context.Context) {
+		// attempts are kept as long as they can block logins
 		cmd := DeleteOldLoginAttemptsCommand{
-			OlderThan: time.Now().Add(time.Minute * -10),
+			OlderThan: time.Now().Add(-max(s.cfg.BruteForceLoginProtectionMaxLockout, cleanupInterval)),
 		}
 		if deletedLogs, err := s.store.DeleteOldLoginAttempts(ctx, cmd); err != nil {
 			s.logger.Error("Problem deleting expired login attempts", "error", err.Error())
@@ -2,19 +2,43 @@ package loginattemptimpl
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
 )
 
+const maxInvalidLoginAttempts int64 = 5
+
+func newTestCfg() *setting.Cfg {
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtectionMaxAttempts = maxInvalidLoginAttempts
+	cfg.BruteForceLoginProtectionIPUsernameMaxAttempts = maxInvalidLoginAttempts
+	cfg.BruteForceLoginProtectionIPMaxAttempts = 3 * maxInvalidLoginAttempts
+	cfg.BruteForceLoginProtectionLockout = 5 * time.Minute
+	cfg.BruteForceLoginProtectionMaxLockout = time.Hour
+	return cfg
+}
+
 func TestService_Validate(t *testing.T) {
+	now := time.Now()
+
 	testCases := []struct {
 		name          string
 		loginAttempts int64
+		lastAttempt   time.Time
 		disabled      bool
 		expected      bool
 		expectedErr   error
@@ -22,18 +46,35 @@ func TestService_Validate(t *testing.T) {
 		{
 			name:          "When brute force protection enabled and user login attempt count is less than max",
 			loginAttempts: maxInvalidLoginAttempts - 1,
+			lastAttempt:   now,
 			expected:      true,
 			expectedErr:   nil,
 		},
 		{
 			name:          "When brute force protection enabled and user login attempt count equals max",
 			loginAttempts: maxInvalidLoginAttempts,
+			lastAttempt:   now,
 			expected:      false,
 			expectedErr:   nil,
 		},
 		{
 			name:          "When brute force protection enabled and user login attempt count is greater than max",
 			loginAttempts: maxInvalidLoginAttempts + 1,
+			lastAttempt:   now,
+			expected:      false,
+			expectedErr:   nil,
+		},
+		{
+			name:          "When brute force protection enabled and user login attempt count equals max and lockout expired",
+			loginAttempts: maxInvalidLoginAttempts,
+			lastAttempt:   now.Add(-6 * time.Minute),
+			expected:      true,
+			expectedErr:   nil,
+		},
+		{
+			name:          "When brute force protection enabled and user login attempt count is greater than max and lockout is doubled",
+			loginAttempts: maxInvalidLoginAttempts + 1,
+			lastAttempt:   now.Add(-6 * time.Minute),
 			expected:      false,
 			expectedErr:   nil,
 		},
@@ -41,6 +82,7 @@ func TestService_Validate(t *testing.T) {
 		{
 			name:          "When brute force protection disabled and user login attempt count is less than max",
 			loginAttempts: maxInvalidLoginAttempts - 1,
+			lastAttempt:   now,
 			disabled:      true,
 			expected:      true,
 			expectedErr:   nil,
@@ -48,6 +90,7 @@ func TestService_Validate(t *testing.T) {
 		{
 			name:          "When brute force protection disabled and user login attempt count equals max",
 			loginAttempts: maxInvalidLoginAttempts,
+			lastAttempt:   now,
 			disabled:      true,
 			expected:      true,
 			expectedErr:   nil,
@@ -55,6 +98,7 @@ func TestService_Validate(t *testing.T) {
 		{
 			name:          "When brute force protection disabled and user login attempt count is greater than max",
 			loginAttempts: maxInvalidLoginAttempts + 1,
+			lastAttempt:   now,
 			disabled:      true,
 			expected:      true,
 			expectedErr:   nil,
@@ -63,29 +107,71 @@ func TestService_Validate(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			cfg := setting.NewCfg()
+			cfg := newTestCfg()
 			cfg.DisableBruteForceLoginProtection = tt.disabled
 			service := &Service{
 				store: fakeStore{
-					ExpectedCount: tt.loginAttempts,
-					ExpectedErr:   tt.expectedErr,
+					ExpectedCount:       tt.loginAttempts,
+					ExpectedLastAttempt: tt.lastAttempt.Unix(),
+					ExpectedErr:         tt.expectedErr,
 				},
-				cfg: cfg,
+				cfg:    cfg,
+				logger: log.NewNopLogger(),
+				now:    func() time.Time { return now },
 			}
 
-			ok, err := service.Validate(context.Background(), "test")
+			ok, err := service.Validate(context.Background(), "test", "")
 			assert.Equal(t, tt.expected, ok)
 			assert.Equal(t, tt.expectedErr, err)
 		})
 	}
 }
 
+func TestService_blockedUntil(t *testing.T) {
+	lastAttempt := time.Date(2024, 10, 22, 8, 0, 0, 0, time.UTC)
+	service := &Service{cfg: newTestCfg()}
+
+	testCases := []struct {
+		name          string
+		loginAttempts int64
+		expected      time.Time
+	}{
+		{
+			name:          "Should not block when login attempt count is less than max",
+			loginAttempts: maxInvalidLoginAttempts - 1,
+			expected:      time.Time{},
+		},
+		{
+			name:          "Should block for the lockout when login attempt count equals max",
+			loginAttempts: maxInvalidLoginAttempts,
+			expected:      lastAttempt.Add(5 * time.Minute),
+		},
+		{
+			name:          "Should double the lockout for every login attempt above max",
+			loginAttempts: maxInvalidLoginAttempts + 2,
+			expected:      lastAttempt.Add(20 * time.Minute),
+		},
+		{
+			name:          "Should not block for longer than the max lockout",
+			loginAttempts: maxInvalidLoginAttempts + 100,
+			expected:      lastAttempt.Add(time.Hour),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			blockedUntil := service.blockedUntil(LoginAttemptStats{Count: tt.loginAttempts, LastAttempt: lastAttempt.Unix()}, maxInvalidLoginAttempts)
+			assert.True(t, tt.expected.Equal(blockedUntil), "expected %s, got %s", tt.expected, blockedUntil)
+		})
+	}
+}
+
 func TestLoginAttempts(t *testing.T) {
 	ctx := context.Background()
-	cfg := setting.NewCfg()
+	cfg := newTestCfg()
 	cfg.DisableBruteForceLoginProtection = false
 	db := db.InitTestDB(t)
-	service := ProvideService(db, cfg, nil)
+	service := ProvideService(db, cfg, nil, nil)
 
 	// add multiple login attempts with different uppercases, they all should be counted as the same user
 	_ = service.Add(ctx, "admin", "[::1]")
@@ -100,16 +186,152 @@ func TestLoginAttempts(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, int64(6), count)
 
-	ok, err := service.Validate(ctx, "admin")
+	ok, err := service.Validate(ctx, "admin", "")
 	assert.False(t, ok)
 	assert.Nil(t, err)
 }
 
+func TestIntegrationLoginAttemptPolicies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	t.Run("Should block any username from an IP address with too many login attempts", func(t *testing.T) {
+		cfg := newTestCfg()
+		cfg.BruteForceLoginProtectionIPMaxAttempts = 3
+		service := ProvideService(db.InitTestDB(t), cfg, nil, nil)
+
+		// password spraying, one attempt per username
+		for _, username := range []string{"user1", "user2", "user3"} {
+			require.NoError(t, service.Add(ctx, username, "10.0.0.1"))
+		}
+
+		ok, err := service.Validate(ctx, "user4", "10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = service.Validate(ctx, "user4", "10.0.0.2")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = service.Validate(ctx, "user4", "")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		blocked, err := service.ListBlocked(ctx)
+		require.NoError(t, err)
+		require.Len(t, blocked, 1)
+		assert.Equal(t, loginattempt.PolicyIPAddress, blocked[0].Policy)
+		assert.Equal(t, "10.0.0.1", blocked[0].IPAddress)
+		assert.Empty(t, blocked[0].Username)
+		assert.Equal(t, int64(3), blocked[0].Attempts)
+
+		require.NoError(t, service.Unblock(ctx, "", "10.0.0.1"))
+		ok, err = service.Validate(ctx, "user4", "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Should block a username from an IP address with too many login attempts", func(t *testing.T) {
+		cfg := newTestCfg()
+		cfg.BruteForceLoginProtectionMaxAttempts = 0
+		cfg.BruteForceLoginProtectionIPUsernameMaxAttempts = 2
+		service := ProvideService(db.InitTestDB(t), cfg, nil, nil)
+
+		require.NoError(t, service.Add(ctx, "user", "10.0.0.1"))
+		require.NoError(t, service.Add(ctx, "User", "10.0.0.1"))
+		require.NoError(t, service.Add(ctx, "user", "10.0.0.2"))
+
+		ok, err := service.Validate(ctx, "user", "10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = service.Validate(ctx, "user", "10.0.0.2")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = service.Validate(ctx, "other", "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		blocked, err := service.ListBlocked(ctx)
+		require.NoError(t, err)
+		require.Len(t, blocked, 1)
+		assert.Equal(t, loginattempt.PolicyIPAddressUsername, blocked[0].Policy)
+		assert.Equal(t, "user", blocked[0].Username)
+		assert.Equal(t, "10.0.0.1", blocked[0].IPAddress)
+
+		require.NoError(t, service.Unblock(ctx, "USER", "10.0.0.1"))
+		ok, err = service.Validate(ctx, "user", "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestIntegrationLoginAttemptsAPI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	router := routing.NewRouteRegister()
+	service := ProvideService(db.InitTestDB(t), newTestCfg(), nil, router)
+	server := webtest.NewServer(t, router)
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1, IsGrafanaAdmin: true}
+
+	for i := int64(0); i < maxInvalidLoginAttempts; i++ {
+		require.NoError(t, service.Add(ctx, "user", "10.0.0.1"))
+	}
+
+	t.Run("Should require a Grafana admin", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/login-attempts/blocked"), &user.SignedInUser{UserID: 2, OrgID: 1})
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("Should list blocked logins", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/login-attempts/blocked"), admin)
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var blocked []loginattempt.BlockedLogin
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&blocked))
+		require.NoError(t, res.Body.Close())
+		require.Len(t, blocked, 2)
+		assert.Equal(t, loginattempt.PolicyUsername, blocked[0].Policy)
+		assert.Equal(t, loginattempt.PolicyIPAddressUsername, blocked[1].Policy)
+	})
+
+	t.Run("Should require a username or an IP address to unblock", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewPostRequest("/api/admin/login-attempts/unblock", strings.NewReader(`{}`)), admin)
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("Should unblock logins", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewPostRequest("/api/admin/login-attempts/unblock", strings.NewReader(`{"username":"user"}`)), admin)
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		blocked, err := service.ListBlocked(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, blocked)
+	})
+}
+
 var _ store = new(fakeStore)
 
 type fakeStore struct {
 	ExpectedErr         error
 	ExpectedCount       int64
+	ExpectedLastAttempt int64
 	ExpectedDeletedRows int64
 }
 
@@ -117,6 +339,14 @@ func (f fakeStore) GetUserLoginAttemptCount(ctx context.Context, query GetUserLo
 	return f.ExpectedCount, f.ExpectedErr
 }
 
+func (f fakeStore) GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error) {
+	return LoginAttemptStats{Username: query.Username, IpAddress: query.IpAddress, Count: f.ExpectedCount, LastAttempt: f.ExpectedLastAttempt}, f.ExpectedErr
+}
+
+func (f fakeStore) ListLoginAttemptStats(ctx context.Context, query ListLoginAttemptStatsQuery) ([]LoginAttemptStats, error) {
+	return []LoginAttemptStats{{Count: f.ExpectedCount, LastAttempt: f.ExpectedLastAttempt}}, f.ExpectedErr
+}
+
 func (f fakeStore) CreateLoginAttempt(ctx context.Context, command CreateLoginAttemptCommand) (loginattempt.LoginAttempt, error) {
 	return loginattempt.LoginAttempt{}, f.ExpectedErr
 }
@@ -14,10 +14,37 @@ type GetUserLoginAttemptCountQuery struct {
 	Since    time.Time
 }
 
+// GetLoginAttemptStatsQuery counts the login attempts of a username, of a username from an IP address or from an
+// IP address, empty fields are not filtered on.
+type GetLoginAttemptStatsQuery struct {
+	Username  string
+	IpAddress string
+	Since     time.Time
+}
+
+// ListLoginAttemptStatsQuery groups the login attempts by username, IP address or both, and returns the groups
+// with at least MinCount attempts.
+type ListLoginAttemptStatsQuery struct {
+	ByUsername  bool
+	ByIpAddress bool
+	Since       time.Time
+	MinCount    int64
+}
+
+type LoginAttemptStats struct {
+	Username    string `xorm:"username"`
+	IpAddress   string `xorm:"ip_address"`
+	Count       int64  `xorm:"attempts"`
+	LastAttempt int64  `xorm:"last_attempt"`
+}
+
 type DeleteOldLoginAttemptsCommand struct {
 	OlderThan time.Time
 }
 
+// DeleteLoginAttemptsCommand deletes the login attempts of a username, of a username from an IP address or from
+// an IP address.
 type DeleteLoginAttemptsCommand struct {
-	Username string
+	Username  string
+	IpAddress string
 }
@@ -2,6 +2,8 @@ package loginattemptimpl
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -18,6 +20,8 @@ type store interface {
 	DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLoginAttemptsCommand) (int64, error)
 	DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error
 	GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error)
+	GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error)
+	ListLoginAttemptStats(ctx context.Context, query ListLoginAttemptStatsQuery) ([]LoginAttemptStats, error)
 }
 
 func (xs *xormStore) CreateLoginAttempt(ctx context.Context, cmd CreateLoginAttemptCommand) (result loginattempt.LoginAttempt, err error) {
@@ -57,8 +61,12 @@ func (xs *xormStore) DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLo
 }
 
 func (xs *xormStore) DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error {
+	where, args := loginAttemptFilter(cmd.Username, cmd.IpAddress)
+	if len(where) == 0 {
+		return errors.New("username or IP address is required to delete login attempts")
+	}
 	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
-		_, err := sess.Exec("DELETE FROM login_attempt WHERE username = ?", cmd.Username)
+		_, err := sess.Exec(append([]any{"DELETE FROM login_attempt WHERE " + strings.Join(where, " AND ")}, args...)...)
 		return err
 	})
 }
@@ -82,3 +90,56 @@ func (xs *xormStore) GetUserLoginAttemptCount(ctx context.Context, query GetUser
 
 	return total, err
 }
+
+func (xs *xormStore) GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error) {
+	var stats LoginAttemptStats
+	where, args := loginAttemptFilter(query.Username, query.IpAddress)
+	where = append(where, "created >= ?")
+	args = append(args, query.Since.Unix())
+
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		_, err := dbSession.SQL("SELECT COUNT(*) AS attempts, COALESCE(MAX(created), 0) AS last_attempt FROM login_attempt WHERE "+
+			strings.Join(where, " AND "), args...).Get(&stats)
+		return err
+	})
+	stats.Username, stats.IpAddress = query.Username, query.IpAddress
+	return stats, err
+}
+
+func (xs *xormStore) ListLoginAttemptStats(ctx context.Context, query ListLoginAttemptStatsQuery) ([]LoginAttemptStats, error) {
+	stats := make([]LoginAttemptStats, 0)
+	columns := []string{}
+	where := []string{"created >= ?"}
+	if query.ByUsername {
+		columns = append(columns, "username")
+	}
+	if query.ByIpAddress {
+		columns = append(columns, "ip_address")
+		where = append(where, "ip_address <> ''")
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("login attempts must be grouped by username or IP address")
+	}
+
+	sql := "SELECT " + strings.Join(columns, ", ") + ", COUNT(*) AS attempts, MAX(created) AS last_attempt" +
+		" FROM login_attempt WHERE " + strings.Join(where, " AND ") +
+		" GROUP BY " + strings.Join(columns, ", ") +
+		" HAVING COUNT(*) >= ? ORDER BY last_attempt DESC"
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		return dbSession.SQL(sql, query.Since.Unix(), query.MinCount).Find(&stats)
+	})
+	return stats, err
+}
+
+func loginAttemptFilter(username, ipAddress string) ([]string, []any) {
+	where, args := []string{}, []any{}
+	if username != "" {
+		where = append(where, "username = ?")
+		args = append(args, username)
+	}
+	if ipAddress != "" {
+		where = append(where, "ip_address = ?")
+		args = append(args, ipAddress)
+	}
+	return where, args
+}
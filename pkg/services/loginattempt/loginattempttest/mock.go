@@ -12,6 +12,8 @@ type MockLoginAttemptService struct {
 	AddCalled      bool
 	ResetCalled    bool
 	ValidateCalled bool
+	// IPAddress is the IP address of the last call to Add or Validate
+	IPAddress string
 
 	ExpectedValid bool
 	ExpectedErr   error
@@ -19,6 +21,7 @@ type MockLoginAttemptService struct {
 
 func (f *MockLoginAttemptService) Add(ctx context.Context, username, IPAddress string) error {
 	f.AddCalled = true
+	f.IPAddress = IPAddress
 	return f.ExpectedErr
 }
 
@@ -27,7 +30,8 @@ func (f *MockLoginAttemptService) Reset(ctx context.Context, username string) er
 	return f.ExpectedErr
 }
 
-func (f *MockLoginAttemptService) Validate(ctx context.Context, username string) (bool, error) {
+func (f *MockLoginAttemptService) Validate(ctx context.Context, username, IPAddress string) (bool, error) {
 	f.ValidateCalled = true
+	f.IPAddress = IPAddress
 	return f.ExpectedValid, f.ExpectedErr
 }
@@ -0,0 +1,108 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrNotEnrolled       = errutil.BadRequest("mfa.not-enrolled", errutil.WithPublicMessage("Multi-factor authentication is not enabled for the user"))
+	ErrAlreadyEnrolled   = errutil.BadRequest("mfa.already-enrolled", errutil.WithPublicMessage("Multi-factor authentication is already enabled for the user"))
+	ErrInvalidCode       = errutil.Unauthorized("mfa.invalid-code", errutil.WithPublicMessage("Invalid verification code"))
+	ErrEnforced          = errutil.Forbidden("mfa.enforced", errutil.WithPublicMessage("Multi-factor authentication is enforced and cannot be disabled"))
+	ErrChallengeNotFound = errutil.Unauthorized("mfa.challenge-not-found", errutil.WithPublicMessage("The login challenge is invalid or expired, log in again"))
+	ErrBadRequest        = errutil.BadRequest("mfa.bad-request")
+	// ErrEnrollmentNotAllowed is returned when a user who already has a second factor tries to enroll with a login challenge.
+	ErrEnrollmentNotAllowed = errutil.Forbidden("mfa.enrollment-not-allowed", errutil.WithPublicMessage("Verify your second factor to log in"))
+	// ErrIncorrectCode is returned instead of ErrInvalidCode to signed in users, whose session is still valid.
+	ErrIncorrectCode   = errutil.BadRequest("mfa.incorrect-code", errutil.WithPublicMessage("Invalid verification code"))
+	ErrTooManyAttempts = errutil.TooManyRequests("mfa.too-many-attempts", errutil.WithPublicMessage("Too many invalid verification codes, try again later"))
+)
+
+type Service interface {
+	// GetStatus returns whether the user has enabled multi-factor authentication and if it is enforced for them.
+	GetStatus(ctx context.Context, userID int64) (*Status, error)
+	// StartEnrollment generates a new TOTP secret and recovery codes for the user. The enrollment is pending
+	// until it is confirmed with a code generated from the secret.
+	StartEnrollment(ctx context.Context, userID int64, login string) (*Enrollment, error)
+	// ConfirmEnrollment enables multi-factor authentication for the user if code is valid for the pending enrollment.
+	ConfirmEnrollment(ctx context.Context, userID int64, code string) error
+	// Verify checks a TOTP code or one of the recovery codes of the user. Recovery codes can only be used once.
+	Verify(ctx context.Context, userID int64, code string) error
+	// Disable removes the multi-factor authentication of the user.
+	Disable(ctx context.Context, userID int64) error
+	// RegenerateRecoveryCodes replaces the recovery codes of the user.
+	RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error)
+	// IsEnforced returns whether the user has to use multi-factor authentication, either because it is enforced
+	// on the instance or by one of the organizations of the user.
+	IsEnforced(ctx context.Context, userID int64) (bool, error)
+	GetOrgPolicy(ctx context.Context, orgID int64) (*OrgPolicy, error)
+	SetOrgPolicy(ctx context.Context, policy *OrgPolicy) error
+	// CreateChallenge returns a token that identifies the challenge between the first and the second factor of a login.
+	CreateChallenge(ctx context.Context, challenge *Challenge) (string, error)
+	// GetChallenge returns the login challenge of the token.
+	GetChallenge(ctx context.Context, token string) (*Challenge, error)
+	DeleteChallenge(ctx context.Context, token string) error
+}
+
+// UserMFA is the multi-factor authentication of a user. The secret and the recovery codes are encrypted.
+type UserMFA struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	UserID        int64  `xorm:"user_id"`
+	Secret        string `xorm:"secret"`
+	RecoveryCodes string `xorm:"recovery_codes"`
+	Enabled       bool   `xorm:"enabled"`
+	// LastUsedStep is the time step of the last TOTP code used, so that a code cannot be used twice.
+	LastUsedStep int64     `xorm:"last_used_step"`
+	Created      time.Time `xorm:"created"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// OrgPolicy is the multi-factor authentication policy of an organization.
+type OrgPolicy struct {
+	ID       int64     `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID    int64     `xorm:"org_id" json:"-"`
+	Enforced bool      `xorm:"enforced" json:"enforced"`
+	Updated  time.Time `xorm:"updated" json:"-"`
+}
+
+func (OrgPolicy) TableName() string {
+	return "org_mfa_policy"
+}
+
+// Challenge is a login challenge of a user who entered their password and has to verify a second factor.
+type Challenge struct {
+	UserID int64 `json:"userId"`
+	// EnrollmentRequired is set when the user had no second factor, only these users can enroll with the challenge.
+	EnrollmentRequired bool `json:"enrollmentRequired"`
+}
+
+type Status struct {
+	Enabled                bool `json:"enabled"`
+	Enforced               bool `json:"enforced"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+type Enrollment struct {
+	Secret        string   `json:"secret"`
+	URL           string   `json:"url"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type VerifyCodeCommand struct {
+	Code string `json:"code" binding:"Required"`
+}
+
+type EnrollChallengeCommand struct {
+	Challenge string `json:"challenge" binding:"Required"`
+}
+
+type UpdateOrgPolicyCommand struct {
+	Enforced bool `json:"enforced"`
+}
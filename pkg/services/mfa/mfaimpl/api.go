@@ -0,0 +1,218 @@
+package mfaimpl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/user/mfa", func(entities routing.RouteRegister) {
+		entities.Get("/", routing.Wrap(s.getStatusHandler))
+		entities.Post("/enroll", routing.Wrap(s.startEnrollmentHandler))
+		entities.Post("/enroll/confirm", routing.Wrap(s.confirmEnrollmentHandler))
+		entities.Post("/recovery-codes", routing.Wrap(s.regenerateRecoveryCodesHandler))
+		entities.Post("/disable", routing.Wrap(s.disableHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	s.routeRegister.Group("/api/org/mfa", func(entities routing.RouteRegister) {
+		entities.Get("/", routing.Wrap(s.getOrgPolicyHandler))
+		entities.Put("/", middleware.ReqOrgAdmin, routing.Wrap(s.updateOrgPolicyHandler))
+	}, middleware.ReqSignedIn)
+
+	s.routeRegister.Delete("/api/admin/users/:id/mfa", middleware.ReqGrafanaAdmin, routing.Wrap(s.adminDisableHandler))
+
+	// the login challenge authenticates the user that has to enroll before completing the login
+	s.routeRegister.Post("/api/login/mfa/enroll", routing.Wrap(s.loginEnrollmentHandler))
+}
+
+func (s *Service) getStatusHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	status, err := s.GetStatus(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+func (s *Service) startEnrollmentHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	enrollment, err := s.StartEnrollment(c.Req.Context(), userID, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start multi-factor authentication enrollment", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+func (s *Service) confirmEnrollmentHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	cmd := mfa.VerifyCodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(mfa.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	if err := s.ConfirmEnrollment(c.Req.Context(), userID, cmd.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to confirm multi-factor authentication enrollment", err)
+	}
+	return response.Success("Multi-factor authentication enabled")
+}
+
+func (s *Service) regenerateRecoveryCodesHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	cmd := mfa.VerifyCodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(mfa.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	if errResponse := s.verifyCode(c, userID, cmd.Code); errResponse != nil {
+		return errResponse
+	}
+	codes, err := s.RegenerateRecoveryCodes(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to regenerate recovery codes", err)
+	}
+	return response.JSON(http.StatusOK, map[string]any{"recoveryCodes": codes})
+}
+
+func (s *Service) disableHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	cmd := mfa.VerifyCodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(mfa.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	enforced, err := s.IsEnforced(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable multi-factor authentication", err)
+	}
+	if enforced {
+		return response.Err(mfa.ErrEnforced.Errorf("multi-factor authentication is enforced for user %d", userID))
+	}
+	if errResponse := s.verifyCode(c, userID, cmd.Code); errResponse != nil {
+		return errResponse
+	}
+	if err := s.Disable(c.Req.Context(), userID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable multi-factor authentication", err)
+	}
+	return response.Success("Multi-factor authentication disabled")
+}
+
+func (s *Service) getOrgPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	policy, err := s.GetOrgPolicy(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) updateOrgPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := mfa.UpdateOrgPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(mfa.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	policy := &mfa.OrgPolicy{OrgID: c.SignedInUser.GetOrgID(), Enforced: cmd.Enforced}
+	if err := s.SetOrgPolicy(c.Req.Context(), policy); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update multi-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) adminDisableHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Err(mfa.ErrBadRequest.Errorf("id is invalid: %w", err))
+	}
+
+	if err := s.Disable(c.Req.Context(), userID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable multi-factor authentication", err)
+	}
+	return response.Success("Multi-factor authentication disabled")
+}
+
+func (s *Service) loginEnrollmentHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := mfa.EnrollChallengeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(mfa.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	challenge, err := s.GetChallenge(c.Req.Context(), cmd.Challenge)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start multi-factor authentication enrollment", err)
+	}
+	// users with a second factor have to verify it, they cannot replace it by enrolling with the challenge
+	if !challenge.EnrollmentRequired {
+		return response.Err(mfa.ErrEnrollmentNotAllowed.Errorf("login challenge of user %d does not allow enrollment", challenge.UserID))
+	}
+	userID := challenge.UserID
+	usr, err := s.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start multi-factor authentication enrollment", err)
+	}
+
+	enrollment, err := s.StartEnrollment(c.Req.Context(), userID, usr.Login)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start multi-factor authentication enrollment", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// verifyCode checks the code of the signed in user. Failed codes count as failed login attempts of the user, so the
+// codes cannot be guessed with a stolen session.
+func (s *Service) verifyCode(c *contextmodel.ReqContext, userID int64, code string) response.Response {
+	ctx := c.Req.Context()
+	login := c.SignedInUser.GetLogin()
+	ok, err := s.loginAttempts.Validate(ctx, login, "")
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to verify code", err)
+	}
+	if !ok {
+		return response.Err(mfa.ErrTooManyAttempts.Errorf("too many invalid codes of user %d", userID))
+	}
+
+	if err := s.Verify(ctx, userID, code); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			_ = s.loginAttempts.Add(ctx, login, "")
+			return response.Err(mfa.ErrIncorrectCode.Errorf("invalid code of user %d: %w", userID, err))
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to verify code", err)
+	}
+	return nil
+}
+
+func signedInUserID(c *contextmodel.ReqContext) (int64, response.Response) {
+	if !c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		return 0, response.Error(http.StatusForbidden, "Multi-factor authentication is only available to users", nil)
+	}
+	userID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get user id", err)
+	}
+	return userID, nil
+}
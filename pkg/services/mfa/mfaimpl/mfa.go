@@ -0,0 +1,381 @@
+package mfaimpl
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	recoveryCodeCount   = 10
+	challengeKeyPrefix  = "mfa-challenge-%s"
+	challengeTokenBytes = 32
+)
+
+var recoveryCodeAlphabet = []byte("abcdefghjkmnpqrstuvwxyz23456789")
+
+type Service struct {
+	store          store
+	cfg            *setting.Cfg
+	secretsService secrets.Service
+	cache          remotecache.CacheStorage
+	userService    user.Service
+	loginAttempts  loginattempt.Service
+	routeRegister  routing.RouteRegister
+	now            func() time.Time
+	log            log.Logger
+}
+
+var _ mfa.Service = &Service{}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, secretsService secrets.Service, cache *remotecache.RemoteCache,
+	userService user.Service, loginAttempts loginattempt.Service, routeRegister routing.RouteRegister) *Service {
+	s := &Service{
+		store:          &sqlStore{db: db},
+		cfg:            cfg,
+		secretsService: secretsService,
+		cache:          cache,
+		userService:    userService,
+		loginAttempts:  loginAttempts,
+		routeRegister:  routeRegister,
+		now:            time.Now,
+		log:            log.New("mfa"),
+	}
+
+	if cfg.MFA.Enabled {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+func (s *Service) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	enforced, err := s.IsEnforced(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &mfa.Status{Enforced: enforced}
+	m, has, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !has || !m.Enabled {
+		return status, nil
+	}
+
+	codes, err := s.decryptRecoveryCodes(ctx, m.RecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = true
+	status.RecoveryCodesRemaining = len(codes)
+	return status, nil
+}
+
+func (s *Service) StartEnrollment(ctx context.Context, userID int64, login string) (*mfa.Enrollment, error) {
+	m, has, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if has && m.Enabled {
+		return nil, mfa.ErrAlreadyEnrolled.Errorf("user %d already enabled multi-factor authentication", userID)
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encryptedSecret, err := s.encrypt(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+	codes, encryptedCodes, err := s.generateRecoveryCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if err := s.store.Upsert(ctx, &mfa.UserMFA{
+		UserID:        userID,
+		Secret:        encryptedSecret,
+		RecoveryCodes: encryptedCodes,
+		Enabled:       false,
+		Created:       now,
+		Updated:       now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &mfa.Enrollment{
+		Secret:        secret,
+		URL:           totpURL(s.cfg.MFA.Issuer, login, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *Service) ConfirmEnrollment(ctx context.Context, userID int64, code string) error {
+	m, has, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return mfa.ErrNotEnrolled.Errorf("user %d has not started an enrollment", userID)
+	}
+	if m.Enabled {
+		return mfa.ErrAlreadyEnrolled.Errorf("user %d already enabled multi-factor authentication", userID)
+	}
+
+	step, err := s.validateTOTP(ctx, m, code)
+	if err != nil {
+		return err
+	}
+
+	m.Enabled = true
+	m.LastUsedStep = step
+	m.Updated = s.now()
+	return s.store.Upsert(ctx, m)
+}
+
+func (s *Service) Verify(ctx context.Context, userID int64, code string) error {
+	m, has, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !has || !m.Enabled {
+		return mfa.ErrNotEnrolled.Errorf("user %d has not enabled multi-factor authentication", userID)
+	}
+
+	step, err := s.validateTOTP(ctx, m, code)
+	if err == nil {
+		updated, err := s.store.UpdateLastUsedStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return mfa.ErrInvalidCode.Errorf("code was already used")
+		}
+		return nil
+	}
+	if !errors.Is(err, mfa.ErrInvalidCode) {
+		return err
+	}
+
+	return s.useRecoveryCode(ctx, m, code)
+}
+
+func (s *Service) Disable(ctx context.Context, userID int64) error {
+	return s.store.Delete(ctx, userID)
+}
+
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	m, has, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !has || !m.Enabled {
+		return nil, mfa.ErrNotEnrolled.Errorf("user %d has not enabled multi-factor authentication", userID)
+	}
+
+	codes, encryptedCodes, err := s.generateRecoveryCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.RecoveryCodes = encryptedCodes
+	m.Updated = s.now()
+	if err := s.store.Upsert(ctx, m); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *Service) IsEnforced(ctx context.Context, userID int64) (bool, error) {
+	if s.cfg.MFA.Enforced {
+		return true, nil
+	}
+	return s.store.IsEnforcedInUserOrgs(ctx, userID)
+}
+
+func (s *Service) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	policy, has, err := s.store.GetOrgPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return &mfa.OrgPolicy{OrgID: orgID}, nil
+	}
+	return policy, nil
+}
+
+func (s *Service) SetOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error {
+	policy.Updated = s.now()
+	return s.store.UpsertOrgPolicy(ctx, policy)
+}
+
+func (s *Service) CreateChallenge(ctx context.Context, challenge *mfa.Challenge) (string, error) {
+	token, err := util.GetRandomString(challengeTokenBytes)
+	if err != nil {
+		return "", err
+	}
+	value, err := json.Marshal(challenge)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf(challengeKeyPrefix, token)
+	if err := s.cache.Set(ctx, key, value, s.cfg.MFA.ChallengeTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *Service) GetChallenge(ctx context.Context, token string) (*mfa.Challenge, error) {
+	if token == "" {
+		return nil, mfa.ErrChallengeNotFound.Errorf("missing challenge")
+	}
+
+	value, err := s.cache.Get(ctx, fmt.Sprintf(challengeKeyPrefix, token))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, mfa.ErrChallengeNotFound.Errorf("challenge not found")
+		}
+		return nil, err
+	}
+
+	challenge := &mfa.Challenge{}
+	if err := json.Unmarshal(value, challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (s *Service) DeleteChallenge(ctx context.Context, token string) error {
+	err := s.cache.Delete(ctx, fmt.Sprintf(challengeKeyPrefix, token))
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil
+	}
+	return err
+}
+
+// validateTOTP returns the time step of code if it is valid for the secret of m.
+func (s *Service) validateTOTP(ctx context.Context, m *mfa.UserMFA, code string) (int64, error) {
+	secret, err := s.decrypt(ctx, m.Secret)
+	if err != nil {
+		return 0, err
+	}
+	step, ok, err := validateTOTP(secret, code, s.now(), m.LastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, mfa.ErrInvalidCode.Errorf("invalid code")
+	}
+	return step, nil
+}
+
+// useRecoveryCode removes code from the recovery codes of the user, or returns mfa.ErrInvalidCode if it is not
+// one of them.
+func (s *Service) useRecoveryCode(ctx context.Context, m *mfa.UserMFA, code string) error {
+	codes, err := s.decryptRecoveryCodes(ctx, m.RecoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	code = normalizeRecoveryCode(code)
+	for i, recoveryCode := range codes {
+		if subtle.ConstantTimeCompare([]byte(normalizeRecoveryCode(recoveryCode)), []byte(code)) != 1 {
+			continue
+		}
+
+		remaining, err := s.encryptRecoveryCodes(ctx, append(codes[:i:i], codes[i+1:]...))
+		if err != nil {
+			return err
+		}
+		updated, err := s.store.UpdateRecoveryCodes(ctx, m.UserID, m.RecoveryCodes, remaining)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return mfa.ErrInvalidCode.Errorf("recovery code was already used")
+		}
+		s.log.FromContext(ctx).Info("Recovery code used", "userID", m.UserID, "remaining", len(codes)-1)
+		return nil
+	}
+	return mfa.ErrInvalidCode.Errorf("invalid code")
+}
+
+func (s *Service) generateRecoveryCodes(ctx context.Context) ([]string, string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GetRandomString(10, recoveryCodeAlphabet...)
+		if err != nil {
+			return nil, "", err
+		}
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+
+	encrypted, err := s.encryptRecoveryCodes(ctx, codes)
+	if err != nil {
+		return nil, "", err
+	}
+	return codes, encrypted, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func (s *Service) encryptRecoveryCodes(ctx context.Context, codes []string) (string, error) {
+	data, err := json.Marshal(codes)
+	if err != nil {
+		return "", err
+	}
+	return s.encrypt(ctx, string(data))
+}
+
+func (s *Service) decryptRecoveryCodes(ctx context.Context, encrypted string) ([]string, error) {
+	data, err := s.decrypt(ctx, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0)
+	if err := json.Unmarshal([]byte(data), &codes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *Service) encrypt(ctx context.Context, value string) (string, error) {
+	encrypted, err := s.secretsService.Encrypt(ctx, []byte(value), secrets.WithoutScope())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func (s *Service) decrypt(ctx context.Context, value string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := s.secretsService.Decrypt(ctx, decoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
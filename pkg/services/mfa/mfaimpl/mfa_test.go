@@ -0,0 +1,275 @@
+package mfaimpl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/clients"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/services/webauthn/webauthntest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationMFA(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	testDB := db.InitTestDB(t)
+	now := time.Unix(1700000000, 0)
+	s := &Service{
+		store:          &sqlStore{db: testDB},
+		cfg:            setting.NewCfg(),
+		secretsService: fakes.NewFakeSecretsService(),
+		cache:          remotecache.NewFakeCacheStorage(),
+		now:            func() time.Time { return now },
+		log:            log.NewNopLogger(),
+	}
+	s.cfg.MFA.Issuer = "Grafana"
+	ctx := context.Background()
+	const userID = 1
+
+	enrollment, err := s.StartEnrollment(ctx, userID, "admin")
+	require.NoError(t, err)
+	require.Len(t, enrollment.RecoveryCodes, recoveryCodeCount)
+	require.Contains(t, enrollment.URL, "otpauth://totp/Grafana:admin?")
+
+	t.Run("the enrollment is pending until it is confirmed", func(t *testing.T) {
+		status, err := s.GetStatus(ctx, userID)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+		require.ErrorIs(t, s.Verify(ctx, userID, "000000"), mfa.ErrNotEnrolled)
+
+		require.ErrorIs(t, s.ConfirmEnrollment(ctx, userID, "000000"), mfa.ErrInvalidCode)
+
+		code, err := totpCode(enrollment.Secret, totpStep(now))
+		require.NoError(t, err)
+		require.NoError(t, s.ConfirmEnrollment(ctx, userID, code))
+
+		status, err = s.GetStatus(ctx, userID)
+		require.NoError(t, err)
+		require.True(t, status.Enabled)
+		require.Equal(t, recoveryCodeCount, status.RecoveryCodesRemaining)
+
+		_, err = s.StartEnrollment(ctx, userID, "admin")
+		require.ErrorIs(t, err, mfa.ErrAlreadyEnrolled)
+	})
+
+	t.Run("stores the secret encrypted", func(t *testing.T) {
+		m, has, err := s.store.Get(ctx, userID)
+		require.NoError(t, err)
+		require.True(t, has)
+		require.NotEqual(t, enrollment.Secret, m.Secret)
+	})
+
+	t.Run("a code can only be used once", func(t *testing.T) {
+		// the code of the current step was used to confirm the enrollment
+		code, err := totpCode(enrollment.Secret, totpStep(now))
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Verify(ctx, userID, code), mfa.ErrInvalidCode)
+
+		code, err = totpCode(enrollment.Secret, totpStep(now)+1)
+		require.NoError(t, err)
+		require.NoError(t, s.Verify(ctx, userID, code))
+		require.ErrorIs(t, s.Verify(ctx, userID, code), mfa.ErrInvalidCode)
+	})
+
+	t.Run("a recovery code can only be used once", func(t *testing.T) {
+		require.NoError(t, s.Verify(ctx, userID, enrollment.RecoveryCodes[3]))
+		require.ErrorIs(t, s.Verify(ctx, userID, enrollment.RecoveryCodes[3]), mfa.ErrInvalidCode)
+
+		status, err := s.GetStatus(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+	})
+
+	t.Run("regenerating the recovery codes invalidates the previous ones", func(t *testing.T) {
+		codes, err := s.RegenerateRecoveryCodes(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, codes, recoveryCodeCount)
+
+		require.ErrorIs(t, s.Verify(ctx, userID, enrollment.RecoveryCodes[0]), mfa.ErrInvalidCode)
+		require.NoError(t, s.Verify(ctx, userID, codes[0]))
+	})
+
+	t.Run("is enforced by the organizations of the user", func(t *testing.T) {
+		err := testDB.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (?, ?, ?, ?, ?)", 2, userID, "Viewer", now, now)
+			return err
+		})
+		require.NoError(t, err)
+
+		enforced, err := s.IsEnforced(ctx, userID)
+		require.NoError(t, err)
+		require.False(t, enforced)
+
+		require.NoError(t, s.SetOrgPolicy(ctx, &mfa.OrgPolicy{OrgID: 2, Enforced: true}))
+		enforced, err = s.IsEnforced(ctx, userID)
+		require.NoError(t, err)
+		require.True(t, enforced)
+
+		policy, err := s.GetOrgPolicy(ctx, 3)
+		require.NoError(t, err)
+		require.False(t, policy.Enforced)
+	})
+
+	t.Run("login challenges identify the user", func(t *testing.T) {
+		challenge, err := s.CreateChallenge(ctx, &mfa.Challenge{UserID: userID, EnrollmentRequired: true})
+		require.NoError(t, err)
+
+		got, err := s.GetChallenge(ctx, challenge)
+		require.NoError(t, err)
+		require.Equal(t, &mfa.Challenge{UserID: userID, EnrollmentRequired: true}, got)
+
+		require.NoError(t, s.DeleteChallenge(ctx, challenge))
+		_, err = s.GetChallenge(ctx, challenge)
+		require.ErrorIs(t, err, mfa.ErrChallengeNotFound)
+	})
+
+	t.Run("disable removes the multi-factor authentication", func(t *testing.T) {
+		require.NoError(t, s.Disable(ctx, userID))
+		status, err := s.GetStatus(ctx, userID)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+	})
+}
+
+func TestIntegrationMFA_LoginEnrollment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	routeRegister := routing.NewRouteRegister()
+	userService := &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Login: "admin"}}
+	s := &Service{
+		store:          &sqlStore{db: db.InitTestDB(t)},
+		cfg:            setting.NewCfg(),
+		secretsService: fakes.NewFakeSecretsService(),
+		cache:          remotecache.NewFakeCacheStorage(),
+		userService:    userService,
+		routeRegister:  routeRegister,
+		now:            time.Now,
+		log:            log.NewNopLogger(),
+	}
+	s.cfg.MFA.Issuer = "Grafana"
+	s.cfg.MFA.ChallengeTTL = time.Minute
+	s.registerAPIEndpoints()
+	server := webtest.NewServer(t, routeRegister)
+	ctx := context.Background()
+	const userID = 1
+
+	// the user has a security key, the login challenge only lets them verify it
+	challenge, err := s.CreateChallenge(ctx, &mfa.Challenge{UserID: userID})
+	require.NoError(t, err)
+
+	t.Run("a user with a second factor cannot enroll with the login challenge", func(t *testing.T) {
+		req := server.NewPostRequest("/api/login/mfa/enroll", strings.NewReader(`{"challenge": "`+challenge+`"}`))
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		_, has, err := s.store.Get(ctx, userID)
+		require.NoError(t, err)
+		require.False(t, has)
+	})
+
+	t.Run("a user with a second factor cannot complete the login by confirming an enrollment", func(t *testing.T) {
+		enrollment, err := s.StartEnrollment(ctx, userID, "admin")
+		require.NoError(t, err)
+		code, err := totpCode(enrollment.Secret, totpStep(time.Now()))
+		require.NoError(t, err)
+
+		c := clients.ProvideMFA(setting.NewCfg(), s, &webauthntest.FakeService{ExpectedHasCredentials: true}, userService, &loginattempttest.FakeLoginAttemptService{ExpectedValid: true})
+		identity, err := c.Authenticate(ctx, &authn.Request{OrgID: 1, HTTPRequest: &http.Request{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{"challenge": "` + challenge + `", "code": "` + code + `"}`)),
+		}})
+		require.ErrorIs(t, err, mfa.ErrEnrollmentNotAllowed)
+		require.Nil(t, identity)
+
+		status, err := s.GetStatus(ctx, userID)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+	})
+}
+
+func TestIntegrationMFA_VerifyCodeAttempts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	routeRegister := routing.NewRouteRegister()
+	loginAttempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+	s := &Service{
+		store:          &sqlStore{db: db.InitTestDB(t)},
+		cfg:            setting.NewCfg(),
+		secretsService: fakes.NewFakeSecretsService(),
+		cache:          remotecache.NewFakeCacheStorage(),
+		loginAttempts:  loginAttempts,
+		routeRegister:  routeRegister,
+		now:            time.Now,
+		log:            log.NewNopLogger(),
+	}
+	s.cfg.MFA.Issuer = "Grafana"
+	s.registerAPIEndpoints()
+	server := webtest.NewServer(t, routeRegister)
+	ctx := context.Background()
+	const userID = 1
+
+	enrollment, err := s.StartEnrollment(ctx, userID, "admin")
+	require.NoError(t, err)
+	code, err := totpCode(enrollment.Secret, totpStep(time.Now()))
+	require.NoError(t, err)
+	require.NoError(t, s.ConfirmEnrollment(ctx, userID, code))
+
+	send := func(t *testing.T, target, code string) int {
+		req := server.NewPostRequest(target, strings.NewReader(`{"code": "`+code+`"}`))
+		req = webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: userID, OrgID: 1, Login: "admin"})
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode
+	}
+
+	for _, target := range []string{"/api/user/mfa/recovery-codes", "/api/user/mfa/disable"} {
+		t.Run(target+" counts invalid codes as failed login attempts", func(t *testing.T) {
+			loginAttempts.AddCalled = false
+			require.Equal(t, http.StatusBadRequest, send(t, target, "000000"))
+			require.True(t, loginAttempts.ValidateCalled)
+			require.True(t, loginAttempts.AddCalled)
+		})
+
+		t.Run(target+" rejects codes once the user is blocked", func(t *testing.T) {
+			loginAttempts.ExpectedValid = false
+			t.Cleanup(func() { loginAttempts.ExpectedValid = true })
+
+			require.Equal(t, http.StatusTooManyRequests, send(t, target, enrollment.RecoveryCodes[0]))
+			status, err := s.GetStatus(ctx, userID)
+			require.NoError(t, err)
+			require.True(t, status.Enabled)
+			require.Equal(t, recoveryCodeCount, status.RecoveryCodesRemaining)
+		})
+	}
+}
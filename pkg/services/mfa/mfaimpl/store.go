@@ -0,0 +1,137 @@
+package mfaimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+type store interface {
+	Get(ctx context.Context, userID int64) (*mfa.UserMFA, bool, error)
+	// Upsert stores the multi-factor authentication of the user, replacing the existing one.
+	Upsert(ctx context.Context, m *mfa.UserMFA) error
+	Delete(ctx context.Context, userID int64) error
+	// UpdateLastUsedStep sets the last used time step of the user if it is after the current one. It returns false
+	// if the step was already used, e.g. by a concurrent login.
+	UpdateLastUsedStep(ctx context.Context, userID int64, step int64) (bool, error)
+	// UpdateRecoveryCodes replaces the recovery codes of the user if they were not changed since they were read.
+	UpdateRecoveryCodes(ctx context.Context, userID int64, previous, codes string) (bool, error)
+	GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, bool, error)
+	UpsertOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error
+	// IsEnforcedInUserOrgs returns whether one of the organizations of the user enforces multi-factor authentication.
+	IsEnforcedInUserOrgs(ctx context.Context, userID int64) (bool, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Get(ctx context.Context, userID int64) (*mfa.UserMFA, bool, error) {
+	m := &mfa.UserMFA{}
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("user_id = ?", userID).Get(m)
+		return err
+	})
+	return m, has, err
+}
+
+func (s *sqlStore) Upsert(ctx context.Context, m *mfa.UserMFA) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &mfa.UserMFA{}
+		has, err := sess.Where("user_id = ?", m.UserID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(m)
+			return err
+		}
+
+		m.ID = existing.ID
+		m.Created = existing.Created
+		_, err = sess.ID(m.ID).AllCols().Update(m)
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_mfa WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+func (s *sqlStore) UpdateLastUsedStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	var updated bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec("UPDATE user_mfa SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?", step, userID, step)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		updated = rows > 0
+		return err
+	})
+	return updated, err
+}
+
+func (s *sqlStore) UpdateRecoveryCodes(ctx context.Context, userID int64, previous, codes string) (bool, error) {
+	var updated bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec("UPDATE user_mfa SET recovery_codes = ? WHERE user_id = ? AND recovery_codes = ?", codes, userID, previous)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		updated = rows > 0
+		return err
+	})
+	return updated, err
+}
+
+func (s *sqlStore) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, bool, error) {
+	policy := &mfa.OrgPolicy{}
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("org_id = ?", orgID).Get(policy)
+		return err
+	})
+	return policy, has, err
+}
+
+func (s *sqlStore) UpsertOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &mfa.OrgPolicy{}
+		has, err := sess.Where("org_id = ?", policy.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(policy)
+			return err
+		}
+
+		policy.ID = existing.ID
+		_, err = sess.ID(policy.ID).AllCols().Update(policy)
+		return err
+	})
+}
+
+func (s *sqlStore) IsEnforcedInUserOrgs(ctx context.Context, userID int64) (bool, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Table("org_user").
+			Join("INNER", "org_mfa_policy", "org_mfa_policy.org_id = org_user.org_id").
+			Where("org_user.user_id = ? AND org_mfa_policy.enforced = ?", userID, true).
+			Count()
+		return err
+	})
+	return count > 0, err
+}
@@ -0,0 +1,57 @@
+package mfaimpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP(t *testing.T) {
+	// test vectors of RFC 6238 for the SHA1 secret "12345678901234567890", truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	t.Run("generates the codes of RFC 6238", func(t *testing.T) {
+		for unix, expected := range map[int64]string{
+			59:         "287082",
+			1111111109: "081804",
+			1234567890: "005924",
+			2000000000: "279037",
+		} {
+			code, err := totpCode(secret, totpStep(time.Unix(unix, 0)))
+			require.NoError(t, err)
+			require.Equal(t, expected, code)
+		}
+	})
+
+	t.Run("accepts the codes of the previous and the next time steps", func(t *testing.T) {
+		now := time.Unix(1111111109, 0)
+		for _, offset := range []time.Duration{-totpPeriod, 0, totpPeriod} {
+			code, err := totpCode(secret, totpStep(now.Add(offset)))
+			require.NoError(t, err)
+
+			step, ok, err := validateTOTP(secret, code, now, 0)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, totpStep(now.Add(offset)), step)
+		}
+
+		code, err := totpCode(secret, totpStep(now.Add(2*totpPeriod)))
+		require.NoError(t, err)
+		_, ok, err := validateTOTP(secret, code, now, 0)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("rejects codes of time steps that were already used", func(t *testing.T) {
+		now := time.Unix(1111111109, 0)
+		_, ok, err := validateTOTP(secret, "081804", now, totpStep(now))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("builds the otpauth url", func(t *testing.T) {
+		url := totpURL("Grafana", "admin@localhost", secret)
+		require.Equal(t, "otpauth://totp/Grafana:admin@localhost?algorithm=SHA1&digits=6&issuer=Grafana&period=30&secret="+secret, url)
+	})
+}
@@ -0,0 +1,84 @@
+package mfatest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+var _ mfa.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedStatus     *mfa.Status
+	ExpectedEnrollment *mfa.Enrollment
+	ExpectedCodes      []string
+	ExpectedEnforced   bool
+	ExpectedPolicy     *mfa.OrgPolicy
+	ExpectedChallenge  string
+	ExpectedUserID     int64
+	// ExpectedEnrollmentRequired is set on the challenge returned by GetChallenge
+	ExpectedEnrollmentRequired bool
+	ExpectedErr                error
+	// ExpectedVerifyErr is returned by Verify and ConfirmEnrollment
+	ExpectedVerifyErr error
+
+	VerifyCalls            int
+	ConfirmEnrollmentCalls int
+	DeleteChallengeCalls   int
+	CreatedChallenge       *mfa.Challenge
+}
+
+func (f *FakeService) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	if f.ExpectedStatus == nil {
+		return &mfa.Status{}, f.ExpectedErr
+	}
+	return f.ExpectedStatus, f.ExpectedErr
+}
+
+func (f *FakeService) StartEnrollment(ctx context.Context, userID int64, login string) (*mfa.Enrollment, error) {
+	return f.ExpectedEnrollment, f.ExpectedErr
+}
+
+func (f *FakeService) ConfirmEnrollment(ctx context.Context, userID int64, code string) error {
+	f.ConfirmEnrollmentCalls++
+	return f.ExpectedVerifyErr
+}
+
+func (f *FakeService) Verify(ctx context.Context, userID int64, code string) error {
+	f.VerifyCalls++
+	return f.ExpectedVerifyErr
+}
+
+func (f *FakeService) Disable(ctx context.Context, userID int64) error {
+	return f.ExpectedErr
+}
+
+func (f *FakeService) RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	return f.ExpectedCodes, f.ExpectedErr
+}
+
+func (f *FakeService) IsEnforced(ctx context.Context, userID int64) (bool, error) {
+	return f.ExpectedEnforced, f.ExpectedErr
+}
+
+func (f *FakeService) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	return f.ExpectedPolicy, f.ExpectedErr
+}
+
+func (f *FakeService) SetOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error {
+	return f.ExpectedErr
+}
+
+func (f *FakeService) CreateChallenge(ctx context.Context, challenge *mfa.Challenge) (string, error) {
+	f.CreatedChallenge = challenge
+	return f.ExpectedChallenge, f.ExpectedErr
+}
+
+func (f *FakeService) GetChallenge(ctx context.Context, token string) (*mfa.Challenge, error) {
+	return &mfa.Challenge{UserID: f.ExpectedUserID, EnrollmentRequired: f.ExpectedEnrollmentRequired}, f.ExpectedErr
+}
+
+func (f *FakeService) DeleteChallenge(ctx context.Context, token string) error {
+	f.DeleteChallengeCalls++
+	return f.ExpectedErr
+}
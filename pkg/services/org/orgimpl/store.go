@@ -244,6 +244,8 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM team_role WHERE org_id = ?",
 			"DELETE FROM user_role WHERE org_id = ?",
 			"DELETE FROM builtin_role WHERE org_id = ?",
+			"DELETE FROM org_mfa_policy WHERE org_id = ?",
+			"DELETE FROM scim_resource WHERE org_id = ?",
 		}
 
 		// Add registered deletes
@@ -687,6 +689,7 @@ func (ss *sqlStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCom
 			"DELETE FROM dashboard_acl WHERE org_id=? and user_id = ?",
 			"DELETE FROM team_member WHERE org_id=? and user_id = ?",
 			"DELETE FROM query_history_star WHERE org_id=? and user_id = ?",
+			"DELETE FROM scim_resource WHERE org_id=? and resource_type = 'User' and resource_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -818,6 +821,9 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_mfa WHERE user_id = ?",
+		"DELETE FROM webauthn_credential WHERE user_id = ?",
+		"DELETE FROM scim_resource WHERE resource_type = 'User' AND resource_id = ?",
 	}
 	return deletes
 }
@@ -8,6 +8,17 @@ import (
 var (
 	ErrPlaylistNotFound        = errors.New("Playlist not found")
 	ErrCommandValidationFailed = errors.New("command missing required fields")
+	ErrInvalidItem             = errors.New("invalid playlist item")
+	ErrInvalidSchedule         = errors.New("invalid playlist schedule")
+)
+
+// Playlist item types
+const (
+	ItemTypeDashboardByID      = "dashboard_by_id"
+	ItemTypeDashboardByUID     = "dashboard_by_uid"
+	ItemTypeDashboardByTag     = "dashboard_by_tag"
+	ItemTypeDashboardByFolder  = "dashboard_by_folder"
+	ItemTypeDashboardByStarred = "dashboard_by_starred"
 )
 
 // Playlist model
@@ -39,6 +50,9 @@ type PlaylistDTO struct {
 	// The ordered list of items that the playlist will iterate over.
 	Items []PlaylistItemDTO `json:"items,omitempty"`
 
+	// Schedules switch to other playlists during parts of the day.
+	Schedules []PlaylistSchedule `json:"schedules,omitempty"`
+
 	// Returned for k8s
 	CreatedAt int64 `json:"-" db:"created_at"`
 
@@ -67,7 +81,14 @@ type PlaylistItemDTO struct {
 	//  - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All
 	//  dashboards behind the tag will be added to the playlist.
 	//  - dashboard_by_uid: The value is the dashboard UID
+	//  - dashboard_by_folder: The value is a folder UID. All dashboards in the folder
+	//  will be added to the playlist.
+	//  - dashboard_by_starred: The value is unused. The dashboards starred by the user
+	//  playing the playlist will be added to the playlist.
 	Value string `json:"value"`
+
+	// Interval overrides the playlist interval for the dashboards of this item.
+	Interval string `json:"interval,omitempty"`
 }
 
 type PlaylistItem struct {
@@ -77,6 +98,28 @@ type PlaylistItem struct {
 	Value      string `json:"value" db:"value"`
 	Order      int    `json:"order" db:"order"`
 	Title      string `json:"title" db:"title"`
+	Interval   string `json:"interval,omitempty" db:"interval"`
+}
+
+// PlaylistSchedule plays another playlist between a start and an end time of day.
+type PlaylistSchedule struct {
+	Id         int64 `json:"-" db:"id"`
+	PlaylistId int64 `json:"-" db:"playlist_id"`
+	Order      int   `json:"-" db:"order"`
+
+	// Weekdays the schedule starts on, for example "monday". The schedule applies to every day when empty.
+	Weekdays []string `json:"weekdays,omitempty" db:"weekdays"`
+
+	// StartTime and EndTime of the schedule in the 15:04 format. Schedules ending before they start
+	// span midnight.
+	StartTime string `json:"startTime" db:"start_time"`
+	EndTime   string `json:"endTime" db:"end_time"`
+
+	// Timezone of the start and end times. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty" db:"timezone"`
+
+	// PlaylistUID is the playlist played during the schedule.
+	PlaylistUID string `json:"playlistUid" xorm:"playlist_uid" db:"playlist_uid"`
 }
 
 type Playlists []*Playlist
@@ -86,18 +129,20 @@ type Playlists []*Playlist
 //
 
 type UpdatePlaylistCommand struct {
-	OrgId    int64          `json:"-"`
-	UID      string         `json:"uid"`
-	Name     string         `json:"name" binding:"Required"`
-	Interval string         `json:"interval"`
-	Items    []PlaylistItem `json:"items"`
+	OrgId     int64              `json:"-"`
+	UID       string             `json:"uid"`
+	Name      string             `json:"name" binding:"Required"`
+	Interval  string             `json:"interval"`
+	Items     []PlaylistItem     `json:"items"`
+	Schedules []PlaylistSchedule `json:"schedules"`
 }
 
 type CreatePlaylistCommand struct {
-	Name     string         `json:"name" binding:"Required"`
-	Interval string         `json:"interval"`
-	Items    []PlaylistItem `json:"items"`
-	OrgId    int64          `json:"-"`
+	Name      string             `json:"name" binding:"Required"`
+	Interval  string             `json:"interval"`
+	Items     []PlaylistItem     `json:"items"`
+	Schedules []PlaylistSchedule `json:"schedules"`
+	OrgId     int64              `json:"-"`
 	// Used to create playlists from kubectl with a known uid/name
 	UID string `json:"-"`
 }
@@ -127,3 +172,8 @@ type GetPlaylistItemsByUidQuery struct {
 	PlaylistUID string
 	OrgId       int64
 }
+
+type GetPlaylistSchedulesByUidQuery struct {
+	PlaylistUID string
+	OrgId       int64
+}
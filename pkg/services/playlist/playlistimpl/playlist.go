@@ -2,6 +2,8 @@ package playlistimpl
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -27,15 +29,41 @@ func ProvideService(db db.DB, tracer tracing.Tracer) playlist.Service {
 func (s *Service) Create(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.Create")
 	defer span.End()
+	if err := playlist.Validate(cmd.UID, cmd.Items, cmd.Schedules); err != nil {
+		return nil, err
+	}
+	if err := s.validateScheduledPlaylists(ctx, cmd.OrgId, cmd.Schedules); err != nil {
+		return nil, err
+	}
 	return s.store.Insert(ctx, cmd)
 }
 
 func (s *Service) Update(ctx context.Context, cmd *playlist.UpdatePlaylistCommand) (*playlist.PlaylistDTO, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.Update")
 	defer span.End()
+	if err := playlist.Validate(cmd.UID, cmd.Items, cmd.Schedules); err != nil {
+		return nil, err
+	}
+	if err := s.validateScheduledPlaylists(ctx, cmd.OrgId, cmd.Schedules); err != nil {
+		return nil, err
+	}
 	return s.store.Update(ctx, cmd)
 }
 
+// validateScheduledPlaylists checks that the playlists played by the schedules exist.
+func (s *Service) validateScheduledPlaylists(ctx context.Context, orgID int64, schedules []playlist.PlaylistSchedule) error {
+	for _, schedule := range schedules {
+		_, err := s.store.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: schedule.PlaylistUID, OrgId: orgID})
+		if errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return fmt.Errorf("%w: scheduled playlist %q not found", playlist.ErrInvalidSchedule, schedule.PlaylistUID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Service) GetWithoutItems(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*playlist.Playlist, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.GetWithoutItems")
 	defer span.End()
@@ -60,6 +88,7 @@ func (s *Service) Get(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*
 	for i := 0; i < len(rawItems); i++ {
 		items[i].Type = rawItems[i].Type
 		items[i].Value = rawItems[i].Value
+		items[i].Interval = rawItems[i].Interval
 
 		// Add the unused title to the result
 		title := rawItems[i].Title
@@ -67,12 +96,23 @@ func (s *Service) Get(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*
 			items[i].Title = &title
 		}
 	}
+	schedules, err := s.store.GetSchedules(ctx, &playlist.GetPlaylistSchedulesByUidQuery{
+		PlaylistUID: v.UID,
+		OrgId:       q.OrgId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		schedules = nil
+	}
 	return &playlist.PlaylistDTO{
 		Id:        v.Id,
 		Uid:       v.UID,
 		Name:      v.Name,
 		Interval:  v.Interval,
 		Items:     items,
+		Schedules: schedules,
 		CreatedAt: v.CreatedAt,
 		UpdatedAt: v.UpdatedAt,
 		OrgID:     v.OrgId,
@@ -0,0 +1,42 @@
+package playlistimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/playlist"
+)
+
+func TestIntegrationScheduledPlaylistValidation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s := ProvideService(db.InitTestDB(t), tracing.InitializeTracerForTest())
+	items := []playlist.PlaylistItem{{Type: playlist.ItemTypeDashboardByTag, Value: "ops"}}
+
+	night, err := s.Create(ctx, &playlist.CreatePlaylistCommand{Name: "Night", Interval: "10m", OrgId: 1, Items: items})
+	require.NoError(t, err)
+
+	schedules := []playlist.PlaylistSchedule{{StartTime: "20:00", EndTime: "06:00", PlaylistUID: night.UID}}
+	lobby, err := s.Create(ctx, &playlist.CreatePlaylistCommand{Name: "Lobby", Interval: "5m", OrgId: 1, Items: items, Schedules: schedules})
+	require.NoError(t, err)
+
+	t.Run("rejects schedules of unknown playlists", func(t *testing.T) {
+		unknown := []playlist.PlaylistSchedule{{StartTime: "20:00", EndTime: "06:00", PlaylistUID: "unknown"}}
+		_, err := s.Create(ctx, &playlist.CreatePlaylistCommand{Name: "Office", Interval: "5m", OrgId: 1, Items: items, Schedules: unknown})
+		require.ErrorIs(t, err, playlist.ErrInvalidSchedule)
+
+		_, err = s.Update(ctx, &playlist.UpdatePlaylistCommand{UID: lobby.UID, Name: "Lobby", Interval: "5m", OrgId: 1, Items: items, Schedules: unknown})
+		require.ErrorIs(t, err, playlist.ErrInvalidSchedule)
+	})
+
+	t.Run("rejects schedules of playlists of other organizations", func(t *testing.T) {
+		_, err := s.Create(ctx, &playlist.CreatePlaylistCommand{Name: "Office", Interval: "5m", OrgId: 2, Items: items, Schedules: schedules})
+		require.ErrorIs(t, err, playlist.ErrInvalidSchedule)
+	})
+}
@@ -11,6 +11,7 @@ type store interface {
 	Delete(context.Context, *playlist.DeletePlaylistCommand) error
 	Get(context.Context, *playlist.GetPlaylistByUidQuery) (*playlist.Playlist, error)
 	GetItems(context.Context, *playlist.GetPlaylistItemsByUidQuery) ([]playlist.PlaylistItem, error)
+	GetSchedules(context.Context, *playlist.GetPlaylistSchedulesByUidQuery) ([]playlist.PlaylistSchedule, error)
 	List(context.Context, *playlist.GetPlaylistsQuery) (playlist.Playlists, error)
 	Update(context.Context, *playlist.UpdatePlaylistCommand) (*playlist.PlaylistDTO, error)
 
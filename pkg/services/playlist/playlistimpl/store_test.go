@@ -110,6 +110,57 @@ func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Can create playlist with schedules and item intervals", func(t *testing.T) {
+		ctx := context.Background()
+		items := []playlist.PlaylistItem{
+			{Value: "ops", Type: "dashboard_by_folder", Interval: "1m"},
+			{Type: "dashboard_by_starred"},
+		}
+		schedules := []playlist.PlaylistSchedule{
+			{StartTime: "09:00", EndTime: "18:00", Weekdays: []string{"monday", "friday"}, PlaylistUID: "day"},
+			{StartTime: "18:00", EndTime: "09:00", Timezone: "Europe/Paris", PlaylistUID: "night"},
+		}
+		cmd := playlist.CreatePlaylistCommand{Name: "Lobby", Interval: "5m", OrgId: 3, Items: items, Schedules: schedules}
+		p, err := playlistStore.Insert(ctx, &cmd)
+		require.NoError(t, err)
+
+		storedItems, err := playlistStore.GetItems(ctx, &playlist.GetPlaylistItemsByUidQuery{PlaylistUID: p.UID, OrgId: 3})
+		require.NoError(t, err)
+		require.Len(t, storedItems, 2)
+		require.Equal(t, "1m", storedItems[0].Interval)
+
+		storedSchedules, err := playlistStore.GetSchedules(ctx, &playlist.GetPlaylistSchedulesByUidQuery{PlaylistUID: p.UID, OrgId: 3})
+		require.NoError(t, err)
+		require.Len(t, storedSchedules, 2)
+		require.Equal(t, []string{"monday", "friday"}, storedSchedules[0].Weekdays)
+		require.Equal(t, "day", storedSchedules[0].PlaylistUID)
+		require.Equal(t, "Europe/Paris", storedSchedules[1].Timezone)
+		require.Equal(t, "night", storedSchedules[1].PlaylistUID)
+
+		all, err := playlistStore.ListAll(ctx, 3)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		require.Equal(t, "1m", all[0].Items[0].Interval)
+		require.Len(t, all[0].Schedules, 2)
+		require.Equal(t, "09:00", all[0].Schedules[0].StartTime)
+
+		_, err = playlistStore.Update(ctx, &playlist.UpdatePlaylistCommand{
+			UID: p.UID, OrgId: 3, Name: "Lobby", Interval: "5m", Items: items,
+			Schedules: schedules[1:],
+		})
+		require.NoError(t, err)
+		storedSchedules, err = playlistStore.GetSchedules(ctx, &playlist.GetPlaylistSchedulesByUidQuery{PlaylistUID: p.UID, OrgId: 3})
+		require.NoError(t, err)
+		require.Len(t, storedSchedules, 1)
+		require.Equal(t, "night", storedSchedules[0].PlaylistUID)
+
+		err = playlistStore.Delete(ctx, &playlist.DeletePlaylistCommand{UID: p.UID, OrgId: 3})
+		require.NoError(t, err)
+		all, err = playlistStore.ListAll(ctx, 3)
+		require.NoError(t, err)
+		require.Empty(t, all)
+	})
+
 	t.Run("Search playlist", func(t *testing.T) {
 		startTime := time.Now().UnixMilli()
 		time.Sleep(time.Millisecond * 20)
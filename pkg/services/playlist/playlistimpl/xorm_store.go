@@ -3,6 +3,7 @@ package playlistimpl
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -62,12 +63,16 @@ func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComma
 				Value:      item.Value,
 				Order:      order + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
 			})
 		}
 
 		_, err = sess.Insert(&playlistItems)
+		if err != nil {
+			return err
+		}
 
-		return err
+		return insertSchedules(sess, p.Id, cmd.Schedules)
 	})
 	return &p, err
 }
@@ -118,15 +123,47 @@ func (s *sqlStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComma
 				Value:      item.Value,
 				Order:      index + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
 			})
 		}
 
 		_, err = sess.Insert(&playlistItems)
-		return err
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM playlist_schedule WHERE playlist_id = ?", p.Id)
+		if err != nil {
+			return err
+		}
+
+		return insertSchedules(sess, p.Id, cmd.Schedules)
 	})
 	return &dto, err
 }
 
+func insertSchedules(sess *db.Session, playlistID int64, schedules []playlist.PlaylistSchedule) error {
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	playlistSchedules := make([]playlist.PlaylistSchedule, 0, len(schedules))
+	for index, schedule := range schedules {
+		playlistSchedules = append(playlistSchedules, playlist.PlaylistSchedule{
+			PlaylistId:  playlistID,
+			Order:       index + 1,
+			Weekdays:    schedule.Weekdays,
+			StartTime:   schedule.StartTime,
+			EndTime:     schedule.EndTime,
+			Timezone:    schedule.Timezone,
+			PlaylistUID: schedule.PlaylistUID,
+		})
+	}
+
+	_, err := sess.Insert(&playlistSchedules)
+	return err
+}
+
 func (s *sqlStore) Get(ctx context.Context, query *playlist.GetPlaylistByUidQuery) (*playlist.Playlist, error) {
 	if query.UID == "" || query.OrgId == 0 {
 		return nil, playlist.ErrCommandValidationFailed
@@ -165,6 +202,12 @@ func (s *sqlStore) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComma
 
 		var rawItemSQL = "DELETE FROM playlist_item WHERE playlist_id = ?"
 		_, err = sess.Exec(rawItemSQL, playlist.Id)
+		if err != nil {
+			return err
+		}
+
+		var rawScheduleSQL = "DELETE FROM playlist_schedule WHERE playlist_id = ?"
+		_, err = sess.Exec(rawScheduleSQL, playlist.Id)
 
 		return err
 	})
@@ -213,8 +256,9 @@ func (s *sqlStore) ListAll(ctx context.Context, orgId int64) ([]playlist.Playlis
 	var playlistId int64
 	var itemType string
 	var itemValue string
+	var itemInterval string
 
-	rows, err := db.Query(ctx, `SELECT playlist.id,playlist_item.type,playlist_item.value
+	rows, err := db.Query(ctx, `SELECT playlist.id,playlist_item.type,playlist_item.value,playlist_item.`+s.db.Quote("interval")+`
 		FROM playlist_item 
 		JOIN playlist ON playlist_item.playlist_id = playlist.id
 		WHERE playlist.org_id = ?
@@ -223,7 +267,7 @@ func (s *sqlStore) ListAll(ctx context.Context, orgId int64) ([]playlist.Playlis
 		return nil, err
 	}
 	for rows.Next() {
-		err = rows.Scan(&playlistId, &itemType, &itemValue)
+		err = rows.Scan(&playlistId, &itemType, &itemValue, &itemInterval)
 		if err != nil {
 			return nil, err
 		}
@@ -232,12 +276,42 @@ func (s *sqlStore) ListAll(ctx context.Context, orgId int64) ([]playlist.Playlis
 			return nil, fmt.Errorf("could not find playlist by id")
 		}
 		items := append(playlists[idx].Items, playlist.PlaylistItemDTO{
-			Type:  itemType,
-			Value: itemValue,
+			Type:     itemType,
+			Value:    itemValue,
+			Interval: itemInterval,
 		})
 		playlists[idx].Items = items
 	}
-	return playlists, err
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.listSchedules(ctx, orgId)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range schedules {
+		idx, ok := lookup[schedule.PlaylistId]
+		if !ok {
+			continue // the playlist is over the limit of listed playlists
+		}
+		playlists[idx].Schedules = append(playlists[idx].Schedules, schedule)
+	}
+	return playlists, nil
+}
+
+func (s *sqlStore) listSchedules(ctx context.Context, orgId int64) ([]playlist.PlaylistSchedule, error) {
+	schedules := make([]playlist.PlaylistSchedule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("playlist_id IN (SELECT id FROM playlist WHERE org_id = ?)", orgId).Find(&schedules)
+	})
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].PlaylistId != schedules[j].PlaylistId {
+			return schedules[i].PlaylistId < schedules[j].PlaylistId
+		}
+		return schedules[i].Order < schedules[j].Order
+	})
+	return schedules, err
 }
 
 func (s *sqlStore) GetItems(ctx context.Context, query *playlist.GetPlaylistItemsByUidQuery) ([]playlist.PlaylistItem, error) {
@@ -259,3 +333,21 @@ func (s *sqlStore) GetItems(ctx context.Context, query *playlist.GetPlaylistItem
 	})
 	return playlistItems, err
 }
+
+func (s *sqlStore) GetSchedules(ctx context.Context, query *playlist.GetPlaylistSchedulesByUidQuery) ([]playlist.PlaylistSchedule, error) {
+	var schedules = make([]playlist.PlaylistSchedule, 0)
+	if query.PlaylistUID == "" || query.OrgId == 0 {
+		return schedules, playlist.ErrCommandValidationFailed
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		p, err := s.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: query.PlaylistUID, OrgId: query.OrgId})
+		if err != nil {
+			return err
+		}
+
+		err = sess.Where("playlist_id=?", p.Id).Find(&schedules)
+		sort.Slice(schedules, func(i, j int) bool { return schedules[i].Order < schedules[j].Order })
+		return err
+	})
+	return schedules, err
+}
@@ -0,0 +1,155 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+const timeOfDayLayout = "15:04"
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Validate checks the items and schedules of the playlist with the given UID.
+func Validate(uid string, items []PlaylistItem, schedules []PlaylistSchedule) error {
+	if err := ValidateItems(items); err != nil {
+		return err
+	}
+	return ValidateSchedules(uid, schedules)
+}
+
+// IsValidationError returns whether the error is caused by invalid playlist items or schedules.
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrInvalidItem) || errors.Is(err, ErrInvalidSchedule)
+}
+
+// ValidateItems checks the types and intervals of the playlist items.
+func ValidateItems(items []PlaylistItem) error {
+	for _, item := range items {
+		switch item.Type {
+		case ItemTypeDashboardByID, ItemTypeDashboardByUID, ItemTypeDashboardByTag, ItemTypeDashboardByFolder:
+			if item.Value == "" {
+				return fmt.Errorf("%w: %s item without value", ErrInvalidItem, item.Type)
+			}
+		case ItemTypeDashboardByStarred:
+		default:
+			return fmt.Errorf("%w: unknown item type %q", ErrInvalidItem, item.Type)
+		}
+
+		if item.Interval != "" {
+			if _, err := gtime.ParseDuration(item.Interval); err != nil {
+				return fmt.Errorf("%w: invalid interval %q", ErrInvalidItem, item.Interval)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSchedules checks the schedules of the playlist with the given UID.
+func ValidateSchedules(playlistUID string, schedules []PlaylistSchedule) error {
+	for _, s := range schedules {
+		if s.PlaylistUID == "" {
+			return fmt.Errorf("%w: schedule without playlist", ErrInvalidSchedule)
+		}
+		if s.PlaylistUID == playlistUID {
+			return fmt.Errorf("%w: a playlist cannot be scheduled in itself", ErrInvalidSchedule)
+		}
+
+		start, err := parseTimeOfDay(s.StartTime)
+		if err != nil {
+			return err
+		}
+		end, err := parseTimeOfDay(s.EndTime)
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return fmt.Errorf("%w: schedule starting and ending at %s", ErrInvalidSchedule, s.StartTime)
+		}
+
+		for _, day := range s.Weekdays {
+			if _, ok := weekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("%w: unknown weekday %q", ErrInvalidSchedule, day)
+			}
+		}
+
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("%w: unknown timezone %q", ErrInvalidSchedule, s.Timezone)
+		}
+	}
+	return nil
+}
+
+// ActiveSchedule returns the first of the schedules active at the given time, or nil.
+func ActiveSchedule(schedules []PlaylistSchedule, now time.Time) *PlaylistSchedule {
+	for i := range schedules {
+		if schedules[i].IsActive(now) {
+			return &schedules[i]
+		}
+	}
+	return nil
+}
+
+// IsActive returns whether the schedule is active at the given time. The part of a schedule
+// spanning midnight that falls on the next day belongs to the day the schedule starts on.
+func (s PlaylistSchedule) IsActive(now time.Time) bool {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err := parseTimeOfDay(s.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(s.EndTime)
+	if err != nil {
+		return false
+	}
+
+	now = now.In(loc)
+	t := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute + time.Duration(now.Second())*time.Second
+	day := now.Weekday()
+
+	if start < end {
+		return t >= start && t < end && s.startsOn(day)
+	}
+	if t >= start {
+		return s.startsOn(day)
+	}
+	if t < end {
+		return s.startsOn((day + 6) % 7)
+	}
+	return false
+}
+
+func (s PlaylistSchedule) startsOn(day time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range s.Weekdays {
+		if wd, ok := weekdays[strings.ToLower(d)]; ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeOfDay returns the duration since midnight of a time in the 15:04 format.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse(timeOfDayLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time of day %q, expected HH:MM", ErrInvalidSchedule, value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,146 @@
+package playlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleIsActive(t *testing.T) {
+	// Friday 15 March 2024
+	friday := func(hour, min int) time.Time {
+		return time.Date(2024, 3, 15, hour, min, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		desc     string
+		schedule PlaylistSchedule
+		now      time.Time
+		active   bool
+	}{
+		{
+			desc:     "during the day",
+			schedule: PlaylistSchedule{StartTime: "09:00", EndTime: "18:00"},
+			now:      friday(12, 0),
+			active:   true,
+		},
+		{
+			desc:     "includes the start time",
+			schedule: PlaylistSchedule{StartTime: "09:00", EndTime: "18:00"},
+			now:      friday(9, 0),
+			active:   true,
+		},
+		{
+			desc:     "excludes the end time",
+			schedule: PlaylistSchedule{StartTime: "09:00", EndTime: "18:00"},
+			now:      friday(18, 0),
+			active:   false,
+		},
+		{
+			desc:     "on a matching weekday",
+			schedule: PlaylistSchedule{StartTime: "09:00", EndTime: "18:00", Weekdays: []string{"monday", "Friday"}},
+			now:      friday(12, 0),
+			active:   true,
+		},
+		{
+			desc:     "on another weekday",
+			schedule: PlaylistSchedule{StartTime: "09:00", EndTime: "18:00", Weekdays: []string{"saturday", "sunday"}},
+			now:      friday(12, 0),
+			active:   false,
+		},
+		{
+			desc:     "in the schedule timezone",
+			schedule: PlaylistSchedule{StartTime: "09:00", EndTime: "18:00", Timezone: "America/New_York"},
+			now:      friday(12, 0),
+			active:   false,
+		},
+		{
+			desc:     "before midnight of a schedule spanning midnight",
+			schedule: PlaylistSchedule{StartTime: "18:00", EndTime: "09:00", Weekdays: []string{"friday"}},
+			now:      friday(23, 0),
+			active:   true,
+		},
+		{
+			desc:     "after midnight of a schedule spanning midnight started the previous day",
+			schedule: PlaylistSchedule{StartTime: "18:00", EndTime: "09:00", Weekdays: []string{"thursday"}},
+			now:      friday(6, 0),
+			active:   true,
+		},
+		{
+			desc:     "after midnight of a schedule spanning midnight not started the previous day",
+			schedule: PlaylistSchedule{StartTime: "18:00", EndTime: "09:00", Weekdays: []string{"friday"}},
+			now:      friday(6, 0),
+			active:   false,
+		},
+		{
+			desc:     "between the end and start of a schedule spanning midnight",
+			schedule: PlaylistSchedule{StartTime: "18:00", EndTime: "09:00"},
+			now:      friday(12, 0),
+			active:   false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.active, tc.schedule.IsActive(tc.now))
+		})
+	}
+}
+
+func TestActiveSchedule(t *testing.T) {
+	schedules := []PlaylistSchedule{
+		{StartTime: "09:00", EndTime: "18:00", PlaylistUID: "day"},
+		{StartTime: "18:00", EndTime: "09:00", PlaylistUID: "night"},
+		{StartTime: "00:00", EndTime: "23:59", PlaylistUID: "never"},
+	}
+
+	require.Equal(t, "day", ActiveSchedule(schedules, time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)).PlaylistUID)
+	require.Equal(t, "night", ActiveSchedule(schedules, time.Date(2024, 3, 15, 3, 0, 0, 0, time.UTC)).PlaylistUID)
+	require.Nil(t, ActiveSchedule(nil, time.Now()))
+}
+
+func TestValidate(t *testing.T) {
+	valid := PlaylistSchedule{StartTime: "09:00", EndTime: "18:00", Weekdays: []string{"Monday"}, Timezone: "Europe/Paris", PlaylistUID: "day"}
+	require.NoError(t, Validate("lobby", []PlaylistItem{
+		{Type: ItemTypeDashboardByFolder, Value: "folder", Interval: "1m"},
+		{Type: ItemTypeDashboardByStarred},
+	}, []PlaylistSchedule{valid}))
+
+	itemTestCases := []struct {
+		desc string
+		item PlaylistItem
+	}{
+		{desc: "unknown type", item: PlaylistItem{Type: "dashboard_by_query", Value: "q"}},
+		{desc: "missing value", item: PlaylistItem{Type: ItemTypeDashboardByTag}},
+		{desc: "invalid interval", item: PlaylistItem{Type: ItemTypeDashboardByUID, Value: "uid", Interval: "often"}},
+	}
+	for _, tc := range itemTestCases {
+		t.Run("rejects items with "+tc.desc, func(t *testing.T) {
+			err := Validate("lobby", []PlaylistItem{tc.item}, nil)
+			require.ErrorIs(t, err, ErrInvalidItem)
+			require.True(t, IsValidationError(err))
+		})
+	}
+
+	scheduleTestCases := []struct {
+		desc   string
+		modify func(s *PlaylistSchedule)
+	}{
+		{desc: "missing playlist", modify: func(s *PlaylistSchedule) { s.PlaylistUID = "" }},
+		{desc: "the playlist itself", modify: func(s *PlaylistSchedule) { s.PlaylistUID = "lobby" }},
+		{desc: "invalid start time", modify: func(s *PlaylistSchedule) { s.StartTime = "9am" }},
+		{desc: "invalid end time", modify: func(s *PlaylistSchedule) { s.EndTime = "24:00" }},
+		{desc: "empty range", modify: func(s *PlaylistSchedule) { s.EndTime = s.StartTime }},
+		{desc: "unknown weekday", modify: func(s *PlaylistSchedule) { s.Weekdays = []string{"someday"} }},
+		{desc: "unknown timezone", modify: func(s *PlaylistSchedule) { s.Timezone = "Mars/Olympus" }},
+	}
+	for _, tc := range scheduleTestCases {
+		t.Run("rejects schedules with "+tc.desc, func(t *testing.T) {
+			s := valid
+			tc.modify(&s)
+			err := Validate("lobby", nil, []PlaylistSchedule{s})
+			require.ErrorIs(t, err, ErrInvalidSchedule)
+			require.True(t, IsValidationError(err))
+		})
+	}
+}
@@ -104,6 +104,10 @@ func (cr *configReader) readConfig(ctx context.Context) ([]*config, error) {
 			dashboard.Type = "file"
 		}
 
+		if dashboard.SyncUIUpdatesToFile && dashboard.Type != "file" {
+			return nil, fmt.Errorf("failed to provision dashboards with %q reader: syncUiUpdatesToFile is only supported by file readers", dashboard.Name)
+		}
+
 		if dashboard.UpdateIntervalSeconds == 0 {
 			dashboard.UpdateIntervalSeconds = 10
 		}
@@ -20,11 +20,13 @@ type DashboardProvisioner interface {
 	PollChanges(ctx context.Context)
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetSyncUIUpdatesToFileFromConfig(name string) bool
+	SaveDashboardToFile(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error)
 	CleanUpOrphanedDashboards(ctx context.Context)
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
-type DashboardProvisionerFactory func(context.Context, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
+type DashboardProvisionerFactory func(context.Context, string, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
 
 // Provisioner is responsible for syncing dashboard from disk to Grafana's database.
 type Provisioner struct {
@@ -39,8 +41,8 @@ func (provider *Provisioner) HasDashboardSources() bool {
 	return len(provider.fileReaders) > 0
 }
 
-// New returns a new DashboardProvisioner
-func New(ctx context.Context, configDirectory string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
+// New returns a new DashboardProvisioner. Git providers check out their repositories under dataPath.
+func New(ctx context.Context, configDirectory string, dataPath string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
 	logger := log.New("provisioning.dashboard")
 	cfgReader := &configReader{path: configDirectory, log: logger, orgService: orgService}
 	configs, err := cfgReader.readConfig(ctx)
@@ -48,7 +50,7 @@ func New(ctx context.Context, configDirectory string, provisioner dashboards.Das
 		return nil, fmt.Errorf("%v: %w", "Failed to read dashboards config", err)
 	}
 
-	fileReaders, err := getFileReaders(configs, logger, provisioner, dashboardStore, folderService)
+	fileReaders, err := getFileReaders(configs, dataPath, logger, provisioner, dashboardStore, folderService)
 	if err != nil {
 		return nil, fmt.Errorf("%v: %w", "Failed to initialize file readers", err)
 	}
@@ -124,14 +126,46 @@ func (provider *Provisioner) GetProvisionerResolvedPath(name string) string {
 func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	for _, config := range provider.configs {
 		if config.Name == name {
-			return config.AllowUIUpdates
+			return config.AllowUIUpdates || config.SyncUIUpdatesToFile
 		}
 	}
 	return false
 }
 
+// GetSyncUIUpdatesToFileFromConfig return if a dashboard provisioner writes updates from the UI back to the
+// provisioning files
+func (provider *Provisioner) GetSyncUIUpdatesToFileFromConfig(name string) bool {
+	for _, config := range provider.configs {
+		if config.Name == name {
+			return config.SyncUIUpdatesToFile
+		}
+	}
+	return false
+}
+
+// SaveDashboardToFile saves a dashboard from the UI with save and writes it back to its provisioning file, without
+// letting the provisioner provision the file in between. It returns ErrDashboardFileConflict without saving the
+// dashboard if the file was changed on disk since it was last provisioned.
+func (provider *Provisioner) SaveDashboardToFile(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error) {
+	reader, err := provider.getFileReader(provisioning.Name)
+	if err != nil {
+		return nil, err
+	}
+	return reader.saveDashboardToFile(ctx, provisioning, dash, save)
+}
+
+func (provider *Provisioner) getFileReader(name string) (*FileReader, error) {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == name {
+			return reader, nil
+		}
+	}
+	return nil, ErrDashboardFileSyncDisabled.Errorf("dashboard provisioner %s does not exist", name)
+}
+
 func getFileReaders(
 	configs []*config,
+	dataPath string,
 	logger log.Logger,
 	service dashboards.DashboardProvisioningService,
 	store utils.DashboardStore,
@@ -153,6 +187,19 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(
+				config,
+				dataPath,
+				logger.New("type", config.Type, "name", config.Name),
+				service,
+				store,
+				folderService,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
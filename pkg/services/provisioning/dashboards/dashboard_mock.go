@@ -1,22 +1,30 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
-	Provision                   []any
-	PollChanges                 []any
-	GetProvisionerResolvedPath  []any
-	GetAllowUIUpdatesFromConfig []any
+	Provision                        []any
+	PollChanges                      []any
+	GetProvisionerResolvedPath       []any
+	GetAllowUIUpdatesFromConfig      []any
+	GetSyncUIUpdatesToFileFromConfig []any
+	SaveDashboardToFile              []any
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
 type ProvisionerMock struct {
-	Calls                           *calls
-	ProvisionFunc                   func(ctx context.Context) error
-	PollChangesFunc                 func(ctx context.Context)
-	GetProvisionerResolvedPathFunc  func(name string) string
-	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	Calls                                *calls
+	ProvisionFunc                        func(ctx context.Context) error
+	PollChangesFunc                      func(ctx context.Context)
+	GetProvisionerResolvedPathFunc       func(name string) string
+	GetAllowUIUpdatesFromConfigFunc      func(name string) bool
+	GetSyncUIUpdatesToFileFromConfigFunc func(name string) bool
+	SaveDashboardToFileFunc              func(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error)
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -65,5 +73,23 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// GetSyncUIUpdatesToFileFromConfig is a mock implementation of `Provisioner.GetSyncUIUpdatesToFileFromConfig`
+func (dpm *ProvisionerMock) GetSyncUIUpdatesToFileFromConfig(name string) bool {
+	dpm.Calls.GetSyncUIUpdatesToFileFromConfig = append(dpm.Calls.GetSyncUIUpdatesToFileFromConfig, name)
+	if dpm.GetSyncUIUpdatesToFileFromConfigFunc != nil {
+		return dpm.GetSyncUIUpdatesToFileFromConfigFunc(name)
+	}
+	return false
+}
+
+// SaveDashboardToFile is a mock implementation of `Provisioner.SaveDashboardToFile`
+func (dpm *ProvisionerMock) SaveDashboardToFile(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error) {
+	dpm.Calls.SaveDashboardToFile = append(dpm.Calls.SaveDashboardToFile, provisioning)
+	if dpm.SaveDashboardToFileFunc != nil {
+		return dpm.SaveDashboardToFileFunc(ctx, provisioning, dash, save)
+	}
+	return save()
+}
+
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
@@ -39,8 +39,13 @@ type FileReader struct {
 	dashboardStore               utils.DashboardStore
 	FoldersFromFilesStructure    bool
 	folderService                folder.Service
+	// git is set when dashboards are read from a git repository checkout.
+	git *gitRepository
+	// commit is the commit SHA of the last git checkout.
+	commit string
 
 	mux                     sync.RWMutex
+	syncMux                 sync.Mutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
 }
@@ -94,6 +99,18 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	fr.syncMux.Lock()
+	defer fr.syncMux.Unlock()
+
+	if fr.git != nil {
+		commit, err := fr.git.sync(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to sync git repository: %w", err)
+		}
+		fr.log.Debug("Synced git repository", "url", fr.git.url, "commit", commit)
+		fr.commit = commit
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -286,6 +303,9 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Provisioning).Inc()
 		// nolint:staticcheck
 		fr.log.Debug("provisioned dashboard is up to date", "provisioner", fr.Cfg.Name, "file", path, "folderId", dash.Dashboard.FolderID, "folderUid", dash.Dashboard.FolderUID)
+		if err := fr.saveCommit(ctx, provisionedData); err != nil {
+			fr.log.Error("failed to save commit of provisioned dashboard", "provisioner", fr.Cfg.Name, "file", path, "error", err)
+		}
 		return provisioningMetadata, nil
 	}
 
@@ -307,6 +327,7 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 			Name:       fr.Cfg.Name,
 			Updated:    resolvedFileInfo.ModTime().Unix(),
 			CheckSum:   jsonFile.checkSum,
+			CommitSHA:  fr.commit,
 		}
 		_, err := fr.dashboardProvisioningService.SaveProvisionedDashboard(ctx, dash, dp)
 		if err != nil {
@@ -322,6 +343,17 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 	return provisioningMetadata, nil
 }
 
+// saveCommit records the synced commit for an unchanged dashboard of a git repository, so
+// that all dashboards of the repository point to the same commit.
+func (fr *FileReader) saveCommit(ctx context.Context, provisionedData *dashboards.DashboardProvisioning) error {
+	if fr.git == nil || provisionedData == nil || provisionedData.CommitSHA == fr.commit || fr.isDatabaseAccessRestricted() {
+		return nil
+	}
+	dp := *provisionedData
+	dp.CommitSHA = fr.commit
+	return fr.dashboardProvisioningService.SaveProvisionedDashboardData(ctx, &dp)
+}
+
 func getProvisionedDashboardsByPath(ctx context.Context, service dashboards.DashboardProvisioningService, name string) (
 	map[string]*dashboards.DashboardProvisioning, error) {
 	arr, err := service.GetProvisionedDashboardData(ctx, name)
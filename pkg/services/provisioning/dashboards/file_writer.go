@@ -0,0 +1,365 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	// ErrDashboardFileConflict is returned when the provisioning file of a dashboard was changed on disk since it was
+	// last provisioned.
+	ErrDashboardFileConflict = errutil.Conflict("provisioning.dashboard-file-conflict",
+		errutil.WithPublicMessage("The provisioning file of the dashboard was changed on disk, reload the dashboard before saving it"))
+	// ErrDashboardFileSyncDisabled is returned when a dashboard is written back to the file of a provisioner that does
+	// not sync UI updates to files.
+	ErrDashboardFileSyncDisabled = errutil.BadRequest("provisioning.dashboard-file-sync-disabled")
+)
+
+// checkFileConflict returns ErrDashboardFileConflict if the provisioning file of the dashboard does not have the
+// content it was last provisioned from.
+func (fr *FileReader) checkFileConflict(provisioning *dashboards.DashboardProvisioning) error {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path comes from the provisioned dashboard files.
+	content, err := os.ReadFile(provisioning.ExternalID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrDashboardFileConflict.Errorf("provisioning file %s does not exist", provisioning.ExternalID)
+		}
+		return err
+	}
+
+	checkSum, err := util.Md5SumString(string(content))
+	if err != nil {
+		return err
+	}
+	if checkSum != provisioning.CheckSum {
+		return ErrDashboardFileConflict.Errorf("provisioning file %s was changed on disk", provisioning.ExternalID)
+	}
+	return nil
+}
+
+// saveDashboardToFile saves the dashboard with save and writes it back to its provisioning file. The poller can't
+// provision the file until both are done. The file is written before the dashboard is saved and restored if the
+// save fails, so that the file and the database don't diverge. The file is replaced atomically and keeps its
+// indentation and the order of its keys, new keys are added in alphabetical order. The provisioning data is
+// updated, so that the file is not provisioned again.
+func (fr *FileReader) saveDashboardToFile(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error) {
+	if !fr.Cfg.SyncUIUpdatesToFile || fr.git != nil {
+		return nil, ErrDashboardFileSyncDisabled.Errorf("provisioner %s does not sync UI updates to files", fr.Cfg.Name)
+	}
+
+	fr.syncMux.Lock()
+	defer fr.syncMux.Unlock()
+
+	if err := fr.checkFileConflict(provisioning); err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.EvalSymlinks(provisioning.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path comes from the provisioned dashboard files.
+	previous, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := formatDashboardFile(previous, dash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format dashboard %s: %w", dash.UID, err)
+	}
+	if err := writeFileAtomically(path, content); err != nil {
+		return nil, fmt.Errorf("failed to write provisioning file %s: %w", path, err)
+	}
+
+	saved, err := save()
+	if err != nil {
+		if restoreErr := writeFileAtomically(path, previous); restoreErr != nil {
+			fr.log.Error("Failed to restore provisioning file after the dashboard failed to save", "file", path, "error", restoreErr)
+		}
+		return nil, err
+	}
+
+	// the saved dashboard can differ from the one written, for example by its version
+	if savedContent, err := formatDashboardFile(previous, saved); err != nil {
+		fr.log.Warn("Failed to format saved dashboard", "uid", saved.UID, "error", err)
+	} else if !bytes.Equal(savedContent, content) {
+		if err := writeFileAtomically(path, savedContent); err != nil {
+			fr.log.Warn("Failed to write saved dashboard to provisioning file", "file", path, "error", err)
+		} else {
+			content = savedContent
+		}
+	}
+
+	fr.log.Debug("Wrote dashboard to provisioning file", "provisioner", fr.Cfg.Name, "file", provisioning.ExternalID, "uid", saved.UID)
+	// the dashboard is saved, the file is only provisioned again if the provisioning data isn't updated
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		fr.log.Warn("Failed to update provisioning data", "file", path, "error", err)
+		return saved, nil
+	}
+	checkSum, err := util.Md5SumString(string(content))
+	if err != nil {
+		fr.log.Warn("Failed to update provisioning data", "file", path, "error", err)
+		return saved, nil
+	}
+	provisioning.CheckSum = checkSum
+	provisioning.Updated = fileInfo.ModTime().Unix()
+	if err := fr.dashboardProvisioningService.SaveProvisionedDashboardData(ctx, provisioning); err != nil {
+		fr.log.Warn("Failed to update provisioning data", "file", path, "error", err)
+	}
+	return saved, nil
+}
+
+// formatDashboardFile returns the JSON of the dashboard formatted like the previous content of its file.
+func formatDashboardFile(previous []byte, dash *dashboards.Dashboard) ([]byte, error) {
+	order, err := readKeyOrder(json.NewDecoder(bytes.NewReader(previous)))
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := dash.Data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	// the dashboard id and version are specific to the database the dashboard was saved in, the version is only
+	// kept up to date if the file has one
+	if m, ok := value.(map[string]any); ok {
+		delete(m, "id")
+		if order.has("id") {
+			m["id"] = nil
+		}
+		if !order.has("version") {
+			delete(m, "version")
+		}
+	}
+
+	var compact bytes.Buffer
+	if err := writeOrderedJSON(&compact, value, order); err != nil {
+		return nil, err
+	}
+
+	var result bytes.Buffer
+	if indent := detectIndent(previous); indent != "" {
+		if err := json.Indent(&result, compact.Bytes(), "", indent); err != nil {
+			return nil, err
+		}
+	} else {
+		result = compact
+	}
+	if bytes.HasSuffix(previous, []byte("\n")) {
+		result.WriteByte('\n')
+	}
+	return result.Bytes(), nil
+}
+
+// detectIndent returns the indentation of the first indented line of content, or an empty string if the content
+// is on a single line.
+func detectIndent(content []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	for _, line := range lines[1:] {
+		line = strings.TrimRight(line, "\r")
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != line {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	if len(lines) > 1 {
+		return "  "
+	}
+	return ""
+}
+
+// jsonKeyOrder is the order of the keys of a JSON value and of its nested values.
+type jsonKeyOrder struct {
+	keys   []string
+	fields map[string]*jsonKeyOrder
+	items  []*jsonKeyOrder
+}
+
+// readKeyOrder reads the next JSON value of the decoder and returns the order of its keys.
+func readKeyOrder(decoder *json.Decoder) (*jsonKeyOrder, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil, nil
+	}
+
+	order := &jsonKeyOrder{fields: map[string]*jsonKeyOrder{}}
+	for decoder.More() {
+		if delim == '{' {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := token.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected key %v", token)
+			}
+			field, err := readKeyOrder(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := order.fields[key]; !ok {
+				order.keys = append(order.keys, key)
+			}
+			order.fields[key] = field
+		} else {
+			item, err := readKeyOrder(decoder)
+			if err != nil {
+				return nil, err
+			}
+			order.items = append(order.items, item)
+		}
+	}
+
+	// closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (o *jsonKeyOrder) has(key string) bool {
+	if o == nil {
+		return false
+	}
+	_, ok := o.fields[key]
+	return ok
+}
+
+func (o *jsonKeyOrder) field(key string) *jsonKeyOrder {
+	if o == nil {
+		return nil
+	}
+	return o.fields[key]
+}
+
+func (o *jsonKeyOrder) item(i int) *jsonKeyOrder {
+	if o == nil || i >= len(o.items) {
+		return nil
+	}
+	return o.items[i]
+}
+
+// sortKeys returns the keys of value, the keys known by the order first.
+func (o *jsonKeyOrder) sortKeys(value map[string]any) []string {
+	keys := make([]string, 0, len(value))
+	if o != nil {
+		for _, key := range o.keys {
+			if _, ok := value[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	added := make([]string, 0)
+	for key := range value {
+		if !o.has(key) {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	return append(keys, added...)
+}
+
+// writeOrderedJSON writes value as compact JSON, with its keys ordered as in order.
+func writeOrderedJSON(buf *bytes.Buffer, value any, order *jsonKeyOrder) error {
+	switch v := value.(type) {
+	case map[string]any:
+		buf.WriteByte('{')
+		for i, key := range order.sortKeys(v) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONValue(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeOrderedJSON(buf, v[key], order.field(key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeOrderedJSON(buf, item, order.item(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		return writeJSONValue(buf, v)
+	}
+	return nil
+}
+
+func writeJSONValue(buf *bytes.Buffer, value any) error {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
+
+// writeFileAtomically replaces the file at path with content, keeping its permissions. The content is written to a
+// temporary file in the same directory first, which is then renamed to path.
+func writeFileAtomically(path string, content []byte) error {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the temporary file does not exist anymore when it was renamed
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), fileInfo.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,186 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestFormatDashboardFile(t *testing.T) {
+	t.Run("keeps the indentation and the order of the keys", func(t *testing.T) {
+		previous := []byte("{\n    \"title\": \"Old\",\n    \"id\": null,\n    \"panels\": [\n        {\"type\": \"graph\", \"id\": 1}\n    ],\n    \"uid\": \"abc\",\n    \"version\": 2\n}\n")
+		dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{
+			"id":      int64(12),
+			"uid":     "abc",
+			"title":   "New <title>",
+			"version": int64(3),
+			"panels": []any{
+				map[string]any{"id": 1, "type": "timeseries", "gridPos": map[string]any{"y": 0, "x": 0}},
+				map[string]any{"type": "text", "id": 2},
+			},
+		}))
+
+		content, err := formatDashboardFile(previous, dash)
+		require.NoError(t, err)
+		require.Equal(t, `{
+    "title": "New <title>",
+    "id": null,
+    "panels": [
+        {
+            "type": "timeseries",
+            "id": 1,
+            "gridPos": {
+                "x": 0,
+                "y": 0
+            }
+        },
+        {
+            "id": 2,
+            "type": "text"
+        }
+    ],
+    "uid": "abc",
+    "version": 3
+}
+`, string(content))
+		require.Equal(t, int64(12), dash.Data.Get("id").MustInt64())
+	})
+
+	t.Run("keeps single line files on a single line", func(t *testing.T) {
+		dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"id": int64(1), "title": "New", "uid": "abc"}))
+
+		content, err := formatDashboardFile([]byte(`{"uid":"abc","title":"Old"}`), dash)
+		require.NoError(t, err)
+		require.Equal(t, `{"uid":"abc","title":"New"}`, string(content))
+	})
+}
+
+func TestWriteDashboardFile(t *testing.T) {
+	setup := func(t *testing.T, syncUIUpdatesToFile bool) (*FileReader, *dashboards.FakeDashboardProvisioning, *dashboards.DashboardProvisioning) {
+		t.Helper()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "dashboard.json")
+		content := "{\n  \"uid\": \"abc\",\n  \"title\": \"Old\"\n}\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0640))
+		checkSum, err := util.Md5SumString(content)
+		require.NoError(t, err)
+
+		cfg := &config{
+			Name:                configName,
+			Type:                "file",
+			OrgID:               1,
+			Options:             map[string]any{"path": dir},
+			SyncUIUpdatesToFile: syncUIUpdatesToFile,
+		}
+		fakeService := dashboards.NewFakeDashboardProvisioning(t)
+		reader, err := NewDashboardFileReader(cfg, log.New("test-logger"), fakeService, nil, nil)
+		require.NoError(t, err)
+
+		return reader, fakeService, &dashboards.DashboardProvisioning{
+			DashboardID: 2,
+			Name:        configName,
+			ExternalID:  path,
+			CheckSum:    checkSum,
+		}
+	}
+	dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"id": int64(2), "uid": "abc", "title": "New"}))
+
+	saved := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"id": int64(2), "uid": "abc", "title": "New", "version": 3}))
+
+	t.Run("writes the dashboard and updates the provisioning data", func(t *testing.T) {
+		reader, fakeService, provisioning := setup(t, true)
+		fakeService.On("SaveProvisionedDashboardData", mock.Anything, mock.Anything).Return(nil).Once()
+
+		result, err := reader.saveDashboardToFile(context.Background(), provisioning, dash, func() (*dashboards.Dashboard, error) {
+			// the file is written before the dashboard is saved
+			content, err := os.ReadFile(provisioning.ExternalID)
+			require.NoError(t, err)
+			require.Equal(t, "{\n  \"uid\": \"abc\",\n  \"title\": \"New\"\n}\n", string(content))
+			return saved, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, saved, result)
+
+		content, err := os.ReadFile(provisioning.ExternalID)
+		require.NoError(t, err)
+		require.Equal(t, "{\n  \"uid\": \"abc\",\n  \"title\": \"New\"\n}\n", string(content))
+		checkSum, err := util.Md5SumString(string(content))
+		require.NoError(t, err)
+		require.Equal(t, checkSum, provisioning.CheckSum)
+
+		fileInfo, err := os.Stat(provisioning.ExternalID)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0640), fileInfo.Mode().Perm())
+		entries, err := os.ReadDir(filepath.Dir(provisioning.ExternalID))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		require.NoError(t, reader.checkFileConflict(provisioning))
+	})
+
+	t.Run("restores the file when the dashboard fails to save", func(t *testing.T) {
+		reader, _, provisioning := setup(t, true)
+		previous, err := os.ReadFile(provisioning.ExternalID)
+		require.NoError(t, err)
+
+		_, err = reader.saveDashboardToFile(context.Background(), provisioning, dash, func() (*dashboards.Dashboard, error) {
+			return nil, dashboards.ErrDashboardVersionMismatch
+		})
+		require.ErrorIs(t, err, dashboards.ErrDashboardVersionMismatch)
+
+		content, err := os.ReadFile(provisioning.ExternalID)
+		require.NoError(t, err)
+		require.Equal(t, string(previous), string(content))
+		require.NoError(t, reader.checkFileConflict(provisioning))
+	})
+
+	t.Run("does not save the dashboard when the file was changed on disk", func(t *testing.T) {
+		reader, _, provisioning := setup(t, true)
+		changed := "{\"uid\": \"abc\", \"title\": \"Changed on disk\"}"
+		require.NoError(t, os.WriteFile(provisioning.ExternalID, []byte(changed), 0640))
+
+		require.ErrorIs(t, reader.checkFileConflict(provisioning), ErrDashboardFileConflict)
+		_, err := reader.saveDashboardToFile(context.Background(), provisioning, dash, func() (*dashboards.Dashboard, error) {
+			t.Fatal("the dashboard must not be saved")
+			return nil, nil
+		})
+		require.ErrorIs(t, err, ErrDashboardFileConflict)
+
+		content, err := os.ReadFile(provisioning.ExternalID)
+		require.NoError(t, err)
+		require.Equal(t, changed, string(content))
+	})
+
+	t.Run("does not save the dashboard when the provisioner does not sync UI updates", func(t *testing.T) {
+		reader, _, provisioning := setup(t, false)
+
+		_, err := reader.saveDashboardToFile(context.Background(), provisioning, dash, func() (*dashboards.Dashboard, error) {
+			t.Fatal("the dashboard must not be saved")
+			return nil, nil
+		})
+		require.ErrorIs(t, err, ErrDashboardFileSyncDisabled)
+	})
+
+	t.Run("the provisioner can't provision the file while the dashboard is saved", func(t *testing.T) {
+		reader, fakeService, provisioning := setup(t, true)
+		fakeService.On("SaveProvisionedDashboardData", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := reader.saveDashboardToFile(context.Background(), provisioning, dash, func() (*dashboards.Dashboard, error) {
+			require.False(t, reader.syncMux.TryLock())
+			return saved, nil
+		})
+		require.NoError(t, err)
+		require.True(t, reader.syncMux.TryLock())
+		reader.syncMux.Unlock()
+	})
+}
@@ -0,0 +1,247 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// gitRepository keeps a local checkout of a git repository in sync with the remote.
+// It shells out to the git binary so that the system git configuration (SSH
+// agent, known hosts, proxies) is respected.
+type gitRepository struct {
+	url        string
+	ref        string
+	clonePath  string
+	username   string
+	password   string
+	sshKeyFile string
+	// managed is set when clonePath is the default directory under the data path.
+	managed bool
+	log     log.Logger
+}
+
+// gitCheckoutMarker is created in the .git directory of checkouts initialized by Grafana.
+const gitCheckoutMarker = "grafana-provisioning"
+
+// NewDashboardGitReader returns a FileReader reading dashboards from a local
+// checkout of a git repository. The checkout is updated before every walk.
+//
+// Options:
+// * url - remote URL or path to a local (bare) repository, required
+// * ref - branch, tag or commit to check out, defaults to the remote HEAD
+// * path - path of the dashboards inside the repository, defaults to the repository root
+// * clonePath - where the repository is checked out, defaults to a directory under dataPath
+// * username, password - credentials for HTTPS remotes
+// * sshKeyFile - private key for SSH remotes
+// * foldersFromFilesStructure - same as for the file provider
+func NewDashboardGitReader(cfg *config, dataPath string, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service) (*FileReader, error) {
+	url, _ := cfg.Options["url"].(string)
+	if url == "" {
+		return nil, fmt.Errorf("failed to load dashboards, url param is not a string")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git provider requires git binary: %w", err)
+	}
+
+	repo := &gitRepository{url: url, log: log}
+	repo.ref, _ = cfg.Options["ref"].(string)
+	// url and ref are passed to git as arguments, they must not be taken for options.
+	if strings.HasPrefix(repo.url, "-") {
+		return nil, fmt.Errorf("url param must not start with a dash")
+	}
+	if strings.HasPrefix(repo.ref, "-") {
+		return nil, fmt.Errorf("ref param must not start with a dash")
+	}
+	repo.username, _ = cfg.Options["username"].(string)
+	repo.password, _ = cfg.Options["password"].(string)
+	repo.sshKeyFile, _ = cfg.Options["sshKeyFile"].(string)
+	repo.clonePath, _ = cfg.Options["clonePath"].(string)
+	if repo.clonePath == "" {
+		if dataPath == "" {
+			return nil, fmt.Errorf("clonePath param is required when the data path is not set")
+		}
+		repo.clonePath = filepath.Join(dataPath, "provisioning-git", strconv.FormatInt(cfg.OrgID, 10), safeDirName(cfg.Name))
+		repo.managed = true
+	}
+
+	subPath, _ := cfg.Options["path"].(string)
+	if filepath.IsAbs(subPath) || strings.HasPrefix(filepath.Clean(subPath), "..") {
+		return nil, fmt.Errorf("path param must be relative to the repository root")
+	}
+
+	// Folder options are validated the same way as for file provider.
+	fileCfg := *cfg
+	fileCfg.Options = make(map[string]any, len(cfg.Options))
+	for k, v := range cfg.Options {
+		fileCfg.Options[k] = v
+	}
+	fileCfg.Options["path"] = filepath.Join(repo.clonePath, subPath)
+	reader, err := NewDashboardFileReader(&fileCfg, log, service, dashboardStore, folderService)
+	if err != nil {
+		return nil, err
+	}
+	reader.Cfg = cfg
+	reader.git = repo
+	return reader, nil
+}
+
+var unsafeDirNameChars = regexp.MustCompile(`[^A-Za-z0-9_\-.]`)
+
+func safeDirName(name string) string {
+	return unsafeDirNameChars.ReplaceAllString(name, "_")
+}
+
+// sync fetches the configured ref and checks it out, returns the commit SHA of the checkout.
+func (r *gitRepository) sync(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(r.clonePath, ".git")); os.IsNotExist(err) {
+		if err := r.init(ctx); err != nil {
+			return "", err
+		}
+	} else {
+		if err := r.checkCheckout(); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, "remote", "set-url", "--", "origin", r.url); err != nil {
+			return "", err
+		}
+	}
+
+	ref := r.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := r.git(ctx, "fetch", "--quiet", "--force", "--depth=1", "--", "origin", ref); err != nil {
+		return "", err
+	}
+	if _, err := r.git(ctx, "checkout", "--quiet", "--force", "--detach", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	// Untracked files are only removed from checkouts Grafana initialized in an empty directory.
+	if r.createdByGrafana() {
+		if _, err := r.git(ctx, "clean", "--quiet", "--force", "-d", "-x"); err != nil {
+			return "", err
+		}
+	}
+	commit, err := r.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// init initializes the checkout, the directory is created only readable by the Grafana user.
+func (r *gitRepository) init(ctx context.Context) error {
+	r.log.Debug("Initializing git checkout", "path", r.clonePath)
+	entries, err := os.ReadDir(r.clonePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	empty := len(entries) == 0
+	if r.managed && !empty {
+		return fmt.Errorf("refusing to use git checkout %s not created by Grafana", r.clonePath)
+	}
+	if err := os.MkdirAll(r.clonePath, 0700); err != nil {
+		return err
+	}
+	if r.managed {
+		// MkdirAll keeps the permissions of an existing directory.
+		if err := os.Chmod(r.clonePath, 0700); err != nil {
+			return err
+		}
+	}
+	if _, err := r.git(ctx, "init", "--quiet"); err != nil {
+		return err
+	}
+	if empty {
+		if err := os.WriteFile(filepath.Join(r.clonePath, ".git", gitCheckoutMarker), nil, 0600); err != nil {
+			return err
+		}
+	}
+	_, err = r.git(ctx, "remote", "add", "--", "origin", r.url)
+	return err
+}
+
+// checkCheckout refuses to use an existing checkout in the default directory that Grafana did not
+// create, its git configuration and hooks would be run by Grafana.
+func (r *gitRepository) checkCheckout() error {
+	if !r.managed {
+		return nil
+	}
+	if !r.createdByGrafana() {
+		return fmt.Errorf("refusing to use git checkout %s not created by Grafana", r.clonePath)
+	}
+	info, err := os.Stat(r.clonePath)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("refusing to use git checkout %s accessible by other users", r.clonePath)
+	}
+	return nil
+}
+
+func (r *gitRepository) createdByGrafana() bool {
+	_, err := os.Stat(filepath.Join(r.clonePath, ".git", gitCheckoutMarker))
+	return err == nil
+}
+
+func (r *gitRepository) git(ctx context.Context, args ...string) (string, error) {
+	// nolint:gosec
+	// We can ignore the gosec G204 warning as the arguments come from the provisioning configuration file.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.clonePath
+	cmd.Env = append(os.Environ(), r.env()...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// env returns environment variables passing credentials to git. Credentials are
+// not passed as arguments so that they are not visible in the process list.
+func (r *gitRepository) env() []string {
+	var config [][2]string
+	if r.username != "" || r.password != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(r.username + ":" + r.password))
+		config = append(config, [2]string{"http.extraHeader", "Authorization: Basic " + auth})
+	}
+	if r.sshKeyFile != "" {
+		// The ssh command is run by a shell.
+		config = append(config, [2]string{"core.sshCommand", "ssh -i " + shellQuote(r.sshKeyFile) + " -o IdentitiesOnly=yes"})
+	}
+
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if len(config) > 0 {
+		env = append(env, "GIT_CONFIG_COUNT="+strconv.Itoa(len(config)))
+	}
+	for i, kv := range config {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, kv[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, kv[1]),
+		)
+	}
+	return env
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
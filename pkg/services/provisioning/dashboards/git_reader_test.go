@@ -0,0 +1,221 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// setupGitRepository creates a bare repository with a commit of dashboards under dashboards/
+// on main branch and returns the repository path and the commit SHA.
+func setupGitRepository(t *testing.T) (string, string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not found")
+	}
+	root := t.TempDir()
+	bare := filepath.Join(root, "dashboards.git")
+	work := filepath.Join(root, "work")
+	runGit(t, root, "init", "--quiet", "--bare", bare)
+	runGit(t, root, "init", "--quiet", work)
+
+	dashboardJSON, err := os.ReadFile(filepath.Join(oneDashboard, "dashboard1.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "dashboards", "Team A"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "dashboards", "Team A", "dashboard1.json"), dashboardJSON, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "README.md"), []byte("dashboards"), 0600))
+
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "--quiet", "-m", "Add dashboards")
+	runGit(t, work, "push", "--quiet", bare, "HEAD:refs/heads/main")
+	return bare, runGit(t, work, "rev-parse", "HEAD")
+}
+
+func TestGitReader(t *testing.T) {
+	bare, commit := setupGitRepository(t)
+
+	cfg := &config{
+		Name:  "git",
+		Type:  "git",
+		OrgID: 1,
+		Options: map[string]any{
+			"url":                       bare,
+			"ref":                       "main",
+			"path":                      "dashboards",
+			"clonePath":                 filepath.Join(t.TempDir(), "checkout"),
+			"foldersFromFilesStructure": true,
+		},
+	}
+
+	fakeService := &dashboards.FakeDashboardProvisioning{}
+	defer fakeService.AssertExpectations(t)
+	fakeService.On("GetProvisionedDashboardData", mock.Anything, "git").Return(nil, nil).Once()
+	fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.MatchedBy(func(cmd *folder.CreateFolderCommand) bool {
+		return cmd.Title == "Team A"
+	})).Return(&folder.Folder{ID: 1, UID: "team-a"}, nil).Once()
+	fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.MatchedBy(func(dp *dashboards.DashboardProvisioning) bool {
+		return dp.CommitSHA == commit && strings.HasSuffix(dp.ExternalID, filepath.Join("dashboards", "Team A", "dashboard1.json"))
+	})).Return(&dashboards.Dashboard{ID: 2}, nil).Once()
+
+	reader, err := NewDashboardGitReader(cfg, "", log.New("test-logger"), fakeService, &fakeDashboardStore{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "git", reader.Cfg.Type)
+
+	err = reader.walkDisk(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, commit, reader.commit)
+
+	t.Run("unchanged dashboards record the new commit", func(t *testing.T) {
+		work := filepath.Join(filepath.Dir(bare), "work")
+		require.NoError(t, os.WriteFile(filepath.Join(work, "README.md"), []byte("dashboards v2"), 0600))
+		runGit(t, work, "commit", "--quiet", "-am", "Update readme")
+		runGit(t, work, "push", "--quiet", bare, "HEAD:refs/heads/main")
+		next := runGit(t, work, "rev-parse", "HEAD")
+
+		path := filepath.Join(reader.resolvedPath(), "Team A", "dashboard1.json")
+		jsonFile, err := reader.readDashboardFromFile(path, time.Now(), 1, "team-a")
+		require.NoError(t, err)
+		provisioned := &dashboards.DashboardProvisioning{
+			DashboardID: 2, Name: "git", ExternalID: path, CheckSum: jsonFile.checkSum, CommitSHA: commit,
+		}
+		fakeService.On("GetProvisionedDashboardData", mock.Anything, "git").Return([]*dashboards.DashboardProvisioning{provisioned}, nil).Once()
+		fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).Return(&folder.Folder{ID: 1, UID: "team-a"}, nil).Once()
+		fakeService.On("SaveProvisionedDashboardData", mock.Anything, mock.MatchedBy(func(dp *dashboards.DashboardProvisioning) bool {
+			return dp.DashboardID == 2 && dp.CommitSHA == next && dp.CheckSum == provisioned.CheckSum
+		})).Return(nil).Once()
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		require.Equal(t, next, reader.commit)
+	})
+}
+
+func TestGitReader_InvalidOptions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not found")
+	}
+	for name, options := range map[string]map[string]any{
+		"url required":    {},
+		"absolute path":   {"url": "https://example.com/dashboards.git", "path": "/etc"},
+		"path outside":    {"url": "https://example.com/dashboards.git", "path": "../etc"},
+		"folder and from": {"url": "https://example.com/dashboards.git", "foldersFromFilesStructure": true},
+		"url option":      {"url": "--upload-pack=touch /tmp/pwned"},
+		"ref option":      {"url": "https://example.com/dashboards.git", "ref": "--upload-pack=touch /tmp/pwned"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config{Name: "git", Type: "git", OrgID: 1, Folder: "folder", FolderUID: "folder", Options: options}
+			_, err := NewDashboardGitReader(cfg, "", log.New("test-logger"), nil, nil, nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGitRepository_Env(t *testing.T) {
+	repo := &gitRepository{username: "user", password: "secret", sshKeyFile: "/keys/it's a key"}
+	env := repo.env()
+	require.Contains(t, env, "GIT_CONFIG_COUNT=2")
+	require.Contains(t, env, "GIT_CONFIG_VALUE_0=Authorization: Basic dXNlcjpzZWNyZXQ=")
+	require.Contains(t, env, "GIT_CONFIG_KEY_1=core.sshCommand")
+	require.Contains(t, env, `GIT_CONFIG_VALUE_1=ssh -i '/keys/it'\''s a key' -o IdentitiesOnly=yes`)
+
+	out, err := exec.Command("sh", "-c", "printf '%s' "+shellQuote(`$(id) "a" 'b'`)).Output()
+	require.NoError(t, err)
+	require.Equal(t, `$(id) "a" 'b'`, string(out))
+}
+
+func TestGitRepository_Checkout(t *testing.T) {
+	bare, commit := setupGitRepository(t)
+
+	t.Run("default checkout is created under the data path", func(t *testing.T) {
+		dataPath := t.TempDir()
+		cfg := &config{Name: "git repo", Type: "git", OrgID: 2, Options: map[string]any{"url": bare, "ref": "main"}}
+		reader, err := NewDashboardGitReader(cfg, dataPath, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dataPath, "provisioning-git", "2", "git_repo"), reader.git.clonePath)
+
+		sha, err := reader.git.sync(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, commit, sha)
+		info, err := os.Stat(reader.git.clonePath)
+		require.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			require.Equal(t, os.FileMode(0700), info.Mode().Perm())
+		}
+
+		// the checkout is reused and untracked files are removed
+		untracked := filepath.Join(reader.git.clonePath, "untracked.json")
+		require.NoError(t, os.WriteFile(untracked, []byte("{}"), 0600))
+		_, err = reader.git.sync(context.Background())
+		require.NoError(t, err)
+		require.NoFileExists(t, untracked)
+	})
+
+	t.Run("default checkout not created by Grafana is not used", func(t *testing.T) {
+		dataPath := t.TempDir()
+		clonePath := filepath.Join(dataPath, "provisioning-git", "1", "git")
+		require.NoError(t, os.MkdirAll(clonePath, 0700))
+		runGit(t, clonePath, "init", "--quiet")
+		runGit(t, clonePath, "config", "core.fsmonitor", "touch pwned")
+
+		cfg := &config{Name: "git", Type: "git", OrgID: 1, Options: map[string]any{"url": bare}}
+		reader, err := NewDashboardGitReader(cfg, dataPath, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		_, err = reader.git.sync(context.Background())
+		require.ErrorContains(t, err, "not created by Grafana")
+		require.NoFileExists(t, filepath.Join(clonePath, "pwned"))
+	})
+
+	t.Run("default checkout accessible by other users is not used", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("permissions are not checked on windows")
+		}
+		dataPath := t.TempDir()
+		cfg := &config{Name: "git", Type: "git", OrgID: 1, Options: map[string]any{"url": bare, "ref": "main"}}
+		reader, err := NewDashboardGitReader(cfg, dataPath, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		_, err = reader.git.sync(context.Background())
+		require.NoError(t, err)
+
+		require.NoError(t, os.Chmod(reader.git.clonePath, 0777))
+		_, err = reader.git.sync(context.Background())
+		require.ErrorContains(t, err, "accessible by other users")
+	})
+
+	t.Run("untracked files of an existing checkout are kept", func(t *testing.T) {
+		clonePath := t.TempDir()
+		runGit(t, clonePath, "init", "--quiet")
+		runGit(t, clonePath, "remote", "add", "origin", bare)
+		untracked := filepath.Join(clonePath, "notes.txt")
+		require.NoError(t, os.WriteFile(untracked, []byte("notes"), 0600))
+
+		cfg := &config{Name: "git", Type: "git", OrgID: 1, Options: map[string]any{"url": bare, "ref": "main", "clonePath": clonePath}}
+		reader, err := NewDashboardGitReader(cfg, "", log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		_, err = reader.git.sync(context.Background())
+		require.NoError(t, err)
+		require.FileExists(t, untracked)
+	})
+}
@@ -21,6 +21,7 @@ type config struct {
 	DisableDeletion       bool
 	UpdateIntervalSeconds int64
 	AllowUIUpdates        bool
+	SyncUIUpdatesToFile   bool
 }
 
 type configV0 struct {
@@ -55,6 +56,7 @@ type configs struct {
 	DisableDeletion       values.BoolValue   `json:"disableDeletion" yaml:"disableDeletion"`
 	UpdateIntervalSeconds values.Int64Value  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
 	AllowUIUpdates        values.BoolValue   `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	SyncUIUpdatesToFile   values.BoolValue   `json:"syncUiUpdatesToFile" yaml:"syncUiUpdatesToFile"`
 }
 
 func createDashboardJSON(data *simplejson.Json, lastModified time.Time, cfg *config, folderID int64, folderUID string) (*dashboards.SaveDashboardDTO, error) {
@@ -124,6 +126,7 @@ func (dc *configV1) mapToDashboardsAsConfig() ([]*config, error) {
 			DisableDeletion:       v.DisableDeletion.Value(),
 			UpdateIntervalSeconds: v.UpdateIntervalSeconds.Value(),
 			AllowUIUpdates:        v.AllowUIUpdates.Value(),
+			SyncUIUpdatesToFile:   v.SyncUIUpdatesToFile.Value(),
 		})
 	}
 
@@ -94,7 +94,7 @@ func ProvideService(
 
 func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
+	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.Cfg.DataPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -111,6 +111,8 @@ type ProvisioningService interface {
 	ProvisionAlerting(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetSyncUIUpdatesToFileFromConfig(name string) bool
+	SaveDashboardToFile(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning, dash *dashboardservice.Dashboard, save func() (*dashboardservice.Dashboard, error)) (*dashboardservice.Dashboard, error)
 }
 
 // Used for testing purposes
@@ -320,6 +322,14 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+func (ps *ProvisioningServiceImpl) GetSyncUIUpdatesToFileFromConfig(name string) bool {
+	return ps.dashboardProvisioner.GetSyncUIUpdatesToFileFromConfig(name)
+}
+
+func (ps *ProvisioningServiceImpl) SaveDashboardToFile(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning, dash *dashboardservice.Dashboard, save func() (*dashboardservice.Dashboard, error)) (*dashboardservice.Dashboard, error) {
+	return ps.dashboardProvisioner.SaveDashboardToFile(ctx, provisioning, dash, save)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
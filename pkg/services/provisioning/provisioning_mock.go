@@ -1,6 +1,10 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -10,6 +14,8 @@ type Calls struct {
 	ProvisionAlerting                   []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	GetSyncUIUpdatesToFileFromConfig    []any
+	SaveDashboardToFile                 []any
 	Run                                 []any
 }
 
@@ -21,6 +27,8 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	GetSyncUIUpdatesToFileFromConfigFunc    func(name string) bool
+	SaveDashboardToFileFunc                 func(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error)
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -83,6 +91,22 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) GetSyncUIUpdatesToFileFromConfig(name string) bool {
+	mock.Calls.GetSyncUIUpdatesToFileFromConfig = append(mock.Calls.GetSyncUIUpdatesToFileFromConfig, name)
+	if mock.GetSyncUIUpdatesToFileFromConfigFunc != nil {
+		return mock.GetSyncUIUpdatesToFileFromConfigFunc(name)
+	}
+	return false
+}
+
+func (mock *ProvisioningServiceMock) SaveDashboardToFile(ctx context.Context, provisioning *dashboards.DashboardProvisioning, dash *dashboards.Dashboard, save func() (*dashboards.Dashboard, error)) (*dashboards.Dashboard, error) {
+	mock.Calls.SaveDashboardToFile = append(mock.Calls.SaveDashboardToFile, provisioning)
+	if mock.SaveDashboardToFileFunc != nil {
+		return mock.SaveDashboardToFileFunc(ctx, provisioning, dash, save)
+	}
+	return save()
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
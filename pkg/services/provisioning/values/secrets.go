@@ -0,0 +1,54 @@
+package values
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// expandSecrets expands the expander references of s, such as $__file{path} or the references of the expanders
+// added with setting.AddExpander, and the environment variables. The values of the references are inserted as is,
+// so that a '$' in a resolved secret is not interpolated again.
+func expandSecrets(s string) (string, error) {
+	var sb strings.Builder
+	last := 0
+	for _, match := range setting.GetExpanderRegex().FindAllStringSubmatchIndex(s, -1) {
+		name := strings.TrimPrefix(s[match[2]:match[3]], "__")
+		if name == "" {
+			continue
+		}
+
+		secret, ok, err := setting.ExpandReference(name, s[match[4]:match[5]])
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("got error while expanding expander %s: %w", name, err)
+		}
+
+		expanded, err := expandEnv(s[last:match[0]])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(expanded)
+		sb.WriteString(secret)
+		last = match[1]
+	}
+
+	expanded, err := expandEnv(s[last:])
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(expanded)
+	return sb.String(), nil
+}
+
+func expandEnv(s string) (string, error) {
+	expanded, err := setting.ExpandVar(s)
+	if err != nil {
+		return "", err
+	}
+	return os.ExpandEnv(expanded), nil
+}
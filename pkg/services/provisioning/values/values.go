@@ -1,5 +1,6 @@
 // Package values is a set of value types to use in provisioning. They add custom unmarshaling logic that puts the string values
-// through os.ExpandEnv.
+// through os.ExpandEnv and the expanders of the settings file, such as $__file{/path/to/secret}. Expanded values are
+// resolved at unmarshaling time only, the Raw values keep the references.
 // Usage:
 //
 //	type Data struct {
@@ -13,12 +14,9 @@ package values
 
 import (
 	"fmt"
-	"os"
 	"reflect"
 	"strconv"
 	"strings"
-
-	"github.com/grafana/grafana/pkg/setting"
 )
 
 // IntValue represents a string value in a YAML
@@ -286,11 +284,10 @@ func interpolateIfaceValue(val string) (interface{}, string, error) {
 	if len(parts) > 1 {
 		return interpolateValue(val)
 	}
-	expanded, err := setting.ExpandVar(val)
+	expandedEnv, err := expandSecrets(val)
 	if err != nil {
 		return val, val, fmt.Errorf("failed to interpolate value '%s': %w", val, err)
 	}
-	expandedEnv := os.ExpandEnv(expanded)
 	if expandedEnv != val {
 		// If the value is an environment variable, consider it may not be a string
 		intV, err := strconv.ParseInt(expandedEnv, 10, 64)
@@ -310,18 +307,17 @@ func interpolateIfaceValue(val string) (interface{}, string, error) {
 }
 
 // interpolateValue returns the final value after interpolation. In addition to environment variable interpolation,
-// expanders available for the settings file are expanded here.
+// secret references and expanders available for the settings file are expanded here.
 // For a literal '$', '$$' can be used to avoid interpolation.
 func interpolateValue(val string) (string, string, error) {
 	parts := strings.Split(val, "$$")
 	interpolated := make([]string, len(parts))
 	for i, v := range parts {
-		expanded, err := setting.ExpandVar(v)
+		expanded, err := expandSecrets(v)
 		if err != nil {
 			return val, val, fmt.Errorf("failed to interpolate value '%s': %w", val, err)
 		}
-		v = expanded
-		interpolated[i] = os.ExpandEnv(v)
+		interpolated[i] = expanded
 	}
 	return strings.Join(interpolated, "$"), val, nil
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -326,6 +327,62 @@ func TestValues_readFile(t *testing.T) {
 	assert.Equal(t, expected, data.Val.Value())
 }
 
+func TestValues_secretFile(t *testing.T) {
+	type Data struct {
+		Val  StringValue    `yaml:"val"`
+		Json JSONValue      `yaml:"json"`
+		Map  StringMapValue `yaml:"map"`
+	}
+
+	file := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(file, []byte("pa$$w0rd$HOME\n"), 0600))
+
+	data := &Data{}
+	doc := fmt.Sprintf("val: $__file{%[1]s}\njson:\n  password: user:$__file{%[1]s}\nmap:\n  password: $__file{%[1]s}", file)
+	err := yaml.Unmarshal([]byte(doc), data)
+	require.NoError(t, err)
+	require.Equal(t, "pa$$w0rd$HOME", data.Val.Value())
+	require.Equal(t, "user:pa$$w0rd$HOME", data.Json.Value()["password"])
+	require.Equal(t, "pa$$w0rd$HOME", data.Map.Value()["password"])
+	require.Equal(t, fmt.Sprintf("$__file{%s}", file), data.Val.Raw)
+	require.Equal(t, fmt.Sprintf("user:$__file{%s}", file), data.Json.Raw["password"])
+	require.Equal(t, fmt.Sprintf("$__file{%s}", file), data.Map.Raw["password"])
+
+	err = yaml.Unmarshal([]byte("val: $__file{"+filepath.Join(t.TempDir(), "missing")+"}"), data)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestValues_secretExpander(t *testing.T) {
+	type Data struct {
+		Val  StringValue `yaml:"val"`
+		Json JSONValue   `yaml:"json"`
+	}
+
+	t.Setenv("SECRET_PATH", "secret/db")
+	setting.AddExpander("testsecrets", 0, fakeSecretExpander{
+		"secret/db#password": "s3cr$t${SECRET_PATH}",
+		"secret/token":       "token",
+	})
+
+	data := &Data{}
+	doc := "val: $__testsecrets{secret/db#password}\njson:\n  url: http://$__testsecrets{secret/token}@$SECRET_PATH\n  nested:\n    - $__testsecrets{secret/db#password}"
+	err := yaml.Unmarshal([]byte(doc), data)
+	require.NoError(t, err)
+	require.Equal(t, "s3cr$t${SECRET_PATH}", data.Val.Value())
+	require.Equal(t, "$__testsecrets{secret/db#password}", data.Val.Raw)
+	require.Equal(t, map[string]any{
+		"url":    "http://token@secret/db",
+		"nested": []any{"s3cr$t${SECRET_PATH}"},
+	}, data.Json.Value())
+	require.Equal(t, map[string]any{
+		"url":    "http://$__testsecrets{secret/token}@$SECRET_PATH",
+		"nested": []any{"$__testsecrets{secret/db#password}"},
+	}, data.Json.Raw)
+
+	err = yaml.Unmarshal([]byte("val: $__testsecrets{secret/db#username}"), data)
+	require.ErrorIs(t, err, errSecretNotFound)
+}
+
 func TestValues_expanderError(t *testing.T) {
 	type Data struct {
 		Top JSONValue `yaml:"top"`
@@ -351,3 +408,19 @@ func (f failExpander) SetupExpander(file *ini.File) error {
 func (f failExpander) Expand(s string) (string, error) {
 	return "", errExpand
 }
+
+var errSecretNotFound = errors.New("test error: secret not found")
+
+type fakeSecretExpander map[string]string
+
+func (f fakeSecretExpander) SetupExpander(file *ini.File) error {
+	return nil
+}
+
+func (f fakeSecretExpander) Expand(s string) (string, error) {
+	secret, ok := f[s]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return secret, nil
+}
@@ -72,11 +72,15 @@ func (api *Api) RegisterAPIEndpoints() {
 	// Anonymous access to public dashboard route is configured in pkg/api/api.go
 	// because it is deeply dependent on the HTTPServer.Index() method and would result in a
 	// circular dependency
+	// Invalid passphrases have their own stricter limit, requests with access are rate limited afterwards so that
+	// clients failing the passphrase can't use up the requests of the dashboard.
 	api.routeRegister.Group("/api/public/dashboards/:accessToken", func(apiRoute routing.RouteRegister) {
 		apiRoute.Get("/", routing.Wrap(api.ViewPublicDashboard))
 		apiRoute.Get("/annotations", routing.Wrap(api.GetPublicAnnotations))
 		apiRoute.Post("/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
-	}, api.Middleware.HandleApi)
+	}, api.Middleware.HandleApi,
+		RequiresValidAccess(api.PublicDashboardService, api.cfg),
+		RateLimitByAccessToken(api.cfg, api.cfg.PublicDashboardsQueryRateLimit, api.cfg.PublicDashboardsQueryRateLimitBurst))
 
 	// Auth endpoints
 	auth := accesscontrol.Middleware(api.accessControl)
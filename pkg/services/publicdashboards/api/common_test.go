@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -75,6 +76,11 @@ func setupTestServer(
 		cfg.PublicDashboardsEnabled = true
 	}
 
+	// the access middleware looks up the public dashboard before the handlers
+	if fakeService, ok := service.(*publicdashboards.FakePublicDashboardService); ok {
+		fakeService.On("FindByAccessToken", mock.Anything, mock.Anything).Return(&publicdashboardModels.PublicDashboard{IsEnabled: true}, nil).Maybe()
+	}
+
 	// build api, this will mount the routes at the same time if the feature is enabled
 	license := licensingtest.NewFakeLicensing()
 	license.On("FeatureEnabled", publicdashboardModels.FeaturePublicDashboardsEmailSharing).Return(false)
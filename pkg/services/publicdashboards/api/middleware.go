@@ -2,11 +2,18 @@ package api
 
 import (
 	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
 
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -62,6 +69,135 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// RequiresValidAccess Middleware to reject requests to expired public dashboards and to public dashboards protected by
+// a passphrase when the request doesn't carry the passphrase header. Missing public dashboards are left to the handler.
+// Invalid passphrases are rate limited per access token and client IP address, with a stricter limit than requests.
+func RequiresValidAccess(publicDashboardService publicdashboards.Service, cfg *setting.Cfg) func(c *contextmodel.ReqContext) {
+	limiters := newAccessTokenLimiters(cfg.PublicDashboardsPassphraseRateLimit, cfg.PublicDashboardsPassphraseRateLimitBurst)
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			return
+		}
+
+		pubdash, err := publicDashboardService.FindByAccessToken(c.Req.Context(), accessToken)
+		if err != nil {
+			return
+		}
+
+		if pubdash.IsExpired(time.Now()) {
+			c.WriteErr(ErrPublicDashboardExpired.Errorf("RequiresValidAccess: public dashboard is expired"))
+			return
+		}
+
+		if !pubdash.RequiresPassphrase() {
+			return
+		}
+
+		passphrase := c.Req.Header.Get(PassphraseHeader)
+		if passphrase == "" {
+			c.WriteErr(ErrPublicDashboardPassphraseRequired.Errorf("RequiresValidAccess: passphrase required"))
+			return
+		}
+		key := accessToken + "/" + loginattempt.ClientIP(cfg, c.Req)
+		if limiters != nil && limiters.blocked(key, time.Now()) {
+			c.WriteErr(ErrPublicDashboardPassphraseRateLimited.Errorf("RequiresValidAccess: too many invalid passphrases"))
+			return
+		}
+		if !pubdash.VerifyPassphrase(passphrase) {
+			if limiters != nil {
+				limiters.allow(key, time.Now())
+			}
+			c.WriteErr(ErrPublicDashboardPassphraseMismatch.Errorf("RequiresValidAccess: invalid passphrase"))
+			return
+		}
+	}
+}
+
+// RateLimitByAccessToken Middleware to limit the rate of requests per public dashboard access token and client IP
+// address, so that a single client cannot exhaust the limit of a public dashboard for everyone. Limiters not used
+// for a while are dropped. A zero limit disables rate limiting. Limiters are kept in memory, so the limit applies per
+// Grafana instance and not to the whole cluster.
+func RateLimitByAccessToken(cfg *setting.Cfg, limit float64, burst int) func(c *contextmodel.ReqContext) {
+	limiters := newAccessTokenLimiters(limit, burst)
+	if limiters == nil {
+		return func(c *contextmodel.ReqContext) {}
+	}
+
+	return func(c *contextmodel.ReqContext) {
+		accessToken := web.Params(c.Req)[":accessToken"]
+		if !validation.IsValidAccessToken(accessToken) {
+			return
+		}
+
+		if !limiters.allow(accessToken+"/"+loginattempt.ClientIP(cfg, c.Req), time.Now()) {
+			c.WriteErr(ErrPublicDashboardRateLimited.Errorf("RateLimitByAccessToken: rate limit exceeded"))
+			return
+		}
+	}
+}
+
+const accessTokenLimiterTTL = 10 * time.Minute
+
+type accessTokenLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// accessTokenLimiters are the rate limiters of access tokens, keyed by the access token and the client IP address.
+type accessTokenLimiters struct {
+	limit     rate.Limit
+	burst     int
+	mu        sync.Mutex
+	limiters  map[string]*accessTokenLimiter
+	lastPrune time.Time
+}
+
+// newAccessTokenLimiters returns nil when limit is zero, which disables rate limiting.
+func newAccessTokenLimiters(limit float64, burst int) *accessTokenLimiters {
+	if limit <= 0 {
+		return nil
+	}
+	return &accessTokenLimiters{
+		limit:    rate.Limit(limit),
+		burst:    burst,
+		limiters: map[string]*accessTokenLimiter{},
+	}
+}
+
+// allow takes a token of the limiter of key and reports whether there was one.
+func (l *accessTokenLimiters) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.get(key, now).AllowN(now, 1)
+}
+
+// blocked reports whether the limiter of key has no token left, without taking one.
+func (l *accessTokenLimiters) blocked(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.get(key, now).TokensAt(now) < 1
+}
+
+func (l *accessTokenLimiters) get(key string, now time.Time) *rate.Limiter {
+	if now.Sub(l.lastPrune) > accessTokenLimiterTTL {
+		for k, limiter := range l.limiters {
+			if now.Sub(limiter.lastSeen) > accessTokenLimiterTTL {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &accessTokenLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = limiter
+	}
+	limiter.lastSeen = now
+	return limiter.limiter
+}
+
 func CountPublicDashboardRequest() func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
@@ -5,13 +5,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"errors"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -83,6 +88,125 @@ func TestRequiresExistingAccessToken(t *testing.T) {
 	}
 }
 
+func TestRequiresValidAccess(t *testing.T) {
+	protected := &PublicDashboard{IsEnabled: true, Share: PublicShareType, PassphraseEnabled: true, PassphraseSalt: "salt"}
+	protected.PassphraseHash, _ = util.EncodePassword("correct horse battery", "salt")
+	expiresAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		Name                 string
+		PublicDashboard      *PublicDashboard
+		FindErr              error
+		Passphrase           string
+		ExpectedResponseCode int
+	}{
+		{
+			Name:                 "Returns 200 when public dashboard is valid",
+			PublicDashboard:      &PublicDashboard{IsEnabled: true, Share: PublicShareType},
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Leaves missing public dashboard to the handler",
+			FindErr:              ErrPublicDashboardNotFound.Errorf("not found"),
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 403 when public dashboard is expired",
+			PublicDashboard:      &PublicDashboard{IsEnabled: true, Share: PublicShareType, ExpiresAt: &expiresAt},
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 401 when passphrase is missing",
+			PublicDashboard:      protected,
+			ExpectedResponseCode: http.StatusUnauthorized,
+		},
+		{
+			Name:                 "Returns 401 when passphrase is wrong",
+			PublicDashboard:      protected,
+			Passphrase:           "wrong passphrase",
+			ExpectedResponseCode: http.StatusUnauthorized,
+		},
+		{
+			Name:                 "Returns 200 when passphrase matches",
+			PublicDashboard:      protected,
+			Passphrase:           "correct horse battery",
+			ExpectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := &publicdashboards.FakePublicDashboardService{}
+			publicdashboardService.On("FindByAccessToken", mock.Anything, validAccessToken).Return(tt.PublicDashboard, tt.FindErr)
+			params := map[string]string{":accessToken": validAccessToken}
+			ctx := &contextmodel.ReqContext{Context: &web.Context{}}
+			mw := func(c *contextmodel.ReqContext) {
+				if tt.Passphrase != "" {
+					c.Req.Header.Set(PassphraseHeader, tt.Passphrase)
+				}
+				RequiresValidAccess(publicdashboardService, setting.NewCfg())(c)
+			}
+			_, resp := runMw(t, ctx, "GET", "/api/public/dashboards/myAccesstoken", params, mw)
+			require.Equal(t, tt.ExpectedResponseCode, resp.Code)
+		})
+	}
+
+	t.Run("rate limits invalid passphrases per client", func(t *testing.T) {
+		publicdashboardService := &publicdashboards.FakePublicDashboardService{}
+		publicdashboardService.On("FindByAccessToken", mock.Anything, validAccessToken).Return(protected, nil)
+		cfg := setting.NewCfg()
+		cfg.PublicDashboardsPassphraseRateLimit = 0.001
+		cfg.PublicDashboardsPassphraseRateLimitBurst = 2
+		mw := RequiresValidAccess(publicdashboardService, cfg)
+		check := func(remoteAddr, passphrase string) int {
+			params := map[string]string{":accessToken": validAccessToken}
+			_, resp := runMw(t, nil, "GET", "/api/public/dashboards/myAccesstoken", params, func(c *contextmodel.ReqContext) {
+				c.Req.RemoteAddr = remoteAddr
+				c.Req.Header.Set(PassphraseHeader, passphrase)
+				mw(c)
+			})
+			return resp.Code
+		}
+
+		require.Equal(t, http.StatusOK, check("192.0.2.1:1234", "correct horse battery"))
+		require.Equal(t, http.StatusUnauthorized, check("192.0.2.1:1234", "wrong passphrase"))
+		require.Equal(t, http.StatusUnauthorized, check("192.0.2.1:1234", "wrong passphrase"))
+		require.Equal(t, http.StatusTooManyRequests, check("192.0.2.1:1234", "correct horse battery"))
+		require.Equal(t, http.StatusOK, check("192.0.2.2:1234", "correct horse battery"))
+	})
+}
+
+func TestRateLimitByAccessToken(t *testing.T) {
+	otherAccessToken, _ := service.GenerateAccessToken()
+	mw := RateLimitByAccessToken(setting.NewCfg(), 1, 2)
+	query := func(accessToken, remoteAddr string) int {
+		params := map[string]string{":accessToken": accessToken}
+		_, resp := runMw(t, nil, "POST", "/api/public/dashboards/myAccesstoken/panels/1/query", params, func(c *contextmodel.ReqContext) {
+			c.Req.RemoteAddr = remoteAddr
+			mw(c)
+		})
+		return resp.Code
+	}
+
+	require.Equal(t, http.StatusOK, query(validAccessToken, "192.0.2.1:1234"))
+	require.Equal(t, http.StatusOK, query(validAccessToken, "192.0.2.1:1234"))
+	require.Equal(t, http.StatusTooManyRequests, query(validAccessToken, "192.0.2.1:1234"))
+	require.Equal(t, http.StatusOK, query(otherAccessToken, "192.0.2.1:1234"))
+	// other clients of the dashboard are not limited by the requests of one client
+	require.Equal(t, http.StatusOK, query(validAccessToken, "192.0.2.2:1234"))
+	// the limit applies to the address of the client, whatever its port
+	require.Equal(t, http.StatusTooManyRequests, query(validAccessToken, "192.0.2.1:5678"))
+
+	t.Run("drops limiters of access tokens not seen for a while", func(t *testing.T) {
+		limiters := &accessTokenLimiters{limit: 1, burst: 1, limiters: map[string]*accessTokenLimiter{}}
+		now := time.Now()
+		require.True(t, limiters.allow(validAccessToken, now))
+		require.False(t, limiters.allow(validAccessToken, now))
+		require.True(t, limiters.allow(otherAccessToken, now.Add(2*accessTokenLimiterTTL)))
+		require.Len(t, limiters.limiters, 1)
+	})
+}
+
 func TestSetPublicDashboardOrgIdOnContext(t *testing.T) {
 	tests := []struct {
 		Name          string
@@ -168,6 +292,9 @@ func runMw(t *testing.T, ctx *contextmodel.ReqContext, httpmethod string, path s
 	if ctx.SignedInUser == nil {
 		ctx.SignedInUser = &user.SignedInUser{}
 	}
+	if ctx.Logger == nil {
+		ctx.Logger = log.NewNopLogger()
+	}
 
 	// create request and add params
 	request, err := http.NewRequest(httpmethod, path, nil)
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -42,6 +43,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -135,6 +138,71 @@ func TestAPIViewPublicDashboard(t *testing.T) {
 	}
 }
 
+func TestAPIPublicDashboardPassphraseRateLimit(t *testing.T) {
+	protected := &PublicDashboard{IsEnabled: true, Share: PublicShareType, PassphraseEnabled: true, PassphraseSalt: "salt"}
+	protected.PassphraseHash, _ = util.EncodePassword("correct horse battery", "salt")
+
+	service := publicdashboards.NewFakePublicDashboardService(t)
+	service.On("FindByAccessToken", mock.Anything, validAccessToken).Return(protected, nil)
+
+	cfg := setting.NewCfg()
+	cfg.PublicDashboardsEnabled = true
+	cfg.PublicDashboardsQueryRateLimit = 100
+	cfg.PublicDashboardsQueryRateLimitBurst = 100
+	cfg.PublicDashboardsPassphraseRateLimit = 0.001
+	cfg.PublicDashboardsPassphraseRateLimitBurst = 2
+	testServer := setupTestServer(t, cfg, service, anonymousUser, true)
+
+	guess := func(path, passphrase string) int {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(PassphraseHeader, passphrase)
+		recorder := httptest.NewRecorder()
+		testServer.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// every endpoint verifying the passphrase shares the limit of invalid passphrases, which is stricter than the
+	// limit of requests
+	require.Equal(t, http.StatusUnauthorized, guess("/api/public/dashboards/"+validAccessToken, "wrong passphrase"))
+	require.Equal(t, http.StatusUnauthorized, guess("/api/public/dashboards/"+validAccessToken+"/annotations", "wrong passphrase"))
+	require.Equal(t, http.StatusTooManyRequests, guess("/api/public/dashboards/"+validAccessToken, "wrong passphrase"))
+	require.Equal(t, http.StatusTooManyRequests, guess("/api/public/dashboards/"+validAccessToken+"/annotations", "correct horse battery"))
+}
+
+func TestAPIPublicDashboardRateLimitAfterAccess(t *testing.T) {
+	protected := &PublicDashboard{IsEnabled: true, Share: PublicShareType, PassphraseEnabled: true, PassphraseSalt: "salt"}
+	protected.PassphraseHash, _ = util.EncodePassword("correct horse battery", "salt")
+
+	service := publicdashboards.NewFakePublicDashboardService(t)
+	service.On("FindByAccessToken", mock.Anything, validAccessToken).Return(protected, nil)
+	service.On("FindAnnotations", mock.Anything, mock.Anything, validAccessToken).Return([]AnnotationEvent{}, nil)
+
+	cfg := setting.NewCfg()
+	cfg.PublicDashboardsEnabled = true
+	cfg.PublicDashboardsQueryRateLimit = 0.001
+	cfg.PublicDashboardsQueryRateLimitBurst = 1
+	testServer := setupTestServer(t, cfg, service, anonymousUser, true)
+
+	annotations := func(remoteAddr, passphrase string) int {
+		req, err := http.NewRequest(http.MethodGet, "/api/public/dashboards/"+validAccessToken+"/annotations", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(PassphraseHeader, passphrase)
+		recorder := httptest.NewRecorder()
+		testServer.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// requests without access don't use up the requests of the clients with access
+	require.Equal(t, http.StatusUnauthorized, annotations("192.0.2.1:1234", "wrong passphrase"))
+	require.Equal(t, http.StatusUnauthorized, annotations("192.0.2.1:1234", "wrong passphrase"))
+	require.Equal(t, http.StatusOK, annotations("192.0.2.1:1234", "correct horse battery"))
+	require.Equal(t, http.StatusTooManyRequests, annotations("192.0.2.1:1234", "correct horse battery"))
+	require.Equal(t, http.StatusOK, annotations("192.0.2.2:1234", "correct horse battery"))
+}
+
 // `/public/dashboards/:uid/query“ endpoint test
 func TestAPIQueryPublicDashboard(t *testing.T) {
 	mockedResponse := &backend.QueryDataResponse{
@@ -230,6 +230,30 @@ func TestIntegrationExistsEnabledByAccessToken(t *testing.T) {
 		require.False(t, res)
 	})
 
+	t.Run("ExistsEnabledByAccessToken will return false when public dashboard is expired", func(t *testing.T) {
+		setup()
+
+		expiresAt := time.Now().Add(-time.Hour)
+		_, err := publicdashboardStore.Create(context.Background(), SavePublicDashboardCommand{
+			PublicDashboard: PublicDashboard{
+				IsEnabled:    true,
+				Uid:          "abc123",
+				DashboardUid: savedDashboard.UID,
+				OrgId:        savedDashboard.OrgID,
+				CreatedAt:    time.Now(),
+				CreatedBy:    7,
+				AccessToken:  "accessToken",
+				ExpiresAt:    &expiresAt,
+			},
+		})
+		require.NoError(t, err)
+
+		res, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), "accessToken")
+		require.NoError(t, err)
+
+		require.False(t, res)
+	})
+
 	t.Run("ExistsEnabledByAccessToken will return false when no public dashboard has matching access token", func(t *testing.T) {
 		setup()
 
@@ -786,6 +810,42 @@ func TestFindByFolder(t *testing.T) {
 	})
 }
 
+func TestIntegrationFindExpired(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	quotaService := quotatest.New(false, nil)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotaService)
+	require.NoError(t, err)
+	publicdashboardStore := ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+	notExpired := now.Add(time.Hour)
+	// the last one is already disabled
+	for i, expiresAt := range []*time.Time{&expired, &notExpired, nil, &expired} {
+		dashboard := insertTestDashboard(t, dashboardStore, fmt.Sprintf("testDashie%d", i), 1, "", true)
+		_, err := publicdashboardStore.Create(context.Background(), SavePublicDashboardCommand{
+			PublicDashboard: PublicDashboard{
+				IsEnabled:    i < 3,
+				Uid:          fmt.Sprintf("pubdash%d", i),
+				DashboardUid: dashboard.UID,
+				OrgId:        dashboard.OrgID,
+				CreatedAt:    now,
+				AccessToken:  fmt.Sprintf("accessToken%d", i),
+				ExpiresAt:    expiresAt,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	pubdashes, err := publicdashboardStore.FindExpired(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, pubdashes, 1)
+	assert.Equal(t, "pubdash0", pubdashes[0].Uid)
+}
+
 func TestGetMetrics(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
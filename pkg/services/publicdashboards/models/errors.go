@@ -23,6 +23,16 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Dashboard Access Token already exists"))
+	ErrInvalidExpiration                   = errutil.BadRequest("publicdashboards.invalidExpiration", errutil.WithPublicMessage("Expiration must be in the future"))
+	ErrInvalidPassphrase                   = errutil.BadRequest("publicdashboards.invalidPassphrase", errutil.WithPublicMessage("Passphrase must be at least 8 characters long"))
+	ErrPassphraseNotSupported              = errutil.BadRequest("publicdashboards.passphraseNotSupported", errutil.WithPublicMessage("Passphrase is only supported for public share type"))
+
+	ErrPublicDashboardPassphraseRequired = errutil.Unauthorized("publicdashboards.passphraseRequired", errutil.WithPublicMessage("Passphrase required"))
+	ErrPublicDashboardPassphraseMismatch = errutil.Unauthorized("publicdashboards.passphraseMismatch", errutil.WithPublicMessage("Invalid passphrase"))
+
+	ErrPublicDashboardRateLimited           = errutil.TooManyRequests("publicdashboards.rateLimited", errutil.WithPublicMessage("Too many requests"))
+	ErrPublicDashboardPassphraseRateLimited = errutil.TooManyRequests("publicdashboards.passphraseRateLimited", errutil.WithPublicMessage("Too many invalid passphrases, try again later"))
 
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Dashboard paused"))
+	ErrPublicDashboardExpired    = errutil.Forbidden("publicdashboards.expired", errutil.WithPublicMessage("Dashboard expired"))
 )
@@ -1,11 +1,13 @@
 package models
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"time"
 
 	"github.com/grafana/grafana/pkg/kinds/dashboard"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 // PublicDashboardErr represents a dashboard error.
@@ -29,6 +31,8 @@ const (
 	EmailShareType                      ShareType = "email"
 	PublicShareType                     ShareType = "public"
 	FeaturePublicDashboardsEmailSharing           = "publicDashboardsEmailSharing"
+	PassphraseHeader                              = "X-Grafana-Public-Dashboard-Passphrase"
+	MinPassphraseLength                           = 8
 )
 
 var (
@@ -54,6 +58,10 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	ExpiresAt            *time.Time    `json:"expiresAt,omitempty" xorm:"expires_at"`
+	PassphraseEnabled    bool          `json:"passphraseEnabled" xorm:"passphrase_enabled"`
+	PassphraseHash       string        `json:"-" xorm:"passphrase_hash"`
+	PassphraseSalt       string        `json:"-" xorm:"passphrase_salt"`
 }
 
 type PublicDashboardDTO struct {
@@ -63,6 +71,10 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// ExpiresAt sets when the public dashboard stops being accessible, the zero time removes the expiration
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// Passphrase protects a public dashboard with public share type, an empty passphrase removes the protection
+	Passphrase *string `json:"passphrase"`
 }
 
 type EmailDTO struct {
@@ -96,6 +108,28 @@ func (pd PublicDashboard) TableName() string {
 	return "dashboard_public"
 }
 
+// IsExpired returns true when the public dashboard has an expiration before now
+func (pd PublicDashboard) IsExpired(now time.Time) bool {
+	return pd.ExpiresAt != nil && !pd.ExpiresAt.After(now)
+}
+
+// RequiresPassphrase returns true when the public dashboard can only be accessed with a passphrase
+func (pd PublicDashboard) RequiresPassphrase() bool {
+	return pd.PassphraseEnabled && pd.Share == PublicShareType
+}
+
+// VerifyPassphrase checks the passphrase against the stored hash
+func (pd PublicDashboard) VerifyPassphrase(passphrase string) bool {
+	if passphrase == "" || pd.PassphraseHash == "" {
+		return false
+	}
+	hash, err := util.EncodePassword(passphrase, pd.PassphraseSalt)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(pd.PassphraseHash)) == 1
+}
+
 type PublicDashboardListQuery struct {
 	OrgID  int64
 	Query  string
@@ -177,6 +177,10 @@ func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAc
 		return nil, nil, ErrPublicDashboardNotEnabled.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is not enabled accessToken: %s", accessToken)
 	}
 
+	if pubdash.IsExpired(time.Now()) {
+		return nil, nil, ErrPublicDashboardExpired.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is expired accessToken: %s", accessToken)
+	}
+
 	if !pd.license.FeatureEnabled(FeaturePublicDashboardsEmailSharing) && pubdash.Share == EmailShareType {
 		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
 	}
@@ -293,7 +297,10 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 		return nil, ErrInvalidUid.Errorf("Update: the public dashboard does not belong to the dashboard")
 	}
 
-	publicDashboard := newUpdatePublicDashboard(dto, existingPubdash)
+	publicDashboard, err := newUpdatePublicDashboard(dto, existingPubdash)
+	if err != nil {
+		return nil, err
+	}
 
 	// set values to update
 	cmd := SavePublicDashboardCommand{
@@ -442,6 +449,29 @@ func (pd *PublicDashboardServiceImpl) DeleteByDashboard(ctx context.Context, das
 	return pd.serviceWrapper.Delete(ctx, pubdash.Uid)
 }
 
+// DisableExpired disables public dashboards with an expiration in the past and returns the number of disabled public
+// dashboards. The configuration is kept, so that the dashboard can be shared again with a new expiration.
+func (pd *PublicDashboardServiceImpl) DisableExpired(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.DisableExpired")
+	defer span.End()
+	pubdashes, err := pd.store.FindExpired(ctx, time.Now())
+	if err != nil {
+		return 0, ErrInternalServerError.Errorf("DisableExpired: failed to find expired public dashboards: %w", err)
+	}
+
+	var disabled int64
+	for _, pubdash := range pubdashes {
+		pubdash.IsEnabled = false
+		pubdash.UpdatedAt = time.Now()
+		if _, err := pd.store.Update(ctx, SavePublicDashboardCommand{PublicDashboard: *pubdash}); err != nil {
+			return disabled, ErrInternalServerError.Errorf("DisableExpired: failed to disable public dashboard %s: %w", pubdash.Uid, err)
+		}
+		disabled++
+	}
+
+	return disabled, nil
+}
+
 // intervalMS and maxQueryData values are being calculated on the frontend for regular dashboards
 // we are doing the same for public dashboards but because this access would be public, we need a way to keep this
 // values inside reasonable bounds to avoid an attack that could hit data sources with a small interval and a big
@@ -544,7 +574,7 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 
 	now := time.Now()
 
-	return &PublicDashboard{
+	publicDashboard := &PublicDashboard{
 		Uid:                  uid,
 		DashboardUid:         dto.DashboardUid,
 		OrgId:                dto.OrgID,
@@ -553,15 +583,22 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         &TimeSettings{},
 		Share:                share,
+		ExpiresAt:            returnExpirationOrDefault(dto.PublicDashboard.ExpiresAt, nil),
 		CreatedBy:            dto.UserId,
 		CreatedAt:            now,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            now,
 		AccessToken:          accessToken,
-	}, nil
+	}
+
+	if err := setPassphrase(publicDashboard, dto.PublicDashboard.Passphrase); err != nil {
+		return nil, err
+	}
+
+	return publicDashboard, nil
 }
 
-func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard) *PublicDashboard {
+func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard) (*PublicDashboard, error) {
 	pubdashDTO := dto.PublicDashboard
 	timeSelectionEnabled := returnValueOrDefault(pubdashDTO.TimeSelectionEnabled, pd.TimeSelectionEnabled)
 	isEnabled := returnValueOrDefault(pubdashDTO.IsEnabled, pd.IsEnabled)
@@ -572,16 +609,26 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
-	return &PublicDashboard{
+	publicDashboard := &PublicDashboard{
 		Uid:                  pd.Uid,
 		IsEnabled:            isEnabled,
 		AnnotationsEnabled:   annotationsEnabled,
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		ExpiresAt:            returnExpirationOrDefault(pubdashDTO.ExpiresAt, pd.ExpiresAt),
+		PassphraseEnabled:    pd.PassphraseEnabled,
+		PassphraseHash:       pd.PassphraseHash,
+		PassphraseSalt:       pd.PassphraseSalt,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
 	}
+
+	if err := setPassphrase(publicDashboard, pubdashDTO.Passphrase); err != nil {
+		return nil, err
+	}
+
+	return publicDashboard, nil
 }
 
 func returnValueOrDefault(value *bool, defaultValue bool) bool {
@@ -591,3 +638,48 @@ func returnValueOrDefault(value *bool, defaultValue bool) bool {
 
 	return defaultValue
 }
+
+// returnExpirationOrDefault returns the default when the expiration is not set and nil when it is removed with the zero time
+func returnExpirationOrDefault(value *time.Time, defaultValue *time.Time) *time.Time {
+	if value == nil {
+		return defaultValue
+	}
+	if value.IsZero() {
+		return nil
+	}
+
+	return value
+}
+
+// setPassphrase hashes the passphrase into the public dashboard. Nil keeps the current passphrase and an empty one
+// removes it
+func setPassphrase(pubdash *PublicDashboard, passphrase *string) error {
+	if passphrase == nil {
+		return nil
+	}
+
+	if *passphrase == "" {
+		pubdash.PassphraseEnabled = false
+		pubdash.PassphraseHash = ""
+		pubdash.PassphraseSalt = ""
+		return nil
+	}
+
+	if pubdash.Share != PublicShareType {
+		return ErrPassphraseNotSupported.Errorf("setPassphrase: passphrase is not supported for %s share type", pubdash.Share)
+	}
+
+	salt, err := util.GetRandomString(10)
+	if err != nil {
+		return ErrInternalServerError.Errorf("setPassphrase: failed to generate salt: %w", err)
+	}
+	hash, err := util.EncodePassword(*passphrase, salt)
+	if err != nil {
+		return ErrInternalServerError.Errorf("setPassphrase: failed to hash passphrase: %w", err)
+	}
+
+	pubdash.PassphraseEnabled = true
+	pubdash.PassphraseHash = hash
+	pubdash.PassphraseSalt = salt
+	return nil
+}
@@ -551,6 +551,17 @@ func TestGetEnabledPublicDashboard(t *testing.T) {
 			ErrResp:  ErrPublicDashboardNotFound,
 			DashResp: nil,
 		},
+		{
+			Name:        "returns ErrPublicDashboardExpired when expiration is in the past",
+			AccessToken: "abc123",
+			StoreResp: &storeResp{
+				pd:  &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, ExpiresAt: util.Pointer(time.Now().Add(-time.Minute))},
+				d:   &dashboards.Dashboard{UID: "mydashboard"},
+				err: nil,
+			},
+			ErrResp:  ErrPublicDashboardExpired,
+			DashResp: nil,
+		},
 	}
 
 	for _, test := range testCases {
@@ -1057,6 +1068,58 @@ func TestUpdatePublicDashboard(t *testing.T) {
 		assert.Equal(t, &TimeSettings{}, updatedPubdash.TimeSettings)
 	})
 
+	t.Run("Updating sets and removes expiration and passphrase", func(t *testing.T) {
+		isEnabled := true
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		passphrase := "correct horse battery"
+
+		dto := &SavePublicDashboardDTO{
+			DashboardUid: dashboard2.UID,
+			UserId:       7,
+			PublicDashboard: &PublicDashboardDTO{
+				IsEnabled: &isEnabled,
+			},
+		}
+
+		savedPubdash, err := service.Create(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		assert.Nil(t, savedPubdash.ExpiresAt)
+		assert.False(t, savedPubdash.PassphraseEnabled)
+
+		dto = &SavePublicDashboardDTO{
+			Uid:          savedPubdash.Uid,
+			DashboardUid: dashboard2.UID,
+			UserId:       8,
+			PublicDashboard: &PublicDashboardDTO{
+				ExpiresAt:  &expiresAt,
+				Passphrase: &passphrase,
+			},
+		}
+
+		updatedPubdash, err := service.Update(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		require.NotNil(t, updatedPubdash.ExpiresAt)
+		assert.True(t, expiresAt.Equal(*updatedPubdash.ExpiresAt))
+		assert.True(t, updatedPubdash.RequiresPassphrase())
+		assert.True(t, updatedPubdash.VerifyPassphrase(passphrase))
+		assert.False(t, updatedPubdash.VerifyPassphrase("wrong passphrase"))
+
+		// nil values keep the current settings
+		dto.PublicDashboard = &PublicDashboardDTO{IsEnabled: &isEnabled}
+		updatedPubdash, err = service.Update(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		assert.NotNil(t, updatedPubdash.ExpiresAt)
+		assert.True(t, updatedPubdash.VerifyPassphrase(passphrase))
+
+		noPassphrase := ""
+		dto.PublicDashboard = &PublicDashboardDTO{ExpiresAt: &time.Time{}, Passphrase: &noPassphrase}
+		updatedPubdash, err = service.Update(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		assert.Nil(t, updatedPubdash.ExpiresAt)
+		assert.False(t, updatedPubdash.PassphraseEnabled)
+		assert.Empty(t, updatedPubdash.PassphraseHash)
+	})
+
 	t.Run("Should fail when public dashboard uid does not match dashboard uid", func(t *testing.T) {
 		isEnabled := true
 
@@ -1666,6 +1729,35 @@ func TestDeleteByDashboard(t *testing.T) {
 	})
 }
 
+func TestDisableExpired(t *testing.T) {
+	t.Run("will disable expired pubdashes", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		pd := &PublicDashboardServiceImpl{store: store, serviceWrapper: ProvideServiceWrapper(store)}
+		pubdash1 := &PublicDashboard{Uid: "2", OrgId: 1, DashboardUid: "1", IsEnabled: true, AccessToken: "token1"}
+		pubdash2 := &PublicDashboard{Uid: "3", OrgId: 1, DashboardUid: "4", IsEnabled: true, AccessToken: "token2"}
+		store.On("FindExpired", mock.Anything, mock.Anything).Return([]*PublicDashboard{pubdash1, pubdash2}, nil)
+		for _, uid := range []string{"2", "3"} {
+			store.On("Update", mock.Anything, mock.MatchedBy(func(cmd SavePublicDashboardCommand) bool {
+				return cmd.PublicDashboard.Uid == uid && !cmd.PublicDashboard.IsEnabled && cmd.PublicDashboard.AccessToken != ""
+			})).Return(int64(1), nil).Once()
+		}
+
+		disabled, err := pd.DisableExpired(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), disabled)
+		store.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("will return error when finding expired pubdashes fails", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		pd := &PublicDashboardServiceImpl{store: store, serviceWrapper: ProvideServiceWrapper(store)}
+		store.On("FindExpired", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		_, err := pd.DisableExpired(context.Background())
+		require.ErrorIs(t, err, ErrInternalServerError)
+	})
+}
+
 func TestGenerateAccessToken(t *testing.T) {
 	accessToken, err := GenerateAccessToken()
 
@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -13,6 +15,19 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if expiresAt := dto.PublicDashboard.ExpiresAt; expiresAt != nil && !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return ErrInvalidExpiration.Errorf("ValidateSavePublicDashboard: expiration is in the past")
+	}
+
+	if passphrase := dto.PublicDashboard.Passphrase; passphrase != nil && *passphrase != "" {
+		if len(*passphrase) < MinPassphraseLength {
+			return ErrInvalidPassphrase.Errorf("ValidateSavePublicDashboard: passphrase is too short")
+		}
+		if dto.PublicDashboard.Share == EmailShareType {
+			return ErrPassphraseNotSupported.Errorf("ValidateSavePublicDashboard: passphrase is not supported for email share type")
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,166 @@
+package report
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var (
+	ErrReportBadRequest       = errutil.BadRequest("report.bad-request")
+	ErrReportNotFound         = errutil.NotFound("report.not-found", errutil.WithPublicMessage("Report not found"))
+	ErrReportMissingFields    = errutil.ValidationFailed("report.missing-fields", errutil.WithPublicMessage("Report name and dashboard are required"))
+	ErrReportInvalidFormat    = errutil.ValidationFailed("report.invalid-format", errutil.WithPublicMessage("Report format must be one of pdf, png or csv"))
+	ErrReportPanelRequired    = errutil.ValidationFailed("report.panel-required", errutil.WithPublicMessage("CSV reports require a panel"))
+	ErrReportInvalidSchedule  = errutil.ValidationFailed("report.invalid-schedule", errutil.WithPublicMessage("Invalid cron schedule"))
+	ErrReportInvalidTimezone  = errutil.ValidationFailed("report.invalid-timezone", errutil.WithPublicMessage("Invalid timezone"))
+	ErrReportNoRecipients     = errutil.ValidationFailed("report.no-recipients", errutil.WithPublicMessage("Report requires email recipients or a webhook URL"))
+	ErrReportInvalidRecipient = errutil.ValidationFailed("report.invalid-recipient", errutil.WithPublicMessage("Invalid email recipient"))
+	ErrReportInvalidWebhook   = errutil.ValidationFailed("report.invalid-webhook", errutil.WithPublicMessage("Webhook URL must be an absolute http or https URL"))
+	ErrReportWebhookInternal  = errutil.ValidationFailed("report.webhook-internal", errutil.WithPublicMessage("Webhook URL must not point to an internal address"))
+	ErrReportDashboardAccess  = errutil.Forbidden("report.dashboard-access", errutil.WithPublicMessage("Missing permission to read the report dashboard"))
+	ErrReportAccessDenied     = errutil.Forbidden("report.access-denied", errutil.WithPublicMessage("Missing permission to access the report"))
+)
+
+const (
+	ActionRead   = "reports:read"
+	ActionCreate = "reports:create"
+	ActionWrite  = "reports:write"
+	ActionDelete = "reports:delete"
+	ActionSend   = "reports:send"
+)
+
+var (
+	// ScopeProvider builds report scopes from report ids, e.g. reports:id:1.
+	ScopeProvider = accesscontrol.NewScopeProvider("reports")
+	ScopeAll      = ScopeProvider.GetResourceAllScope()
+)
+
+type Format string
+
+const (
+	FormatPDF Format = "pdf"
+	FormatPNG Format = "png"
+	FormatCSV Format = "csv"
+)
+
+func (f Format) IsValid() bool {
+	return f == FormatPDF || f == FormatPNG || f == FormatCSV
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSuccess DeliveryStatus = "success"
+	DeliveryStatusError   DeliveryStatus = "error"
+)
+
+type DeliveryTrigger string
+
+const (
+	DeliveryTriggerSchedule DeliveryTrigger = "schedule"
+	DeliveryTriggerManual   DeliveryTrigger = "manual"
+)
+
+// Report renders a dashboard or a dashboard panel on a cron schedule and delivers it
+// to email recipients and/or a webhook. Reports are rendered as the user that last saved them.
+type Report struct {
+	ID           int64               `json:"id" xorm:"pk autoincr 'id'"`
+	UID          string              `json:"uid" xorm:"uid"`
+	OrgID        int64               `json:"orgId" xorm:"org_id"`
+	UserID       int64               `json:"userId" xorm:"user_id"`
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid" xorm:"dashboard_uid"`
+	PanelID      int64               `json:"panelId,omitempty" xorm:"panel_id"`
+	Variables    map[string][]string `json:"variables,omitempty"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Timezone     string              `json:"timezone,omitempty"`
+	Format       Format              `json:"format"`
+	Schedule     string              `json:"schedule"`
+	Recipients   []string            `json:"recipients,omitempty"`
+	WebhookURL   string              `json:"webhookUrl,omitempty" xorm:"webhook_url"`
+	Enabled      bool                `json:"enabled"`
+	NextRun      *time.Time          `json:"nextRun,omitempty"`
+	Created      time.Time           `json:"created"`
+	Updated      time.Time           `json:"updated"`
+}
+
+// Delivery is an entry of the delivery history of a report.
+type Delivery struct {
+	ID         int64           `json:"id" xorm:"pk autoincr 'id'"`
+	ReportID   int64           `json:"reportId" xorm:"report_id"`
+	OrgID      int64           `json:"-" xorm:"org_id"`
+	Trigger    DeliveryTrigger `json:"trigger" xorm:"triggered_by"`
+	Status     DeliveryStatus  `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt time.Time       `json:"finishedAt"`
+}
+
+func (d Delivery) TableName() string {
+	return "report_delivery"
+}
+
+type CreateReportCommand struct {
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	PanelID      int64               `json:"panelId"`
+	Variables    map[string][]string `json:"variables"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Timezone     string              `json:"timezone"`
+	Format       Format              `json:"format"`
+	Schedule     string              `json:"schedule"`
+	Recipients   []string            `json:"recipients"`
+	WebhookURL   string              `json:"webhookUrl"`
+	Enabled      bool                `json:"enabled"`
+
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+}
+
+type UpdateReportCommand struct {
+	UID          string              `json:"-"`
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	PanelID      int64               `json:"panelId"`
+	Variables    map[string][]string `json:"variables"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Timezone     string              `json:"timezone"`
+	Format       Format              `json:"format"`
+	Schedule     string              `json:"schedule"`
+	Recipients   []string            `json:"recipients"`
+	WebhookURL   string              `json:"webhookUrl"`
+	Enabled      bool                `json:"enabled"`
+
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+}
+
+type GetReportQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type ListReportsQuery struct {
+	OrgID int64
+}
+
+type DeleteReportCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type SendReportCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type ListDeliveriesQuery struct {
+	UID   string
+	OrgID int64
+	Limit int
+}
@@ -0,0 +1,17 @@
+package report
+
+import (
+	"context"
+)
+
+type Service interface {
+	Create(context.Context, *CreateReportCommand) (*Report, error)
+	Update(context.Context, *UpdateReportCommand) (*Report, error)
+	Get(context.Context, *GetReportQuery) (*Report, error)
+	List(context.Context, *ListReportsQuery) ([]*Report, error)
+	Delete(context.Context, *DeleteReportCommand) error
+
+	// Send renders and delivers the report immediately, independently of its schedule.
+	Send(context.Context, *SendReportCommand) (*Delivery, error)
+	ListDeliveries(context.Context, *ListDeliveriesQuery) ([]*Delivery, error)
+}
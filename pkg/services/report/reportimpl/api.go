@@ -0,0 +1,207 @@
+package reportimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/report"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// registerAPIEndpoints registers the report endpoints. Requests on a report are checked against the report scope,
+// reports:id:<id>, and require read access to the report dashboard.
+func (s *Service) registerAPIEndpoints() {
+	authorize := accesscontrol.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/dashboard-reports", func(entities routing.RouteRegister) {
+		entities.Get("/", authorize(accesscontrol.EvalPermission(report.ActionRead)), routing.Wrap(s.listHandler))
+		entities.Post("/", authorize(accesscontrol.EvalPermission(report.ActionCreate)), routing.Wrap(s.createHandler))
+		entities.Get("/:uid", authorize(accesscontrol.EvalPermission(report.ActionRead)), routing.Wrap(s.getHandler))
+		entities.Put("/:uid", authorize(accesscontrol.EvalPermission(report.ActionWrite)), routing.Wrap(s.updateHandler))
+		entities.Delete("/:uid", authorize(accesscontrol.EvalPermission(report.ActionDelete)), routing.Wrap(s.deleteHandler))
+		entities.Post("/:uid/send", authorize(accesscontrol.EvalPermission(report.ActionSend)), routing.Wrap(s.sendHandler))
+		entities.Get("/:uid/deliveries", authorize(accesscontrol.EvalPermission(report.ActionRead)), routing.Wrap(s.listDeliveriesHandler))
+	}, middleware.ReqSignedIn)
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.List(c.Req.Context(), &report.ListReportsQuery{OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list reports", err)
+	}
+
+	// reports the user cannot access are left out
+	filtered := make([]*report.Report, 0, len(reports))
+	for _, r := range reports {
+		ok, err := s.canAccessReport(c, report.ActionRead, r)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to check report permissions", err)
+		}
+		if ok {
+			filtered = append(filtered, r)
+		}
+	}
+	return response.JSON(http.StatusOK, filtered)
+}
+
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	r, resp := s.getAccessibleReport(c, report.ActionRead)
+	if resp != nil {
+		return resp
+	}
+	return response.JSON(http.StatusOK, r)
+}
+
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := report.CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(report.ErrReportBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	if resp := s.checkDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Err(report.ErrReportBadRequest.Errorf("reports can only be created by users and service accounts: %w", err))
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = userID
+	r, err := s.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create report", err)
+	}
+	return response.JSON(http.StatusOK, r)
+}
+
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := report.UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(report.ErrReportBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	if _, resp := s.getAccessibleReport(c, report.ActionWrite); resp != nil {
+		return resp
+	}
+
+	if resp := s.checkDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Err(report.ErrReportBadRequest.Errorf("reports can only be updated by users and service accounts: %w", err))
+	}
+
+	// the report is rendered as the user that last saved it
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = userID
+	r, err := s.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update report", err)
+	}
+	return response.JSON(http.StatusOK, r)
+}
+
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if _, resp := s.getAccessibleReport(c, report.ActionDelete); resp != nil {
+		return resp
+	}
+
+	err := s.Delete(c.Req.Context(), &report.DeleteReportCommand{UID: web.Params(c.Req)[":uid"], OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete report", err)
+	}
+	return response.Success("Report deleted")
+}
+
+func (s *Service) sendHandler(c *contextmodel.ReqContext) response.Response {
+	if _, resp := s.getAccessibleReport(c, report.ActionSend); resp != nil {
+		return resp
+	}
+
+	delivery, err := s.Send(c.Req.Context(), &report.SendReportCommand{UID: web.Params(c.Req)[":uid"], OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to send report", err)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
+
+func (s *Service) listDeliveriesHandler(c *contextmodel.ReqContext) response.Response {
+	if _, resp := s.getAccessibleReport(c, report.ActionRead); resp != nil {
+		return resp
+	}
+
+	deliveries, err := s.ListDeliveries(c.Req.Context(), &report.ListDeliveriesQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+		Limit: c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list report deliveries", err)
+	}
+	return response.JSON(http.StatusOK, deliveries)
+}
+
+// checkDashboardAccess makes sure users can only create reports of dashboards they can read,
+// the reports are rendered with their permissions.
+func (s *Service) checkDashboardAccess(c *contextmodel.ReqContext, dashboardUID string) response.Response {
+	if dashboardUID == "" {
+		return response.Err(report.ErrReportMissingFields.Errorf("report without dashboard"))
+	}
+
+	evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID))
+	canRead, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+	if !canRead {
+		return response.Err(report.ErrReportDashboardAccess.Errorf("user cannot read dashboard %s", dashboardUID))
+	}
+	return nil
+}
+
+// getAccessibleReport returns the report of the request when the user has the action on it and owns it or can read
+// its dashboard.
+func (s *Service) getAccessibleReport(c *contextmodel.ReqContext, action string) (*report.Report, response.Response) {
+	r, err := s.Get(c.Req.Context(), &report.GetReportQuery{UID: web.Params(c.Req)[":uid"], OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "Failed to get report", err)
+	}
+
+	ok, err := s.canAccessReport(c, action, r)
+	if err != nil {
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "Failed to check report permissions", err)
+	}
+	if !ok {
+		return nil, response.Err(report.ErrReportAccessDenied.Errorf("user cannot %s report %s", action, r.UID))
+	}
+	return r, nil
+}
+
+// canAccessReport checks the action on the report scope. Reports contain the rendered dashboard, so users other than
+// the report owner must also be able to read the report dashboard.
+func (s *Service) canAccessReport(c *contextmodel.ReqContext, action string, r *report.Report) (bool, error) {
+	ctx := c.Req.Context()
+	ok, err := s.accessControl.Evaluate(ctx, c.SignedInUser, accesscontrol.EvalPermission(action, report.ScopeProvider.GetResourceScope(strconv.FormatInt(r.ID, 10))))
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if userID, err := identity.UserIdentifier(c.SignedInUser.GetID()); err == nil && userID == r.UserID {
+		return true, nil
+	}
+
+	return s.accessControl.Evaluate(ctx, c.SignedInUser,
+		accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(r.DashboardUID)))
+}
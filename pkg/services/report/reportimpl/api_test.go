@@ -0,0 +1,103 @@
+package reportimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/report"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func setupTestServer(t *testing.T, s *Service, usr *user.SignedInUser) *web.Mux {
+	t.Helper()
+	rr := routing.NewRouteRegister()
+	s.routeRegister = rr
+	s.accessControl = acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+	s.registerAPIEndpoints()
+
+	m := web.New()
+	m.Use(func(c *web.Context) {
+		reqCtx := &contextmodel.ReqContext{Context: c, SignedInUser: usr, IsSignedIn: true, Logger: log.NewNopLogger()}
+		c.Req = c.Req.WithContext(ctxkey.Set(c.Req.Context(), reqCtx))
+	})
+	rr.Register(m.Router)
+	return m
+}
+
+func TestReportAPIAccess(t *testing.T) {
+	s, _, _ := setupTestService(t)
+	cmd := validCreateCommand()
+	cmd.WebhookURL = ""
+	// the report of another user
+	cmd.UserID = 20
+	r, err := s.Create(context.Background(), cmd)
+	require.NoError(t, err)
+	reportScope := report.ScopeProvider.GetResourceScope(strconv.FormatInt(r.ID, 10))
+	dashboardScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID("dash")
+
+	call := func(t *testing.T, userID int64, permissions map[string][]string, method, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		usr := &user.SignedInUser{UserID: userID, OrgID: 1, Permissions: map[int64]map[string][]string{1: permissions}}
+		server := setupTestServer(t, s, usr)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+	list := func(t *testing.T, recorder *httptest.ResponseRecorder) []*report.Report {
+		t.Helper()
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var reports []*report.Report
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &reports))
+		return reports
+	}
+
+	t.Run("users need the report action and read access to the report dashboard", func(t *testing.T) {
+		permissions := map[string][]string{
+			report.ActionRead:               {reportScope},
+			dashboards.ActionDashboardsRead: {dashboardScope},
+		}
+		require.Equal(t, http.StatusOK, call(t, 10, permissions, http.MethodGet, "/api/dashboard-reports/"+r.UID).Code)
+		require.Len(t, list(t, call(t, 10, permissions, http.MethodGet, "/api/dashboard-reports/")), 1)
+		require.Equal(t, http.StatusForbidden, call(t, 10, permissions, http.MethodDelete, "/api/dashboard-reports/"+r.UID).Code)
+		require.Equal(t, http.StatusForbidden, call(t, 10, permissions, http.MethodPost, "/api/dashboard-reports/"+r.UID+"/send").Code)
+
+		delete(permissions, dashboards.ActionDashboardsRead)
+		require.Equal(t, http.StatusForbidden, call(t, 10, permissions, http.MethodGet, "/api/dashboard-reports/"+r.UID).Code)
+		require.Equal(t, http.StatusForbidden, call(t, 10, permissions, http.MethodGet, "/api/dashboard-reports/"+r.UID+"/deliveries").Code)
+		require.Empty(t, list(t, call(t, 10, permissions, http.MethodGet, "/api/dashboard-reports/")))
+	})
+
+	t.Run("report owners do not need read access to the dashboard", func(t *testing.T) {
+		permissions := map[string][]string{report.ActionRead: {reportScope}}
+		require.Equal(t, http.StatusOK, call(t, 20, permissions, http.MethodGet, "/api/dashboard-reports/"+r.UID).Code)
+	})
+
+	t.Run("the report action is checked on the report scope", func(t *testing.T) {
+		permissions := map[string][]string{
+			report.ActionRead:               {report.ScopeProvider.GetResourceScope("0")},
+			report.ActionDelete:             {report.ScopeProvider.GetResourceScope("0")},
+			dashboards.ActionDashboardsRead: {dashboardScope},
+		}
+		require.Equal(t, http.StatusForbidden, call(t, 20, permissions, http.MethodGet, "/api/dashboard-reports/"+r.UID).Code)
+		require.Equal(t, http.StatusForbidden, call(t, 20, permissions, http.MethodDelete, "/api/dashboard-reports/"+r.UID).Code)
+		require.Empty(t, list(t, call(t, 20, permissions, http.MethodGet, "/api/dashboard-reports/")))
+
+		permissions[report.ActionDelete] = []string{report.ScopeAll}
+		require.Equal(t, http.StatusOK, call(t, 20, permissions, http.MethodDelete, "/api/dashboard-reports/"+r.UID).Code)
+	})
+}
@@ -0,0 +1,223 @@
+package reportimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/report"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	renderTimeout = 60 * time.Second
+
+	emailTemplate = "report"
+)
+
+// renderedReport is the file produced by rendering a report.
+type renderedReport struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// webhookPayload is the body posted to report webhooks.
+type webhookPayload struct {
+	UID          string    `json:"uid"`
+	Name         string    `json:"name"`
+	DashboardUID string    `json:"dashboardUid"`
+	DashboardURL string    `json:"dashboardUrl"`
+	Format       string    `json:"format"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Content      string    `json:"content"`
+	GeneratedAt  time.Time `json:"generatedAt"`
+}
+
+// deliver renders the report as its creator and sends it to all the report destinations.
+func (s *Service) deliver(ctx context.Context, r *report.Report) error {
+	usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: r.UserID, OrgID: r.OrgID})
+	if err != nil {
+		return fmt.Errorf("failed to get report user: %w", err)
+	}
+
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: r.DashboardUID, OrgID: r.OrgID})
+	if err != nil {
+		return fmt.Errorf("failed to get report dashboard: %w", err)
+	}
+
+	file, err := s.render(ctx, r, usr)
+	if err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	dashboardURL := s.cfg.AppURL + dashboardPath("d", r)
+
+	var errs []error
+	if len(r.Recipients) > 0 {
+		if err := s.sendEmail(ctx, r, dash, dashboardURL, file); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send report email: %w", err))
+		}
+	}
+	if r.WebhookURL != "" {
+		if err := s.sendWebhook(ctx, r, dashboardURL, file); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send report webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) render(ctx context.Context, r *report.Report, usr *user.SignedInUser) (*renderedReport, error) {
+	opts := rendering.CommonOpts{
+		TimeoutOpts: rendering.TimeoutOpts{Timeout: renderTimeout},
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   r.OrgID,
+			UserID:  usr.UserID,
+			OrgRole: usr.OrgRole,
+		},
+		Timezone:        r.Timezone,
+		ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+	}
+
+	fileName := slugify.Slugify(r.Name) + "." + string(r.Format)
+	var filePath, contentType string
+	switch r.Format {
+	case report.FormatCSV:
+		opts.Path = dashboardPath("d-csv", r)
+		result, err := s.renderingService.RenderCSV(ctx, rendering.CSVOpts{CommonOpts: opts}, nil)
+		if err != nil {
+			return nil, err
+		}
+		filePath, contentType = result.FilePath, "text/csv"
+	case report.FormatPNG, report.FormatPDF:
+		renderType, prefix := rendering.RenderPNG, "d"
+		contentType = "image/png"
+		if r.Format == report.FormatPDF {
+			renderType, contentType = rendering.RenderPDF, "application/pdf"
+		}
+		if r.PanelID > 0 {
+			prefix = "d-solo"
+		}
+		opts.Path = dashboardPath(prefix, r)
+
+		result, err := s.renderingService.Render(ctx, renderType, rendering.Opts{
+			CommonOpts: opts,
+			ErrorOpts: rendering.ErrorOpts{
+				ErrorConcurrentLimitReached: true,
+				ErrorRenderUnavailable:      true,
+			},
+			Width:             s.cfg.RendererDefaultImageWidth,
+			Height:            s.cfg.RendererDefaultImageHeight,
+			DeviceScaleFactor: s.cfg.RendererDefaultImageScale,
+			Theme:             models.ThemeLight,
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		filePath = result.FilePath
+	default:
+		return nil, report.ErrReportInvalidFormat.Errorf("invalid report format %q", r.Format)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &renderedReport{FileName: fileName, ContentType: contentType, Content: content}, nil
+}
+
+func (s *Service) sendEmail(ctx context.Context, r *report.Report, dash *dashboards.Dashboard, dashboardURL string, file *renderedReport) error {
+	return s.notificationService.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+		SendEmailCommand: notifications.SendEmailCommand{
+			To:       r.Recipients,
+			Template: emailTemplate,
+			Data: map[string]any{
+				"ReportName":     r.Name,
+				"DashboardTitle": dash.Title,
+				"DashboardUrl":   dashboardURL,
+				"TimeFrom":       r.TimeFrom,
+				"TimeTo":         r.TimeTo,
+			},
+			AttachedFiles: []*notifications.SendEmailAttachFile{{Name: file.FileName, Content: file.Content}},
+		},
+	})
+}
+
+func (s *Service) sendWebhook(ctx context.Context, r *report.Report, dashboardURL string, file *renderedReport) error {
+	body, err := json.Marshal(webhookPayload{
+		UID:          r.UID,
+		Name:         r.Name,
+		DashboardUID: r.DashboardUID,
+		DashboardURL: dashboardURL,
+		Format:       string(r.Format),
+		FileName:     file.FileName,
+		ContentType:  file.ContentType,
+		Content:      base64.StdEncoding.EncodeToString(file.Content),
+		GeneratedAt:  s.now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grafana")
+
+	resp, err := s.webhookClient.Do(req)
+	if err != nil {
+		// the error is stored in the delivery history, it must not contain the webhook credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = req.URL.Redacted()
+		}
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close report webhook response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// dashboardPath returns the relative URL of the report dashboard, panel or panel CSV export
+// with the report time range and variables.
+func dashboardPath(prefix string, r *report.Report) string {
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(r.OrgID, 10))
+	params.Set("from", r.TimeFrom)
+	params.Set("to", r.TimeTo)
+	if r.PanelID > 0 && prefix != "d" {
+		params.Set("panelId", strconv.FormatInt(r.PanelID, 10))
+	}
+
+	for name, values := range r.Variables {
+		for _, value := range values {
+			params.Add("var-"+name, value)
+		}
+	}
+
+	return fmt.Sprintf("%s/%s/?%s", prefix, r.DashboardUID, params.Encode())
+}
@@ -0,0 +1,324 @@
+package reportimpl
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/report"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	schedulerInterval = time.Minute
+
+	defaultTimeFrom = "now-24h"
+	defaultTimeTo   = "now"
+)
+
+type Service struct {
+	store               store
+	cfg                 *setting.Cfg
+	routeRegister       routing.RouteRegister
+	accessControl       accesscontrol.AccessControl
+	dashboardService    dashboards.DashboardService
+	userService         user.Service
+	renderingService    rendering.Service
+	notificationService notifications.Service
+	serverLock          *serverlock.ServerLockService
+	webhookClient       *http.Client
+	log                 log.Logger
+	now                 func() time.Time
+}
+
+var _ report.Service = &Service{}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	accesscontrolService accesscontrol.Service, dashboardService dashboards.DashboardService, userService user.Service,
+	renderingService rendering.Service, notificationService notifications.Service, serverLock *serverlock.ServerLockService) (*Service, error) {
+	s := &Service{
+		store:               &sqlStore{db: db},
+		cfg:                 cfg,
+		routeRegister:       routeRegister,
+		accessControl:       accessControl,
+		dashboardService:    dashboardService,
+		userService:         userService,
+		renderingService:    renderingService,
+		notificationService: notificationService,
+		serverLock:          serverLock,
+		webhookClient:       newWebhookClient(cfg.DashboardReportsWebhookAllowedHosts),
+		log:                 log.New("report"),
+		now:                 time.Now,
+	}
+
+	if err := declareFixedRoles(accesscontrolService); err != nil {
+		return nil, err
+	}
+
+	if cfg.DashboardReportsEnabled {
+		s.registerAPIEndpoints()
+	}
+
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.DashboardReportsEnabled
+}
+
+// Run sends the reports when they are due. Only one instance of the scheduler sends reports in HA setups.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, "send scheduled reports", schedulerInterval, func(ctx context.Context) {
+				s.sendDueReports(ctx)
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute sending of scheduled reports", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) Create(ctx context.Context, cmd *report.CreateReportCommand) (*report.Report, error) {
+	now := s.now()
+	r := &report.Report{
+		UID:          util.GenerateShortUID(),
+		OrgID:        cmd.OrgID,
+		UserID:       cmd.UserID,
+		Name:         cmd.Name,
+		DashboardUID: cmd.DashboardUID,
+		PanelID:      cmd.PanelID,
+		Variables:    cmd.Variables,
+		TimeFrom:     cmd.TimeFrom,
+		TimeTo:       cmd.TimeTo,
+		Timezone:     cmd.Timezone,
+		Format:       cmd.Format,
+		Schedule:     cmd.Schedule,
+		Recipients:   cmd.Recipients,
+		WebhookURL:   cmd.WebhookURL,
+		Enabled:      cmd.Enabled,
+		Created:      now,
+		Updated:      now,
+	}
+	if err := s.prepare(ctx, r); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Insert(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *report.UpdateReportCommand) (*report.Report, error) {
+	r, err := s.store.Get(ctx, &report.GetReportQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	r.UserID = cmd.UserID
+	r.Name = cmd.Name
+	r.DashboardUID = cmd.DashboardUID
+	r.PanelID = cmd.PanelID
+	r.Variables = cmd.Variables
+	r.TimeFrom = cmd.TimeFrom
+	r.TimeTo = cmd.TimeTo
+	r.Timezone = cmd.Timezone
+	r.Format = cmd.Format
+	r.Schedule = cmd.Schedule
+	r.Recipients = cmd.Recipients
+	r.WebhookURL = cmd.WebhookURL
+	r.Enabled = cmd.Enabled
+	r.Updated = s.now()
+	if err := s.prepare(ctx, r); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Service) Get(ctx context.Context, query *report.GetReportQuery) (*report.Report, error) {
+	return s.store.Get(ctx, query)
+}
+
+func (s *Service) List(ctx context.Context, query *report.ListReportsQuery) ([]*report.Report, error) {
+	return s.store.List(ctx, query)
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *report.DeleteReportCommand) error {
+	return s.store.Delete(ctx, cmd)
+}
+
+func (s *Service) Send(ctx context.Context, cmd *report.SendReportCommand) (*report.Delivery, error) {
+	r, err := s.store.Get(ctx, &report.GetReportQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	return s.send(ctx, r, report.DeliveryTriggerManual)
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, query *report.ListDeliveriesQuery) ([]*report.Delivery, error) {
+	r, err := s.store.Get(ctx, &report.GetReportQuery{UID: query.UID, OrgID: query.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListDeliveries(ctx, r.ID, query.Limit)
+}
+
+func (s *Service) sendDueReports(ctx context.Context) {
+	now := s.now()
+	reports, err := s.store.GetDue(ctx, now)
+	if err != nil {
+		s.log.Error("Failed to get scheduled reports", "error", err)
+		return
+	}
+
+	for _, r := range reports {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// schedule the next run first, so a report failing to send is not sent again on every tick
+		nextRun, err := getNextRun(r.Schedule, r.Timezone, now)
+		if err != nil {
+			s.log.Error("Failed to schedule report", "uid", r.UID, "orgId", r.OrgID, "error", err)
+		}
+		if err := s.store.SetNextRun(ctx, r.ID, nextRun); err != nil {
+			s.log.Error("Failed to schedule report", "uid", r.UID, "orgId", r.OrgID, "error", err)
+			continue
+		}
+
+		if _, err := s.send(ctx, r, report.DeliveryTriggerSchedule); err != nil {
+			s.log.Error("Failed to store report delivery", "uid", r.UID, "orgId", r.OrgID, "error", err)
+		}
+	}
+}
+
+// send renders and delivers the report and records the delivery in the report history.
+func (s *Service) send(ctx context.Context, r *report.Report, trigger report.DeliveryTrigger) (*report.Delivery, error) {
+	delivery := &report.Delivery{
+		ReportID:  r.ID,
+		OrgID:     r.OrgID,
+		Trigger:   trigger,
+		Status:    report.DeliveryStatusSuccess,
+		StartedAt: s.now(),
+	}
+
+	if err := s.deliver(ctx, r); err != nil {
+		s.log.Warn("Failed to send report", "uid", r.UID, "orgId", r.OrgID, "error", err)
+		delivery.Status = report.DeliveryStatusError
+		delivery.Error = err.Error()
+	}
+	delivery.FinishedAt = s.now()
+
+	if err := s.store.InsertDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// prepare applies the defaults, validates the report and schedules its next run.
+func (s *Service) prepare(ctx context.Context, r *report.Report) error {
+	if strings.TrimSpace(r.Name) == "" || r.DashboardUID == "" {
+		return report.ErrReportMissingFields.Errorf("report name and dashboard are required")
+	}
+
+	if r.Format == "" {
+		r.Format = report.FormatPDF
+	}
+	if !r.Format.IsValid() {
+		return report.ErrReportInvalidFormat.Errorf("invalid report format %q", r.Format)
+	}
+	if r.Format == report.FormatCSV && r.PanelID == 0 {
+		return report.ErrReportPanelRequired.Errorf("csv report without panel")
+	}
+
+	if r.TimeFrom == "" {
+		r.TimeFrom = defaultTimeFrom
+	}
+	if r.TimeTo == "" {
+		r.TimeTo = defaultTimeTo
+	}
+
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		return report.ErrReportInvalidTimezone.Errorf("invalid timezone %q: %w", r.Timezone, err)
+	}
+
+	if len(r.Recipients) == 0 && r.WebhookURL == "" {
+		return report.ErrReportNoRecipients.Errorf("report without recipients")
+	}
+	for _, recipient := range r.Recipients {
+		if !util.IsEmail(recipient) {
+			return report.ErrReportInvalidRecipient.Errorf("invalid email recipient %q", recipient)
+		}
+	}
+	if r.WebhookURL != "" {
+		u, err := url.Parse(r.WebhookURL)
+		if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") {
+			return report.ErrReportInvalidWebhook.Errorf("invalid webhook URL")
+		}
+		// host names are checked again when connecting, they can resolve to other addresses later
+		if isInternalHost(u.Hostname()) && !isAllowedHost(s.cfg.DashboardReportsWebhookAllowedHosts, u.Hostname()) {
+			return report.ErrReportWebhookInternal.Errorf("webhook URL %s points to an internal address", u.Redacted())
+		}
+	}
+
+	if _, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: r.DashboardUID, OrgID: r.OrgID}); err != nil {
+		return err
+	}
+
+	nextRun, err := getNextRun(r.Schedule, r.Timezone, s.now())
+	if err != nil {
+		return err
+	}
+	r.NextRun = nil
+	if r.Enabled {
+		r.NextRun = nextRun
+	}
+
+	return nil
+}
+
+// getNextRun returns the next time after now matching the cron schedule in the given timezone.
+func getNextRun(schedule string, timezone string, now time.Time) (*time.Time, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, report.ErrReportInvalidSchedule.Errorf("invalid cron schedule %q: %w", schedule, err)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, report.ErrReportInvalidTimezone.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	next := sched.Next(now.In(loc))
+	if next.IsZero() {
+		return nil, report.ErrReportInvalidSchedule.Errorf("cron schedule %q never runs", schedule)
+	}
+	next = next.UTC()
+	return &next, nil
+}
@@ -0,0 +1,381 @@
+package reportimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/report"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var testNow = time.Date(2024, 3, 15, 7, 30, 0, 0, time.UTC)
+
+func setupTestService(t *testing.T) (*Service, *rendering.MockService, *notifications.NotificationServiceMock) {
+	t.Helper()
+
+	dashboardService := &dashboards.FakeDashboardService{}
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+		return q.UID == "dash"
+	})).Return(&dashboards.Dashboard{UID: "dash", OrgID: 1, Title: "Sales"}, nil)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound)
+
+	renderingService := rendering.NewMockService(gomock.NewController(t))
+	notificationService := &notifications.NotificationServiceMock{}
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.RendererDefaultImageWidth = 1000
+	cfg.RendererDefaultImageHeight = 500
+
+	s := &Service{
+		store:               &sqlStore{db: db.InitTestDB(t)},
+		cfg:                 cfg,
+		dashboardService:    dashboardService,
+		userService:         &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 10, OrgID: 1, OrgRole: org.RoleEditor}},
+		renderingService:    renderingService,
+		notificationService: notificationService,
+		webhookClient:       newWebhookClient(nil),
+		log:                 log.NewNopLogger(),
+		now:                 func() time.Time { return testNow },
+	}
+	return s, renderingService, notificationService
+}
+
+func validCreateCommand() *report.CreateReportCommand {
+	return &report.CreateReportCommand{
+		Name:         "Daily sales",
+		DashboardUID: "dash",
+		Variables:    map[string][]string{"region": {"eu", "us"}},
+		TimeFrom:     "now-1d/d",
+		TimeTo:       "now-1d/d",
+		Timezone:     "Europe/Paris",
+		Schedule:     "0 8 * * *",
+		Recipients:   []string{"partner@example.com"},
+		WebhookURL:   "https://example.com/hook",
+		Enabled:      true,
+		OrgID:        1,
+		UserID:       10,
+	}
+}
+
+// testWebhook records the bodies posted to a local webhook, the service is allowed to post to it.
+type testWebhook struct {
+	URL    string
+	bodies chan []byte
+}
+
+func serveTestWebhook(t *testing.T, s *Service) *testWebhook {
+	t.Helper()
+	hook := &testWebhook{bodies: make(chan []byte, 10)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		hook.bodies <- body
+	}))
+	t.Cleanup(server.Close)
+
+	hook.URL = server.URL + "/hook"
+	s.cfg.DashboardReportsWebhookAllowedHosts = []string{"127.0.0.1"}
+	s.webhookClient = newWebhookClient(s.cfg.DashboardReportsWebhookAllowedHosts)
+	return hook
+}
+
+func (h *testWebhook) next(t *testing.T) []byte {
+	t.Helper()
+	select {
+	case body := <-h.bodies:
+		return body
+	default:
+		require.FailNow(t, "webhook not called")
+		return nil
+	}
+}
+
+func writeRenderedFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rendered")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestCreateReport(t *testing.T) {
+	t.Run("applies the defaults and schedules the next run in the report timezone", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+
+		cmd := validCreateCommand()
+		cmd.TimeFrom, cmd.TimeTo = "", ""
+		r, err := s.Create(context.Background(), cmd)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, r.UID)
+		require.Equal(t, report.FormatPDF, r.Format)
+		require.Equal(t, defaultTimeFrom, r.TimeFrom)
+		require.Equal(t, defaultTimeTo, r.TimeTo)
+		// 08:00 in Paris is 07:00 UTC, which has passed already on the test day
+		require.Equal(t, time.Date(2024, 3, 16, 7, 0, 0, 0, time.UTC), *r.NextRun)
+	})
+
+	t.Run("does not schedule disabled reports", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+
+		cmd := validCreateCommand()
+		cmd.Enabled = false
+		r, err := s.Create(context.Background(), cmd)
+		require.NoError(t, err)
+		require.Nil(t, r.NextRun)
+	})
+
+	testCases := []struct {
+		desc   string
+		modify func(cmd *report.CreateReportCommand)
+		err    error
+	}{
+		{desc: "missing name", modify: func(cmd *report.CreateReportCommand) { cmd.Name = " " }, err: report.ErrReportMissingFields},
+		{desc: "invalid format", modify: func(cmd *report.CreateReportCommand) { cmd.Format = "docx" }, err: report.ErrReportInvalidFormat},
+		{desc: "csv without panel", modify: func(cmd *report.CreateReportCommand) { cmd.Format = report.FormatCSV }, err: report.ErrReportPanelRequired},
+		{desc: "invalid schedule", modify: func(cmd *report.CreateReportCommand) { cmd.Schedule = "every day" }, err: report.ErrReportInvalidSchedule},
+		{desc: "invalid timezone", modify: func(cmd *report.CreateReportCommand) { cmd.Timezone = "Mars/Olympus" }, err: report.ErrReportInvalidTimezone},
+		{desc: "invalid recipient", modify: func(cmd *report.CreateReportCommand) { cmd.Recipients = []string{"partner"} }, err: report.ErrReportInvalidRecipient},
+		{desc: "invalid webhook", modify: func(cmd *report.CreateReportCommand) { cmd.WebhookURL = "ftp://example.com" }, err: report.ErrReportInvalidWebhook},
+		{desc: "loopback webhook", modify: func(cmd *report.CreateReportCommand) { cmd.WebhookURL = "http://localhost:8080/hook" }, err: report.ErrReportWebhookInternal},
+		{desc: "private webhook", modify: func(cmd *report.CreateReportCommand) { cmd.WebhookURL = "http://10.0.0.5/hook" }, err: report.ErrReportWebhookInternal},
+		{desc: "link-local webhook", modify: func(cmd *report.CreateReportCommand) { cmd.WebhookURL = "http://169.254.169.254/latest/meta-data" }, err: report.ErrReportWebhookInternal},
+		{desc: "ipv6 loopback webhook", modify: func(cmd *report.CreateReportCommand) { cmd.WebhookURL = "http://[::1]/hook" }, err: report.ErrReportWebhookInternal},
+		{desc: "unknown dashboard", modify: func(cmd *report.CreateReportCommand) { cmd.DashboardUID = "unknown" }, err: dashboards.ErrDashboardNotFound},
+		{desc: "no destination", modify: func(cmd *report.CreateReportCommand) {
+			cmd.Recipients = nil
+			cmd.WebhookURL = ""
+		}, err: report.ErrReportNoRecipients},
+	}
+	for _, tc := range testCases {
+		t.Run("rejects reports with "+tc.desc, func(t *testing.T) {
+			s, _, _ := setupTestService(t)
+
+			cmd := validCreateCommand()
+			tc.modify(cmd)
+			_, err := s.Create(context.Background(), cmd)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestSendDueReports(t *testing.T) {
+	t.Run("renders the report as its creator and delivers it by email and webhook", func(t *testing.T) {
+		s, renderingService, notificationService := setupTestService(t)
+		hook := serveTestWebhook(t, s)
+
+		cmd := validCreateCommand()
+		cmd.WebhookURL = hook.URL
+		r, err := s.Create(context.Background(), cmd)
+		require.NoError(t, err)
+
+		renderingService.EXPECT().Render(gomock.Any(), rendering.RenderPDF, gomock.Any(), nil).
+			DoAndReturn(func(_ context.Context, _ rendering.RenderType, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+				require.Equal(t, "d/dash/?from=now-1d%2Fd&orgId=1&to=now-1d%2Fd&var-region=eu&var-region=us", opts.Path)
+				require.Equal(t, rendering.AuthOpts{OrgID: 1, UserID: 10, OrgRole: org.RoleEditor}, opts.AuthOpts)
+				require.Equal(t, "Europe/Paris", opts.Timezone)
+				return &rendering.RenderResult{FilePath: writeRenderedFile(t, "%PDF")}, nil
+			})
+
+		s.now = func() time.Time { return r.NextRun.Add(time.Second) }
+		s.sendDueReports(context.Background())
+
+		email := notificationService.EmailSync.SendEmailCommand
+		require.Equal(t, []string{"partner@example.com"}, email.To)
+		require.Equal(t, "report", email.Template)
+		require.Equal(t, "Sales", email.Data["DashboardTitle"])
+		require.Len(t, email.AttachedFiles, 1)
+		require.Equal(t, "daily-sales.pdf", email.AttachedFiles[0].Name)
+		require.Equal(t, []byte("%PDF"), email.AttachedFiles[0].Content)
+
+		var payload webhookPayload
+		require.NoError(t, json.Unmarshal(hook.next(t), &payload))
+		require.Equal(t, r.UID, payload.UID)
+		require.Equal(t, "application/pdf", payload.ContentType)
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte("%PDF")), payload.Content)
+
+		deliveries, err := s.ListDeliveries(context.Background(), &report.ListDeliveriesQuery{UID: r.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, report.DeliveryStatusSuccess, deliveries[0].Status)
+		require.Equal(t, report.DeliveryTriggerSchedule, deliveries[0].Trigger)
+
+		updated, err := s.Get(context.Background(), &report.GetReportQuery{UID: r.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, r.NextRun.Add(24*time.Hour), updated.NextRun.UTC())
+	})
+
+	t.Run("does not send reports before their next run", func(t *testing.T) {
+		s, _, notificationService := setupTestService(t)
+
+		_, err := s.Create(context.Background(), validCreateCommand())
+		require.NoError(t, err)
+
+		s.sendDueReports(context.Background())
+		require.Empty(t, notificationService.EmailSync.To)
+	})
+
+	t.Run("records failed deliveries and schedules the next run", func(t *testing.T) {
+		s, renderingService, notificationService := setupTestService(t)
+
+		r, err := s.Create(context.Background(), validCreateCommand())
+		require.NoError(t, err)
+
+		renderingService.EXPECT().Render(gomock.Any(), gomock.Any(), gomock.Any(), nil).Return(nil, rendering.ErrRenderUnavailable)
+
+		s.now = func() time.Time { return r.NextRun.Add(time.Second) }
+		s.sendDueReports(context.Background())
+		require.Empty(t, notificationService.EmailSync.To)
+
+		deliveries, err := s.ListDeliveries(context.Background(), &report.ListDeliveriesQuery{UID: r.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, report.DeliveryStatusError, deliveries[0].Status)
+		require.Contains(t, deliveries[0].Error, rendering.ErrRenderUnavailable.Error())
+
+		updated, err := s.Get(context.Background(), &report.GetReportQuery{UID: r.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.True(t, updated.NextRun.After(*r.NextRun))
+	})
+}
+
+func TestSendReport(t *testing.T) {
+	t.Run("renders csv reports of a panel", func(t *testing.T) {
+		s, renderingService, notificationService := setupTestService(t)
+
+		cmd := validCreateCommand()
+		cmd.Format = report.FormatCSV
+		cmd.PanelID = 4
+		cmd.Variables = nil
+		cmd.WebhookURL = ""
+		r, err := s.Create(context.Background(), cmd)
+		require.NoError(t, err)
+
+		renderingService.EXPECT().RenderCSV(gomock.Any(), gomock.Any(), nil).
+			DoAndReturn(func(_ context.Context, opts rendering.CSVOpts, _ rendering.Session) (*rendering.RenderCSVResult, error) {
+				require.Equal(t, "d-csv/dash/?from=now-1d%2Fd&orgId=1&panelId=4&to=now-1d%2Fd", opts.Path)
+				return &rendering.RenderCSVResult{FilePath: writeRenderedFile(t, "time,value")}, nil
+			})
+
+		delivery, err := s.Send(context.Background(), &report.SendReportCommand{UID: r.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, report.DeliveryStatusSuccess, delivery.Status)
+		require.Equal(t, report.DeliveryTriggerManual, delivery.Trigger)
+		require.Equal(t, "daily-sales.csv", notificationService.EmailSync.AttachedFiles[0].Name)
+	})
+
+	t.Run("records delivery errors", func(t *testing.T) {
+		s, renderingService, notificationService := setupTestService(t)
+		hook := serveTestWebhook(t, s)
+
+		cmd := validCreateCommand()
+		cmd.WebhookURL = hook.URL
+		r, err := s.Create(context.Background(), cmd)
+		require.NoError(t, err)
+
+		renderingService.EXPECT().Render(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+			Return(&rendering.RenderResult{FilePath: writeRenderedFile(t, "%PDF")}, nil)
+		notificationService.EmailHandlerSync = func(context.Context, *notifications.SendEmailCommandSync) error {
+			return errors.New("smtp not configured")
+		}
+
+		delivery, err := s.Send(context.Background(), &report.SendReportCommand{UID: r.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, report.DeliveryStatusError, delivery.Status)
+		require.Contains(t, delivery.Error, "smtp not configured")
+		// the webhook is still called
+		require.NotEmpty(t, hook.next(t))
+	})
+
+	t.Run("returns not found for unknown reports", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+
+		_, err := s.Send(context.Background(), &report.SendReportCommand{UID: "unknown", OrgID: 1})
+		require.ErrorIs(t, err, report.ErrReportNotFound)
+	})
+}
+
+func TestWebhookClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/hook", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	post := func(client *http.Client, url string) (*http.Response, error) {
+		resp, err := client.Post(url, "application/json", nil)
+		if err == nil {
+			require.NoError(t, resp.Body.Close())
+		}
+		return resp, err
+	}
+
+	t.Run("refuses internal addresses", func(t *testing.T) {
+		client := newWebhookClient(nil)
+		_, err := post(client, server.URL+"/hook")
+		require.ErrorIs(t, err, errWebhookInternalAddress)
+
+		// host names are checked after name resolution
+		_, err = post(client, "http://localhost:"+port+"/hook")
+		require.ErrorIs(t, err, errWebhookInternalAddress)
+	})
+
+	t.Run("posts to allowed hosts without following redirects", func(t *testing.T) {
+		client := newWebhookClient([]string{"127.0.0.1"})
+		resp, err := post(client, server.URL+"/hook")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = post(client, server.URL+"/redirect")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+
+		_, err = post(client, "http://localhost:"+port+"/hook")
+		require.ErrorIs(t, err, errWebhookInternalAddress)
+	})
+}
+
+func TestIsInternalHost(t *testing.T) {
+	for host, internal := range map[string]bool{
+		"localhost":       true,
+		"api.localhost":   true,
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.1":     true,
+		"100.64.0.1":      true,
+		"169.254.169.254": true,
+		"::1":             true,
+		"fd00::1":         true,
+		"0.0.0.0":         true,
+		"8.8.8.8":         false,
+		"example.com":     false,
+	} {
+		require.Equal(t, internal, isInternalHost(host), host)
+	}
+}
@@ -0,0 +1,41 @@
+package reportimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/report"
+)
+
+var (
+	reportsReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:reports:reader",
+		DisplayName: "Report reader",
+		Description: "List and view reports and their delivery history",
+		Group:       "Reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: report.ActionRead, Scope: report.ScopeAll},
+		},
+	}
+
+	reportsWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:reports:writer",
+		DisplayName: "Report writer",
+		Description: "Create, update, delete, send and view reports",
+		Group:       "Reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: report.ActionCreate},
+			{Action: report.ActionRead, Scope: report.ScopeAll},
+			{Action: report.ActionWrite, Scope: report.ScopeAll},
+			{Action: report.ActionDelete, Scope: report.ScopeAll},
+			{Action: report.ActionSend, Scope: report.ScopeAll},
+		},
+	}
+)
+
+// declareFixedRoles grants the reports roles to editors, reports still require read access to their dashboard.
+func declareFixedRoles(ac accesscontrol.Service) error {
+	return ac.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{Role: reportsReaderRole, Grants: []string{string(org.RoleEditor)}},
+		accesscontrol.RoleRegistration{Role: reportsWriterRole, Grants: []string{string(org.RoleEditor)}},
+	)
+}
@@ -0,0 +1,23 @@
+package reportimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/report"
+)
+
+type store interface {
+	Insert(context.Context, *report.Report) error
+	Update(context.Context, *report.Report) error
+	Get(context.Context, *report.GetReportQuery) (*report.Report, error)
+	List(context.Context, *report.ListReportsQuery) ([]*report.Report, error)
+	Delete(context.Context, *report.DeleteReportCommand) error
+
+	// GetDue returns the enabled reports scheduled to run at or before now.
+	GetDue(ctx context.Context, now time.Time) ([]*report.Report, error)
+	SetNextRun(ctx context.Context, id int64, nextRun *time.Time) error
+
+	InsertDelivery(context.Context, *report.Delivery) error
+	ListDeliveries(ctx context.Context, reportID int64, limit int) ([]*report.Delivery, error)
+}
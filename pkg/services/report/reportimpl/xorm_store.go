@@ -0,0 +1,128 @@
+package reportimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/report"
+)
+
+// maxDeliveriesPerReport is the number of delivery history entries kept per report.
+const maxDeliveriesPerReport = 100
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Insert(ctx context.Context, r *report.Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(r)
+		return err
+	})
+}
+
+func (s *sqlStore) Update(ctx context.Context, r *report.Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(r.ID).AllCols().Update(r)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return report.ErrReportNotFound.Errorf("report %s not found", r.UID)
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, query *report.GetReportQuery) (*report.Report, error) {
+	r := &report.Report{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(r)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return report.ErrReportNotFound.Errorf("report %s not found", query.UID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *sqlStore) List(ctx context.Context, query *report.ListReportsQuery) ([]*report.Report, error) {
+	reports := make([]*report.Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", query.OrgID).Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *sqlStore) Delete(ctx context.Context, cmd *report.DeleteReportCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		r := &report.Report{}
+		has, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Get(r)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return report.ErrReportNotFound.Errorf("report %s not found", cmd.UID)
+		}
+
+		if _, err := sess.Exec("DELETE FROM report_delivery WHERE report_id = ?", r.ID); err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM report WHERE id = ?", r.ID)
+		return err
+	})
+}
+
+func (s *sqlStore) GetDue(ctx context.Context, now time.Time) ([]*report.Report, error) {
+	reports := make([]*report.Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ? AND next_run <= ?", true, now).Asc("next_run").Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *sqlStore) SetNextRun(ctx context.Context, id int64, nextRun *time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE report SET next_run = ? WHERE id = ?", nextRun, id)
+		return err
+	})
+}
+
+func (s *sqlStore) InsertDelivery(ctx context.Context, delivery *report.Delivery) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(delivery); err != nil {
+			return err
+		}
+
+		// keep the most recent deliveries only
+		var ids []int64
+		err := sess.Table("report_delivery").Where("report_id = ?", delivery.ReportID).
+			Desc("id").Limit(1, maxDeliveriesPerReport).Cols("id").Find(&ids)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM report_delivery WHERE report_id = ? AND id <= ?", delivery.ReportID, ids[0])
+		return err
+	})
+}
+
+func (s *sqlStore) ListDeliveries(ctx context.Context, reportID int64, limit int) ([]*report.Delivery, error) {
+	deliveries := make([]*report.Delivery, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("report_id = ?", reportID).Desc("id")
+		if limit > 0 {
+			sess.Limit(limit)
+		}
+		return sess.Find(&deliveries)
+	})
+	return deliveries, err
+}
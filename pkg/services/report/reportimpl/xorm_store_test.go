@@ -0,0 +1,141 @@
+package reportimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/report"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationXormReportDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	reportStore := &sqlStore{db: db.InitTestDB(t)}
+
+	now := time.Now().Truncate(time.Second)
+	due := now.Add(-time.Minute)
+	later := now.Add(time.Hour)
+
+	newReport := func(uid string, enabled bool, nextRun *time.Time) *report.Report {
+		r := &report.Report{
+			UID:          uid,
+			OrgID:        1,
+			UserID:       10,
+			Name:         "report " + uid,
+			DashboardUID: "dash",
+			Variables:    map[string][]string{"server": {"a", "b"}},
+			TimeFrom:     "now-1d/d",
+			TimeTo:       "now-1d/d",
+			Format:       report.FormatPDF,
+			Schedule:     "0 8 * * *",
+			Recipients:   []string{"ops@example.com"},
+			Enabled:      enabled,
+			NextRun:      nextRun,
+			Created:      now,
+			Updated:      now,
+		}
+		require.NoError(t, reportStore.Insert(ctx, r))
+		return r
+	}
+
+	dueReport := newReport("due", true, &due)
+	newReport("later", true, &later)
+	newReport("disabled", false, nil)
+
+	t.Run("Can get a report", func(t *testing.T) {
+		r, err := reportStore.Get(ctx, &report.GetReportQuery{UID: "due", OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, dueReport.ID, r.ID)
+		require.Equal(t, map[string][]string{"server": {"a", "b"}}, r.Variables)
+		require.Equal(t, []string{"ops@example.com"}, r.Recipients)
+
+		_, err = reportStore.Get(ctx, &report.GetReportQuery{UID: "due", OrgID: 2})
+		require.ErrorIs(t, err, report.ErrReportNotFound)
+	})
+
+	t.Run("Can list the reports of an organization", func(t *testing.T) {
+		reports, err := reportStore.List(ctx, &report.ListReportsQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, reports, 3)
+
+		reports, err = reportStore.List(ctx, &report.ListReportsQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Empty(t, reports)
+	})
+
+	t.Run("Can get the due reports", func(t *testing.T) {
+		reports, err := reportStore.GetDue(ctx, now)
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		require.Equal(t, "due", reports[0].UID)
+
+		require.NoError(t, reportStore.SetNextRun(ctx, dueReport.ID, &later))
+		reports, err = reportStore.GetDue(ctx, now)
+		require.NoError(t, err)
+		require.Empty(t, reports)
+	})
+
+	t.Run("Can update a report", func(t *testing.T) {
+		r, err := reportStore.Get(ctx, &report.GetReportQuery{UID: "later", OrgID: 1})
+		require.NoError(t, err)
+
+		r.Name = "renamed"
+		r.Enabled = false
+		r.NextRun = nil
+		require.NoError(t, reportStore.Update(ctx, r))
+
+		r, err = reportStore.Get(ctx, &report.GetReportQuery{UID: "later", OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, "renamed", r.Name)
+		require.False(t, r.Enabled)
+		require.Nil(t, r.NextRun)
+	})
+
+	t.Run("Keeps the most recent deliveries only", func(t *testing.T) {
+		for i := 0; i < maxDeliveriesPerReport+5; i++ {
+			err := reportStore.InsertDelivery(ctx, &report.Delivery{
+				ReportID:   dueReport.ID,
+				OrgID:      1,
+				Trigger:    report.DeliveryTriggerSchedule,
+				Status:     report.DeliveryStatusSuccess,
+				StartedAt:  now,
+				FinishedAt: now,
+			})
+			require.NoError(t, err)
+		}
+
+		deliveries, err := reportStore.ListDeliveries(ctx, dueReport.ID, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, maxDeliveriesPerReport)
+		require.Greater(t, deliveries[0].ID, deliveries[1].ID)
+
+		deliveries, err = reportStore.ListDeliveries(ctx, dueReport.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 10)
+	})
+
+	t.Run("Deleting a report deletes its deliveries", func(t *testing.T) {
+		require.NoError(t, reportStore.Delete(ctx, &report.DeleteReportCommand{UID: "due", OrgID: 1}))
+
+		_, err := reportStore.Get(ctx, &report.GetReportQuery{UID: "due", OrgID: 1})
+		require.ErrorIs(t, err, report.ErrReportNotFound)
+
+		deliveries, err := reportStore.ListDeliveries(ctx, dueReport.ID, 0)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		err = reportStore.Delete(ctx, &report.DeleteReportCommand{UID: "due", OrgID: 1})
+		require.ErrorIs(t, err, report.ErrReportNotFound)
+	})
+}
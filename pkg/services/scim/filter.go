@@ -0,0 +1,484 @@
+package scim
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter is a parsed SCIM filter, https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.2.
+type Filter interface {
+	// Matches reports whether the JSON representation of a resource matches the filter.
+	Matches(resource map[string]any) bool
+}
+
+var attributeNamePattern = regexp.MustCompile(`^[A-Za-z$][\w$-]*$`)
+
+const (
+	opEqual          = "eq"
+	opNotEqual       = "ne"
+	opContains       = "co"
+	opStartsWith     = "sw"
+	opEndsWith       = "ew"
+	opGreater        = "gt"
+	opGreaterOrEqual = "ge"
+	opLess           = "lt"
+	opLessOrEqual    = "le"
+	opPresent        = "pr"
+)
+
+type logicalFilter struct {
+	and         bool
+	left, right Filter
+}
+
+func (f *logicalFilter) Matches(resource map[string]any) bool {
+	if f.and {
+		return f.left.Matches(resource) && f.right.Matches(resource)
+	}
+	return f.left.Matches(resource) || f.right.Matches(resource)
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f *notFilter) Matches(resource map[string]any) bool {
+	return !f.filter.Matches(resource)
+}
+
+type attributeFilter struct {
+	path  []string
+	op    string
+	value any
+}
+
+func (f *attributeFilter) Matches(resource map[string]any) bool {
+	values := attributeValues(resource, f.path)
+	switch {
+	case f.op == opPresent:
+		for _, v := range values {
+			if !isEmpty(v) {
+				return true
+			}
+		}
+		return false
+	case f.value == nil:
+		// only eq and ne are allowed with null, which matches unassigned attributes
+		return (len(values) == 0) == (f.op == opEqual)
+	case f.op == opNotEqual:
+		return !matchesAny(values, opEqual, f.value)
+	default:
+		return matchesAny(values, f.op, f.value)
+	}
+}
+
+// valuePathFilter matches the values of a multi-valued attribute, e.g. emails[type eq "work"].
+type valuePathFilter struct {
+	path   []string
+	filter Filter
+}
+
+func (f *valuePathFilter) Matches(resource map[string]any) bool {
+	for _, v := range attributeValues(resource, f.path) {
+		if element, ok := v.(map[string]any); ok && f.filter.Matches(element) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(values []any, op string, expected any) bool {
+	for _, v := range values {
+		// multi-valued complex attributes are compared with their value sub-attribute
+		if element, ok := v.(map[string]any); ok {
+			v = lookup(element, "value")
+		}
+		if compare(v, op, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(actual any, op string, expected any) bool {
+	switch e := expected.(type) {
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		if op != opEqual && op != opContains && op != opStartsWith && op != opEndsWith {
+			return compareOrder(compareStrings(a, e), op)
+		}
+		a, e = strings.ToLower(a), strings.ToLower(e)
+		switch op {
+		case opEqual:
+			return a == e
+		case opContains:
+			return strings.Contains(a, e)
+		case opStartsWith:
+			return strings.HasPrefix(a, e)
+		default:
+			return strings.HasSuffix(a, e)
+		}
+	case bool:
+		a, ok := actual.(bool)
+		return ok && op == opEqual && a == e
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case a < e:
+			return compareOrder(-1, op)
+		case a > e:
+			return compareOrder(1, op)
+		default:
+			return compareOrder(0, op)
+		}
+	}
+	return false
+}
+
+// compareStrings compares date times chronologically and other strings lexicographically, ignoring case.
+func compareStrings(a, b string) int {
+	if ta, err := time.Parse(time.RFC3339, a); err == nil {
+		if tb, err := time.Parse(time.RFC3339, b); err == nil {
+			return ta.Compare(tb)
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func compareOrder(cmp int, op string) bool {
+	switch op {
+	case opEqual:
+		return cmp == 0
+	case opGreater:
+		return cmp > 0
+	case opGreaterOrEqual:
+		return cmp >= 0
+	case opLess:
+		return cmp < 0
+	case opLessOrEqual:
+		return cmp <= 0
+	}
+	return false
+}
+
+func isEmpty(v any) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []any:
+		return len(value) == 0
+	case map[string]any:
+		return len(value) == 0
+	}
+	return false
+}
+
+// attributeValues returns the values of the attribute path in the resource, the values of multi-valued attributes
+// are flattened.
+func attributeValues(resource map[string]any, path []string) []any {
+	current := []any{resource}
+	for _, name := range path {
+		next := []any{}
+		for _, v := range current {
+			element, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch value := lookup(element, name).(type) {
+			case nil:
+			case []any:
+				next = append(next, value...)
+			default:
+				next = append(next, value)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// lookup returns the value of an attribute, attribute names are case-insensitive.
+func lookup(resource map[string]any, name string) any {
+	if key, ok := findKey(resource, name); ok {
+		return resource[key]
+	}
+	return nil
+}
+
+func findKey(resource map[string]any, name string) (string, bool) {
+	if _, ok := resource[name]; ok {
+		return name, true
+	}
+	for key := range resource {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// parseAttributePath parses an attribute path like name.givenName, optionally prefixed with the URN of its schema.
+func parseAttributePath(s string) ([]string, error) {
+	if strings.HasPrefix(strings.ToLower(s), "urn:") {
+		s = s[strings.LastIndex(s, ":")+1:]
+	}
+	path := strings.Split(s, ".")
+	if len(path) > 2 {
+		return nil, Errorf(ErrInvalidFilter, "attribute path %q has too many sub-attributes", s)
+	}
+	for _, name := range path {
+		if !attributeNamePattern.MatchString(name) {
+			return nil, Errorf(ErrInvalidFilter, "invalid attribute path %q", s)
+		}
+	}
+	return path, nil
+}
+
+// ParseFilter parses the filter query parameter of list requests.
+func ParseFilter(s string) (Filter, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, Errorf(ErrInvalidFilter, "unexpected %q in filter", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+type token struct {
+	text string
+	// quoted is set for string literals, text is then unquoted
+	quoted bool
+}
+
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, Errorf(ErrInvalidFilter, "unterminated string in filter")
+			}
+			var text string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &text); err != nil {
+				return nil, Errorf(ErrInvalidFilter, "invalid string %s in filter", s[i:end+1])
+			}
+			tokens = append(tokens, token{text: text, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(s) && !strings.ContainsRune(" \t\n()[]\"", rune(s[end])); end++ {
+			}
+			tokens = append(tokens, token{text: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// peekKeyword reports whether the next token is the unquoted keyword, keywords are case-insensitive.
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) next() (token, error) {
+	t, ok := p.peek()
+	if !ok {
+		return token{}, Errorf(ErrInvalidFilter, "unexpected end of filter")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.quoted || t.text != text {
+		return Errorf(ErrInvalidFilter, "expected %q but got %q in filter", text, t.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return &notFilter{filter: f}, nil
+	}
+	if p.peekKeyword("(") {
+		p.pos++
+		return p.parseGroup()
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.quoted {
+		return nil, Errorf(ErrInvalidFilter, "expected an attribute but got %q in filter", t.text)
+	}
+	path, err := parseAttributePath(t.text)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peekKeyword("[") {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return &valuePathFilter{path: path, filter: f}, nil
+	}
+
+	opToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(opToken.text)
+	if opToken.quoted {
+		return nil, Errorf(ErrInvalidFilter, "expected an operator but got %q in filter", opToken.text)
+	}
+	if op == opPresent {
+		return &attributeFilter{path: path, op: op}, nil
+	}
+
+	valueToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseValue(valueToken)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case opEqual, opNotEqual:
+	case opContains, opStartsWith, opEndsWith:
+		if _, ok := value.(string); !ok {
+			return nil, Errorf(ErrInvalidFilter, "operator %s requires a string", op)
+		}
+	case opGreater, opGreaterOrEqual, opLess, opLessOrEqual:
+		switch value.(type) {
+		case string, float64:
+		default:
+			return nil, Errorf(ErrInvalidFilter, "operator %s requires a string or a number", op)
+		}
+	default:
+		return nil, Errorf(ErrInvalidFilter, "unsupported operator %q", opToken.text)
+	}
+	return &attributeFilter{path: path, op: op, value: value}, nil
+}
+
+func (p *filterParser) parseGroup() (Filter, error) {
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func parseValue(t token) (any, error) {
+	if t.quoted {
+		return t.text, nil
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, Errorf(ErrInvalidFilter, "invalid value %q in filter", t.text)
+	}
+	return n, nil
+}
@@ -0,0 +1,85 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	user := map[string]any{
+		"userName":    "Alice",
+		"displayName": "Alice Doe",
+		"active":      true,
+		"name":        map[string]any{"givenName": "Alice", "familyName": "Doe"},
+		"emails": []any{
+			map[string]any{"value": "alice@example.com", "type": "work", "primary": true},
+			map[string]any{"value": "alice@home.example.org", "type": "home"},
+		},
+		"meta": map[string]any{"lastModified": "2024-05-01T10:00:00Z"},
+	}
+
+	tests := []struct {
+		filter   string
+		expected bool
+	}{
+		{filter: `userName eq "alice"`, expected: true},
+		{filter: `USERNAME Eq "ALICE"`, expected: true},
+		{filter: `userName eq "bob"`, expected: false},
+		{filter: `userName ne "bob"`, expected: true},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, expected: true},
+		{filter: `name.familyName sw "d"`, expected: true},
+		{filter: `displayName co "doe"`, expected: true},
+		{filter: `displayName ew "doe"`, expected: true},
+		{filter: `emails co "example.org"`, expected: true},
+		{filter: `emails.value eq "alice@example.com"`, expected: true},
+		{filter: `emails[type eq "work" and value co "example.com"]`, expected: true},
+		{filter: `emails[type eq "work" and value co "example.org"]`, expected: false},
+		{filter: `active eq true`, expected: true},
+		{filter: `active eq false`, expected: false},
+		{filter: `title pr`, expected: false},
+		{filter: `name pr`, expected: true},
+		{filter: `title eq null`, expected: true},
+		{filter: `meta.lastModified gt "2024-04-30T10:00:00+02:00"`, expected: true},
+		{filter: `meta.lastModified lt "2024-05-01T11:00:00+02:00"`, expected: false},
+		{filter: `userName eq "bob" or (active eq true and not (displayName eq "bob"))`, expected: true},
+		{filter: `userName eq "bob" or active eq true and userName eq "bob"`, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, f.Matches(user))
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		filter  string
+		message string
+	}{
+		{filter: ``},
+		{filter: `userName`},
+		{filter: `userName eq`},
+		{filter: `userName xx "alice"`, message: `unsupported operator "xx"`},
+		{filter: `userName eq "alice`},
+		{filter: `userName eq alice`, message: `invalid value "alice" in filter`},
+		{filter: `userName "eq" "alice"`, message: `expected an operator but got "eq" in filter`},
+		{filter: `active gt true`, message: "operator gt requires a string or a number"},
+		{filter: `userName co 1`, message: "operator co requires a string"},
+		{filter: `(userName eq "alice"`},
+		{filter: `emails[type eq "work"`},
+		{filter: `userName eq "alice" "bob"`},
+		{filter: `a.b.c eq "alice"`},
+	} {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := ParseFilter(tt.filter)
+			require.ErrorIs(t, err, ErrInvalidFilter)
+			if tt.message != "" {
+				require.ErrorContains(t, err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package scim
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	patchOpAdd     = "add"
+	patchOpReplace = "replace"
+	patchOpRemove  = "remove"
+)
+
+// readOnlyAttributes can't be changed by PATCH operations.
+var readOnlyAttributes = map[string]bool{"id": true, "meta": true, "schemas": true}
+
+// patchPath is the target of a PATCH operation: an attribute, the values of a multi-valued attribute matching a
+// filter, and a sub-attribute of either, e.g. name.givenName or emails[type eq "work"].value.
+type patchPath struct {
+	attribute    string
+	filter       Filter
+	subAttribute string
+}
+
+func parsePatchPath(s string) (*patchPath, error) {
+	// the schema prefix can't contain brackets, contrary to the filter
+	prefixEnd := len(s)
+	if i := strings.Index(s, "["); i >= 0 {
+		prefixEnd = i
+	}
+	if strings.HasPrefix(strings.ToLower(s), "urn:") {
+		s = s[strings.LastIndex(s[:prefixEnd], ":")+1:]
+	}
+
+	p := &patchPath{}
+	if start := strings.Index(s, "["); start >= 0 {
+		end := strings.LastIndex(s, "]")
+		if end < start {
+			return nil, Errorf(ErrInvalidPath, "invalid path %q", s)
+		}
+		filter, err := ParseFilter(s[start+1 : end])
+		if err != nil {
+			return nil, Errorf(ErrInvalidPath, "invalid filter in path %q: %s", s, err)
+		}
+		p.filter = filter
+		rest := s[end+1:]
+		if rest != "" && !strings.HasPrefix(rest, ".") {
+			return nil, Errorf(ErrInvalidPath, "invalid path %q", s)
+		}
+		s = s[:start] + rest
+	}
+
+	path, err := parseAttributePath(s)
+	if err != nil {
+		return nil, Errorf(ErrInvalidPath, "invalid path %q", s)
+	}
+	p.attribute = path[0]
+	if len(path) == 2 {
+		p.subAttribute = path[1]
+	}
+	if readOnlyAttributes[strings.ToLower(p.attribute)] {
+		return nil, Errorf(ErrMutability, "attribute %s is read-only", p.attribute)
+	}
+	return p, nil
+}
+
+// ApplyPatch applies the operations of a PATCH request to the JSON representation of a resource,
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2.
+func ApplyPatch(resource map[string]any, operations []PatchOperation) error {
+	for _, operation := range operations {
+		if err := applyOperation(resource, operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(resource map[string]any, operation PatchOperation) error {
+	// some identity providers capitalize the operations
+	op := strings.ToLower(operation.Op)
+	switch op {
+	case patchOpAdd, patchOpReplace:
+		if operation.Path != "" {
+			path, err := parsePatchPath(operation.Path)
+			if err != nil {
+				return err
+			}
+			return set(resource, op, path, operation.Value)
+		}
+		// without path, the value contains the attributes to add or replace
+		values, ok := operation.Value.(map[string]any)
+		if !ok {
+			return Errorf(ErrInvalidValue, "%s operation without path requires an object value", op)
+		}
+		for name, value := range values {
+			if strings.EqualFold(name, "schemas") {
+				continue
+			}
+			path, err := parsePatchPath(name)
+			if err != nil {
+				return err
+			}
+			if err := set(resource, op, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case patchOpRemove:
+		if operation.Path == "" {
+			return Errorf(ErrNoTarget, "remove operation requires a path")
+		}
+		path, err := parsePatchPath(operation.Path)
+		if err != nil {
+			return err
+		}
+		return remove(resource, path, operation.Value)
+	default:
+		return Errorf(ErrInvalidSyntax, "unsupported operation %q", operation.Op)
+	}
+}
+
+func set(resource map[string]any, op string, path *patchPath, value any) error {
+	key, exists := findKey(resource, path.attribute)
+	if !exists {
+		key = path.attribute
+	}
+
+	if path.filter != nil {
+		elements, _ := resource[key].([]any)
+		matched := false
+		for i, v := range elements {
+			element, ok := v.(map[string]any)
+			if !ok || !path.filter.Matches(element) {
+				continue
+			}
+			matched = true
+			switch {
+			case path.subAttribute != "":
+				setAttribute(element, path.subAttribute, value)
+			case op == patchOpReplace:
+				elements[i] = value
+			default:
+				merge(element, value)
+			}
+		}
+		if matched {
+			return nil
+		}
+		// identity providers add values like emails[type eq "work"].value before the email exists
+		element := newElement(path.filter)
+		if element == nil || path.subAttribute == "" {
+			return Errorf(ErrNoTarget, "no value of %s matches the filter", path.attribute)
+		}
+		setAttribute(element, path.subAttribute, value)
+		resource[key] = append(elements, element)
+		return nil
+	}
+
+	if path.subAttribute != "" {
+		switch parent := resource[key].(type) {
+		case map[string]any:
+			setAttribute(parent, path.subAttribute, value)
+		case []any:
+			for _, v := range parent {
+				if element, ok := v.(map[string]any); ok {
+					setAttribute(element, path.subAttribute, value)
+				}
+			}
+		default:
+			resource[key] = map[string]any{path.subAttribute: value}
+		}
+		return nil
+	}
+
+	switch current := resource[key].(type) {
+	case []any:
+		values, ok := value.([]any)
+		if !ok {
+			values = []any{value}
+		}
+		if op == patchOpReplace {
+			resource[key] = values
+			return nil
+		}
+		for _, v := range values {
+			if !containsValue(current, v) {
+				current = append(current, v)
+			}
+		}
+		resource[key] = current
+	case map[string]any:
+		if !merge(current, value) {
+			resource[key] = value
+		}
+	default:
+		resource[key] = value
+	}
+	return nil
+}
+
+func remove(resource map[string]any, path *patchPath, value any) error {
+	key, exists := findKey(resource, path.attribute)
+	if !exists {
+		return nil
+	}
+
+	elements, isMultiValued := resource[key].([]any)
+	if !isMultiValued {
+		if path.subAttribute == "" {
+			delete(resource, key)
+		} else if parent, ok := resource[key].(map[string]any); ok {
+			removeAttribute(parent, path.subAttribute)
+		}
+		return nil
+	}
+
+	// values to remove can be given as a filter or, as some identity providers do for members, as a value
+	toRemove, _ := value.([]any)
+	remaining := make([]any, 0, len(elements))
+	for _, v := range elements {
+		element, _ := v.(map[string]any)
+		matches := false
+		switch {
+		case path.filter != nil:
+			matches = element != nil && path.filter.Matches(element)
+		case len(toRemove) > 0:
+			matches = containsValue(toRemove, v)
+		default:
+			matches = true
+		}
+
+		switch {
+		case !matches:
+			remaining = append(remaining, v)
+		case path.subAttribute != "" && element != nil:
+			removeAttribute(element, path.subAttribute)
+			remaining = append(remaining, element)
+		}
+	}
+	resource[key] = remaining
+	return nil
+}
+
+func setAttribute(resource map[string]any, name string, value any) {
+	if key, ok := findKey(resource, name); ok {
+		resource[key] = value
+		return
+	}
+	resource[name] = value
+}
+
+func removeAttribute(resource map[string]any, name string) {
+	if key, ok := findKey(resource, name); ok {
+		delete(resource, key)
+	}
+}
+
+// merge sets the attributes of value, if it is an object, in the resource.
+func merge(resource map[string]any, value any) bool {
+	values, ok := value.(map[string]any)
+	if !ok {
+		return false
+	}
+	for name, v := range values {
+		setAttribute(resource, name, v)
+	}
+	return true
+}
+
+// containsValue reports whether a value of a multi-valued attribute is in the values. Complex values are
+// identified by their value sub-attribute.
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+		a, aOK := v.(map[string]any)
+		b, bOK := value.(map[string]any)
+		if aOK && bOK && lookup(a, "value") != nil && reflect.DeepEqual(lookup(a, "value"), lookup(b, "value")) {
+			return true
+		}
+	}
+	return false
+}
+
+// newElement returns the value of a multi-valued attribute that matches an equality filter like type eq "work".
+func newElement(filter Filter) map[string]any {
+	f, ok := filter.(*attributeFilter)
+	if !ok || f.op != opEqual || len(f.path) != 1 || f.value == nil {
+		return nil
+	}
+	return map[string]any{f.path[0]: f.value}
+}
@@ -0,0 +1,136 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		desc        string
+		resource    string
+		operations  string
+		expected    string
+		expectedErr error
+	}{
+		{
+			desc:       "should replace an attribute",
+			resource:   `{"userName": "alice", "active": true}`,
+			operations: `[{"op": "Replace", "path": "active", "value": false}]`,
+			expected:   `{"userName": "alice", "active": false}`,
+		},
+		{
+			desc:       "should replace the attributes of the value without path",
+			resource:   `{"userName": "alice", "name": {"givenName": "Alice", "familyName": "Doe"}}`,
+			operations: `[{"op": "replace", "value": {"userName": "alice2", "name.givenName": "Alicia", "schemas": []}}]`,
+			expected:   `{"userName": "alice2", "name": {"givenName": "Alicia", "familyName": "Doe"}}`,
+		},
+		{
+			desc:       "should replace a sub-attribute ignoring the case and the schema",
+			resource:   `{"name": {"givenName": "Alice"}}`,
+			operations: `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:name.GIVENNAME", "value": "Alicia"}]`,
+			expected:   `{"name": {"givenName": "Alicia"}}`,
+		},
+		{
+			desc:       "should replace the sub-attribute of filtered values",
+			resource:   `{"emails": [{"type": "work", "value": "a@example.com"}, {"type": "home", "value": "a@example.org"}]}`,
+			operations: `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "alice@example.com"}]`,
+			expected:   `{"emails": [{"type": "work", "value": "alice@example.com"}, {"type": "home", "value": "a@example.org"}]}`,
+		},
+		{
+			desc:       "should add a value matching the filter when none exists",
+			resource:   `{"userName": "alice"}`,
+			operations: `[{"op": "add", "path": "emails[type eq \"work\"].value", "value": "alice@example.com"}]`,
+			expected:   `{"userName": "alice", "emails": [{"type": "work", "value": "alice@example.com"}]}`,
+		},
+		{
+			desc:       "should add members without duplicates",
+			resource:   `{"members": [{"value": "a", "display": "alice"}]}`,
+			operations: `[{"op": "add", "path": "members", "value": [{"value": "a"}, {"value": "b"}]}]`,
+			expected:   `{"members": [{"value": "a", "display": "alice"}, {"value": "b"}]}`,
+		},
+		{
+			desc:       "should replace all members",
+			resource:   `{"members": [{"value": "a"}]}`,
+			operations: `[{"op": "replace", "path": "members", "value": [{"value": "b"}]}]`,
+			expected:   `{"members": [{"value": "b"}]}`,
+		},
+		{
+			desc:       "should remove filtered members",
+			resource:   `{"members": [{"value": "a"}, {"value": "b"}]}`,
+			operations: `[{"op": "remove", "path": "members[value eq \"a\"]"}]`,
+			expected:   `{"members": [{"value": "b"}]}`,
+		},
+		{
+			desc:       "should remove members given as value",
+			resource:   `{"members": [{"value": "a"}, {"value": "b"}]}`,
+			operations: `[{"op": "remove", "path": "members", "value": [{"value": "b"}]}]`,
+			expected:   `{"members": [{"value": "a"}]}`,
+		},
+		{
+			desc:       "should remove all members",
+			resource:   `{"displayName": "team", "members": [{"value": "a"}, {"value": "b"}]}`,
+			operations: `[{"op": "remove", "path": "members"}]`,
+			expected:   `{"displayName": "team", "members": []}`,
+		},
+		{
+			desc:       "should remove an attribute",
+			resource:   `{"userName": "alice", "externalId": "1"}`,
+			operations: `[{"op": "remove", "path": "externalId"}]`,
+			expected:   `{"userName": "alice"}`,
+		},
+		{
+			desc:        "should fail to replace a value matching no filter",
+			resource:    `{"members": [{"value": "a"}]}`,
+			operations:  `[{"op": "replace", "path": "members[value eq \"b\"]", "value": {"value": "c"}}]`,
+			expectedErr: ErrNoTarget,
+		},
+		{
+			desc:        "should fail to remove without path",
+			resource:    `{"userName": "alice"}`,
+			operations:  `[{"op": "remove"}]`,
+			expectedErr: ErrNoTarget,
+		},
+		{
+			desc:        "should fail to change read-only attributes",
+			resource:    `{"id": "1"}`,
+			operations:  `[{"op": "replace", "path": "id", "value": "2"}]`,
+			expectedErr: ErrMutability,
+		},
+		{
+			desc:        "should fail for an invalid path",
+			resource:    `{"userName": "alice"}`,
+			operations:  `[{"op": "replace", "path": "emails[type eq]", "value": "a"}]`,
+			expectedErr: ErrInvalidPath,
+		},
+		{
+			desc:        "should fail for an unsupported operation",
+			resource:    `{"userName": "alice"}`,
+			operations:  `[{"op": "move", "path": "userName"}]`,
+			expectedErr: ErrInvalidSyntax,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resource := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(tt.resource), &resource))
+			var operations []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tt.operations), &operations))
+
+			err := ApplyPatch(resource, operations)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			expected := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(tt.expected), &expected))
+			assert.Equal(t, expected, resource)
+		})
+	}
+}
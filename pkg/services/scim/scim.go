@@ -0,0 +1,135 @@
+package scim
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+// Schemas of the SCIM resources and messages, https://datatracker.ietf.org/doc/html/rfc7643.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+const (
+	ResourceTypeUser  = "User"
+	ResourceTypeGroup = "Group"
+)
+
+// The message ids of the errors are prefixed SCIM error types, returned as the scimType of the error responses.
+var (
+	ErrInvalidFilter = errutil.BadRequest("scim.invalidFilter", errutil.WithPublicMessage("The filter is invalid"))
+	ErrInvalidPath   = errutil.BadRequest("scim.invalidPath", errutil.WithPublicMessage("The path is invalid"))
+	ErrInvalidSyntax = errutil.BadRequest("scim.invalidSyntax", errutil.WithPublicMessage("The request is invalid"))
+	ErrInvalidValue  = errutil.BadRequest("scim.invalidValue", errutil.WithPublicMessage("A value is invalid"))
+	ErrNoTarget      = errutil.BadRequest("scim.noTarget", errutil.WithPublicMessage("The path matches no attribute"))
+	ErrMutability    = errutil.BadRequest("scim.mutability", errutil.WithPublicMessage("The attribute can't be modified"))
+	ErrUniqueness    = errutil.Conflict("scim.uniqueness", errutil.WithPublicMessage("The resource already exists"))
+	ErrNotFound      = errutil.NotFound("scim.not-found", errutil.WithPublicMessage("Resource not found"))
+	ErrForbidden     = errutil.Forbidden("scim.forbidden", errutil.WithPublicMessage("The operation is not allowed"))
+)
+
+// Errorf returns an error of the base with a public message detailing it. The arguments must not contain
+// sensitive data.
+func Errorf(base errutil.Base, format string, args ...any) errutil.Error {
+	err := base.Errorf(format, args...)
+	err.PublicMessage = err.LogMessage
+	return err
+}
+
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// MultiValue is a value of a multi-valued attribute such as emails.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Reference is a reference to another resource, such as the members of a group.
+type Reference struct {
+	Value   string `json:"value"`
+	Ref     string `json:"$ref,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+type Group struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	DisplayName string      `json:"displayName"`
+	Members     []Reference `json:"members,omitempty"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Error is the body of SCIM error responses.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// Resource stores the attributes of users and groups that have no equivalent in Grafana.
+type Resource struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	ResourceType string    `xorm:"resource_type"`
+	ResourceID   int64     `xorm:"resource_id"`
+	ExternalID   string    `xorm:"external_id"`
+	Created      time.Time `xorm:"created"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (Resource) TableName() string {
+	return "scim_resource"
+}
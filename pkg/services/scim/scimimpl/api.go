@@ -0,0 +1,223 @@
+package scimimpl
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/scim"
+)
+
+const (
+	contentType = "application/scim+json; charset=utf-8"
+
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+// scimTypes are the error types of https://datatracker.ietf.org/doc/html/rfc7644#section-3.12.
+var scimTypes = map[string]bool{
+	"invalidFilter": true, "tooMany": true, "uniqueness": true, "mutability": true, "invalidSyntax": true,
+	"invalidPath": true, "noTarget": true, "invalidValue": true, "invalidVers": true, "sensitive": true,
+}
+
+var errInternal = errutil.Internal("scim.internal")
+
+func (s *Service) getServiceProviderConfig(c *contextmodel.ReqContext) response.Response {
+	return scimResponse(http.StatusOK, map[string]any{
+		"schemas":          []string{scim.SchemaServiceProviderConfig},
+		"documentationUri": "https://grafana.com/docs/grafana/latest/setup-grafana/configure-security/configure-scim-provisioning/",
+		"patch":            map[string]any{"supported": true},
+		"bulk":             map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]any{"supported": true, "maxResults": maxCount},
+		"changePassword":   map[string]any{"supported": false},
+		"sort":             map[string]any{"supported": false},
+		"etag":             map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Authentication with the token of a Grafana service account",
+			"primary":     true,
+		}},
+		"meta": scim.Meta{ResourceType: "ServiceProviderConfig", Location: s.location("ServiceProviderConfig")},
+	})
+}
+
+func (s *Service) getResourceTypes(c *contextmodel.ReqContext) response.Response {
+	resourceTypes := []any{
+		map[string]any{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       scim.ResourceTypeUser,
+			"name":     scim.ResourceTypeUser,
+			"endpoint": "/Users",
+			"schema":   scim.SchemaUser,
+			"meta":     scim.Meta{ResourceType: "ResourceType", Location: s.location("ResourceTypes", scim.ResourceTypeUser)},
+		},
+		map[string]any{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       scim.ResourceTypeGroup,
+			"name":     scim.ResourceTypeGroup,
+			"endpoint": "/Groups",
+			"schema":   scim.SchemaGroup,
+			"meta":     scim.Meta{ResourceType: "ResourceType", Location: s.location("ResourceTypes", scim.ResourceTypeGroup)},
+		},
+	}
+	return scimResponse(http.StatusOK, &scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: len(resourceTypes),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	})
+}
+
+// location returns the URL of a SCIM endpoint.
+func (s *Service) location(path ...string) string {
+	return strings.TrimSuffix(s.cfg.AppURL, "/") + "/api/scim/v2/" + strings.Join(path, "/")
+}
+
+func scimResponse(status int, body any) *response.NormalResponse {
+	return response.JSON(status, body).SetHeader("Content-Type", contentType)
+}
+
+// errorResponse returns the SCIM representation of an error, errors that are not errutil errors are logged and
+// returned as internal errors.
+func (s *Service) errorResponse(c *contextmodel.ReqContext, message string, err error) response.Response {
+	grafanaErr := errutil.Error{}
+	if !errors.As(err, &grafanaErr) {
+		s.log.FromContext(c.Req.Context()).Error(message, "error", err)
+		grafanaErr = errInternal.Errorf("%s: %w", message, err)
+		grafanaErr.PublicMessage = message
+	}
+
+	public := grafanaErr.Public()
+	body := scim.Error{
+		Schemas: []string{scim.SchemaError},
+		Status:  strconv.Itoa(public.StatusCode),
+		Detail:  public.Message,
+	}
+	if scimType := strings.TrimPrefix(public.MessageID, "scim."); scimTypes[scimType] {
+		body.ScimType = scimType
+	}
+	return scimResponse(public.StatusCode, body)
+}
+
+// bind decodes the JSON body of a request, identity providers send it as application/scim+json.
+func bind(c *contextmodel.ReqContext, v any) error {
+	mediaType, _, err := mime.ParseMediaType(c.Req.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "application/scim+json" && mediaType != "application/json") {
+		return scim.Errorf(scim.ErrInvalidSyntax, "unsupported content type %q", c.Req.Header.Get("Content-Type"))
+	}
+	if err := json.NewDecoder(c.Req.Body).Decode(v); err != nil {
+		return scim.Errorf(scim.ErrInvalidSyntax, "invalid request body: %s", err)
+	}
+	return nil
+}
+
+// listQuery holds the parameters of list requests, https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.
+type listQuery struct {
+	filter scim.Filter
+	// startIndex is 1-based
+	startIndex int
+	count      int
+	// excludedAttributes are the lowercase names of the attributes to leave out of the resources
+	excludedAttributes map[string]bool
+}
+
+func parseListQuery(c *contextmodel.ReqContext) (*listQuery, error) {
+	q := &listQuery{startIndex: 1, count: defaultCount, excludedAttributes: map[string]bool{}}
+
+	if filter := c.Query("filter"); filter != "" {
+		f, err := scim.ParseFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		q.filter = f
+	}
+
+	if startIndex := c.Query("startIndex"); startIndex != "" {
+		i, err := strconv.Atoi(startIndex)
+		if err != nil {
+			return nil, scim.Errorf(scim.ErrInvalidValue, "invalid startIndex %q", startIndex)
+		}
+		// values less than 1 are interpreted as 1
+		q.startIndex = max(i, 1)
+	}
+
+	if count := c.Query("count"); count != "" {
+		i, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, scim.Errorf(scim.ErrInvalidValue, "invalid count %q", count)
+		}
+		// negative values are interpreted as 0, which returns the number of results only
+		q.count = min(max(i, 0), maxCount)
+	}
+
+	for _, attribute := range strings.Split(c.Query("excludedAttributes"), ",") {
+		if attribute = strings.TrimSpace(attribute); attribute != "" {
+			q.excludedAttributes[strings.ToLower(attribute)] = true
+		}
+	}
+
+	return q, nil
+}
+
+// listResponse filters and paginates the resources.
+func listResponse[T any](resources []T, q *listQuery) (*scim.ListResponse, error) {
+	matching := make([]any, 0, len(resources))
+	for _, resource := range resources {
+		if q.filter != nil {
+			m, err := toMap(resource)
+			if err != nil {
+				return nil, err
+			}
+			if !q.filter.Matches(m) {
+				continue
+			}
+		}
+		matching = append(matching, resource)
+	}
+
+	page := []any{}
+	if start := q.startIndex - 1; start < len(matching) {
+		page = matching[start:min(start+q.count, len(matching))]
+	}
+	return &scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: len(matching),
+		StartIndex:   q.startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}, nil
+}
+
+// toMap returns the JSON representation of a resource, as it is filtered and patched.
+func toMap(resource any) (map[string]any, error) {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// fromMap decodes the JSON representation of a resource, after a patch.
+func fromMap(m map[string]any, resource any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, resource); err != nil {
+		return scim.Errorf(scim.ErrInvalidValue, "invalid value: %s", err)
+	}
+	return nil
+}
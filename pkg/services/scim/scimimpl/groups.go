@@ -0,0 +1,374 @@
+package scimimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/scim"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) listGroups(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	q, err := parseListQuery(c)
+	if err != nil {
+		return s.errorResponse(c, "Invalid list request", err)
+	}
+
+	result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return s.errorResponse(c, "Failed to list groups", err)
+	}
+	resources, err := s.store.List(ctx, c.SignedInUser.GetOrgID(), scim.ResourceTypeGroup)
+	if err != nil {
+		return s.errorResponse(c, "Failed to list groups", err)
+	}
+	resourcesByTeamID := make(map[int64]*scim.Resource, len(resources))
+	for _, resource := range resources {
+		resourcesByTeamID[resource.ResourceID] = resource
+	}
+
+	// the members are loaded to be filtered even if they are excluded from the response
+	excludeMembers := q.excludedAttributes["members"]
+	var orgUsers map[int64]*org.OrgUserDTO
+	if !excludeMembers || q.filter != nil {
+		if orgUsers, err = s.orgUsersByID(ctx, c.SignedInUser); err != nil {
+			return s.errorResponse(c, "Failed to list groups", err)
+		}
+	}
+
+	groups := make([]*scim.Group, 0, len(result.Teams))
+	for _, t := range result.Teams {
+		g := s.newGroup(t, resourcesByTeamID[t.ID])
+		if orgUsers != nil {
+			if g.Members, err = s.groupMembers(ctx, c.SignedInUser, t.ID, orgUsers); err != nil {
+				return s.errorResponse(c, "Failed to list groups", err)
+			}
+		}
+		groups = append(groups, g)
+	}
+
+	list, err := listResponse(groups, q)
+	if err != nil {
+		return s.errorResponse(c, "Failed to list groups", err)
+	}
+	if excludeMembers {
+		for _, g := range list.Resources {
+			g.(*scim.Group).Members = nil
+		}
+	}
+	return scimResponse(http.StatusOK, list)
+}
+
+func (s *Service) getGroup(c *contextmodel.ReqContext) response.Response {
+	t, err := s.getTeam(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"])
+	if err != nil {
+		return s.errorResponse(c, "Failed to get group", err)
+	}
+	return s.groupResponse(c, http.StatusOK, t)
+}
+
+func (s *Service) createGroup(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	g := scim.Group{}
+	if err := bind(c, &g); err != nil {
+		return s.errorResponse(c, "Invalid group", err)
+	}
+	name := strings.TrimSpace(g.DisplayName)
+	if name == "" {
+		return s.errorResponse(c, "Invalid group", scim.Errorf(scim.ErrInvalidValue, "displayName is required"))
+	}
+	memberIDs, err := s.resolveMembers(ctx, c.SignedInUser, g.Members)
+	if err != nil {
+		return s.errorResponse(c, "Invalid group", err)
+	}
+
+	t, err := s.teamService.CreateTeam(ctx, name, "", c.SignedInUser.GetOrgID())
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			err = scim.Errorf(scim.ErrUniqueness, "group %s already exists", name)
+		}
+		return s.errorResponse(c, "Failed to create group", err)
+	}
+	for userID := range memberIDs {
+		if err := s.setTeamMember(ctx, t.OrgID, t.ID, userID, team.PermissionTypeMember.String()); err != nil {
+			return s.errorResponse(c, "Failed to add group member", err)
+		}
+	}
+	if err := s.setExternalID(ctx, t.OrgID, scim.ResourceTypeGroup, t.ID, g.ExternalID); err != nil {
+		return s.errorResponse(c, "Failed to create group", err)
+	}
+
+	dto, err := s.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: t.OrgID, ID: t.ID, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return s.errorResponse(c, "Failed to get group", err)
+	}
+	return s.groupResponse(c, http.StatusCreated, dto)
+}
+
+func (s *Service) replaceGroup(c *contextmodel.ReqContext) response.Response {
+	g := scim.Group{}
+	if err := bind(c, &g); err != nil {
+		return s.errorResponse(c, "Invalid group", err)
+	}
+	return s.saveGroup(c, web.Params(c.Req)[":id"], func(*scim.Group) (*scim.Group, error) {
+		return &g, nil
+	})
+}
+
+func (s *Service) patchGroup(c *contextmodel.ReqContext) response.Response {
+	patch := scim.PatchRequest{}
+	if err := bind(c, &patch); err != nil {
+		return s.errorResponse(c, "Invalid patch", err)
+	}
+	return s.saveGroup(c, web.Params(c.Req)[":id"], func(current *scim.Group) (*scim.Group, error) {
+		m, err := toMap(current)
+		if err != nil {
+			return nil, err
+		}
+		if err := scim.ApplyPatch(m, patch.Operations); err != nil {
+			return nil, err
+		}
+		g := &scim.Group{}
+		return g, fromMap(m, g)
+	})
+}
+
+// saveGroup updates a team with the attributes returned by update for its current representation.
+func (s *Service) saveGroup(c *contextmodel.ReqContext, uid string, update func(*scim.Group) (*scim.Group, error)) response.Response {
+	ctx := c.Req.Context()
+	t, err := s.getTeam(ctx, c.SignedInUser, uid)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get group", err)
+	}
+	current, err := s.toGroup(ctx, c.SignedInUser, t)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get group", err)
+	}
+
+	g, err := update(current)
+	if err != nil {
+		return s.errorResponse(c, "Invalid group", err)
+	}
+	name := strings.TrimSpace(g.DisplayName)
+	if name == "" {
+		return s.errorResponse(c, "Invalid group", scim.Errorf(scim.ErrInvalidValue, "displayName is required"))
+	}
+	memberIDs, err := s.resolveMembers(ctx, c.SignedInUser, g.Members)
+	if err != nil {
+		return s.errorResponse(c, "Invalid group", err)
+	}
+
+	if name != t.Name {
+		err := s.teamService.UpdateTeam(ctx, &team.UpdateTeamCommand{ID: t.ID, Name: name, Email: t.Email, OrgID: t.OrgID})
+		if err != nil {
+			if errors.Is(err, team.ErrTeamNameTaken) {
+				err = scim.Errorf(scim.ErrUniqueness, "group %s already exists", name)
+			}
+			return s.errorResponse(c, "Failed to update group", err)
+		}
+	}
+	if err := s.syncTeamMembers(ctx, c.SignedInUser, t, memberIDs); err != nil {
+		return s.errorResponse(c, "Failed to update group members", err)
+	}
+	if err := s.setExternalID(ctx, t.OrgID, scim.ResourceTypeGroup, t.ID, g.ExternalID); err != nil {
+		return s.errorResponse(c, "Failed to update group", err)
+	}
+
+	t, err = s.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: t.OrgID, ID: t.ID, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return s.errorResponse(c, "Failed to get group", err)
+	}
+	return s.groupResponse(c, http.StatusOK, t)
+}
+
+func (s *Service) deleteGroup(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	t, err := s.getTeam(ctx, c.SignedInUser, web.Params(c.Req)[":id"])
+	if err != nil {
+		return s.errorResponse(c, "Failed to get group", err)
+	}
+
+	if err := s.teamService.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: t.OrgID, ID: t.ID}); err != nil {
+		return s.errorResponse(c, "Failed to delete group", err)
+	}
+	// This should be called from appropriate service when moved
+	if err := s.accessControlService.DeleteTeamPermissions(ctx, t.OrgID, t.ID); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to delete permissions for team", "teamID", t.ID, "orgID", t.OrgID, "error", err)
+	}
+
+	return response.Empty(http.StatusNoContent)
+}
+
+func (s *Service) groupResponse(c *contextmodel.ReqContext, status int, t *team.TeamDTO) response.Response {
+	g, err := s.toGroup(c.Req.Context(), c.SignedInUser, t)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get group", err)
+	}
+	return scimResponse(status, g).SetHeader("Location", g.Meta.Location)
+}
+
+// getTeam returns a team of the organization of the requester by its uid.
+func (s *Service) getTeam(ctx context.Context, requester identity.Requester, uid string) (*team.TeamDTO, error) {
+	t, err := s.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: requester.GetOrgID(), UID: uid, SignedInUser: requester})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return nil, scim.Errorf(scim.ErrNotFound, "group %s not found", uid)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// syncTeamMembers adds the missing members to the team and removes the others. The permission of the members that
+// are kept, such as team administrators, is not changed.
+func (s *Service) syncTeamMembers(ctx context.Context, requester identity.Requester, t *team.TeamDTO, memberIDs map[int64]bool) error {
+	members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: t.OrgID, TeamID: t.ID, SignedInUser: requester})
+	if err != nil {
+		return err
+	}
+
+	current := make(map[int64]bool, len(members))
+	for _, member := range members {
+		current[member.UserID] = true
+		if !memberIDs[member.UserID] {
+			if err := s.setTeamMember(ctx, t.OrgID, t.ID, member.UserID, ""); err != nil {
+				return err
+			}
+		}
+	}
+	for userID := range memberIDs {
+		if !current[userID] {
+			if err := s.setTeamMember(ctx, t.OrgID, t.ID, userID, team.PermissionTypeMember.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setTeamMember sets the permission of a user on a team, the user is removed from the team without permission.
+func (s *Service) setTeamMember(ctx context.Context, orgID, teamID, userID int64, permission string) error {
+	_, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, ac.User{ID: userID}, strconv.FormatInt(teamID, 10), permission)
+	if err != nil {
+		return fmt.Errorf("failed setting permissions for user %d in team %d: %w", userID, teamID, err)
+	}
+	return nil
+}
+
+// resolveMembers returns the ids of the users referenced by the members of a group, which must be members of the
+// organization of the requester.
+func (s *Service) resolveMembers(ctx context.Context, requester identity.Requester, members []scim.Reference) (map[int64]bool, error) {
+	userIDs := make(map[int64]bool, len(members))
+	if len(members) == 0 {
+		return userIDs, nil
+	}
+
+	orgUsers, err := s.orgUsersByID(ctx, requester)
+	if err != nil {
+		return nil, err
+	}
+	byUID := make(map[string]int64, len(orgUsers))
+	for _, orgUser := range orgUsers {
+		byUID[orgUser.UID] = orgUser.UserID
+	}
+
+	for _, member := range members {
+		userID, ok := byUID[member.Value]
+		if !ok {
+			return nil, scim.Errorf(scim.ErrInvalidValue, "member %s is not a user of the organization", member.Value)
+		}
+		userIDs[userID] = true
+	}
+	return userIDs, nil
+}
+
+func (s *Service) orgUsersByID(ctx context.Context, requester identity.Requester) (map[int64]*org.OrgUserDTO, error) {
+	result, err := s.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{OrgID: requester.GetOrgID(), User: requester})
+	if err != nil {
+		return nil, err
+	}
+	orgUsers := make(map[int64]*org.OrgUserDTO, len(result.OrgUsers))
+	for _, orgUser := range result.OrgUsers {
+		orgUsers[orgUser.UserID] = orgUser
+	}
+	return orgUsers, nil
+}
+
+func (s *Service) groupMembers(ctx context.Context, requester identity.Requester, teamID int64, orgUsers map[int64]*org.OrgUserDTO) ([]scim.Reference, error) {
+	members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: requester.GetOrgID(), TeamID: teamID, SignedInUser: requester})
+	if err != nil {
+		return nil, err
+	}
+
+	references := make([]scim.Reference, 0, len(members))
+	for _, member := range members {
+		orgUser, ok := orgUsers[member.UserID]
+		if !ok {
+			continue
+		}
+		display := orgUser.Name
+		if display == "" {
+			display = orgUser.Login
+		}
+		references = append(references, scim.Reference{
+			Value:   orgUser.UID,
+			Ref:     s.location("Users", orgUser.UID),
+			Display: display,
+		})
+	}
+	return references, nil
+}
+
+func (s *Service) toGroup(ctx context.Context, requester identity.Requester, t *team.TeamDTO) (*scim.Group, error) {
+	resource, has, err := s.store.Get(ctx, t.OrgID, scim.ResourceTypeGroup, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		resource = nil
+	}
+	g := s.newGroup(t, resource)
+
+	orgUsers, err := s.orgUsersByID(ctx, requester)
+	if err != nil {
+		return nil, err
+	}
+	if g.Members, err = s.groupMembers(ctx, requester, t.ID, orgUsers); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// newGroup returns a group without members, the resource is nil for teams that were not provisioned.
+func (s *Service) newGroup(t *team.TeamDTO, resource *scim.Resource) *scim.Group {
+	g := &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          t.UID,
+		DisplayName: t.Name,
+		Meta: &scim.Meta{
+			ResourceType: scim.ResourceTypeGroup,
+			Location:     s.location("Groups", t.UID),
+		},
+	}
+	if resource != nil {
+		created, updated := resource.Created.UTC(), resource.Updated.UTC()
+		g.ExternalID = resource.ExternalID
+		g.Meta.Created = &created
+		g.Meta.LastModified = &updated
+	}
+	return g
+}
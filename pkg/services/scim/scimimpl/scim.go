@@ -0,0 +1,113 @@
+package scimimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/scim"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// Service is a SCIM 2.0 service provider, https://datatracker.ietf.org/doc/html/rfc7644. Identity providers use it
+// to provision the users and the teams of the organization of their service account, Users are the members of the
+// organization and Groups are its teams.
+type Service struct {
+	store                  store
+	cfg                    *setting.Cfg
+	accessControlService   ac.Service
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService ac.TeamPermissionsService
+	sessionService         auth.UserTokenService
+	routeRegister          routing.RouteRegister
+	log                    log.Logger
+}
+
+func ProvideService(
+	cfg *setting.Cfg, db db.DB, features featuremgmt.FeatureToggles, routeRegister routing.RouteRegister,
+	accessControl ac.AccessControl, accessControlService ac.Service, userService user.Service, orgService org.Service,
+	teamService team.Service, teamPermissionsService ac.TeamPermissionsService, sessionService auth.UserTokenService,
+) *Service {
+	s := &Service{
+		store:                  &sqlStore{db: db},
+		cfg:                    cfg,
+		accessControlService:   accessControlService,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		sessionService:         sessionService,
+		routeRegister:          routeRegister,
+		log:                    log.New("scim"),
+	}
+
+	if !features.IsEnabledGlobally(featuremgmt.FlagEnableSCIM) {
+		return s
+	}
+
+	s.registerAPIEndpoints(ac.Middleware(accessControl))
+
+	return s
+}
+
+func (s *Service) registerAPIEndpoints(authorize func(ac.Evaluator) web.Handler) {
+	s.routeRegister.Group("/api/scim/v2", func(scimRoute routing.RouteRegister) {
+		scimRoute.Get("/ServiceProviderConfig", routing.Wrap(s.getServiceProviderConfig))
+		scimRoute.Get("/ResourceTypes", routing.Wrap(s.getResourceTypes))
+
+		scimRoute.Group("/Users", func(usersRoute routing.RouteRegister) {
+			usersRoute.Get("/", routing.Wrap(s.listUsers))
+			usersRoute.Post("/", routing.Wrap(s.createUser))
+			usersRoute.Get("/:id", routing.Wrap(s.getUser))
+			usersRoute.Put("/:id", routing.Wrap(s.replaceUser))
+			usersRoute.Patch("/:id", routing.Wrap(s.patchUser))
+			usersRoute.Delete("/:id", routing.Wrap(s.deleteUser))
+		}, authorize(ac.EvalAll(
+			ac.EvalPermission(ac.ActionOrgUsersRead),
+			ac.EvalPermission(ac.ActionOrgUsersAdd),
+			ac.EvalPermission(ac.ActionOrgUsersWrite),
+			ac.EvalPermission(ac.ActionOrgUsersRemove),
+		)))
+
+		scimRoute.Group("/Groups", func(groupsRoute routing.RouteRegister) {
+			groupsRoute.Get("/", routing.Wrap(s.listGroups))
+			groupsRoute.Post("/", routing.Wrap(s.createGroup))
+			groupsRoute.Get("/:id", routing.Wrap(s.getGroup))
+			groupsRoute.Put("/:id", routing.Wrap(s.replaceGroup))
+			groupsRoute.Patch("/:id", routing.Wrap(s.patchGroup))
+			groupsRoute.Delete("/:id", routing.Wrap(s.deleteGroup))
+		}, authorize(ac.EvalAll(
+			ac.EvalPermission(ac.ActionTeamsCreate),
+			ac.EvalPermission(ac.ActionTeamsRead),
+			ac.EvalPermission(ac.ActionTeamsWrite),
+			ac.EvalPermission(ac.ActionTeamsDelete),
+			ac.EvalPermission(ac.ActionTeamsPermissionsWrite),
+			ac.EvalPermission(ac.ActionOrgUsersRead),
+		)))
+	}, middleware.ReqSignedIn)
+}
+
+// setExternalID stores the id of a resource in the identity provider, with the time of its last modification.
+func (s *Service) setExternalID(ctx context.Context, orgID int64, resourceType string, resourceID int64, externalID string) error {
+	now := time.Now()
+	return s.store.Upsert(ctx, &scim.Resource{
+		OrgID:        orgID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		ExternalID:   externalID,
+		Created:      now,
+		Updated:      now,
+	})
+}
@@ -0,0 +1,330 @@
+package scimimpl
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/scim"
+	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/userimpl"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationSCIM(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	env := setupTestEnv(t)
+
+	var userID string
+	t.Run("creates a user in the organization", func(t *testing.T) {
+		res := env.send(t, http.MethodPost, "/api/scim/v2/Users", `{
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+			"externalId": "e-1",
+			"userName": "jdoe@example.com",
+			"name": {"givenName": "Jane", "familyName": "Doe"},
+			"emails": [{"value": "jane@example.com", "primary": true}],
+			"active": true
+		}`)
+		require.Equal(t, http.StatusCreated, res.status, res.body)
+
+		u := scim.User{}
+		require.NoError(t, json.Unmarshal([]byte(res.body), &u))
+		require.NotEmpty(t, u.ID)
+		require.Equal(t, "e-1", u.ExternalID)
+		require.Equal(t, "jdoe@example.com", u.UserName)
+		require.Equal(t, "Jane Doe", u.DisplayName)
+		require.Equal(t, []scim.MultiValue{{Value: "jane@example.com", Type: "work", Primary: true}}, u.Emails)
+		require.True(t, *u.Active)
+		require.Equal(t, "http://localhost:3000/api/scim/v2/Users/"+u.ID, u.Meta.Location)
+		userID = u.ID
+
+		orgUser, err := env.orgService.SearchOrgUsers(context.Background(), &org.SearchOrgUsersQuery{OrgID: 1, DontEnforceAccessControl: true, Query: "jdoe"})
+		require.NoError(t, err)
+		require.Len(t, orgUser.OrgUsers, 1)
+		require.Equal(t, string(org.RoleViewer), orgUser.OrgUsers[0].Role)
+	})
+
+	t.Run("rejects users that already exist", func(t *testing.T) {
+		res := env.send(t, http.MethodPost, "/api/scim/v2/Users", `{"userName": "JDOE@example.com"}`)
+		require.Equal(t, http.StatusConflict, res.status)
+		require.Contains(t, res.body, `"scimType":"uniqueness"`)
+	})
+
+	t.Run("filters users", func(t *testing.T) {
+		res := env.send(t, http.MethodGet, `/api/scim/v2/Users?filter=userName+eq+"JDOE@EXAMPLE.COM"`, "")
+		require.Equal(t, http.StatusOK, res.status, res.body)
+		list := scim.ListResponse{}
+		require.NoError(t, json.Unmarshal([]byte(res.body), &list))
+		require.Equal(t, 1, list.TotalResults)
+
+		res = env.send(t, http.MethodGet, `/api/scim/v2/Users?filter=externalId+eq+"e-2"`, "")
+		require.NoError(t, json.Unmarshal([]byte(res.body), &list))
+		require.Equal(t, 0, list.TotalResults)
+
+		res = env.send(t, http.MethodGet, `/api/scim/v2/Users?filter=userName+zz+"x"`, "")
+		require.Equal(t, http.StatusBadRequest, res.status)
+		require.Contains(t, res.body, `"scimType":"invalidFilter"`)
+	})
+
+	t.Run("paginates users", func(t *testing.T) {
+		res := env.send(t, http.MethodGet, "/api/scim/v2/Users?startIndex=2&count=1", "")
+		require.Equal(t, http.StatusOK, res.status, res.body)
+		list := scim.ListResponse{}
+		require.NoError(t, json.Unmarshal([]byte(res.body), &list))
+		// the admin of the organization and the provisioned user
+		require.Equal(t, 2, list.TotalResults)
+		require.Equal(t, 2, list.StartIndex)
+		require.Len(t, list.Resources, 1)
+	})
+
+	t.Run("deactivates a user with a patch", func(t *testing.T) {
+		res := env.send(t, http.MethodPatch, "/api/scim/v2/Users/"+userID, `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "Replace", "path": "active", "value": "False"},
+				{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "jane.doe@example.com"}
+			]
+		}`)
+		require.Equal(t, http.StatusOK, res.status, res.body)
+		require.Contains(t, res.body, `"active":false`)
+
+		usr, err := env.userService.GetByUID(context.Background(), &user.GetUserByUIDQuery{UID: userID})
+		require.NoError(t, err)
+		require.True(t, usr.IsDisabled)
+		require.Equal(t, "jane.doe@example.com", usr.Email)
+		require.Equal(t, []int64{usr.ID}, env.revokedUserIDs)
+	})
+
+	t.Run("does not change users of other organizations", func(t *testing.T) {
+		usr, err := env.userService.GetByUID(context.Background(), &user.GetUserByUIDQuery{UID: userID})
+		require.NoError(t, err)
+		require.NoError(t, env.orgService.AddOrgUser(context.Background(), &org.AddOrgUserCommand{OrgID: env.otherOrgID, UserID: usr.ID, Role: org.RoleViewer}))
+
+		res := env.send(t, http.MethodPut, "/api/scim/v2/Users/"+userID, `{"userName": "someone-else"}`)
+		require.Equal(t, http.StatusForbidden, res.status, res.body)
+
+		require.NoError(t, env.orgService.RemoveOrgUser(context.Background(), &org.RemoveOrgUserCommand{OrgID: env.otherOrgID, UserID: usr.ID}))
+	})
+
+	var groupID string
+	t.Run("creates a group with members", func(t *testing.T) {
+		res := env.send(t, http.MethodPost, "/api/scim/v2/Groups", `{
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+			"externalId": "g-1",
+			"displayName": "Engineering",
+			"members": [{"value": "`+userID+`"}]
+		}`)
+		require.Equal(t, http.StatusCreated, res.status, res.body)
+
+		g := scim.Group{}
+		require.NoError(t, json.Unmarshal([]byte(res.body), &g))
+		require.Equal(t, "Engineering", g.DisplayName)
+		require.Equal(t, "g-1", g.ExternalID)
+		require.Len(t, g.Members, 1)
+		require.Equal(t, userID, g.Members[0].Value)
+		require.NotNil(t, g.Meta.Created)
+		groupID = g.ID
+	})
+
+	t.Run("rejects members that are not users of the organization", func(t *testing.T) {
+		res := env.send(t, http.MethodPost, "/api/scim/v2/Groups", `{"displayName": "Other", "members": [{"value": "unknown"}]}`)
+		require.Equal(t, http.StatusBadRequest, res.status, res.body)
+		require.Contains(t, res.body, `"scimType":"invalidValue"`)
+	})
+
+	t.Run("removes members and renames a group with a patch", func(t *testing.T) {
+		res := env.send(t, http.MethodPatch, "/api/scim/v2/Groups/"+groupID, `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "remove", "path": "members[value eq \"`+userID+`\"]"},
+				{"op": "replace", "value": {"displayName": "Platform"}}
+			]
+		}`)
+		require.Equal(t, http.StatusOK, res.status, res.body)
+
+		g := scim.Group{}
+		require.NoError(t, json.Unmarshal([]byte(res.body), &g))
+		require.Equal(t, "Platform", g.DisplayName)
+		require.Empty(t, g.Members)
+	})
+
+	t.Run("lists groups without members", func(t *testing.T) {
+		res := env.send(t, http.MethodPut, "/api/scim/v2/Groups/"+groupID, `{"displayName": "Platform", "members": [{"value": "`+userID+`"}]}`)
+		require.Equal(t, http.StatusOK, res.status, res.body)
+
+		res = env.send(t, http.MethodGet, `/api/scim/v2/Groups?filter=members[value+eq+"`+userID+`"]&excludedAttributes=members`, "")
+		require.Equal(t, http.StatusOK, res.status, res.body)
+		require.Contains(t, res.body, `"totalResults":1`)
+		require.NotContains(t, res.body, `"members"`)
+	})
+
+	t.Run("deletes users", func(t *testing.T) {
+		res := env.send(t, http.MethodDelete, "/api/scim/v2/Users/"+userID, "")
+		require.Equal(t, http.StatusNoContent, res.status, res.body)
+
+		_, err := env.userService.GetByUID(context.Background(), &user.GetUserByUIDQuery{UID: userID})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+
+		res = env.send(t, http.MethodGet, "/api/scim/v2/Users/"+userID, "")
+		require.Equal(t, http.StatusNotFound, res.status)
+	})
+
+	t.Run("deletes groups", func(t *testing.T) {
+		res := env.send(t, http.MethodDelete, "/api/scim/v2/Groups/"+groupID, "")
+		require.Equal(t, http.StatusNoContent, res.status, res.body)
+
+		res = env.send(t, http.MethodGet, "/api/scim/v2/Groups/"+groupID, "")
+		require.Equal(t, http.StatusNotFound, res.status)
+		require.Equal(t, "application/scim+json; charset=utf-8", res.contentType)
+	})
+}
+
+type testEnv struct {
+	server         *webtest.Server
+	admin          *user.SignedInUser
+	userService    user.Service
+	orgService     org.Service
+	otherOrgID     int64
+	revokedUserIDs []int64
+}
+
+type testResponse struct {
+	status      int
+	body        string
+	contentType string
+}
+
+func (e *testEnv) send(t *testing.T, method, target, body string) testResponse {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := webtest.RequestWithSignedInUser(e.server.NewRequest(method, target, reader), e.admin)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/scim+json")
+	}
+	res, err := e.server.Send(req)
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return testResponse{status: res.StatusCode, body: string(b), contentType: res.Header.Get("Content-Type")}
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.AutoAssignOrgRole = string(org.RoleViewer)
+	tracer := tracing.InitializeTracerForTest()
+
+	teamService, err := teamimpl.ProvideService(sqlStore, cfg, tracer)
+	require.NoError(t, err)
+	orgService, err := orgimpl.ProvideService(sqlStore, cfg, quotatest.New(false, nil))
+	require.NoError(t, err)
+	userService, err := userimpl.ProvideService(
+		sqlStore, orgService, cfg, teamService, nil, tracer,
+		quotatest.New(false, nil), supportbundlestest.NewFakeBundleService(),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	adminUser, err := userService.Create(ctx, &user.CreateUserCommand{Login: "admin", OrgName: "Main"})
+	require.NoError(t, err)
+	otherOrg, err := orgService.CreateWithMember(ctx, &org.CreateOrgCommand{Name: "Other", UserID: adminUser.ID})
+	require.NoError(t, err)
+
+	env := &testEnv{
+		userService: userService,
+		orgService:  orgService,
+		otherOrgID:  otherOrg.ID,
+	}
+	sessionService := authtest.NewFakeUserAuthTokenService()
+	sessionService.RevokeAllUserTokensProvider = func(ctx context.Context, userID int64) error {
+		env.revokedUserIDs = append(env.revokedUserIDs, userID)
+		return nil
+	}
+
+	router := routing.NewRouteRegister()
+	ProvideService(
+		cfg, sqlStore, featuremgmt.WithFeatures(featuremgmt.FlagEnableSCIM), router,
+		acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), &actest.FakeService{},
+		userService, orgService, teamService, &fakeTeamPermissionsService{db: sqlStore}, sessionService,
+	)
+	env.server = webtest.NewServer(t, router)
+
+	permissions := []accesscontrol.Permission{}
+	for _, action := range []string{
+		accesscontrol.ActionOrgUsersRead, accesscontrol.ActionOrgUsersAdd, accesscontrol.ActionOrgUsersWrite,
+		accesscontrol.ActionOrgUsersRemove,
+	} {
+		permissions = append(permissions, accesscontrol.Permission{Action: action, Scope: "users:*"})
+	}
+	for _, action := range []string{
+		accesscontrol.ActionTeamsCreate, accesscontrol.ActionTeamsRead, accesscontrol.ActionTeamsWrite,
+		accesscontrol.ActionTeamsDelete, accesscontrol.ActionTeamsPermissionsWrite,
+	} {
+		permissions = append(permissions, accesscontrol.Permission{Action: action, Scope: "teams:*"})
+	}
+	env.admin = &user.SignedInUser{
+		UserID:  adminUser.ID,
+		OrgID:   adminUser.OrgID,
+		OrgRole: org.RoleAdmin,
+		Permissions: map[int64]map[string][]string{
+			adminUser.OrgID: accesscontrol.GroupScopesByActionContext(ctx, permissions),
+		},
+	}
+	return env
+}
+
+// fakeTeamPermissionsService manages the team members as the team permissions service, without the permissions.
+type fakeTeamPermissionsService struct {
+	actest.FakePermissionsService
+	db db.DB
+}
+
+func (s *fakeTeamPermissionsService) SetUserPermission(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	teamID, err := strconv.ParseInt(resourceID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &accesscontrol.ResourcePermission{}, s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if permission == "" {
+			return teamimpl.RemoveTeamMemberHook(sess, &team.RemoveTeamMemberCommand{OrgID: orgID, TeamID: teamID, UserID: user.ID})
+		}
+		return teamimpl.AddOrUpdateTeamMemberHook(sess, user.ID, orgID, teamID, false, team.PermissionTypeMember)
+	})
+}
@@ -0,0 +1,69 @@
+package scimimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/scim"
+)
+
+type store interface {
+	Get(ctx context.Context, orgID int64, resourceType string, resourceID int64) (*scim.Resource, bool, error)
+	List(ctx context.Context, orgID int64, resourceType string) ([]*scim.Resource, error)
+	// Upsert creates the resource or updates its external id and its last modification.
+	Upsert(ctx context.Context, resource *scim.Resource) error
+	Delete(ctx context.Context, orgID int64, resourceType string, resourceID int64) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Get(ctx context.Context, orgID int64, resourceType string, resourceID int64) (*scim.Resource, bool, error) {
+	resource := &scim.Resource{}
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("org_id = ? AND resource_type = ? AND resource_id = ?", orgID, resourceType, resourceID).Get(resource)
+		return err
+	})
+	return resource, has, err
+}
+
+func (s *sqlStore) List(ctx context.Context, orgID int64, resourceType string) ([]*scim.Resource, error) {
+	resources := make([]*scim.Resource, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND resource_type = ?", orgID, resourceType).Find(&resources)
+	})
+	return resources, err
+}
+
+func (s *sqlStore) Upsert(ctx context.Context, resource *scim.Resource) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &scim.Resource{}
+		has, err := sess.Where("org_id = ? AND resource_type = ? AND resource_id = ?",
+			resource.OrgID, resource.ResourceType, resource.ResourceID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(resource)
+			return err
+		}
+
+		resource.ID = existing.ID
+		resource.Created = existing.Created
+		_, err = sess.ID(existing.ID).Cols("external_id", "updated").Update(resource)
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, orgID int64, resourceType string, resourceID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM scim_resource WHERE org_id = ? AND resource_type = ? AND resource_id = ?",
+			orgID, resourceType, resourceID)
+		return err
+	})
+}
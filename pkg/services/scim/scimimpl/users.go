@@ -0,0 +1,425 @@
+package scimimpl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/scim"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) listUsers(c *contextmodel.ReqContext) response.Response {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return s.errorResponse(c, "Invalid list request", err)
+	}
+
+	users, err := s.searchUsers(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return s.errorResponse(c, "Failed to list users", err)
+	}
+	result, err := listResponse(users, q)
+	if err != nil {
+		return s.errorResponse(c, "Failed to list users", err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (s *Service) getUser(c *contextmodel.ReqContext) response.Response {
+	orgUser, err := s.getOrgUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"])
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+	return s.userResponse(c, http.StatusOK, orgUser)
+}
+
+func (s *Service) createUser(c *contextmodel.ReqContext) response.Response {
+	u := scim.User{}
+	if err := bind(c, &u); err != nil {
+		return s.errorResponse(c, "Invalid user", err)
+	}
+
+	userID, err := s.provisionUser(c.Req.Context(), c.SignedInUser, &u)
+	if err != nil {
+		return s.errorResponse(c, "Failed to create user", err)
+	}
+	if err := s.setExternalID(c.Req.Context(), c.SignedInUser.GetOrgID(), scim.ResourceTypeUser, userID, u.ExternalID); err != nil {
+		return s.errorResponse(c, "Failed to create user", err)
+	}
+
+	orgUser, err := s.getOrgUserByID(c.Req.Context(), c.SignedInUser, userID)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+	return s.userResponse(c, http.StatusCreated, orgUser)
+}
+
+func (s *Service) replaceUser(c *contextmodel.ReqContext) response.Response {
+	u := scim.User{}
+	if err := bind(c, &u); err != nil {
+		return s.errorResponse(c, "Invalid user", err)
+	}
+	return s.saveUser(c, web.Params(c.Req)[":id"], func(*scim.User) (*scim.User, error) {
+		return &u, nil
+	})
+}
+
+func (s *Service) patchUser(c *contextmodel.ReqContext) response.Response {
+	patch := scim.PatchRequest{}
+	if err := bind(c, &patch); err != nil {
+		return s.errorResponse(c, "Invalid patch", err)
+	}
+	return s.saveUser(c, web.Params(c.Req)[":id"], func(current *scim.User) (*scim.User, error) {
+		m, err := toMap(current)
+		if err != nil {
+			return nil, err
+		}
+		if err := scim.ApplyPatch(m, patch.Operations); err != nil {
+			return nil, err
+		}
+		// some identity providers send booleans as strings, such as "False"
+		for key, value := range m {
+			if active, ok := value.(string); ok && strings.EqualFold(key, "active") {
+				b, err := strconv.ParseBool(strings.ToLower(active))
+				if err != nil {
+					return nil, scim.Errorf(scim.ErrInvalidValue, "invalid value %q of active", active)
+				}
+				m[key] = b
+			}
+		}
+		u := &scim.User{}
+		return u, fromMap(m, u)
+	})
+}
+
+// saveUser updates a user with the attributes returned by update for its current representation.
+func (s *Service) saveUser(c *contextmodel.ReqContext, uid string, update func(*scim.User) (*scim.User, error)) response.Response {
+	ctx := c.Req.Context()
+	orgUser, err := s.getOrgUser(ctx, c.SignedInUser, uid)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+	current, err := s.toUser(ctx, orgUser)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+
+	u, err := update(current)
+	if err != nil {
+		return s.errorResponse(c, "Invalid user", err)
+	}
+	if err := s.updateUser(ctx, orgUser, u); err != nil {
+		return s.errorResponse(c, "Failed to update user", err)
+	}
+	if err := s.setExternalID(ctx, orgUser.OrgID, scim.ResourceTypeUser, orgUser.UserID, u.ExternalID); err != nil {
+		return s.errorResponse(c, "Failed to update user", err)
+	}
+
+	orgUser, err = s.getOrgUserByID(ctx, c.SignedInUser, orgUser.UserID)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+	return s.userResponse(c, http.StatusOK, orgUser)
+}
+
+func (s *Service) deleteUser(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgUser, err := s.getOrgUser(ctx, c.SignedInUser, web.Params(c.Req)[":id"])
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+	usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: orgUser.UserID})
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+
+	// users who are not members of other organizations are deleted, unless they are server administrators
+	cmd := &org.RemoveOrgUserCommand{UserID: usr.ID, OrgID: orgUser.OrgID, ShouldDeleteOrphanedUser: !usr.IsAdmin}
+	if err := s.orgService.RemoveOrgUser(ctx, cmd); err != nil {
+		if errors.Is(err, org.ErrLastOrgAdmin) {
+			err = scim.Errorf(scim.ErrForbidden, "the user is the last administrator of the organization")
+		}
+		return s.errorResponse(c, "Failed to delete user", err)
+	}
+
+	permissionsOrgID := cmd.OrgID
+	if cmd.UserWasDeleted {
+		permissionsOrgID = ac.GlobalOrgID
+	}
+	if err := s.accessControlService.DeleteUserPermissions(ctx, permissionsOrgID, cmd.UserID); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to delete permissions for user", "userID", cmd.UserID, "orgID", permissionsOrgID, "error", err)
+	}
+
+	return response.Empty(http.StatusNoContent)
+}
+
+func (s *Service) userResponse(c *contextmodel.ReqContext, status int, orgUser *org.OrgUserDTO) response.Response {
+	u, err := s.toUser(c.Req.Context(), orgUser)
+	if err != nil {
+		return s.errorResponse(c, "Failed to get user", err)
+	}
+	return scimResponse(status, u).SetHeader("Location", u.Meta.Location)
+}
+
+// searchUsers returns the members of the organization of the requester.
+func (s *Service) searchUsers(ctx context.Context, requester identity.Requester) ([]*scim.User, error) {
+	result, err := s.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{OrgID: requester.GetOrgID(), User: requester})
+	if err != nil {
+		return nil, err
+	}
+	resources, err := s.store.List(ctx, requester.GetOrgID(), scim.ResourceTypeUser)
+	if err != nil {
+		return nil, err
+	}
+	externalIDs := make(map[int64]string, len(resources))
+	for _, resource := range resources {
+		externalIDs[resource.ResourceID] = resource.ExternalID
+	}
+
+	users := make([]*scim.User, 0, len(result.OrgUsers))
+	for _, orgUser := range result.OrgUsers {
+		users = append(users, s.newUser(orgUser, externalIDs[orgUser.UserID]))
+	}
+	return users, nil
+}
+
+// getOrgUser returns a member of the organization of the requester by the uid of the user.
+func (s *Service) getOrgUser(ctx context.Context, requester identity.Requester, uid string) (*org.OrgUserDTO, error) {
+	usr, err := s.userService.GetByUID(ctx, &user.GetUserByUIDQuery{UID: uid})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, scim.Errorf(scim.ErrNotFound, "user %s not found", uid)
+		}
+		return nil, err
+	}
+	if usr.IsServiceAccount {
+		return nil, scim.Errorf(scim.ErrNotFound, "user %s not found", uid)
+	}
+
+	orgUser, err := s.getOrgUserByID(ctx, requester, usr.ID)
+	if err != nil {
+		if errors.Is(err, scim.ErrNotFound) {
+			return nil, scim.Errorf(scim.ErrNotFound, "user %s not found", uid)
+		}
+		return nil, err
+	}
+	return orgUser, nil
+}
+
+func (s *Service) getOrgUserByID(ctx context.Context, requester identity.Requester, userID int64) (*org.OrgUserDTO, error) {
+	result, err := s.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{
+		OrgID:  requester.GetOrgID(),
+		UserID: userID,
+		User:   requester,
+		Limit:  1,
+		Page:   1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.OrgUsers) == 0 {
+		return nil, scim.ErrNotFound.Errorf("user %d is not a member of the organization", userID)
+	}
+	return result.OrgUsers[0], nil
+}
+
+// provisionUser creates a user and adds it to the organization of the requester. Existing users, known by their
+// login or their email, are only added to the organization.
+func (s *Service) provisionUser(ctx context.Context, requester identity.Requester, u *scim.User) (int64, error) {
+	login, email, name := userAttributes(u)
+	if login == "" {
+		return 0, scim.Errorf(scim.ErrInvalidValue, "userName is required")
+	}
+
+	usr, err := s.findUser(ctx, login, email)
+	if err != nil {
+		return 0, err
+	}
+	if usr == nil {
+		usr, err = s.userService.Create(ctx, &user.CreateUserCommand{
+			Login:        login,
+			Email:        email,
+			Name:         name,
+			IsDisabled:   u.Active != nil && !*u.Active,
+			SkipOrgSetup: true,
+		})
+		if err != nil {
+			if errors.Is(err, user.ErrUserAlreadyExists) {
+				return 0, scim.Errorf(scim.ErrUniqueness, "user %s already exists", login)
+			}
+			return 0, err
+		}
+	}
+
+	err = s.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{
+		OrgID:  requester.GetOrgID(),
+		UserID: usr.ID,
+		Role:   org.RoleType(s.cfg.AutoAssignOrgRole),
+	})
+	if err != nil {
+		if errors.Is(err, org.ErrOrgUserAlreadyAdded) {
+			return 0, scim.Errorf(scim.ErrUniqueness, "user %s already exists", login)
+		}
+		return 0, err
+	}
+	return usr.ID, nil
+}
+
+// findUser returns the user with the login or the email, or nil if there is none.
+func (s *Service) findUser(ctx context.Context, login, email string) (*user.User, error) {
+	usr, err := s.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: login})
+	if err == nil || !errors.Is(err, user.ErrUserNotFound) {
+		return usr, err
+	}
+	if email == "" {
+		return nil, nil
+	}
+	usr, err = s.userService.GetByEmail(ctx, &user.GetUserByEmailQuery{Email: email})
+	if errors.Is(err, user.ErrUserNotFound) {
+		return nil, nil
+	}
+	return usr, err
+}
+
+// updateUser sets the attributes of a member of the organization. Users who are members of other organizations and
+// server administrators are not managed by a single organization, their attributes can't be changed.
+func (s *Service) updateUser(ctx context.Context, orgUser *org.OrgUserDTO, u *scim.User) error {
+	login, email, name := userAttributes(u)
+	if login == "" {
+		return scim.Errorf(scim.ErrInvalidValue, "userName is required")
+	}
+
+	cmd := &user.UpdateUserCommand{UserID: orgUser.UserID}
+	changed := false
+	if !strings.EqualFold(login, orgUser.Login) {
+		cmd.Login = login
+		changed = true
+	}
+	if email != "" && !strings.EqualFold(email, orgUser.Email) {
+		cmd.Email = email
+		changed = true
+	}
+	if name != "" && name != orgUser.Name {
+		cmd.Name = name
+		changed = true
+	}
+	if u.Active != nil && *u.Active == orgUser.IsDisabled {
+		isDisabled := !*u.Active
+		cmd.IsDisabled = &isDisabled
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: orgUser.UserID})
+	if err != nil {
+		return err
+	}
+	if usr.IsAdmin {
+		return scim.Errorf(scim.ErrForbidden, "user %s is a server administrator", usr.UID)
+	}
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: usr.ID})
+	if err != nil {
+		return err
+	}
+	if len(orgs) > 1 {
+		return scim.Errorf(scim.ErrForbidden, "user %s is a member of other organizations", usr.UID)
+	}
+
+	for _, value := range []string{cmd.Login, cmd.Email} {
+		if value == "" {
+			continue
+		}
+		other, err := s.findUser(ctx, value, "")
+		if err != nil {
+			return err
+		}
+		if other != nil && other.ID != usr.ID {
+			return scim.Errorf(scim.ErrUniqueness, "user %s already exists", value)
+		}
+	}
+
+	if err := s.userService.Update(ctx, cmd); err != nil {
+		return err
+	}
+
+	// disabled users are logged out
+	if cmd.IsDisabled != nil && *cmd.IsDisabled {
+		if err := s.sessionService.RevokeAllUserTokens(ctx, usr.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) toUser(ctx context.Context, orgUser *org.OrgUserDTO) (*scim.User, error) {
+	resource, _, err := s.store.Get(ctx, orgUser.OrgID, scim.ResourceTypeUser, orgUser.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.newUser(orgUser, resource.ExternalID), nil
+}
+
+func (s *Service) newUser(orgUser *org.OrgUserDTO, externalID string) *scim.User {
+	active := !orgUser.IsDisabled
+	created, updated := orgUser.Created.UTC().Truncate(time.Second), orgUser.Updated.UTC().Truncate(time.Second)
+	u := &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          orgUser.UID,
+		ExternalID:  externalID,
+		UserName:    orgUser.Login,
+		DisplayName: orgUser.Name,
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: scim.ResourceTypeUser,
+			Created:      &created,
+			LastModified: &updated,
+			Location:     s.location("Users", orgUser.UID),
+		},
+	}
+	if orgUser.Name != "" {
+		u.Name = &scim.Name{Formatted: orgUser.Name}
+	}
+	if orgUser.Email != "" {
+		u.Emails = []scim.MultiValue{{Value: orgUser.Email, Type: "work", Primary: true}}
+	}
+	return u
+}
+
+// userAttributes returns the login, the email and the name of a user from its SCIM representation.
+func userAttributes(u *scim.User) (login, email, name string) {
+	login = strings.TrimSpace(u.UserName)
+
+	for _, e := range u.Emails {
+		if e.Primary || email == "" {
+			email = strings.TrimSpace(e.Value)
+		}
+		if e.Primary {
+			break
+		}
+	}
+	if email == "" && strings.Contains(login, "@") {
+		email = login
+	}
+
+	switch {
+	case u.DisplayName != "":
+		name = u.DisplayName
+	case u.Name != nil && u.Name.Formatted != "":
+		name = u.Name.Formatted
+	case u.Name != nil:
+		name = strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+	}
+	return login, email, strings.TrimSpace(name)
+}
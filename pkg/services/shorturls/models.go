@@ -11,6 +11,9 @@ var (
 	ErrShortURLNotFound     = errutil.NotFound("shorturl.not-found")
 	ErrShortURLAbsolutePath = errutil.ValidationFailed("shorturl.absolute-path", errutil.WithPublicMessage("Path should be relative"))
 	ErrShortURLInvalidPath  = errutil.ValidationFailed("shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
+	ErrShortURLInvalidSlug  = errutil.ValidationFailed("shorturl.invalid-slug", errutil.WithPublicMessage("Slugs can only contain letters, numbers, '-' and '_' and be up to 40 characters long"))
+	ErrShortURLSlugTaken    = errutil.Conflict("shorturl.slug-taken", errutil.WithPublicMessage("A short URL with this slug already exists"))
+	ErrShortURLInvalidTTL   = errutil.ValidationFailed("shorturl.invalid-expiry", errutil.WithPublicMessage("Invalid short URL expiry"))
 	ErrShortURLInternal     = errutil.Internal("shorturl.internal")
 )
 
@@ -22,10 +25,45 @@ type ShortUrl struct {
 	CreatedBy  int64
 	CreatedAt  int64
 	LastSeenAt int64
+	// ExpiresAt is the unix time the short URL stops resolving, 0 when it does not expire.
+	ExpiresAt int64
+	// Hits counts the visits of the short URL.
+	Hits int64
+}
+
+// IsExpired returns whether the short URL has expired at the given time.
+func (s *ShortUrl) IsExpired(now time.Time) bool {
+	return s.ExpiresAt > 0 && s.ExpiresAt <= now.Unix()
+}
+
+type CreateShortURLCommand struct {
+	Path string
+	// Slug is used as the UID of the short URL when set, a random UID is generated otherwise.
+	Slug string
+	// ExpiresIn is the lifetime of the short URL, it does not expire when 0.
+	ExpiresIn time.Duration
 }
 
 type DeleteShortUrlCommand struct {
+	// OlderThan deletes the short URLs without expiry created before and never visited.
 	OlderThan time.Time
+	// ExpiredBefore deletes the short URLs that expired before.
+	ExpiredBefore time.Time
 
 	NumDeleted int64
 }
+
+type SearchShortURLsQuery struct {
+	OrgID int64
+	// Query matches the UID or the path of the short URLs.
+	Query string
+	Limit int
+	Page  int
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64
+	ShortURLs  []*ShortUrl
+	Page       int
+	PerPage    int
+}
@@ -8,7 +8,9 @@ import (
 
 type Service interface {
 	GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*ShortUrl, error)
-	CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*ShortUrl, error)
+	CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *CreateShortURLCommand) (*ShortUrl, error)
 	UpdateLastSeenAt(ctx context.Context, shortURL *ShortUrl) error
+	SearchShortURLs(ctx context.Context, query *SearchShortURLsQuery) (*SearchShortURLsResult, error)
+	DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *DeleteShortUrlCommand) error
 }
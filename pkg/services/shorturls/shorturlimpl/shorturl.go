@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/teris-io/shortid"
 )
 
@@ -27,15 +28,22 @@ func ProvideService(db db.DB) *ShortURLService {
 }
 
 func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
-	return s.SQLStore.Get(ctx, user, uid)
+	shortURL, err := s.SQLStore.Get(ctx, user, uid)
+	if err != nil {
+		return nil, err
+	}
+	if shortURL.IsExpired(getTime()) {
+		return nil, shorturls.ErrShortURLNotFound.Errorf("short URL expired")
+	}
+	return shortURL, nil
 }
 
 func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	return s.SQLStore.Update(ctx, shortURL)
 }
 
-func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, relPath string) (*shorturls.ShortUrl, error) {
-	relPath = strings.TrimSpace(relPath)
+func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+	relPath := strings.TrimSpace(cmd.Path)
 
 	if path.IsAbs(relPath) {
 		return nil, shorturls.ErrShortURLAbsolutePath.Errorf("expected relative path: %s", relPath)
@@ -44,27 +52,74 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 		return nil, shorturls.ErrShortURLInvalidPath.Errorf("path cannot contain '../': %s", relPath)
 	}
 
-	uid, err := shortid.Generate()
+	if cmd.ExpiresIn < 0 {
+		return nil, shorturls.ErrShortURLInvalidTTL.Errorf("expiry cannot be negative: %s", cmd.ExpiresIn)
+	}
+
+	uid, err := s.shortURLUID(ctx, user, strings.TrimSpace(cmd.Slug))
 	if err != nil {
-		return nil, shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+		return nil, err
 	}
 
-	now := time.Now().Unix()
+	now := getTime()
 	shortURL := shorturls.ShortUrl{
 		OrgId:     user.OrgID,
 		Uid:       uid,
 		Path:      relPath,
 		CreatedBy: user.UserID,
-		CreatedAt: now,
+		CreatedAt: now.Unix(),
+	}
+	if cmd.ExpiresIn > 0 {
+		shortURL.ExpiresAt = now.Add(cmd.ExpiresIn).Unix()
 	}
 
 	if err := s.SQLStore.Insert(ctx, &shortURL); err != nil {
+		if shorturls.ErrShortURLSlugTaken.Is(err) {
+			return nil, err
+		}
 		return nil, shorturls.ErrShortURLInternal.Errorf("failed to insert shorturl: %w", err)
 	}
 
 	return &shortURL, nil
 }
 
+// shortURLUID returns the slug as the UID of a new short URL, or a random UID when the slug is empty.
+func (s ShortURLService) shortURLUID(ctx context.Context, user *user.SignedInUser, slug string) (string, error) {
+	if slug == "" {
+		uid, err := shortid.Generate()
+		if err != nil {
+			return "", shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+		}
+		return uid, nil
+	}
+
+	if !util.IsValidShortUID(slug) || util.IsShortUIDTooLong(slug) {
+		return "", shorturls.ErrShortURLInvalidSlug.Errorf("invalid slug: %s", slug)
+	}
+	_, err := s.SQLStore.Get(ctx, user, slug)
+	if err == nil {
+		return "", shorturls.ErrShortURLSlugTaken.Errorf("slug already exists: %s", slug)
+	}
+	if !shorturls.ErrShortURLNotFound.Is(err) {
+		return "", shorturls.ErrShortURLInternal.Errorf("failed to check slug: %w", err)
+	}
+	return slug, nil
+}
+
+func (s ShortURLService) SearchShortURLs(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = 1000
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.SQLStore.Search(ctx, query)
+}
+
+func (s ShortURLService) DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error {
+	return s.SQLStore.DeleteByUID(ctx, user.OrgID, uid)
+}
+
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.SQLStore.Delete(ctx, cmd)
 }
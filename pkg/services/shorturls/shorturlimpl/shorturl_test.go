@@ -18,6 +18,9 @@ func TestMain(m *testing.M) {
 }
 
 func TestShortURLService(t *testing.T) {
+	otherOrgUser := &user.SignedInUser{UserID: 1, OrgID: 2}
+	expiringUser := &user.SignedInUser{UserID: 1, OrgID: 3}
+	adminUser := &user.SignedInUser{UserID: 1, OrgID: 4}
 	user := &user.SignedInUser{UserID: 1}
 	store := db.InitTestDB(t)
 
@@ -26,7 +29,7 @@ func TestShortURLService(t *testing.T) {
 
 		service := ShortURLService{SQLStore: &sqlStore{db: store}}
 
-		newShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+		newShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 		require.NoError(t, err)
 		require.NotNil(t, newShortURL)
 		require.NotEmpty(t, newShortURL.Uid)
@@ -53,10 +56,11 @@ func TestShortURLService(t *testing.T) {
 			updatedShortURL, err := service.GetShortURLByUID(context.Background(), user, existingShortURL.Uid)
 			require.NoError(t, err)
 			require.Equal(t, expectedTime.Unix(), updatedShortURL.LastSeenAt)
+			require.Equal(t, int64(1), updatedShortURL.Hits)
 		})
 
 		t.Run("and stale short urls can be deleted", func(t *testing.T) {
-			staleShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+			staleShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 			require.NoError(t, err)
 			require.NotNil(t, staleShortURL)
 			require.NotEmpty(t, staleShortURL.Uid)
@@ -81,6 +85,105 @@ func TestShortURLService(t *testing.T) {
 		})
 	})
 
+	t.Run("User can create short URLs with a slug", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+		ctx := context.Background()
+
+		shortURL, err := service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{Path: "d/runbook", Slug: "oncall-runbook"})
+		require.NoError(t, err)
+		require.Equal(t, "oncall-runbook", shortURL.Uid)
+
+		_, err = service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{Path: "d/other", Slug: "oncall-runbook"})
+		require.True(t, shorturls.ErrShortURLSlugTaken.Is(err))
+
+		_, err = service.CreateShortURL(ctx, otherOrgUser, &shorturls.CreateShortURLCommand{Path: "d/other", Slug: "oncall-runbook"})
+		require.NoError(t, err)
+
+		_, err = service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{Path: "d/other", Slug: "on call"})
+		require.True(t, shorturls.ErrShortURLInvalidSlug.Is(err))
+	})
+
+	t.Run("Expired short URLs cannot be looked up and are deleted", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+		ctx := context.Background()
+
+		_, err := service.CreateShortURL(ctx, expiringUser, &shorturls.CreateShortURLCommand{Path: "d/x", ExpiresIn: -time.Hour})
+		require.True(t, shorturls.ErrShortURLInvalidTTL.Is(err))
+
+		shortURL, err := service.CreateShortURL(ctx, expiringUser, &shorturls.CreateShortURLCommand{Path: "d/x", ExpiresIn: time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, shortURL.CreatedAt+3600, shortURL.ExpiresAt)
+
+		_, err = service.GetShortURLByUID(ctx, expiringUser, shortURL.Uid)
+		require.NoError(t, err)
+
+		// links with an expiry are not deleted by the age-based cleanup
+		cmd := shorturls.DeleteShortUrlCommand{OlderThan: time.Unix(shortURL.CreatedAt, 0), ExpiredBefore: time.Unix(shortURL.CreatedAt, 0)}
+		require.NoError(t, service.DeleteStaleShortURLs(ctx, &cmd))
+		_, err = service.GetShortURLByUID(ctx, expiringUser, shortURL.Uid)
+		require.NoError(t, err)
+
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+		later := time.Unix(shortURL.ExpiresAt, 0)
+		getTime = func() time.Time {
+			return later
+		}
+
+		_, err = service.GetShortURLByUID(ctx, expiringUser, shortURL.Uid)
+		require.True(t, shorturls.ErrShortURLNotFound.Is(err))
+
+		cmd = shorturls.DeleteShortUrlCommand{ExpiredBefore: later}
+		require.NoError(t, service.DeleteStaleShortURLs(ctx, &cmd))
+		require.Equal(t, int64(1), cmd.NumDeleted)
+	})
+
+	t.Run("Admins can search and delete short URLs", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+		ctx := context.Background()
+
+		for _, slug := range []string{"runbook-db", "runbook-api", "dashboard-home"} {
+			_, err := service.CreateShortURL(ctx, adminUser, &shorturls.CreateShortURLCommand{Path: "d/" + slug, Slug: slug})
+			require.NoError(t, err)
+		}
+
+		result, err := service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{OrgID: 4})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.ShortURLs, 3)
+
+		result, err = service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{OrgID: 4, Query: "runbook", Limit: 1, Page: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+		require.Len(t, result.ShortURLs, 1)
+
+		// wildcards in the query are matched literally
+		for _, q := range []string{"_", "%", "runbook_db", "!"} {
+			result, err = service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{OrgID: 4, Query: q})
+			require.NoError(t, err)
+			require.Equal(t, int64(0), result.TotalCount, q)
+		}
+
+		require.NoError(t, service.DeleteShortURL(ctx, adminUser, "runbook-db"))
+		_, err = service.GetShortURLByUID(ctx, adminUser, "runbook-db")
+		require.True(t, shorturls.ErrShortURLNotFound.Is(err))
+
+		err = service.DeleteShortURL(ctx, adminUser, "runbook-db")
+		require.True(t, shorturls.ErrShortURLNotFound.Is(err))
+	})
+
+	t.Run("Inserting a taken slug returns a conflict", func(t *testing.T) {
+		store := &sqlStore{db: store}
+		ctx := context.Background()
+
+		require.NoError(t, store.Insert(ctx, &shorturls.ShortUrl{OrgId: 5, Uid: "taken", Path: "d/a"}))
+		// bypasses the slug check of the service, like a concurrent request would
+		err := store.Insert(ctx, &shorturls.ShortUrl{OrgId: 5, Uid: "taken", Path: "d/b"})
+		require.True(t, shorturls.ErrShortURLSlugTaken.Is(err))
+	})
+
 	t.Run("User cannot look up nonexistent short URLs", func(t *testing.T) {
 		service := ShortURLService{SQLStore: &sqlStore{db: store}}
 
@@ -2,16 +2,25 @@ package shorturlimpl
 
 import (
 	"context"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// likeEscapeChar escapes the wildcards of the search query. It is not a backslash because
+// MySQL and Postgres do not agree on how a backslash is written in a string literal.
+const likeEscapeChar = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscapeChar, likeEscapeChar+likeEscapeChar, "%", likeEscapeChar+"%", "_", likeEscapeChar+"_")
+
 type store interface {
 	Get(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error)
 	Update(ctx context.Context, shortURL *shorturls.ShortUrl) error
 	Insert(ctx context.Context, shortURL *shorturls.ShortUrl) error
+	Search(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error)
+	DeleteByUID(ctx context.Context, orgID int64, uid string) error
 	Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error
 }
 
@@ -39,13 +48,16 @@ func (s sqlStore) Get(ctx context.Context, user *user.SignedInUser, uid string)
 	return &shortURL, nil
 }
 
+// Update records a visit of the short URL.
 func (s sqlStore) Update(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	shortURL.LastSeenAt = getTime().Unix()
 	return s.db.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
-		_, err := dbSession.ID(shortURL.Id).Update(shortURL)
+		// the hits are incremented in the database, so concurrent visits are all counted
+		_, err := dbSession.Exec("UPDATE short_url SET last_seen_at = ?, hits = hits + 1 WHERE id = ?", shortURL.LastSeenAt, shortURL.Id)
 		if err != nil {
 			return err
 		}
+		shortURL.Hits++
 		return nil
 	})
 }
@@ -53,15 +65,69 @@ func (s sqlStore) Update(ctx context.Context, shortURL *shorturls.ShortUrl) erro
 func (s sqlStore) Insert(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	return s.db.WithDbSession(ctx, func(session *db.Session) error {
 		_, err := session.Insert(shortURL)
+		if err != nil && s.db.GetDialect().IsUniqueConstraintViolation(err) {
+			// the slug was taken concurrently, after it was checked
+			return shorturls.ErrShortURLSlugTaken.Errorf("slug already exists: %s", shortURL.Uid)
+		}
 		return err
 	})
 }
 
+func (s sqlStore) Search(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	result := &shorturls.SearchShortURLsResult{
+		ShortURLs: make([]*shorturls.ShortUrl, 0),
+		Page:      query.Page,
+		PerPage:   query.Limit,
+	}
+	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		whereSQL := "org_id = ?"
+		whereParams := []any{query.OrgID}
+		if query.Query != "" {
+			like := s.db.GetDialect().LikeStr() + " ? ESCAPE '" + likeEscapeChar + "'"
+			pattern := "%" + likeEscaper.Replace(query.Query) + "%"
+			whereSQL += " AND (uid " + like + " OR path " + like + ")"
+			whereParams = append(whereParams, pattern, pattern)
+		}
+
+		count, err := dbSession.Table("short_url").Where(whereSQL, whereParams...).Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		offset := query.Limit * (query.Page - 1)
+		return dbSession.Table("short_url").Where(whereSQL, whereParams...).
+			Desc("created_at").Limit(query.Limit, offset).Find(&result.ShortURLs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s sqlStore) DeleteByUID(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(session *db.Session) error {
+		result, err := session.Exec("DELETE FROM short_url WHERE org_id = ? AND uid = ?", orgID, uid)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return shorturls.ErrShortURLNotFound.Errorf("short URL not found")
+		}
+		return nil
+	})
+}
+
 func (s sqlStore) Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
-		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)"
+		// short URLs with an expiry are kept until they expire, even if they were never visited
+		var rawSql = "DELETE FROM short_url WHERE (expires_at = 0 AND created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)) OR (expires_at > 0 AND expires_at <= ?)"
 
-		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix()); err != nil {
+		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix(), cmd.ExpiredBefore.Unix()); err != nil {
 			return err
 		} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
 			return err
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardLintMigrations(mg *Migrator) {
+	dashboardLintRuleV1 := Table{
+		Name: "dashboard_lint_rule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "rule", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "severity", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "settings", Type: DB_Text, Nullable: true},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "rule"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_lint_rule table v1", NewAddTableMigration(dashboardLintRuleV1))
+	addTableIndicesMigrations(mg, "v1", dashboardLintRuleV1)
+}
@@ -244,4 +244,8 @@ func addDashboardMigration(mg *Migrator) {
 		Cols: []string{"deleted"},
 		Type: IndexType,
 	}))
+
+	mg.AddMigration("Add commit_sha column to dashboard_provisioning", NewAddColumnMigration(dashboardExtrasTableV2, &Column{
+		Name: "commit_sha", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
 }
@@ -71,4 +71,12 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	mg.AddMigration("Add refresh_interval column to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "refresh_interval", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("Add next_refresh column to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "next_refresh", Type: DB_DateTime, Nullable: true,
+	}))
 }
@@ -83,4 +83,33 @@ func addLibraryElementsMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("populate library_element folder_uid", migrator.NewRawSQLMigration(q))
 
 	mg.AddMigration("add index library_element org_id-folder_uid-name-kind", migrator.NewAddIndexMigration(libraryElementsV1, &migrator.Index{Cols: []string{"org_id", "folder_uid", "name", "kind"}, Type: migrator.UniqueIndex}))
+
+	libraryElementVersionV1 := migrator.Table{
+		Name: model.LibraryElementVersionTableName,
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "element_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 150, Nullable: false},
+			{Name: "model", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"element_id", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create "+model.LibraryElementVersionTableName+" table v1", migrator.NewAddTableMigration(libraryElementVersionV1))
+	mg.AddMigration("add index "+model.LibraryElementVersionTableName+" element_id-version", migrator.NewAddIndexMigration(libraryElementVersionV1, libraryElementVersionV1.Indices[0]))
+	mg.AddMigration("alter "+model.LibraryElementVersionTableName+" model to mediumtext", migrator.NewRawSQLMigration("").
+		Mysql("ALTER TABLE "+model.LibraryElementVersionTableName+" MODIFY model MEDIUMTEXT NOT NULL;"))
+	mg.AddMigration("populate "+model.LibraryElementVersionTableName+" with current versions", migrator.NewRawSQLMigration(
+		"INSERT INTO "+model.LibraryElementVersionTableName+" (org_id, element_id, version, name, model, created, created_by)"+
+			" SELECT org_id, id, version, name, model, updated, updated_by FROM library_element"))
+
+	mg.AddMigration("add "+model.LibraryElementConnectionTableName+" version", migrator.NewAddColumnMigration(libraryElementConnectionV1, &migrator.Column{
+		Name: "version", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
@@ -141,4 +141,16 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	externalsession.AddMigration(mg)
 
 	accesscontrol.AddReceiverCreateScopeMigration(mg)
+
+	addReportMigrations(mg)
+
+	addPlaylistScheduleMigrations(mg)
+
+	addDashboardLintMigrations(mg)
+
+	addMFAMigrations(mg)
+
+	addWebAuthnMigrations(mg)
+
+	addSCIMMigrations(mg)
 }
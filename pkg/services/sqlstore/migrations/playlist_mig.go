@@ -60,6 +60,32 @@ func addPlaylistUIDMigration(mg *Migrator) {
 	}))
 }
 
+func addPlaylistScheduleMigrations(mg *Migrator) {
+	mg.AddMigration("Add playlist_item column interval", NewAddColumnMigration(Table{Name: "playlist_item"}, &Column{
+		Name: "interval", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''",
+	}))
+
+	playlistScheduleV1 := Table{
+		Name: "playlist_schedule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "playlist_id", Type: DB_BigInt, Nullable: false},
+			{Name: "order", Type: DB_Int, Nullable: false},
+			{Name: "weekdays", Type: DB_Text, Nullable: true},
+			{Name: "start_time", Type: DB_NVarchar, Length: 5, Nullable: false},
+			{Name: "end_time", Type: DB_NVarchar, Length: 5, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''"},
+			{Name: "playlist_uid", Type: DB_NVarchar, Length: 80, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"playlist_id"}},
+		},
+	}
+
+	mg.AddMigration("create playlist schedule table", NewAddTableMigration(playlistScheduleV1))
+	mg.AddMigration("add index playlist_schedule.playlist_id", NewAddIndexMigration(playlistScheduleV1, playlistScheduleV1.Indices[0]))
+}
+
 func playlistV2() Table {
 	return Table{
 		Name: "playlist",
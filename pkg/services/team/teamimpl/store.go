@@ -139,6 +139,7 @@ func (ss *xormStore) Delete(ctx context.Context, cmd *team.DeleteTeamCommand) er
 			"DELETE FROM team_member WHERE org_id=? and team_id = ?",
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
+			"DELETE FROM scim_resource WHERE org_id=? and resource_type = 'Group' and resource_id = ?",
 		}
 
 		deletes = append(deletes, ss.deletes...)
@@ -0,0 +1,179 @@
+package webauthn
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var (
+	ErrInvalidCredential  = errutil.Unauthorized("webauthn.invalid-credential", errutil.WithPublicMessage("Invalid security key or passkey"))
+	ErrSessionNotFound    = errutil.Unauthorized("webauthn.session-not-found", errutil.WithPublicMessage("The ceremony is invalid or expired, try again"))
+	ErrCredentialNotFound = errutil.NotFound("webauthn.credential-not-found", errutil.WithPublicMessage("Credential not found"))
+	ErrCredentialExists   = errutil.Conflict("webauthn.credential-exists", errutil.WithPublicMessage("The security key or passkey is already registered"))
+	ErrBadRequest         = errutil.BadRequest("webauthn.bad-request")
+)
+
+// Service implements the WebAuthn relying party: the registration ceremony that adds credentials to users and
+// the authentication ceremony that verifies assertions of these credentials.
+type Service interface {
+	// BeginRegistration returns the options passed to navigator.credentials.create() to register a new credential.
+	BeginRegistration(ctx context.Context, usr *user.User) (*CredentialCreationOptions, error)
+	// FinishRegistration verifies the response of the authenticator and stores the credential.
+	FinishRegistration(ctx context.Context, userID int64, name string, response *RegistrationResponse) (*CredentialDTO, error)
+	// BeginLogin returns the options passed to navigator.credentials.get(). When userID is 0, any discoverable
+	// credential can be used. The MFA challenge, if any, is returned by FinishLogin.
+	BeginLogin(ctx context.Context, userID int64, mfaChallenge string) (*CredentialRequestOptions, error)
+	// FinishLogin verifies the assertion of the authenticator and returns the user of the credential.
+	FinishLogin(ctx context.Context, response *AssertionResponse) (*LoginResult, error)
+	HasCredentials(ctx context.Context, userID int64) (bool, error)
+	ListCredentials(ctx context.Context, userID int64) ([]*CredentialDTO, error)
+	// DeleteCredential revokes a credential of the user.
+	DeleteCredential(ctx context.Context, userID, id int64) error
+}
+
+// Credential is a public key credential registered by a user.
+type Credential struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	UserID       int64  `xorm:"user_id"`
+	Name         string `xorm:"name"`
+	CredentialID string `xorm:"credential_id"`
+	// CredentialIDHash is used to look up credentials, as credential ids can be too long to be indexed.
+	CredentialIDHash string `xorm:"credential_id_hash"`
+	// PublicKey is the COSE encoded public key of the credential.
+	PublicKey  string     `xorm:"public_key"`
+	SignCount  int64      `xorm:"sign_count"`
+	AAGUID     string     `xorm:"aaguid"`
+	Transports string     `xorm:"transports"`
+	Created    time.Time  `xorm:"created"`
+	LastUsed   *time.Time `xorm:"last_used"`
+}
+
+func (Credential) TableName() string {
+	return "webauthn_credential"
+}
+
+type CredentialDTO struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	AAGUID     string     `json:"aaguid"`
+	Transports []string   `json:"transports"`
+	Created    time.Time  `json:"created"`
+	LastUsed   *time.Time `json:"lastUsed"`
+}
+
+type LoginResult struct {
+	UserID       int64
+	MFAChallenge string
+}
+
+// URLEncodedBase64 is a byte slice encoded in JSON as an unpadded base64url string, as in the JSON
+// serialization of WebAuthn credentials.
+type URLEncodedBase64 []byte
+
+func (e URLEncodedBase64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(e))
+}
+
+func (e *URLEncodedBase64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*e = decoded
+	return nil
+}
+
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          URLEncodedBase64 `json:"id"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string           `json:"type"`
+	ID         URLEncodedBase64 `json:"id"`
+	Transports []string         `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+type CredentialCreationOptions struct {
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Challenge              URLEncodedBase64       `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+type CredentialRequestOptions struct {
+	Challenge        URLEncodedBase64       `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse is the JSON serialization of the PublicKeyCredential returned by navigator.credentials.create().
+type RegistrationResponse struct {
+	ID       string                           `json:"id"`
+	RawID    URLEncodedBase64                 `json:"rawId"`
+	Type     string                           `json:"type"`
+	Response AuthenticatorAttestationResponse `json:"response"`
+}
+
+type AuthenticatorAttestationResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AttestationObject URLEncodedBase64 `json:"attestationObject"`
+	Transports        []string         `json:"transports"`
+}
+
+// AssertionResponse is the JSON serialization of the PublicKeyCredential returned by navigator.credentials.get().
+type AssertionResponse struct {
+	ID       string                         `json:"id"`
+	RawID    URLEncodedBase64               `json:"rawId"`
+	Type     string                         `json:"type"`
+	Response AuthenticatorAssertionResponse `json:"response"`
+}
+
+type AuthenticatorAssertionResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBase64 `json:"authenticatorData"`
+	Signature         URLEncodedBase64 `json:"signature"`
+	UserHandle        URLEncodedBase64 `json:"userHandle"`
+}
+
+type FinishRegistrationCommand struct {
+	Name       string                `json:"name"`
+	Credential *RegistrationResponse `json:"credential" binding:"Required"`
+}
+
+type BeginLoginCommand struct {
+	// MFAChallenge is the challenge of a login that requires a second factor.
+	MFAChallenge string `json:"mfaChallenge"`
+}
@@ -0,0 +1,154 @@
+package webauthnimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/user/webauthn", func(entities routing.RouteRegister) {
+		entities.Post("/register/begin", routing.Wrap(s.beginRegistrationHandler))
+		entities.Post("/register/finish", routing.Wrap(s.finishRegistrationHandler))
+		entities.Get("/credentials", routing.Wrap(s.listCredentialsHandler))
+		entities.Delete("/credentials/:credentialId", routing.Wrap(s.deleteCredentialHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	s.routeRegister.Group("/api/admin/users/:id/webauthn/credentials", func(entities routing.RouteRegister) {
+		entities.Get("/", routing.Wrap(s.adminListCredentialsHandler))
+		entities.Delete("/:credentialId", routing.Wrap(s.adminDeleteCredentialHandler))
+	}, middleware.ReqGrafanaAdmin)
+
+	// the login is completed by the webauthn authn client
+	s.routeRegister.Post("/api/login/webauthn/begin", routing.Wrap(s.beginLoginHandler))
+}
+
+func (s *Service) beginRegistrationHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	usr, err := s.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	options, err := s.BeginRegistration(c.Req.Context(), usr)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin registration", err)
+	}
+	return response.JSON(http.StatusOK, map[string]any{"publicKey": options})
+}
+
+func (s *Service) finishRegistrationHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	cmd := webauthn.FinishRegistrationCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(webauthn.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	credential, err := s.FinishRegistration(c.Req.Context(), userID, cmd.Name, cmd.Credential)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to register credential", err)
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+func (s *Service) listCredentialsHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	credentials, err := s.ListCredentials(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list credentials", err)
+	}
+	return response.JSON(http.StatusOK, credentials)
+}
+
+func (s *Service) deleteCredentialHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := signedInUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	return s.deleteCredential(c, userID)
+}
+
+func (s *Service) adminListCredentialsHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Err(webauthn.ErrBadRequest.Errorf("id is invalid: %w", err))
+	}
+
+	credentials, err := s.ListCredentials(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list credentials", err)
+	}
+	return response.JSON(http.StatusOK, credentials)
+}
+
+func (s *Service) adminDeleteCredentialHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Err(webauthn.ErrBadRequest.Errorf("id is invalid: %w", err))
+	}
+	return s.deleteCredential(c, userID)
+}
+
+func (s *Service) deleteCredential(c *contextmodel.ReqContext, userID int64) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":credentialId"], 10, 64)
+	if err != nil {
+		return response.Err(webauthn.ErrBadRequest.Errorf("credentialId is invalid: %w", err))
+	}
+
+	if err := s.DeleteCredential(c.Req.Context(), userID, id); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete credential", err)
+	}
+	return response.Success("Credential deleted")
+}
+
+func (s *Service) beginLoginHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := webauthn.BeginLoginCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(webauthn.ErrBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	// the credentials of a second factor are restricted to the user who entered their password
+	var userID int64
+	if cmd.MFAChallenge != "" {
+		challenge, err := s.mfaService.GetChallenge(c.Req.Context(), cmd.MFAChallenge)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin login", err)
+		}
+		userID = challenge.UserID
+	}
+
+	options, err := s.BeginLogin(c.Req.Context(), userID, cmd.MFAChallenge)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin login", err)
+	}
+	return response.JSON(http.StatusOK, map[string]any{"publicKey": options})
+}
+
+func signedInUserID(c *contextmodel.ReqContext) (int64, response.Response) {
+	if !c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		return 0, response.Error(http.StatusForbidden, "Security keys are only available to users", nil)
+	}
+	userID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get user id", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,98 @@
+package webauthnimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+type store interface {
+	Create(ctx context.Context, credential *webauthn.Credential) error
+	GetByCredentialIDHash(ctx context.Context, hash string) (*webauthn.Credential, bool, error)
+	List(ctx context.Context, userID int64) ([]*webauthn.Credential, error)
+	Count(ctx context.Context, userID int64) (int64, error)
+	// UpdateUsage sets the sign count and the last use of the credential if its sign count was not changed since
+	// it was read.
+	UpdateUsage(ctx context.Context, id int64, previousSignCount, signCount int64, lastUsed time.Time) (bool, error)
+	Delete(ctx context.Context, userID, id int64) (bool, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+var _ store = &sqlStore{}
+
+func (s *sqlStore) Create(ctx context.Context, credential *webauthn.Credential) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("credential_id_hash = ?", credential.CredentialIDHash).Exist(&webauthn.Credential{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return webauthn.ErrCredentialExists.Errorf("credential is already registered")
+		}
+		_, err = sess.Insert(credential)
+		return err
+	})
+}
+
+func (s *sqlStore) GetByCredentialIDHash(ctx context.Context, hash string) (*webauthn.Credential, bool, error) {
+	credential := &webauthn.Credential{}
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("credential_id_hash = ?", hash).Get(credential)
+		return err
+	})
+	return credential, has, err
+}
+
+func (s *sqlStore) List(ctx context.Context, userID int64) ([]*webauthn.Credential, error) {
+	credentials := make([]*webauthn.Credential, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Asc("id").Find(&credentials)
+	})
+	return credentials, err
+}
+
+func (s *sqlStore) Count(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("user_id = ?", userID).Count(&webauthn.Credential{})
+		return err
+	})
+	return count, err
+}
+
+func (s *sqlStore) UpdateUsage(ctx context.Context, id int64, previousSignCount, signCount int64, lastUsed time.Time) (bool, error) {
+	var updated bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec("UPDATE webauthn_credential SET sign_count = ?, last_used = ? WHERE id = ? AND sign_count = ?",
+			signCount, lastUsed, id, previousSignCount)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		updated = rows > 0
+		return err
+	})
+	return updated, err
+}
+
+func (s *sqlStore) Delete(ctx context.Context, userID, id int64) (bool, error) {
+	var deleted bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec("DELETE FROM webauthn_credential WHERE user_id = ? AND id = ?", userID, id)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		deleted = rows > 0
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,221 @@
+package webauthnimpl
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+// COSE algorithms supported for credential public keys.
+const (
+	algES256 int64 = -7
+	algEdDSA int64 = -8
+	algRS256 int64 = -257
+)
+
+// COSE key types and curves.
+const (
+	ktyOKP     int64 = 1
+	ktyEC2     int64 = 2
+	ktyRSA     int64 = 3
+	crvP256    int64 = 1
+	crvEd25519 int64 = 6
+)
+
+// Flags of the authenticator data.
+const (
+	flagUserPresent            byte = 0x01
+	flagUserVerified           byte = 0x04
+	flagAttestedCredentialData byte = 0x40
+)
+
+const (
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+)
+
+type clientData struct {
+	Type      string                    `json:"type"`
+	Challenge webauthn.URLEncodedBase64 `json:"challenge"`
+	Origin    string                    `json:"origin"`
+}
+
+type attestationObject struct {
+	Fmt      string          `cbor:"fmt"`
+	AttStmt  cbor.RawMessage `cbor:"attStmt"`
+	AuthData []byte          `cbor:"authData"`
+}
+
+type authenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	// the attested credential data is only set during the registration
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func parseClientData(data []byte, expectedType string) (*clientData, error) {
+	cd := &clientData{}
+	if err := json.Unmarshal(data, cd); err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("failed to parse client data: %w", err)
+	}
+	if cd.Type != expectedType {
+		return nil, webauthn.ErrInvalidCredential.Errorf("expected client data of type %s but got %s", expectedType, cd.Type)
+	}
+	return cd, nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, webauthn.ErrInvalidCredential.Errorf("authenticator data is too short")
+	}
+
+	ad := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if ad.Flags&flagAttestedCredentialData == 0 {
+		return ad, nil
+	}
+
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, webauthn.ErrInvalidCredential.Errorf("attested credential data is too short")
+	}
+	ad.AAGUID = rest[:16]
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLength {
+		return nil, webauthn.ErrInvalidCredential.Errorf("credential id is too short")
+	}
+	ad.CredentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	// the public key is followed by the extensions, if any
+	var key cbor.RawMessage
+	extensions, err := cbor.UnmarshalFirst(rest, &key)
+	if err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("failed to parse credential public key: %w", err)
+	}
+	ad.PublicKey = rest[:len(rest)-len(extensions)]
+	return ad, nil
+}
+
+// verify checks the relying party and the flags of the authenticator data.
+func (ad *authenticatorData) verify(rpID string, requireUserVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(ad.RPIDHash, rpIDHash[:]) {
+		return webauthn.ErrInvalidCredential.Errorf("authenticator data is for another relying party")
+	}
+	if ad.Flags&flagUserPresent == 0 {
+		return webauthn.ErrInvalidCredential.Errorf("user was not present")
+	}
+	if requireUserVerification && ad.Flags&flagUserVerified == 0 {
+		return webauthn.ErrInvalidCredential.Errorf("user was not verified")
+	}
+	return nil
+}
+
+func formatAAGUID(aaguid []byte) string {
+	if len(aaguid) != 16 {
+		return ""
+	}
+	s := hex.EncodeToString(aaguid)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// parsePublicKey returns the public key and the algorithm of a COSE encoded key.
+func parsePublicKey(data []byte) (crypto.PublicKey, int64, error) {
+	key := map[int64]any{}
+	if err := cbor.Unmarshal(data, &key); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	kty, _ := coseInt(key[1])
+	alg, _ := coseInt(key[3])
+	switch {
+	case kty == ktyEC2 && alg == algES256:
+		crv, _ := coseInt(key[-1])
+		x, xOK := key[-2].([]byte)
+		y, yOK := key[-3].([]byte)
+		if crv != crvP256 || !xOK || !yOK {
+			return nil, 0, fmt.Errorf("invalid EC2 public key")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, 0, fmt.Errorf("public key is not on the P-256 curve")
+		}
+		return pub, alg, nil
+	case kty == ktyRSA && alg == algRS256:
+		n, nOK := key[-1].([]byte)
+		e, eOK := key[-2].([]byte)
+		if !nOK || !eOK {
+			return nil, 0, fmt.Errorf("invalid RSA public key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, alg, nil
+	case kty == ktyOKP && alg == algEdDSA:
+		crv, _ := coseInt(key[-1])
+		x, ok := key[-2].([]byte)
+		if crv != crvEd25519 || !ok || len(x) != ed25519.PublicKeySize {
+			return nil, 0, fmt.Errorf("invalid OKP public key")
+		}
+		return ed25519.PublicKey(x), alg, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported public key type %d with algorithm %d", kty, alg)
+	}
+}
+
+func coseInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// verifySignature checks the signature of the authenticator data and of the hash of the client data.
+func verifySignature(publicKey []byte, authData, clientDataJSON, signature []byte) error {
+	pub, alg, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authData...), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+
+	switch alg {
+	case algES256:
+		if !ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], signature) {
+			return fmt.Errorf("invalid signature")
+		}
+	case algRS256:
+		if err := rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+	case algEdDSA:
+		if !ed25519.Verify(pub.(ed25519.PublicKey), signed, signature) {
+			return fmt.Errorf("invalid signature")
+		}
+	}
+	return nil
+}
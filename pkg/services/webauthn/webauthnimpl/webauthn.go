@@ -0,0 +1,499 @@
+package webauthnimpl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	sessionKeyPrefix       = "webauthn-session-%s"
+	usedChallengeKeyPrefix = "webauthn-used-challenge-%s"
+	challengeSize          = 32
+	// a signed challenge is its expiry in unix seconds, a random nonce and the HMAC of both
+	signedChallengeNonceSize = 16
+	signedChallengeSize      = 8 + signedChallengeNonceSize + sha256.Size
+
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+
+	userVerificationRequired = "required"
+)
+
+// session is the state of a ceremony between its beginning and its end, stored in the remote cache under the
+// challenge sent to the authenticator.
+type session struct {
+	Ceremony     string `json:"ceremony"`
+	UserID       int64  `json:"userId"`
+	MFAChallenge string `json:"mfaChallenge,omitempty"`
+	// UserVerification is the user verification requested for the ceremony
+	UserVerification string `json:"userVerification"`
+	// signedChallengeExpiry is set for usernameless logins, whose challenge is signed instead of stored
+	signedChallengeExpiry time.Time
+}
+
+type Service struct {
+	store         store
+	cfg           *setting.Cfg
+	cache         remotecache.CacheStorage
+	userService   user.Service
+	mfaService    mfa.Service
+	routeRegister routing.RouteRegister
+	rpID          string
+	origins       []string
+	now           func() time.Time
+	log           log.Logger
+}
+
+var _ webauthn.Service = &Service{}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, cache *remotecache.RemoteCache, userService user.Service,
+	mfaService mfa.Service, routeRegister routing.RouteRegister) (*Service, error) {
+	s := &Service{
+		store:         &sqlStore{db: db},
+		cfg:           cfg,
+		cache:         cache,
+		userService:   userService,
+		mfaService:    mfaService,
+		routeRegister: routeRegister,
+		rpID:          cfg.WebAuthn.RPID,
+		origins:       cfg.WebAuthn.Origins,
+		now:           time.Now,
+		log:           log.New("webauthn"),
+	}
+
+	if !cfg.WebAuthn.Enabled {
+		return s, nil
+	}
+
+	// credentials are bound to the domain and origin Grafana is served from by default
+	if s.rpID == "" || len(s.origins) == 0 {
+		appURL, err := url.Parse(cfg.AppURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse root_url for webauthn: %w", err)
+		}
+		if s.rpID == "" {
+			s.rpID = appURL.Hostname()
+		}
+		if len(s.origins) == 0 {
+			s.origins = []string{appURL.Scheme + "://" + appURL.Host}
+		}
+	}
+
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) BeginRegistration(ctx context.Context, usr *user.User) (*webauthn.CredentialCreationOptions, error) {
+	credentials, err := s.store.List(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.createSession(ctx, &session{
+		Ceremony:         ceremonyRegistration,
+		UserID:           usr.ID,
+		UserVerification: s.cfg.WebAuthn.UserVerification,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := usr.Name
+	if displayName == "" {
+		displayName = usr.Login
+	}
+	return &webauthn.CredentialCreationOptions{
+		RP:   webauthn.RelyingParty{ID: s.rpID, Name: s.cfg.WebAuthn.RPName},
+		User: webauthn.UserEntity{ID: []byte(usr.UID), Name: usr.Login, DisplayName: displayName},
+		PubKeyCredParams: []webauthn.CredentialParameter{
+			{Type: "public-key", Alg: algES256},
+			{Type: "public-key", Alg: algEdDSA},
+			{Type: "public-key", Alg: algRS256},
+		},
+		Challenge:          challenge,
+		Timeout:            s.cfg.WebAuthn.Timeout.Milliseconds(),
+		ExcludeCredentials: descriptors(credentials),
+		AuthenticatorSelection: webauthn.AuthenticatorSelection{
+			// discoverable credentials can be used without entering a username
+			ResidentKey:      "preferred",
+			UserVerification: s.cfg.WebAuthn.UserVerification,
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration implements webauthn.Service. As the attestation "none" is requested, the attestation
+// statement is not verified, the credential is trusted because the signed-in user registers it.
+func (s *Service) FinishRegistration(ctx context.Context, userID int64, name string, response *webauthn.RegistrationResponse) (*webauthn.CredentialDTO, error) {
+	if response == nil {
+		return nil, webauthn.ErrBadRequest.Errorf("missing credential")
+	}
+
+	cd, err := parseClientData(response.Response.ClientDataJSON, clientDataTypeCreate)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.consumeSession(ctx, cd, ceremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if sess.UserID != userID {
+		return nil, webauthn.ErrSessionNotFound.Errorf("registration was started by another user")
+	}
+
+	attestation := attestationObject{}
+	if err := cbor.Unmarshal(response.Response.AttestationObject, &attestation); err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("failed to parse attestation object: %w", err)
+	}
+	authData, err := parseAuthenticatorData(attestation.AuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.verify(s.rpID, sess.UserVerification == userVerificationRequired); err != nil {
+		return nil, err
+	}
+	if len(authData.CredentialID) == 0 {
+		return nil, webauthn.ErrInvalidCredential.Errorf("attestation has no credential data")
+	}
+	if _, _, err := parsePublicKey(authData.PublicKey); err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("unsupported credential: %w", err)
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(authData.CredentialID)
+	if name == "" {
+		name = "Security key"
+	}
+	credential := &webauthn.Credential{
+		UserID:           userID,
+		Name:             name,
+		CredentialID:     credentialID,
+		CredentialIDHash: hashCredentialID(credentialID),
+		PublicKey:        base64.StdEncoding.EncodeToString(authData.PublicKey),
+		SignCount:        int64(authData.SignCount),
+		AAGUID:           formatAAGUID(authData.AAGUID),
+		Transports:       strings.Join(response.Response.Transports, ","),
+		Created:          s.now(),
+	}
+	if err := s.store.Create(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Info("Registered WebAuthn credential", "userID", userID, "credentialID", credential.ID)
+	return credentialDTO(credential), nil
+}
+
+func (s *Service) BeginLogin(ctx context.Context, userID int64, mfaChallenge string) (*webauthn.CredentialRequestOptions, error) {
+	// passkeys used without a password have to verify the user, e.g. with a PIN or biometrics, to be a second factor
+	userVerification := s.cfg.WebAuthn.UserVerification
+	if mfaChallenge == "" {
+		userVerification = userVerificationRequired
+	}
+
+	allowCredentials := []webauthn.CredentialDescriptor{}
+	if userID != 0 {
+		credentials, err := s.store.List(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		allowCredentials = descriptors(credentials)
+	}
+
+	var challenge []byte
+	var err error
+	if userID == 0 && mfaChallenge == "" {
+		// anyone can begin a usernameless login, its challenge is signed so that beginning it stores nothing
+		challenge, err = s.createSignedChallenge()
+	} else {
+		challenge, err = s.createSession(ctx, &session{
+			Ceremony:         ceremonyLogin,
+			UserID:           userID,
+			MFAChallenge:     mfaChallenge,
+			UserVerification: userVerification,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.CredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          s.cfg.WebAuthn.Timeout.Milliseconds(),
+		RPID:             s.rpID,
+		AllowCredentials: allowCredentials,
+		UserVerification: userVerification,
+	}, nil
+}
+
+func (s *Service) FinishLogin(ctx context.Context, response *webauthn.AssertionResponse) (*webauthn.LoginResult, error) {
+	if response == nil {
+		return nil, webauthn.ErrBadRequest.Errorf("missing credential")
+	}
+
+	cd, err := parseClientData(response.Response.ClientDataJSON, clientDataTypeGet)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.loginSession(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(response.RawID)
+	credential, has, err := s.store.GetByCredentialIDHash(ctx, hashCredentialID(credentialID))
+	if err != nil {
+		return nil, err
+	}
+	if !has || credential.CredentialID != credentialID {
+		return nil, webauthn.ErrInvalidCredential.Errorf("credential is not registered")
+	}
+	if sess.UserID != 0 && sess.UserID != credential.UserID {
+		return nil, webauthn.ErrInvalidCredential.Errorf("credential belongs to another user")
+	}
+	if len(response.Response.UserHandle) > 0 {
+		usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: credential.UserID})
+		if err != nil {
+			return nil, err
+		}
+		if subtle.ConstantTimeCompare(response.Response.UserHandle, []byte(usr.UID)) != 1 {
+			return nil, webauthn.ErrInvalidCredential.Errorf("user handle does not match the user of the credential")
+		}
+	}
+
+	authData, err := parseAuthenticatorData(response.Response.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.verify(s.rpID, sess.UserVerification == userVerificationRequired); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(credential.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(publicKey, response.Response.AuthenticatorData, response.Response.ClientDataJSON, response.Response.Signature); err != nil {
+		return nil, webauthn.ErrInvalidCredential.Errorf("failed to verify assertion: %w", err)
+	}
+
+	// authenticators that count their signatures always increase the count, a lower count means the credential
+	// may have been cloned
+	signCount := int64(authData.SignCount)
+	if (signCount != 0 || credential.SignCount != 0) && signCount <= credential.SignCount {
+		s.log.FromContext(ctx).Warn("WebAuthn sign count did not increase, the credential may have been cloned", "userID", credential.UserID, "credentialID", credential.ID)
+		return nil, webauthn.ErrInvalidCredential.Errorf("sign count did not increase")
+	}
+	updated, err := s.store.UpdateUsage(ctx, credential.ID, credential.SignCount, signCount, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, webauthn.ErrInvalidCredential.Errorf("credential was used concurrently")
+	}
+	if !sess.signedChallengeExpiry.IsZero() {
+		if err := s.useSignedChallenge(ctx, cd.Challenge, sess.signedChallengeExpiry); err != nil {
+			return nil, err
+		}
+	}
+
+	return &webauthn.LoginResult{UserID: credential.UserID, MFAChallenge: sess.MFAChallenge}, nil
+}
+
+func (s *Service) HasCredentials(ctx context.Context, userID int64) (bool, error) {
+	count, err := s.store.Count(ctx, userID)
+	return count > 0, err
+}
+
+func (s *Service) ListCredentials(ctx context.Context, userID int64) ([]*webauthn.CredentialDTO, error) {
+	credentials, err := s.store.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*webauthn.CredentialDTO, 0, len(credentials))
+	for _, credential := range credentials {
+		result = append(result, credentialDTO(credential))
+	}
+	return result, nil
+}
+
+func (s *Service) DeleteCredential(ctx context.Context, userID, id int64) error {
+	deleted, err := s.store.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return webauthn.ErrCredentialNotFound.Errorf("credential %d of user %d not found", id, userID)
+	}
+	s.log.FromContext(ctx).Info("Revoked WebAuthn credential", "userID", userID, "credentialID", id)
+	return nil
+}
+
+// createSession stores the session of a ceremony and returns its challenge.
+func (s *Service) createSession(ctx context.Context, sess *session) ([]byte, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(sess)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf(sessionKeyPrefix, base64.RawURLEncoding.EncodeToString(challenge))
+	if err := s.cache.Set(ctx, key, value, s.cfg.WebAuthn.Timeout); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// consumeSession checks the origin of the client data and returns the session of its challenge. A session can
+// only be used once.
+func (s *Service) consumeSession(ctx context.Context, cd *clientData, ceremony string) (*session, error) {
+	if !s.isAllowedOrigin(cd.Origin) {
+		return nil, webauthn.ErrInvalidCredential.Errorf("origin %s is not allowed", cd.Origin)
+	}
+
+	key := fmt.Sprintf(sessionKeyPrefix, base64.RawURLEncoding.EncodeToString(cd.Challenge))
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, webauthn.ErrSessionNotFound.Errorf("session not found")
+		}
+		return nil, err
+	}
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+
+	sess := &session{}
+	if err := json.Unmarshal(value, sess); err != nil {
+		return nil, err
+	}
+	if sess.Ceremony != ceremony {
+		return nil, webauthn.ErrSessionNotFound.Errorf("expected a %s session but got %s", ceremony, sess.Ceremony)
+	}
+	return sess, nil
+}
+
+// loginSession returns the session of a login. The session of a usernameless login is not stored, its challenge is
+// verified instead and only stored as used once the assertion is verified.
+func (s *Service) loginSession(ctx context.Context, cd *clientData) (*session, error) {
+	if len(cd.Challenge) != signedChallengeSize {
+		return s.consumeSession(ctx, cd, ceremonyLogin)
+	}
+	if !s.isAllowedOrigin(cd.Origin) {
+		return nil, webauthn.ErrInvalidCredential.Errorf("origin %s is not allowed", cd.Origin)
+	}
+
+	expiry := time.Unix(int64(binary.BigEndian.Uint64(cd.Challenge[:8])), 0)
+	if !hmac.Equal(cd.Challenge[8+signedChallengeNonceSize:], s.signChallenge(cd.Challenge[:8+signedChallengeNonceSize])) {
+		return nil, webauthn.ErrSessionNotFound.Errorf("challenge signature is invalid")
+	}
+	if !s.now().Before(expiry) {
+		return nil, webauthn.ErrSessionNotFound.Errorf("challenge expired")
+	}
+	_, err := s.cache.Get(ctx, fmt.Sprintf(usedChallengeKeyPrefix, base64.RawURLEncoding.EncodeToString(cd.Challenge)))
+	if err == nil {
+		return nil, webauthn.ErrSessionNotFound.Errorf("challenge was already used")
+	}
+	if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil, err
+	}
+
+	return &session{
+		Ceremony:              ceremonyLogin,
+		UserVerification:      userVerificationRequired,
+		signedChallengeExpiry: expiry,
+	}, nil
+}
+
+// createSignedChallenge returns a challenge that expires after the WebAuthn timeout, signed with the secret key.
+func (s *Service) createSignedChallenge() ([]byte, error) {
+	challenge := make([]byte, 8+signedChallengeNonceSize, signedChallengeSize)
+	binary.BigEndian.PutUint64(challenge, uint64(s.now().Add(s.cfg.WebAuthn.Timeout).Unix()))
+	if _, err := rand.Read(challenge[8:]); err != nil {
+		return nil, err
+	}
+	return append(challenge, s.signChallenge(challenge)...), nil
+}
+
+func (s *Service) signChallenge(payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(s.cfg.SecretKey))
+	h.Write([]byte(ceremonyLogin))
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// useSignedChallenge stores the challenge of a verified assertion until it expires, so that it is only used once.
+func (s *Service) useSignedChallenge(ctx context.Context, challenge []byte, expiry time.Time) error {
+	key := fmt.Sprintf(usedChallengeKeyPrefix, base64.RawURLEncoding.EncodeToString(challenge))
+	// the cache stores items with a zero expiry for 24h, challenges that are about to expire are kept for a second
+	ttl := max(expiry.Sub(s.now()), time.Second)
+	return s.cache.Set(ctx, key, []byte{1}, ttl)
+}
+
+func (s *Service) isAllowedOrigin(origin string) bool {
+	for _, allowed := range s.origins {
+		if strings.TrimSuffix(allowed, "/") == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func hashCredentialID(credentialID string) string {
+	hash := sha256.Sum256([]byte(credentialID))
+	return base64.RawStdEncoding.EncodeToString(hash[:])
+}
+
+func descriptors(credentials []*webauthn.Credential) []webauthn.CredentialDescriptor {
+	result := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		id, err := base64.RawURLEncoding.DecodeString(credential.CredentialID)
+		if err != nil {
+			continue
+		}
+		result = append(result, webauthn.CredentialDescriptor{Type: "public-key", ID: id, Transports: splitTransports(credential.Transports)})
+	}
+	return result
+}
+
+func credentialDTO(credential *webauthn.Credential) *webauthn.CredentialDTO {
+	return &webauthn.CredentialDTO{
+		ID:         credential.ID,
+		Name:       credential.Name,
+		AAGUID:     credential.AAGUID,
+		Transports: splitTransports(credential.Transports),
+		Created:    credential.Created,
+		LastUsed:   credential.LastUsed,
+	}
+}
+
+func splitTransports(transports string) []string {
+	if transports == "" {
+		return []string{}
+	}
+	return strings.Split(transports, ",")
+}
@@ -0,0 +1,236 @@
+package webauthnimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/services/webauthn/webauthntest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationWebAuthn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	testDB := db.InitTestDB(t)
+	usr := &user.User{ID: 1, UID: "user-uid", Login: "admin"}
+	cfg := setting.NewCfg()
+	cfg.WebAuthn.RPName = "Grafana"
+	cfg.WebAuthn.UserVerification = "preferred"
+	cfg.WebAuthn.Timeout = 5 * time.Minute
+	cfg.SecretKey = "secret"
+	cache := remotecache.NewFakeCacheStorage()
+	s := &Service{
+		store:       &sqlStore{db: testDB},
+		cfg:         cfg,
+		cache:       cache,
+		userService: &usertest.FakeUserService{ExpectedUser: usr},
+		rpID:        "grafana.example.com",
+		origins:     []string{"https://grafana.example.com"},
+		now:         time.Now,
+		log:         log.NewNopLogger(),
+	}
+	ctx := context.Background()
+	authenticator := webauthntest.NewAuthenticator("grafana.example.com", "https://grafana.example.com")
+
+	var credential *webauthn.CredentialDTO
+	t.Run("registers a credential", func(t *testing.T) {
+		options, err := s.BeginRegistration(ctx, usr)
+		require.NoError(t, err)
+		require.Equal(t, "grafana.example.com", options.RP.ID)
+		require.Equal(t, []byte(usr.UID), []byte(options.User.ID))
+		require.Empty(t, options.ExcludeCredentials)
+
+		response, err := authenticator.Register(options)
+		require.NoError(t, err)
+		credential, err = s.FinishRegistration(ctx, usr.ID, "YubiKey", response)
+		require.NoError(t, err)
+		require.Equal(t, "YubiKey", credential.Name)
+		require.Equal(t, []string{"internal"}, credential.Transports)
+
+		has, err := s.HasCredentials(ctx, usr.ID)
+		require.NoError(t, err)
+		require.True(t, has)
+
+		// the registered credentials are excluded to not register an authenticator twice
+		options, err = s.BeginRegistration(ctx, usr)
+		require.NoError(t, err)
+		require.Len(t, options.ExcludeCredentials, 1)
+	})
+
+	t.Run("a registration can only be finished by the user who started it", func(t *testing.T) {
+		options, err := s.BeginRegistration(ctx, usr)
+		require.NoError(t, err)
+		response, err := webauthntest.NewAuthenticator("grafana.example.com", "https://grafana.example.com").Register(options)
+		require.NoError(t, err)
+		_, err = s.FinishRegistration(ctx, 2, "", response)
+		require.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+	})
+
+	t.Run("logs in with a discoverable credential", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		require.Empty(t, options.AllowCredentials)
+		require.Equal(t, userVerificationRequired, options.UserVerification)
+
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		result, err := s.FinishLogin(ctx, response)
+		require.NoError(t, err)
+		require.Equal(t, &webauthn.LoginResult{UserID: usr.ID}, result)
+
+		credentials, err := s.ListCredentials(ctx, usr.ID)
+		require.NoError(t, err)
+		require.Len(t, credentials, 1)
+		require.NotNil(t, credentials[0].LastUsed)
+	})
+
+	t.Run("a challenge can only be used once", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.NoError(t, err)
+
+		response, err = authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+	})
+
+	t.Run("beginning a login without a password stores nothing", func(t *testing.T) {
+		for key := range cache.Storage {
+			delete(cache.Storage, key)
+		}
+		for i := 0; i < 10; i++ {
+			_, err := s.BeginLogin(ctx, 0, "")
+			require.NoError(t, err)
+		}
+		require.Empty(t, cache.Storage)
+	})
+
+	t.Run("rejects challenges that were not signed by Grafana", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		options.Challenge[len(options.Challenge)-1] ^= 1
+
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+	})
+
+	t.Run("rejects expired challenges", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		s.now = func() time.Time { return time.Now().Add(cfg.WebAuthn.Timeout) }
+		defer func() { s.now = time.Now }()
+
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+	})
+
+	t.Run("rejects assertions of other origins", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		authenticator.Origin = "https://phishing.example.com"
+		defer func() { authenticator.Origin = "https://grafana.example.com" }()
+
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+	})
+
+	t.Run("requires user verification without a password", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		authenticator.UserVerified = false
+		defer func() { authenticator.UserVerified = true }()
+
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+	})
+
+	t.Run("rejects sign counts that did not increase", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.NoError(t, err)
+
+		// a cloned authenticator reuses the count of the last signature
+		options, err = s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		authenticator.CountSignatures = false
+		defer func() { authenticator.CountSignatures = true }()
+
+		response, err = authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+	})
+
+	t.Run("completes the login challenge of the user as a second factor", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, usr.ID, "mfa-challenge")
+		require.NoError(t, err)
+		require.Len(t, options.AllowCredentials, 1)
+		require.Equal(t, "preferred", options.UserVerification)
+
+		authenticator.UserVerified = false
+		defer func() { authenticator.UserVerified = true }()
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		result, err := s.FinishLogin(ctx, response)
+		require.NoError(t, err)
+		require.Equal(t, &webauthn.LoginResult{UserID: usr.ID, MFAChallenge: "mfa-challenge"}, result)
+	})
+
+	t.Run("rejects credentials of other users as a second factor", func(t *testing.T) {
+		options, err := s.BeginLogin(ctx, 2, "mfa-challenge")
+		require.NoError(t, err)
+		options.AllowCredentials = nil
+
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+	})
+
+	t.Run("revoked credentials can not log in", func(t *testing.T) {
+		require.ErrorIs(t, s.DeleteCredential(ctx, 2, credential.ID), webauthn.ErrCredentialNotFound)
+		require.NoError(t, s.DeleteCredential(ctx, usr.ID, credential.ID))
+
+		options, err := s.BeginLogin(ctx, 0, "")
+		require.NoError(t, err)
+		response, err := authenticator.Assert(options)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(ctx, response)
+		require.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+
+		has, err := s.HasCredentials(ctx, usr.ID)
+		require.NoError(t, err)
+		require.False(t, has)
+	})
+}
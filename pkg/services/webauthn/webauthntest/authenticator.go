@@ -0,0 +1,159 @@
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+// Authenticator is a software WebAuthn authenticator with ES256 credentials, used to test the registration and
+// authentication ceremonies without hardware.
+type Authenticator struct {
+	RPID   string
+	Origin string
+	// UserVerified sets the user verified flag of the authenticator data.
+	UserVerified bool
+	// CountSignatures increases the sign count of the credentials on each assertion, synced passkeys don't.
+	CountSignatures bool
+
+	credentials []*credential
+}
+
+type credential struct {
+	id         []byte
+	key        *ecdsa.PrivateKey
+	userHandle []byte
+	signCount  uint32
+}
+
+func NewAuthenticator(rpID, origin string) *Authenticator {
+	return &Authenticator{RPID: rpID, Origin: origin, UserVerified: true, CountSignatures: true}
+}
+
+// Register creates a credential like navigator.credentials.create().
+func (a *Authenticator) Register(options *webauthn.CredentialCreationOptions) (*webauthn.RegistrationResponse, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	cred := &credential{id: id, key: key, userHandle: options.User.ID}
+
+	publicKey, err := cbor.Marshal(map[int]any{
+		1:  2,  // EC2
+		3:  -7, // ES256
+		-1: 1,  // P-256
+		-2: key.X.FillBytes(make([]byte, 32)),
+		-3: key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authData := a.authenticatorData(0x40, 0)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(id)))
+	authData = append(authData, id...)
+	authData = append(authData, publicKey...)
+
+	attestation, err := cbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+	if err != nil {
+		return nil, err
+	}
+	clientData, err := a.clientData("webauthn.create", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	a.credentials = append(a.credentials, cred)
+	return &webauthn.RegistrationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(id),
+		RawID: id,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAttestationResponse{
+			ClientDataJSON:    clientData,
+			AttestationObject: attestation,
+			Transports:        []string{"internal"},
+		},
+	}, nil
+}
+
+// Assert signs the challenge with the first credential allowed by the options, like navigator.credentials.get().
+// When the options allow any credential, the first registered credential is used.
+func (a *Authenticator) Assert(options *webauthn.CredentialRequestOptions) (*webauthn.AssertionResponse, error) {
+	cred := a.findCredential(options.AllowCredentials)
+	if cred == nil {
+		return nil, fmt.Errorf("no credential allowed")
+	}
+	if a.CountSignatures {
+		cred.signCount++
+	}
+
+	authData := a.authenticatorData(0, cred.signCount)
+	clientData, err := a.clientData("webauthn.get", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(cred.id),
+		RawID: cred.id,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAssertionResponse{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        cred.userHandle,
+		},
+	}, nil
+}
+
+func (a *Authenticator) findCredential(allowed []webauthn.CredentialDescriptor) *credential {
+	for _, cred := range a.credentials {
+		if len(allowed) == 0 {
+			return cred
+		}
+		for _, descriptor := range allowed {
+			if string(descriptor.ID) == string(cred.id) {
+				return cred
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) authenticatorData(flags byte, signCount uint32) []byte {
+	flags |= 0x01 // user present
+	if a.UserVerified {
+		flags |= 0x04
+	}
+	rpIDHash := sha256.Sum256([]byte(a.RPID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, signCount)
+}
+
+func (a *Authenticator) clientData(typ string, challenge []byte) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":      typ,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.Origin,
+	})
+}
@@ -0,0 +1,48 @@
+package webauthntest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+var _ webauthn.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedCreationOptions *webauthn.CredentialCreationOptions
+	ExpectedRequestOptions  *webauthn.CredentialRequestOptions
+	ExpectedCredential      *webauthn.CredentialDTO
+	ExpectedCredentials     []*webauthn.CredentialDTO
+	ExpectedLoginResult     *webauthn.LoginResult
+	ExpectedHasCredentials  bool
+	ExpectedErr             error
+}
+
+func (f *FakeService) BeginRegistration(ctx context.Context, usr *user.User) (*webauthn.CredentialCreationOptions, error) {
+	return f.ExpectedCreationOptions, f.ExpectedErr
+}
+
+func (f *FakeService) FinishRegistration(ctx context.Context, userID int64, name string, response *webauthn.RegistrationResponse) (*webauthn.CredentialDTO, error) {
+	return f.ExpectedCredential, f.ExpectedErr
+}
+
+func (f *FakeService) BeginLogin(ctx context.Context, userID int64, mfaChallenge string) (*webauthn.CredentialRequestOptions, error) {
+	return f.ExpectedRequestOptions, f.ExpectedErr
+}
+
+func (f *FakeService) FinishLogin(ctx context.Context, response *webauthn.AssertionResponse) (*webauthn.LoginResult, error) {
+	return f.ExpectedLoginResult, f.ExpectedErr
+}
+
+func (f *FakeService) HasCredentials(ctx context.Context, userID int64) (bool, error) {
+	return f.ExpectedHasCredentials, f.ExpectedErr
+}
+
+func (f *FakeService) ListCredentials(ctx context.Context, userID int64) ([]*webauthn.CredentialDTO, error) {
+	return f.ExpectedCredentials, f.ExpectedErr
+}
+
+func (f *FakeService) DeleteCredential(ctx context.Context, userID, id int64) error {
+	return f.ExpectedErr
+}
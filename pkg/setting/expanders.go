@@ -89,6 +89,19 @@ func ExpandVar(s string) (string, error) {
 	return s, nil
 }
 
+// ExpandReference expands the value of a single $__<name>{value} reference with the expander added as name. The
+// expanded value is returned as is, other expanders are not applied to it. The returned bool is false when no
+// expander is added as name.
+func ExpandReference(name, value string) (string, bool, error) {
+	for _, expander := range expanders {
+		if expander.name == name {
+			expanded, err := expander.expander.Expand(value)
+			return expanded, true, err
+		}
+	}
+	return "", false, nil
+}
+
 func applyExpander(s string, e registeredExpander) (string, error) {
 	matches := regex.FindAllStringSubmatch(s, -1)
 
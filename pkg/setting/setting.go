@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -168,6 +169,20 @@ type Cfg struct {
 	StrictTransportSecurityMaxAge     int
 	StrictTransportSecurityPreload    bool
 	StrictTransportSecuritySubDomains bool
+
+	// Failed login attempts of a username, of a username from an IP address and from an IP address
+	// after which logins are blocked, 0 disables the policy.
+	BruteForceLoginProtectionMaxAttempts           int64
+	BruteForceLoginProtectionIPUsernameMaxAttempts int64
+	BruteForceLoginProtectionIPMaxAttempts         int64
+	// BruteForceLoginProtectionLockout is how long logins are blocked after the last failed attempt, it doubles
+	// with every attempt above the maximum up to BruteForceLoginProtectionMaxLockout.
+	BruteForceLoginProtectionLockout    time.Duration
+	BruteForceLoginProtectionMaxLockout time.Duration
+	// BruteForceLoginProtectionTrustedProxies are the networks of the reverse proxies whose X-Forwarded-For
+	// and X-Real-IP headers are used as the IP address of a login attempt.
+	BruteForceLoginProtectionTrustedProxies []*net.IPNet
+
 	// CSPEnabled toggles Content Security Policy support.
 	CSPEnabled bool
 	// CSPTemplate contains the Content Security Policy template.
@@ -224,6 +239,7 @@ type Cfg struct {
 	DashboardVersionsToKeep  int
 	MinRefreshInterval       string
 	DefaultHomeDashboardPath string
+	DashboardLintOnSave      bool
 
 	// Auth
 	LoginCookieName               string
@@ -273,6 +289,9 @@ type Cfg struct {
 
 	PasswordlessMagicLinkAuth AuthPasswordlessMagicLinkSettings
 
+	MFA      AuthMFASettings
+	WebAuthn AuthWebAuthnSettings
+
 	// SSO Settings Auth
 	SSOSettingsReloadInterval        time.Duration
 	SSOSettingsConfigurableProviders map[string]bool
@@ -436,6 +455,11 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveHistoryMaxFrames is a number of frames kept per managed stream channel
+	// to replay them to new subscribers. 0 disables the history.
+	LiveHistoryMaxFrames int
+	// LiveHistoryMaxAge is a maximum age of frames kept in managed stream history.
+	LiveHistoryMaxAge time.Duration
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -457,6 +481,11 @@ type Cfg struct {
 	// Query history
 	QueryHistoryEnabled bool
 
+	// Dashboard reports
+	DashboardReportsEnabled bool
+	// Hosts report webhooks may post to even when they resolve to internal addresses
+	DashboardReportsWebhookAllowedHosts []string
+
 	Storage StorageSettings
 
 	Search SearchSettings
@@ -499,7 +528,11 @@ type Cfg struct {
 	DatabaseInstrumentQueries bool
 
 	// Public dashboards
-	PublicDashboardsEnabled bool
+	PublicDashboardsEnabled                  bool
+	PublicDashboardsQueryRateLimit           float64
+	PublicDashboardsQueryRateLimitBurst      int
+	PublicDashboardsPassphraseRateLimit      float64
+	PublicDashboardsPassphraseRateLimitBurst int
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
@@ -539,11 +572,38 @@ type Cfg struct {
 	IndexWorkers      int
 	IndexMaxBatchSize int
 	IndexListLimit    int
+
+	HistoryCompactionInterval time.Duration
+	// Compression of the stored resource values
+	ValueCompression        string
+	ValueCompressionMinSize int
+	UnifiedStorageSinks     []UnifiedStorageSinkConfig
 }
 
 type UnifiedStorageConfig struct {
 	DualWriterMode                       rest.DualWriterMode
 	DualWriterPeriodicDataSyncJobEnabled bool
+
+	// HistoryMaxVersions is the number of versions kept per object in the resource history (0 means unlimited)
+	HistoryMaxVersions int
+	// HistoryMaxAge is the age after which old versions are removed from the resource history (0 means unlimited)
+	HistoryMaxAge time.Duration
+}
+
+// UnifiedStorageSinkConfig is an external system receiving the write events of unified storage
+type UnifiedStorageSinkConfig struct {
+	Name string
+	// webhook, file or kafka
+	Type string
+	// URL of the webhook, or of the Kafka REST proxy
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+	Path    string
+	// Kafka topic
+	Topic string
+	// group/resource pairs sent to the sink, all when empty
+	Resources []string
 }
 
 type InstallPlugin struct {
@@ -1121,6 +1181,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
 	cfg.MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	cfg.DashboardLintOnSave = dashboards.Key("lint_on_save").MustBool(false)
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -1213,6 +1274,10 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	queryHistory := iniFile.Section("query_history")
 	cfg.QueryHistoryEnabled = queryHistory.Key("enabled").MustBool(true)
 
+	dashboardReports := iniFile.Section("dashboard_reports")
+	cfg.DashboardReportsEnabled = dashboardReports.Key("enabled").MustBool(false)
+	cfg.DashboardReportsWebhookAllowedHosts = util.SplitString(dashboardReports.Key("webhook_allowed_hosts").MustString(""))
+
 	shortLinks := iniFile.Section("short_links")
 	cfg.ShortLinkExpiration = shortLinks.Key("expire_time").MustInt(7)
 
@@ -1251,6 +1316,8 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readAuthProxySettings()
 	cfg.readSessionConfig()
 	cfg.readPasswordlessMagicLinkSettings()
+	cfg.readMFASettings()
+	cfg.readWebAuthnSettings()
 	if err := cfg.readSmtpSettings(); err != nil {
 		return err
 	}
@@ -1506,6 +1573,32 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.SecretKey = valueAsString(security, "secret_key", "")
 	cfg.DisableGravatar = security.Key("disable_gravatar").MustBool(true)
 	cfg.DisableBruteForceLoginProtection = security.Key("disable_brute_force_login_protection").MustBool(false)
+	cfg.BruteForceLoginProtectionMaxAttempts = security.Key("brute_force_login_protection_max_attempts").MustInt64(5)
+	cfg.BruteForceLoginProtectionIPUsernameMaxAttempts = security.Key("brute_force_login_protection_ip_username_max_attempts").MustInt64(5)
+	cfg.BruteForceLoginProtectionIPMaxAttempts = security.Key("brute_force_login_protection_ip_max_attempts").MustInt64(0)
+	cfg.BruteForceLoginProtectionLockout = security.Key("brute_force_login_protection_lockout").MustDuration(5 * time.Minute)
+	cfg.BruteForceLoginProtectionMaxLockout = security.Key("brute_force_login_protection_max_lockout").MustDuration(time.Hour)
+	if cfg.BruteForceLoginProtectionLockout <= 0 {
+		return fmt.Errorf("brute_force_login_protection_lockout must be positive")
+	}
+	if cfg.BruteForceLoginProtectionMaxLockout < cfg.BruteForceLoginProtectionLockout {
+		cfg.BruteForceLoginProtectionMaxLockout = cfg.BruteForceLoginProtectionLockout
+	}
+	cfg.BruteForceLoginProtectionTrustedProxies = nil
+	for _, proxy := range util.SplitString(security.Key("brute_force_login_protection_trusted_proxies").MustString("")) {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid brute_force_login_protection_trusted_proxies entry %q: %w", proxy, err)
+		}
+		cfg.BruteForceLoginProtectionTrustedProxies = append(cfg.BruteForceLoginProtectionTrustedProxies, network)
+	}
 
 	CookieSecure = security.Key("cookie_secure").MustBool(false)
 	cfg.CookieSecure = CookieSecure
@@ -2027,6 +2120,11 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	cfg.LiveHAPrefix = section.Key("ha_prefix").MustString("")
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveHistoryMaxFrames = section.Key("managed_stream_history_max_frames").MustInt(0)
+	if cfg.LiveHistoryMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_history_max_frames", cfg.LiveHistoryMaxFrames)
+	}
+	cfg.LiveHistoryMaxAge = section.Key("managed_stream_history_max_age").MustDuration(5 * time.Minute)
 
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	origins := strings.Split(allowedOrigins, ",")
@@ -2052,6 +2150,10 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 func (cfg *Cfg) readPublicDashboardsSettings() {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+	cfg.PublicDashboardsQueryRateLimit = publicDashboards.Key("query_rate_limit").MustFloat64(10)
+	cfg.PublicDashboardsQueryRateLimitBurst = publicDashboards.Key("query_rate_limit_burst").MustInt(100)
+	cfg.PublicDashboardsPassphraseRateLimit = publicDashboards.Key("passphrase_rate_limit").MustFloat64(0.1)
+	cfg.PublicDashboardsPassphraseRateLimitBurst = publicDashboards.Key("passphrase_rate_limit_burst").MustInt(10)
 }
 
 func (cfg *Cfg) DefaultOrgID() int64 {
@@ -0,0 +1,22 @@
+package setting
+
+import "time"
+
+type AuthMFASettings struct {
+	// TOTP multi-factor authentication for users logging in with a Grafana password
+	Enabled bool
+	// Enforced requires all users logging in with a Grafana password to use multi-factor authentication
+	Enforced     bool
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+func (cfg *Cfg) readMFASettings() {
+	authMFA := cfg.SectionWithEnvOverrides("auth.mfa")
+	MFASettings := AuthMFASettings{}
+	MFASettings.Enabled = authMFA.Key("enabled").MustBool(false)
+	MFASettings.Enforced = authMFA.Key("enforced").MustBool(false)
+	MFASettings.Issuer = authMFA.Key("issuer").MustString("Grafana")
+	MFASettings.ChallengeTTL = authMFA.Key("challenge_ttl").MustDuration(time.Minute * 5)
+	cfg.MFA = MFASettings
+}
@@ -2,8 +2,10 @@ package setting
 
 import (
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 // read storage configs from ini file. They look like:
@@ -12,6 +14,8 @@ import (
 // e.g.
 // [unified_storage.playlists.playlist.grafana.app]
 // dualWriterMode = 2
+// historyMaxVersions = 100
+// historyMaxAge = 720h
 func (cfg *Cfg) setUnifiedStorageConfig() {
 	storageConfig := make(map[string]UnifiedStorageConfig)
 	sections := cfg.Raw.Sections()
@@ -29,9 +33,15 @@ func (cfg *Cfg) setUnifiedStorageConfig() {
 		// parse dualWriter periodic data syncer config
 		dualWriterPeriodicDataSyncJobEnabled := section.Key("dualWriterPeriodicDataSyncJobEnabled").MustBool(false)
 
+		// parse resource history retention from the section
+		historyMaxVersions := section.Key("historyMaxVersions").MustInt(0)
+		historyMaxAge := section.Key("historyMaxAge").MustDuration(0)
+
 		storageConfig[resourceName] = UnifiedStorageConfig{
 			DualWriterMode:                       rest.DualWriterMode(dualWriterMode),
 			DualWriterPeriodicDataSyncJobEnabled: dualWriterPeriodicDataSyncJobEnabled,
+			HistoryMaxVersions:                   historyMaxVersions,
+			HistoryMaxAge:                        historyMaxAge,
 		}
 	}
 	cfg.UnifiedStorage = storageConfig
@@ -42,4 +52,54 @@ func (cfg *Cfg) setUnifiedStorageConfig() {
 	cfg.IndexWorkers = section.Key("index_workers").MustInt(10)
 	cfg.IndexMaxBatchSize = section.Key("index_max_batch_size").MustInt(100)
 	cfg.IndexListLimit = section.Key("index_list_limit").MustInt(1000)
+
+	cfg.setUnifiedStorageSinks()
+
+	// Set resource history compaction config for unified storage
+	cfg.HistoryCompactionInterval = section.Key("history_compaction_interval").MustDuration(time.Hour)
+
+	// Set the compression of the values stored by unified storage
+	cfg.ValueCompression = section.Key("value_compression").MustString("")
+	cfg.ValueCompressionMinSize = section.Key("value_compression_min_size").MustInt(4096)
+}
+
+// read the event sinks of unified storage from the ini file. They look like:
+// [unified_storage_sink.<name>]
+// type = webhook
+// url = https://example.com/hook
+// resources = dashboard.grafana.app/dashboards, folder.grafana.app/folders
+//
+// [unified_storage_sink.<name>]
+// type = kafka
+// url = http://kafka-rest-proxy:8082
+// topic = grafana-events
+func (cfg *Cfg) setUnifiedStorageSinks() {
+	sinks := []UnifiedStorageSinkConfig{}
+	for _, section := range cfg.Raw.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), "unified_storage_sink.")
+		if !ok || name == "" {
+			continue
+		}
+
+		headers := map[string]string{}
+		// header values may contain spaces, so only split on commas
+		for _, header := range strings.Split(section.Key("headers").String(), ",") {
+			k, v, ok := strings.Cut(header, ":")
+			if ok && strings.TrimSpace(k) != "" {
+				headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+
+		sinks = append(sinks, UnifiedStorageSinkConfig{
+			Name:      name,
+			Type:      section.Key("type").String(),
+			URL:       section.Key("url").String(),
+			Headers:   headers,
+			Timeout:   section.Key("timeout").MustDuration(10 * time.Second),
+			Path:      section.Key("path").String(),
+			Topic:     section.Key("topic").String(),
+			Resources: util.SplitString(section.Key("resources").String()),
+		})
+	}
+	cfg.UnifiedStorageSinks = sinks
 }
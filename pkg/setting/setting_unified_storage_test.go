@@ -2,6 +2,7 @@ package setting
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -31,4 +32,51 @@ func TestCfg_setUnifiedStorageConfig(t *testing.T) {
 			DualWriterPeriodicDataSyncJobEnabled: true,
 		})
 	})
+
+	t.Run("read unified_storage_sink configs", func(t *testing.T) {
+		cfg := NewCfg()
+		err := cfg.Load(CommandLineArgs{HomePath: "../../", Config: "../../conf/defaults.ini"})
+		assert.NoError(t, err)
+
+		s, err := cfg.Raw.NewSection("unified_storage_sink.audit")
+		assert.NoError(t, err)
+
+		_, err = s.NewKey("type", "webhook")
+		assert.NoError(t, err)
+		_, err = s.NewKey("url", "https://example.com/hook")
+		assert.NoError(t, err)
+		_, err = s.NewKey("headers", "Authorization: Bearer token, X-Source: grafana")
+		assert.NoError(t, err)
+		_, err = s.NewKey("resources", "dashboard.grafana.app/dashboards")
+		assert.NoError(t, err)
+
+		s, err = cfg.Raw.NewSection("unified_storage_sink.events")
+		assert.NoError(t, err)
+
+		_, err = s.NewKey("type", "kafka")
+		assert.NoError(t, err)
+		_, err = s.NewKey("url", "http://kafka-rest-proxy:8082")
+		assert.NoError(t, err)
+		_, err = s.NewKey("topic", "grafana-events")
+		assert.NoError(t, err)
+
+		cfg.setUnifiedStorageConfig()
+
+		assert.Equal(t, []UnifiedStorageSinkConfig{{
+			Name:      "audit",
+			Type:      "webhook",
+			URL:       "https://example.com/hook",
+			Headers:   map[string]string{"Authorization": "Bearer token", "X-Source": "grafana"},
+			Timeout:   10 * time.Second,
+			Resources: []string{"dashboard.grafana.app/dashboards"},
+		}, {
+			Name:      "events",
+			Type:      "kafka",
+			URL:       "http://kafka-rest-proxy:8082",
+			Headers:   map[string]string{},
+			Timeout:   10 * time.Second,
+			Topic:     "grafana-events",
+			Resources: []string{},
+		}}, cfg.UnifiedStorageSinks)
+	})
 }
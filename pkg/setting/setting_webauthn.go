@@ -0,0 +1,31 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type AuthWebAuthnSettings struct {
+	// WebAuthn security keys and passkeys
+	Enabled bool
+	// RPID is the domain the credentials are bound to, the domain of the root_url when empty
+	RPID   string
+	RPName string
+	// Origins allowed to use the credentials, the origin of the root_url when empty
+	Origins          []string
+	UserVerification string
+	Timeout          time.Duration
+}
+
+func (cfg *Cfg) readWebAuthnSettings() {
+	authWebAuthn := cfg.SectionWithEnvOverrides("auth.webauthn")
+	WebAuthnSettings := AuthWebAuthnSettings{}
+	WebAuthnSettings.Enabled = authWebAuthn.Key("enabled").MustBool(false)
+	WebAuthnSettings.RPID = authWebAuthn.Key("rp_id").MustString("")
+	WebAuthnSettings.RPName = authWebAuthn.Key("rp_name").MustString("Grafana")
+	WebAuthnSettings.Origins = util.SplitString(authWebAuthn.Key("origins").MustString(""))
+	WebAuthnSettings.UserVerification = authWebAuthn.Key("user_verification").In("preferred", []string{"required", "preferred", "discouraged"})
+	WebAuthnSettings.Timeout = authWebAuthn.Key("timeout").MustDuration(time.Minute * 5)
+	cfg.WebAuthn = WebAuthnSettings
+}
@@ -10,6 +10,7 @@ import (
 	"gocloud.dev/blob/fileblob"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
 
 	authnlib "github.com/grafana/authlib/authn"
 
@@ -41,6 +42,8 @@ func ProvideUnifiedStorageClient(
 		Address:      apiserverCfg.Key("address").MustString(""), // client address
 		BlobStoreURL: apiserverCfg.Key("blob_url").MustString(""),
 	}
+	// Compress the messages sent to the storage server (gzip or zstd)
+	grpcCompression := apiserverCfg.Key("grpc_compression").MustString("")
 	ctx := context.Background()
 
 	switch opts.StorageType {
@@ -77,11 +80,20 @@ func ProvideUnifiedStorageClient(
 			return nil, fmt.Errorf("expecting address for storage_type: %s", opts.StorageType)
 		}
 
-		// Create a connection to the gRPC server
-		conn, err := grpc.NewClient(opts.Address,
+		dialOpts := []grpc.DialOption{
 			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
-		)
+		}
+		if grpcCompression != "" {
+			if encoding.GetCompressor(grpcCompression) == nil {
+				return nil, fmt.Errorf("unsupported grpc_compression: %s", grpcCompression)
+			}
+			// The server replies with the same encoding
+			dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(grpcCompression)))
+		}
+
+		// Create a connection to the gRPC server
+		conn, err := grpc.NewClient(opts.Address, dialOpts...)
 		if err != nil {
 			return nil, err
 		}
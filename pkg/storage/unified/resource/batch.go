@@ -0,0 +1,219 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/authlib/authz"
+	"github.com/grafana/authlib/claims"
+)
+
+// The maximum number of items in a single batch write
+const maxBatchWriteItems = 100
+
+// BatchStorageBackend is implemented by the storage backends that can write
+// multiple events atomically
+type BatchStorageBackend interface {
+	// Write all the events, or none of them.
+	// The PreviousRV of updated and deleted resources must match the stored value,
+	// and created resources must not exist yet, otherwise ErrOptimisticLockingFailed is returned.
+	// Errors caused by a single event are wrapped in a *BatchWriteError.
+	// Returns the resource version of each event
+	WriteEvents(context.Context, []WriteEvent) ([]int64, error)
+}
+
+// BatchWriteError links a batch write failure to the event that caused it
+type BatchWriteError struct {
+	// The index of the event in the batch
+	Index int
+	Err   error
+}
+
+func (e *BatchWriteError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchWriteError) Unwrap() error {
+	return e.Err
+}
+
+func (s *server) BatchWrite(ctx context.Context, req *BatchWriteRequest) (*BatchWriteResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "storage_server.BatchWrite")
+	defer span.End()
+
+	if err := s.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	rsp := &BatchWriteResponse{}
+	user, ok := claims.From(ctx)
+	if !ok || user == nil {
+		rsp.Error = &ErrorResult{
+			Message: "no user found in context",
+			Code:    http.StatusUnauthorized,
+		}
+		return rsp, nil
+	}
+
+	backend, ok := s.backend.(BatchStorageBackend)
+	if !ok {
+		rsp.Error = &ErrorResult{
+			Message: "the storage backend does not support batch writes",
+			Code:    http.StatusNotImplemented,
+		}
+		return rsp, nil
+	}
+
+	if len(req.Items) == 0 {
+		rsp.Error = NewBadRequestError("missing items")
+		return rsp, nil
+	}
+	if len(req.Items) > maxBatchWriteItems {
+		rsp.Error = NewBadRequestError(fmt.Sprintf("too many items (max %d)", maxBatchWriteItems))
+		return rsp, nil
+	}
+
+	// Validate every item before writing anything
+	events := make([]WriteEvent, len(req.Items))
+	rsp.Results = make([]*BatchWriteResponse_Result, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	failed := -1
+	for i, item := range req.Items {
+		event, e := s.newBatchEvent(ctx, user, item)
+		if e == nil {
+			// the expected versions are ambiguous when an object is written twice
+			id := fmt.Sprintf("%s/%s/%s/%s", event.Key.Namespace, event.Key.Group, event.Key.Resource, event.Key.Name)
+			if seen[id] {
+				e = NewBadRequestError("duplicate key in batch")
+			}
+			seen[id] = true
+		}
+		rsp.Results[i] = &BatchWriteResponse_Result{Key: item.Key, Error: e}
+		if e != nil {
+			if failed < 0 {
+				failed = i
+			}
+			continue
+		}
+		events[i] = *event
+	}
+	if failed >= 0 {
+		rsp.Error = batchError(failed, rsp.Results[failed].Error)
+		return rsp, nil
+	}
+
+	rvs, err := backend.WriteEvents(ctx, events)
+	if err != nil {
+		var itemErr *BatchWriteError
+		if errors.As(err, &itemErr) && itemErr.Index >= 0 && itemErr.Index < len(rsp.Results) {
+			rsp.Results[itemErr.Index].Error = asBatchErrorResult(itemErr.Err)
+			rsp.Error = batchError(itemErr.Index, rsp.Results[itemErr.Index].Error)
+		} else {
+			rsp.Error = asBatchErrorResult(err)
+		}
+		return rsp, nil
+	}
+	for i, rv := range rvs {
+		rsp.Results[i].ResourceVersion = rv
+	}
+	s.log.Debug("server.BatchWrite", "items", len(events), "rvs", rvs)
+	return rsp, nil
+}
+
+// newBatchEvent validates a batch item and converts it to a write event, the same way
+// Create, Update and Delete do
+func (s *server) newBatchEvent(ctx context.Context, user claims.AuthInfo, item *BatchWriteRequest_Item) (*WriteEvent, *ErrorResult) {
+	if e := verifyRequestKey(item.Key); e != nil {
+		return nil, e
+	}
+	if item.ResourceVersion < 0 {
+		return nil, NewBadRequestError("invalid resource version")
+	}
+
+	switch item.Action {
+	case BatchWriteRequest_Item_CREATE:
+		found := s.backend.ReadResource(ctx, &ReadRequest{Key: item.Key})
+		if found != nil && len(found.Value) > 0 {
+			return nil, &ErrorResult{
+				Code:    http.StatusConflict,
+				Message: "key already exists",
+			}
+		}
+		return s.newEvent(ctx, user, item.Key, item.Value, nil)
+
+	case BatchWriteRequest_Item_UPDATE:
+		latest := s.backend.ReadResource(ctx, &ReadRequest{Key: item.Key})
+		if latest.Error != nil {
+			return nil, latest.Error
+		}
+		if latest.Value == nil {
+			return nil, NewBadRequestError("current value does not exist")
+		}
+		if item.ResourceVersion > 0 && latest.ResourceVersion != item.ResourceVersion {
+			return nil, asBatchErrorResult(ErrOptimisticLockingFailed)
+		}
+		event, e := s.newEvent(ctx, user, item.Key, item.Value, latest.Value)
+		if e != nil {
+			return nil, e
+		}
+		event.Type = WatchEvent_MODIFIED
+		event.PreviousRV = latest.ResourceVersion
+		return event, nil
+
+	case BatchWriteRequest_Item_DELETE:
+		latest := s.backend.ReadResource(ctx, &ReadRequest{Key: item.Key})
+		if latest.Error != nil {
+			return nil, latest.Error
+		}
+		if item.ResourceVersion > 0 && latest.ResourceVersion != item.ResourceVersion {
+			return nil, asBatchErrorResult(ErrOptimisticLockingFailed)
+		}
+		access, err := s.access.Check(ctx, user, authz.CheckRequest{
+			Verb:      "delete",
+			Group:     item.Key.Group,
+			Resource:  item.Key.Resource,
+			Namespace: item.Key.Namespace,
+			Name:      item.Key.Name,
+			Folder:    latest.Folder,
+		})
+		if err != nil {
+			return nil, AsErrorResult(err)
+		}
+		if !access.Allowed {
+			return nil, &ErrorResult{
+				Code: http.StatusForbidden,
+			}
+		}
+		event, err := s.newDeleteEvent(user, item.Key, latest)
+		if err != nil {
+			return nil, AsErrorResult(err)
+		}
+		return event, nil
+	}
+	return nil, NewBadRequestError(fmt.Sprintf("unsupported action %q", item.Action))
+}
+
+// asBatchErrorResult reports optimistic locking failures as conflicts
+func asBatchErrorResult(err error) *ErrorResult {
+	if errors.Is(err, ErrOptimisticLockingFailed) {
+		return &ErrorResult{
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+			Reason:  string(metav1.StatusReasonConflict),
+		}
+	}
+	return AsErrorResult(err)
+}
+
+func batchError(index int, e *ErrorResult) *ErrorResult {
+	return &ErrorResult{
+		Message: fmt.Sprintf("item %d: %s", index, e.Message),
+		Reason:  e.Reason,
+		Code:    e.Code,
+		Details: e.Details,
+	}
+}
@@ -0,0 +1,144 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+// failingBucket fails the writes with a path containing failOn
+type failingBucket struct {
+	*blob.Bucket
+	failOn string
+}
+
+func (b *failingBucket) WriteAll(ctx context.Context, key string, p []byte, opts *blob.WriterOptions) error {
+	if strings.Contains(key, b.failOn) {
+		return fmt.Errorf("write failed")
+	}
+	return b.Bucket.WriteAll(ctx, key, p, opts)
+}
+
+func TestBatchWrite(t *testing.T) {
+	ctx := claims.WithClaims(context.Background(), &identity.StaticRequester{
+		Type:           claims.TypeUser,
+		Login:          "testuser",
+		UserID:         123,
+		UserUID:        "u123",
+		OrgRole:        identity.RoleAdmin,
+		IsGrafanaAdmin: true, // can do anything
+	})
+
+	bucket := &failingBucket{Bucket: memblob.OpenBucket(nil), failOn: "__never__"}
+	store, err := NewCDKBackend(ctx, CDKBackendOptions{
+		Bucket: bucket,
+	})
+	require.NoError(t, err)
+
+	server, err := NewResourceServer(ResourceServerOptions{
+		Backend: store,
+	})
+	require.NoError(t, err)
+
+	key := func(name string) *ResourceKey {
+		return &ResourceKey{
+			Group:     "playlist.grafana.app",
+			Resource:  "playlists",
+			Namespace: "default",
+			Name:      name,
+		}
+	}
+	value := func(name, title string) []byte {
+		return []byte(fmt.Sprintf(`{
+			"apiVersion": "playlist.grafana.app/v0alpha1",
+			"kind": "Playlist",
+			"metadata": {"name": %q, "uid": %q, "namespace": "default"},
+			"spec": {"title": %q}
+		}`, name, name, title))
+	}
+	read := func(name string) *ReadResponse {
+		rsp, err := server.Read(ctx, &ReadRequest{Key: key(name)})
+		require.NoError(t, err)
+		return rsp
+	}
+
+	t.Run("create multiple resources", func(t *testing.T) {
+		rsp, err := server.BatchWrite(ctx, &BatchWriteRequest{Items: []*BatchWriteRequest_Item{
+			{Action: BatchWriteRequest_Item_CREATE, Key: key("a"), Value: value("a", "A")},
+			{Action: BatchWriteRequest_Item_CREATE, Key: key("b"), Value: value("b", "B")},
+		}})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Len(t, rsp.Results, 2)
+		require.Greater(t, rsp.Results[1].ResourceVersion, rsp.Results[0].ResourceVersion)
+
+		require.Equal(t, rsp.Results[0].ResourceVersion, read("a").ResourceVersion)
+		require.Equal(t, rsp.Results[1].ResourceVersion, read("b").ResourceVersion)
+	})
+
+	t.Run("nothing is written when a version does not match", func(t *testing.T) {
+		a, b := read("a"), read("b")
+
+		rsp, err := server.BatchWrite(ctx, &BatchWriteRequest{Items: []*BatchWriteRequest_Item{
+			{Action: BatchWriteRequest_Item_UPDATE, Key: key("a"), Value: value("a", "A2"), ResourceVersion: a.ResourceVersion},
+			{Action: BatchWriteRequest_Item_UPDATE, Key: key("b"), Value: value("b", "B2"), ResourceVersion: b.ResourceVersion - 1},
+		}})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+		require.Equal(t, int32(http.StatusConflict), rsp.Error.Code)
+		require.Nil(t, rsp.Results[0].Error)
+		require.Equal(t, int32(http.StatusConflict), rsp.Results[1].Error.Code)
+
+		require.Equal(t, a.ResourceVersion, read("a").ResourceVersion)
+		require.Equal(t, b.ResourceVersion, read("b").ResourceVersion)
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		rsp, err := server.BatchWrite(ctx, &BatchWriteRequest{Items: []*BatchWriteRequest_Item{
+			{Action: BatchWriteRequest_Item_UPDATE, Key: key("a"), Value: value("a", "A2")},
+			{Action: BatchWriteRequest_Item_DELETE, Key: key("a")},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, int32(http.StatusBadRequest), rsp.Error.Code)
+		require.Contains(t, rsp.Error.Message, "item 1")
+	})
+
+	t.Run("written objects are removed when a write fails", func(t *testing.T) {
+		a := read("a")
+		bucket.failOn = "/c/"
+		defer func() { bucket.failOn = "__never__" }()
+
+		rsp, err := server.BatchWrite(ctx, &BatchWriteRequest{Items: []*BatchWriteRequest_Item{
+			{Action: BatchWriteRequest_Item_UPDATE, Key: key("a"), Value: value("a", "A3")},
+			{Action: BatchWriteRequest_Item_CREATE, Key: key("c"), Value: value("c", "C")},
+		}})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+		require.NotNil(t, rsp.Results[1].Error)
+
+		found := read("a")
+		require.Equal(t, a.ResourceVersion, found.ResourceVersion)
+		require.Equal(t, a.Value, found.Value)
+	})
+
+	t.Run("delete multiple resources", func(t *testing.T) {
+		rsp, err := server.BatchWrite(ctx, &BatchWriteRequest{Items: []*BatchWriteRequest_Item{
+			{Action: BatchWriteRequest_Item_DELETE, Key: key("a")},
+			{Action: BatchWriteRequest_Item_DELETE, Key: key("b"), ResourceVersion: read("b").ResourceVersion},
+		}})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+
+		require.Equal(t, int32(http.StatusNotFound), read("a").Error.Code)
+		require.Equal(t, int32(http.StatusNotFound), read("b").Error.Code)
+	})
+}
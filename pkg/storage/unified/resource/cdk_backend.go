@@ -138,6 +138,77 @@ func (s *cdkBackend) WriteEvent(ctx context.Context, event WriteEvent) (rv int64
 	return rv, err
 }
 
+var _ BatchStorageBackend = (*cdkBackend)(nil)
+
+// WriteEvents writes all the events or none of them.
+// The bucket does not support transactions: when a write fails, the objects already
+// written by the batch are removed again.
+func (s *cdkBackend) WriteEvents(ctx context.Context, events []WriteEvent) ([]int64, error) {
+	rvs, err := s.writeEvents(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+
+	// Async notify all subscribers (in order)
+	if s.stream != nil {
+		now := time.Now().UnixMilli()
+		go func() {
+			for i, event := range events {
+				s.stream <- &WrittenEvent{
+					WriteEvent:      event,
+					Timestamp:       now,
+					ResourceVersion: rvs[i],
+				}
+			}
+		}()
+	}
+	return rvs, nil
+}
+
+func (s *cdkBackend) writeEvents(ctx context.Context, events []WriteEvent) ([]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// All writes hold the mutex, so the versions can not change until the batch is written
+	for i, event := range events {
+		latest := s.ReadResource(ctx, &ReadRequest{Key: event.Key})
+		switch event.Type {
+		case WatchEvent_ADDED:
+			if len(latest.Value) > 0 {
+				return nil, &BatchWriteError{Index: i, Err: ErrOptimisticLockingFailed}
+			}
+		case WatchEvent_MODIFIED, WatchEvent_DELETED:
+			if latest.Error != nil {
+				return nil, &BatchWriteError{Index: i, Err: GetError(latest.Error)}
+			}
+			if latest.ResourceVersion != event.PreviousRV {
+				return nil, &BatchWriteError{Index: i, Err: ErrOptimisticLockingFailed}
+			}
+		default:
+			return nil, &BatchWriteError{Index: i, Err: fmt.Errorf("unsupported event type")}
+		}
+	}
+
+	rvs := make([]int64, 0, len(events))
+	for i, event := range events {
+		rv := s.rv.Add(1)
+		err := s.bucket.WriteAll(ctx, s.getPath(event.Key, rv), event.Value, &blob.WriterOptions{
+			ContentType: "application/json",
+		})
+		if err != nil {
+			// Roll back, so the batch is not partially applied
+			for j, written := range rvs {
+				if derr := s.bucket.Delete(ctx, s.getPath(events[j].Key, written)); derr != nil {
+					err = errors.Join(err, fmt.Errorf("rollback %s: %w", events[j].Key.Name, derr))
+				}
+			}
+			return nil, &BatchWriteError{Index: i, Err: err}
+		}
+		rvs = append(rvs, rv)
+	}
+	return rvs, nil
+}
+
 func (s *cdkBackend) ReadResource(ctx context.Context, req *ReadRequest) *BackendReadResponse {
 	rv := req.ResourceVersion
 
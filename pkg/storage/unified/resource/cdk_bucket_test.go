@@ -16,6 +16,7 @@ type fakeCDKBucket struct {
 	attributesFunc func(ctx context.Context, key string) (*blob.Attributes, error)
 	writeAllFunc   func(ctx context.Context, key string, p []byte, opts *blob.WriterOptions) error
 	readAllFunc    func(ctx context.Context, key string) ([]byte, error)
+	deleteFunc     func(ctx context.Context, key string) error
 	signedURLFunc  func(ctx context.Context, key string, opts *blob.SignedURLOptions) (string, error)
 	listFunc       func(opts *blob.ListOptions) *blob.ListIterator
 	listPageFunc   func(ctx context.Context, pageToken []byte, pageSize int, opts *blob.ListOptions) ([]*blob.ListObject, []byte, error)
@@ -42,6 +43,13 @@ func (f *fakeCDKBucket) ReadAll(ctx context.Context, key string) ([]byte, error)
 	return nil, nil
 }
 
+func (f *fakeCDKBucket) Delete(ctx context.Context, key string) error {
+	if f.deleteFunc != nil {
+		return f.deleteFunc(ctx, key)
+	}
+	return nil
+}
+
 func (f *fakeCDKBucket) SignedURL(ctx context.Context, key string, opts *blob.SignedURLOptions) (string, error) {
 	if f.signedURLFunc != nil {
 		return f.signedURLFunc(ctx, key, opts)
@@ -108,6 +116,24 @@ func TestInstrumentedBucket(t *testing.T) {
 				return err
 			},
 		},
+		{
+			name:      "Delete",
+			operation: "Delete",
+			setup: func(fakeBucket *fakeCDKBucket, success bool) {
+				if success {
+					fakeBucket.deleteFunc = func(ctx context.Context, key string) error {
+						return nil
+					}
+				} else {
+					fakeBucket.deleteFunc = func(ctx context.Context, key string) error {
+						return fmt.Errorf("some error")
+					}
+				}
+			},
+			call: func(instrumentedBucket *InstrumentedBucket) error {
+				return instrumentedBucket.Delete(context.Background(), "key")
+			},
+		},
 		{
 			name:      "ReadAll",
 			operation: "ReadAll",
@@ -2,16 +2,20 @@ package resource
 
 import (
 	"context"
-	golog "log"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
 	reflect "reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/search"
-	"github.com/google/uuid"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"go.opentelemetry.io/otel/attribute"
@@ -23,10 +27,45 @@ const tracingPrexfixIndex = "unified_storage.index."
 const specFieldPrefix = "Spec."
 const descendingPrefix = "-"
 
+// Internal keys stored in the on-disk indexes
+var (
+	indexMappingVersionKey  = []byte("mapping_version")
+	indexResourceVersionKey = []byte("resource_version")
+)
+
+// indexMappingVersion must be increased whenever the index mappings change, so stale on-disk indexes are rebuilt
+const indexMappingVersion = "1"
+
 type Shard struct {
 	index bleve.Index
 	path  string
 	batch *bleve.Batch
+
+	// the latest resource version written to the index
+	rvMutex sync.Mutex
+	rv      int64
+}
+
+// ResourceVersion returns the latest resource version written to the shard
+func (s *Shard) ResourceVersion() int64 {
+	s.rvMutex.Lock()
+	defer s.rvMutex.Unlock()
+	return s.rv
+}
+
+// setResourceVersion records the latest resource version written to the shard.
+// For on-disk indexes, it is persisted so a restart can catch up from there.
+func (s *Shard) setResourceVersion(rv int64) error {
+	s.rvMutex.Lock()
+	defer s.rvMutex.Unlock()
+	if rv <= s.rv {
+		return nil
+	}
+	s.rv = rv
+	if s.path == "" {
+		return nil
+	}
+	return s.index.SetInternal(indexResourceVersionKey, []byte(strconv.FormatInt(rv, 10)))
 }
 
 type Opts struct {
@@ -192,10 +231,19 @@ func (i *Index) InitForTenant(ctx context.Context, namespace string) (int, error
 	defer span.End()
 	logger := i.log.FromContext(ctx)
 
+	shard, err := i.getShard(namespace)
+	if err != nil {
+		return 0, err
+	}
+	// An index loaded from disk only needs the resources that changed since it was last written
+	since := shard.ResourceVersion()
+	seen := map[string]bool{}
+	latestRV := int64(0)
+
 	resourceTypes := fetchResourceTypes()
 	totalObjectsFetched := 0
 	for _, rt := range resourceTypes {
-		logger.Debug("indexing resource", "kind", rt.Kind, "list_limit", i.opts.ListLimit, "batch_size", i.opts.BatchSize, "workers", i.opts.Workers, "namespace", namespace)
+		logger.Debug("indexing resource", "kind", rt.Kind, "list_limit", i.opts.ListLimit, "batch_size", i.opts.BatchSize, "workers", i.opts.Workers, "namespace", namespace, "since", since)
 		r := &ListRequest{Options: rt.ListOptions, Limit: int64(i.opts.ListLimit)}
 		r.Options.Key.Namespace = namespace // scope the list to a tenant or this will take forever when US has 1M+ resources
 
@@ -206,15 +254,24 @@ func (i *Index) InitForTenant(ctx context.Context, namespace string) (int, error
 			if err != nil {
 				return totalObjectsFetched, err
 			}
+			latestRV = max(latestRV, list.ResourceVersion)
 
 			// Record the number of objects indexed for the kind
 			IndexServerMetrics.IndexedKinds.WithLabelValues(rt.Kind).Add(float64(len(list.Items)))
 
 			totalObjectsFetched += len(list.Items)
 
-			logger.Debug("indexing batch", "kind", rt.Kind, "count", len(list.Items), "namespace", namespace)
+			changed := list
+			if since > 0 {
+				changed, err = i.changedSince(list, since, seen)
+				if err != nil {
+					return totalObjectsFetched, err
+				}
+			}
+
+			logger.Debug("indexing batch", "kind", rt.Kind, "count", len(changed.Items), "namespace", namespace)
 			//add changes to batches for shards with changes in the List
-			err = i.writeBatch(ctx, list)
+			err = i.writeBatch(ctx, changed)
 			if err != nil {
 				return totalObjectsFetched, err
 			}
@@ -227,6 +284,22 @@ func (i *Index) InitForTenant(ctx context.Context, namespace string) (int, error
 		}
 	}
 
+	if since > 0 {
+		if err := i.deleteUnseen(shard, seen); err != nil {
+			return totalObjectsFetched, err
+		}
+	}
+
+	// flush the remaining changes before recording how far the index got
+	err = i.IndexBatches(ctx, 1, []string{namespace})
+	if err != nil {
+		return totalObjectsFetched, err
+	}
+	err = shard.setResourceVersion(latestRV)
+	if err != nil {
+		return totalObjectsFetched, err
+	}
+
 	span.AddEvent(
 		"indexing finished for tenant",
 		trace.WithAttributes(attribute.Int64("objects_indexed", int64(totalObjectsFetched))),
@@ -236,6 +309,45 @@ func (i *Index) InitForTenant(ctx context.Context, namespace string) (int, error
 	return totalObjectsFetched, nil
 }
 
+// changedSince returns the resources of the list that changed after the given resource version,
+// and marks every listed resource as seen
+func (i *Index) changedSince(list *ListResponse, since int64, seen map[string]bool) (*ListResponse, error) {
+	changed := &ListResponse{}
+	for _, obj := range list.Items {
+		res, err := NewIndexedResource(obj.Value)
+		if err != nil {
+			return nil, err
+		}
+		seen[res.Uid] = true
+		if obj.ResourceVersion > since {
+			changed.Items = append(changed.Items, obj)
+		}
+	}
+	return changed, nil
+}
+
+// deleteUnseen removes the documents of resources that were deleted while the index was not running
+func (i *Index) deleteUnseen(shard *Shard, seen map[string]bool) error {
+	count, err := shard.index.DocCount()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	req := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), int(count), 0, false)
+	res, err := shard.index.Search(req)
+	if err != nil {
+		return err
+	}
+	for _, hit := range res.Hits {
+		if !seen[hit.ID] {
+			shard.batch.Delete(hit.ID)
+		}
+	}
+	return nil
+}
+
 func (i *Index) writeBatch(ctx context.Context, list *ListResponse) error {
 	tenants, err := i.AddToBatches(ctx, list)
 	if err != nil {
@@ -281,6 +393,10 @@ func (i *Index) Index(ctx context.Context, data *Data) error {
 	if err != nil {
 		return err
 	}
+	err = shard.setResourceVersion(data.Value.ResourceVersion)
+	if err != nil {
+		return err
+	}
 
 	//record the kind of resource that was indexed
 	IndexServerMetrics.IndexedKinds.WithLabelValues(res.Kind).Inc()
@@ -364,19 +480,40 @@ func (i *Index) Search(ctx context.Context, request *SearchRequest) (*IndexResul
 	}
 
 	req := bleve.NewSearchRequest(query)
-	if len(request.SortBy) > 0 {
-		sorting := getSortFields(request)
-		req.SortBy(sorting)
-	}
+	req.SortBy(getSortFields(request))
 
 	for _, group := range request.GroupBy {
 		facet := bleve.NewFacetRequest(specFieldPrefix+group.Name, int(group.Limit))
 		req.AddFacet(group.Name+"_facet", facet)
 	}
 
+	for _, f := range request.Facet {
+		field, ok := facetFields[f.Field]
+		if !ok {
+			return nil, fmt.Errorf("unsupported facet field %q", f.Field)
+		}
+		req.AddFacet(f.Field, bleve.NewFacetRequest(field, int(f.Limit)))
+	}
+
 	req.From = int(request.Offset)
 	req.Size = int(request.Limit)
 
+	// offset of the page in all hits, search_after pages start from 0 in the request
+	offset := req.From
+	if request.NextPageToken != "" {
+		token, err := getSearchContinueToken(request.NextPageToken)
+		if err != nil {
+			return nil, err
+		}
+		offset = int(token.Offset)
+		if len(token.After) > 0 {
+			req.From = 0
+			req.SetSearchAfter(token.After)
+		} else {
+			req.From = offset
+		}
+	}
+
 	req.Fields = []string{"*"} // return all indexed fields in search results
 
 	logger.Info("searching index", "query", request.Query, "tenant", request.Tenant)
@@ -403,7 +540,75 @@ func (i *Index) Search(ctx context.Context, request *SearchRequest) (*IndexResul
 		}
 	}
 
-	return &IndexResults{Values: results, Groups: groups}, nil
+	facets := map[string]*FacetResult{}
+	for _, f := range request.Facet {
+		facet, ok := res.Facets[f.Field]
+		if !ok {
+			continue
+		}
+		result := &FacetResult{
+			Field:   f.Field,
+			Total:   int64(facet.Total),
+			Missing: int64(facet.Missing),
+			Other:   int64(facet.Other),
+		}
+		for _, term := range facet.Terms.Terms() {
+			result.Terms = append(result.Terms, &Group{Name: term.Term, Count: int64(term.Count)})
+		}
+		facets[f.Field] = result
+	}
+
+	nextPageToken := ""
+	if len(hits) > 0 && len(hits) == req.Size && uint64(offset+len(hits)) < res.Total {
+		token := searchContinueToken{Offset: int64(offset + len(hits))}
+		if len(request.SortBy) > 0 {
+			// sort values are stable across pages, unlike the offset when documents are added or removed
+			token.After = hits[len(hits)-1].Sort
+		}
+		nextPageToken = token.String()
+	}
+
+	return &IndexResults{
+		Values:        results,
+		Groups:        groups,
+		Facets:        facets,
+		TotalHits:     int64(res.Total),
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// facetFields maps the supported facets to their indexed field
+var facetFields = map[string]string{
+	"kind":      "Kind",
+	"folder":    "FolderId",
+	"tags":      specFieldPrefix + "tags",
+	"createdBy": "CreatedBy",
+}
+
+// searchContinueToken is the next page token of a search.
+// Sorted searches continue after the sort values of the last hit, others continue from the offset.
+type searchContinueToken struct {
+	Offset int64    `json:"o,omitempty"`
+	After  []string `json:"a,omitempty"`
+}
+
+func (t searchContinueToken) String() string {
+	b, _ := json.Marshal(t)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func getSearchContinueToken(token string) (*searchContinueToken, error) {
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding search continue token")
+	}
+
+	t := &searchContinueToken{}
+	err = json.Unmarshal(decoded, t)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling search continue token")
+	}
+	return t, nil
 }
 
 // Count returns the total doc count
@@ -437,7 +642,7 @@ func (i *Index) getShard(tenant string) (*Shard, error) {
 		return shard, nil
 	}
 
-	index, path, err := i.createIndex()
+	index, path, err := i.createIndex(tenant)
 	if err != nil {
 		return &Shard{}, err
 	}
@@ -447,28 +652,61 @@ func (i *Index) getShard(tenant string) (*Shard, error) {
 		path:  path,
 		batch: index.NewBatch(),
 	}
+	if path != "" {
+		rv, err := index.GetInternal(indexResourceVersionKey)
+		if err != nil {
+			return &Shard{}, err
+		}
+		if len(rv) > 0 {
+			shard.rv, err = strconv.ParseInt(string(rv), 10, 64)
+			if err != nil {
+				return &Shard{}, err
+			}
+		}
+	}
 	i.shards[tenant] = shard
 
 	return shard, nil
 }
 
-func (i *Index) createIndex() (bleve.Index, string, error) {
+func (i *Index) createIndex(tenant string) (bleve.Index, string, error) {
 	if i.opts.IndexDir == "" {
 		return createInMemoryIndex()
 	}
-	return createFileIndex(i.opts.IndexDir)
+	return openFileIndex(filepath.Join(i.opts.IndexDir, tenant))
 }
 
 var mappings = createIndexMappings()
 
 // less memory intensive alternative for larger indexes with less tenants (on-prem)
-func createFileIndex(path string) (bleve.Index, string, error) {
-	indexPath := filepath.Join(path, uuid.New().String())
-	index, err := bleve.New(indexPath, mappings)
+// The index is reused across restarts, unless it was created with different mappings.
+func openFileIndex(indexPath string) (bleve.Index, string, error) {
+	index, err := bleve.Open(indexPath)
+	if err == nil {
+		version, err := index.GetInternal(indexMappingVersionKey)
+		if err == nil && string(version) == indexMappingVersion {
+			return index, indexPath, nil
+		}
+		// the mappings changed, so the index has to be rebuilt
+		if err := index.Close(); err != nil {
+			return nil, "", err
+		}
+		if err := os.RemoveAll(indexPath); err != nil {
+			return nil, "", err
+		}
+	} else if !errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		return nil, "", err
+	}
+
+	index, err = bleve.New(indexPath, mappings)
 	if err != nil {
-		golog.Fatalf("Failed to create index: %v", err)
+		return nil, "", fmt.Errorf("failed to create index: %w", err)
 	}
-	return index, indexPath, err
+	err = index.SetInternal(indexMappingVersionKey, []byte(indexMappingVersion))
+	if err != nil {
+		return nil, "", err
+	}
+	return index, indexPath, nil
 }
 
 // faster indexing when there are many tenants with smaller batches (cloud)
@@ -516,22 +754,42 @@ func fetchResourceTypes() []*IndexerListOptions {
 	}
 }
 
+// sortFields maps the sortable top level fields to their indexed field
+var sortFields = map[string]string{
+	"kind":      "Kind",
+	"name":      "Name",
+	"folder":    "FolderId",
+	"createdAt": "CreatedAt",
+	"createdBy": "CreatedBy",
+	"updatedAt": "UpdatedAt",
+	"updatedBy": "UpdatedBy",
+}
+
+// getSortFields returns the sort order of the search. The document id is always
+// added last, so results with the same sort values are paginated consistently.
 func getSortFields(request *SearchRequest) []string {
-	sorting := make([]string, 0, len(request.SortBy))
+	sorting := make([]string, 0, len(request.SortBy)+1)
 	for _, sort := range request.SortBy {
+		descending := strings.HasPrefix(sort, descendingPrefix)
+		sortOrder := ""
+		if descending {
+			sortOrder = descendingPrefix
+		}
 		if IsSpecField(sort) {
-			descending := strings.HasPrefix(sort, descendingPrefix)
 			sort = strings.TrimPrefix(sort, descendingPrefix)
-			sortOrder := ""
-			if descending {
-				sortOrder = descendingPrefix
-			}
 			sorting = append(sorting, sortOrder+specFieldPrefix+sort)
 			continue
 		}
+		if field, ok := sortFields[strings.TrimPrefix(sort, descendingPrefix)]; ok {
+			sorting = append(sorting, sortOrder+field)
+			continue
+		}
 		sorting = append(sorting, sort)
 	}
-	return sorting
+	if len(sorting) == 0 {
+		sorting = append(sorting, "-_score")
+	}
+	return append(sorting, "_id")
 }
 
 func getTermFacets(f *search.TermFacets) []*search.TermFacet {
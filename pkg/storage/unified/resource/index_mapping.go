@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
 	"github.com/blevesearch/bleve/v2/mapping"
 	"github.com/blevesearch/bleve/v2/search"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
@@ -26,8 +27,11 @@ type IndexedResource struct {
 }
 
 type IndexResults struct {
-	Values []IndexedResource
-	Groups []*Group
+	Values        []IndexedResource
+	Groups        []*Group
+	Facets        map[string]*FacetResult
+	TotalHits     int64
+	NextPageToken string
 }
 
 func (ir IndexedResource) FromSearchHit(hit *search.DocumentMatch) IndexedResource {
@@ -41,6 +45,7 @@ func (ir IndexedResource) FromSearchHit(hit *search.DocumentMatch) IndexedResour
 	ir.UpdatedAt = fieldValue("UpdatedAt", hit)
 	ir.UpdatedBy = fieldValue("UpdatedBy", hit)
 	ir.Title = fieldValue("Title", hit)
+	ir.FolderId = fieldValue("FolderId", hit)
 
 	// add indexed spec fields to search results
 	specResult := map[string]any{}
@@ -95,6 +100,7 @@ func NewIndexedResource(rawResource []byte) (*IndexedResource, error) {
 		ir.UpdatedAt = ir.CreatedAt
 	}
 	ir.UpdatedBy = meta.GetUpdatedBy()
+	ir.FolderId = meta.GetFolder()
 	spec, err := meta.GetSpec()
 	if err != nil {
 		return nil, err
@@ -132,10 +138,10 @@ func createIndexMappingForKind(resourceKind string) *mapping.DocumentMapping {
 		"Name":      bleve.NewTextFieldMapping(),
 		"Title":     bleve.NewTextFieldMapping(),
 		"CreatedAt": bleve.NewDateTimeFieldMapping(),
-		"CreatedBy": bleve.NewTextFieldMapping(),
+		"CreatedBy": newKeywordFieldMapping(), // identities are faceted as a whole
 		"UpdatedAt": bleve.NewDateTimeFieldMapping(),
-		"UpdatedBy": bleve.NewTextFieldMapping(),
-		"FolderId":  bleve.NewTextFieldMapping(),
+		"UpdatedBy": newKeywordFieldMapping(),
+		"FolderId":  newKeywordFieldMapping(), // folder uids are faceted as a whole
 	}
 
 	// Spec is different for all resources, so we need to generate the spec mapping based on the kind
@@ -155,6 +161,12 @@ func createIndexMappingForKind(resourceKind string) *mapping.DocumentMapping {
 	return objectMapping
 }
 
+func newKeywordFieldMapping() *mapping.FieldMapping {
+	m := bleve.NewTextFieldMapping()
+	m.Analyzer = keyword.Name
+	return m
+}
+
 type SpecFieldMapping struct {
 	Field string
 	Type  string
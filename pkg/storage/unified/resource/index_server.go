@@ -32,7 +32,11 @@ func (is *IndexServer) Search(ctx context.Context, req *SearchRequest) (*SearchR
 	if err != nil {
 		return nil, err
 	}
-	res := &SearchResponse{}
+	res := &SearchResponse{
+		Facets:        results.Facets,
+		TotalHits:     results.TotalHits,
+		NextPageToken: results.NextPageToken,
+	}
 	for _, r := range results.Values {
 		resJsonBytes, err := json.Marshal(r)
 		if err != nil {
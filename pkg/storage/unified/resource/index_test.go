@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -137,6 +138,112 @@ func TestSort(t *testing.T) {
 	assert.NotEqual(t, "dashboard-a", val.Spec["title"])
 }
 
+func TestSearchFacets(t *testing.T) {
+	dashboard := readTestData(t, "dashboard-resource.json")
+	folder := readTestData(t, "folder-resource.json")
+	playlist := readTestData(t, "playlist-resource.json")
+	list := &ListResponse{Items: []*ResourceWrapper{{Value: dashboard}, {Value: folder}, {Value: playlist}}}
+	index := newTestIndex(t, 1)
+
+	err := index.writeBatch(testContext, list)
+	require.NoError(t, err)
+
+	req := &SearchRequest{Query: "*", Tenant: testTenant, Limit: 10, Facet: []*FacetRequest{
+		{Field: "kind", Limit: 10},
+		{Field: "createdBy", Limit: 10},
+	}}
+	results, err := index.Search(testContext, req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), results.TotalHits)
+
+	require.Contains(t, results.Facets, "kind")
+	assert.Equal(t, int64(3), results.Facets["kind"].Total)
+	assert.Len(t, results.Facets["kind"].Terms, 3)
+
+	require.Contains(t, results.Facets, "createdBy")
+	assert.Equal(t, []*Group{
+		{Name: "user:1", Count: 2},
+		{Name: "user:be2g71ke8yoe8b", Count: 1},
+	}, results.Facets["createdBy"].Terms)
+
+	req = &SearchRequest{Query: "*", Tenant: testTenant, Facet: []*FacetRequest{{Field: "unknown"}}}
+	_, err = index.Search(testContext, req)
+	require.Error(t, err)
+}
+
+func TestSearchPagination(t *testing.T) {
+	// 20 records fill the last page, it must not be followed by an empty one
+	for records, wantPages := range map[int]int{25: 3, 20: 2} {
+		folders, _ := simulateFolders(records)
+		list := &ListResponse{Items: []*ResourceWrapper{}}
+		for _, f := range folders {
+			list.Items = append(list.Items, &ResourceWrapper{Value: []byte(f)})
+		}
+		index := newTestIndex(t, 1)
+
+		err := index.writeBatch(testContext, list)
+		require.NoError(t, err)
+
+		for _, sortBy := range [][]string{nil, {"name"}} {
+			seen := map[string]bool{}
+			req := &SearchRequest{Query: "*", Tenant: testTenant, Limit: 10, SortBy: sortBy}
+			pages := 0
+			for {
+				results, err := index.Search(testContext, req)
+				require.NoError(t, err)
+				assert.Equal(t, int64(records), results.TotalHits)
+				assert.NotEmpty(t, results.Values)
+				pages++
+				for _, v := range results.Values {
+					seen[v.Uid] = true
+				}
+				if results.NextPageToken == "" {
+					break
+				}
+				req.NextPageToken = results.NextPageToken
+			}
+			assert.Equal(t, wantPages, pages, "records: %d, sort: %v", records, sortBy)
+			assert.Len(t, seen, records)
+		}
+	}
+}
+
+func TestPersistentIndex(t *testing.T) {
+	dir := t.TempDir()
+	dashboard := readTestData(t, "dashboard-resource.json")
+	folder := readTestData(t, "folder-resource.json")
+	list := &ListResponse{Items: []*ResourceWrapper{{Value: dashboard}, {Value: folder}}}
+
+	index := newTestIndex(t, 1)
+	index.opts.IndexDir = dir
+	err := index.writeBatch(testContext, list)
+	require.NoError(t, err)
+
+	shard, err := index.getShard(testTenant)
+	require.NoError(t, err)
+	require.NoError(t, shard.setResourceVersion(1234))
+	require.NoError(t, shard.index.Close())
+
+	// the index is loaded from disk with the last indexed resource version
+	index = newTestIndex(t, 1)
+	index.opts.IndexDir = dir
+	shard, err = index.getShard(testTenant)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, testTenant), shard.path)
+	assert.Equal(t, int64(1234), shard.ResourceVersion())
+	assertCountEquals(t, index, 2)
+
+	// resources that are not listed anymore are removed during the catch up
+	folderRes, err := NewIndexedResource(folder)
+	require.NoError(t, err)
+	err = index.deleteUnseen(shard, map[string]bool{folderRes.Uid: true})
+	require.NoError(t, err)
+	err = index.IndexBatches(testContext, 1, []string{testTenant})
+	require.NoError(t, err)
+	assertCountEquals(t, index, 1)
+	require.NoError(t, shard.index.Close())
+}
+
 func TestIndexBatch(t *testing.T) {
 	index := newTestIndex(t, 1000)
 
@@ -0,0 +1,134 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Cursor is the latest resource version delivered to a sink, for each group and resource.
+type Cursor map[string]map[string]int64
+
+// Get returns the resource version delivered for a group and resource, or 0
+func (c Cursor) Get(group, resource string) int64 {
+	return c[group][resource]
+}
+
+// Set records the resource version delivered for a group and resource
+func (c Cursor) Set(group, resource string, rv int64) {
+	if c[group] == nil {
+		c[group] = map[string]int64{}
+	}
+	c[group][resource] = rv
+}
+
+func (c Cursor) clone() Cursor {
+	out := Cursor{}
+	for group, items := range c {
+		for resource, rv := range items {
+			out.Set(group, resource, rv)
+		}
+	}
+	return out
+}
+
+// CursorStore persists the sink cursors.
+type CursorStore interface {
+	Load(ctx context.Context, sink string) (Cursor, error)
+	Save(ctx context.Context, sink string, cursor Cursor) error
+}
+
+// FileCursorStore keeps every cursor in a JSON file of a directory.
+type FileCursorStore struct {
+	Dir string
+}
+
+var _ CursorStore = (*FileCursorStore)(nil)
+
+func (s *FileCursorStore) path(sink string) string {
+	return filepath.Join(s.Dir, sink+".cursor.json")
+}
+
+// Load returns the saved cursor, or an empty cursor if none was saved yet
+func (s *FileCursorStore) Load(_ context.Context, sink string) (Cursor, error) {
+	// nolint:gosec
+	data, err := os.ReadFile(s.path(sink))
+	if errors.Is(err, os.ErrNotExist) {
+		return Cursor{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cursor := Cursor{}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor for sink %s: %w", sink, err)
+	}
+	return cursor, nil
+}
+
+// Save writes the cursor atomically, so a crash never leaves a partial cursor behind
+func (s *FileCursorStore) Save(_ context.Context, sink string, cursor Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, sink+".cursor-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(sink))
+}
+
+// KVStore is the subset of a key-value store used by the KVCursorStore.
+type KVStore interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key string, value string) error
+}
+
+// KVCursorStore keeps the cursors in a key-value store shared by every instance,
+// so another instance can resume the delivery where the previous one stopped.
+type KVCursorStore struct {
+	KV KVStore
+}
+
+var _ CursorStore = (*KVCursorStore)(nil)
+
+// Load returns the saved cursor, or an empty cursor if none was saved yet
+func (s *KVCursorStore) Load(ctx context.Context, sink string) (Cursor, error) {
+	value, ok, err := s.KV.Get(ctx, sink)
+	if err != nil || !ok {
+		return Cursor{}, err
+	}
+	cursor := Cursor{}
+	if err := json.Unmarshal([]byte(value), &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor for sink %s: %w", sink, err)
+	}
+	return cursor, nil
+}
+
+func (s *KVCursorStore) Save(ctx context.Context, sink string, cursor Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return s.KV.Set(ctx, sink, string(data))
+}
@@ -0,0 +1,217 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+const defaultCursorSaveInterval = 5 * time.Second
+
+// DispatcherOptions configures a Dispatcher
+type DispatcherOptions struct {
+	Backend resource.StorageBackend
+	Cursors CursorStore
+	Sinks   []Sink
+	// Filters limits each sink (by name) to some "group/resource"
+	Filters map[string]Filter
+
+	// How often the cursors are persisted while events are delivered
+	CursorSaveInterval time.Duration
+	// Retry policy for failed deliveries (retried until the dispatcher stops)
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	Reg prometheus.Registerer
+}
+
+// Dispatcher feeds the write events of a storage backend to the sinks.
+type Dispatcher struct {
+	opts    DispatcherOptions
+	sinks   []*sinkState
+	log     log.Logger
+	metrics *dispatcherMetrics
+}
+
+type sinkState struct {
+	sink      Sink
+	filter    Filter
+	cursor    Cursor
+	dirty     bool
+	lastSaved time.Time
+}
+
+type dispatcherMetrics struct {
+	delivered *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+}
+
+func NewDispatcher(opts DispatcherOptions) (*Dispatcher, error) {
+	if opts.Backend == nil {
+		return nil, errors.New("missing backend")
+	}
+	if opts.Cursors == nil {
+		return nil, errors.New("missing cursor store")
+	}
+	if opts.CursorSaveInterval == 0 {
+		opts.CursorSaveInterval = defaultCursorSaveInterval
+	}
+	if opts.MinBackoff == 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = time.Minute
+	}
+
+	d := &Dispatcher{
+		opts: opts,
+		log:  log.New("unified-storage-sink"),
+		metrics: &dispatcherMetrics{
+			delivered: promauto.With(opts.Reg).NewCounterVec(prometheus.CounterOpts{
+				Namespace: "storage_server",
+				Name:      "sink_events_delivered_total",
+				Help:      "Number of write events delivered to a sink",
+			}, []string{"sink"}),
+			failed: promauto.With(opts.Reg).NewCounterVec(prometheus.CounterOpts{
+				Namespace: "storage_server",
+				Name:      "sink_delivery_failures_total",
+				Help:      "Number of failed deliveries to a sink (failed deliveries are retried)",
+			}, []string{"sink"}),
+		},
+	}
+
+	names := map[string]bool{}
+	for _, s := range opts.Sinks {
+		if names[s.Name()] {
+			return nil, fmt.Errorf("duplicate sink name %q", s.Name())
+		}
+		names[s.Name()] = true
+		d.sinks = append(d.sinks, &sinkState{sink: s, filter: opts.Filters[s.Name()]})
+	}
+	return d, nil
+}
+
+// Run delivers events until the context is canceled or the backend stops streaming.
+// Every sink has its own stream and cursor, so a failing sink does not hold back the others.
+// Run can be called again after it returned, the sinks are only closed by Close.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	errs := make([]error, len(d.sinks))
+	var wg sync.WaitGroup
+	for i, s := range d.sinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = d.runSink(ctx, s)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) runSink(ctx context.Context, s *sinkState) error {
+	cursor, err := d.opts.Cursors.Load(ctx, s.sink.Name())
+	if err != nil {
+		return fmt.Errorf("load cursor of sink %s: %w", s.sink.Name(), err)
+	}
+	s.cursor = cursor
+
+	events, err := d.watch(ctx, s)
+	if err != nil {
+		return fmt.Errorf("watch events for sink %s: %w", s.sink.Name(), err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.saveCursor(s, true)
+			return nil
+		case we, ok := <-events:
+			if !ok {
+				d.saveCursor(s, true)
+				return nil
+			}
+			if we == nil || we.Key == nil {
+				continue
+			}
+			if err := d.deliver(ctx, s, newEvent(we)); err != nil {
+				// only happens when the context is canceled
+				d.saveCursor(s, true)
+				return nil
+			}
+			d.saveCursor(s, false)
+		}
+	}
+}
+
+// watch starts the event stream of a sink from its cursor, when the backend supports it
+func (d *Dispatcher) watch(ctx context.Context, s *sinkState) (<-chan *resource.WrittenEvent, error) {
+	replay, ok := d.opts.Backend.(ReplayableBackend)
+	if ok && len(s.cursor) > 0 {
+		d.log.Info("replaying write events for sink", "sink", s.sink.Name(), "since", s.cursor)
+		return replay.WatchWriteEventsSince(ctx, s.cursor.clone())
+	}
+	return d.opts.Backend.WatchWriteEvents(ctx)
+}
+
+// deliver sends the event to a sink, retrying until it succeeds.
+// Events already delivered before a restart are skipped.
+func (d *Dispatcher) deliver(ctx context.Context, s *sinkState, event *Event) error {
+	if event.ResourceVersion <= s.cursor.Get(event.Group, event.Resource) {
+		return nil
+	}
+
+	if s.filter.Matches(event) {
+		boff := backoff.New(ctx, backoff.Config{
+			MinBackoff: d.opts.MinBackoff,
+			MaxBackoff: d.opts.MaxBackoff,
+		})
+		for {
+			err := s.sink.Send(ctx, event)
+			if err == nil {
+				break
+			}
+			d.metrics.failed.WithLabelValues(s.sink.Name()).Inc()
+			d.log.Warn("failed to deliver event to sink", "sink", s.sink.Name(), "key", event.Key(), "rv", event.ResourceVersion, "retries", boff.NumRetries(), "err", err)
+			boff.Wait()
+			if !boff.Ongoing() {
+				return boff.Err()
+			}
+		}
+		d.metrics.delivered.WithLabelValues(s.sink.Name()).Inc()
+	}
+
+	s.cursor.Set(event.Group, event.Resource, event.ResourceVersion)
+	s.dirty = true
+	return nil
+}
+
+func (d *Dispatcher) saveCursor(s *sinkState, force bool) {
+	if !s.dirty || (!force && time.Since(s.lastSaved) < d.opts.CursorSaveInterval) {
+		return
+	}
+	// the context may already be canceled, but the cursor should still be saved
+	if err := d.opts.Cursors.Save(context.Background(), s.sink.Name(), s.cursor.clone()); err != nil {
+		d.log.Error("failed to save sink cursor", "sink", s.sink.Name(), "err", err)
+		return
+	}
+	s.dirty = false
+	s.lastSaved = time.Now()
+}
+
+// Close closes the sinks, once Run returned
+func (d *Dispatcher) Close() {
+	for _, s := range d.sinks {
+		if err := s.sink.Close(); err != nil {
+			d.log.Warn("failed to close sink", "sink", s.sink.Name(), "err", err)
+		}
+	}
+}
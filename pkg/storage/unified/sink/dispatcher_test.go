@@ -0,0 +1,321 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+type fakeBackend struct {
+	resource.StorageBackend
+
+	mu     sync.Mutex
+	events []*resource.WrittenEvent
+	since  map[string]map[string]int64
+}
+
+// stream sends all the events to every watcher
+func (f *fakeBackend) stream() <-chan *resource.WrittenEvent {
+	events := make(chan *resource.WrittenEvent, len(f.events))
+	for _, e := range f.events {
+		events <- e
+	}
+	close(events)
+	return events
+}
+
+func (f *fakeBackend) WatchWriteEvents(context.Context) (<-chan *resource.WrittenEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stream(), nil
+}
+
+func (f *fakeBackend) WatchWriteEventsSince(_ context.Context, since map[string]map[string]int64) (<-chan *resource.WrittenEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.since = since
+	return f.stream(), nil
+}
+
+type fakeSink struct {
+	name     string
+	mu       sync.Mutex
+	failures int
+	events   []*Event
+	closed   bool
+}
+
+func (f *fakeSink) Name() string { return f.name }
+
+func (f *fakeSink) Send(_ context.Context, e *Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unavailable")
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+func (f *fakeSink) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeSink) delivered() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.events)
+}
+
+func writtenEvent(group, name string, rv int64) *resource.WrittenEvent {
+	return &resource.WrittenEvent{
+		WriteEvent: resource.WriteEvent{
+			Type:  resource.WatchEvent_ADDED,
+			Key:   &resource.ResourceKey{Namespace: "default", Group: group, Resource: "things", Name: name},
+			Value: []byte(`{"kind":"Thing"}`),
+		},
+		ResourceVersion: rv,
+	}
+}
+
+func runDispatcher(t *testing.T, backend *fakeBackend, cursors CursorStore, sinks []Sink, filters map[string]Filter, events ...*resource.WrittenEvent) {
+	t.Helper()
+	d, err := NewDispatcher(DispatcherOptions{
+		Backend:    backend,
+		Cursors:    cursors,
+		Sinks:      sinks,
+		Filters:    filters,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	backend.events = events
+	require.NoError(t, d.Run(context.Background()))
+	d.Close()
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("delivers events at least once and saves the cursor", func(t *testing.T) {
+		cursors := &FileCursorStore{Dir: t.TempDir()}
+		s := &fakeSink{name: "test", failures: 2}
+		backend := &fakeBackend{}
+
+		runDispatcher(t, backend, cursors, []Sink{s}, nil,
+			writtenEvent("a.grafana.app", "one", 10),
+			writtenEvent("a.grafana.app", "two", 20),
+		)
+		require.True(t, s.closed)
+		require.Len(t, s.events, 2)
+		require.Equal(t, "default/a.grafana.app/things/one", s.events[0].Key())
+		require.JSONEq(t, `{"kind":"Thing"}`, string(s.events[0].Object))
+		require.Nil(t, backend.since, "nothing to replay on the first run")
+
+		cursor, err := cursors.Load(context.Background(), "test")
+		require.NoError(t, err)
+		require.Equal(t, int64(20), cursor.Get("a.grafana.app", "things"))
+
+		// after a restart, the stream is replayed from the cursor and old events are skipped
+		s = &fakeSink{name: "test"}
+		runDispatcher(t, backend, cursors, []Sink{s}, nil,
+			writtenEvent("a.grafana.app", "two", 20),
+			writtenEvent("a.grafana.app", "three", 30),
+		)
+		require.Equal(t, map[string]map[string]int64{"a.grafana.app": {"things": 20}}, backend.since)
+		require.Len(t, s.events, 1)
+		require.Equal(t, "three", s.events[0].Name)
+	})
+
+	t.Run("filters events per sink", func(t *testing.T) {
+		all := &fakeSink{name: "all"}
+		filtered := &fakeSink{name: "filtered"}
+		runDispatcher(t, &fakeBackend{}, &FileCursorStore{Dir: t.TempDir()}, []Sink{all, filtered},
+			map[string]Filter{"filtered": {"b.grafana.app/things"}},
+			writtenEvent("a.grafana.app", "one", 10),
+			writtenEvent("b.grafana.app", "two", 20),
+		)
+		require.Len(t, all.events, 2)
+		require.Len(t, filtered.events, 1)
+		require.Equal(t, "b.grafana.app", filtered.events[0].Group)
+	})
+
+	t.Run("a failing sink does not hold back the others", func(t *testing.T) {
+		healthy := &fakeSink{name: "healthy"}
+		failing := &fakeSink{name: "failing", failures: math.MaxInt}
+		backend := &fakeBackend{events: []*resource.WrittenEvent{
+			writtenEvent("a.grafana.app", "one", 10),
+			writtenEvent("a.grafana.app", "two", 20),
+		}}
+		cursors := &FileCursorStore{Dir: t.TempDir()}
+		d, err := NewDispatcher(DispatcherOptions{
+			Backend:    backend,
+			Cursors:    cursors,
+			Sinks:      []Sink{failing, healthy},
+			MinBackoff: time.Millisecond,
+			MaxBackoff: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- d.Run(ctx) }()
+
+		require.Eventually(t, func() bool { return healthy.delivered() == 2 }, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+		require.Zero(t, failing.delivered())
+
+		// each sink has its own cursor
+		cursor, err := cursors.Load(context.Background(), "healthy")
+		require.NoError(t, err)
+		require.Equal(t, int64(20), cursor.Get("a.grafana.app", "things"))
+		cursor, err = cursors.Load(context.Background(), "failing")
+		require.NoError(t, err)
+		require.Empty(t, cursor)
+	})
+
+	t.Run("duplicate sink names", func(t *testing.T) {
+		_, err := NewDispatcher(DispatcherOptions{
+			Backend: &fakeBackend{},
+			Cursors: &FileCursorStore{Dir: t.TempDir()},
+			Sinks:   []Sink{&fakeSink{name: "a"}, &fakeSink{name: "a"}},
+		})
+		require.ErrorContains(t, err, "duplicate sink name")
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "out.ndjson")
+	s, err := NewFileSink("file", path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(context.Background(), &Event{Name: "one", ResourceVersion: 1}))
+	require.NoError(t, s.Send(context.Background(), &Event{Name: "two", ResourceVersion: 2}))
+	require.NoError(t, s.Close())
+
+	// nolint:gosec
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := &Event{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), e))
+		names = append(names, e.Name)
+	}
+	require.Equal(t, []string{"one", "two"}, names)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []*Event
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		e := &Event{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(e))
+		received = append(received, e)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	s, err := NewWebhookSink("hook", srv.URL, map[string]string{"Authorization": "Bearer token"}, time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(context.Background(), &Event{Name: "one"}))
+	status = http.StatusServiceUnavailable
+	require.ErrorContains(t, s.Send(context.Background(), &Event{Name: "two"}), "503")
+	require.Len(t, received, 2)
+	require.NoError(t, s.Close())
+}
+
+type fakeProducer struct {
+	topic string
+	key   string
+}
+
+func (p *fakeProducer) Produce(_ context.Context, topic string, key, _ []byte) error {
+	p.topic = topic
+	p.key = string(key)
+	return nil
+}
+
+func (p *fakeProducer) Close() error { return nil }
+
+type fakeKV map[string]string
+
+func (f fakeKV) Get(_ context.Context, key string) (string, bool, error) {
+	v, ok := f[key]
+	return v, ok, nil
+}
+
+func (f fakeKV) Set(_ context.Context, key string, value string) error {
+	f[key] = value
+	return nil
+}
+
+func TestKVCursorStore(t *testing.T) {
+	store := &KVCursorStore{KV: fakeKV{}}
+
+	cursor, err := store.Load(context.Background(), "test")
+	require.NoError(t, err)
+	require.Empty(t, cursor)
+
+	cursor.Set("a.grafana.app", "things", 10)
+	require.NoError(t, store.Save(context.Background(), "test", cursor))
+
+	cursor, err = store.Load(context.Background(), "test")
+	require.NoError(t, err)
+	require.Equal(t, int64(10), cursor.Get("a.grafana.app", "things"))
+}
+
+func TestKafkaSink(t *testing.T) {
+	p := &fakeProducer{}
+	s, err := NewKafkaSink("kafka", "grafana-events", p)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(context.Background(), &Event{Namespace: "default", Group: "g", Resource: "r", Name: "n"}))
+	require.Equal(t, "grafana-events", p.topic)
+	require.Equal(t, "default/g/r/n", p.key)
+}
+
+func TestRESTProducer(t *testing.T) {
+	var records restRecords
+	response := `{"offsets":[{"partition":0,"offset":1}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/topics/grafana-events", r.URL.Path)
+		require.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&records))
+		_, _ = w.Write([]byte(response))
+	}))
+	defer srv.Close()
+
+	p, err := NewRESTProducer(srv.URL, nil, time.Second)
+	require.NoError(t, err)
+	s, err := NewKafkaSink("kafka", "grafana-events", p)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(context.Background(), &Event{Namespace: "default", Group: "g", Resource: "r", Name: "n"}))
+	require.Len(t, records.Records, 1)
+	require.Equal(t, "default/g/r/n", records.Records[0].Key)
+
+	response = `{"offsets":[{"error_code":50003,"error":"broker unavailable"}]}`
+	require.ErrorContains(t, s.Send(context.Background(), &Event{Name: "n"}), "broker unavailable")
+	require.NoError(t, s.Close())
+}
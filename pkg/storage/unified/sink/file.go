@@ -0,0 +1,60 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink appends every event as a line of JSON (NDJSON) to a file.
+// The file is synced after each event, so an acknowledged event survives a crash.
+type FileSink struct {
+	name string
+	mu   sync.Mutex
+	f    *os.File
+}
+
+var _ Sink = (*FileSink)(nil)
+
+func NewFileSink(name, path string) (*FileSink, error) {
+	if path == "" {
+		return nil, errors.New("file sink requires a path")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+	// nolint:gosec
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{name: name, f: f}, nil
+}
+
+func (s *FileSink) Name() string {
+	return s.name
+}
+
+func (s *FileSink) Send(_ context.Context, event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.f.Write(line); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
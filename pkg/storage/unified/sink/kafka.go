@@ -0,0 +1,143 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Producer is the subset of a Kafka-compatible client used by the KafkaSink.
+// Produce must only return once the broker acknowledged the message.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+	Close() error
+}
+
+// KafkaSink publishes every event to a topic. The message key is the object key,
+// so all the events of an object land on the same partition and keep their order.
+type KafkaSink struct {
+	name     string
+	topic    string
+	producer Producer
+}
+
+var _ Sink = (*KafkaSink)(nil)
+
+func NewKafkaSink(name, topic string, producer Producer) (*KafkaSink, error) {
+	if topic == "" {
+		return nil, errors.New("kafka sink requires a topic")
+	}
+	if producer == nil {
+		return nil, errors.New("kafka sink requires a producer")
+	}
+	return &KafkaSink{
+		name:     name,
+		topic:    topic,
+		producer: producer,
+	}, nil
+}
+
+func (s *KafkaSink) Name() string {
+	return s.name
+}
+
+func (s *KafkaSink) Send(ctx context.Context, event *Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(ctx, s.topic, []byte(event.Key()), value)
+}
+
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
+
+// RESTProducer produces messages through a Kafka REST proxy (v2 API), so no native client is needed.
+type RESTProducer struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+var _ Producer = (*RESTProducer)(nil)
+
+func NewRESTProducer(proxyURL string, headers map[string]string, timeout time.Duration) (*RESTProducer, error) {
+	if proxyURL == "" {
+		return nil, errors.New("kafka sink requires the url of a REST proxy")
+	}
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &RESTProducer{
+		url:     strings.TrimSuffix(proxyURL, "/"),
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type restRecords struct {
+	Records []restRecord `json:"records"`
+}
+
+type restRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type restOffsets struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Produce returns once the proxy acknowledged the message, the proxy reports per message errors in the offsets
+func (p *RESTProducer) Produce(ctx context.Context, topic string, key, value []byte) error {
+	body, err := json.Marshal(restRecords{Records: []restRecord{{Key: string(key), Value: value}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("kafka REST proxy responded with status %d", res.StatusCode)
+	}
+	offsets := restOffsets{}
+	if err := json.NewDecoder(res.Body).Decode(&offsets); err != nil {
+		return fmt.Errorf("invalid kafka REST proxy response: %w", err)
+	}
+	for _, o := range offsets.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("kafka REST proxy failed to produce the message: %s", o.Error)
+		}
+	}
+	return nil
+}
+
+func (p *RESTProducer) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,87 @@
+// Package sink forwards the write events of unified storage to external systems.
+//
+// Every sink receives the events in resource version order and keeps a persisted cursor,
+// so events are delivered at least once, including across restarts.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// Sink delivers write events to an external system.
+type Sink interface {
+	// Name uniquely identifies the sink, and is used to store its cursor
+	Name() string
+
+	// Send delivers a single event. Returning an error will retry the same event later.
+	Send(ctx context.Context, event *Event) error
+
+	// Close releases the resources held by the sink
+	Close() error
+}
+
+// ReplayableBackend can stream the write events that happened after the given resource versions.
+// Backends that do not implement it only deliver the events written after the dispatcher started.
+type ReplayableBackend interface {
+	WatchWriteEventsSince(ctx context.Context, since map[string]map[string]int64) (<-chan *resource.WrittenEvent, error)
+}
+
+// Event is the representation of a write event sent to the sinks.
+type Event struct {
+	Type            string          `json:"type"`
+	Namespace       string          `json:"namespace"`
+	Group           string          `json:"group"`
+	Resource        string          `json:"resource"`
+	Name            string          `json:"name"`
+	Folder          string          `json:"folder,omitempty"`
+	ResourceVersion int64           `json:"resourceVersion"`
+	PreviousRV      int64           `json:"previousResourceVersion,omitempty"`
+	Timestamp       int64           `json:"timestamp,omitempty"`
+	Object          json.RawMessage `json:"object,omitempty"`
+}
+
+// Key identifies the object of the event
+func (e *Event) Key() string {
+	return fmt.Sprintf("%s/%s/%s/%s", e.Namespace, e.Group, e.Resource, e.Name)
+}
+
+func newEvent(we *resource.WrittenEvent) *Event {
+	e := &Event{
+		Type:            we.Type.String(),
+		Folder:          we.Folder,
+		ResourceVersion: we.ResourceVersion,
+		PreviousRV:      we.PreviousRV,
+		Timestamp:       we.Timestamp,
+	}
+	if we.Key != nil {
+		e.Namespace = we.Key.Namespace
+		e.Group = we.Key.Group
+		e.Resource = we.Key.Resource
+		e.Name = we.Key.Name
+	}
+	if json.Valid(we.Value) {
+		e.Object = we.Value
+	}
+	return e
+}
+
+// Filter limits a sink to some resources. An empty filter accepts every event.
+type Filter []string
+
+// Matches returns true if the event's "group/resource" is part of the filter
+func (f Filter) Matches(e *Event) bool {
+	if len(f) == 0 {
+		return true
+	}
+	gr := e.Group + "/" + e.Resource
+	for _, v := range f {
+		if v == gr {
+			return true
+		}
+	}
+	return false
+}
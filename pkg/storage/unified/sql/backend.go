@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
@@ -37,6 +38,15 @@ type BackendOptions struct {
 	DBProvider      db.DBProvider
 	Tracer          trace.Tracer
 	PollingInterval time.Duration
+
+	// Retention policies for the resource history, applied every CompactionInterval
+	RetentionPolicies  []RetentionPolicy
+	CompactionInterval time.Duration
+	Reg                prometheus.Registerer
+
+	// Compress the stored values (zstd), when they are at least ValueCompressionMinSize bytes
+	ValueCompression        string
+	ValueCompressionMinSize int
 }
 
 func NewBackend(opts BackendOptions) (Backend, error) {
@@ -52,13 +62,36 @@ func NewBackend(opts BackendOptions) (Backend, error) {
 	if pollingInterval == 0 {
 		pollingInterval = defaultPollingInterval
 	}
+	compactionInterval := opts.CompactionInterval
+	if compactionInterval == 0 {
+		compactionInterval = defaultCompactionInterval
+	}
+	var retention []RetentionPolicy
+	for _, policy := range opts.RetentionPolicies {
+		if err := policy.validate(); err != nil {
+			cancel()
+			return nil, err
+		}
+		if policy.enabled() {
+			retention = append(retention, policy)
+		}
+	}
+	codec, err := newValueCodec(opts.ValueCompression, opts.ValueCompressionMinSize)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	return &backend{
-		done:            ctx.Done(),
-		cancel:          cancel,
-		log:             log.New("sql-resource-server"),
-		tracer:          opts.Tracer,
-		dbProvider:      opts.DBProvider,
-		pollingInterval: pollingInterval,
+		done:               ctx.Done(),
+		cancel:             cancel,
+		log:                log.New("sql-resource-server"),
+		tracer:             opts.Tracer,
+		dbProvider:         opts.DBProvider,
+		pollingInterval:    pollingInterval,
+		retention:          retention,
+		compactionInterval: compactionInterval,
+		compactionMetrics:  newCompactionMetrics(opts.Reg),
+		codec:              codec,
 	}, nil
 }
 
@@ -81,6 +114,15 @@ type backend struct {
 	// watch streaming
 	//stream chan *resource.WatchEvent
 	pollingInterval time.Duration
+	watchCursors    watchCursors
+
+	// history compaction
+	retention          []RetentionPolicy
+	compactionInterval time.Duration
+	compactionMetrics  *compactionMetrics
+
+	// stored values compression
+	codec *valueCodec
 }
 
 func (b *backend) Init(ctx context.Context) error {
@@ -103,7 +145,14 @@ func (b *backend) initLocked(ctx context.Context) error {
 		return fmt.Errorf("no dialect for driver %q", driverName)
 	}
 
-	return b.db.PingContext(ctx)
+	if err := b.db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	if len(b.retention) > 0 {
+		go b.compactor()
+	}
+	return nil
 }
 
 func (b *backend) IsHealthy(ctx context.Context, r *resource.HealthCheckRequest) (*resource.HealthCheckResponse, error) {
@@ -166,179 +215,267 @@ func (b *backend) create(ctx context.Context, event resource.WriteEvent) (int64,
 	ctx, span := b.tracer.Start(ctx, tracePrefix+"Create")
 	defer span.End()
 	var newVersion int64
-	guid := uuid.New().String()
 	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
-		folder := ""
-		if event.Object != nil {
-			folder = event.Object.GetFolder()
-		}
-		// 1. Insert into resource
-		if _, err := dbutil.Exec(ctx, tx, sqlResourceInsert, sqlResourceRequest{
-			SQLTemplate: sqltemplate.New(b.dialect),
-			WriteEvent:  event,
-			Folder:      folder,
-			GUID:        guid,
-		}); err != nil {
-			return fmt.Errorf("insert into resource: %w", err)
-		}
+		var err error
+		newVersion, err = b.createLocked(ctx, tx, event)
+		return err
+	})
+	return newVersion, err
+}
 
-		// 2. Insert into resource history
-		if _, err := dbutil.Exec(ctx, tx, sqlResourceHistoryInsert, sqlResourceRequest{
-			SQLTemplate: sqltemplate.New(b.dialect),
-			WriteEvent:  event,
-			Folder:      folder,
-			GUID:        guid,
-		}); err != nil {
-			return fmt.Errorf("insert into resource history: %w", err)
-		}
+// createLocked inserts the resource in an existing transaction
+func (b *backend) createLocked(ctx context.Context, tx db.Tx, event resource.WriteEvent) (int64, error) {
+	guid := uuid.New().String()
+	var err error
+	if event.Value, err = b.codec.encode(event.Value); err != nil {
+		return 0, fmt.Errorf("compress value: %w", err)
+	}
+	folder := ""
+	if event.Object != nil {
+		folder = event.Object.GetFolder()
+	}
+	// 1. Insert into resource
+	if _, err := dbutil.Exec(ctx, tx, sqlResourceInsert, sqlResourceRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		WriteEvent:  event,
+		Folder:      folder,
+		GUID:        guid,
+	}); err != nil {
+		return 0, fmt.Errorf("insert into resource: %w", err)
+	}
 
-		// 3. TODO: Rebuild the whole folder tree structure if we're creating a folder
+	// 2. Insert into resource history
+	if _, err := dbutil.Exec(ctx, tx, sqlResourceHistoryInsert, sqlResourceRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		WriteEvent:  event,
+		Folder:      folder,
+		GUID:        guid,
+	}); err != nil {
+		return 0, fmt.Errorf("insert into resource history: %w", err)
+	}
 
-		// 4. Atomically increment resource version for this kind
-		rv, err := b.resourceVersionAtomicInc(ctx, tx, event.Key)
-		if err != nil {
-			return fmt.Errorf("increment resource version: %w", err)
-		}
+	// 3. TODO: Rebuild the whole folder tree structure if we're creating a folder
 
-		// 5. Update the RV in both resource and resource_history
-		if _, err = dbutil.Exec(ctx, tx, sqlResourceHistoryUpdateRV, sqlResourceUpdateRVRequest{
-			SQLTemplate:     sqltemplate.New(b.dialect),
-			GUID:            guid,
-			ResourceVersion: rv,
-		}); err != nil {
-			return fmt.Errorf("update resource_history rv: %w", err)
-		}
+	// 4. Atomically increment resource version for this kind
+	rv, err := b.resourceVersionAtomicInc(ctx, tx, event.Key)
+	if err != nil {
+		return 0, fmt.Errorf("increment resource version: %w", err)
+	}
 
-		if _, err = dbutil.Exec(ctx, tx, sqlResourceUpdateRV, sqlResourceUpdateRVRequest{
-			SQLTemplate:     sqltemplate.New(b.dialect),
-			GUID:            guid,
-			ResourceVersion: rv,
-		}); err != nil {
-			return fmt.Errorf("update resource rv: %w", err)
-		}
-		newVersion = rv
+	// 5. Update the RV in both resource and resource_history
+	if _, err = dbutil.Exec(ctx, tx, sqlResourceHistoryUpdateRV, sqlResourceUpdateRVRequest{
+		SQLTemplate:     sqltemplate.New(b.dialect),
+		GUID:            guid,
+		ResourceVersion: rv,
+	}); err != nil {
+		return 0, fmt.Errorf("update resource_history rv: %w", err)
+	}
 
-		return nil
-	})
-	return newVersion, err
+	if _, err = dbutil.Exec(ctx, tx, sqlResourceUpdateRV, sqlResourceUpdateRVRequest{
+		SQLTemplate:     sqltemplate.New(b.dialect),
+		GUID:            guid,
+		ResourceVersion: rv,
+	}); err != nil {
+		return 0, fmt.Errorf("update resource rv: %w", err)
+	}
+	return rv, nil
 }
 
 func (b *backend) update(ctx context.Context, event resource.WriteEvent) (int64, error) {
 	ctx, span := b.tracer.Start(ctx, tracePrefix+"Update")
 	defer span.End()
 	var newVersion int64
-	guid := uuid.New().String()
 	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
-		folder := ""
-		if event.Object != nil {
-			folder = event.Object.GetFolder()
-		}
-		// 1. Update resource
-		_, err := dbutil.Exec(ctx, tx, sqlResourceUpdate, sqlResourceRequest{
-			SQLTemplate: sqltemplate.New(b.dialect),
-			WriteEvent:  event,
-			Folder:      folder,
-			GUID:        guid,
-		})
-		if err != nil {
-			return fmt.Errorf("initial resource update: %w", err)
-		}
-
-		// 2. Insert into resource history
-		if _, err := dbutil.Exec(ctx, tx, sqlResourceHistoryInsert, sqlResourceRequest{
-			SQLTemplate: sqltemplate.New(b.dialect),
-			WriteEvent:  event,
-			Folder:      folder,
-			GUID:        guid,
-		}); err != nil {
-			return fmt.Errorf("insert into resource history: %w", err)
-		}
+		var err error
+		newVersion, err = b.updateLocked(ctx, tx, event)
+		return err
+	})
+	return newVersion, err
+}
 
-		// 3. TODO: Rebuild the whole folder tree structure if we're creating a folder
+// updateLocked updates the resource in an existing transaction
+func (b *backend) updateLocked(ctx context.Context, tx db.Tx, event resource.WriteEvent) (int64, error) {
+	guid := uuid.New().String()
+	var err error
+	if event.Value, err = b.codec.encode(event.Value); err != nil {
+		return 0, fmt.Errorf("compress value: %w", err)
+	}
+	folder := ""
+	if event.Object != nil {
+		folder = event.Object.GetFolder()
+	}
+	// 1. Update resource
+	_, err = dbutil.Exec(ctx, tx, sqlResourceUpdate, sqlResourceRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		WriteEvent:  event,
+		Folder:      folder,
+		GUID:        guid,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("initial resource update: %w", err)
+	}
 
-		// 4. Atomically increment resource version for this kind
-		rv, err := b.resourceVersionAtomicInc(ctx, tx, event.Key)
-		if err != nil {
-			return fmt.Errorf("increment resource version: %w", err)
-		}
+	// 2. Insert into resource history
+	if _, err := dbutil.Exec(ctx, tx, sqlResourceHistoryInsert, sqlResourceRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		WriteEvent:  event,
+		Folder:      folder,
+		GUID:        guid,
+	}); err != nil {
+		return 0, fmt.Errorf("insert into resource history: %w", err)
+	}
 
-		// 5. Update the RV in both resource and resource_history
-		if _, err = dbutil.Exec(ctx, tx, sqlResourceHistoryUpdateRV, sqlResourceUpdateRVRequest{
-			SQLTemplate:     sqltemplate.New(b.dialect),
-			GUID:            guid,
-			ResourceVersion: rv,
-		}); err != nil {
-			return fmt.Errorf("update history rv: %w", err)
-		}
+	// 3. TODO: Rebuild the whole folder tree structure if we're creating a folder
 
-		if _, err = dbutil.Exec(ctx, tx, sqlResourceUpdateRV, sqlResourceUpdateRVRequest{
-			SQLTemplate:     sqltemplate.New(b.dialect),
-			GUID:            guid,
-			ResourceVersion: rv,
-		}); err != nil {
-			return fmt.Errorf("update resource rv: %w", err)
-		}
-		newVersion = rv
+	// 4. Atomically increment resource version for this kind
+	rv, err := b.resourceVersionAtomicInc(ctx, tx, event.Key)
+	if err != nil {
+		return 0, fmt.Errorf("increment resource version: %w", err)
+	}
 
-		return nil
-	})
+	// 5. Update the RV in both resource and resource_history
+	if _, err = dbutil.Exec(ctx, tx, sqlResourceHistoryUpdateRV, sqlResourceUpdateRVRequest{
+		SQLTemplate:     sqltemplate.New(b.dialect),
+		GUID:            guid,
+		ResourceVersion: rv,
+	}); err != nil {
+		return 0, fmt.Errorf("update history rv: %w", err)
+	}
 
-	return newVersion, err
+	if _, err = dbutil.Exec(ctx, tx, sqlResourceUpdateRV, sqlResourceUpdateRVRequest{
+		SQLTemplate:     sqltemplate.New(b.dialect),
+		GUID:            guid,
+		ResourceVersion: rv,
+	}); err != nil {
+		return 0, fmt.Errorf("update resource rv: %w", err)
+	}
+	return rv, nil
 }
 
 func (b *backend) delete(ctx context.Context, event resource.WriteEvent) (int64, error) {
 	ctx, span := b.tracer.Start(ctx, tracePrefix+"Delete")
 	defer span.End()
 	var newVersion int64
+	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+		var err error
+		newVersion, err = b.deleteLocked(ctx, tx, event)
+		return err
+	})
+	return newVersion, err
+}
+
+// deleteLocked deletes the resource in an existing transaction
+func (b *backend) deleteLocked(ctx context.Context, tx db.Tx, event resource.WriteEvent) (int64, error) {
 	guid := uuid.New().String()
+	folder := ""
+	if event.Object != nil {
+		folder = event.Object.GetFolder()
+	}
+	// 1. delete from resource
+	_, err := dbutil.Exec(ctx, tx, sqlResourceDelete, sqlResourceRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		WriteEvent:  event,
+		GUID:        guid,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete resource: %w", err)
+	}
 
-	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
-		folder := ""
-		if event.Object != nil {
-			folder = event.Object.GetFolder()
-		}
-		// 1. delete from resource
-		_, err := dbutil.Exec(ctx, tx, sqlResourceDelete, sqlResourceRequest{
-			SQLTemplate: sqltemplate.New(b.dialect),
-			WriteEvent:  event,
-			GUID:        guid,
-		})
-		if err != nil {
-			return fmt.Errorf("delete resource: %w", err)
-		}
+	// 2. Add event to resource history
+	if _, err := dbutil.Exec(ctx, tx, sqlResourceHistoryInsert, sqlResourceRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		WriteEvent:  event,
+		Folder:      folder,
+		GUID:        guid,
+	}); err != nil {
+		return 0, fmt.Errorf("insert into resource history: %w", err)
+	}
 
-		// 2. Add event to resource history
-		if _, err := dbutil.Exec(ctx, tx, sqlResourceHistoryInsert, sqlResourceRequest{
-			SQLTemplate: sqltemplate.New(b.dialect),
-			WriteEvent:  event,
-			Folder:      folder,
-			GUID:        guid,
-		}); err != nil {
-			return fmt.Errorf("insert into resource history: %w", err)
-		}
+	// 3. TODO: Rebuild the whole folder tree structure if we're creating a folder
 
-		// 3. TODO: Rebuild the whole folder tree structure if we're creating a folder
+	// 4. Atomically increment resource version for this kind
+	rv, err := b.resourceVersionAtomicInc(ctx, tx, event.Key)
+	if err != nil {
+		return 0, fmt.Errorf("increment resource version: %w", err)
+	}
 
-		// 4. Atomically increment resource version for this kind
-		rv, err := b.resourceVersionAtomicInc(ctx, tx, event.Key)
-		if err != nil {
-			return fmt.Errorf("increment resource version: %w", err)
-		}
+	// 5. Update the RV in resource_history
+	if _, err = dbutil.Exec(ctx, tx, sqlResourceHistoryUpdateRV, sqlResourceUpdateRVRequest{
+		SQLTemplate:     sqltemplate.New(b.dialect),
+		GUID:            guid,
+		ResourceVersion: rv,
+	}); err != nil {
+		return 0, fmt.Errorf("update history rv: %w", err)
+	}
+	return rv, nil
+}
 
-		// 5. Update the RV in resource_history
-		if _, err = dbutil.Exec(ctx, tx, sqlResourceHistoryUpdateRV, sqlResourceUpdateRVRequest{
-			SQLTemplate:     sqltemplate.New(b.dialect),
-			GUID:            guid,
-			ResourceVersion: rv,
-		}); err != nil {
-			return fmt.Errorf("update history rv: %w", err)
-		}
-		newVersion = rv
+var _ resource.BatchStorageBackend = (*backend)(nil)
+
+// WriteEvents writes all the events in a single transaction.
+func (b *backend) WriteEvents(ctx context.Context, events []resource.WriteEvent) ([]int64, error) {
+	ctx, span := b.tracer.Start(ctx, tracePrefix+"WriteEvents", trace.WithAttributes(
+		attribute.Int("events", len(events)),
+	))
+	defer span.End()
 
+	rvs := make([]int64, len(events))
+	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+		for i, event := range events {
+			if err := b.checkPreviousRV(ctx, tx, event); err != nil {
+				return &resource.BatchWriteError{Index: i, Err: err}
+			}
+
+			var err error
+			switch event.Type {
+			case resource.WatchEvent_ADDED:
+				rvs[i], err = b.createLocked(ctx, tx, event)
+			case resource.WatchEvent_MODIFIED:
+				rvs[i], err = b.updateLocked(ctx, tx, event)
+			case resource.WatchEvent_DELETED:
+				rvs[i], err = b.deleteLocked(ctx, tx, event)
+			}
+			if err != nil {
+				return &resource.BatchWriteError{Index: i, Err: err}
+			}
+		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return rvs, nil
+}
 
-	return newVersion, err
+// checkPreviousRV locks the current row of the event and verifies it has not changed
+// since the event was created.
+func (b *backend) checkPreviousRV(ctx context.Context, tx db.Tx, event resource.WriteEvent) error {
+	current, err := dbutil.QueryRow(ctx, tx, sqlResourceRead, &sqlResourceReadRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		Request:     &resource.ReadRequest{Key: event.Key},
+		Response:    NewReadResponse(),
+		ForUpdate:   true,
+	})
+	exists := true
+	if errors.Is(err, sql.ErrNoRows) {
+		exists = false
+	} else if err != nil {
+		return fmt.Errorf("read current version: %w", err)
+	}
+
+	switch event.Type {
+	case resource.WatchEvent_ADDED:
+		if exists {
+			return resource.ErrOptimisticLockingFailed
+		}
+	case resource.WatchEvent_MODIFIED, resource.WatchEvent_DELETED:
+		if !exists || current.ResourceVersion != event.PreviousRV {
+			return resource.ErrOptimisticLockingFailed
+		}
+	default:
+		return fmt.Errorf("unsupported event type")
+	}
+	return nil
 }
 
 func (b *backend) ReadResource(ctx context.Context, req *resource.ReadRequest) *resource.BackendReadResponse {
@@ -372,6 +509,9 @@ func (b *backend) ReadResource(ctx context.Context, req *resource.ReadRequest) *
 	} else if err != nil {
 		return &resource.BackendReadResponse{Error: resource.AsErrorResult(err)}
 	}
+	if res.Value, err = decodeValue(res.Value); err != nil {
+		return &resource.BackendReadResponse{Error: resource.AsErrorResult(err)}
+	}
 
 	return res
 }
@@ -446,6 +586,9 @@ func (l *listIter) Next() bool {
 	if l.rows.Next() {
 		l.offset++
 		l.err = l.rows.Scan(&l.rv, &l.namespace, &l.name, &l.folder, &l.value)
+		if l.err == nil {
+			l.value, l.err = decodeValue(l.value)
+		}
 		return true
 	}
 	return false
@@ -559,11 +702,34 @@ func (b *backend) WatchWriteEvents(ctx context.Context) (<-chan *resource.Writte
 	return stream, nil
 }
 
+// WatchWriteEventsSince streams the write events after the given resource versions.
+// Groups and resources missing from since start from their latest resource version.
+func (b *backend) WatchWriteEventsSince(ctx context.Context, since map[string]map[string]int64) (<-chan *resource.WrittenEvent, error) {
+	latest, err := b.listLatestRVs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get the latest resource version: %w", err)
+	}
+	for group, items := range since {
+		for resource, rv := range items {
+			if latest[group] == nil {
+				latest[group] = map[string]int64{}
+			}
+			latest[group][resource] = rv
+		}
+	}
+	stream := make(chan *resource.WrittenEvent)
+	go b.poller(ctx, latest, stream)
+	return stream, nil
+}
+
 func (b *backend) poller(ctx context.Context, since groupResourceRV, stream chan<- *resource.WrittenEvent) {
 	t := time.NewTicker(b.pollingInterval)
 	defer close(stream)
 	defer t.Stop()
 
+	cursor := b.watchCursors.register(since)
+	defer b.watchCursors.remove(cursor)
+
 	for {
 		select {
 		case <-b.done:
@@ -597,6 +763,7 @@ func (b *backend) poller(ctx context.Context, since groupResourceRV, stream chan
 					if next > since[group][resource] {
 						since[group][resource] = next
 					}
+					b.watchCursors.update(cursor, group, resource, since[group][resource])
 				}
 			}
 
@@ -676,6 +843,10 @@ func (b *backend) poll(ctx context.Context, grp string, res string, since int64,
 		if rec.Key.Group == "" || rec.Key.Resource == "" || rec.Key.Name == "" {
 			return nextRV, fmt.Errorf("missing key in response")
 		}
+		value, err := decodeValue(rec.Value)
+		if err != nil {
+			return nextRV, fmt.Errorf("read %s/%s/%s: %w", rec.Key.Resource, rec.Key.Namespace, rec.Key.Name, err)
+		}
 		nextRV = rec.ResourceVersion
 		prevRV := rec.PreviousRV
 		if prevRV == nil {
@@ -683,7 +854,7 @@ func (b *backend) poll(ctx context.Context, grp string, res string, since int64,
 		}
 		stream <- &resource.WrittenEvent{
 			WriteEvent: resource.WriteEvent{
-				Value: rec.Value,
+				Value: value,
 				Key: &resource.ResourceKey{
 					Namespace: rec.Key.Namespace,
 					Group:     rec.Key.Group,
@@ -592,3 +592,88 @@ func TestBackend_delete(t *testing.T) {
 		require.ErrorContains(t, err, "update history rv")
 	})
 }
+
+func TestBackend_WriteEvents(t *testing.T) {
+	t.Parallel()
+	meta, err := utils.MetaAccessor(&unstructured.Unstructured{
+		Object: map[string]any{},
+	})
+	require.NoError(t, err)
+	created := &resource.ResourceKey{Namespace: "ns", Group: "gr", Resource: "rs", Name: "new"}
+	events := []resource.WriteEvent{
+		{
+			Type:       resource.WatchEvent_MODIFIED,
+			Key:        resKey,
+			Object:     meta,
+			PreviousRV: 100,
+		},
+		{
+			Type:   resource.WatchEvent_ADDED,
+			Key:    created,
+			Object: meta,
+		},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		// update
+		b.QueryWithResult("select resource", 7, Rows{{"ns", "gr", "rs", "nm", "", 100, "{}"}})
+		b.ExecWithResult("update resource", 0, 1)
+		b.ExecWithResult("insert resource_history", 0, 1)
+		expectSuccessfulResourceVersionAtomicInc(t, b)
+		b.ExecWithResult("update resource_history", 0, 1)
+		b.ExecWithResult("update resource", 0, 1)
+		// create
+		b.QueryWithResult("select resource", 7, Rows{})
+		b.ExecWithResult("insert resource", 0, 1)
+		b.ExecWithResult("insert resource_history", 0, 1)
+		expectSuccessfulResourceVersionAtomicInc(t, b)
+		b.ExecWithResult("update resource_history", 0, 1)
+		b.ExecWithResult("update resource", 0, 1)
+		b.SQLMock.ExpectCommit()
+
+		rvs, err := b.WriteEvents(ctx, events)
+		require.NoError(t, err)
+		require.Equal(t, []int64{23456, 23456}, rvs)
+	})
+
+	t.Run("version changed", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource", 7, Rows{{"ns", "gr", "rs", "nm", "", 101, "{}"}})
+		b.SQLMock.ExpectRollback()
+
+		rvs, err := b.WriteEvents(ctx, events)
+		require.Nil(t, rvs)
+		require.ErrorIs(t, err, resource.ErrOptimisticLockingFailed)
+		var batchErr *resource.BatchWriteError
+		require.ErrorAs(t, err, &batchErr)
+		require.Equal(t, 0, batchErr.Index)
+	})
+
+	t.Run("created resource already exists", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource", 7, Rows{{"ns", "gr", "rs", "nm", "", 100, "{}"}})
+		b.ExecWithResult("update resource", 0, 1)
+		b.ExecWithResult("insert resource_history", 0, 1)
+		expectSuccessfulResourceVersionAtomicInc(t, b)
+		b.ExecWithResult("update resource_history", 0, 1)
+		b.ExecWithResult("update resource", 0, 1)
+		b.QueryWithResult("select resource", 7, Rows{{"ns", "gr", "rs", "new", "", 50, "{}"}})
+		b.SQLMock.ExpectRollback()
+
+		rvs, err := b.WriteEvents(ctx, events)
+		require.Nil(t, rvs)
+		var batchErr *resource.BatchWriteError
+		require.ErrorAs(t, err, &batchErr)
+		require.Equal(t, 1, batchErr.Index)
+	})
+}
@@ -0,0 +1,126 @@
+package sql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // the gzip compressor is also accepted by the server
+)
+
+const (
+	// ValueCompressionZstd stores the values compressed with zstd
+	ValueCompressionZstd = "zstd"
+
+	defaultValueCompressionMinSize = 4 * 1024
+)
+
+// Compressed values are stored as zstd frames, which start with this magic number.
+// JSON values never start with it, so compressed and plain values can live side by side.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	})
+)
+
+// valueCodec compresses the values written to the resource tables
+type valueCodec struct {
+	compression string
+	minSize     int
+}
+
+func newValueCodec(compression string, minSize int) (*valueCodec, error) {
+	switch compression {
+	case "", ValueCompressionZstd:
+	default:
+		return nil, fmt.Errorf("unsupported value compression %q", compression)
+	}
+	if minSize <= 0 {
+		minSize = defaultValueCompressionMinSize
+	}
+	return &valueCodec{compression: compression, minSize: minSize}, nil
+}
+
+// encode compresses the value when it is large enough to be worth it
+func (c *valueCodec) encode(value []byte) ([]byte, error) {
+	if c == nil || c.compression == "" || len(value) < c.minSize || isCompressedValue(value) {
+		return value, nil
+	}
+	enc, err := zstdEncoder()
+	if err != nil {
+		return nil, err
+	}
+	compressed := enc.EncodeAll(value, nil)
+	if len(compressed) >= len(value) {
+		return value, nil
+	}
+	return compressed, nil
+}
+
+// decodeValue returns the plain value, regardless of the configured compression,
+// so values written with a different configuration can still be read.
+func decodeValue(value []byte) ([]byte, error) {
+	if !isCompressedValue(value) {
+		return value, nil
+	}
+	dec, err := zstdDecoder()
+	if err != nil {
+		return nil, err
+	}
+	out, err := dec.DecodeAll(value, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decompress value: %w", err)
+	}
+	return out, nil
+}
+
+func isCompressedValue(value []byte) bool {
+	return bytes.HasPrefix(value, zstdMagic)
+}
+
+// GRPCCompressorZstd is the name of the zstd compressor registered for the gRPC transport.
+// Clients opt in with grpc.UseCompressor, the server replies with the same encoding.
+const GRPCCompressorZstd = "zstd"
+
+func init() {
+	encoding.RegisterCompressor(&grpcZstdCompressor{})
+}
+
+type grpcZstdCompressor struct{}
+
+func (c *grpcZstdCompressor) Name() string {
+	return GRPCCompressorZstd
+}
+
+func (c *grpcZstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+}
+
+func (c *grpcZstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReadCloser{dec}, nil
+}
+
+// zstdReadCloser releases the decoder once the message has been read
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (r *zstdReadCloser) Read(p []byte) (int, error) {
+	n, err := r.Decoder.Read(p)
+	if err == io.EOF {
+		r.Decoder.Close()
+	}
+	return n, err
+}
@@ -0,0 +1,124 @@
+package sql
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/test"
+)
+
+func TestValueCodec(t *testing.T) {
+	t.Parallel()
+
+	large := []byte(`{"kind":"Dashboard","spec":{"title":"` + strings.Repeat("panel ", 2000) + `"}}`)
+	small := []byte(`{"kind":"Dashboard"}`)
+
+	t.Run("compress large values", func(t *testing.T) {
+		t.Parallel()
+		codec, err := newValueCodec(ValueCompressionZstd, 1024)
+		require.NoError(t, err)
+
+		encoded, err := codec.encode(large)
+		require.NoError(t, err)
+		require.True(t, isCompressedValue(encoded))
+		require.Less(t, len(encoded), len(large))
+
+		decoded, err := decodeValue(encoded)
+		require.NoError(t, err)
+		require.Equal(t, large, decoded)
+	})
+
+	t.Run("small values are kept as is", func(t *testing.T) {
+		t.Parallel()
+		codec, err := newValueCodec(ValueCompressionZstd, 1024)
+		require.NoError(t, err)
+
+		encoded, err := codec.encode(small)
+		require.NoError(t, err)
+		require.Equal(t, small, encoded)
+	})
+
+	t.Run("compression disabled", func(t *testing.T) {
+		t.Parallel()
+		codec, err := newValueCodec("", 0)
+		require.NoError(t, err)
+
+		encoded, err := codec.encode(large)
+		require.NoError(t, err)
+		require.Equal(t, large, encoded)
+
+		// plain values are always readable
+		decoded, err := decodeValue(large)
+		require.NoError(t, err)
+		require.Equal(t, large, decoded)
+	})
+
+	t.Run("unsupported compression", func(t *testing.T) {
+		t.Parallel()
+		_, err := newValueCodec("lz4", 0)
+		require.ErrorContains(t, err, "unsupported value compression")
+	})
+
+	t.Run("corrupted value", func(t *testing.T) {
+		t.Parallel()
+		_, err := decodeValue(append([]byte{0x28, 0xb5, 0x2f, 0xfd}, "not zstd"...))
+		require.Error(t, err)
+	})
+}
+
+func TestBackend_ReadResource_compressed(t *testing.T) {
+	t.Parallel()
+
+	value := []byte(`{"kind":"Dashboard","spec":{"title":"` + strings.Repeat("panel ", 2000) + `"}}`)
+	codec, err := newValueCodec(ValueCompressionZstd, 0)
+	require.NoError(t, err)
+	encoded, err := codec.encode(value)
+	require.NoError(t, err)
+
+	b, ctx := setupBackendTest(t)
+	b.SQLMock.ExpectBegin()
+	b.QueryWithResult("select resource", 7, Rows{{"ns", "gr", "rs", "nm", "", 100, encoded}})
+	b.SQLMock.ExpectCommit()
+
+	res := b.ReadResource(ctx, &resource.ReadRequest{Key: resKey})
+	require.Nil(t, res.Error)
+	require.Equal(t, value, res.Value)
+}
+
+func TestNewBackend_compression(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewBackend(BackendOptions{
+		DBProvider:       test.NewDBProviderNopSQL(t),
+		ValueCompression: "brotli",
+	})
+	require.ErrorContains(t, err, "unsupported value compression")
+}
+
+func TestGRPCZstdCompressor(t *testing.T) {
+	t.Parallel()
+
+	c := encoding.GetCompressor(GRPCCompressorZstd)
+	require.NotNil(t, c)
+
+	msg := bytes.Repeat([]byte("resource "), 1000)
+	buf := &bytes.Buffer{}
+	w, err := c.Compress(buf)
+	require.NoError(t, err)
+	_, err = w.Write(msg)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Less(t, buf.Len(), len(msg))
+
+	r, err := c.Decompress(buf)
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, msg, out)
+}
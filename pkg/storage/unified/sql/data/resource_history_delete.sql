@@ -0,0 +1,3 @@
+DELETE FROM {{ .Ident "resource_history" }}
+    WHERE {{ .Ident "guid" }} IN ({{ .ArgList .GUIDs }})
+;
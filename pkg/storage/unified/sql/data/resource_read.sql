@@ -12,4 +12,7 @@ SELECT
         AND {{ .Ident "group" }}     = {{ .Arg .Request.Key.Group }}
         AND {{ .Ident "resource" }}  = {{ .Arg .Request.Key.Resource }}
         AND {{ .Ident "name" }}      = {{ .Arg .Request.Key.Name }}
+    {{ if .ForUpdate }}
+    {{ .SelectFor "UPDATE" }}
+    {{ end }}
 ;
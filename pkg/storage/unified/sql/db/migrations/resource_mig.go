@@ -121,5 +121,12 @@ func initResourceTables(mg *migrator.Migrator) string {
 		Name: "folder", Type: migrator.DB_NVarchar, Length: 253, Nullable: false, Default: "''",
 	}))
 
+	// Compressed values are binary, text columns would require an encoding that cancels part of the saving
+	for _, table := range []string{"resource", "resource_history"} {
+		mg.AddMigration("Change value column to binary in "+table, migrator.NewRawSQLMigration("").
+			Mysql("ALTER TABLE "+table+" MODIFY value LONGBLOB NULL;").
+			Postgres("ALTER TABLE "+table+" ALTER COLUMN value TYPE BYTEA USING convert_to(value, 'UTF8');"))
+	}
+
 	return marker
 }
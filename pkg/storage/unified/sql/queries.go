@@ -38,6 +38,8 @@ var (
 	sqlResourceHistoryUpdateRV = mustTemplate("resource_history_update_rv.sql")
 	sqlResourceHistoryInsert   = mustTemplate("resource_history_insert.sql")
 	sqlResourceHistoryPoll     = mustTemplate("resource_history_poll.sql")
+	sqlResourceHistoryCompact  = mustTemplate("resource_history_compact_list.sql")
+	sqlResourceHistoryDelete   = mustTemplate("resource_history_delete.sql")
 
 	// sqlResourceLabelsInsert = mustTemplate("resource_labels_insert.sql")
 	sqlResourceVersionGet    = mustTemplate("resource_version_get.sql")
@@ -118,6 +120,48 @@ func (r *sqlResourceHistoryPollRequest) Results() (*historyPollResponse, error)
 	}, nil
 }
 
+// compaction
+
+type historyCompactResponse struct {
+	GUID            string
+	Namespace       string
+	Name            string
+	ResourceVersion int64
+}
+
+type sqlResourceHistoryCompactRequest struct {
+	sqltemplate.SQLTemplate
+	Group, Resource string
+	// After is the last row of the previous page, nil for the first page
+	After    *historyCompactResponse
+	Limit    int64
+	Response *historyCompactResponse
+}
+
+func (r *sqlResourceHistoryCompactRequest) Validate() error {
+	if r.Limit <= 0 {
+		return fmt.Errorf("invalid limit %d", r.Limit)
+	}
+	return nil
+}
+
+func (r *sqlResourceHistoryCompactRequest) Results() (*historyCompactResponse, error) {
+	x := *r.Response
+	return &x, nil
+}
+
+type sqlResourceHistoryDeleteRequest struct {
+	sqltemplate.SQLTemplate
+	GUIDs []string
+}
+
+func (r sqlResourceHistoryDeleteRequest) Validate() error {
+	if len(r.GUIDs) == 0 {
+		return fmt.Errorf("no guids to delete")
+	}
+	return nil
+}
+
 // sqlResourceReadRequest can be used to retrieve a row fromthe "resource" tables.
 func NewReadResponse() *resource.BackendReadResponse {
 	return &resource.BackendReadResponse{
@@ -129,6 +173,8 @@ type sqlResourceReadRequest struct {
 	sqltemplate.SQLTemplate
 	Request  *resource.ReadRequest
 	Response *resource.BackendReadResponse
+	// Lock the row until the transaction is committed
+	ForUpdate bool
 }
 
 func (r *sqlResourceReadRequest) Validate() error {
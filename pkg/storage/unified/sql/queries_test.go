@@ -80,6 +80,22 @@ func TestUnifiedStorageQueries(t *testing.T) {
 						Response: NewReadResponse(),
 					},
 				},
+				{
+					Name: "for_update",
+					Data: &sqlResourceReadRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Request: &resource.ReadRequest{
+							Key: &resource.ResourceKey{
+								Namespace: "nn",
+								Group:     "gg",
+								Resource:  "rr",
+								Name:      "name",
+							},
+						},
+						Response:  NewReadResponse(),
+						ForUpdate: true,
+					},
+				},
 			},
 
 			sqlResourceList: {
@@ -129,6 +145,40 @@ func TestUnifiedStorageQueries(t *testing.T) {
 				},
 			},
 
+			sqlResourceHistoryCompact: {
+				{
+					Name: "single path",
+					Data: &sqlResourceHistoryCompactRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Group:       "group",
+						Resource:    "res",
+						Limit:       1000,
+						Response:    new(historyCompactResponse),
+					},
+				},
+				{
+					Name: "next page",
+					Data: &sqlResourceHistoryCompactRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Group:       "group",
+						Resource:    "res",
+						After:       &historyCompactResponse{Namespace: "ns", Name: "nm", ResourceVersion: 1234},
+						Limit:       1000,
+						Response:    new(historyCompactResponse),
+					},
+				},
+			},
+
+			sqlResourceHistoryDelete: {
+				{
+					Name: "multiple guids",
+					Data: &sqlResourceHistoryDeleteRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						GUIDs:       []string{"guid1", "guid2", "guid3"},
+					},
+				},
+			},
+
 			sqlResourceUpdateRV: {
 				{
 					Name: "single path",
@@ -0,0 +1,306 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/dbutil"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+const (
+	defaultCompactionInterval = time.Hour
+	compactionListBatchSize   = 1000
+	compactionDeleteBatchSize = 500
+
+	// compactionSafetyWindow is how long history is kept regardless of the policies. Only the local
+	// watchers are tracked, so this leaves time for the watchers of the other instances to catch up.
+	compactionSafetyWindow = time.Hour
+)
+
+// RetentionPolicy limits how much history is kept in resource_history for a (Group, Resource) pair.
+// The latest version of every object is always kept, regardless of the policy.
+type RetentionPolicy struct {
+	Group    string
+	Resource string
+
+	// MaxVersions is the number of versions kept per object. Zero means unlimited.
+	MaxVersions int
+	// MaxAge is how long old versions are kept. Zero means unlimited.
+	MaxAge time.Duration
+}
+
+func (p RetentionPolicy) validate() error {
+	if p.Group == "" || p.Resource == "" {
+		return errors.New("retention policy requires a group and a resource")
+	}
+	if p.MaxVersions < 0 {
+		return fmt.Errorf("invalid max versions %d for %s/%s", p.MaxVersions, p.Group, p.Resource)
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("invalid max age %s for %s/%s", p.MaxAge, p.Group, p.Resource)
+	}
+	return nil
+}
+
+func (p RetentionPolicy) enabled() bool {
+	return p.MaxVersions > 0 || p.MaxAge > 0
+}
+
+// compactionCursor is the position in the history of an object, kept across the listed pages.
+type compactionCursor struct {
+	namespace, name string
+	position        int
+}
+
+// expiredVersions returns the guids of the history rows that can be removed under the policy.
+// The records must be sorted by namespace and name, with the newest resource version first,
+// and follow the records of the previous call with the same cursor.
+// Rows above maxRV are kept, since some watchers have not consumed them yet.
+// Resource versions are microsecond timestamps, so the age is derived from them.
+func (p RetentionPolicy) expiredVersions(records []*historyCompactResponse, now time.Time, maxRV int64, cursor *compactionCursor) []string {
+	var cutoffRV int64
+	if p.MaxAge > 0 {
+		cutoffRV = now.Add(-p.MaxAge).UnixMicro()
+	}
+
+	var guids []string
+	for _, rec := range records {
+		if rec.Namespace != cursor.namespace || rec.Name != cursor.name {
+			cursor.namespace, cursor.name = rec.Namespace, rec.Name
+			cursor.position = 0
+		} else {
+			cursor.position++
+		}
+
+		// the latest version is always kept, and so is anything not yet seen by a watcher
+		if cursor.position == 0 || rec.ResourceVersion > maxRV {
+			continue
+		}
+		if (p.MaxVersions > 0 && cursor.position >= p.MaxVersions) || rec.ResourceVersion < cutoffRV {
+			guids = append(guids, rec.GUID)
+		}
+	}
+	return guids
+}
+
+// watchCursors keeps track of the resource versions consumed by the local watch pollers,
+// so compaction never removes history that has not been delivered yet.
+type watchCursors struct {
+	mu      sync.Mutex
+	nextID  int
+	cursors map[int]groupResourceRV
+}
+
+func (w *watchCursors) register(since groupResourceRV) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cursors == nil {
+		w.cursors = make(map[int]groupResourceRV)
+	}
+	w.nextID++
+	cursor := groupResourceRV{}
+	for group, items := range since {
+		cursor[group] = make(map[string]int64, len(items))
+		for resource, rv := range items {
+			cursor[group][resource] = rv
+		}
+	}
+	w.cursors[w.nextID] = cursor
+	return w.nextID
+}
+
+func (w *watchCursors) update(id int, group, resource string, rv int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cursor, ok := w.cursors[id]
+	if !ok {
+		return
+	}
+	if cursor[group] == nil {
+		cursor[group] = map[string]int64{}
+	}
+	cursor[group][resource] = rv
+}
+
+func (w *watchCursors) remove(id int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.cursors, id)
+}
+
+// min returns the lowest resource version consumed by the watchers of a (Group, Resource) pair.
+// It returns math.MaxInt64 when nobody is watching.
+func (w *watchCursors) min(group, resource string) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	minRV := int64(math.MaxInt64)
+	for _, cursor := range w.cursors {
+		rv, ok := cursor[group][resource]
+		if !ok {
+			// the poller will start from the beginning for this resource
+			rv = 0
+		}
+		minRV = min(minRV, rv)
+	}
+	return minRV
+}
+
+type compactionMetrics struct {
+	deleted  *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newCompactionMetrics(reg prometheus.Registerer) *compactionMetrics {
+	return &compactionMetrics{
+		deleted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "storage_server",
+			Name:      "history_compaction_deleted_total",
+			Help:      "Number of resource history rows removed by the retention policies",
+		}, []string{"group", "resource"}),
+		errors: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "storage_server",
+			Name:      "history_compaction_errors_total",
+			Help:      "Number of failed resource history compaction runs",
+		}, []string{"group", "resource"}),
+		duration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "storage_server",
+			Name:      "history_compaction_duration_seconds",
+			Help:      "Time (in seconds) spent compacting the resource history",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+		}, []string{"group", "resource"}),
+	}
+}
+
+// compactor periodically applies the retention policies until the backend is stopped.
+func (b *backend) compactor() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-b.done
+		cancel()
+	}()
+
+	t := time.NewTicker(b.compactionInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-t.C:
+			for _, policy := range b.retention {
+				deleted, err := b.compact(ctx, policy)
+				if err != nil {
+					b.log.Error("compact resource history", "group", policy.Group, "resource", policy.Resource, "err", err)
+					continue
+				}
+				if deleted > 0 {
+					b.log.Debug("compacted resource history", "group", policy.Group, "resource", policy.Resource, "deleted", deleted)
+				}
+			}
+		}
+	}
+}
+
+// compact removes the resource history rows of a (Group, Resource) pair that are expired under the policy.
+func (b *backend) compact(ctx context.Context, policy RetentionPolicy) (int64, error) {
+	ctx, span := b.tracer.Start(ctx, tracePrefix+"compact", trace.WithAttributes(
+		attribute.String("k8s.resource.group", policy.Group),
+		attribute.String("k8s.resource.type", policy.Resource),
+	))
+	defer span.End()
+
+	start := time.Now()
+	defer func() {
+		b.compactionMetrics.duration.WithLabelValues(policy.Group, policy.Resource).Observe(time.Since(start).Seconds())
+	}()
+
+	deleted, err := b.compactLocked(ctx, policy)
+	if deleted > 0 {
+		b.compactionMetrics.deleted.WithLabelValues(policy.Group, policy.Resource).Add(float64(deleted))
+	}
+	if err != nil {
+		b.compactionMetrics.errors.WithLabelValues(policy.Group, policy.Resource).Inc()
+	}
+	return deleted, err
+}
+
+func (b *backend) compactLocked(ctx context.Context, policy RetentionPolicy) (int64, error) {
+	// Read the watcher position before listing, so new writes can only move it forward
+	now := time.Now()
+	maxRV := min(b.watchCursors.min(policy.Group, policy.Resource), now.Add(-compactionSafetyWindow).UnixMicro())
+
+	var deleted int64
+	var after *historyCompactResponse
+	cursor := &compactionCursor{}
+	for {
+		var records []*historyCompactResponse
+		err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
+			var err error
+			records, err = dbutil.Query(ctx, tx, sqlResourceHistoryCompact, &sqlResourceHistoryCompactRequest{
+				SQLTemplate: sqltemplate.New(b.dialect),
+				Group:       policy.Group,
+				Resource:    policy.Resource,
+				After:       after,
+				Limit:       compactionListBatchSize,
+				Response:    new(historyCompactResponse),
+			})
+			return err
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("list resource history: %w", err)
+		}
+
+		n, err := b.deleteHistory(ctx, policy.expiredVersions(records, now, maxRV, cursor))
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+
+		if len(records) < compactionListBatchSize {
+			return deleted, nil
+		}
+		after = records[len(records)-1]
+	}
+}
+
+// deleteHistory removes the resource history rows in batches.
+func (b *backend) deleteHistory(ctx context.Context, guids []string) (int64, error) {
+	var deleted int64
+	for len(guids) > 0 {
+		batch := guids[:min(len(guids), compactionDeleteBatchSize)]
+		guids = guids[len(batch):]
+
+		err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+			res, err := dbutil.Exec(ctx, tx, sqlResourceHistoryDelete, sqlResourceHistoryDeleteRequest{
+				SQLTemplate: sqltemplate.New(b.dialect),
+				GUIDs:       batch,
+			})
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			deleted += n
+			return nil
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("delete resource history: %w", err)
+		}
+	}
+	return deleted, nil
+}
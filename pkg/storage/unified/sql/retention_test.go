@@ -0,0 +1,166 @@
+package sql
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/storage/unified/sql/test"
+)
+
+func TestRetentionPolicy_expiredVersions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	rv := func(ago time.Duration) int64 {
+		return now.Add(-ago).UnixMicro()
+	}
+	// sorted by namespace, name and newest version first
+	records := []*historyCompactResponse{
+		{GUID: "a3", Namespace: "ns", Name: "a", ResourceVersion: rv(time.Hour)},
+		{GUID: "a2", Namespace: "ns", Name: "a", ResourceVersion: rv(48 * time.Hour)},
+		{GUID: "a1", Namespace: "ns", Name: "a", ResourceVersion: rv(72 * time.Hour)},
+		{GUID: "b1", Namespace: "ns", Name: "b", ResourceVersion: rv(96 * time.Hour)},
+		{GUID: "c2", Namespace: "ns2", Name: "a", ResourceVersion: rv(2 * time.Hour)},
+		{GUID: "c1", Namespace: "ns2", Name: "a", ResourceVersion: rv(3 * time.Hour)},
+	}
+
+	t.Run("keep last versions", func(t *testing.T) {
+		t.Parallel()
+		p := RetentionPolicy{MaxVersions: 2}
+		require.Equal(t, []string{"a1"}, p.expiredVersions(records, now, math.MaxInt64, &compactionCursor{}))
+	})
+
+	t.Run("keep recent versions", func(t *testing.T) {
+		t.Parallel()
+		p := RetentionPolicy{MaxAge: 24 * time.Hour}
+		// the latest version of "b" is kept even if it is older than the max age
+		require.Equal(t, []string{"a2", "a1"}, p.expiredVersions(records, now, math.MaxInt64, &compactionCursor{}))
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		t.Parallel()
+		p := RetentionPolicy{MaxVersions: 1, MaxAge: 24 * time.Hour}
+		require.Equal(t, []string{"a2", "a1", "c1"}, p.expiredVersions(records, now, math.MaxInt64, &compactionCursor{}))
+	})
+
+	t.Run("history split across pages", func(t *testing.T) {
+		t.Parallel()
+		p := RetentionPolicy{MaxVersions: 2}
+		cursor := &compactionCursor{}
+		require.Empty(t, p.expiredVersions(records[:2], now, math.MaxInt64, cursor))
+		require.Equal(t, []string{"a1"}, p.expiredVersions(records[2:], now, math.MaxInt64, cursor))
+	})
+
+	t.Run("versions not consumed by watchers are kept", func(t *testing.T) {
+		t.Parallel()
+		p := RetentionPolicy{MaxVersions: 1}
+		require.Equal(t, []string{"a1"}, p.expiredVersions(records, now, rv(60*time.Hour), &compactionCursor{}))
+	})
+}
+
+func TestWatchCursors(t *testing.T) {
+	t.Parallel()
+
+	w := watchCursors{}
+	require.Equal(t, int64(math.MaxInt64), w.min("gr", "rs"))
+
+	first := w.register(groupResourceRV{"gr": {"rs": 10}})
+	second := w.register(groupResourceRV{"gr": {"rs": 20}})
+	require.Equal(t, int64(10), w.min("gr", "rs"))
+	// a new resource is polled from the beginning
+	require.Equal(t, int64(0), w.min("gr", "other"))
+
+	w.update(first, "gr", "rs", 30)
+	require.Equal(t, int64(20), w.min("gr", "rs"))
+
+	w.remove(second)
+	require.Equal(t, int64(30), w.min("gr", "rs"))
+}
+
+func TestBackend_compact(t *testing.T) {
+	t.Parallel()
+
+	policy := RetentionPolicy{Group: "gr", Resource: "rs", MaxVersions: 1}
+
+	t.Run("happy path", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource_history", 4, Rows{
+			{"g3", "ns", "nm", 3},
+			{"g2", "ns", "nm", 2},
+			{"g1", "ns", "nm", 1},
+		})
+		b.SQLMock.ExpectCommit()
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, 2)
+		b.SQLMock.ExpectCommit()
+
+		deleted, err := b.compact(ctx, policy)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+	})
+
+	t.Run("nothing to delete", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource_history", 4, Rows{
+			{"g1", "ns", "nm", 1},
+		})
+		b.SQLMock.ExpectCommit()
+
+		deleted, err := b.compact(ctx, policy)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+
+	t.Run("recent history is kept", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+		now := time.Now()
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource_history", 4, Rows{
+			{"g3", "ns", "nm", now.UnixMicro()},
+			{"g2", "ns", "nm", now.Add(-time.Minute).UnixMicro()},
+			{"g1", "ns", "nm", now.Add(-2 * compactionSafetyWindow).UnixMicro()},
+		})
+		b.SQLMock.ExpectCommit()
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, 1)
+		b.SQLMock.ExpectCommit()
+
+		deleted, err := b.compact(ctx, policy)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("error listing history", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithErr("select resource_history", errTest)
+		b.SQLMock.ExpectRollback()
+
+		deleted, err := b.compact(ctx, policy)
+		require.Zero(t, deleted)
+		require.ErrorContains(t, err, "list resource history")
+	})
+}
+
+func TestNewBackend_retention(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewBackend(BackendOptions{
+		DBProvider:        test.NewDBProviderNopSQL(t),
+		RetentionPolicies: []RetentionPolicy{{Group: "gr", Resource: "rs", MaxVersions: -1}},
+	})
+	require.ErrorContains(t, err, "invalid max versions")
+}
@@ -42,7 +42,16 @@ func NewResourceServer(ctx context.Context, db infraDB.DB, cfg *setting.Cfg, fea
 	if err != nil {
 		return nil, err
 	}
-	store, err := NewBackend(BackendOptions{DBProvider: eDB, Tracer: tracer})
+	store, err := NewBackend(BackendOptions{
+		DBProvider:         eDB,
+		Tracer:             tracer,
+		RetentionPolicies:  retentionPoliciesFromConfig(cfg),
+		CompactionInterval: cfg.HistoryCompactionInterval,
+		Reg:                reg,
+
+		ValueCompression:        cfg.ValueCompression,
+		ValueCompressionMinSize: cfg.ValueCompressionMinSize,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +59,10 @@ func NewResourceServer(ctx context.Context, db infraDB.DB, cfg *setting.Cfg, fea
 	opts.Diagnostics = store
 	opts.Lifecycle = store
 
+	if err := startSinks(ctx, cfg, store, db, tracer, reg); err != nil {
+		return nil, err
+	}
+
 	if features.IsEnabledGlobally(featuremgmt.FlagUnifiedStorageSearch) {
 		opts.Index = resource.NewResourceIndexServer(cfg, tracer)
 	}
@@ -85,3 +98,24 @@ func NewResourceServer(ctx context.Context, db infraDB.DB, cfg *setting.Cfg, fea
 
 	return rs, nil
 }
+
+// retentionPoliciesFromConfig reads the history retention from the [unified_storage.<resource>.<group>] sections
+func retentionPoliciesFromConfig(cfg *setting.Cfg) []RetentionPolicy {
+	var policies []RetentionPolicy
+	for name, c := range cfg.UnifiedStorage {
+		if c.HistoryMaxVersions == 0 && c.HistoryMaxAge == 0 {
+			continue
+		}
+		parts := strings.SplitN(name, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		policies = append(policies, RetentionPolicy{
+			Group:       parts[1],
+			Resource:    parts[0],
+			MaxVersions: c.HistoryMaxVersions,
+			MaxAge:      c.HistoryMaxAge,
+		})
+	}
+	return policies
+}
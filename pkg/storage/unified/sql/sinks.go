@@ -0,0 +1,114 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	infraDB "github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/sink"
+)
+
+const (
+	sinksLockName = "unified-storage-sinks"
+	// sinksLeaseDuration is how long an instance delivers the events before it releases the lock,
+	// the lock is considered dead after twice this duration
+	sinksLeaseDuration = 5 * time.Minute
+	// sinksLockRetryInterval is how often the other instances try to take over the delivery
+	sinksLockRetryInterval = 30 * time.Second
+)
+
+var _ sink.ReplayableBackend = (*backend)(nil)
+
+// startSinks forwards the write events of the backend to the sinks configured in [unified_storage_sink.<name>].
+// Only the instance holding the server lock delivers the events, and the cursors are stored in the database,
+// so the events are delivered once per sink when several instances share the database.
+func startSinks(ctx context.Context, cfg *setting.Cfg, store Backend, db infraDB.DB, tracer tracing.Tracer, reg prometheus.Registerer) error {
+	if len(cfg.UnifiedStorageSinks) == 0 {
+		return nil
+	}
+
+	opts := sink.DispatcherOptions{
+		Backend: store,
+		Cursors: &sink.KVCursorStore{KV: kvstore.WithNamespace(kvstore.ProvideService(db), 0, "unified-storage-sinks")},
+		Filters: map[string]sink.Filter{},
+		Reg:     reg,
+	}
+	for _, c := range cfg.UnifiedStorageSinks {
+		s, err := newSink(c)
+		if err != nil {
+			return fmt.Errorf("sink %s: %w", c.Name, err)
+		}
+		opts.Sinks = append(opts.Sinks, s)
+		opts.Filters[c.Name] = c.Resources
+	}
+
+	dispatcher, err := sink.NewDispatcher(opts)
+	if err != nil {
+		return err
+	}
+
+	lock := serverlock.ProvideService(db, tracer)
+	logger := log.New("sql-resource-server")
+	go func() {
+		defer dispatcher.Close()
+		if err := store.Init(ctx); err != nil {
+			logger.Error("failed to start the unified storage sinks", "err", err)
+			return
+		}
+
+		for {
+			var runErr error
+			err := lock.LockExecuteAndRelease(ctx, sinksLockName, 2*sinksLeaseDuration, func(ctx context.Context) {
+				ctx, cancel := context.WithTimeout(ctx, sinksLeaseDuration)
+				defer cancel()
+				if runErr = dispatcher.Run(ctx); runErr != nil {
+					logger.Error("unified storage sinks stopped", "err", runErr)
+				}
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil && runErr == nil {
+				// renew the lease right away, so the delivery is not interrupted
+				continue
+			}
+
+			var lockedErr *serverlock.ServerLockExistsError
+			if err != nil && !errors.As(err, &lockedErr) {
+				logger.Error("failed to lock the unified storage sinks", "err", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(sinksLockRetryInterval):
+			}
+		}
+	}()
+	return nil
+}
+
+func newSink(c setting.UnifiedStorageSinkConfig) (sink.Sink, error) {
+	switch c.Type {
+	case "webhook":
+		return sink.NewWebhookSink(c.Name, c.URL, c.Headers, c.Timeout)
+	case "file":
+		return sink.NewFileSink(c.Name, c.Path)
+	case "kafka":
+		producer, err := sink.NewRESTProducer(c.URL, c.Headers, c.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		return sink.NewKafkaSink(c.Name, c.Topic, producer)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", c.Type)
+	}
+}
@@ -0,0 +1,3 @@
+DELETE FROM `resource_history`
+    WHERE `guid` IN ('guid1', 'guid2', 'guid3')
+;
@@ -0,0 +1,17 @@
+SELECT
+    "guid",
+    "namespace",
+    "name",
+    "resource_version"
+    FROM "resource_history"
+    WHERE 1 = 1
+    AND "group" = 'group'
+    AND "resource" = 'res'
+    AND (
+        "namespace" > 'ns'
+        OR ("namespace" = 'ns' AND "name" > 'nm')
+        OR ("namespace" = 'ns' AND "name" = 'nm' AND "resource_version" < 1234)
+    )
+    ORDER BY "namespace" ASC, "name" ASC, "resource_version" DESC
+    LIMIT 1000
+;
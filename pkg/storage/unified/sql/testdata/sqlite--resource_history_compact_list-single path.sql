@@ -0,0 +1,12 @@
+SELECT
+    "guid",
+    "namespace",
+    "name",
+    "resource_version"
+    FROM "resource_history"
+    WHERE 1 = 1
+    AND "group" = 'group'
+    AND "resource" = 'res'
+    ORDER BY "namespace" ASC, "name" ASC, "resource_version" DESC
+    LIMIT 1000
+;
@@ -3,8 +3,9 @@ import { PureComponent } from 'react';
 import { FetchError, getBackendSrv, isFetchError, locationService } from '@grafana/runtime';
 import config from 'app/core/config';
 import { t } from 'app/core/internationalization';
+import { getAssertion, isWebAuthnCancelled, WebAuthnRequestOptions } from 'app/core/utils/webauthn';
 
-import { LoginDTO, AuthNRedirectDTO } from './types';
+import { LoginDTO, AuthNRedirectDTO, MFAChallenge, MFAEnrollmentDTO } from './types';
 
 const isOauthEnabled = () => {
   return !!config.oauth && Object.keys(config.oauth).length > 0;
@@ -21,6 +22,10 @@ export interface FormModel {
   email: string;
 }
 
+export interface MFAFormModel {
+  code: string;
+}
+
 export interface PasswordlessFormModel {
   email: string;
 }
@@ -44,6 +49,11 @@ interface Props {
     passwordlessStart: (data: PasswordlessFormModel) => void;
     passwordlessConfirm: (data: PasswordlessConfirmationFormModel) => void;
     showPasswordlessConfirmation: boolean;
+    mfaChallenge: MFAChallenge | undefined;
+    mfaEnrollment: MFAEnrollmentDTO | undefined;
+    startMFAEnrollment: () => void;
+    verifyMFA: (data: MFAFormModel) => void;
+    loginWithSecurityKey: () => void;
     disableLoginForm: boolean;
     disableUserSignUp: boolean;
     isOauthEnabled: boolean;
@@ -59,6 +69,8 @@ interface State {
   isChangingPassword: boolean;
   showDefaultPasswordWarning: boolean;
   loginErrorMessage?: string;
+  mfaChallenge?: MFAChallenge;
+  mfaEnrollment?: MFAEnrollmentDTO;
 }
 
 export class LoginCtrl extends PureComponent<Props, State> {
@@ -122,6 +134,12 @@ export class LoginCtrl extends PureComponent<Props, State> {
         }
       })
       .catch((err) => {
+        // users with a second factor complete the login with the challenge of the response
+        const mfaChallenge = isFetchError(err) ? getMFAChallenge(err) : undefined;
+        if (mfaChallenge) {
+          this.setState({ isLoggingIn: false, mfaChallenge, mfaEnrollment: undefined });
+          return;
+        }
         const fetchErrorMessage = isFetchError(err) ? getErrorMessage(err) : undefined;
         this.setState({
           isLoggingIn: false,
@@ -130,6 +148,92 @@ export class LoginCtrl extends PureComponent<Props, State> {
       });
   };
 
+  startMFAEnrollment = () => {
+    const { mfaChallenge } = this.state;
+    if (!mfaChallenge) {
+      return;
+    }
+
+    getBackendSrv()
+      .post<MFAEnrollmentDTO>('/api/login/mfa/enroll', { challenge: mfaChallenge.challenge }, { showErrorAlert: false })
+      .then((mfaEnrollment) => {
+        this.setState({ mfaEnrollment });
+      })
+      .catch((err) => {
+        this.loginStepFailed(err, true);
+      });
+  };
+
+  verifyMFA = (formModel: MFAFormModel) => {
+    const { mfaChallenge } = this.state;
+    if (!mfaChallenge) {
+      return;
+    }
+    this.setState({
+      loginErrorMessage: undefined,
+      isLoggingIn: true,
+    });
+
+    getBackendSrv()
+      .post<LoginDTO>(
+        '/login/mfa',
+        { challenge: mfaChallenge.challenge, code: formModel.code },
+        { showErrorAlert: false }
+      )
+      .then((result) => {
+        this.result = result;
+        this.toGrafana();
+      })
+      .catch((err) => {
+        this.loginStepFailed(err, isFetchError(err) && err.data?.messageId === 'mfa.challenge-not-found');
+      });
+  };
+
+  // loginWithSecurityKey logs in with a passkey, or completes the multi-factor challenge with a security key of the
+  // user who entered their password
+  loginWithSecurityKey = () => {
+    const { mfaChallenge } = this.state;
+    this.setState({
+      loginErrorMessage: undefined,
+      isLoggingIn: true,
+    });
+
+    getBackendSrv()
+      .post<{ publicKey: WebAuthnRequestOptions }>(
+        '/api/login/webauthn/begin',
+        { mfaChallenge: mfaChallenge?.challenge },
+        { showErrorAlert: false }
+      )
+      .then(({ publicKey }) => getAssertion(publicKey))
+      .then((assertion) => getBackendSrv().post<LoginDTO>('/login/webauthn', assertion, { showErrorAlert: false }))
+      .then((result) => {
+        this.result = result;
+        this.toGrafana();
+      })
+      .catch((err) => {
+        if (isWebAuthnCancelled(err)) {
+          this.setState({
+            isLoggingIn: false,
+            loginErrorMessage: t('login.error.webauthn-cancelled', 'The security key or passkey was not used'),
+          });
+          return;
+        }
+        const challengeExpired = isFetchError(err) && err.data?.messageId === 'mfa.challenge-not-found';
+        this.loginStepFailed(err, !!mfaChallenge && challengeExpired);
+      });
+  };
+
+  // an expired challenge or a failed enrollment returns the user to the password form to start over
+  loginStepFailed = (err: unknown, resetChallenge: boolean) => {
+    const fetchErrorMessage = isFetchError(err) ? getErrorMessage(err) : undefined;
+    this.setState({
+      isLoggingIn: false,
+      loginErrorMessage: fetchErrorMessage || t('login.error.unknown', 'Unknown error occurred'),
+      mfaChallenge: resetChallenge ? undefined : this.state.mfaChallenge,
+      mfaEnrollment: resetChallenge ? undefined : this.state.mfaEnrollment,
+    });
+  };
+
   passwordlessStart = (formModel: PasswordlessFormModel) => {
     this.setState({
       loginErrorMessage: undefined,
@@ -199,8 +303,24 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
   render() {
     const { children } = this.props;
-    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage } = this.state;
-    const { login, toGrafana, changePassword, passwordlessStart, passwordlessConfirm } = this;
+    const {
+      isLoggingIn,
+      isChangingPassword,
+      showDefaultPasswordWarning,
+      loginErrorMessage,
+      mfaChallenge,
+      mfaEnrollment,
+    } = this.state;
+    const {
+      login,
+      toGrafana,
+      changePassword,
+      passwordlessStart,
+      passwordlessConfirm,
+      startMFAEnrollment,
+      verifyMFA,
+      loginWithSecurityKey,
+    } = this;
     const { loginHint, passwordHint, disableLoginForm, disableUserSignUp } = config;
 
     return (
@@ -215,6 +335,11 @@ export class LoginCtrl extends PureComponent<Props, State> {
           passwordlessStart,
           passwordlessConfirm,
           showPasswordlessConfirmation: showPasswordlessConfirmation(),
+          mfaChallenge,
+          mfaEnrollment,
+          startMFAEnrollment,
+          verifyMFA,
+          loginWithSecurityKey,
           isLoggingIn,
           changePassword,
           skipPasswordChange: toGrafana,
@@ -235,7 +360,10 @@ function getErrorMessage(err: FetchError<undefined | { messageId?: string; messa
     case 'password-auth.failed':
     case 'password-auth.invalid':
       return t('login.error.invalid-user-or-password', 'Invalid username or password');
+    case 'mfa.invalid-code':
+      return t('login.error.invalid-mfa-code', 'Invalid verification code');
     case 'login-attempt.blocked':
+    case 'mfa.invalid.login-attempt':
       return t(
         'login.error.blocked',
         'You have exceeded the number of login attempts for this user. Please try again later.'
@@ -245,6 +373,21 @@ function getErrorMessage(err: FetchError<undefined | { messageId?: string; messa
   }
 }
 
+function getMFAChallenge(
+  err: FetchError<undefined | { messageId?: string; extra?: { challenge?: string; methods?: string[] } }>
+): MFAChallenge | undefined {
+  const messageId = err.data?.messageId;
+  const challenge = err.data?.extra?.challenge;
+  if ((messageId !== 'mfa.required' && messageId !== 'mfa.enrollment-required') || !challenge) {
+    return undefined;
+  }
+  return {
+    challenge,
+    methods: err.data?.extra?.methods ?? [],
+    enrollmentRequired: messageId === 'mfa.enrollment-required',
+  };
+}
+
 function getBootDataErrMessage(str?: string) {
   switch (str) {
     case 'oauth.login.error':
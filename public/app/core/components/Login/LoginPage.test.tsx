@@ -33,6 +33,20 @@ jest.mock('@grafana/runtime', () => ({
   },
 }));
 
+const getAssertionMock = jest.fn();
+jest.mock('app/core/utils/webauthn', () => ({
+  ...jest.requireActual('app/core/utils/webauthn'),
+  isWebAuthnSupported: () => true,
+  getAssertion: (...args: unknown[]) => getAssertionMock(...args),
+}));
+
+const assertion = {
+  id: 'credential',
+  rawId: 'Y3JlZGVudGlhbA',
+  type: 'public-key',
+  response: { clientDataJSON: 'e30', authenticatorData: 'YQ', signature: 'cw', userHandle: 'dQ' },
+};
+
 describe('Login Page', () => {
   beforeEach(() => {
     jest.resetAllMocks();
@@ -163,4 +177,186 @@ describe('Login Page', () => {
       'You have exceeded the number of login attempts for this user. Please try again later.'
     );
   });
+
+  it('completes the login with the verification code of a multi-factor challenge', async () => {
+    Object.defineProperty(window, 'location', {
+      value: {
+        assign: jest.fn(),
+      },
+    });
+    postMock.mockRejectedValueOnce({
+      data: {
+        message: 'Verify your second factor to log in',
+        messageId: 'mfa.required',
+        statusCode: 401,
+        extra: { challenge: 'challenge-token', methods: ['totp'] },
+      },
+      status: 401,
+      statusText: 'Unauthorized',
+    });
+    postMock.mockResolvedValueOnce({ message: 'Logged in' });
+
+    render(<LoginPage />);
+
+    await userEvent.type(screen.getByLabelText('Email or username'), 'admin');
+    await userEvent.type(screen.getByLabelText('Password'), 'test');
+    await userEvent.click(screen.getByRole('button', { name: 'Log in' }));
+
+    await userEvent.type(await screen.findByLabelText(/Verification code/), '123456');
+    await userEvent.click(screen.getByRole('button', { name: 'Verify' }));
+
+    await waitFor(() =>
+      expect(postMock).toHaveBeenCalledWith(
+        '/login/mfa',
+        { challenge: 'challenge-token', code: '123456' },
+        { showErrorAlert: false }
+      )
+    );
+    expect(window.location.assign).toHaveBeenCalledWith('/');
+  });
+
+  it('enrolls an authenticator app when multi-factor authentication is required', async () => {
+    postMock.mockRejectedValueOnce({
+      data: {
+        message: 'Multi-factor authentication is required, set up an authenticator app',
+        messageId: 'mfa.enrollment-required',
+        statusCode: 401,
+        extra: { challenge: 'challenge-token', methods: [] },
+      },
+      status: 401,
+      statusText: 'Unauthorized',
+    });
+    postMock.mockResolvedValueOnce({
+      secret: 'JBSWY3DPEHPK3PXP',
+      url: 'otpauth://totp/Grafana:admin?secret=JBSWY3DPEHPK3PXP',
+      recoveryCodes: ['code-1', 'code-2'],
+    });
+
+    render(<LoginPage />);
+
+    await userEvent.type(screen.getByLabelText('Email or username'), 'admin');
+    await userEvent.type(screen.getByLabelText('Password'), 'test');
+    await userEvent.click(screen.getByRole('button', { name: 'Log in' }));
+
+    expect(await screen.findByTestId('mfa-secret')).toHaveValue('JBSWY3DPEHPK3PXP');
+    expect(postMock).toHaveBeenCalledWith(
+      '/api/login/mfa/enroll',
+      { challenge: 'challenge-token' },
+      { showErrorAlert: false }
+    );
+    expect(screen.getByRole('link', { name: 'Open in authenticator app' })).toHaveAttribute(
+      'href',
+      'otpauth://totp/Grafana:admin?secret=JBSWY3DPEHPK3PXP'
+    );
+    expect(screen.getByText(/code-1/)).toBeInTheDocument();
+  });
+
+  it('returns to the password form when the multi-factor challenge expired', async () => {
+    postMock.mockRejectedValueOnce({
+      data: {
+        messageId: 'mfa.required',
+        statusCode: 401,
+        extra: { challenge: 'challenge-token', methods: ['totp'] },
+      },
+      status: 401,
+      statusText: 'Unauthorized',
+    });
+    postMock.mockRejectedValueOnce({
+      data: {
+        message: 'The login challenge is invalid or expired, log in again',
+        messageId: 'mfa.challenge-not-found',
+        statusCode: 401,
+      },
+      status: 401,
+      statusText: 'Unauthorized',
+    });
+
+    render(<LoginPage />);
+
+    await userEvent.type(screen.getByLabelText('Email or username'), 'admin');
+    await userEvent.type(screen.getByLabelText('Password'), 'test');
+    await userEvent.click(screen.getByRole('button', { name: 'Log in' }));
+
+    await userEvent.type(await screen.findByLabelText(/Verification code/), '123456');
+    await userEvent.click(screen.getByRole('button', { name: 'Verify' }));
+
+    const alert = await screen.findByRole('alert', { name: 'Login failed' });
+    expect(alert).toHaveTextContent('The login challenge is invalid or expired, log in again');
+    expect(screen.getByLabelText('Password')).toBeInTheDocument();
+  });
+
+  it('logs in with a security key or passkey', async () => {
+    Object.defineProperty(window, 'location', {
+      value: {
+        assign: jest.fn(),
+      },
+    });
+    runtimeMock.config.auth.webauthnEnabled = true;
+    const publicKey = { challenge: 'Y2hhbGxlbmdl', timeout: 300000, rpId: 'localhost', userVerification: 'required' };
+    postMock.mockResolvedValueOnce({ publicKey });
+    getAssertionMock.mockResolvedValueOnce(assertion);
+    postMock.mockResolvedValueOnce({ message: 'Logged in' });
+
+    render(<LoginPage />);
+
+    await userEvent.click(screen.getByRole('button', { name: 'Log in with a security key or passkey' }));
+
+    await waitFor(() => expect(postMock).toHaveBeenCalledWith('/login/webauthn', assertion, { showErrorAlert: false }));
+    expect(postMock).toHaveBeenCalledWith(
+      '/api/login/webauthn/begin',
+      { mfaChallenge: undefined },
+      { showErrorAlert: false }
+    );
+    expect(getAssertionMock).toHaveBeenCalledWith(publicKey);
+    expect(window.location.assign).toHaveBeenCalledWith('/');
+    runtimeMock.config.auth.webauthnEnabled = false;
+  });
+
+  it('completes a multi-factor challenge with a security key', async () => {
+    postMock.mockRejectedValueOnce({
+      data: {
+        messageId: 'mfa.required',
+        statusCode: 401,
+        extra: { challenge: 'challenge-token', methods: ['webauthn'] },
+      },
+      status: 401,
+      statusText: 'Unauthorized',
+    });
+    postMock.mockResolvedValueOnce({ publicKey: { challenge: 'Y2hhbGxlbmdl' } });
+    getAssertionMock.mockResolvedValueOnce(assertion);
+    postMock.mockResolvedValueOnce({ message: 'Logged in' });
+
+    render(<LoginPage />);
+
+    await userEvent.type(screen.getByLabelText('Email or username'), 'admin');
+    await userEvent.type(screen.getByLabelText('Password'), 'test');
+    await userEvent.click(screen.getByRole('button', { name: 'Log in' }));
+
+    // users without an authenticator app have no verification code to enter
+    await userEvent.click(await screen.findByRole('button', { name: 'Use a security key or passkey' }));
+    expect(screen.queryByLabelText(/Verification code/)).not.toBeInTheDocument();
+
+    await waitFor(() => expect(postMock).toHaveBeenCalledWith('/login/webauthn', assertion, { showErrorAlert: false }));
+    expect(postMock).toHaveBeenCalledWith(
+      '/api/login/webauthn/begin',
+      { mfaChallenge: 'challenge-token' },
+      { showErrorAlert: false }
+    );
+  });
+
+  it('shows an error when the security key is not used', async () => {
+    runtimeMock.config.auth.webauthnEnabled = true;
+    postMock.mockResolvedValueOnce({ publicKey: { challenge: 'Y2hhbGxlbmdl' } });
+    const cancelled = new Error('The operation either timed out or was not allowed.');
+    cancelled.name = 'NotAllowedError';
+    getAssertionMock.mockRejectedValueOnce(cancelled);
+
+    render(<LoginPage />);
+
+    await userEvent.click(screen.getByRole('button', { name: 'Log in with a security key or passkey' }));
+
+    const alert = await screen.findByRole('alert', { name: 'Login failed' });
+    expect(alert).toHaveTextContent('The security key or passkey was not used');
+    runtimeMock.config.auth.webauthnEnabled = false;
+  });
 });
@@ -4,9 +4,10 @@ import { css } from '@emotion/css';
 // Components
 import { GrafanaTheme2 } from '@grafana/data';
 import { config } from '@grafana/runtime';
-import { Alert, LinkButton, Stack, useStyles2 } from '@grafana/ui';
+import { Alert, Button, LinkButton, Stack, useStyles2 } from '@grafana/ui';
 import { Branding } from 'app/core/components/Branding/Branding';
 import { t, Trans } from 'app/core/internationalization';
+import { isWebAuthnSupported } from 'app/core/utils/webauthn';
 
 import { ChangePassword } from '../ForgottenPassword/ChangePassword';
 
@@ -14,6 +15,7 @@ import LoginCtrl from './LoginCtrl';
 import { LoginForm } from './LoginForm';
 import { LoginLayout, InnerBox } from './LoginLayout';
 import { LoginServiceButtons } from './LoginServiceButtons';
+import { MFAForm } from './MFAForm';
 import { PasswordlessConfirmation } from './PasswordlessConfirmationForm';
 import { PasswordlessLoginForm } from './PasswordlessLoginForm';
 import { UserSignup } from './UserSignup';
@@ -33,6 +35,11 @@ const LoginPage = () => {
         passwordlessStart,
         passwordlessConfirm,
         showPasswordlessConfirmation,
+        mfaChallenge,
+        mfaEnrollment,
+        startMFAEnrollment,
+        verifyMFA,
+        loginWithSecurityKey,
         isLoggingIn,
         changePassword,
         skipPasswordChange,
@@ -41,7 +48,7 @@ const LoginPage = () => {
         loginErrorMessage,
       }) => (
         <LoginLayout isChangingPassword={isChangingPassword}>
-          {!isChangingPassword && !showPasswordlessConfirmation && (
+          {!isChangingPassword && !showPasswordlessConfirmation && !mfaChallenge && (
             <InnerBox>
               {loginErrorMessage && (
                 <Alert className={styles.alert} severity="error" title={t('login.error.title', 'Login failed')}>
@@ -64,6 +71,17 @@ const LoginPage = () => {
                   </Stack>
                 </LoginForm>
               )}
+              {config.auth.webauthnEnabled && isWebAuthnSupported() && (
+                <Button
+                  className={styles.securityKeyButton}
+                  variant="secondary"
+                  icon="key-skeleton-alt"
+                  onClick={loginWithSecurityKey}
+                  disabled={isLoggingIn}
+                >
+                  <Trans i18nKey="login.security-key">Log in with a security key or passkey</Trans>
+                </Button>
+              )}
               {config.auth.passwordlessEnabled && (
                 <PasswordlessLoginForm onSubmit={passwordlessStart} isLoggingIn={isLoggingIn}></PasswordlessLoginForm>
               )}
@@ -72,6 +90,24 @@ const LoginPage = () => {
             </InnerBox>
           )}
 
+          {!isChangingPassword && mfaChallenge && (
+            <InnerBox>
+              {loginErrorMessage && (
+                <Alert className={styles.alert} severity="error" title={t('login.error.title', 'Login failed')}>
+                  {loginErrorMessage}
+                </Alert>
+              )}
+              <MFAForm
+                challenge={mfaChallenge}
+                enrollment={mfaEnrollment}
+                onStartEnrollment={startMFAEnrollment}
+                onSubmit={verifyMFA}
+                onSecurityKey={loginWithSecurityKey}
+                isLoggingIn={isLoggingIn}
+              />
+            </InnerBox>
+          )}
+
           {config.auth.passwordlessEnabled && showPasswordlessConfirmation && (
             <InnerBox>
               <PasswordlessConfirmation
@@ -108,5 +144,11 @@ const getStyles = (theme: GrafanaTheme2) => {
     alert: css({
       width: '100%',
     }),
+
+    securityKeyButton: css({
+      justifyContent: 'center',
+      width: '100%',
+      marginBottom: theme.spacing(2),
+    }),
   };
 };
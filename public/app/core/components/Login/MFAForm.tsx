@@ -0,0 +1,157 @@
+import { css } from '@emotion/css';
+import { useEffect, useId } from 'react';
+import { useForm } from 'react-hook-form';
+
+import { GrafanaTheme2 } from '@grafana/data';
+import { selectors } from '@grafana/e2e-selectors';
+import { Button, ClipboardButton, Field, Input, LinkButton, Stack, Text, useStyles2 } from '@grafana/ui';
+import { t, Trans } from 'app/core/internationalization';
+import { isWebAuthnSupported } from 'app/core/utils/webauthn';
+
+import { MFAFormModel } from './LoginCtrl';
+import { MFAChallenge, MFAEnrollmentDTO } from './types';
+
+interface Props {
+  challenge: MFAChallenge;
+  enrollment?: MFAEnrollmentDTO;
+  onStartEnrollment: () => void;
+  onSubmit: (data: MFAFormModel) => void;
+  onSecurityKey: () => void;
+  isLoggingIn: boolean;
+}
+
+export const MFAForm = ({ challenge, enrollment, onStartEnrollment, onSubmit, onSecurityKey, isLoggingIn }: Props) => {
+  const styles = useStyles2(getStyles);
+  const codeId = useId();
+  const {
+    handleSubmit,
+    register,
+    formState: { errors },
+  } = useForm<MFAFormModel>({ mode: 'onChange' });
+
+  // users without a second factor enroll an authenticator app before completing the login
+  const { enrollmentRequired } = challenge;
+  useEffect(() => {
+    if (enrollmentRequired && !enrollment) {
+      onStartEnrollment();
+    }
+  }, [enrollmentRequired, enrollment, onStartEnrollment]);
+
+  if (enrollmentRequired && !enrollment) {
+    return null;
+  }
+
+  // users with only a security key or passkey don't have a verification code to enter
+  const hasCode = enrollmentRequired || challenge.methods.includes('totp');
+  const hasSecurityKey = challenge.methods.includes('webauthn') && isWebAuthnSupported();
+
+  return (
+    <div className={styles.wrapper}>
+      <form onSubmit={handleSubmit(onSubmit)}>
+        {enrollment && (
+          <Stack direction="column" gap={2}>
+            <Text element="p">
+              <Trans i18nKey="login.mfa.enrollment-description">
+                Multi-factor authentication is required. Add your account to an authenticator app with the key below,
+                then enter the code it shows.
+              </Trans>
+            </Text>
+            <Field label={t('login.mfa.secret-label', 'Setup key')}>
+              <Input
+                value={enrollment.secret}
+                readOnly
+                data-testid="mfa-secret"
+                addonAfter={
+                  <ClipboardButton icon="copy" variant="primary" getText={() => enrollment.secret}>
+                    <Trans i18nKey="login.mfa.copy">Copy</Trans>
+                  </ClipboardButton>
+                }
+              />
+            </Field>
+            <LinkButton href={enrollment.url} variant="secondary" fill="outline" icon="mobile-android">
+              <Trans i18nKey="login.mfa.open-authenticator">Open in authenticator app</Trans>
+            </LinkButton>
+            <Field
+              label={t('login.mfa.recovery-codes-label', 'Recovery codes')}
+              description={t(
+                'login.mfa.recovery-codes-description',
+                'Store these codes in a safe place. Each code can be used once instead of a verification code if you lose your device.'
+              )}
+            >
+              <pre className={styles.recoveryCodes}>{enrollment.recoveryCodes.join('\n')}</pre>
+            </Field>
+          </Stack>
+        )}
+        {hasSecurityKey && (
+          <Button
+            type="button"
+            variant={hasCode ? 'secondary' : 'primary'}
+            icon="key-skeleton-alt"
+            className={styles.securityKeyButton}
+            onClick={onSecurityKey}
+            disabled={isLoggingIn}
+          >
+            <Trans i18nKey="login.mfa.security-key">Use a security key or passkey</Trans>
+          </Button>
+        )}
+        {hasCode && (
+          <>
+            <Field
+              label={t('login.mfa.code-label', 'Verification code')}
+              description={
+                enrollment
+                  ? undefined
+                  : t('login.mfa.code-description', 'Enter the code of your authenticator app or a recovery code.')
+              }
+              invalid={!!errors.code}
+              error={errors.code?.message}
+            >
+              <Input
+                {...register('code', { required: t('login.mfa.code-required', 'Verification code is required') })}
+                id={codeId}
+                autoFocus
+                autoCapitalize="none"
+                autoComplete="one-time-code"
+                placeholder={t('login.mfa.code-placeholder', 'verification code')}
+              />
+            </Field>
+            <Button
+              type="submit"
+              data-testid={selectors.pages.Login.submit}
+              className={styles.submitButton}
+              disabled={isLoggingIn}
+            >
+              {isLoggingIn
+                ? t('login.mfa.submit-loading-label', 'Verifying...')
+                : t('login.mfa.submit-label', 'Verify')}
+            </Button>
+          </>
+        )}
+      </form>
+    </div>
+  );
+};
+
+export const getStyles = (theme: GrafanaTheme2) => {
+  return {
+    wrapper: css({
+      width: '100%',
+      paddingBottom: theme.spacing(2),
+    }),
+
+    recoveryCodes: css({
+      margin: 0,
+    }),
+
+    submitButton: css({
+      justifyContent: 'center',
+      width: '100%',
+    }),
+
+    securityKeyButton: css({
+      justifyContent: 'center',
+      width: '100%',
+      marginBottom: theme.spacing(2),
+    }),
+  };
+};
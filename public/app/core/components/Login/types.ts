@@ -6,3 +6,15 @@ export interface LoginDTO {
 export interface AuthNRedirectDTO {
   URL: string;
 }
+
+export interface MFAChallenge {
+  challenge: string;
+  methods: string[];
+  enrollmentRequired: boolean;
+}
+
+export interface MFAEnrollmentDTO {
+  secret: string;
+  url: string;
+  recoveryCodes: string[];
+}
@@ -22,6 +22,7 @@ import { getConfig } from 'app/core/config';
 import { getSessionExpiry, hasSessionExpiry } from 'app/core/utils/auth';
 import { loadUrlToken } from 'app/core/utils/urlToken';
 import { getDashboardAPI } from 'app/features/dashboard/api/dashboard_api';
+import { getPublicDashboardPassphraseHeaders } from 'app/features/dashboard/components/PublicDashboard/publicDashboardPassphrase';
 import { DashboardModel } from 'app/features/dashboard/state';
 import { DashboardSearchItem } from 'app/features/search/types';
 import { TokenRevokedModal } from 'app/features/users/TokenRevokedModal';
@@ -528,7 +529,9 @@ export class BackendSrv implements BackendService {
   }
 
   getPublicDashboardByUid(uid: string) {
-    return this.get<DashboardDTO>(`/api/public/dashboards/${uid}`);
+    return this.get<DashboardDTO>(`/api/public/dashboards/${uid}`, undefined, undefined, {
+      headers: getPublicDashboardPassphraseHeaders(),
+    });
   }
 
   getFolderByUid(uid: string, options: FolderRequestOptions = {}) {
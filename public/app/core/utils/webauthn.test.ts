@@ -0,0 +1,29 @@
+import { decode, encode, isWebAuthnCancelled } from './webauthn';
+
+describe('webauthn', () => {
+  it('encodes buffers as unpadded base64url', () => {
+    const bytes = new Uint8Array([251, 255, 191, 0, 1]);
+
+    expect(encode(bytes.buffer)).toBe('-_-_AAE');
+  });
+
+  it('decodes unpadded base64url', () => {
+    expect(new Uint8Array(decode('-_-_AAE'))).toEqual(new Uint8Array([251, 255, 191, 0, 1]));
+  });
+
+  it('round-trips values of every padding length', () => {
+    for (let length = 0; length < 8; length++) {
+      const bytes = new Uint8Array(length).map((_, i) => i * 37);
+
+      expect(new Uint8Array(decode(encode(bytes.buffer)))).toEqual(bytes);
+    }
+  });
+
+  it('detects cancelled ceremonies', () => {
+    const cancelled = new Error('The operation either timed out or was not allowed.');
+    cancelled.name = 'NotAllowedError';
+
+    expect(isWebAuthnCancelled(cancelled)).toBe(true);
+    expect(isWebAuthnCancelled(new Error('invalid state'))).toBe(false);
+  });
+});
@@ -0,0 +1,136 @@
+// The WebAuthn endpoints exchange the options and credentials of navigator.credentials as JSON, with the binary
+// fields encoded as unpadded base64url strings.
+
+export interface WebAuthnCredentialDescriptor {
+  type: PublicKeyCredentialType;
+  id: string;
+  transports?: AuthenticatorTransport[];
+}
+
+export interface WebAuthnCreationOptions {
+  rp: PublicKeyCredentialRpEntity;
+  user: { id: string; name: string; displayName: string };
+  challenge: string;
+  pubKeyCredParams: PublicKeyCredentialParameters[];
+  timeout: number;
+  excludeCredentials?: WebAuthnCredentialDescriptor[];
+  authenticatorSelection: AuthenticatorSelectionCriteria;
+  attestation: AttestationConveyancePreference;
+}
+
+export interface WebAuthnRequestOptions {
+  challenge: string;
+  timeout: number;
+  rpId: string;
+  allowCredentials?: WebAuthnCredentialDescriptor[];
+  userVerification: UserVerificationRequirement;
+}
+
+export interface WebAuthnRegistration {
+  id: string;
+  rawId: string;
+  type: string;
+  response: {
+    clientDataJSON: string;
+    attestationObject: string;
+    transports: string[];
+  };
+}
+
+export interface WebAuthnAssertion {
+  id: string;
+  rawId: string;
+  type: string;
+  response: {
+    clientDataJSON: string;
+    authenticatorData: string;
+    signature: string;
+    userHandle: string;
+  };
+}
+
+export function isWebAuthnSupported(): boolean {
+  return typeof window !== 'undefined' && !!window.PublicKeyCredential && !!navigator.credentials;
+}
+
+// createCredential runs the registration ceremony of a security key or passkey.
+export async function createCredential(options: WebAuthnCreationOptions): Promise<WebAuthnRegistration> {
+  const credential = (await navigator.credentials.create({
+    publicKey: {
+      ...options,
+      challenge: decode(options.challenge),
+      user: { ...options.user, id: decode(options.user.id) },
+      excludeCredentials: options.excludeCredentials?.map(toDescriptor),
+    },
+  })) as PublicKeyCredential | null;
+  if (!credential) {
+    throw new Error('No credential was created');
+  }
+
+  const response = credential.response as AuthenticatorAttestationResponse;
+  return {
+    id: credential.id,
+    rawId: encode(credential.rawId),
+    type: credential.type,
+    response: {
+      clientDataJSON: encode(response.clientDataJSON),
+      attestationObject: encode(response.attestationObject),
+      transports: response.getTransports?.() ?? [],
+    },
+  };
+}
+
+// getAssertion runs the authentication ceremony of a security key or passkey.
+export async function getAssertion(options: WebAuthnRequestOptions): Promise<WebAuthnAssertion> {
+  const credential = (await navigator.credentials.get({
+    publicKey: {
+      ...options,
+      challenge: decode(options.challenge),
+      allowCredentials: options.allowCredentials?.map(toDescriptor),
+    },
+  })) as PublicKeyCredential | null;
+  if (!credential) {
+    throw new Error('No credential was selected');
+  }
+
+  const response = credential.response as AuthenticatorAssertionResponse;
+  return {
+    id: credential.id,
+    rawId: encode(credential.rawId),
+    type: credential.type,
+    response: {
+      clientDataJSON: encode(response.clientDataJSON),
+      authenticatorData: encode(response.authenticatorData),
+      signature: encode(response.signature),
+      userHandle: response.userHandle ? encode(response.userHandle) : '',
+    },
+  };
+}
+
+// isWebAuthnCancelled returns whether the user dismissed the browser dialog or the ceremony timed out.
+export function isWebAuthnCancelled(err: unknown): boolean {
+  return err instanceof Error && (err.name === 'NotAllowedError' || err.name === 'AbortError');
+}
+
+function toDescriptor(descriptor: WebAuthnCredentialDescriptor): PublicKeyCredentialDescriptor {
+  return { ...descriptor, id: decode(descriptor.id) };
+}
+
+export function encode(buffer: ArrayBuffer): string {
+  const bytes = new Uint8Array(buffer);
+  let binary = '';
+  for (let i = 0; i < bytes.length; i++) {
+    binary += String.fromCharCode(bytes[i]);
+  }
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+}
+
+export function decode(value: string): ArrayBuffer {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/');
+  const binary = atob(base64.padEnd(base64.length + ((4 - (base64.length % 4)) % 4), '='));
+  const bytes = new Uint8Array(binary.length);
+  for (let i = 0; i < binary.length; i++) {
+    bytes[i] = binary.charCodeAt(i);
+  }
+  return bytes.buffer;
+}
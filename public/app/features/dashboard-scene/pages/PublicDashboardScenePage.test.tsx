@@ -1,4 +1,5 @@
 import { screen, waitForElementToBeRemoved } from '@testing-library/react';
+import userEvent from '@testing-library/user-event';
 import { Route, Routes } from 'react-router-dom-v5-compat';
 import { of } from 'rxjs';
 import { render } from 'test/test-utils';
@@ -222,6 +223,48 @@ describe('given unavailable public dashboard', () => {
   });
 });
 
+describe('given passphrase protected public dashboard', () => {
+  afterEach(() => {
+    config.publicDashboardPassphrase = undefined;
+  });
+
+  it('asks for the passphrase and renders the dashboard once it is entered', async () => {
+    const accessToken = 'protected-pubdash-access-token';
+    config.publicDashboardAccessToken = accessToken;
+    getDashboardScenePageStateManager().clearDashboardCache();
+    const loadDashboardMock = setupLoadDashboardMock({
+      dashboard: simpleDashboard,
+      meta: { publicDashboardPassphraseRequired: true },
+    });
+    setup(accessToken);
+
+    expect(await screen.findByTestId(publicDashboardSelector.Passphrase.container)).toBeInTheDocument();
+    expect(screen.queryByTestId(publicDashboardSceneSelector.page)).not.toBeInTheDocument();
+
+    loadDashboardMock.mockResolvedValue({ dashboard: simpleDashboard, meta: {} });
+    await userEvent.type(screen.getByTestId(publicDashboardSelector.Passphrase.input), 'open sesame');
+    await userEvent.click(screen.getByTestId(publicDashboardSelector.Passphrase.submit));
+
+    await waitForDashboardGridToRender();
+    expect(config.publicDashboardPassphrase).toBe('open sesame');
+    expect(loadDashboardMock).toHaveBeenCalledTimes(2);
+  });
+
+  it('tells the viewer when the passphrase is not correct', async () => {
+    const accessToken = 'mismatched-pubdash-access-token';
+    config.publicDashboardAccessToken = accessToken;
+    getDashboardScenePageStateManager().clearDashboardCache();
+    setupLoadDashboardMock({
+      dashboard: simpleDashboard,
+      meta: { publicDashboardPassphraseRequired: true, publicDashboardPassphraseInvalid: true },
+    });
+    setup(accessToken);
+
+    expect(await screen.findByTestId(publicDashboardSelector.Passphrase.container)).toBeInTheDocument();
+    expect(screen.getByText('The passphrase is not correct')).toBeInTheDocument();
+  });
+});
+
 interface VizOptions {
   content: string;
 }
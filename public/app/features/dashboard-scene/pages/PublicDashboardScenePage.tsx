@@ -4,6 +4,7 @@ import { useParams } from 'react-router-dom-v5-compat';
 
 import { GrafanaTheme2, PageLayoutType } from '@grafana/data';
 import { selectors as e2eSelectors } from '@grafana/e2e-selectors';
+import { config } from '@grafana/runtime';
 import { SceneComponentProps, UrlSyncContextProvider } from '@grafana/scenes';
 import { Icon, Stack, useStyles2 } from '@grafana/ui';
 import { Page } from 'app/core/components/Page/Page';
@@ -11,6 +12,7 @@ import PageLoader from 'app/core/components/PageLoader/PageLoader';
 import { GrafanaRouteComponentProps } from 'app/core/navigation/types';
 import { PublicDashboardFooter } from 'app/features/dashboard/components/PublicDashboard/PublicDashboardsFooter';
 import { PublicDashboardNotAvailable } from 'app/features/dashboard/components/PublicDashboardNotAvailable/PublicDashboardNotAvailable';
+import { PublicDashboardPassphrase } from 'app/features/dashboard/components/PublicDashboardPassphrase/PublicDashboardPassphrase';
 import {
   PublicDashboardPageRouteParams,
   PublicDashboardPageRouteSearchParams,
@@ -59,6 +61,22 @@ export function PublicDashboardScenePage({ route }: Props) {
     return <PublicDashboardNotAvailable />;
   }
 
+  if (dashboard.state.meta.publicDashboardPassphraseRequired) {
+    const onSubmitPassphrase = (passphrase: string) => {
+      config.publicDashboardPassphrase = passphrase;
+      stateManager.clearDashboardCache();
+      stateManager.clearSceneCache();
+      stateManager.loadDashboard({ uid: accessToken, route: DashboardRoutes.Public });
+    };
+
+    return (
+      <PublicDashboardPassphrase
+        invalid={dashboard.state.meta.publicDashboardPassphraseInvalid}
+        onSubmit={onSubmitPassphrase}
+      />
+    );
+  }
+
   // if no time picker render without url sync
   if (dashboard.state.controls?.state.hideTimeControls) {
     return <PublicDashboardSceneRenderer model={dashboard} />;
@@ -28,6 +28,7 @@ import {
 import { sortedDeepCloneWithoutNulls } from 'app/core/utils/object';
 import { getPanelDataFrames } from 'app/features/dashboard/components/HelpWizard/utils';
 import { DASHBOARD_SCHEMA_VERSION } from 'app/features/dashboard/state/DashboardMigrator';
+import { getLibraryPanelRef } from 'app/features/library-panels/pinnedVersion';
 import { GrafanaQueryType } from 'app/plugins/datasource/grafana/types';
 
 import { DashboardDataLayerSet } from '../scene/DashboardDataLayerSet';
@@ -182,10 +183,7 @@ export function vizPanelToPanel(
       id: getPanelIdForVizPanel(vizPanel),
       title: libPanel!.state.title,
       gridPos: gridPos,
-      libraryPanel: {
-        name: libPanel!.state.name,
-        uid: libPanel!.state.uid,
-      },
+      libraryPanel: getLibraryPanelRef(libPanel!.state),
     } as Panel;
 
     return panel;
@@ -0,0 +1,12 @@
+import { config } from '@grafana/runtime';
+
+export const PUBLIC_DASHBOARD_PASSPHRASE_HEADER = 'X-Grafana-Public-Dashboard-Passphrase';
+
+// getPublicDashboardPassphraseHeaders returns the header with the passphrase the viewer entered for a passphrase
+// protected public dashboard, every request of the public dashboard API has to carry it.
+export function getPublicDashboardPassphraseHeaders(): Record<string, string> | undefined {
+  if (!config.publicDashboardPassphrase) {
+    return undefined;
+  }
+  return { [PUBLIC_DASHBOARD_PASSPHRASE_HEADER]: config.publicDashboardPassphrase };
+}
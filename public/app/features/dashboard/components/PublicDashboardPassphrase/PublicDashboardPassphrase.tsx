@@ -0,0 +1,96 @@
+import { css, cx } from '@emotion/css';
+import { FormEvent, useState } from 'react';
+
+import { GrafanaTheme2 } from '@grafana/data';
+import { selectors as e2eSelectors } from '@grafana/e2e-selectors';
+import { Button, Field, Input, useStyles2 } from '@grafana/ui';
+import { Branding } from 'app/core/components/Branding/Branding';
+import { getLoginStyles } from 'app/core/components/Login/LoginLayout';
+import { t, Trans } from 'app/core/internationalization';
+
+const selectors = e2eSelectors.pages.PublicDashboard.Passphrase;
+
+interface Props {
+  invalid?: boolean;
+  onSubmit: (passphrase: string) => void;
+}
+
+export const PublicDashboardPassphrase = ({ invalid, onSubmit }: Props) => {
+  const styles = useStyles2(getStyles);
+  const loginStyles = useStyles2(getLoginStyles);
+  const loginBoxBackground = Branding.LoginBoxBackground();
+  const [passphrase, setPassphrase] = useState('');
+
+  const onFormSubmit = (event: FormEvent<HTMLFormElement>) => {
+    event.preventDefault();
+    if (passphrase) {
+      onSubmit(passphrase);
+    }
+  };
+
+  return (
+    <Branding.LoginBackground className={styles.container} data-testid={selectors.container}>
+      <div className={cx(styles.box, loginBoxBackground)}>
+        <Branding.LoginLogo className={loginStyles.loginLogo} />
+        <p className={styles.title}>
+          <Trans i18nKey="public-dashboard.passphrase.title">This dashboard is protected with a passphrase</Trans>
+        </p>
+        <form className={styles.form} onSubmit={onFormSubmit}>
+          <Field
+            label={t('public-dashboard.passphrase.label', 'Passphrase')}
+            invalid={invalid}
+            error={invalid ? t('public-dashboard.passphrase.invalid', 'The passphrase is not correct') : undefined}
+          >
+            <Input
+              type="password"
+              autoFocus
+              autoComplete="off"
+              value={passphrase}
+              onChange={(event) => setPassphrase(event.currentTarget.value)}
+              data-testid={selectors.input}
+            />
+          </Field>
+          <Button type="submit" className={styles.submit} disabled={!passphrase} data-testid={selectors.submit}>
+            <Trans i18nKey="public-dashboard.passphrase.submit">View dashboard</Trans>
+          </Button>
+        </form>
+      </div>
+    </Branding.LoginBackground>
+  );
+};
+
+const getStyles = (theme: GrafanaTheme2) => ({
+  container: css({
+    display: 'flex',
+    justifyContent: 'center',
+    alignItems: 'center',
+    height: '100%',
+
+    ':before': {
+      opacity: 1,
+    },
+  }),
+  box: css({
+    width: '608px',
+    display: 'flex',
+    alignItems: 'center',
+    flexDirection: 'column',
+    gap: theme.spacing(4),
+    zIndex: 1,
+    borderRadius: theme.shape.borderRadius(4),
+    padding: theme.spacing(6, 8),
+    opacity: 1,
+  }),
+  title: css({
+    fontSize: theme.typography.h3.fontSize,
+    textAlign: 'center',
+    margin: 0,
+  }),
+  form: css({
+    width: '100%',
+  }),
+  submit: css({
+    width: '100%',
+    justifyContent: 'center',
+  }),
+});
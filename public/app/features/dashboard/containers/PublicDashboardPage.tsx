@@ -1,10 +1,11 @@
 import { css } from '@emotion/css';
-import { useEffect } from 'react';
+import { useCallback, useEffect } from 'react';
 import { useLocation, useParams } from 'react-router-dom-v5-compat';
 import { usePrevious } from 'react-use';
 
 import { GrafanaTheme2, PageLayoutType, TimeZone } from '@grafana/data';
 import { selectors as e2eSelectors } from '@grafana/e2e-selectors/src';
+import { config } from '@grafana/runtime';
 import { PageToolbar, useStyles2 } from '@grafana/ui';
 import { Page } from 'app/core/components/Page/Page';
 import { useGrafana } from 'app/core/context/GrafanaContext';
@@ -22,6 +23,7 @@ import { DashboardLoading } from '../components/DashboardLoading/DashboardLoadin
 import { PublicDashboardFooter } from '../components/PublicDashboard/PublicDashboardsFooter';
 import { useGetPublicDashboardConfig } from '../components/PublicDashboard/usePublicDashboardConfig';
 import { PublicDashboardNotAvailable } from '../components/PublicDashboardNotAvailable/PublicDashboardNotAvailable';
+import { PublicDashboardPassphrase } from '../components/PublicDashboardPassphrase/PublicDashboardPassphrase';
 import { DashboardGrid } from '../dashgrid/DashboardGrid';
 import { getTimeSrv } from '../services/TimeSrv';
 import { DashboardModel } from '../state';
@@ -66,7 +68,7 @@ const PublicDashboardPage = (props: Props) => {
   const dashboardState = useSelector((store) => store.dashboard);
   const dashboard = dashboardState.getModel();
 
-  useEffect(() => {
+  const loadDashboard = useCallback(() => {
     dispatch(
       initDashboard({
         routeName: route.routeName,
@@ -77,6 +79,10 @@ const PublicDashboardPage = (props: Props) => {
     );
   }, [route.routeName, accessToken, context.keybindings, dispatch]);
 
+  useEffect(() => {
+    loadDashboard();
+  }, [loadDashboard]);
+
   useEffect(() => {
     if (prevProps?.location.search !== location.search) {
       const prevUrlParams = prevProps?.queryParams;
@@ -108,6 +114,20 @@ const PublicDashboardPage = (props: Props) => {
     return <PublicDashboardNotAvailable />;
   }
 
+  if (dashboard.meta.publicDashboardPassphraseRequired) {
+    const onSubmitPassphrase = (passphrase: string) => {
+      config.publicDashboardPassphrase = passphrase;
+      loadDashboard();
+    };
+
+    return (
+      <PublicDashboardPassphrase
+        invalid={dashboard.meta.publicDashboardPassphraseInvalid}
+        onSubmit={onSubmitPassphrase}
+      />
+    );
+  }
+
   return (
     <Page pageNav={{ text: dashboard.title }} layout={PageLayoutType.Custom} data-testid={selectors.page}>
       <Toolbar dashboard={dashboard} />
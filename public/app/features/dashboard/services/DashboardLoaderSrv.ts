@@ -67,6 +67,17 @@ export class DashboardLoaderSrv {
             e.data.statusCode === 404 && e.data.messageId === 'publicdashboards.notFound';
           const isDashboardNotFound =
             e.data.statusCode === 404 && e.data.messageId === 'publicdashboards.dashboardNotFound';
+          const isPassphraseRequired =
+            e.data.statusCode === 401 && e.data.messageId === 'publicdashboards.passphraseRequired';
+          const isPassphraseInvalid =
+            e.data.statusCode === 401 && e.data.messageId === 'publicdashboards.passphraseMismatch';
+          // the alert of the error tells to try again later, the passphrase prompt stays to do so
+          const isPassphraseRateLimited =
+            e.data.statusCode === 429 && e.data.messageId === 'publicdashboards.passphraseRateLimited';
+          if (isPassphraseRequired || isPassphraseInvalid) {
+            // the passphrase prompt of the page shows the error instead of an alert
+            e.isHandled = true;
+          }
 
           const dashboardModel = this._dashboardLoadFailed(
             isPublicDashboardPaused ? 'Public Dashboard paused' : 'Public Dashboard Not found',
@@ -78,6 +89,9 @@ export class DashboardLoaderSrv {
               ...dashboardModel.meta,
               publicDashboardEnabled: isPublicDashboardNotFound ? undefined : !isPublicDashboardPaused,
               dashboardNotFound: isPublicDashboardNotFound || isDashboardNotFound,
+              publicDashboardPassphraseRequired:
+                isPassphraseRequired || isPassphraseInvalid || isPassphraseRateLimited,
+              publicDashboardPassphraseInvalid: isPassphraseInvalid,
             },
           };
         });
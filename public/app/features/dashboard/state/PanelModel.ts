@@ -25,6 +25,7 @@ import { getTemplateSrv, RefreshEvent } from '@grafana/runtime';
 import { LibraryPanel, LibraryPanelRef } from '@grafana/schema';
 import config from 'app/core/config';
 import { safeStringifyValue } from 'app/core/utils/explore';
+import { getLibraryPanelRef, getPinnedVersion } from 'app/features/library-panels/pinnedVersion';
 import { QueryGroupOptions } from 'app/types';
 import {
   PanelOptionsChangedEvent,
@@ -335,10 +336,7 @@ export class PanelModel implements DataConfigSource, IPanelModel {
             id,
             title,
             gridPos,
-            libraryPanel: {
-              uid: libraryPanel.uid,
-              name: libraryPanel.name,
-            },
+            libraryPanel: getLibraryPanelRef(libraryPanel),
           };
         }
 
@@ -696,7 +694,9 @@ export class PanelModel implements DataConfigSource, IPanelModel {
       }
       (this as any)[key] = val; // :grimmice:
     }
-    this.libraryPanel = libPanel;
+    // keep the version pinned by the dashboard
+    const pinnedVersion = getPinnedVersion(this.libraryPanel);
+    this.libraryPanel = pinnedVersion ? Object.assign({}, libPanel, { pinnedVersion }) : libPanel;
   }
 
   unlinkLibraryPanel() {
@@ -0,0 +1,25 @@
+import { getLibraryPanelRef, getPinnedVersion } from './pinnedVersion';
+
+describe('getPinnedVersion', () => {
+  it('returns the pinned version', () => {
+    expect(getPinnedVersion({ uid: 'uid', name: 'name', pinnedVersion: 3 })).toBe(3);
+  });
+
+  it('returns undefined when the library panel follows the latest version', () => {
+    expect(getPinnedVersion(undefined)).toBeUndefined();
+    expect(getPinnedVersion({ uid: 'uid', name: 'name' })).toBeUndefined();
+    expect(getPinnedVersion({ uid: 'uid', name: 'name', pinnedVersion: 0 })).toBeUndefined();
+  });
+});
+
+describe('getLibraryPanelRef', () => {
+  it('keeps the pinned version', () => {
+    const libraryPanel = { uid: 'uid', name: 'name', pinnedVersion: 2, model: {}, version: 2 };
+    expect(getLibraryPanelRef(libraryPanel)).toEqual({ uid: 'uid', name: 'name', pinnedVersion: 2 });
+  });
+
+  it('only keeps the uid and the name of a library panel that follows the latest version', () => {
+    const libraryPanel = { uid: 'uid', name: 'name', model: {}, version: 4 };
+    expect(getLibraryPanelRef(libraryPanel)).toEqual({ uid: 'uid', name: 'name' });
+  });
+});
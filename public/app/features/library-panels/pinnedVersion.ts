@@ -0,0 +1,33 @@
+import { LibraryPanelRef } from '@grafana/schema';
+
+/**
+ * The reference of a library panel in a dashboard. A dashboard pins a version of the library panel with
+ * pinnedVersion, it follows the latest version of the library panel when pinnedVersion is not set.
+ */
+export interface PinnableLibraryPanelRef extends LibraryPanelRef {
+  pinnedVersion?: number;
+}
+
+export function getPinnedVersion(libraryPanel?: object): number | undefined {
+  if (
+    libraryPanel &&
+    'pinnedVersion' in libraryPanel &&
+    typeof libraryPanel.pinnedVersion === 'number' &&
+    libraryPanel.pinnedVersion > 0
+  ) {
+    return libraryPanel.pinnedVersion;
+  }
+  return undefined;
+}
+
+/**
+ * Returns the reference saved in the dashboard for a library panel, keeping its pinned version.
+ */
+export function getLibraryPanelRef(libraryPanel: LibraryPanelRef): PinnableLibraryPanelRef {
+  const ref: PinnableLibraryPanelRef = { uid: libraryPanel.uid, name: libraryPanel.name };
+  const pinnedVersion = getPinnedVersion(libraryPanel);
+  if (pinnedVersion) {
+    ref.pinnedVersion = pinnedVersion;
+  }
+  return ref;
+}
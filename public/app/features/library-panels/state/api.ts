@@ -52,11 +52,16 @@ export async function getLibraryPanels({
   return result;
 }
 
-export async function getLibraryPanel(uid: string, isHandled = false): Promise<LibraryElementDTO> {
+export async function getLibraryPanel(
+  uid: string,
+  isHandled = false,
+  pinnedVersion?: number
+): Promise<LibraryElementDTO> {
   const response = await lastValueFrom(
     getBackendSrv().fetch<{ result: LibraryElementDTO }>({
       method: 'GET',
       url: `/api/library-elements/${uid}`,
+      params: pinnedVersion ? { version: pinnedVersion } : undefined,
       showSuccessAlert: !isHandled,
       showErrorAlert: !isHandled,
     })
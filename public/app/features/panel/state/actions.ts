@@ -1,5 +1,6 @@
 import { DataTransformerConfig, FieldConfigSource, getPanelOptionsWithDefaults } from '@grafana/data';
 import { PanelModel } from 'app/features/dashboard/state/PanelModel';
+import { getPinnedVersion } from 'app/features/library-panels/pinnedVersion';
 import { getLibraryPanel } from 'app/features/library-panels/state/api';
 import { LibraryElementDTO } from 'app/features/library-panels/types';
 import { getPanelPluginNotFound } from 'app/features/panel/components/PanelPluginError';
@@ -152,7 +153,7 @@ export function loadLibraryPanelAndUpdate(panel: PanelModel): ThunkResult<void>
   return async (dispatch, getStore) => {
     const uid = panel.libraryPanel!.uid!;
     try {
-      const libPanel = await getLibraryPanel(uid, true);
+      const libPanel = await getLibraryPanel(uid, true, getPinnedVersion(panel.libraryPanel));
       panel.initLibraryPanel(libPanel);
 
       const dashboard = getStore().dashboard.getModel();
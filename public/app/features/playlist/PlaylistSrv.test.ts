@@ -5,33 +5,20 @@ import configureMockStore from 'redux-mock-store';
 import { locationService } from '@grafana/runtime';
 import { setStore } from 'app/store/store';
 
-import { DashboardQueryResult } from '../search/service';
-
 import { PlaylistSrv } from './PlaylistSrv';
-import { Playlist, PlaylistItem } from './types';
+import { PlaylistDashboard } from './types';
+
+const mockLoadPlaylistDashboards = jest.fn();
 
 jest.mock('./api', () => ({
-  getPlaylistAPI: () => ({
-    getPlaylist: jest.fn().mockReturnValue({
-      interval: '1s',
-      uid: 'xyz',
-      name: 'The display',
-      items: [
-        { type: 'dashboard_by_uid', value: 'aaa' },
-        { type: 'dashboard_by_uid', value: 'bbb' },
-      ],
-    } as Playlist),
-  }),
-  loadDashboards: (items: PlaylistItem[]) => {
-    return Promise.resolve(
-      items.map((v) => ({
-        ...v, // same item with dashboard URLs filled in
-        dashboards: [{ url: `/url/to/${v.value}` } as unknown as DashboardQueryResult],
-      }))
-    );
-  },
+  loadPlaylistDashboards: (uid: string) => mockLoadPlaylistDashboards(uid),
 }));
 
+const dashboards: PlaylistDashboard[] = [
+  { uid: 'aaa', title: 'A', url: '/url/to/aaa', interval: '1s' },
+  { uid: 'bbb', title: 'B', url: '/url/to/bbb', interval: '5m' },
+];
+
 const mockStore = configureMockStore();
 
 setStore(
@@ -76,6 +63,7 @@ describe('PlaylistSrv', () => {
 
   beforeEach(() => {
     jest.clearAllMocks();
+    mockLoadPlaylistDashboards.mockResolvedValue(dashboards);
 
     srv = createPlaylistSrv();
     [hrefMock, unmockLocation] = mockWindowLocation();
@@ -139,4 +127,38 @@ describe('PlaylistSrv', () => {
     expect((srv as any).validPlaylistUrl).toBe('/url/to/bbb');
     expect(srv.state.isPlaying).toBe(true);
   });
+
+  it('plays each dashboard for its interval', async () => {
+    jest.useFakeTimers();
+    await srv.start('foo');
+
+    // eslint-disable-next-line
+    expect((srv as any).validPlaylistUrl).toBe('/url/to/aaa');
+    jest.advanceTimersByTime(1000);
+    // eslint-disable-next-line
+    expect((srv as any).validPlaylistUrl).toBe('/url/to/bbb');
+    jest.advanceTimersByTime(60 * 1000);
+    // eslint-disable-next-line
+    expect((srv as any).validPlaylistUrl).toBe('/url/to/bbb');
+
+    srv.stop();
+    jest.useRealTimers();
+  });
+
+  it('loads the dashboards of the current schedule again after each loop', async () => {
+    await srv.start('foo');
+    expect(mockLoadPlaylistDashboards).toHaveBeenCalledWith('foo');
+
+    const night: PlaylistDashboard[] = [{ uid: 'ccc', title: 'C', url: '/url/to/ccc', interval: '1s' }];
+    mockLoadPlaylistDashboards.mockResolvedValue(night);
+
+    srv.next();
+    srv.next();
+    await new Promise((resolve) => setTimeout(resolve, 0));
+    expect(mockLoadPlaylistDashboards).toHaveBeenCalledTimes(2);
+
+    srv.next();
+    // eslint-disable-next-line
+    expect((srv as any).validPlaylistUrl).toBe('/url/to/ccc');
+  });
 });
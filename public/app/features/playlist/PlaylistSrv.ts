@@ -5,8 +5,8 @@ import { locationUtil, urlUtil, rangeUtil } from '@grafana/data';
 import { locationService } from '@grafana/runtime';
 import { StateManagerBase } from 'app/core/services/StateManagerBase';
 
-import { getPlaylistAPI, loadDashboards } from './api';
-import { PlaylistAPI } from './types';
+import { loadPlaylistDashboards } from './api';
+import { PlaylistDashboard } from './types';
 
 export const queryParamsToPreserve: { [key: string]: boolean } = {
   kiosk: true,
@@ -23,26 +23,24 @@ export interface PlaylistSrvState {
 
 export class PlaylistSrv extends StateManagerBase<PlaylistSrvState> {
   private nextTimeoutId: ReturnType<typeof setTimeout> | undefined;
-  private urls: string[] = []; // the URLs we need to load
+  private dashboards: PlaylistDashboard[] = []; // the dashboards we need to load
   private index = 0;
-  private declare interval: number;
+  private declare playlistUid: string;
   private declare startUrl: string;
   private numberOfLoops = 0;
   private declare validPlaylistUrl: string;
   private locationListenerUnsub?: () => void;
-  private api: PlaylistAPI;
 
   public constructor() {
     super({ isPlaying: false });
 
     this.locationUpdated = this.locationUpdated.bind(this);
-    this.api = getPlaylistAPI();
   }
 
   next() {
     clearTimeout(this.nextTimeoutId);
 
-    const playedAllDashboards = this.index > this.urls.length - 1;
+    const playedAllDashboards = this.index > this.dashboards.length - 1;
     if (playedAllDashboards) {
       this.numberOfLoops++;
 
@@ -53,16 +51,19 @@ export class PlaylistSrv extends StateManagerBase<PlaylistSrvState> {
         return;
       }
       this.index = 0;
+
+      // The scheduled playlist may have changed, the next loop plays the dashboards of the current schedule
+      this.reloadDashboards();
     }
 
-    const url = this.urls[this.index];
+    const dashboard = this.dashboards[this.index];
     const queryParams = locationService.getSearchObject();
     const filteredParams = pickBy(queryParams, (value: unknown, key: string) => queryParamsToPreserve[key]);
-    const nextDashboardUrl = locationUtil.stripBaseFromUrl(url);
+    const nextDashboardUrl = locationUtil.stripBaseFromUrl(dashboard.url);
 
     this.index++;
     this.validPlaylistUrl = nextDashboardUrl;
-    this.nextTimeoutId = setTimeout(() => this.next(), this.interval);
+    this.nextTimeoutId = setTimeout(() => this.next(), rangeUtil.intervalToMs(dashboard.interval));
 
     locationService.push(nextDashboardUrl + '?' + urlUtil.toUrlParams(filteredParams));
   }
@@ -89,36 +90,32 @@ export class PlaylistSrv extends StateManagerBase<PlaylistSrvState> {
 
     // setup location tracking
     this.locationListenerUnsub = locationService.getHistory().listen(this.locationUpdated);
-    const urls: string[] = [];
-
-    let playlist = await this.api.getPlaylist(playlistUid);
-    if (!playlist.items?.length) {
-      // alert
-      return;
-    }
-
-    this.interval = rangeUtil.intervalToMs(playlist.interval);
-
-    const items = await loadDashboards(playlist.items);
-    for (const item of items) {
-      if (item.dashboards) {
-        for (const dash of item.dashboards) {
-          urls.push(dash.url);
-        }
-      }
-    }
 
-    if (!urls.length) {
+    // The server resolves the playlist, or the playlist scheduled now, to the dashboards to play
+    this.playlistUid = playlistUid;
+    const dashboards = await loadPlaylistDashboards(playlistUid);
+    if (!dashboards.length) {
       // alert... not found, etc
       return;
     }
 
-    this.urls = urls;
+    this.dashboards = dashboards;
     this.setState({ isPlaying: true });
     this.next();
     return;
   }
 
+  private async reloadDashboards() {
+    try {
+      const dashboards = await loadPlaylistDashboards(this.playlistUid);
+      if (dashboards.length && this.state.isPlaying) {
+        this.dashboards = dashboards;
+      }
+    } catch (e) {
+      // keep playing the current dashboards
+    }
+  }
+
   stop() {
     if (!this.state.isPlaying) {
       return;
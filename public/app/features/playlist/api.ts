@@ -12,7 +12,7 @@ import { ScopedResourceClient } from '../apiserver/client';
 import { Resource, ResourceForCreate, ResourceClient } from '../apiserver/types';
 import { DashboardQueryResult, getGrafanaSearcher, SearchQuery } from '../search/service';
 
-import { Playlist, PlaylistItem, PlaylistAPI } from './types';
+import { Playlist, PlaylistDashboard, PlaylistItem, PlaylistAPI } from './types';
 
 class LegacyAPI implements PlaylistAPI {
   async getAllPlaylist(): Promise<Playlist[]> {
@@ -138,6 +138,11 @@ async function withErrorHandling(apiCall: () => Promise<void>, message = 'Playli
   }
 }
 
+/** Returns the dashboards to play, with the playlist schedules and the item intervals applied by the server */
+export async function loadPlaylistDashboards(uid: string): Promise<PlaylistDashboard[]> {
+  return getBackendSrv().get<PlaylistDashboard[]>(`/api/playlists/${uid}/dashboards`);
+}
+
 /** Returns a copy with the dashboards loaded */
 export async function loadDashboards(items: PlaylistItem[]): Promise<PlaylistItem[]> {
   let idx = 0;
@@ -33,6 +33,21 @@ export interface Playlist {
   items?: PlaylistItem[];
 }
 
+/**
+ * A dashboard to play, resolved by the server from the items of the playlist
+ * or of the playlist scheduled at the current time.
+ */
+export interface PlaylistDashboard {
+  uid: string;
+  title: string;
+  url: string;
+
+  /**
+   * Time to show the dashboard, the interval of its item or of the playlist.
+   */
+  interval: string;
+}
+
 export interface PlaylistItem {
   /**
    * Type of the item.
@@ -0,0 +1,72 @@
+import { render, screen, waitFor } from '@testing-library/react';
+import userEvent from '@testing-library/user-event';
+
+import { UserSecurityKeys } from './UserSecurityKeys';
+import { api } from './api';
+
+jest.mock('app/core/utils/webauthn', () => ({
+  ...jest.requireActual('app/core/utils/webauthn'),
+  isWebAuthnSupported: () => true,
+}));
+
+jest.mock('./api', () => ({
+  api: {
+    loadSecurityKeys: jest.fn(),
+    registerSecurityKey: jest.fn(),
+    deleteSecurityKey: jest.fn(),
+  },
+}));
+
+const securityKey = {
+  id: 1,
+  name: 'YubiKey',
+  aaguid: '',
+  transports: ['usb'],
+  created: '2024-01-01T00:00:00Z',
+};
+
+describe('UserSecurityKeys', () => {
+  beforeEach(() => {
+    jest.mocked(api.loadSecurityKeys).mockReset().mockResolvedValue([securityKey]);
+    jest.mocked(api.registerSecurityKey).mockReset();
+    jest.mocked(api.deleteSecurityKey).mockReset().mockResolvedValue();
+  });
+
+  it('lists the registered security keys', async () => {
+    render(<UserSecurityKeys />);
+
+    expect(await screen.findByText('YubiKey')).toBeInTheDocument();
+  });
+
+  it('registers a security key with its name', async () => {
+    jest.mocked(api.registerSecurityKey).mockResolvedValue({ ...securityKey, id: 2, name: 'Phone' });
+
+    render(<UserSecurityKeys />);
+
+    await userEvent.type(screen.getByPlaceholderText('e.g. YubiKey'), 'Phone');
+    await userEvent.click(screen.getByRole('button', { name: 'Register a security key or passkey' }));
+
+    await waitFor(() => expect(api.registerSecurityKey).toHaveBeenCalledWith('Phone'));
+    await waitFor(() => expect(api.loadSecurityKeys).toHaveBeenCalledTimes(2));
+  });
+
+  it('shows an error when the registration is cancelled', async () => {
+    const cancelled = new Error('The operation either timed out or was not allowed.');
+    cancelled.name = 'NotAllowedError';
+    jest.mocked(api.registerSecurityKey).mockRejectedValue(cancelled);
+
+    render(<UserSecurityKeys />);
+
+    await userEvent.click(screen.getByRole('button', { name: 'Register a security key or passkey' }));
+
+    expect(await screen.findByText('The security key or passkey was not registered')).toBeInTheDocument();
+  });
+
+  it('revokes a security key', async () => {
+    render(<UserSecurityKeys />);
+
+    await userEvent.click(await screen.findByRole('button', { name: 'Revoke security key' }));
+
+    await waitFor(() => expect(api.deleteSecurityKey).toHaveBeenCalledWith(1));
+  });
+});
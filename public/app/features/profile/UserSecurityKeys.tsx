@@ -0,0 +1,129 @@
+import { css } from '@emotion/css';
+import { useId, useState } from 'react';
+import { useAsyncFn, useMount } from 'react-use';
+
+import { GrafanaTheme2 } from '@grafana/data';
+import { isFetchError } from '@grafana/runtime';
+import { Alert, Button, Field, Icon, Input, LoadingPlaceholder, Stack, useStyles2 } from '@grafana/ui';
+import { t, Trans } from 'app/core/internationalization';
+import { formatDate } from 'app/core/internationalization/dates';
+import { isWebAuthnCancelled, isWebAuthnSupported } from 'app/core/utils/webauthn';
+
+import { api } from './api';
+
+export const UserSecurityKeys = () => {
+  const styles = useStyles2(getStyles);
+  const nameId = useId();
+  const [name, setName] = useState('');
+  const [error, setError] = useState<string>();
+
+  const [{ value: securityKeys = [], loading }, loadSecurityKeys] = useAsyncFn(() => api.loadSecurityKeys(), []);
+  useMount(() => {
+    loadSecurityKeys();
+  });
+
+  const [{ loading: isRegistering }, registerSecurityKey] = useAsyncFn(async () => {
+    setError(undefined);
+    try {
+      await api.registerSecurityKey(name);
+      setName('');
+      await loadSecurityKeys();
+    } catch (err) {
+      if (isWebAuthnCancelled(err)) {
+        setError(t('user-security-keys.error.cancelled', 'The security key or passkey was not registered'));
+      } else if (isFetchError<{ message?: string }>(err) && err.data?.message) {
+        setError(err.data.message);
+      } else {
+        setError(t('user-security-keys.error.unknown', 'Failed to register the security key or passkey'));
+      }
+    }
+  }, [name, loadSecurityKeys]);
+
+  const deleteSecurityKey = async (id: number) => {
+    await api.deleteSecurityKey(id);
+    await loadSecurityKeys();
+  };
+
+  if (!isWebAuthnSupported()) {
+    return null;
+  }
+
+  return (
+    <div className={styles.wrapper}>
+      <h3 className="page-sub-heading">
+        <Trans i18nKey="user-security-keys.title">Security keys and passkeys</Trans>
+      </h3>
+      {loading && securityKeys.length === 0 ? (
+        <LoadingPlaceholder text={<Trans i18nKey="user-security-keys.loading">Loading security keys...</Trans>} />
+      ) : (
+        securityKeys.length > 0 && (
+          <table className="filter-table form-inline">
+            <thead>
+              <tr>
+                <th>
+                  <Trans i18nKey="user-security-keys.name-column">Name</Trans>
+                </th>
+                <th>
+                  <Trans i18nKey="user-security-keys.created-column">Registered</Trans>
+                </th>
+                <th>
+                  <Trans i18nKey="user-security-keys.last-used-column">Last used</Trans>
+                </th>
+                <th></th>
+              </tr>
+            </thead>
+            <tbody>
+              {securityKeys.map((securityKey) => (
+                <tr key={securityKey.id}>
+                  <td>{securityKey.name}</td>
+                  <td>{formatDate(securityKey.created, { dateStyle: 'long' })}</td>
+                  <td>{securityKey.lastUsed ? formatDate(securityKey.lastUsed, { dateStyle: 'long' }) : '-'}</td>
+                  <td>
+                    <Button
+                      size="sm"
+                      variant="destructive"
+                      tooltip={t('user-security-keys.revoke', 'Revoke security key')}
+                      onClick={() => deleteSecurityKey(securityKey.id)}
+                      aria-label={t('user-security-keys.revoke', 'Revoke security key')}
+                    >
+                      <Icon name="trash-alt" />
+                    </Button>
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )
+      )}
+      {error && (
+        <Alert severity="error" title={t('user-security-keys.error.title', 'Registration failed')}>
+          {error}
+        </Alert>
+      )}
+      <Stack alignItems="flex-end">
+        <Field label={t('user-security-keys.new-name-label', 'Name')} className={styles.nameField}>
+          <Input
+            id={nameId}
+            value={name}
+            placeholder={t('user-security-keys.new-name-placeholder', 'e.g. YubiKey')}
+            onChange={(e) => setName(e.currentTarget.value)}
+          />
+        </Field>
+        <Field>
+          <Button icon="key-skeleton-alt" onClick={registerSecurityKey} disabled={isRegistering}>
+            <Trans i18nKey="user-security-keys.register">Register a security key or passkey</Trans>
+          </Button>
+        </Field>
+      </Stack>
+    </div>
+  );
+};
+
+const getStyles = (theme: GrafanaTheme2) => ({
+  wrapper: css({
+    maxWidth: '100%',
+  }),
+  nameField: css({
+    width: theme.spacing(40),
+  }),
+});
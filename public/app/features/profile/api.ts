@@ -1,8 +1,9 @@
 import { getBackendSrv } from '@grafana/runtime';
+import { createCredential, WebAuthnCreationOptions } from 'app/core/utils/webauthn';
 
 import { Team, UserDTO, UserOrg, UserSession } from '../../types';
 
-import { ChangePasswordFields, ProfileUpdateFields } from './types';
+import { ChangePasswordFields, ProfileUpdateFields, SecurityKey } from './types';
 
 async function changePassword(payload: ChangePasswordFields): Promise<void> {
   try {
@@ -46,6 +47,23 @@ async function updateUserProfile(payload: ProfileUpdateFields): Promise<void> {
   }
 }
 
+function loadSecurityKeys(): Promise<SecurityKey[]> {
+  return getBackendSrv().get('/api/user/webauthn/credentials');
+}
+
+// registerSecurityKey runs the registration ceremony of the browser between the begin and finish endpoints.
+async function registerSecurityKey(name: string): Promise<SecurityKey> {
+  const { publicKey } = await getBackendSrv().post<{ publicKey: WebAuthnCreationOptions }>(
+    '/api/user/webauthn/register/begin'
+  );
+  const credential = await createCredential(publicKey);
+  return getBackendSrv().post('/api/user/webauthn/register/finish', { name, credential });
+}
+
+async function deleteSecurityKey(id: number): Promise<void> {
+  await getBackendSrv().delete(`/api/user/webauthn/credentials/${id}`);
+}
+
 export const api = {
   changePassword,
   revokeUserSession,
@@ -55,4 +73,7 @@ export const api = {
   loadTeams,
   setUserOrg,
   updateUserProfile,
+  loadSecurityKeys,
+  registerSecurityKey,
+  deleteSecurityKey,
 };
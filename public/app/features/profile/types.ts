@@ -9,3 +9,12 @@ export interface ProfileUpdateFields {
   email: string;
   login: string;
 }
+
+export interface SecurityKey {
+  id: number;
+  name: string;
+  aaguid: string;
+  transports: string[];
+  created: string;
+  lastUsed?: string;
+}
@@ -13,8 +13,13 @@ const backendSrv = {
   fetch: (options: BackendSrvRequest) => {
     return of(mockDatasourceRequest(options));
   },
-  get: (url: string, options?: Partial<BackendSrvRequest>) => {
-    return mockDatasourceRequest(url, options);
+  get: (
+    url: string,
+    params?: BackendSrvRequest['params'],
+    requestId?: string,
+    options?: Partial<BackendSrvRequest>
+  ) => {
+    return mockDatasourceRequest(url, params, requestId, options);
   },
 } as unknown as BackendSrv;
 
@@ -71,4 +76,34 @@ describe('PublicDashboardDatasource', () => {
     expect(mock.calls.length).toBe(1);
     expect(mock.lastCall[0]).toEqual(`/api/public/dashboards/abc123/annotations`);
   });
+
+  test('sends the passphrase of a passphrase protected public dashboard with annotation queries', async () => {
+    mockDatasourceRequest.mockReset();
+    mockDatasourceRequest.mockReturnValue(Promise.resolve([]));
+
+    const ds = new PublicAnnotationsDataSource();
+
+    config.publicDashboardAccessToken = 'abc123';
+    config.publicDashboardPassphrase = 'correct horse battery staple';
+
+    await ds.query({
+      maxDataPoints: 10,
+      intervalMs: 5000,
+      targets: [
+        {
+          refId: 'A',
+          datasource: { uid: GRAFANA_DATASOURCE_NAME, type: 'sample' },
+          queryType: GrafanaQueryType.Annotations,
+        },
+      ],
+      panelId: 1,
+      range: { from: new Date().toLocaleString(), to: new Date().toLocaleString() } as unknown as TimeRange,
+    } as DataQueryRequest);
+
+    expect(mockDatasourceRequest.mock.lastCall[3]).toEqual({
+      headers: { 'X-Grafana-Public-Dashboard-Passphrase': 'correct horse battery staple' },
+    });
+
+    config.publicDashboardPassphrase = undefined;
+  });
 });
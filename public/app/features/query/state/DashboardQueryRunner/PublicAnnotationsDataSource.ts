@@ -13,6 +13,7 @@ import {
 } from '@grafana/data';
 import { config, getBackendSrv } from '@grafana/runtime';
 import { GRAFANA_DATASOURCE_NAME } from 'app/features/alerting/unified/utils/datasource';
+import { getPublicDashboardPassphraseHeaders } from 'app/features/dashboard/components/PublicDashboard/publicDashboardPassphrase';
 
 import { GrafanaQueryType } from '../../../../plugins/datasource/grafana/types';
 
@@ -70,7 +71,9 @@ export class PublicAnnotationsDataSource extends DataSourceApi<DataQuery, DataSo
 
     const annotations = await getBackendSrv().get(
       `/api/public/dashboards/${config.publicDashboardAccessToken}/annotations`,
-      params
+      params,
+      undefined,
+      { headers: getPublicDashboardPassphraseHeaders() }
     );
 
     return { data: [toDataFrame(annotations)] };
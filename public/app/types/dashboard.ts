@@ -66,6 +66,8 @@ export interface DashboardMeta {
   annotationsPermissions?: AnnotationsPermissions;
   publicDashboardEnabled?: boolean;
   dashboardNotFound?: boolean;
+  publicDashboardPassphraseRequired?: boolean;
+  publicDashboardPassphraseInvalid?: boolean;
   isEmbedded?: boolean;
   isNew?: boolean;
 